@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/activitywatch"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	awIdle     time.Duration
+	awHostname string
+	awServer   string
+	awPush     bool
+)
+
+var activityWatchCmd = &cobra.Command{
+	Use:   "activitywatch",
+	Short: "Export history as ActivityWatch web-watcher events",
+	Long: `Convert history visits into ActivityWatch web-watcher events, e.g. to backfill
+browsing from before aw-watcher-web was installed or from a browser it does not
+support.
+
+Each browser gets a bucket named like aw-watcher-web's, e.g.
+aw-watcher-web-chrome_<hostname>, with one web.tab.current event per visit. A
+visit lasts until the next visit in the same browser, unless the gap exceeds
+--idle, in which case it has no duration.
+
+By default the buckets are written as an aw-server export file, which the
+ActivityWatch web UI can import (Settings > Import). --push sends the events
+to a running aw-server instead; events the bucket already has with the same
+time and URL are skipped, so the same range can be pushed again.
+
+Examples:
+  web-recap activitywatch --start-date 2025-01-01 --end-date 2025-03-31 -o aw-import.json
+  web-recap activitywatch --date 2025-12-15 --push
+  web-recap activitywatch --browser firefox --push --aw-url http://nas.local:5600
+`,
+	RunE: runActivityWatch,
+}
+
+func init() {
+	activityWatchCmd.Flags().DurationVar(&awIdle, "idle", stats.DefaultIdleGap, "Longest gap between visits still counted as time on the first page")
+	activityWatchCmd.Flags().StringVar(&awHostname, "hostname", "", "Host name in the bucket names (default: this machine's)")
+	activityWatchCmd.Flags().BoolVar(&awPush, "push", false, "Send the events to aw-server instead of writing them")
+	activityWatchCmd.Flags().StringVar(&awServer, "aw-url", activitywatch.DefaultServerURL, "aw-server URL for --push")
+}
+
+func runActivityWatch(cmd *cobra.Command, args []string) error {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+	startTimeValue, endTimeValue, err := resolveTimeRange(loc)
+	if err != nil {
+		return err
+	}
+
+	hostname := awHostname
+	if hostname == "" {
+		if hostname, err = os.Hostname(); err != nil {
+			return fmt.Errorf("failed to get host name: %v", err)
+		}
+	}
+
+	entries, _, sources, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+	recordOutcome(len(entries), sources)
+	buckets := activitywatch.Buckets(entries, hostname, awIdle, time.Now())
+
+	if !awPush {
+		return writeOutput(func(out io.Writer) error {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(activitywatch.NewExport(buckets))
+		})
+	}
+
+	server := &activitywatch.Server{URL: awServer}
+	for _, b := range buckets {
+		inserted, err := server.Push(cmd.Context(), b)
+		if err != nil {
+			return err
+		}
+		statusf("%s: inserted %d of %d events", b.ID, inserted, len(b.Events))
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// Values for --source
+const (
+	sourceBrowser = "browser"
+	sourceArchive = "archive"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Keep a local archive of history and bookmarks beyond browser expiry",
+	Long: `Browsers expire history after about 90 days. The archive is a local SQLite
+database that keeps every visit and bookmark copied into it by 'archive sync'.
+
+Run 'web-recap archive sync' regularly (e.g. daily from cron) and query years
+of history with --source archive on any history or bookmarks command.
+
+The archive is stored at web-recap/archive.db in the user config directory;
+use --archive to choose another file.
+
+Examples:
+  web-recap archive sync
+  web-recap archive status
+  web-recap archive stats
+  web-recap archive prune --older-than 2y
+  web-recap archive vacuum
+  web-recap archive tab-counts
+  web-recap archive open-tabs --open-for 1w
+  web-recap --source archive --start-date 2024-01-01 --end-date 2024-12-31
+  web-recap bookmarks --source archive --browser firefox
+`,
+}
+
+var archiveSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Copy new visits and bookmarks from all detected browsers into the archive",
+	Long: `Copy new visits and bookmarks from all detected browsers (or --browser) into
+the archive. Each browser has a watermark, so a sync only reads visits newer
+than the last one archived. Bookmarks are copied in full; bookmarks deleted
+from the browser stay in the archive.
+
+Entries are labelled with --source-label when set.
+`,
+	RunE: runArchiveSync,
+}
+
+var archiveStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show what the archive holds for each browser",
+	RunE:  runArchiveStatus,
+}
+
+var archiveStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show archive size, totals, date range, and visits per year",
+	RunE:  runArchiveStats,
+}
+
+var archivePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove archived visits older than a given age",
+	Long: `Remove archived visits older than --older-than, which is an age such as
+90d, 12w, 6m, or 2y, or a YYYY-MM-DD date. Pages with no remaining visits are
+dropped from the search index.
+
+Bookmarks are kept unless --bookmarks is set, in which case bookmarks added
+before the cutoff are removed too. Bookmarks that still exist in the browser
+are copied back by the next sync.
+
+Pruning does not shrink the file; run 'web-recap archive vacuum' afterwards.
+
+Examples:
+  web-recap archive prune --older-than 2y --dry-run
+  web-recap archive prune --older-than 2020-01-01 --bookmarks
+`,
+	RunE: runArchivePrune,
+}
+
+var archiveVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Compact the archive file and its search index",
+	RunE:  runArchiveVacuum,
+}
+
+var archiveTabCountsCmd = &cobra.Command{
+	Use:   "tab-counts",
+	Short: "Show the number of open tabs at each 'watch --tabs' snapshot",
+	Long: `Show the tab and window counts of each browser at every snapshot taken by
+'web-recap watch --tabs', oldest first, for a chart of tab count over time.
+Covers all snapshots unless date flags are given.
+`,
+	RunE: runArchiveTabCounts,
+}
+
+var archiveOpenTabsCmd = &cobra.Command{
+	Use:   "open-tabs",
+	Short: "List tabs open at the latest snapshot, with when they were first seen",
+	Long: `List the tabs open at the latest 'web-recap watch --tabs' snapshot of each
+browser, longest open first. --open-for keeps tabs open at least that long,
+given as an age such as 3d, 1w, or 2m.
+
+Examples:
+  web-recap archive open-tabs
+  web-recap archive open-tabs --open-for 1w
+`,
+	RunE: runArchiveOpenTabs,
+}
+
+var (
+	openTabsFor    string
+	pruneOlderThan string
+	pruneBookmarks bool
+	pruneDryRun    bool
+)
+
+func init() {
+	archivePruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "Remove data older than this age (e.g. 90d, 12w, 6m, 2y) or date (YYYY-MM-DD)")
+	archivePruneCmd.Flags().BoolVar(&pruneBookmarks, "bookmarks", false, "Also remove bookmarks added before the cutoff")
+	archivePruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Report what would be removed without removing it")
+	archivePruneCmd.MarkFlagRequired("older-than")
+	archiveOpenTabsCmd.Flags().StringVar(&openTabsFor, "open-for", "", "Only tabs open at least this long (e.g. 3d, 1w, 2m)")
+
+	archiveCmd.AddCommand(archiveSyncCmd)
+	archiveCmd.AddCommand(archiveStatusCmd)
+	archiveCmd.AddCommand(archiveStatsCmd)
+	archiveCmd.AddCommand(archivePruneCmd)
+	archiveCmd.AddCommand(archiveVacuumCmd)
+	archiveCmd.AddCommand(archiveTabCountsCmd)
+	archiveCmd.AddCommand(archiveOpenTabsCmd)
+}
+
+// resolveArchivePath returns --archive or the default archive location
+func resolveArchivePath() (string, error) {
+	if archivePath != "" {
+		return archivePath, nil
+	}
+	path, err := archive.DefaultPath()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate archive: %v", err)
+	}
+	return path, nil
+}
+
+func runArchiveSync(cmd *cobra.Command, args []string) error {
+	detector := newDetector()
+	var browsers []browser.Browser
+	if allBrowsers || browserType == "auto" {
+		browsers = detector.Detect()
+	} else {
+		b, err := detector.GetBrowser(browser.Type(browserType))
+		if err != nil {
+			return fmt.Errorf("failed to get browser: %v", err)
+		}
+		browsers = append(browsers, *b)
+	}
+	if len(browsers) == 0 {
+		return fmt.Errorf("no browsers detected")
+	}
+
+	path, err := resolveArchivePath()
+	if err != nil {
+		return err
+	}
+	a, err := archive.Open(path)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	for _, b := range browsers {
+		r := archive.SyncBrowser(cmd.Context(), a, b, sourceLabel, blocked)
+		statusf("%s: %d new visits, %d new bookmarks", r.Browser, r.Visits, r.Bookmarks)
+		for _, e := range r.Errors {
+			warnf("%s: %s", r.Browser, e)
+		}
+		if err := cmd.Context().Err(); err != nil {
+			return err
+		}
+	}
+	statusf("Archive: %s", a.Path())
+	return nil
+}
+
+func runArchiveStatus(cmd *cobra.Command, args []string) error {
+	a, err := openExistingArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	status, err := a.Status()
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %v", err)
+	}
+	if status == nil {
+		status = []models.ArchiveStatus{}
+	}
+	return writeArchiveJSON(status)
+}
+
+func runArchiveStats(cmd *cobra.Command, args []string) error {
+	a, err := openExistingArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	stats, err := a.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %v", err)
+	}
+	return writeArchiveJSON(stats)
+}
+
+func runArchivePrune(cmd *cobra.Command, args []string) error {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+	cutoff, err := archive.Cutoff(time.Now().In(loc), pruneOlderThan)
+	if err != nil {
+		return err
+	}
+
+	a, err := openExistingArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	result, err := a.Prune(cutoff, pruneBookmarks, pruneDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to prune archive: %v", err)
+	}
+	if pruneDryRun {
+		statusf("Would remove %d visits and %d bookmarks before %s", result.Visits, result.Bookmarks, cutoff.Format("2006-01-02"))
+	} else {
+		statusf("Removed %d visits and %d bookmarks before %s", result.Visits, result.Bookmarks, cutoff.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func runArchiveVacuum(cmd *cobra.Command, args []string) error {
+	a, err := openExistingArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	before, after, err := a.Vacuum()
+	if err != nil {
+		return err
+	}
+	statusf("Archive: %s (%d -> %d bytes)", a.Path(), before, after)
+	return nil
+}
+
+func runArchiveTabCounts(cmd *cobra.Command, args []string) error {
+	var start, end time.Time
+	if date != "" || startDate != "" || endDate != "" {
+		loc, err := getTimezone(timezone, utcMode)
+		if err != nil {
+			return err
+		}
+		if start, end, err = resolveTimeRange(loc); err != nil {
+			return err
+		}
+		start, end = start.UTC(), end.UTC()
+	}
+
+	a, err := openExistingArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	counts, err := a.TabCounts(start, end)
+	if err != nil {
+		return err
+	}
+	if counts == nil {
+		counts = []models.TabCount{}
+	}
+	return writeArchiveJSON(counts)
+}
+
+func runArchiveOpenTabs(cmd *cobra.Command, args []string) error {
+	var openSince time.Time
+	if openTabsFor != "" {
+		var err error
+		if openSince, err = archive.Cutoff(time.Now(), openTabsFor); err != nil {
+			return err
+		}
+	}
+
+	a, err := openExistingArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	tabs, err := a.OpenTabs(openSince)
+	if err != nil {
+		return err
+	}
+	kept := []models.ArchivedTab{}
+	for _, t := range tabs {
+		if !blocked.Blocks(t.Domain) {
+			kept = append(kept, t)
+		}
+	}
+	return writeArchiveJSON(kept)
+}
+
+// openExistingArchive opens the archive for maintenance commands
+func openExistingArchive() (*archive.Archive, error) {
+	path, err := resolveArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	return archive.OpenExisting(path)
+}
+
+// writeArchiveJSON writes v as indented JSON to stdout or --output
+func writeArchiveJSON(v any) error {
+	return writeOutput(func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	})
+}
+
+// openArchive opens the archive for --source archive queries
+func openArchive() (*archive.Archive, error) {
+	path, err := resolveArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	return archive.OpenExisting(path)
+}
+
+// archiveBrowser returns the browser type to filter archived entries by, and
+// the browser name for the report
+func archiveBrowser() (string, string) {
+	if allBrowsers || browserType == "auto" {
+		return "", "all"
+	}
+	return browserType, browserType
+}
+
+// queryArchiveHistory reads history from the archive for --source archive
+func queryArchiveHistory(startTimeValue, endTimeValue time.Time) ([]models.HistoryEntry, string, error) {
+	if dbPath != "" {
+		return nil, "", fmt.Errorf("--db-path cannot be used with --source archive (use --archive)")
+	}
+	a, err := openArchive()
+	if err != nil {
+		return nil, "", err
+	}
+	defer a.Close()
+
+	filter, browserName := archiveBrowser()
+	entries, err := a.History(filter, startTimeValue, endTimeValue)
+	if err != nil {
+		return nil, "", err
+	}
+	return setHosts(entries), browserName, nil
+}
+
+// queryArchiveBookmarks reads bookmarks from the archive for --source archive
+func queryArchiveBookmarks(startTimeValue, endTimeValue time.Time) ([]models.BookmarkEntry, string, error) {
+	if dbPath != "" {
+		return nil, "", fmt.Errorf("--db-path cannot be used with --source archive (use --archive)")
+	}
+	a, err := openArchive()
+	if err != nil {
+		return nil, "", err
+	}
+	defer a.Close()
+
+	filter, browserName := archiveBrowser()
+	entries, err := a.Bookmarks(filter, startTimeValue, endTimeValue)
+	if err != nil {
+		return nil, "", err
+	}
+	return blocked.Bookmarks(entries), browserName, nil
+}
+
+// validateSource checks the --source value
+func validateSource() error {
+	switch strings.ToLower(dataSource) {
+	case sourceBrowser, sourceArchive:
+		dataSource = strings.ToLower(dataSource)
+		return nil
+	default:
+		return fmt.Errorf("invalid --source %q (use browser or archive)", dataSource)
+	}
+}
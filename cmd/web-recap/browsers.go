@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+)
+
+var (
+	// browserList and excludeList are the raw --browser and
+	// --exclude-browser values; selectBrowsers resolves them
+	browserList []string
+	excludeList []string
+
+	// selectedBrowsers and excludedBrowsers limit the browsers detected when
+	// reading several at once
+	selectedBrowsers []browser.Type
+	excludedBrowsers []browser.Type
+
+	// dataDir is a browser's user-data root whose profiles are read instead
+	// of the installed browser's
+	dataDir string
+
+	// includeWindowsHost offers the Windows browsers when running in WSL
+	includeWindowsHost bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "Read every profile under this browser user-data directory (portable installs, test profiles, disk images); needs --browser")
+	rootCmd.PersistentFlags().BoolVar(&includeWindowsHost, "include-windows-host", false, "Inside WSL, also detect the browsers installed on Windows (as chrome:windows, firefox:windows, ...)")
+}
+
+// selectBrowsers resolves --browser and --exclude-browser. One --browser
+// value reads that browser as before; several read just those browsers,
+// like --all-browsers limited to the list.
+func selectBrowsers() error {
+	if includeWindowsHost {
+		if err := registerWindowsHost(); err != nil {
+			return err
+		}
+	}
+
+	selected, err := browserTypes("--browser", browserList)
+	if err != nil {
+		return err
+	}
+	if excludedBrowsers, err = browserTypes("--exclude-browser", excludeList); err != nil {
+		return err
+	}
+
+	selectedBrowsers = nil
+	switch len(selected) {
+	case 0:
+		browserType = string(browser.Auto)
+	case 1:
+		browserType = string(selected[0])
+	default:
+		browserType = string(browser.Auto)
+		selectedBrowsers = selected
+	}
+
+	for _, t := range excludedBrowsers {
+		if string(t) == browserType {
+			return fmt.Errorf("--browser %s is excluded by --exclude-browser", t)
+		}
+	}
+	if dataDir != "" {
+		return selectDataDir(selected)
+	}
+	return nil
+}
+
+// selectDataDir registers the profiles under --data-dir as browsers of their
+// own and selects them in place of the installed browsers
+func selectDataDir(selected []browser.Type) error {
+	if dbPath != "" {
+		return fmt.Errorf("--data-dir cannot be used with --db-path")
+	}
+	if len(selected) != 1 || selected[0] == browser.Auto {
+		return fmt.Errorf("--data-dir needs --browser with the one browser the directory belongs to")
+	}
+
+	defs, err := browser.DataDirBrowsers(selected[0], dataDir)
+	if err != nil {
+		return err
+	}
+	for _, def := range defs {
+		if err := browser.Register(def); err != nil {
+			return err
+		}
+		selectedBrowsers = append(selectedBrowsers, def.Type)
+	}
+	browserType = string(browser.Auto)
+	return nil
+}
+
+// registerWindowsHost registers the browsers of the Windows host, so they
+// are detected and can be chosen with --browser
+func registerWindowsHost() error {
+	if !browser.IsWSL() {
+		return fmt.Errorf("--include-windows-host only works inside WSL")
+	}
+	defs := browser.WindowsHostBrowsers(browser.WindowsUsersDir)
+	if len(defs) == 0 {
+		warnf("no Windows browsers found in %s", browser.WindowsUsersDir)
+	}
+	for _, def := range defs {
+		if err := browser.Register(def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// browserTypes parses the browser names given to flag, allowing "auto" only
+// on its own
+func browserTypes(flag string, names []string) ([]browser.Type, error) {
+	var types []browser.Type
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		t := browser.Type(name)
+		if t == browser.Auto {
+			if len(names) > 1 || flag != "--browser" {
+				return nil, fmt.Errorf("%s: auto cannot be combined with other browsers", flag)
+			}
+		} else if browser.EngineOf(t) == "" {
+			return nil, fmt.Errorf("%s: unknown browser %q", flag, name)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// newDetector returns a browser detector limited by --browser and
+// --exclude-browser
+func newDetector() *browser.Detector {
+	return &browser.Detector{Only: selectedBrowsers, Exclude: excludedBrowsers}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var capabilitiesJSON bool
+
+var capabilitiesCmd = &cobra.Command{
+	Use:   "capabilities",
+	Short: "Show which data types can be read from each detected browser",
+	Long: `Print, for each detected browser, which data types web-recap can read on this
+platform: history, bookmarks, tabs, downloads, and searches. Unsupported data
+types include the reason, such as a missing file or an engine that web-recap
+cannot read that data from.
+
+Examples:
+  web-recap capabilities
+  web-recap capabilities --browser firefox
+  web-recap capabilities --json
+`,
+	RunE: runCapabilities,
+}
+
+func init() {
+	capabilitiesCmd.Flags().BoolVar(&capabilitiesJSON, "json", false, "Output as JSON")
+}
+
+func runCapabilities(cmd *cobra.Command, args []string) error {
+	detector := newDetector()
+
+	var browsers []browser.Browser
+	if allBrowsers || browserType == "auto" {
+		browsers = detector.Detect()
+	} else {
+		b, err := detector.GetBrowser(browser.Type(browserType))
+		if err != nil {
+			return fmt.Errorf("failed to get browser: %v", err)
+		}
+		browsers = []browser.Browser{*b}
+	}
+
+	report := models.CapabilitiesReport{
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+		Browsers: []models.BrowserCapabilities{},
+	}
+	for _, b := range browsers {
+		report.Browsers = append(report.Browsers, database.Capabilities(b))
+	}
+
+	return writeOutput(func(out io.Writer) error {
+		if capabilitiesJSON {
+			return output.FormatCapabilitiesJSON(out, report)
+		}
+		return output.FormatCapabilitiesText(out, report)
+	})
+}
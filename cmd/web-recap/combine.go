@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var combineCmd = &cobra.Command{
+	Use:   "combine <report.json>...",
+	Short: "Merge history or bookmark reports from earlier runs into one",
+	Long: `Merge JSON reports written by earlier runs, e.g. exports from several machines or
+date ranges, into one report. Visits and bookmarks found in more than one report
+are kept once, and entries are sorted again.
+
+The reports must all be history reports or all bookmark reports, in the JSON
+format. Streamed formats (jsonl, csv, compact) cannot be combined.
+
+Examples:
+  web-recap combine laptop.json desktop.json -o merged.json
+  web-recap combine week-*.json --display-tz local
+  web-recap combine bookmarks-a.json bookmarks-b.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCombine,
+}
+
+// reportKind is the kind of report a combine input holds
+type reportKind string
+
+const (
+	kindUnknown   reportKind = ""
+	kindHistory   reportKind = "history"
+	kindBookmarks reportKind = "bookmarks"
+)
+
+func runCombine(cmd *cobra.Command, args []string) error {
+	kind, data, err := loadReports(args)
+	if err != nil {
+		return err
+	}
+	if kind == kindBookmarks {
+		return combineBookmarks(args, data)
+	}
+	return combineHistory(args, data)
+}
+
+func combineHistory(paths []string, data [][]byte) error {
+	reports := make([]models.HistoryReport, len(paths))
+	for i, path := range paths {
+		var err error
+		if reports[i], err = decodeHistoryReport(path, data[i]); err != nil {
+			return err
+		}
+		if reports[i].Budget != nil || len(reports[i].CollapsedDomains) > 0 {
+			reports[i].Warnings = append(reports[i].Warnings, fmt.Sprintf("%s was reduced to a token budget; the entries it left out are missing", path))
+		}
+	}
+
+	report := output.CombineHistoryReports(reports)
+	report.Meta = newReportMeta(report.Sources)
+	output.LocalizeHistoryReport(&report, displayLoc)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatHistoryReportJSON(out, report)
+	})
+}
+
+func combineBookmarks(paths []string, data [][]byte) error {
+	reports := make([]models.BookmarkReport, len(paths))
+	for i, path := range paths {
+		var err error
+		if reports[i], err = decodeBookmarkReport(path, data[i]); err != nil {
+			return err
+		}
+	}
+
+	report := output.CombineBookmarkReports(reports)
+	report.Meta = newReportMeta(report.Sources)
+	output.LocalizeBookmarkReport(&report, displayLoc)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatBookmarkReportJSON(out, report)
+	})
+}
+
+// loadReports reads saved reports and checks they are all history reports
+// or all bookmark reports. Reports without entries count as history unless
+// another report is a bookmark report.
+func loadReports(paths []string) (reportKind, [][]byte, error) {
+	kind := kindUnknown
+	data := make([][]byte, len(paths))
+	for i, path := range paths {
+		var err error
+		if data[i], err = os.ReadFile(path); err != nil {
+			return kindUnknown, nil, fmt.Errorf("failed to read report: %v", err)
+		}
+		k, err := detectReportKind(path, data[i])
+		if err != nil {
+			return kindUnknown, nil, err
+		}
+		if k != kindUnknown && kind != kindUnknown && k != kind {
+			return kindUnknown, nil, fmt.Errorf("%s is a %s report, but earlier reports are %s reports", path, k, kind)
+		}
+		if k != kindUnknown {
+			kind = k
+		}
+	}
+	if kind == kindUnknown {
+		kind = kindHistory
+	}
+	return kind, data, nil
+}
+
+// decodeHistoryReport parses a saved history report, checking every entry
+// has a URL and time. Entries on --blocklist domains are dropped, since the
+// report may have been saved before the domain was blocked.
+func decodeHistoryReport(path string, data []byte) (models.HistoryReport, error) {
+	var report models.HistoryReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("%s: invalid history report: %v", path, err)
+	}
+	for i, e := range report.Entries {
+		if e.URL == "" || e.Timestamp.IsZero() {
+			return report, fmt.Errorf("%s: entry %d has no url or timestamp", path, i+1)
+		}
+	}
+	report.Entries = blocked.History(report.Entries)
+	return report, nil
+}
+
+// decodeBookmarkReport parses a saved bookmark report, checking every entry
+// has a URL, and drops the entries on --blocklist domains
+func decodeBookmarkReport(path string, data []byte) (models.BookmarkReport, error) {
+	var report models.BookmarkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("%s: invalid bookmark report: %v", path, err)
+	}
+	for i, e := range report.Entries {
+		if e.URL == "" {
+			return report, fmt.Errorf("%s: entry %d has no url", path, i+1)
+		}
+	}
+	report.Entries = blocked.Bookmarks(report.Entries)
+	return report, nil
+}
+
+// detectReportKind tells history and bookmark reports apart by their
+// entries; a report without entries could be either
+func detectReportKind(path string, data []byte) (reportKind, error) {
+	var probe struct {
+		Entries *[]map[string]json.RawMessage `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return kindUnknown, fmt.Errorf("%s: not a JSON report (streamed jsonl, csv, and compact output cannot be combined): %v", path, err)
+	}
+	if probe.Entries == nil {
+		return kindUnknown, fmt.Errorf("%s: not a history or bookmark report", path)
+	}
+	for _, e := range *probe.Entries {
+		switch {
+		case e["window_id"] != nil:
+			return kindUnknown, fmt.Errorf("%s: tab reports cannot be combined", path)
+		case e["timestamp"] != nil:
+			return kindHistory, nil
+		default:
+			return kindBookmarks, nil
+		}
+	}
+	return kindUnknown, nil
+}
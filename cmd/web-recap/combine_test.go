@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/blocklist"
+)
+
+func TestCombineAndDiffBlocklist(t *testing.T) {
+	list, err := blocklist.Parse(strings.NewReader("mybank.example\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	saved := []any{blocked, outputFile}
+	defer func() { blocked, outputFile = saved[0].(*blocklist.List), saved[1].(string) }()
+	blocked = list
+
+	reports := []string{
+		`{"browser":"chrome","entries":[{"timestamp":"2025-03-01T09:00:00Z","url":"https://online.mybank.example/login","domain":"online.mybank.example"}]}`,
+		`{"browser":"chrome","entries":[{"timestamp":"2025-03-02T09:00:00Z","url":"https://news.example/","domain":"news.example"}]}`,
+	}
+	var paths []string
+	for i, r := range reports {
+		path := filepath.Join(dir, []string{"old.json", "new.json"}[i])
+		if err := os.WriteFile(path, []byte(r), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	for name, run := range map[string]func() error{
+		"combine":      func() error { return runCombine(combineCmd, paths) },
+		"diff-exports": func() error { return runDiffExports(diffExportsCmd, paths) },
+	} {
+		outputFile = filepath.Join(dir, name+".json")
+		if err := run(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); strings.Contains(got, "mybank") || !strings.Contains(got, "news.example") {
+			t.Errorf("%s output should leave out the blocked domain:\n%s", name, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffByURL bool
+	diffTop   int
+)
+
+var diffExportsCmd = &cobra.Command{
+	Use:   "diff-exports <old.json> <new.json>",
+	Short: "Compare two saved history or bookmark reports",
+	Long: `Compare two JSON reports written by earlier runs: how many entries each has,
+which domains appeared or disappeared, and which domains changed the most. For
+bookmark reports, the added, removed, and moved bookmarks are listed too.
+
+Bookmarks match by browser and id (or URL when a browser keeps no id). Use
+--by-url to match by URL alone, e.g. to check that Chrome and Firefox or two
+machines hold the same bookmarks.
+
+Examples:
+  web-recap diff-exports bookmarks-jan.json bookmarks-feb.json
+  web-recap diff-exports laptop-bookmarks.json desktop-bookmarks.json --by-url
+  web-recap diff-exports last-week.json this-week.json --top 10
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiffExports,
+}
+
+func init() {
+	diffExportsCmd.Flags().BoolVar(&diffByURL, "by-url", false, "Match bookmarks by URL alone, ignoring browser and id")
+	diffExportsCmd.Flags().IntVar(&diffTop, "top", 20, "Number of domain count changes to list (0: all)")
+}
+
+func runDiffExports(cmd *cobra.Command, args []string) error {
+	kind, data, err := loadReports(args)
+	if err != nil {
+		return err
+	}
+
+	var diff models.ExportDiff
+	var browsers [2]string
+	if kind == kindBookmarks {
+		var reports [2]models.BookmarkReport
+		for i := range reports {
+			if reports[i], err = decodeBookmarkReport(args[i], data[i]); err != nil {
+				return err
+			}
+			browsers[i] = reports[i].Browser
+		}
+		diff = stats.DiffBookmarks(reports[0].Entries, reports[1].Entries, diffByURL, diffTop)
+	} else {
+		var reports [2]models.HistoryReport
+		for i := range reports {
+			if reports[i], err = decodeHistoryReport(args[i], data[i]); err != nil {
+				return err
+			}
+			browsers[i] = reports[i].Browser
+		}
+		diff = stats.DiffHistory(reports[0].Entries, reports[1].Entries, diffTop)
+	}
+	diff.Old.File, diff.Old.Browser = args[0], browsers[0]
+	diff.New.File, diff.New.Browser = args[1], browsers[1]
+	diff.Meta = newReportMeta(nil)
+
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatExportDiffJSON(out, diff)
+	})
+}
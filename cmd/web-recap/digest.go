@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/digest"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/notify"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	digestPeriod     string
+	digestFormat     string
+	digestTopDomains int
+	digestTopPages   int
+	digestEmails     []string
+	digestSubject    string
+	smtpHost         string
+	smtpPort         int
+	smtpUsername     string
+	smtpFrom         string
+	slackWebhook     string
+	discordWebhook   string
+	postSlack        bool
+	postDiscord      bool
+	postTelegram     bool
+	telegramChat     string
+	postMatrix       bool
+	matrixHomeserver string
+	matrixRoom       string
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Build a daily or weekly browsing digest (Markdown, HTML, or JSON)",
+	Long: `Summarize recent browsing history into a readable digest: visits per day,
+top domains, and most-visited pages.
+
+The digest covers the last day (--period daily) or the last seven days including
+today (--period weekly), unless --date or --start-date/--end-date are given.
+
+With --email the digest is sent through SMTP instead of being printed, which makes
+the command suitable for cron or any other scheduler. SMTP settings come from
+flags or the SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM
+environment variables (the password is only read from the environment).
+
+With --slack / --discord the digest is posted to a channel through an incoming
+webhook (Slack Block Kit message or Discord embed). Webhook URLs come from
+--slack-webhook / --discord-webhook or SLACK_WEBHOOK_URL / DISCORD_WEBHOOK_URL.
+With --telegram the digest is sent by a bot to a Telegram chat (TELEGRAM_BOT_TOKEN,
+TELEGRAM_CHAT_ID or --telegram-chat). With --matrix it is posted to a Matrix room
+(MATRIX_HOMESERVER, MATRIX_ACCESS_TOKEN, MATRIX_ROOM_ID or --matrix-homeserver /
+--matrix-room). Bot and access tokens are only read from the environment.
+
+Delivery options can be combined.
+
+Examples:
+  web-recap digest                                   # Weekly Markdown digest to stdout
+  web-recap digest --period daily --format html -o today.html
+  web-recap digest --email me@example.com            # Email the weekly digest
+  SMTP_HOST=smtp.example.com SMTP_USERNAME=me SMTP_PASSWORD=... web-recap digest --email me@example.com
+  SLACK_WEBHOOK_URL=https://hooks.slack.com/services/... web-recap digest --period daily --slack
+  web-recap digest --discord-webhook https://discord.com/api/webhooks/...
+  TELEGRAM_BOT_TOKEN=123:abc web-recap digest --telegram --telegram-chat 987654321
+  MATRIX_HOMESERVER=matrix.org MATRIX_ACCESS_TOKEN=... web-recap digest --matrix --matrix-room '!abc:matrix.org'
+`,
+	RunE: runDigest,
+}
+
+func init() {
+	digestCmd.Flags().StringVar(&digestPeriod, "period", "weekly", "Digest period: daily or weekly")
+	digestCmd.Flags().StringVar(&digestFormat, "format", "markdown", "Output format: markdown, html, or json")
+	digestCmd.Flags().IntVar(&digestTopDomains, "top-domains", 10, "Number of top domains to include")
+	digestCmd.Flags().IntVar(&digestTopPages, "top-pages", 15, "Number of top pages to include")
+	digestCmd.Flags().StringSliceVar(&digestEmails, "email", nil, "Send the digest to these email addresses instead of printing it")
+	digestCmd.Flags().StringVar(&digestSubject, "subject", "", "Email subject (default: digest title)")
+	digestCmd.Flags().StringVar(&smtpHost, "smtp-host", "", "SMTP server host (default: SMTP_HOST)")
+	digestCmd.Flags().IntVar(&smtpPort, "smtp-port", 0, "SMTP server port (default: SMTP_PORT or 587)")
+	digestCmd.Flags().StringVar(&smtpUsername, "smtp-user", "", "SMTP username (default: SMTP_USERNAME)")
+	digestCmd.Flags().StringVar(&smtpFrom, "smtp-from", "", "Sender address (default: SMTP_FROM or the SMTP username)")
+	digestCmd.Flags().BoolVar(&postSlack, "slack", false, "Post the digest to Slack (webhook from SLACK_WEBHOOK_URL)")
+	digestCmd.Flags().StringVar(&slackWebhook, "slack-webhook", "", "Slack incoming webhook URL to post the digest to")
+	digestCmd.Flags().BoolVar(&postDiscord, "discord", false, "Post the digest to Discord (webhook from DISCORD_WEBHOOK_URL)")
+	digestCmd.Flags().StringVar(&discordWebhook, "discord-webhook", "", "Discord webhook URL to post the digest to")
+	digestCmd.Flags().BoolVar(&postTelegram, "telegram", false, "Send the digest to a Telegram chat (bot token from TELEGRAM_BOT_TOKEN)")
+	digestCmd.Flags().StringVar(&telegramChat, "telegram-chat", "", "Telegram chat ID (default: TELEGRAM_CHAT_ID)")
+	digestCmd.Flags().BoolVar(&postMatrix, "matrix", false, "Post the digest to a Matrix room (access token from MATRIX_ACCESS_TOKEN)")
+	digestCmd.Flags().StringVar(&matrixHomeserver, "matrix-homeserver", "", "Matrix homeserver URL (default: MATRIX_HOMESERVER)")
+	digestCmd.Flags().StringVar(&matrixRoom, "matrix-room", "", "Matrix room ID (default: MATRIX_ROOM_ID)")
+}
+
+func runDigest(cmd *cobra.Command, args []string) error {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	period, err := digest.ParsePeriod(digestPeriod)
+	if err != nil {
+		return err
+	}
+
+	if slackWebhook == "" && postSlack {
+		slackWebhook = os.Getenv("SLACK_WEBHOOK_URL")
+		if slackWebhook == "" {
+			return fmt.Errorf("--slack requires SLACK_WEBHOOK_URL or --slack-webhook")
+		}
+	}
+	if discordWebhook == "" && postDiscord {
+		discordWebhook = os.Getenv("DISCORD_WEBHOOK_URL")
+		if discordWebhook == "" {
+			return fmt.Errorf("--discord requires DISCORD_WEBHOOK_URL or --discord-webhook")
+		}
+	}
+
+	telegramConfig := notify.LoadTelegramConfigFromEnv()
+	if telegramChat != "" {
+		telegramConfig.ChatID = telegramChat
+	}
+	if postTelegram {
+		if err := telegramConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	matrixConfig := notify.LoadMatrixConfigFromEnv()
+	if matrixHomeserver != "" {
+		matrixConfig.Homeserver = matrixHomeserver
+	}
+	if matrixRoom != "" {
+		matrixConfig.RoomID = matrixRoom
+	}
+	if postMatrix {
+		if err := matrixConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	startTimeValue, endTimeValue := period.Range(time.Now(), loc)
+	if date != "" {
+		startTimeValue, err = parseDateTimeInLocation(date, "", loc)
+		if err != nil {
+			return err
+		}
+		endTimeValue = startTimeValue.AddDate(0, 0, 1)
+	} else if startDate != "" || endDate != "" {
+		if startDate != "" {
+			startTimeValue, err = parseDateTimeInLocation(startDate, "", loc)
+			if err != nil {
+				return err
+			}
+		}
+		if endDate != "" {
+			endTimeValue, err = parseDateTimeInLocation(endDate, "", loc)
+			if err != nil {
+				return err
+			}
+			endTimeValue = endTimeValue.AddDate(0, 0, 1)
+		}
+	}
+
+	entries, browserName, _, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+
+	domainAliases.Apply(entries)
+	report := digest.Build(entries, browserName, startTimeValue, endTimeValue, loc, digest.Options{
+		Period:     period,
+		TopDomains: digestTopDomains,
+		TopPages:   digestTopPages,
+	})
+
+	if len(digestEmails) > 0 || slackWebhook != "" || discordWebhook != "" || postTelegram || postMatrix {
+		return deliverDigest(report, telegramConfig, matrixConfig)
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		return writeDigest(out, report, digestFormat)
+	})
+}
+
+// writeDigest renders the digest in the requested format
+func writeDigest(w io.Writer, report models.DigestReport, format string) error {
+	switch format {
+	case "markdown", "md":
+		return output.FormatDigestMarkdown(w, report)
+	case "html":
+		return output.FormatDigestHTML(w, report)
+	case "json":
+		return output.FormatDigestJSON(w, report)
+	default:
+		return fmt.Errorf("unsupported digest format: %s (use markdown, html, or json)", format)
+	}
+}
+
+// deliverDigest sends the digest to every requested destination
+func deliverDigest(report models.DigestReport, telegramConfig notify.TelegramConfig, matrixConfig notify.MatrixConfig) error {
+	if len(digestEmails) > 0 {
+		if err := emailDigest(report); err != nil {
+			return err
+		}
+	}
+
+	if slackWebhook != "" {
+		if err := notify.PostSlackDigest(slackWebhook, report); err != nil {
+			return fmt.Errorf("failed to post digest to Slack: %v", err)
+		}
+		statusf("Posted %s digest to Slack", report.Period)
+	}
+
+	if discordWebhook != "" {
+		if err := notify.PostDiscordDigest(discordWebhook, report); err != nil {
+			return fmt.Errorf("failed to post digest to Discord: %v", err)
+		}
+		statusf("Posted %s digest to Discord", report.Period)
+	}
+
+	if postTelegram {
+		if err := notify.PostTelegramDigest(telegramConfig, report); err != nil {
+			return fmt.Errorf("failed to send digest to Telegram: %v", err)
+		}
+		statusf("Sent %s digest to Telegram", report.Period)
+	}
+
+	if postMatrix {
+		if err := notify.PostMatrixDigest(matrixConfig, report); err != nil {
+			return fmt.Errorf("failed to post digest to Matrix: %v", err)
+		}
+		statusf("Posted %s digest to Matrix", report.Period)
+	}
+
+	return nil
+}
+
+// emailDigest sends the digest as a Markdown/HTML multipart email
+func emailDigest(report models.DigestReport) error {
+	config := notify.LoadSMTPConfigFromEnv()
+	if smtpHost != "" {
+		config.Host = smtpHost
+	}
+	if smtpPort != 0 {
+		config.Port = smtpPort
+	}
+	if smtpUsername != "" {
+		config.Username = smtpUsername
+	}
+	if smtpFrom != "" {
+		config.From = smtpFrom
+	}
+
+	var text, html bytes.Buffer
+	if err := output.FormatDigestMarkdown(&text, report); err != nil {
+		return err
+	}
+	if err := output.FormatDigestHTML(&html, report); err != nil {
+		return err
+	}
+
+	subject := digestSubject
+	if subject == "" {
+		subject = report.Title
+	}
+
+	if err := notify.SendEmail(config, notify.Email{
+		To:       digestEmails,
+		Subject:  subject,
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}); err != nil {
+		return err
+	}
+
+	statusf("Sent %s digest to %d recipient(s)", report.Period, len(digestEmails))
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+)
+
+var dryRun bool
+
+// writeHistoryPlan prints, for --dry-run, what the history export would read
+// and write, without opening a database or the output
+func writeHistoryPlan(ctx context.Context, startTimeValue, endTimeValue time.Time, stateFile string) error {
+	plan := models.DryRunPlan{
+		Command:   reportQuery.Command,
+		Source:    dataSource,
+		StartDate: startTimeValue,
+		EndDate:   endTimeValue,
+		Timezone:  reportTimezone(),
+		Format:    format,
+		Filters:   historyFilters(),
+		Encrypted: encrypting(),
+		Writes:    historyWrites(stateFile),
+	}
+
+	switch {
+	case demoMode:
+		plan.Source = "demo"
+	case dataSource == sourceArchive:
+		path, err := resolveArchivePath()
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, err.Error())
+			break
+		}
+		plan.Archive = path
+		filter, name := archiveBrowser()
+		if filter == "" {
+			filter = name
+		}
+		plan.Browsers = []models.DryRunBrowser{{Browser: filter, Name: name, Path: path, Exists: fileExists(path)}}
+	default:
+		browsers, err := planBrowsers(ctx)
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, err.Error())
+		}
+		plan.Browsers = browsers
+	}
+	return output.FormatDryRunJSON(os.Stdout, plan)
+}
+
+// planBrowsers resolves the browser profiles selected by the flags, as
+// queryBrowserHistory would, without opening their databases
+func planBrowsers(ctx context.Context) ([]models.DryRunBrowser, error) {
+	detector := newDetector()
+	var browsers []browser.Browser
+	if allBrowsers || browserType == "auto" {
+		detected, err := detector.DetectContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(detected) == 0 {
+			return nil, fmt.Errorf("no browsers detected")
+		}
+		browsers = detected
+	} else {
+		b, err := historyBrowser(detector)
+		if err != nil {
+			return nil, err
+		}
+		browsers = []browser.Browser{*b}
+	}
+
+	planned := make([]models.DryRunBrowser, len(browsers))
+	for i, b := range browsers {
+		planned[i] = models.DryRunBrowser{
+			Browser: string(b.Type),
+			Name:    b.Name,
+			Engine:  string(browser.EngineOf(b.Type)),
+			Path:    b.Path,
+			Exists:  fileExists(b.Path),
+		}
+	}
+	return planned, nil
+}
+
+// historyFilters returns the filters and transformations set by the history
+// flags, keyed by flag name
+func historyFilters() map[string]string {
+	filters := make(map[string]string)
+	if n := blocked.Len(); n > 0 {
+		filters["blocklist"] = fmt.Sprintf("%d patterns", n)
+		if blocklistPath != "" {
+			filters["blocklist"] += " from " + blocklistPath
+		}
+	}
+	set := func(name string, on bool, value string) {
+		if on {
+			filters[name] = value
+		}
+	}
+	set("exclude-internal", excludeInternal, "true")
+	set("lang", len(langCodes) > 0, strings.Join(langCodes, ","))
+	set("redact", redacting, redactFlag)
+	set("canonicalize", canonicalizeURLs, "true")
+	set("source-label", sourceLabel != "", sourceLabel)
+	set("collapse", collapseWindow > 0, collapseWindow.String())
+	set("granularity", granularity == "url", granularity)
+	set("merge", mergeMode, "true")
+	set("group-by", historyGroupBy != "", historyGroupBy)
+	set("max-tokens", maxTokens > 0, strconv.Itoa(maxTokens))
+	set("incremental", incrementalMode, "true")
+	set("since-last-run", sinceLastRun, "true")
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+// historyWrites returns where the history export would write its output,
+// and the state file it would update
+func historyWrites(stateFile string) []models.DryRunWrite {
+	var writes []models.DryRunWrite
+	switch {
+	case splitBy != "":
+		base := outputPath()
+		if base == "" {
+			base = "history." + format
+			if format == "browserexport" {
+				base = "history.json"
+			}
+		}
+		ext := filepath.Ext(base)
+		writes = append(writes, models.DryRunWrite{Kind: "files", Target: strings.TrimSuffix(base, ext) + "-NNN" + ext})
+	case uploadTarget() != "":
+		writes = append(writes, models.DryRunWrite{Kind: "upload", Target: uploadTarget()})
+	case postTarget() != "":
+		writes = append(writes, models.DryRunWrite{Kind: "post", Target: postTarget()})
+	case outputFile != "":
+		writes = append(writes, models.DryRunWrite{Kind: "file", Target: outputPath()})
+	default:
+		writes = append(writes, models.DryRunWrite{Kind: "stdout"})
+	}
+	if stateFile != "" {
+		writes = append(writes, models.DryRunWrite{Kind: "state", Target: stateFile})
+	}
+	return writes
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
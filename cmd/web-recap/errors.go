@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+)
+
+// Exit codes, documented under "Exit Codes" in the README
+const (
+	exitFailure    = 1
+	exitNoBrowsers = 2
+	exitPermission = 3
+	exitNoEntries  = 4
+	exitPartial    = 5
+	exitCanceled   = 130
+)
+
+// Error codes reported by --json-errors, besides the database.Code* values
+const (
+	codeError      = "error"
+	codeTimeout    = "timeout"
+	codeNoBrowsers = "no_browsers"
+	codeNoEntries  = "no_entries"
+	codeCanceled   = "canceled"
+	codeOffline    = "offline"
+)
+
+var (
+	jsonErrors bool
+	// failEmpty and minEntries make a written export that found too few
+	// entries exit non-zero, with or without --json-errors
+	failEmpty  bool
+	minEntries int
+)
+
+// cliError is a failure with a stable code, written as a JSON object on
+// stderr with --json-errors
+type cliError struct {
+	Code    string `json:"code"`
+	Browser string `json:"browser,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *cliError) Error() string {
+	return e.Message
+}
+
+// browserError tags err, reported for browser b at path, with the code of
+// the underlying cause
+func browserError(err, cause error, b browser.Type, path string) error {
+	return &cliError{Code: database.ErrorCode(cause), Browser: string(b), Path: path, Message: err.Error()}
+}
+
+// exitCode maps an error code to the process exit code
+func exitCode(code string) int {
+	switch code {
+	case codeNoBrowsers, database.CodeNotFound:
+		return exitNoBrowsers
+	case database.CodePermissionDenied:
+		return exitPermission
+	case codeNoEntries:
+		return exitNoEntries
+	case codeCanceled:
+		return exitCanceled
+	default:
+		return exitFailure
+	}
+}
+
+// runOutcome is what a successful export read, checked after the command
+// returns so --json-errors can flag empty and partial results
+type runOutcome struct {
+	entries     int
+	sources     []models.SourceStatus
+	allBrowsers bool
+}
+
+var outcome *runOutcome
+
+// recordOutcome remembers the entries and sources of a written export
+func recordOutcome(entries int, sources []models.SourceStatus) {
+	outcome = &runOutcome{
+		entries:     entries,
+		sources:     sources,
+		allBrowsers: dataSource == sourceBrowser && (allBrowsers || browserType == "auto"),
+	}
+}
+
+// problems returns the errors and exit code for an export that succeeded
+// but read nothing or skipped some browsers
+func (o *runOutcome) problems() ([]*cliError, int) {
+	if o.allBrowsers && len(o.sources) == 0 {
+		return []*cliError{{Code: codeNoBrowsers, Message: "no browsers detected"}}, exitNoBrowsers
+	}
+
+	var errs []*cliError
+	permission := false
+	for _, s := range o.sources {
+		if s.Error == "" {
+			continue
+		}
+		errs = append(errs, &cliError{Code: s.Code, Browser: s.Browser, Path: s.Path, Message: s.Error})
+		permission = permission || s.Code == database.CodePermissionDenied
+	}
+	switch {
+	case len(errs) > 0 && len(errs) < len(o.sources):
+		return errs, exitPartial
+	case len(errs) > 0 && permission:
+		return errs, exitPermission
+	case len(errs) > 0:
+		return errs, exitFailure
+	case o.entries == 0:
+		return []*cliError{{Code: codeNoEntries, Message: "no entries found"}}, exitNoEntries
+	}
+	return nil, 0
+}
+
+// belowThreshold returns the error for an export with fewer entries than
+// --fail-empty or --min-entries require, or nil
+func (o *runOutcome) belowThreshold() *cliError {
+	switch {
+	case minEntries > 0 && o.entries < minEntries:
+		return &cliError{Code: codeNoEntries, Message: fmt.Sprintf("found %d entries, fewer than --min-entries %d", o.entries, minEntries)}
+	case failEmpty && o.entries == 0:
+		return &cliError{Code: codeNoEntries, Message: "no entries found"}
+	}
+	return nil
+}
+
+// warnSources prints the browsers that could not be read. With --json-errors
+// they are reported as JSON once the command finishes instead.
+func warnSources(sources []models.SourceStatus) {
+	if jsonErrors {
+		return
+	}
+	for _, warning := range output.SourceWarnings(sources) {
+		warnf("%s", warning)
+	}
+}
+
+// warnSkippedCommands writes a warning to stderr when session file commands
+// were skipped, unless --json-errors is set
+func warnSkippedCommands(skipped int) {
+	if jsonErrors {
+		return
+	}
+	for _, warning := range output.SessionWarnings(skipped) {
+		warnf("%s", warning)
+	}
+}
+
+// reportErrors writes errs to stderr, as JSON lines with --json-errors
+func reportErrors(errs []*cliError) {
+	enc := json.NewEncoder(os.Stderr)
+	for _, e := range errs {
+		if jsonErrors {
+			enc.Encode(e)
+			continue
+		}
+		errorf("%s", e.Message)
+	}
+}
+
+// exit reports err and exits with its code. A successful run only exits
+// non-zero with --json-errors, for empty or partial results, or with
+// --fail-empty and --min-entries, for too few entries.
+func exit(err error) {
+	if err != nil {
+		var ce *cliError
+		if !errors.As(err, &ce) {
+			ce = &cliError{Code: codeError, Message: err.Error()}
+		}
+		reportErrors([]*cliError{ce})
+		os.Exit(exitCode(ce.Code))
+	}
+	if jsonErrors && outcome != nil {
+		if errs, code := outcome.problems(); code != 0 {
+			reportErrors(errs)
+			os.Exit(code)
+		}
+	}
+	if outcome != nil {
+		if ce := outcome.belowThreshold(); ce != nil {
+			reportErrors([]*cliError{ce})
+			os.Exit(exitNoEntries)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/datasette"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportDays     int
+	exportMetadata string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write history in formats other tools open directly",
+}
+
+var exportDatasetteCmd = &cobra.Command{
+	Use:   "datasette -o recap.db",
+	Short: "Write history to a SQLite database ready to explore with Datasette",
+	Long: `Write history to a SQLite database laid out for Datasette, plus a
+metadata.json file describing it, so it can be explored straight away:
+
+  datasette recap.db -m metadata.json
+
+The database has:
+  visits           one row per visit, with full-text search over titles and URLs
+  sessions         runs of visits with no gap over 30 minutes (visits.session_id)
+  daily_counts     view of visits, domains, pages, and sessions per day
+  top_domains      view of domains by number of visits
+  hourly_activity  view of visits by hour of day
+
+Dates and hours are in your local timezone (or --tz / --utc). The database
+is replaced if it exists. The metadata file is written next to it unless
+--metadata is given.
+
+Examples:
+  web-recap export datasette -o recap.db
+  web-recap export datasette -o 2024.db --start-date 2024-01-01 --end-date 2024-12-31 --source archive
+`,
+	Args: cobra.NoArgs,
+	RunE: runExportDatasette,
+}
+
+func init() {
+	exportDatasetteCmd.Flags().IntVar(&exportDays, "days", 30, "Number of days to export, ending today (ignored with date flags)")
+	exportDatasetteCmd.Flags().StringVar(&exportMetadata, "metadata", "", "Where to write the Datasette metadata (default: metadata.json next to the database)")
+
+	exportCmd.AddCommand(exportDatasetteCmd)
+}
+
+func runExportDatasette(cmd *cobra.Command, args []string) error {
+	if outputFile == "" || outputFile == "-" || remoteOutput() {
+		return fmt.Errorf("export datasette writes a database file; name it with -o, e.g. -o recap.db")
+	}
+	if exportDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	// Default to the last N days including today
+	now := time.Now().In(loc)
+	endTimeValue := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	startTimeValue := endTimeValue.AddDate(0, 0, -exportDays)
+	if date != "" || startDate != "" || endDate != "" {
+		if startTimeValue, endTimeValue, err = resolveTimeRange(loc); err != nil {
+			return err
+		}
+	}
+
+	entries, _, sources, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+	recordOutcome(len(entries), sources)
+
+	outPath := outputPath()
+	dbPath := outPath
+	if encrypting() {
+		// SQLite needs a real file, so the database is built beside the
+		// output and only its encrypted copy kept
+		tmp, err := os.CreateTemp(filepath.Dir(outPath), ".web-recap-*.db")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary database: %v", err)
+		}
+		tmp.Close()
+		dbPath = tmp.Name()
+		defer os.Remove(dbPath)
+	}
+	if err := datasette.Write(dbPath, entries, loc); err != nil {
+		return err
+	}
+	if dbPath != outPath {
+		if err := encryptFile(dbPath, outPath); err != nil {
+			return err
+		}
+	}
+
+	metadataPath := exportMetadata
+	if metadataPath == "" {
+		metadataPath = filepath.Join(filepath.Dir(outPath), "metadata.json")
+	}
+	data, err := json.MarshalIndent(datasette.Metadata(outPath, startTimeValue, endTimeValue, loc), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(metadataPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %v", err)
+	}
+
+	statusf("Wrote %d visits to %s", len(entries), outPath)
+	if encrypting() {
+		statusf("Decrypt it, then explore with: datasette <database> -m %s", metadataPath)
+	} else {
+		statusf("Explore with: datasette %s -m %s", outPath, metadataPath)
+	}
+	return nil
+}
+
+// encryptFile writes an encrypted copy of src to dst for the --encrypt or
+// --encrypt-gpg recipients
+func encryptFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	err = encrypted(func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})(out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", dst, err)
+	}
+	return nil
+}
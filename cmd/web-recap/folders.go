@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var bookmarksFoldersCmd = &cobra.Command{
+	Use:   "folders",
+	Short: "List bookmark folders with counts and date ranges",
+	Long: `List every bookmark folder of each browser, with the number of bookmarks in it
+(directly and including subfolders) and when the first and last were added.
+
+Use a path from the listing with 'web-recap bookmarks --folder'.
+
+Examples:
+  web-recap bookmarks folders                    # All detected browsers
+  web-recap bookmarks folders --browser firefox  # Firefox only
+  web-recap bookmarks folders --folder "Bookmarks Bar/Reading"  # One folder and its subfolders
+`,
+	RunE: runBookmarkFolders,
+}
+
+func runBookmarkFolders(cmd *cobra.Command, args []string) error {
+	entries, browserName, sources, err := queryAllBookmarks(cmd.Context())
+	if err != nil {
+		return err
+	}
+	folders := stats.BookmarkFolders(filterBookmarks(entries))
+	recordOutcome(len(folders), sources)
+
+	report := models.BookmarkFoldersReport{
+		Browser:      browserName,
+		Meta:         newReportMeta(sources),
+		Sources:      sources,
+		Warnings:     output.SourceWarnings(sources),
+		TotalFolders: len(folders),
+		Folders:      folders,
+	}
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatBookmarkFoldersJSON(out, report)
+	})
+}
+
+// queryAllBookmarks reads every bookmark, whatever its date, from the
+// archive or the browsers selected by the flags
+func queryAllBookmarks(ctx context.Context) ([]models.BookmarkEntry, string, []models.SourceStatus, error) {
+	if dataSource == sourceArchive {
+		entries, browserName, err := queryArchiveBookmarks(time.Time{}, time.Time{})
+		return entries, browserName, nil, err
+	}
+
+	if dbPath == "" && (allBrowsers || browserType == "auto") {
+		entries, sources, err := database.QueryMultipleBrowsersBookmarks(ctx, newDetector(), time.Time{}, time.Time{})
+		warnSources(sources)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to query bookmarks: %v", err)
+		}
+		return entries, "all", sources, nil
+	}
+
+	if dbPath != "" && browserType == "auto" {
+		return nil, "", nil, fmt.Errorf("--browser is required when using --db-path")
+	}
+	entries, err := queryBrowserBookmarks(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	sources := []models.SourceStatus{{Browser: browserType, Name: browserType, Path: dbPath, Entries: len(entries)}}
+	return entries, browserType, sources, nil
+}
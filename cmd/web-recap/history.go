@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Extract browser history in LLM-friendly JSON format",
+	Long: `Extract browser history from Chrome, Chromium, Firefox, Safari, Edge, Brave, Vivaldi,
+other Chromium forks (Whale, Samsung Internet, Yandex, Arc), Pale Moon, SeaMonkey, Lynx, and w3m
+and output it in JSON format suitable for analysis by LLMs and other tools.
+
+Date and time inputs are interpreted in your local timezone by default.
+
+Examples:
+  web-recap history                          # Extract today's history from default browser
+  web-recap history --browser chrome         # Extract from Chrome specifically
+  web-recap history --date 2025-12-15        # Extract history from specific date (local time)
+  web-recap history --date 2025-12-15 --time 12           # Extract 12pm hour (12:00-12:59)
+  web-recap history --date 2025-12-15 --start-time 12:00 --end-time 13:00  # Time range
+  web-recap history --tz America/New_York --date 2025-12-15  # Explicit timezone
+  web-recap history --start-date 2025-12-01 --end-date 2025-12-15  # Date range
+  web-recap history --all-browsers -o history.json  # All browsers to file
+  web-recap history --start-date 2025-12-01 --max-tokens 8000  # Fit output into an LLM context window
+  web-recap history --start-date 2025-12-01 --format arrow -o history.arrow  # Arrow IPC for Polars/pandas
+  web-recap history --start-date 2025-12-01 --split-by 5000-tokens -o history.json  # history-001.json, history-002.json, ...
+  web-recap history --start-date 2025-12-01 --end-date 2025-12-31 --canonical -o 2025-12.json  # Byte-identical re-exports
+  web-recap history --all-browsers --merge  # One entry per page visit, with the browsers it was seen in
+  web-recap history --granularity url       # One entry per page, with its visit count in the range
+  web-recap history --collapse 30s          # Fold auto-refreshes and redirect loops into one visit
+  web-recap history --granularity url --fetch-content  # Add each page's description and text for an LLM
+  web-recap history --incremental -o "history-$(date +%s).json"  # Hourly cron: only entries since the last run
+  web-recap history --since-last-run            # Everything since the previous --since-last-run ended
+`,
+	RunE: runWeb,
+}
+
+// runRootHistory runs history for a bare 'web-recap', noting on an
+// interactive terminal that the history subcommand is preferred
+func runRootHistory(cmd *cobra.Command, args []string) error {
+	if !jsonErrors && term.IsTerminal(int(os.Stderr.Fd())) {
+		statusf("Note: running web-recap without a subcommand is deprecated; use 'web-recap history'")
+	}
+	return runWeb(cmd, args)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/config"
+	"github.com/rzolkos/web-recap/internal/firefoxsync"
+	"github.com/rzolkos/web-recap/internal/takeout"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var syncSkipBookmarks bool
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add history exported elsewhere to the archive",
+}
+
+var importTakeoutCmd = &cobra.Command{
+	Use:   "takeout <BrowserHistory.json>",
+	Short: "Add Chrome history from a Google Takeout export to the archive",
+	Long: `Add the Chrome history in a Google Takeout export to the archive, recovering
+visits older than the ~90 days Chrome keeps locally. Export "Chrome" from
+https://takeout.google.com and pass the Chrome/BrowserHistory.json file from
+the unpacked archive.
+
+Visits are archived as Chrome history (or --browser), so they show up in
+--source archive queries alongside synced visits. Visits already in the
+archive, e.g. from 'archive sync', are skipped, so importing overlapping
+exports is safe. Importing does not change what the next sync reads.
+
+Entries are labelled with --source-label, or "takeout" when it is not set.
+
+Examples:
+  web-recap import takeout ~/Downloads/Takeout/Chrome/BrowserHistory.json
+  web-recap --source archive --start-date 2021-01-01 --end-date 2021-12-31
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportTakeout,
+}
+
+var importFirefoxSyncCmd = &cobra.Command{
+	Use:   "firefox-sync",
+	Short: "Add history and bookmarks from Firefox Sync to the archive",
+	Long: `Sign in to a Mozilla account and add the history and bookmarks stored in
+Firefox Sync to the archive. This captures browsing from devices web-recap
+does not run on, such as phones, as long as they sync to the same account.
+
+Credentials come from the config file or the environment:
+  {"firefox_sync": {"email": "...", "password": "..."}}  or FXA_EMAIL / FXA_PASSWORD
+
+The first sign-in must be confirmed with a code Mozilla emails, or one from an
+authenticator app, so run it in a terminal. The session is then saved to
+web-recap/firefox-sync.json in the user config directory and reused, so later
+runs (e.g. from cron) need no password or code. The session file unlocks all
+of the account's Sync data; delete it to sign out.
+
+Visits and bookmarks are archived as Firefox (or --browser) and labelled with
+--source-label, or "firefox-sync" when it is not set. Sync does not record
+which device made a visit; visits already archived from this machine's Firefox
+are skipped, so those left are from other devices. The devices signed in to
+the account are listed when the import finishes.
+
+Examples:
+  web-recap import firefox-sync
+  web-recap import firefox-sync --skip-bookmarks --source-label phone
+`,
+	Args: cobra.NoArgs,
+	RunE: runImportFirefoxSync,
+}
+
+func init() {
+	importFirefoxSyncCmd.Flags().BoolVar(&syncSkipBookmarks, "skip-bookmarks", false, "Only import history")
+
+	importCmd.AddCommand(importTakeoutCmd)
+	importCmd.AddCommand(importFirefoxSyncCmd)
+}
+
+func runImportTakeout(cmd *cobra.Command, args []string) error {
+	if len(selectedBrowsers) > 0 {
+		return fmt.Errorf("import takeout archives visits under one browser; pass a single --browser")
+	}
+	browserName := string(browser.Chrome)
+	if browserType != "auto" {
+		browserName = browserType
+	}
+	label := sourceLabel
+	if label == "" {
+		label = "takeout"
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open Takeout file: %v", err)
+	}
+	defer f.Close()
+	entries, err := takeout.ReadBrowserHistory(f, browserName)
+	if err != nil {
+		return err
+	}
+	entries = blocked.History(entries)
+	for i := range entries {
+		entries[i].Source = label
+	}
+
+	path, err := resolveArchivePath()
+	if err != nil {
+		return err
+	}
+	a, err := archive.Open(path)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	added, err := a.ImportVisits(browserName, archive.KindTakeout, entries)
+	if err != nil {
+		return fmt.Errorf("failed to import visits: %v", err)
+	}
+	statusf("%s: %d visits read, %d new", browserName, len(entries), added)
+	statusf("Archive: %s", a.Path())
+	return nil
+}
+
+func runImportFirefoxSync(cmd *cobra.Command, args []string) error {
+	if len(selectedBrowsers) > 0 {
+		return fmt.Errorf("import firefox-sync archives entries under one browser; pass a single --browser")
+	}
+	browserName := string(browser.Firefox)
+	if browserType != "auto" {
+		browserName = browserType
+	}
+	label := sourceLabel
+	if label == "" {
+		label = "firefox-sync"
+	}
+
+	client, err := connectFirefoxSync(cmd.Context())
+	if err != nil {
+		return err
+	}
+	visits, err := client.History(cmd.Context(), browserName)
+	if err != nil {
+		return err
+	}
+	visits = blocked.History(visits)
+	for i := range visits {
+		visits[i].Source = label
+	}
+
+	path, err := resolveArchivePath()
+	if err != nil {
+		return err
+	}
+	a, err := archive.Open(path)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	added, err := a.ImportVisits(browserName, archive.KindFirefoxSync, visits)
+	if err != nil {
+		return fmt.Errorf("failed to import visits: %v", err)
+	}
+	statusf("%s: %d visits read, %d new", browserName, len(visits), added)
+
+	if !syncSkipBookmarks {
+		bookmarks, err := client.Bookmarks(cmd.Context(), browserName)
+		if err != nil {
+			return err
+		}
+		bookmarks = blocked.Bookmarks(bookmarks)
+		for i := range bookmarks {
+			bookmarks[i].Source = label
+		}
+		added, err := a.AddBookmarks(browserName, bookmarks)
+		if err != nil {
+			return fmt.Errorf("failed to import bookmarks: %v", err)
+		}
+		statusf("%s: %d bookmarks read, %d new", browserName, len(bookmarks), added)
+	}
+
+	if devices, err := client.Devices(cmd.Context()); err != nil {
+		warnf("%v", err)
+	} else {
+		for _, d := range devices {
+			statusf("Device: %s (%s)", d.Name, d.Type)
+		}
+	}
+	statusf("Archive: %s", a.Path())
+	return nil
+}
+
+// connectFirefoxSync connects with the saved session, signing in again when
+// there is none or it has expired
+func connectFirefoxSync(ctx context.Context) (*firefoxsync.Client, error) {
+	sessionPath, err := firefoxsync.DefaultSessionPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate Firefox Sync session: %v", err)
+	}
+	session, err := firefoxsync.LoadSession(sessionPath)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil {
+		client, err := firefoxsync.Connect(ctx, session)
+		if !errors.Is(err, firefoxsync.ErrSessionExpired) {
+			return client, err
+		}
+		statusf("The saved Firefox Sync session has expired; signing in again")
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var prompt firefoxsync.Prompt
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		reader := bufio.NewReader(os.Stdin)
+		prompt = func(message string) (string, error) {
+			fmt.Fprint(os.Stderr, message)
+			return reader.ReadString('\n')
+		}
+	}
+	if session, err = firefoxsync.Login(ctx, cfg.FirefoxSync.WithEnv(), prompt); err != nil {
+		return nil, err
+	}
+	if err := session.Save(sessionPath); err != nil {
+		return nil, err
+	}
+	return firefoxsync.Connect(ctx, session)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var journeyMinVisits int
+
+var journeysCmd = &cobra.Command{
+	Use:   "journeys",
+	Short: "Export Chrome's Journeys: its own topical clusters of history visits",
+	Long: `Export the Journeys clusters Chrome builds from your history. Each journey is a
+topic Chrome grouped visits under, with its label, keywords, and the visits in it.
+
+Chrome keeps these in the History database (clusters, clusters_and_visits,
+cluster_keywords) while Journeys is enabled. Only Chromium-based browsers
+(Chrome, Chromium, Edge, Brave, Vivaldi) have them. With --browser auto (the
+default) every detected Chromium browser is read.
+
+Only visits in the date range are included, so a journey that started earlier
+is cut at the start of the range.
+
+Examples:
+  web-recap journeys                              # Today
+  web-recap journeys --start-date 2025-12-01 --end-date 2025-12-07 --min-visits 3
+  web-recap journeys --browser edge -o journeys.json
+`,
+	RunE: runJourneys,
+}
+
+func init() {
+	journeysCmd.Flags().IntVar(&journeyMinVisits, "min-visits", 1, "Leave out journeys with fewer visits than this in the range")
+}
+
+func runJourneys(cmd *cobra.Command, args []string) error {
+	if dataSource == sourceArchive {
+		return fmt.Errorf("journeys reads Chrome's own cluster tables and cannot use --source archive")
+	}
+
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+	startTimeValue, endTimeValue, err := resolveTimeRange(loc)
+	if err != nil {
+		return err
+	}
+	startTimeValue = startTimeValue.UTC()
+	endTimeValue = endTimeValue.UTC()
+
+	browsers, browserName, err := chromiumBrowsers("journeys")
+	if err != nil {
+		return err
+	}
+
+	var journeys []models.Journey
+	sources := make([]models.SourceStatus, len(browsers))
+	for i := range browsers {
+		b := &browsers[i]
+		sources[i] = models.SourceStatus{Browser: string(b.Type), Name: b.Name, Path: b.Path}
+		found, err := database.QueryJourneys(cmd.Context(), b, startTimeValue, endTimeValue)
+		if err != nil {
+			if len(browsers) == 1 || cmd.Context().Err() != nil {
+				if errors.Is(err, database.ErrNoJourneys) {
+					return err
+				}
+				return browserError(fmt.Errorf("failed to query journeys: %v", err), err, b.Type, b.Path)
+			}
+			sources[i].Error = err.Error()
+			sources[i].Code = database.ErrorCode(err)
+			continue
+		}
+		found = keepJourneys(found)
+		sources[i].Entries = len(found)
+		journeys = append(journeys, found...)
+	}
+	warnSources(sources)
+	output.SortJourneys(journeys)
+
+	if redacting {
+		redact.Journeys(journeys, redactMode)
+	}
+
+	report := models.JourneyReport{
+		Browser:       browserName,
+		StartDate:     startTimeValue,
+		EndDate:       endTimeValue,
+		Timezone:      reportTimezone(),
+		Meta:          newReportMeta(sources),
+		Sources:       sources,
+		Warnings:      output.SourceWarnings(sources),
+		TotalJourneys: len(journeys),
+		Journeys:      journeys,
+	}
+	if err := writeOutput(func(out io.Writer) error {
+		return output.FormatJourneysJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(len(journeys), sources)
+	return nil
+}
+
+// keepJourneys drops the visits to --blocklist domains and the journeys left
+// with fewer than --min-visits visits
+func keepJourneys(journeys []models.Journey) []models.Journey {
+	kept := journeys[:0]
+	for _, j := range journeys {
+		visits := j.Visits[:0]
+		for _, v := range j.Visits {
+			if !blocked.Blocks(v.Domain) {
+				visits = append(visits, v)
+			}
+		}
+		if len(visits) == 0 || len(visits) < journeyMinVisits {
+			continue
+		}
+		j.Visits = visits
+		j.StartTime, j.EndTime = visits[0].Timestamp, visits[len(visits)-1].Timestamp
+		kept = append(kept, j)
+	}
+	return kept
+}
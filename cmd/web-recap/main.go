@@ -1,36 +1,110 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/blocklist"
 	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/budget"
+	"github.com/rzolkos/web-recap/internal/config"
+	"github.com/rzolkos/web-recap/internal/content"
 	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/demo"
+	"github.com/rzolkos/web-recap/internal/incremental"
+	"github.com/rzolkos/web-recap/internal/lang"
 	"github.com/rzolkos/web-recap/internal/models"
 	"github.com/rzolkos/web-recap/internal/output"
 	"github.com/rzolkos/web-recap/internal/readinglist"
+	"github.com/rzolkos/web-recap/internal/redact"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/rzolkos/web-recap/internal/urlutil"
 	"github.com/rzolkos/web-recap/internal/twitter"
 	"github.com/rzolkos/web-recap/internal/youtube"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"google.golang.org/api/option"
 )
 
 var (
-	browserType string
-	date        string
-	startDate   string
-	endDate     string
-	startTime   string
-	endTime     string
-	timeHour    string
-	timezone    string
-	utcMode     bool
-	outputFile  string
-	dbPath      string
-	allBrowsers bool
-	version     = "0.1.0-alpha"
+	browserType     string
+	date            string
+	startDate       string
+	endDate         string
+	startTime       string
+	endTime         string
+	timeHour        string
+	timezone        string
+	displayTZ       string
+	utcMode         bool
+	outputFile      string
+	postURL         string
+	postToken       string
+	postRetries     int
+	uploadDest      string
+	ageRecipients   []string
+	gpgRecipients   []string
+	commandTimeout  time.Duration
+	dbPath          string
+	cacheDir        string
+	allBrowsers     bool
+	maxTokens       int
+	configPath      string
+	sourceLabel     string
+	redactFlag      string
+	blocklistPath   string
+	dataSource      string
+	archivePath     string
+	format          string
+	splitBy         string
+	canonical       bool
+	mergeMode       bool
+	granularity     string
+	collapseWindow  time.Duration
+	historyGroupBy  string
+	fetchContent    bool
+	fetchWorkers    int
+	fetchMaxText    int
+	demoMode        bool
+	incrementalMode bool
+	sinceLastRun    bool
+	statePath       string
+	version         = "0.1.0-alpha"
+	// redactMode is the parsed --redact; redacting is set when it was given
+	redactMode redact.Mode
+	redacting  bool
+	// blocked holds the domains --blocklist leaves out; nil blocks nothing
+	blocked *blocklist.List
+	// domainAliases are the config file's rollups of hosts into services
+	domainAliases stats.Aliases
+	// langFilter keeps the --lang languages; nil keeps everything
+	langCodes      []string
+	langFilter     *lang.Filter
+	detectLanguage bool
+	// canonicalizeURLs is --canonicalize
+	canonicalizeURLs bool
+	// excludeInternal is --exclude-internal
+	excludeInternal bool
+	// Tabs and bookmarks flags
+	noTitleBackfill bool
+	bookmarkTree    bool
+	tabGroupBy      string
+	tabStale        string
+	tabFormat       string
+	bookmarkFormat  string
+	bookmarkFolder  string
+	maxSessionSize  int
+	cloudTabs       bool
 	// Reading list flags
 	platform     string
 	sessionToken string
@@ -68,27 +142,109 @@ var (
 var rootCmd = &cobra.Command{
 	Use:   "web-recap",
 	Short: "Extract browser history in LLM-friendly JSON format",
-	Long: `web-recap extracts browser history from Chrome, Chromium, Firefox, Safari, Edge, Brave, and Vivaldi
+	Long: `web-recap extracts browser history from Chrome, Chromium, Firefox, Safari, Edge, Brave, Vivaldi,
+other Chromium forks (Whale, Samsung Internet, Yandex, Arc), Pale Moon, SeaMonkey, Lynx, and w3m
 and outputs it in JSON format suitable for analysis by LLMs and other tools.
 
 Date and time inputs are interpreted in your local timezone by default.
 
-Examples:
-  web-recap                          # Extract today's history from default browser
-  web-recap --browser chrome         # Extract from Chrome specifically
-  web-recap --date 2025-12-15        # Extract history from specific date (local time)
-  web-recap --date 2025-12-15 --time 12           # Extract 12pm hour (12:00-12:59)
-  web-recap --date 2025-12-15 --start-time 12:00 --end-time 13:00  # Time range
-  web-recap --tz America/New_York --date 2025-12-15  # Explicit timezone
-  web-recap --start-date 2025-12-01 --end-date 2025-12-15  # Date range
-  web-recap --all-browsers -o history.json  # All browsers to file
+Running web-recap without a subcommand extracts history like 'web-recap history'.
+That form is deprecated and kept as an alias. New history options may only be
+added to 'web-recap history'.
+
+See 'web-recap history --help' for history examples.
 `,
-	RunE: runWeb,
+	PersistentPreRunE: loadConfig,
+	RunE:              runRootHistory,
+}
+
+// loadConfig registers the browsers defined in the config file and applies
+// config defaults for flags that were not set
+func loadConfig(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("source-label") && !demoMode {
+		sourceLabel = cfg.SourceLabel
+	}
+	if jsonErrors || quietMode {
+		// Keep stderr parseable, or to the error alone: main reports it
+		cmd.Root().SilenceErrors = true
+		cmd.Root().SilenceUsage = true
+	}
+	if err := validateSource(); err != nil {
+		return err
+	}
+	if minEntries < 0 {
+		return fmt.Errorf("--min-entries must not be negative")
+	}
+	if !cmd.Flags().Changed("offline") {
+		offlineMode = cfg.Offline
+	}
+	if offlineMode {
+		if err := enableOffline(cmd); err != nil {
+			return err
+		}
+	}
+	if !cmd.Flags().Changed("blocklist") {
+		blocklistPath = cfg.Blocklist
+	}
+	if blocked, err = blocklist.Load(blocklistPath); err != nil {
+		return err
+	}
+	if domainAliases, err = stats.NewAliases(cfg.Aliases); err != nil {
+		return fmt.Errorf("config file: %v", err)
+	}
+	if cmd.Flags().Changed("redact") {
+		if redactMode, err = redact.ParseMode(redactFlag); err != nil {
+			return err
+		}
+		redacting = true
+	}
+	if langFilter, err = lang.ParseFilter(langCodes); err != nil {
+		return err
+	}
+	recordQuery(cmd)
+	if cacheDir != "" {
+		if err := database.SetCacheDir(cacheDir); err != nil {
+			return err
+		}
+	}
+	if displayTZ != "" {
+		if displayLoc, err = getTimezone(displayTZ, false); err != nil {
+			return fmt.Errorf("--display-tz: %v", err)
+		}
+	}
+	if commandTimeout > 0 && cmd != serveCmd {
+		timeoutCtx, cancelTimeout = context.WithTimeoutCause(cmd.Context(), commandTimeout,
+			fmt.Errorf("timed out after %s", commandTimeout))
+		cmd.SetContext(timeoutCtx)
+	}
+	if err := cfg.RegisterBrowsers(); err != nil {
+		return err
+	}
+	return selectBrowsers()
+}
+
+// History filter flags and --redact, registered on history and on each
+// command that reads history rather than on the root, so they are not
+// offered by bookmarks and the rest
+var (
+	historyFilterFlags = pflag.NewFlagSet("history filters", pflag.ContinueOnError)
+	redactFlags        = pflag.NewFlagSet("redact", pflag.ContinueOnError)
+)
+
+// historyReaders are the commands besides history that read history
+var historyReaders = []*cobra.Command{
+	activityWatchCmd, digestCmd, exportDatasetteCmd, pickCmd, recapCmd, siteCmd, statsCmd,
+	streaksCmd, summarizeCmd, tuiCmd, visitedCmd, watchCmd,
 }
 
 func init() {
 	// Persistent flags available to all subcommands
-	rootCmd.PersistentFlags().StringVarP(&browserType, "browser", "b", "auto", "Browser type: auto, chrome, chromium, edge, brave, vivaldi, firefox, or safari")
+	rootCmd.PersistentFlags().StringSliceVarP(&browserList, "browser", "b", []string{"auto"}, "Browser type: auto, chrome, chromium, edge, brave, vivaldi, whale, samsung, yandex, arc, firefox, palemoon, seamonkey, safari, lynx, w3m, or one defined in the config file; several as a comma list or repeated flag")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeList, "exclude-browser", nil, "Leave these browsers out when reading several (comma list or repeated flag)")
 	rootCmd.PersistentFlags().StringVar(&date, "date", "", "Specific date (YYYY-MM-DD, interpreted in local timezone)")
 	rootCmd.PersistentFlags().StringVar(&startDate, "start-date", "", "Start date (YYYY-MM-DD, interpreted in local timezone)")
 	rootCmd.PersistentFlags().StringVar(&endDate, "end-date", "", "End date (YYYY-MM-DD, interpreted in local timezone)")
@@ -97,25 +253,148 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&timeHour, "time", "", "Time hour shorthand (e.g., '12' for 12:00-12:59)")
 	rootCmd.PersistentFlags().StringVar(&timezone, "tz", "", "Timezone (e.g., America/New_York, UTC, local for system timezone)")
 	rootCmd.PersistentFlags().BoolVar(&utcMode, "utc", false, "Treat all dates/times as UTC instead of local timezone")
-	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
+	rootCmd.PersistentFlags().StringVar(&displayTZ, "display-tz", "", "Write history and bookmark timestamps with this timezone's offset instead of UTC (IANA name or local)")
+	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Output file, http(s) URL to POST to, or s3://, gs://, az:// URL to upload to (default: stdout)")
+	rootCmd.PersistentFlags().StringVar(&postURL, "post-url", "", "POST the output to this URL instead of writing it (also used when --output is an http(s) URL)")
+	rootCmd.PersistentFlags().StringVar(&postToken, "post-token", "", "Bearer token for --post-url (default: WEB_RECAP_POST_TOKEN)")
+	rootCmd.PersistentFlags().IntVar(&postRetries, "post-retries", 3, "Retries with exponential backoff when the post fails with a network error, 429, or 5xx")
+	rootCmd.PersistentFlags().StringVar(&uploadDest, "upload", "", "Upload the output to object storage: s3://bucket/prefix/, gs://bucket/prefix/, or az://container/prefix/ (also used when --output is such a URL)")
+	rootCmd.PersistentFlags().StringSliceVar(&ageRecipients, "encrypt", nil, "Encrypt the output for these age recipients (age1...; comma list or repeated flag)")
+	rootCmd.PersistentFlags().StringSliceVar(&gpgRecipients, "encrypt-gpg", nil, "Encrypt the output with gpg for these key IDs, fingerprints, or emails in your keyring")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "Give up after this long, e.g. 30s or 2m (default: no limit; not applied to serve)")
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json-errors", false, "Write errors to stderr as JSON objects {code, browser, path, message} and exit non-zero for empty or partial results")
+	rootCmd.PersistentFlags().BoolVar(&quietMode, "quiet", false, "Only write errors to stderr: no warnings, notes, or status lines")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Never color stderr (it is only colored on a terminal, and not when NO_COLOR is set)")
+	rootCmd.PersistentFlags().BoolVar(&failEmpty, "fail-empty", false, "Exit with code 4 when an export finds no entries (the output is still written)")
+	rootCmd.PersistentFlags().IntVar(&minEntries, "min-entries", 0, "Exit with code 4 when an export finds fewer than N entries (the output is still written)")
 	rootCmd.PersistentFlags().StringVar(&dbPath, "db-path", "", "Custom database path")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Keep copies of locked databases in this directory and reuse them while the database is unchanged")
 	rootCmd.PersistentFlags().BoolVar(&allBrowsers, "all-browsers", false, "Extract from all detected browsers")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file path (default: web-recap/config.json in the user config directory)")
+	rootCmd.PersistentFlags().StringVar(&dataSource, "source", sourceBrowser, "Where to read history and bookmarks: browser or archive (see 'web-recap archive')")
+	rootCmd.PersistentFlags().StringVar(&archivePath, "archive", "", "Archive file path (default: web-recap/archive.db in the user config directory)")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Refuse every feature that uses the network (LLM calls, posts, uploads, digests, sync) and block all HTTP requests (default: offline from the config file)")
+	rootCmd.PersistentFlags().StringVar(&blocklistPath, "blocklist", "", "File of domains or globs, one per line, left out of every command (default: blocklist from the config file, or web-recap/blocklist.txt in the user config directory if it exists)")
+	rootCmd.PersistentFlags().StringVar(&sourceLabel, "source-label", "", "Label recorded on every entry and in report metadata, e.g. the machine name (default: source_label from the config file)")
+	historyCmd.Flags().StringVar(&format, "format", "json", "History output format: json, arrow (Arrow IPC / Feather v2), browserexport (browserexport/promnesia visits), or the streamed jsonl, csv, and compact (one-line JSON)")
+	historyCmd.Flags().StringVar(&splitBy, "split-by", "", "Write numbered output files instead of one: 'day' or '<N>-tokens' (e.g. 5000-tokens)")
+	historyCmd.Flags().BoolVar(&canonical, "canonical", false, "Deterministic output: UTC microsecond timestamps, entries ordered by time (newest first) then URL")
+	historyCmd.Flags().BoolVar(&mergeMode, "merge", false, "Collapse visits to the same URL in the same minute across browsers into one entry with a browsers list")
+	historyCmd.Flags().StringVar(&granularity, "granularity", "visit", "History entries: visit (one per visit, in order) or url (one per URL and browser, its latest visit with visit_count counting the visits in range)")
+	historyCmd.Flags().DurationVar(&collapseWindow, "collapse", 0, "Merge repeat visits to the same URL each within this long of the last (e.g. 30s) into one entry with a repeats count")
+	historyCmd.Flags().StringVar(&historyGroupBy, "group-by", "", "Group related visits: task orders visits opened from the same originating tab together; domain, site (registrable domain), hour, category, or session nests entries in groups with per-group counts (JSON only)")
+	historyCmd.Flags().BoolVar(&fetchContent, "fetch-content", false, "Fetch each unique URL and add its meta description and readable text to the entries (uses the network; pages are cached for a week)")
+	historyCmd.Flags().IntVar(&fetchWorkers, "fetch-concurrency", 4, "Pages fetched at once with --fetch-content")
+	historyCmd.Flags().IntVar(&fetchMaxText, "fetch-max-text", 2000, "Characters of text kept per page with --fetch-content (0: no limit)")
+	historyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the browsers, files, time range, filters, and outputs the export would use, as JSON, without reading any database")
+	historyCmd.Flags().BoolVar(&incrementalMode, "incremental", false, "Only emit entries newer than the previous --incremental run (per browser)")
+	historyCmd.Flags().BoolVar(&sinceLastRun, "since-last-run", false, "Only emit entries since the previous --since-last-run run ended (per browser); the first run exports today so far")
+	historyCmd.Flags().StringVar(&statePath, "state", "", "State file for --incremental and --since-last-run (default: web-recap/state.json in the user cache directory)")
+	historyCmd.Flags().BoolVar(&demoMode, "demo", false, "Write a report of made-up browsing on fake domains instead of reading any browser, to share as an example")
+	historyCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Reduce history output to fit an estimated token budget (dedupe, trim, collapse domains, then truncate)")
+	// The history filters and --redact also apply to the other commands that
+	// read history
+	historyFilterFlags.StringSliceVar(&langCodes, "lang", nil, "Only history entries whose title is in these languages (ISO 639-1, e.g. en,de; und keeps titles whose language can't be detected); adds a language field")
+	historyFilterFlags.BoolVar(&detectLanguage, "detect-language", false, "Add the language detected from each history entry's title, without filtering")
+	historyFilterFlags.BoolVar(&excludeInternal, "exclude-internal", true, "Leave browser pages (chrome://, brave://, edge://, about:, extensions) and sponsored ad clicks and Brave Ads/Rewards hits out of history; --exclude-internal=false keeps them")
+	historyFilterFlags.BoolVar(&canonicalizeURLs, "canonicalize", false, "Rewrite AMP and mobile history URLs (amp., m., /amp/, Google AMP cache) to their desktop form, so the same page is counted once")
+	redactFlags.StringVar(&redactFlag, "redact", "", "Redact history and tab URLs: hash, domain-only, or path-trim; emails and tokens in query strings and titles are scrubbed with any mode")
+	historyCmd.Flags().AddFlagSet(historyFilterFlags)
+	historyCmd.Flags().AddFlagSet(redactFlags)
+	for _, c := range historyReaders {
+		c.Flags().AddFlagSet(historyFilterFlags)
+		c.Flags().AddFlagSet(redactFlags)
+	}
+	tabsCmd.Flags().AddFlagSet(redactFlags)
+	journeysCmd.Flags().AddFlagSet(redactFlags)
+	vivaldiCmd.PersistentFlags().AddFlagSet(redactFlags)
+	// The history flags are shared with the root, which is an alias for it
+	rootCmd.Flags().AddFlagSet(historyCmd.Flags())
 
+	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(listCmd)
+	bookmarksCmd.Flags().BoolVar(&noTitleBackfill, "no-title-backfill", false, "Don't fill in missing titles from browser history")
+	bookmarksCmd.PersistentFlags().StringVar(&bookmarkFolder, "folder", "", "Only bookmarks in this folder and its subfolders, e.g. 'Bookmarks Bar/Reading' (see 'bookmarks folders')")
+	bookmarksCmd.Flags().BoolVar(&bookmarkTree, "tree", false, "Nest bookmarks in their folders, one tree per browser, instead of a flat list")
+	bookmarksCmd.Flags().StringVar(&bookmarkFormat, "format", "json", "Bookmark output format: json, or pinboard-json (Pinboard's export format, with folders as tags)")
+	tabsCmd.Flags().BoolVar(&noTitleBackfill, "no-title-backfill", false, "Don't fill in missing titles from browser history")
+	tabsCmd.Flags().StringVar(&tabGroupBy, "group-by", "", "Nest tabs in their windows: window (with tab groups and each window's active tab)")
+	tabsCmd.Flags().StringVar(&tabStale, "stale", "", "Only tabs not focused for this long (e.g. 7d, 2w), least recently focused first")
+	tabsCmd.Flags().StringVar(&tabFormat, "format", "json", "Tab output format: json, urls (one URL per line), or restore-html (a page of links by window and tab group)")
+	tabsCmd.Flags().BoolVar(&cloudTabs, "include-cloud", false, "Add the tabs open on your other Apple devices, from Safari's iCloud tabs (macOS only)")
+	tabsCmd.PersistentFlags().IntVar(&maxSessionSize, "max-session-size", 64, "Refuse session files larger than this many MB (0: no limit)")
+
 	rootCmd.AddCommand(bookmarksCmd)
+	bookmarksCmd.AddCommand(bookmarksFoldersCmd)
+	bookmarksCmd.AddCommand(bookmarksPushCmd)
 	rootCmd.AddCommand(tabsCmd)
+	tabsCmd.AddCommand(tabsTriageCmd)
 	rootCmd.AddCommand(readingListCmd)
 	rootCmd.AddCommand(youtubeWatchLaterCmd)
 	rootCmd.AddCommand(youtubeCopyPlaylistCmd)
 	rootCmd.AddCommand(twitterBookmarksCmd)
+	rootCmd.AddCommand(streaksCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(digestCmd)
+	rootCmd.AddCommand(summarizeCmd)
+	rootCmd.AddCommand(recapCmd)
+	rootCmd.AddCommand(siteCmd)
+	rootCmd.AddCommand(timeOnSiteCmd)
+	rootCmd.AddCommand(journeysCmd)
+	rootCmd.AddCommand(vivaldiCmd)
+	rootCmd.AddCommand(visitedCmd)
+	rootCmd.AddCommand(capabilitiesCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(tuiCmd)
+	rootCmd.AddCommand(pickCmd)
+	rootCmd.AddCommand(combineCmd)
+	rootCmd.AddCommand(diffExportsCmd)
+	rootCmd.AddCommand(activityWatchCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
 }
 
+// The --display-tz location, set by loadConfig; nil keeps UTC
+var displayLoc *time.Location
+
+// The --timeout context, set by loadConfig
+var (
+	timeoutCtx    context.Context
+	cancelTimeout context.CancelFunc = func() {}
+)
+
+// The context an interrupt cancels, set by main
+var interruptCtx context.Context
+
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// An interrupt cancels the command instead of killing the process, so
+	// the temporary database copies are still removed. A second one kills it.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Restore the default handling once the first signal arrives
+		<-ctx.Done()
+		stop()
+	}()
+	interruptCtx = ctx
+	defer database.CleanupTemp() // when a command panics
+
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+	cancelTimeout()
+	database.CleanupTemp()
+
+	switch {
+	case err != nil && interrupted:
+		err = &cliError{Code: codeCanceled, Message: fmt.Sprintf("interrupted: %v", err)}
+	case err != nil && timeoutCtx != nil && timeoutCtx.Err() == context.DeadlineExceeded:
+		err = &cliError{Code: codeTimeout, Message: fmt.Sprintf("%v: %v", context.Cause(timeoutCtx), err)}
 	}
+	exit(err)
 }
 
 // getTimezone returns the appropriate timezone based on flags
@@ -139,6 +418,34 @@ func getTimezone(tzFlag string, utcFlag bool) (*time.Location, error) {
 	return time.Local, nil
 }
 
+// reportTimezone names the timezone dates and times were interpreted in, for
+// report metadata
+func reportTimezone() string {
+	switch {
+	case utcMode:
+		return "UTC"
+	case timezone != "" && timezone != "local":
+		return timezone
+	default:
+		return localZoneName()
+	}
+}
+
+// localZoneName names the system timezone: $TZ, the zoneinfo file
+// /etc/localtime links to, or else the zone abbreviation
+func localZoneName() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		return tz
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+			return name
+		}
+	}
+	name, _ := time.Now().Zone()
+	return name
+}
+
 // parseDateTimeInLocation parses a date and optional time in a specific timezone
 func parseDateTimeInLocation(dateStr, timeStr string, loc *time.Location) (time.Time, error) {
 	if dateStr == "" {
@@ -184,22 +491,365 @@ func runWeb(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	switch format {
+	case "json":
+	case "arrow", "feather", "browserexport":
+		if maxTokens > 0 {
+			return fmt.Errorf("--max-tokens is only supported with --format json")
+		}
+	case "jsonl", "csv", "compact":
+		if maxTokens > 0 || splitBy != "" || canonical || mergeMode || granularity == "url" || fetchContent || collapseWindow > 0 || historyGroupBy != "" {
+			return fmt.Errorf("--max-tokens, --split-by, --canonical, --merge, --granularity url, --collapse, --group-by, and --fetch-content are not supported with --format %s", format)
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s (use json, arrow, browserexport, jsonl, csv, or compact)", format)
+	}
+
+	if canonical && displayLoc != nil {
+		return fmt.Errorf("--canonical writes UTC timestamps and cannot be combined with --display-tz")
+	}
+	if granularity != "visit" && granularity != "url" {
+		return fmt.Errorf("invalid --granularity %q (use visit or url)", granularity)
+	}
+	switch historyGroupBy {
+	case "", "task":
+	case "domain", "site", "hour", "category", "session":
+		if format != "json" {
+			return fmt.Errorf("--group-by %s is only supported with --format json", historyGroupBy)
+		}
+		if splitBy != "" || maxTokens > 0 {
+			return fmt.Errorf("--group-by %s cannot be combined with --split-by or --max-tokens", historyGroupBy)
+		}
+	default:
+		return fmt.Errorf("invalid --group-by %q (use task, domain, site, hour, category, or session)", historyGroupBy)
+	}
+	if historyGroupBy != "" && canonical {
+		return fmt.Errorf("--canonical orders entries by time and cannot be combined with --group-by")
+	}
+	if fetchContent && redacting {
+		return fmt.Errorf("--fetch-content cannot be combined with --redact")
+	}
+	if sinceLastRun {
+		switch {
+		case incrementalMode:
+			return fmt.Errorf("--since-last-run and --incremental keep different marks; use one of them")
+		case dataSource == sourceArchive:
+			return fmt.Errorf("--since-last-run records browser reads and cannot use --source archive (use --incremental)")
+		case date != "" || startDate != "" || endDate != "":
+			return fmt.Errorf("--since-last-run picks the range itself and cannot be combined with --date, --start-date, or --end-date")
+		}
+	}
+	if demoMode && (dataSource == sourceArchive || incrementalMode || sinceLastRun || fetchContent) {
+		return fmt.Errorf("--demo cannot be combined with --source archive, --incremental, --since-last-run, or --fetch-content")
+	}
+
+	splitTokens, err := parseSplitBy(splitBy)
+	if err != nil {
+		return err
+	}
+	if splitTokens > 0 && format != "json" {
+		return fmt.Errorf("--split-by N-tokens is only supported with --format json")
+	}
+	if splitBy != "" && remoteOutput() {
+		return fmt.Errorf("--split-by writes files and cannot be combined with --post-url, --upload, or a URL --output")
+	}
+
+	startTimeValue, endTimeValue, err := resolveTimeRange(loc)
+	if err != nil {
+		return err
+	}
+
+	var state *incremental.State
+	if incrementalMode || sinceLastRun {
+		if statePath == "" {
+			if statePath, err = incremental.DefaultPath(); err != nil {
+				return fmt.Errorf("failed to locate state file: %v", err)
+			}
+		}
+		if state, err = incremental.Load(statePath); err != nil {
+			return err
+		}
+		state.ByRun = sinceLastRun
+		// Without date flags, pick up everything since the previous run
+		if since := state.Since(); !since.IsZero() && date == "" && startDate == "" && endDate == "" {
+			startTimeValue = since
+			endTimeValue = time.Now()
+		} else if sinceLastRun {
+			// The first run stops now, where the next one starts
+			endTimeValue = time.Now()
+		}
+	}
+
+	// Convert to UTC for database query (important!)
+	startTimeValue = startTimeValue.UTC()
+	endTimeValue = endTimeValue.UTC()
+
+	if dryRun {
+		stateFile := ""
+		if state != nil {
+			stateFile = statePath
+		}
+		return writeHistoryPlan(cmd.Context(), startTimeValue, endTimeValue, stateFile)
+	}
+
+	if output.IsStreamFormat(format) {
+		return streamHistory(cmd.Context(), startTimeValue, endTimeValue, state)
+	}
+
+	entries, browserName, sources, err := queryHistory(cmd.Context(), startTimeValue, endTimeValue)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		entries = state.Filter(entries)
+	}
+	// The state advances on the visits as read, before merging
+	reported := entries
+	if collapseWindow > 0 {
+		reported = output.CollapseRepeats(reported, collapseWindow)
+	}
+	if granularity == "url" {
+		reported = output.CollapseURLs(reported)
+	}
+	if mergeMode {
+		reported = output.MergeEntries(reported)
+	}
+	if canonical {
+		reported = output.CanonicalizeEntries(reported)
+	}
+	if historyGroupBy == "task" {
+		reported = output.GroupByTask(reported)
+	}
+
+	var fetchFailed int
+	if fetchContent {
+		fetchFailed = addPageContent(cmd.Context(), reported)
+	}
+
+	report := newHistoryReport(reported, browserName, startTimeValue, endTimeValue, sources)
+	if granularity == "url" {
+		report.Granularity = granularity
+	}
+	report.GroupBy = historyGroupBy
+	if fetchFailed > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d pages could not be fetched for --fetch-content", fetchFailed))
+	}
+	if err := writeHistory(report, loc, splitTokens); err != nil {
+		return err
+	}
+	recordOutcome(len(reported), sources)
+
+	// Only advance the state once the output has been written
+	if state != nil {
+		if sinceLastRun {
+			state.MarkRun(readBrowsers(sources), endTimeValue)
+		} else {
+			state.Advance(entries)
+		}
+		return state.Save(statePath)
+	}
+	return nil
+}
+
+// readBrowsers returns the types of the browsers in sources that were read
+// without an error, which --since-last-run records the run for
+func readBrowsers(sources []models.SourceStatus) []string {
+	var read []string
+	for _, s := range sources {
+		if s.Error == "" {
+			read = append(read, s.Browser)
+		}
+	}
+	return read
+}
+
+// addPageContent fetches the page behind each entry for --fetch-content and
+// sets its description and content, returning the number of pages that
+// could not be fetched
+func addPageContent(ctx context.Context, entries []models.HistoryEntry) int {
+	urls := make([]string, len(entries))
+	for i, e := range entries {
+		urls[i] = e.URL
+	}
+	opts := content.Options{
+		Concurrency: fetchWorkers,
+		MaxText:     fetchMaxText,
+		MaxAge:      7 * 24 * time.Hour,
+		UserAgent:   "web-recap/" + version,
+	}
+	if dir, err := content.DefaultCacheDir(); err == nil {
+		opts.CacheDir = dir
+	}
+	pages, failed := content.Fetch(ctx, urls, opts)
+	for i := range entries {
+		if page, ok := pages[entries[i].URL]; ok {
+			entries[i].Description = page.Description
+			entries[i].Content = page.Text
+			entries[i].Words = page.WordCount()
+		}
+	}
+	return failed
+}
+
+// newHistoryReport builds the history report with its metadata, the source
+// label, and the status of each browser read
+func newHistoryReport(entries []models.HistoryEntry, browserName string, startTimeValue, endTimeValue time.Time, sources []models.SourceStatus) models.HistoryReport {
+	report := output.NewHistoryReport(entries, browserName, startTimeValue, endTimeValue, reportTimezone())
+	report.Meta = newReportMeta(sources)
+	report.Source = sourceLabel
+	report.Sources = sources
+	report.Warnings = output.SourceWarnings(sources)
+	output.LocalizeHistoryReport(&report, displayLoc)
+	return report
+}
+
+// writeHistory writes the history report in the format selected by the flags
+func writeHistory(report models.HistoryReport, loc *time.Location, splitTokens int) error {
+	if splitBy != "" {
+		return writeSplitHistory(report, loc, splitTokens)
+	}
+
+	if historyGroupBy != "" && historyGroupBy != "task" {
+		return writeGroupedHistory(report, loc)
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		if format != "json" {
+			return formatHistoryEntries(out, report.Entries)
+		}
+
+		if maxTokens > 0 {
+			var err error
+			report, err = budget.FitHistoryReport(report, maxTokens)
+			if err != nil {
+				return err
+			}
+		}
+
+		return output.FormatHistoryReportJSON(out, report)
+	})
+}
+
+// writeGroupedHistory writes the history report with its entries nested in
+// --group-by domain, site, hour, category, or session groups
+func writeGroupedHistory(report models.HistoryReport, loc *time.Location) error {
+	opts := output.GroupOptions{Loc: loc, Domain: domainAliases.Of, SessionGap: stats.DefaultSessionGap}
+	if displayLoc != nil {
+		opts.Loc = displayLoc
+	}
+	if historyGroupBy == "category" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		opts.Category = stats.NewCategories(cfg.Categories).Of
+	}
+	grouped := output.NewGroupedHistoryReport(report, historyGroupBy, opts)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatGroupedHistoryJSON(out, grouped)
+	})
+}
+
+// formatHistoryEntries writes history entries in a --format that has no
+// report metadata: arrow (or feather) and browserexport
+func formatHistoryEntries(w io.Writer, entries []models.HistoryEntry) error {
+	if format == "browserexport" {
+		return output.FormatBrowserExport(w, entries)
+	}
+	return output.FormatArrow(w, entries)
+}
+
+// parseSplitBy parses the --split-by value, returning the token budget per
+// file, or 0 for "day" and when splitting is disabled
+func parseSplitBy(value string) (int, error) {
+	if value == "" || value == "day" {
+		return 0, nil
+	}
+	if n, ok := strings.CutSuffix(value, "-tokens"); ok {
+		tokens, err := strconv.Atoi(n)
+		if err == nil && tokens > 0 {
+			return tokens, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid --split-by value: %s (use day or <N>-tokens, e.g. 5000-tokens)", value)
+}
+
+// writeSplitHistory writes the history report as numbered files named after
+// --output (default history.json), e.g. history-001.json, history-002.json
+func writeSplitHistory(report models.HistoryReport, loc *time.Location, splitTokens int) error {
+	var err error
+	if maxTokens > 0 {
+		report, err = budget.FitHistoryReport(report, maxTokens)
+		if err != nil {
+			return err
+		}
+	}
+
+	var parts []models.HistoryReport
+	if splitTokens > 0 {
+		parts, err = budget.SplitHistoryReport(report, splitTokens)
+		if err != nil {
+			return err
+		}
+	} else {
+		parts = budget.SplitHistoryReportByDay(report, loc)
+	}
+
+	base := outputPath()
+	if base == "" {
+		base = "history." + format
+		if format == "browserexport" {
+			base = "history.json"
+		}
+	}
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i, part := range parts {
+		path := fmt.Sprintf("%s-%03d%s", stem, i+1, ext)
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %v", err)
+		}
+		err = encrypted(func(out io.Writer) error {
+			if format == "json" {
+				return output.FormatHistoryReportJSON(out, part)
+			}
+			return formatHistoryEntries(out, part.Entries)
+		})(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+
+	statusf("Wrote %d entries to %d files (%s-%03d%s to %s-%03d%s)",
+		len(report.Entries), len(parts), stem, 1, ext, stem, len(parts), ext)
+	return nil
+}
+
+// resolveTimeRange turns the --date/--time/--start-date/--end-date flags into
+// a time range in loc, defaulting to today
+func resolveTimeRange(loc *time.Location) (time.Time, time.Time, error) {
 	// Parse dates with timezone
 	var startTimeValue, endTimeValue time.Time
-	var err2 error
+	var err error
 
 	if date != "" {
 		// Single date mode
 		start, err := parseDateTimeInLocation(date, "", loc)
 		if err != nil {
-			return err
+			return startTimeValue, endTimeValue, err
 		}
 
 		if timeHour != "" {
 			// --time 12 means 12:00-12:59
 			hour, err := parseHour(timeHour)
 			if err != nil {
-				return err
+				return startTimeValue, endTimeValue, err
 			}
 			startTimeValue = time.Date(start.Year(), start.Month(), start.Day(),
 				hour, 0, 0, 0, loc)
@@ -220,11 +870,11 @@ func runWeb(cmd *cobra.Command, args []string) error {
 
 			startTimeValue, err = parseDateTimeInLocation(date, st, loc)
 			if err != nil {
-				return err
+				return startTimeValue, endTimeValue, err
 			}
 			endTimeValue, err = parseDateTimeInLocation(date, et, loc)
 			if err != nil {
-				return err
+				return startTimeValue, endTimeValue, err
 			}
 		} else {
 			// Full day
@@ -234,16 +884,16 @@ func runWeb(cmd *cobra.Command, args []string) error {
 	} else if startDate != "" || endDate != "" {
 		// Date range mode (existing logic, updated to use timezone)
 		if startDate != "" {
-			startTimeValue, err2 = parseDateTimeInLocation(startDate, "", loc)
-			if err2 != nil {
-				return err2
+			startTimeValue, err = parseDateTimeInLocation(startDate, "", loc)
+			if err != nil {
+				return startTimeValue, endTimeValue, err
 			}
 		}
 
 		if endDate != "" {
-			endTimeValue, err2 = parseDateTimeInLocation(endDate, "", loc)
-			if err2 != nil {
-				return err2
+			endTimeValue, err = parseDateTimeInLocation(endDate, "", loc)
+			if err != nil {
+				return startTimeValue, endTimeValue, err
 			}
 			endTimeValue = endTimeValue.Add(24 * time.Hour)
 		}
@@ -254,85 +904,169 @@ func runWeb(cmd *cobra.Command, args []string) error {
 		endTimeValue = startTimeValue.Add(24 * time.Hour)
 	}
 
-	// Convert to UTC for database query (important!)
-	startTimeValue = startTimeValue.UTC()
-	endTimeValue = endTimeValue.UTC()
-
-	// Get browser
-	detector := browser.NewDetector()
-	var b *browser.Browser
+	return startTimeValue, endTimeValue, nil
+}
 
-	// Default to all browsers if no specific browser and no --all-browsers flag
-	useAllBrowsers := allBrowsers || browserType == "auto"
+// queryHistory runs a history query for the browser selected by the global
+// flags and returns the entries, labelled with --source-label, along with the
+// browser name for the report and the status of each browser read. Browsers
+// that were skipped are reported on stderr.
+func queryHistory(ctx context.Context, startTimeValue, endTimeValue time.Time) ([]models.HistoryEntry, string, []models.SourceStatus, error) {
+	// Archived entries keep the label they were synced with
+	if dataSource == sourceArchive {
+		entries, browserName, err := queryArchiveHistory(startTimeValue, endTimeValue)
+		entries = filterLanguages(canonicalizeEntries(dropInternal(blocked.History(entries))))
+		if err == nil && redacting {
+			redact.History(entries, redactMode)
+		}
+		return entries, browserName, nil, err
+	}
 
-	if useAllBrowsers {
-		// Handle multiple browsers
-		entries, err := database.QueryMultipleBrowsers(detector, startTimeValue, endTimeValue)
-		if err != nil {
-			return fmt.Errorf("failed to query browsers: %v", err)
+	entries, browserName, sources, err := queryBrowserHistory(ctx, startTimeValue, endTimeValue)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	warnSources(sources)
+	entries = filterLanguages(canonicalizeEntries(dropInternal(blocked.History(entries))))
+	if sourceLabel != "" {
+		for i := range entries {
+			entries[i].Source = sourceLabel
 		}
+	}
+	if redacting {
+		redact.History(entries, redactMode)
+	}
+	return entries, browserName, sources, nil
+}
 
-		// Write output
-		out := os.Stdout
-		if outputFile != "" {
-			f, err := os.Create(outputFile)
-			if err != nil {
-				return fmt.Errorf("failed to create output file: %v", err)
-			}
-			defer f.Close()
-			out = f
+// dropInternal leaves out browser pages and sponsored hits with
+// --exclude-internal
+func dropInternal(entries []models.HistoryEntry) []models.HistoryEntry {
+	if !excludeInternal {
+		return entries
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !urlutil.Internal(e.URL) {
+			kept = append(kept, e)
 		}
+	}
+	return kept
+}
 
-		return output.FormatJSON(out, entries, "all", startTimeValue, endTimeValue, timezone)
+// setHosts sets the host and registrable domain of entries that were not
+// read from a browser database, such as demo and archived visits
+func setHosts(entries []models.HistoryEntry) []models.HistoryEntry {
+	for i := range entries {
+		database.SetHost(&entries[i])
 	}
+	return entries
+}
 
-	// Get specific browser
-	bType := browser.Type(browserType)
-	if dbPath != "" {
-		// Validate custom path
-		info, err := os.Stat(dbPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return fmt.Errorf("database file not found: %s", dbPath)
-			}
-			return fmt.Errorf("cannot access database file: %v", err)
-		}
-		if info.IsDir() {
-			return fmt.Errorf("path is a directory, not a file: %s", dbPath)
-		}
+// canonicalizeEntries rewrites AMP and mobile URLs to their desktop form
+// with --canonicalize, updating the domain to match
+func canonicalizeEntries(entries []models.HistoryEntry) []models.HistoryEntry {
+	if !canonicalizeURLs {
+		return entries
+	}
+	for i := range entries {
+		entries[i] = canonicalizeEntry(entries[i])
+	}
+	return entries
+}
 
-		// Use custom path
-		b = &browser.Browser{
-			Type: bType,
-			Name: string(bType),
-			Path: dbPath,
+// canonicalizeEntry rewrites one entry's URL for --canonicalize
+func canonicalizeEntry(e models.HistoryEntry) models.HistoryEntry {
+	if u := urlutil.CanonicalURL(e.URL); u != e.URL {
+		e.URL = u
+		e.Domain = database.ExtractDomain(u)
+		database.SetHost(&e)
+	}
+	return e
+}
+
+// filterLanguages records the language of each entry's title with --lang
+// or --detect-language, and keeps the entries in the --lang languages
+func filterLanguages(entries []models.HistoryEntry) []models.HistoryEntry {
+	if langFilter == nil && !detectLanguage {
+		return entries
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		e.Language = lang.Detect(e.Title)
+		if langFilter.Keep(e.Language) {
+			kept = append(kept, e)
 		}
-	} else {
-		var err error
-		b, err = detector.GetBrowser(bType)
+	}
+	return kept
+}
+
+// queryBrowserHistory reads history from the browser selected by the flags
+func queryBrowserHistory(ctx context.Context, startTimeValue, endTimeValue time.Time) ([]models.HistoryEntry, string, []models.SourceStatus, error) {
+	if demoMode {
+		entries := setHosts(demo.History(startTimeValue, endTimeValue))
+		return entries, "all", demo.Sources(entries), nil
+	}
+
+	detector := newDetector()
+
+	// Default to all browsers if no specific browser and no --all-browsers flag
+	useAllBrowsers := allBrowsers || browserType == "auto"
+
+	if useAllBrowsers {
+		// Handle multiple browsers
+		entries, sources, err := database.QueryMultipleBrowsers(ctx, detector, startTimeValue, endTimeValue)
 		if err != nil {
-			return fmt.Errorf("failed to get browser: %v", err)
+			return nil, "", nil, fmt.Errorf("failed to query browsers: %v", err)
 		}
+		return entries, "all", sources, nil
+	}
+
+	b, err := historyBrowser(detector)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	// Query history
-	entries, err := database.Query(b, startTimeValue, endTimeValue)
+	entries, err := database.Query(ctx, b, startTimeValue, endTimeValue)
 	if err != nil {
-		return fmt.Errorf("failed to query history: %v", err)
+		return nil, "", nil, browserError(fmt.Errorf("failed to query history: %v", err), err, b.Type, b.Path)
 	}
 
-	// Write output
-	out := os.Stdout
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
+	sources := []models.SourceStatus{{Browser: string(b.Type), Name: b.Name, Path: b.Path, Entries: len(entries)}}
+	return entries, b.Name, sources, nil
+}
+
+// historyBrowser returns the single browser selected by --browser, reading
+// from --db-path when it is set
+func historyBrowser(detector *browser.Detector) (*browser.Browser, error) {
+	bType := browser.Type(browserType)
+	if dbPath == "" {
+		b, err := detector.GetBrowser(bType)
 		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
+			return nil, browserError(fmt.Errorf("failed to get browser: %v", err), err, bType, "")
+		}
+		return b, nil
+	}
+
+	// Validate custom path
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, browserError(fmt.Errorf("database file not found: %s", dbPath), err, bType, dbPath)
 		}
-		defer f.Close()
-		out = f
+		return nil, browserError(fmt.Errorf("cannot access database file: %v", err), err, bType, dbPath)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory, not a file: %s", dbPath)
 	}
 
-	return output.FormatJSON(out, entries, b.Name, startTimeValue, endTimeValue, timezone)
+	// Use custom path
+	return &browser.Browser{
+		Type: bType,
+		Name: string(bType),
+		Path: dbPath,
+	}, nil
 }
 
 var versionCmd = &cobra.Command{
@@ -347,7 +1081,7 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List detected browsers",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		detector := browser.NewDetector()
+		detector := newDetector()
 		browsers := detector.Detect()
 
 		if len(browsers) == 0 {
@@ -367,9 +1101,16 @@ var listCmd = &cobra.Command{
 var bookmarksCmd = &cobra.Command{
 	Use:   "bookmarks",
 	Short: "Extract browser bookmarks in JSON format",
-	Long: `Extract bookmarks from Chrome, Chromium, Firefox, Safari, Edge, Brave, and Vivaldi browsers
+	Long: `Extract bookmarks from Chrome, Chromium, Firefox, Safari, Edge, Brave, Vivaldi, other
+Chromium forks (Whale, Samsung Internet, Yandex, Arc), Pale Moon, and SeaMonkey
 and output them in JSON format.
 
+Bookmarks without a title get the title of the most recent history visit to the
+same URL in any browser; use --no-title-backfill to keep them as stored.
+
+Safari only records when Reading List items were added, so a date range keeps
+its Reading List items added in the range and leaves out its other bookmarks.
+
 Examples:
   web-recap bookmarks                          # Extract all bookmarks from default browser
   web-recap bookmarks --browser chrome         # Extract from Chrome specifically
@@ -377,6 +1118,9 @@ Examples:
   web-recap bookmarks -o bookmarks.json        # Save to file
   web-recap bookmarks --date 2025-12-15        # Extract bookmarks added on specific date
   web-recap bookmarks --start-date 2025-12-01 --end-date 2025-12-15  # Date range
+  web-recap bookmarks --all-browsers --tree    # Nested folders instead of a flat list
+  web-recap bookmarks --folder "Bookmarks Bar/Reading"  # One folder and its subfolders
+  web-recap bookmarks folders                  # List folders with counts and date ranges
 `,
 	RunE: runBookmarks,
 }
@@ -384,52 +1128,210 @@ Examples:
 var tabsCmd = &cobra.Command{
 	Use:   "tabs",
 	Short: "Extract open browser tabs in JSON format",
-	Long: `Extract open tabs from Chromium-based browsers (Chrome, Chromium, Edge, Brave, Vivaldi)
+	Long: `Extract open tabs from Chromium-based browsers (Chrome, Edge, Brave, Vivaldi, Whale, and other forks)
 and output them in JSON format.
 
-Note: This feature only works with Chromium-based browsers. Firefox and Safari are not supported yet.
+Note: This feature only works with Chromium-based browsers. Firefox and Safari are not supported yet,
+but --include-cloud adds the tabs open on your other Apple devices from Safari's iCloud tabs (macOS).
 Also note that the browser's session files may not be immediately updated, so there may be
 a slight delay between actual browser state and what is reported.
 
+Tabs without a title get the title of the most recent history visit to the same
+URL in any browser; use --no-title-backfill to keep them as stored.
+
+--stale lists the tabs not focused for a given time, using the last active
+time Chrome records for each tab. Tabs without one fall back to when
+'web-recap watch --tabs' first saw them open; tabs with neither are left out.
+
 Examples:
   web-recap tabs                          # Extract open tabs from default Chromium browser
   web-recap tabs --browser chrome         # Extract from Chrome specifically
   web-recap tabs --browser vivaldi        # Extract from Vivaldi
   web-recap tabs --all-browsers           # Extract from all detected Chromium browsers
   web-recap tabs -o tabs.json             # Save to file
+  web-recap tabs --group-by window        # Tabs nested in their windows, to restore a workspace
+  web-recap tabs --stale 7d               # Tabs not focused for a week
+  web-recap tabs --stale 7d --format urls -o stale.txt  # Save their URLs before closing them
+  web-recap tabs --format restore-html -o tabs.html      # Links by window, to reopen anywhere
+  web-recap tabs --format urls | xargs open              # Reopen every tab (macOS)
+  web-recap tabs --include-cloud          # Also tabs open on your iPhone, iPad, and other Macs
+  web-recap tabs --browser safari --include-cloud  # Only iCloud tabs
 `,
 	RunE: runTabs,
 }
 
 func runTabs(cmd *cobra.Command, args []string) error {
-	detector := browser.NewDetector()
+	if tabGroupBy != "" && tabGroupBy != "window" {
+		return fmt.Errorf("invalid --group-by %q (use window)", tabGroupBy)
+	}
+	switch tabFormat {
+	case "json":
+	case "urls", "restore-html":
+		if tabGroupBy != "" {
+			return fmt.Errorf("--group-by cannot be used with --format %s", tabFormat)
+		}
+	default:
+		return fmt.Errorf("unsupported tab format: %s (use json, urls, or restore-html)", tabFormat)
+	}
+	var staleBefore time.Time
+	if tabStale != "" {
+		var err error
+		if staleBefore, err = archive.Cutoff(time.Now(), tabStale); err != nil {
+			return err
+		}
+	}
+
+	entries, browserName, skipped, err := queryTabs(cmd.Context())
+	if err != nil {
+		return err
+	}
+	unknown := 0
+	if tabStale != "" {
+		entries, unknown = output.StaleTabs(entries, staleBefore, tabFirstSeen())
+	}
+	if redacting {
+		redact.Tabs(entries, redactMode)
+	}
+
+	report := output.NewTabReport(entries, browserName, sourceLabel)
+	report.Meta = newReportMeta(nil)
+	report.SkippedCommands = skipped
+	report.Warnings = output.SessionWarnings(skipped)
+	if tabStale != "" {
+		cutoff := staleBefore.UTC()
+		report.StaleBefore = &cutoff
+		if unknown > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%d tabs left out: when they were last focused is not known (run 'web-recap watch --tabs' to track them)", unknown))
+		}
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		switch tabFormat {
+		case "urls":
+			return output.FormatTabURLs(out, report.Entries)
+		case "restore-html":
+			return output.FormatTabRestoreHTML(out, output.NewTabWindowReport(report))
+		}
+		if tabGroupBy == "window" {
+			return output.FormatTabWindowReportJSON(out, output.NewTabWindowReport(report))
+		}
+		return output.FormatTabReportJSON(out, report)
+	})
+}
+
+// queryTabs reads open tabs for the --browser/--all-browsers/--db-path flags,
+// returning the tabs, labelled with --source-label, the browser name to
+// report, and the number of session commands skipped as unreadable
+func queryTabs(ctx context.Context) ([]models.TabEntry, string, int, error) {
+	if dataSource == sourceArchive {
+		return nil, "", 0, fmt.Errorf("open tabs are not archived; use --source browser")
+	}
+
+	var entries []models.TabEntry
+	browserName, skipped := browserType, 0
+	// Safari's own tabs cannot be read, only those of the user's other devices
+	if !cloudTabs || browserType != string(browser.Safari) {
+		var err error
+		entries, browserName, skipped, err = queryBrowserTabs(ctx)
+		if err != nil && !(cloudTabs && errors.Is(err, errNoTabs)) {
+			return nil, "", 0, err
+		}
+	}
+	if cloudTabs {
+		cloud, err := queryCloudTabs(ctx)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		if len(entries) == 0 && len(cloud) == 0 {
+			return nil, "", 0, errNoTabs
+		}
+		entries = append(entries, cloud...)
+		if browserName == "" || browserName == string(browser.Auto) {
+			browserName = "all"
+		}
+	}
+	warnSkippedCommands(skipped)
+	entries = blocked.Tabs(entries)
+	if sourceLabel != "" {
+		for i := range entries {
+			entries[i].Source = sourceLabel
+		}
+	}
+	return entries, browserName, skipped, nil
+}
+
+// tabFirstSeen returns a lookup of when 'watch --tabs' first saw each open
+// tab, from the archive. Without an archive nothing is found.
+func tabFirstSeen() func(browser, url string) (time.Time, bool) {
+	seen := make(map[[2]string]time.Time)
+	lookup := func(browser, url string) (time.Time, bool) {
+		t, ok := seen[[2]string{browser, url}]
+		return t, ok
+	}
+	path, err := resolveArchivePath()
+	if err != nil {
+		return lookup
+	}
+	if _, err := os.Stat(path); err != nil {
+		return lookup
+	}
+	a, err := archive.Open(path)
+	if err != nil {
+		warnf("%v", err)
+		return lookup
+	}
+	defer a.Close()
+	tabs, err := a.OpenTabs(time.Time{})
+	if err != nil {
+		warnf("%v", err)
+	}
+	for _, t := range tabs {
+		seen[[2]string{t.Browser, t.URL}] = t.FirstSeen
+	}
+	return lookup
+}
+
+// errNoTabs is returned when the selected browsers have no open tabs
+var errNoTabs = errors.New("no open tabs found")
+
+// queryCloudTabs reads the tabs open on the user's other devices, from
+// Safari's iCloud tabs
+func queryCloudTabs(ctx context.Context) ([]models.TabEntry, error) {
+	path, err := browser.GetCloudTabsPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find iCloud tabs: %v", err)
+	}
+	entries, err := database.QueryCloudTabs(ctx, path)
+	if err != nil {
+		return nil, browserError(fmt.Errorf("failed to query iCloud tabs: %v", err), err, browser.Safari, path)
+	}
+	return entries, nil
+}
+
+// queryBrowserTabs reads open tabs from the browser selected by the flags
+func queryBrowserTabs(ctx context.Context) ([]models.TabEntry, string, int, error) {
+	detector := newDetector()
+	database.MaxSessionSize = int64(maxSessionSize) << 20
 
 	// Determine if we should query all browsers
 	useAllBrowsers := allBrowsers || browserType == "auto"
 
 	if useAllBrowsers {
 		// Query all Chromium-based browsers
-		entries, err := database.QueryMultipleBrowsersTabs(detector)
+		entries, skipped, err := database.QueryMultipleBrowsersTabs(ctx, detector)
 		if err != nil {
-			return fmt.Errorf("failed to query tabs: %v", err)
+			return nil, "", 0, fmt.Errorf("failed to query tabs: %v", err)
 		}
 
 		if len(entries) == 0 {
-			return fmt.Errorf("no open tabs found (only Chromium-based browsers are supported)")
+			return nil, "", 0, fmt.Errorf("%w (only Chromium-based browsers are supported)", errNoTabs)
 		}
-
-		// Write output
-		out := os.Stdout
-		if outputFile != "" {
-			f, err := os.Create(outputFile)
-			if err != nil {
-				return fmt.Errorf("failed to create output file: %v", err)
-			}
-			defer f.Close()
-			out = f
+		if !noTitleBackfill {
+			database.BackfillTabTitles(ctx, detector, entries)
 		}
 
-		return output.FormatTabsJSON(out, entries, "all")
+		return entries, "all", skipped, nil
 	}
 
 	// Get specific browser
@@ -437,7 +1339,7 @@ func runTabs(cmd *cobra.Command, args []string) error {
 
 	// Check if it's a Chromium-based browser
 	if !browser.IsChromiumBased(bType) {
-		return fmt.Errorf("tabs extraction only supported for Chromium-based browsers (chrome, chromium, edge, brave, vivaldi)")
+		return nil, "", 0, fmt.Errorf("tabs extraction only supported for Chromium-based browsers (chrome, chromium, edge, brave, vivaldi, whale, samsung, yandex, arc)")
 	}
 
 	var b *browser.Browser
@@ -448,13 +1350,13 @@ func runTabs(cmd *cobra.Command, args []string) error {
 		info, err := os.Stat(dbPath)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return fmt.Errorf("session path not found: %s", dbPath)
+				return nil, "", 0, fmt.Errorf("session path not found: %s", dbPath)
 			}
-			return fmt.Errorf("cannot access session path: %v", err)
+			return nil, "", 0, fmt.Errorf("cannot access session path: %v", err)
 		}
 
 		if !info.IsDir() {
-			return fmt.Errorf("session path must be a directory: %s", dbPath)
+			return nil, "", 0, fmt.Errorf("session path must be a directory: %s", dbPath)
 		}
 
 		b = &browser.Browser{
@@ -468,41 +1370,43 @@ func runTabs(cmd *cobra.Command, args []string) error {
 		var err error
 		b, err = detector.GetBrowser(bType)
 		if err != nil {
-			return fmt.Errorf("failed to get browser: %v", err)
+			return nil, "", 0, fmt.Errorf("failed to get browser: %v", err)
 		}
 
 		// Get session path
 		sessionPath, err = browser.GetSessionPath(b.Type)
 		if err != nil {
-			return fmt.Errorf("failed to get session path: %v", err)
+			return nil, "", 0, fmt.Errorf("failed to get session path: %v", err)
 		}
 	}
 
 	// Query tabs
-	entries, err := database.QueryTabs(b, sessionPath)
+	entries, skipped, err := database.QueryTabs(ctx, b, sessionPath)
 	if err != nil {
-		return fmt.Errorf("failed to query tabs: %v", err)
+		return nil, "", 0, fmt.Errorf("failed to query tabs: %v", err)
 	}
 
 	if len(entries) == 0 {
-		return fmt.Errorf("no open tabs found")
+		return nil, "", 0, errNoTabs
 	}
-
-	// Write output
-	out := os.Stdout
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
-		}
-		defer f.Close()
-		out = f
+	if !noTitleBackfill {
+		database.BackfillTabTitles(ctx, detector, entries)
 	}
 
-	return output.FormatTabsJSON(out, entries, b.Name)
+	return entries, b.Name, skipped, nil
 }
 
 func runBookmarks(cmd *cobra.Command, args []string) error {
+	switch bookmarkFormat {
+	case "json":
+	case "pinboard-json":
+		if bookmarkTree {
+			return fmt.Errorf("--tree cannot be used with --format pinboard-json")
+		}
+	default:
+		return fmt.Errorf("unsupported bookmark format: %s (use json or pinboard-json)", bookmarkFormat)
+	}
+
 	// Get timezone
 	loc, err := getTimezone(timezone, utcMode)
 	if err != nil {
@@ -586,31 +1490,40 @@ func runBookmarks(cmd *cobra.Command, args []string) error {
 		endTimeValue = endTimeValue.UTC()
 	}
 
+	if dataSource == sourceArchive {
+		entries, browserName, err := queryArchiveBookmarks(startTimeValue, endTimeValue)
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(func(out io.Writer) error {
+			return formatBookmarks(out, newBookmarkReport(entries, browserName, startTimeValue, endTimeValue, nil))
+		})
+	}
+
 	// Get browser detector
-	detector := browser.NewDetector()
+	detector := newDetector()
 
 	// Determine if we should query all browsers
 	useAllBrowsers := allBrowsers || browserType == "auto"
 
 	if useAllBrowsers {
 		// Query all browsers
-		entries, warnings := database.QueryMultipleBrowsersBookmarks(detector, startTimeValue, endTimeValue)
-		for _, warning := range warnings {
-			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		entries, sources, err := database.QueryMultipleBrowsersBookmarks(cmd.Context(), detector, startTimeValue, endTimeValue)
+		warnSources(sources)
+		if err != nil {
+			return fmt.Errorf("failed to query bookmarks: %v", err)
 		}
-
-		// Write output
-		out := os.Stdout
-		if outputFile != "" {
-			f, err := os.Create(outputFile)
-			if err != nil {
-				return fmt.Errorf("failed to create output file: %v", err)
-			}
-			defer f.Close()
-			out = f
+		if !noTitleBackfill {
+			database.BackfillBookmarkTitles(cmd.Context(), detector, entries)
 		}
 
-		return output.FormatBookmarksJSON(out, entries, "all", startTimeValue, endTimeValue, timezone)
+		// Write output
+		recordOutcome(len(entries), sources)
+		return writeOutput(func(out io.Writer) error {
+			labelBookmarks(entries)
+			return formatBookmarks(out, newBookmarkReport(entries, "all", startTimeValue, endTimeValue, sources))
+		})
 	}
 
 	// Get specific browser
@@ -627,13 +1540,13 @@ func runBookmarks(cmd *cobra.Command, args []string) error {
 		info, err := os.Stat(dbPath)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return fmt.Errorf("bookmark file not found: %s", dbPath)
+				return browserError(fmt.Errorf("bookmark file not found: %s", dbPath), err, bType, dbPath)
 			}
-			return fmt.Errorf("cannot access bookmark file: %v", err)
+			return browserError(fmt.Errorf("cannot access bookmark file: %v", err), err, bType, dbPath)
 		}
 
 		// For Firefox, dbPath might be a directory (profile path)
-		if info.IsDir() && bType != browser.Firefox {
+		if info.IsDir() && browser.EngineOf(bType) != browser.EngineGecko {
 			return fmt.Errorf("path is a directory, not a file: %s", dbPath)
 		}
 
@@ -648,42 +1561,95 @@ func runBookmarks(cmd *cobra.Command, args []string) error {
 		var err error
 		b, err = detector.GetBrowser(bType)
 		if err != nil {
-			return fmt.Errorf("failed to get browser: %v", err)
+			return browserError(fmt.Errorf("failed to get browser: %v", err), err, bType, "")
 		}
 
 		// Get bookmark path
 		bookmarkPath, err = browser.GetBookmarkPath(b.Type)
 		if err != nil {
-			return fmt.Errorf("failed to get bookmark path: %v", err)
+			return browserError(fmt.Errorf("failed to get bookmark path: %v", err), err, b.Type, "")
 		}
 
-		// For Firefox, find the profile
-		if b.Type == browser.Firefox {
+		// For Firefox and its derivatives, find the profile
+		if browser.EngineOf(b.Type) == browser.EngineGecko {
 			bookmarkPath, err = browser.GetFirefoxProfilePath(bookmarkPath)
 			if err != nil {
-				return fmt.Errorf("failed to find Firefox profile: %v", err)
+				return browserError(fmt.Errorf("failed to find Firefox profile: %v", err), err, b.Type, "")
 			}
 		}
 	}
 
 	// Query bookmarks
-	entries, err := database.QueryBookmarks(b, bookmarkPath, startTimeValue, endTimeValue)
+	entries, err := database.QueryBookmarks(cmd.Context(), b, bookmarkPath, startTimeValue, endTimeValue)
 	if err != nil {
-		return fmt.Errorf("failed to query bookmarks: %v", err)
+		return browserError(fmt.Errorf("failed to query bookmarks: %v", err), err, b.Type, bookmarkPath)
+	}
+	if !noTitleBackfill {
+		database.BackfillBookmarkTitles(cmd.Context(), detector, entries)
 	}
 
 	// Write output
-	out := os.Stdout
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
+	sources := []models.SourceStatus{{Browser: string(b.Type), Name: b.Name, Path: bookmarkPath, Entries: len(entries)}}
+	recordOutcome(len(entries), sources)
+	return writeOutput(func(out io.Writer) error {
+		labelBookmarks(entries)
+		return formatBookmarks(out, newBookmarkReport(entries, b.Name, startTimeValue, endTimeValue, sources))
+	})
+}
+
+// newBookmarkReport builds the bookmark report with its metadata, the source
+// label, the status of each browser read, and --folder and --display-tz
+// applied
+func newBookmarkReport(entries []models.BookmarkEntry, browserName string, startTimeValue, endTimeValue time.Time, sources []models.SourceStatus) models.BookmarkReport {
+	entries = filterBookmarks(entries)
+	report := output.NewBookmarkReport(entries, browserName, startTimeValue, endTimeValue, reportTimezone())
+	report.Meta = newReportMeta(sources)
+	report.Source = sourceLabel
+	report.Sources = sources
+	report.Warnings = output.SourceWarnings(sources)
+	output.LocalizeBookmarkReport(&report, displayLoc)
+	return report
+}
+
+// formatBookmarks writes the bookmark report as JSON, nested in folders
+// with --tree, or as a Pinboard export with --format pinboard-json
+func formatBookmarks(out io.Writer, report models.BookmarkReport) error {
+	if bookmarkFormat == "pinboard-json" {
+		return output.FormatBookmarksPinboardJSON(out, report.Entries)
+	}
+	if bookmarkTree {
+		return output.FormatBookmarkTreeJSON(out, output.NewBookmarkTreeReport(report))
+	}
+	return output.FormatBookmarkReportJSON(out, report)
+}
+
+// filterBookmarks drops bookmarks on --blocklist domains and keeps those in
+// the --folder folder or its subfolders. Folder names are matched without
+// regard to case.
+func filterBookmarks(entries []models.BookmarkEntry) []models.BookmarkEntry {
+	entries = blocked.Bookmarks(entries)
+	folder := strings.Trim(bookmarkFolder, "/")
+	if folder == "" {
+		return entries
+	}
+	kept := make([]models.BookmarkEntry, 0, len(entries))
+	for _, e := range entries {
+		if len(e.Folder) >= len(folder) && strings.EqualFold(e.Folder[:len(folder)], folder) &&
+			(len(e.Folder) == len(folder) || e.Folder[len(folder)] == '/') {
+			kept = append(kept, e)
 		}
-		defer f.Close()
-		out = f
 	}
+	return kept
+}
 
-	return output.FormatBookmarksJSON(out, entries, b.Name, startTimeValue, endTimeValue, timezone)
+// labelBookmarks records --source-label on each bookmark
+func labelBookmarks(entries []models.BookmarkEntry) {
+	if sourceLabel == "" {
+		return
+	}
+	for i := range entries {
+		entries[i].Source = sourceLabel
+	}
 }
 
 var youtubeWatchLaterCmd = &cobra.Command{
@@ -756,17 +1722,9 @@ func runYouTubeWatchLater(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	out := os.Stdout
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
-		}
-		defer f.Close()
-		out = f
-	}
-
-	return output.FormatYouTubeWatchLaterJSON(out, report)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatYouTubeWatchLaterJSON(out, report)
+	})
 }
 
 var youtubeCopyPlaylistCmd = &cobra.Command{
@@ -1053,17 +2011,9 @@ func runReadingList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write output
-	out := os.Stdout
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
-		}
-		defer f.Close()
-		out = f
-	}
-
-	return output.FormatReadingListJSON(out, entries, platformName, startTimeValue, endTimeValue, timezone)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatReadingListJSON(out, entries, platformName, startTimeValue, endTimeValue, reportTimezone())
+	})
 }
 
 var twitterBookmarksCmd = &cobra.Command{
@@ -1153,15 +2103,7 @@ func runTwitterBookmarks(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	out := os.Stdout
-	if outputFile != "" {
-		f, err := os.Create(outputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %v", err)
-		}
-		defer f.Close()
-		out = f
-	}
-
-	return output.FormatTwitterBookmarksJSON(out, report)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatTwitterBookmarksJSON(out, report)
+	})
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/blocklist"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestNewBookmarkReportBlocklist(t *testing.T) {
+	list, err := blocklist.Parse(strings.NewReader("mybank.example\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := blocked
+	blocked = list
+	defer func() { blocked = saved }()
+
+	entries := []models.BookmarkEntry{
+		{URL: "https://online.mybank.example/login", Domain: "online.mybank.example", Folder: "Finance"},
+		{URL: "https://news.example/", Domain: "news.example", Folder: "Finance"},
+	}
+	report := newBookmarkReport(entries, "chrome", time.Time{}, time.Now(), nil)
+	if len(report.Entries) != 1 || report.Entries[0].Domain != "news.example" {
+		t.Errorf("report entries = %+v, want only news.example", report.Entries)
+	}
+	if got := filterBookmarks(entries); len(got) != 1 {
+		t.Errorf("filterBookmarks kept %d bookmarks, want 1", len(got))
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// noHostInfo leaves the hostname and username out of report metadata
+var noHostInfo bool
+
+// reportQuery is the effective query of the running command, recorded by
+// loadConfig for the report metadata
+var reportQuery models.ReportQuery
+
+// queryFlags are the flags recorded in report metadata: every flag that
+// changes what a report holds. Flags that only choose where output goes, or
+// that carry credentials, are left out.
+var queryFlags = map[string]bool{
+	"browser":           true,
+	"exclude-browser":   true,
+	"date":              true,
+	"start-date":        true,
+	"end-date":          true,
+	"start-time":        true,
+	"end-time":          true,
+	"time":              true,
+	"tz":                true,
+	"utc":               true,
+	"display-tz":        true,
+	"db-path":           true,
+	"data-dir":          true,
+	"all-browsers":      true,
+	"source":            true,
+	"archive":           true,
+	"source-label":      true,
+	"format":            true,
+	"split-by":          true,
+	"canonical":         true,
+	"merge":             true,
+	"incremental":       true,
+	"since-last-run":    true,
+	"max-tokens":        true,
+	"granularity":       true,
+	"collapse":          true,
+	"group-by":          true,
+	"fetch-content":     true,
+	"fetch-max-text":    true,
+	"state":             true,
+	"lang":              true,
+	"detect-language":   true,
+	"canonicalize":      true,
+	"exclude-internal":  true,
+	"redact":            true,
+	"blocklist":         true,
+	"no-title-backfill": true,
+	"tree":              true,
+	"folder":            true,
+	"max-session-size":  true,
+	"demo":              true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noHostInfo, "no-host-info", false, "Leave the hostname and username out of report metadata")
+}
+
+// recordQuery stores the command path and the query flags set on the
+// command line
+func recordQuery(cmd *cobra.Command) {
+	reportQuery = models.ReportQuery{Command: cmd.CommandPath()}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !queryFlags[f.Name] {
+			return
+		}
+		if reportQuery.Flags == nil {
+			reportQuery.Flags = make(map[string]string)
+		}
+		reportQuery.Flags[f.Name] = f.Value.String()
+	})
+}
+
+// newReportMeta returns the metadata for a report read from sources
+func newReportMeta(sources []models.SourceStatus) *models.ReportMeta {
+	meta := &models.ReportMeta{Version: version, Query: reportQuery}
+	if !canonical {
+		now := time.Now().UTC()
+		meta.GeneratedAt = &now
+	}
+	if !noHostInfo && !demoMode {
+		meta.Hostname, _ = os.Hostname()
+		meta.Username = currentUser()
+	}
+
+	browsers := make(map[string]bool)
+	profiles := make(map[string]bool)
+	for _, s := range sources {
+		browsers[s.Browser] = true
+		if s.Name != "" {
+			profiles[s.Name] = true
+		}
+	}
+	meta.Query.Browsers = sortedKeys(browsers)
+	meta.Query.Profiles = sortedKeys(profiles)
+	return meta
+}
+
+// currentUser returns the name of the user running web-recap, or "" when
+// it cannot be found
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
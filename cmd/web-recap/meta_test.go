@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestQueryFlagsCoverHistory(t *testing.T) {
+	// History flags that don't change what the report holds
+	unrecorded := map[string]bool{"dry-run": true, "fetch-concurrency": true}
+	historyCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !queryFlags[f.Name] && !unrecorded[f.Name] {
+			t.Errorf("history flag --%s is not recorded in report metadata; add it to queryFlags", f.Name)
+		}
+	})
+}
+
+func TestRecordQuery(t *testing.T) {
+	f := historyCmd.Flags().Lookup("group-by")
+	saved := historyGroupBy
+	defer func() {
+		historyGroupBy = saved
+		f.Changed = false
+	}()
+	if err := historyCmd.Flags().Set("group-by", "domain"); err != nil {
+		t.Fatal(err)
+	}
+
+	recordQuery(historyCmd)
+	if got := reportQuery.Flags["group-by"]; got != "domain" {
+		t.Errorf("recorded group-by = %q, want domain", got)
+	}
+}
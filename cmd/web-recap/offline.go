@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/rzolkos/web-recap/internal/offline"
+	"github.com/spf13/cobra"
+)
+
+// offlineMode is --offline, or offline in the config file
+var offlineMode bool
+
+// enableOffline fails if cmd was asked to use the network, then blocks
+// every HTTP request for the rest of the run
+func enableOffline(cmd *cobra.Command) error {
+	feature := networkFeature(cmd)
+	if feature == "" {
+		feature = listenFeature(cmd)
+	}
+	if feature != "" {
+		return &cliError{Code: codeOffline, Message: (&offline.Error{Feature: feature}).Error()}
+	}
+	offline.Enable()
+	return nil
+}
+
+// networkFeature names the network feature cmd and its flags request, or
+// returns "" when the run only reads and writes local files
+func networkFeature(cmd *cobra.Command) string {
+	switch {
+	case postTarget() != "":
+		return "posting the output (--post-url)"
+	case uploadTarget() != "":
+		return "uploading the output (--upload)"
+	case fetchContent:
+		return "fetching pages (--fetch-content)"
+	}
+
+	switch cmd {
+	case summarizeCmd:
+		return "summarize (LLM calls)"
+	case recapCmd:
+		if !recapPromptOnly {
+			return "recap without --prompt-only"
+		}
+	case digestCmd:
+		if len(digestEmails) > 0 || postSlack || slackWebhook != "" || postDiscord || discordWebhook != "" || postTelegram || postMatrix {
+			return "sending the digest"
+		}
+	case bookmarksPushCmd:
+		return "bookmarks push"
+	case importFirefoxSyncCmd:
+		return "import firefox-sync"
+	case activityWatchCmd:
+		if awPush {
+			return "activitywatch --push"
+		}
+	case readingListCmd:
+		if filePath == "" {
+			return "reading-list without --file"
+		}
+	case youtubeWatchLaterCmd, youtubeCopyPlaylistCmd, twitterBookmarksCmd:
+		return cmd.Name()
+	}
+	return ""
+}
+
+// listenFeature names the serve listener that would accept connections
+// from other machines, or returns "" when serve only listens on loopback
+func listenFeature(cmd *cobra.Command) string {
+	if cmd != serveCmd {
+		return ""
+	}
+	if !offline.IsLoopback(serveListen) {
+		return "serve on " + serveListen
+	}
+	if metricsListen != "" && !offline.IsLoopback(metricsListen) {
+		return "serve --metrics-listen on " + metricsListen
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/encrypt"
+	"github.com/rzolkos/web-recap/internal/notify"
+	"github.com/rzolkos/web-recap/internal/outfile"
+	"github.com/rzolkos/web-recap/internal/upload"
+)
+
+// postTarget returns the URL the output is POSTed to: --post-url, or
+// --output when it is an http(s) URL. It is empty when writing to a file
+// or stdout.
+func postTarget() string {
+	if postURL != "" {
+		return postURL
+	}
+	if strings.HasPrefix(outputFile, "http://") || strings.HasPrefix(outputFile, "https://") {
+		return outputFile
+	}
+	return ""
+}
+
+// uploadTarget returns the object storage destination: --upload, or
+// --output when it is an s3://, gs://, or az:// URL
+func uploadTarget() string {
+	if uploadDest != "" {
+		return uploadDest
+	}
+	if upload.IsTarget(outputFile) {
+		return outputFile
+	}
+	return ""
+}
+
+// outputFields returns the values of the --output template placeholders for
+// a file written at now. {date} is the --date or --start-date day, or the
+// day of now; {browser} is --browser, or "all" for every detected browser.
+func outputFields(now time.Time) outfile.Fields {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		loc = time.Local
+	}
+	f := outfile.Fields{Date: now.In(loc), Time: now.In(loc), Browser: browserType}
+	for _, day := range []string{date, startDate} {
+		if day == "" {
+			continue
+		}
+		if t, err := parseDateTimeInLocation(day, "", loc); err == nil {
+			f.Date = t
+			break
+		}
+	}
+	if allBrowsers || browserType == "auto" {
+		f.Browser = "all"
+	}
+	f.Host, _ = os.Hostname()
+	return f
+}
+
+// outputPath returns --output with its placeholders filled in for a file
+// written now
+func outputPath() string {
+	return outfile.Expand(outputFile, outputFields(time.Now()))
+}
+
+// remoteOutput reports whether the output is sent somewhere other than a
+// local file or stdout
+func remoteOutput() bool {
+	return postTarget() != "" || uploadTarget() != ""
+}
+
+// encrypting reports whether --encrypt or --encrypt-gpg is set
+func encrypting() bool {
+	return len(ageRecipients) > 0 || len(gpgRecipients) > 0
+}
+
+// encryptTo returns a writer that encrypts to w for the --encrypt or
+// --encrypt-gpg recipients. Closing it finishes the encrypted output.
+func encryptTo(w io.Writer) (io.WriteCloser, error) {
+	if len(ageRecipients) > 0 && len(gpgRecipients) > 0 {
+		return nil, fmt.Errorf("choose either --encrypt or --encrypt-gpg, not both")
+	}
+	if len(gpgRecipients) > 0 {
+		return encrypt.GPG(w, gpgRecipients)
+	}
+	return encrypt.Age(w, ageRecipients)
+}
+
+// encrypted returns write with its output encrypted when --encrypt or
+// --encrypt-gpg is set
+func encrypted(write func(out io.Writer) error) func(out io.Writer) error {
+	if !encrypting() {
+		return write
+	}
+	return func(out io.Writer) error {
+		enc, err := encryptTo(out)
+		if err != nil {
+			return err
+		}
+		err = write(enc)
+		if closeErr := enc.Close(); closeErr != nil {
+			return fmt.Errorf("failed to encrypt output: %v", closeErr)
+		}
+		return err
+	}
+}
+
+// outputContext returns the context for sending output: the --timeout
+// context, or the command's, which an interrupt cancels
+func outputContext() context.Context {
+	if timeoutCtx != nil {
+		return timeoutCtx
+	}
+	if interruptCtx != nil {
+		return interruptCtx
+	}
+	return context.Background()
+}
+
+// writeOutput runs write against stdout, the --output file, or a buffer
+// that is POSTed or uploaded once write succeeds. With --encrypt or
+// --encrypt-gpg only the encrypted output is written.
+func writeOutput(write func(out io.Writer) error) error {
+	if postTarget() != "" && uploadTarget() != "" {
+		return fmt.Errorf("choose either an HTTP post or an object storage upload, not both")
+	}
+	write = encrypted(write)
+
+	if target := uploadTarget(); target != "" {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			return err
+		}
+		dest, err := upload.Upload(outputContext(), target, buf.Bytes())
+		if err != nil {
+			return err
+		}
+		statusf("Uploaded to %s", dest)
+		return nil
+	}
+
+	if target := postTarget(); target != "" {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			return err
+		}
+		token := postToken
+		if token == "" {
+			token = os.Getenv("WEB_RECAP_POST_TOKEN")
+		}
+		return notify.Push(outputContext(), notify.PushConfig{URL: target, Token: token, Retries: postRetries}, buf.Bytes())
+	}
+
+	out := os.Stdout
+	if outputFile != "" {
+		f, err := os.Create(outputPath())
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	return write(out)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pickBookmarks bool
+	pickDays      int
+	pickQuery     string
+	pickMulti     bool
+)
+
+var pickCmd = &cobra.Command{
+	Use:   "pick",
+	Short: "Fuzzy-find a URL from history or bookmarks and print it",
+	Long: `Open an fzf-style fuzzy finder over recent history (or bookmarks) and print the
+selected URL to stdout. The finder is drawn on the terminal, so the output can
+be piped or captured.
+
+Type to narrow the list; space-separated terms must all match, and a term with
+an upper-case letter matches case-sensitively. ↑/↓ move, Enter picks, Esc
+cancels (exit code 130). With --multi, Tab marks several URLs, printed one per
+line.
+
+Examples:
+  web-recap pick                                  # History from the last 30 days
+  xdg-open "$(web-recap pick --days 7)"
+  web-recap pick --bookmarks --multi -q golang
+  bind -x '"\C-o": xdg-open "$(web-recap pick)"'  # Bash: Ctrl-O opens something recent
+`,
+	RunE: runPick,
+}
+
+func init() {
+	pickCmd.Flags().BoolVar(&pickBookmarks, "bookmarks", false, "Pick from bookmarks instead of history")
+	pickCmd.Flags().IntVar(&pickDays, "days", 30, "Days of history to search, ending today (ignored with date flags)")
+	pickCmd.Flags().StringVarP(&pickQuery, "query", "q", "", "Start with this query")
+	pickCmd.Flags().BoolVarP(&pickMulti, "multi", "m", false, "Allow marking several URLs with Tab")
+}
+
+func runPick(cmd *cobra.Command, args []string) error {
+	if err := tui.RequireTerminal(); err != nil {
+		return fmt.Errorf("pick: %v", err)
+	}
+
+	var items []tui.PickItem
+	var err error
+	if pickBookmarks {
+		items, err = bookmarkPickItems(cmd.Context())
+	} else {
+		items, err = historyPickItems(cmd.Context())
+	}
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return &cliError{Code: codeNoEntries, Message: "nothing to pick from"}
+	}
+
+	picker := tui.NewPicker(items, pickQuery, pickMulti)
+	if err := tui.Run(picker); err != nil {
+		return err
+	}
+	urls := picker.Selected()
+	if len(urls) == 0 {
+		// Cancelling is not a usage error
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return &cliError{Code: codeCanceled, Message: "nothing picked"}
+	}
+
+	return writeOutput(func(out io.Writer) error {
+		for _, u := range urls {
+			if _, err := fmt.Fprintln(out, u); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// historyPickItems returns one item per URL visited in the range, most
+// recent first
+func historyPickItems(ctx context.Context) ([]tui.PickItem, error) {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return nil, err
+	}
+	if pickDays <= 0 {
+		return nil, fmt.Errorf("--days must be positive")
+	}
+
+	var startTimeValue, endTimeValue time.Time
+	if date != "" || startDate != "" || endDate != "" {
+		if startTimeValue, endTimeValue, err = resolveTimeRange(loc); err != nil {
+			return nil, err
+		}
+	} else {
+		now := time.Now().In(loc)
+		endTimeValue = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+		startTimeValue = endTimeValue.AddDate(0, 0, -pickDays)
+	}
+
+	entries, _, _, err := queryHistory(ctx, startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var items []tui.PickItem
+	for _, e := range entries {
+		if seen[e.URL] {
+			continue
+		}
+		seen[e.URL] = true
+		items = append(items, tui.PickItem{Label: pickLabel(e.Title, e.URL, ""), Value: e.URL})
+	}
+	return items, nil
+}
+
+// bookmarkPickItems returns one item per bookmarked URL
+func bookmarkPickItems(ctx context.Context) ([]tui.PickItem, error) {
+	var entries []models.BookmarkEntry
+	var err error
+	switch {
+	case dataSource == sourceArchive:
+		entries, _, err = queryArchiveBookmarks(time.Time{}, time.Time{})
+	case allBrowsers || browserType == "auto":
+		var sources []models.SourceStatus
+		entries, sources, err = database.QueryMultipleBrowsersBookmarks(ctx, newDetector(), time.Time{}, time.Time{})
+		warnSources(sources)
+	default:
+		entries, err = queryBrowserBookmarks(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var items []tui.PickItem
+	for _, b := range blocked.Bookmarks(entries) {
+		if seen[b.URL] {
+			continue
+		}
+		seen[b.URL] = true
+		items = append(items, tui.PickItem{Label: pickLabel(b.Title, b.URL, b.Folder), Value: b.URL})
+	}
+	return items, nil
+}
+
+// queryBrowserBookmarks reads the bookmarks of the browser selected by
+// --browser, or of --db-path
+func queryBrowserBookmarks(ctx context.Context) ([]models.BookmarkEntry, error) {
+	bType := browser.Type(browserType)
+	if dbPath != "" {
+		b := &browser.Browser{Type: bType, Name: string(bType), Path: dbPath}
+		return database.QueryBookmarks(ctx, b, dbPath, time.Time{}, time.Time{})
+	}
+
+	b, err := newDetector().GetBrowser(bType)
+	if err != nil {
+		return nil, browserError(fmt.Errorf("failed to get browser: %v", err), err, bType, "")
+	}
+	bookmarkPath, err := database.ResolveBookmarkPath(b.Type)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := database.QueryBookmarks(ctx, b, bookmarkPath, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, browserError(fmt.Errorf("failed to query bookmarks: %v", err), err, b.Type, bookmarkPath)
+	}
+	return entries, nil
+}
+
+// pickLabel is the text shown and matched for a URL
+func pickLabel(title, url, folder string) string {
+	label := url
+	if title != "" {
+		label = title + "  " + url
+	}
+	if folder != "" {
+		label += "  [" + folder + "]"
+	}
+	return label
+}
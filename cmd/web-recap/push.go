@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/config"
+	"github.com/rzolkos/web-recap/internal/readlater"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pushTo         string
+	pushCollection string
+	pushMap        map[string]string
+	pushDryRun     bool
+)
+
+var bookmarksPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Save bookmarks to a read-later service (Raindrop.io, Instapaper, Pinboard)",
+	Long: `Save bookmarks to a read-later service, e.g. to move them out of a browser in
+one go. Select bookmarks with --browser and --folder; a URL bookmarked more than
+once is saved once.
+
+Each bookmark is filed in a Raindrop.io collection named after its folder, e.g.
+"Bookmarks Bar/Reading" goes to "Reading", and collections are created as
+needed. Bookmarks directly in a root folder go to Unsorted. --map files a
+folder and its subfolders in a given collection instead, and --collection
+files everything in one. Instapaper has no folders in its API, so every
+bookmark goes to the unread list. Pinboard bookmarks are private and tagged
+with their folders below the root, plus the collection.
+
+Credentials come from the config file or the environment:
+  {"read_later": {"raindrop": {"token": "..."}}}            or RAINDROP_TOKEN
+  {"read_later": {"instapaper": {"username": "...", "password": "..."}}}
+                                       or INSTAPAPER_USERNAME / INSTAPAPER_PASSWORD
+  {"read_later": {"pinboard": {"token": "user:HEX"}}}         or PINBOARD_TOKEN
+
+Running push twice saves the bookmarks twice on Raindrop.io and Instapaper
+(Pinboard keeps URLs it already has); use --dry-run to check first.
+
+Examples:
+  web-recap bookmarks push --to raindrop --dry-run
+  web-recap bookmarks push --to raindrop --browser chrome --folder "Bookmarks Bar/Reading"
+  web-recap bookmarks push --to raindrop --map "Bookmarks Bar/Work=Work" --map "Other Bookmarks=Archive"
+  web-recap bookmarks push --to instapaper --folder "Read later"
+  web-recap bookmarks push --to pinboard --browser firefox
+`,
+	RunE: runBookmarksPush,
+}
+
+func init() {
+	bookmarksPushCmd.Flags().StringVar(&pushTo, "to", "", "Service to save to: "+strings.Join(readlater.Services, ", "))
+	bookmarksPushCmd.Flags().StringVar(&pushCollection, "collection", "", "File every bookmark in this collection")
+	bookmarksPushCmd.Flags().StringToStringVar(&pushMap, "map", nil, "File a folder and its subfolders in a collection, as folder=collection (repeatable)")
+	bookmarksPushCmd.Flags().BoolVar(&pushDryRun, "dry-run", false, "Write the bookmarks and their collections as JSON instead of saving them")
+	bookmarksPushCmd.MarkFlagRequired("to")
+}
+
+func runBookmarksPush(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	service, err := readlater.New(pushTo, cfg.ReadLater)
+	if err != nil {
+		return err
+	}
+
+	entries, _, _, err := queryAllBookmarks(cmd.Context())
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	var items []readlater.Item
+	for _, e := range filterBookmarks(entries) {
+		if seen[e.URL] {
+			continue
+		}
+		seen[e.URL] = true
+		items = append(items, readlater.Item{Bookmark: e, Collection: readlater.CollectionFor(e.Folder, pushCollection, pushMap)})
+	}
+	if len(items) == 0 {
+		return &cliError{Code: codeNoEntries, Message: "no bookmarks to push"}
+	}
+
+	if pushDryRun {
+		type planned struct {
+			URL        string `json:"url"`
+			Title      string `json:"title"`
+			Collection string `json:"collection,omitempty"`
+		}
+		plan := make([]planned, len(items))
+		for i, item := range items {
+			plan[i] = planned{URL: item.Bookmark.URL, Title: item.Bookmark.Title, Collection: item.Collection}
+		}
+		return writeOutput(func(out io.Writer) error {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(plan)
+		})
+	}
+
+	saved, err := service.Push(cmd.Context(), items)
+	if err != nil {
+		statusf("Saved %d of %d bookmarks to %s before the error", saved, len(items), service.Name())
+		return err
+	}
+	statusf("Saved %d bookmarks to %s", saved, service.Name())
+	return nil
+}
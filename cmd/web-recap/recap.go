@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/recap"
+	"github.com/rzolkos/web-recap/internal/redact"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recapStyle      string
+	recapPromptOnly bool
+	recapPrompt     string
+	recapSessionGap time.Duration
+	recapKeepQuery  bool
+)
+
+var recapCmd = &cobra.Command{
+	Use:   "recap",
+	Short: "Write a narrative daily, weekly, or standup recap of your browsing",
+	Long: `Combine browsing stats (visits, top domains and pages), sessions (runs of activity
+separated by idle gaps), and search queries into a structured prompt for an LLM.
+
+Styles:
+  daily     A short recap of today
+  weekly    A themed review of the last seven days
+  standup   Yesterday / Today / Blockers bullets, covering the previous workday
+
+Each style picks a default time range, which --date or --start-date/--end-date
+override. With --prompt-only the prompt is printed so it can be pasted into any
+chat; otherwise it is sent to the LLM configured for summarize (see
+"web-recap summarize --help") and the recap is printed.
+
+Query strings are stripped from page URLs unless --keep-query is set. Search
+queries are always included since they are what the recap narrates. Emails and
+token-like query values are always replaced with REDACTED.
+
+Examples:
+  web-recap recap --style standup --prompt-only | pbcopy
+  web-recap recap --style weekly -o week.md
+  web-recap recap --style daily --date 2025-12-15 --provider ollama
+`,
+	RunE: runRecap,
+}
+
+func init() {
+	recapCmd.Flags().StringVar(&recapStyle, "style", "daily", "Recap style: daily, weekly, or standup")
+	recapCmd.Flags().BoolVar(&recapPromptOnly, "prompt-only", false, "Print the prompt instead of calling an LLM")
+	recapCmd.Flags().StringVar(&recapPrompt, "prompt", "", "Extra instructions appended to the style's prompt")
+	recapCmd.Flags().DurationVar(&recapSessionGap, "session-gap", stats.DefaultSessionGap, "Idle time that starts a new session")
+	recapCmd.Flags().BoolVar(&recapKeepQuery, "keep-query", false, "Keep query strings and fragments in page URLs")
+	recapCmd.Flags().StringVar(&llmProvider, "provider", "", "LLM provider: openai, anthropic, or ollama (default: LLM_PROVIDER or config)")
+	recapCmd.Flags().StringVar(&llmModel, "model", "", "Model name (default: LLM_MODEL, config, or a provider default)")
+}
+
+func runRecap(cmd *cobra.Command, args []string) error {
+	style, err := recap.ParseStyle(recapStyle)
+	if err != nil {
+		return err
+	}
+
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	startTimeValue, endTimeValue := style.Range(time.Now(), loc)
+	if date != "" || startDate != "" || endDate != "" {
+		startTimeValue, endTimeValue, err = resolveTimeRange(loc)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, browserName, _, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+	// The prompt may go to a cloud LLM
+	redact.History(entries, redact.None)
+	domainAliases.Apply(entries)
+
+	data := recap.Build(entries, browserName, startTimeValue, endTimeValue, loc, recap.Options{
+		Style:      style,
+		SessionGap: recapSessionGap,
+	})
+	if !recapKeepQuery {
+		for i := range data.Stats.TopPages {
+			data.Stats.TopPages[i].URL = stripQuery(data.Stats.TopPages[i].URL)
+		}
+	}
+
+	prompt, err := recap.BuildPrompt(data, loc, recapPrompt)
+	if err != nil {
+		return err
+	}
+
+	text := prompt
+	if !recapPromptOnly {
+		if data.Stats.TotalVisits == 0 {
+			return fmt.Errorf("no history found in the selected range")
+		}
+		client, err := newLLMClient()
+		if err != nil {
+			return err
+		}
+		text, err = client.Complete(cmd.Context(), recap.SystemPrompt, prompt)
+		if err != nil {
+			return fmt.Errorf("failed to generate recap: %v", err)
+		}
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		_, err := fmt.Fprintln(out, text)
+		return err
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchLimit  int
+	searchFormat string
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Full-text search over archived page titles and URLs",
+	Long: `Search the history archive for pages whose title or URL contains every word of
+the query, ranked by relevance, with visit counts and first/last visit dates
+across all browsers.
+
+Words are stemmed ("cancel" also finds "cancellation") and a trailing * matches
+a prefix. Searches the whole archive unless date flags are given. Run
+'web-recap archive sync' first to build the archive.
+
+--format alfred writes the results as Alfred script filter JSON, e.g. for a
+"search my history" workflow; each item opens its URL.
+
+Examples:
+  web-recap search "rust async cancellation"
+  web-recap search kubernet* --browser firefox --limit 5
+  web-recap search postgres --start-date 2024-01-01
+  web-recap search "{query}" --format alfred      # Alfred script filter
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "Maximum number of results")
+	searchCmd.Flags().StringVar(&searchFormat, "format", "json", "Output format: json, or alfred (Alfred script filter JSON)")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+	if searchFormat != "json" && searchFormat != "alfred" {
+		return fmt.Errorf("unsupported format: %s (use json or alfred)", searchFormat)
+	}
+
+	opts := archive.SearchOptions{Limit: searchLimit}
+	opts.Browser, _ = archiveBrowser()
+
+	// Unlike history, search covers all time unless a range is given
+	if date != "" || startDate != "" || endDate != "" {
+		loc, err := getTimezone(timezone, utcMode)
+		if err != nil {
+			return err
+		}
+		start, end, err := resolveTimeRange(loc)
+		if err != nil {
+			return err
+		}
+		opts.Start, opts.End = start.UTC(), end.UTC()
+	}
+
+	a, err := openArchive()
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	results, err := a.Search(query, opts)
+	if err != nil {
+		return err
+	}
+	// The archive may hold visits synced before a domain was blocked
+	kept := results[:0]
+	for _, r := range results {
+		if !blocked.Blocks(r.Domain) {
+			kept = append(kept, r)
+		}
+	}
+	results = kept
+
+	return writeOutput(func(out io.Writer) error {
+		if searchFormat == "alfred" {
+			loc := displayLoc
+			if loc == nil {
+				loc = time.Local
+			}
+			return output.FormatSearchAlfred(out, results, loc)
+		}
+		return output.FormatSearchJSON(out, query, results)
+	})
+}
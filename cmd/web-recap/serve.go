@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rzolkos/web-recap/internal/grpcserver"
+	"github.com/rzolkos/web-recap/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen   string
+	metricsListen string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve history, bookmarks, tabs, and stats over gRPC",
+	Long: `Run a gRPC server for programmatic consumers that prefer typed clients.
+
+The service (proto/webrecap/v1/webrecap.proto) has four RPCs:
+  ListHistory    streams history entries, newest first
+  ListBookmarks  streams bookmarks, most recently added first
+  ListTabs       returns open tabs of Chromium-based browsers
+  GetStats       visit totals, daily counts, and top domains and pages
+
+History and bookmarks are streamed one entry per message, so large ranges
+don't need to fit in one response. Go clients can use the generated client in
+github.com/rzolkos/web-recap/pkg/webrecap/webrecapv1; other languages can
+generate one from the proto file.
+
+With --metrics-listen, Prometheus metrics are also served over HTTP at
+/metrics on that address:
+  web_recap_visits_last_hour                      visits in the last hour, by browser
+  web_recap_open_tabs                             open tabs of Chromium-based browsers
+  web_recap_up                                    whether a browser's history could be read
+  web_recap_archive_last_sync_timestamp_seconds   last archive sync or import, by browser and kind
+  web_recap_archive_entries                       archived visits or bookmarks, by browser and kind
+Metrics are collected when scraped, at most every 15 seconds.
+
+The server has no authentication and listens on localhost by default. Don't
+expose it on a shared network.
+
+Examples:
+  web-recap serve
+  web-recap serve --listen localhost:9090 --source-label laptop
+  web-recap serve --metrics-listen localhost:9337
+`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "localhost:7337", "Address to listen on")
+	serveCmd.Flags().StringVar(&metricsListen, "metrics-listen", "", "Also serve Prometheus metrics at /metrics on this address")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	lis, err := net.Listen("tcp", serveListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", serveListen, err)
+	}
+
+	server := grpcserver.New(sourceLabel)
+	server.Blocklist = blocked
+	srv := grpcserver.Register(server)
+
+	var metricsSrv *http.Server
+	if metricsListen != "" {
+		if metricsSrv, err = serveMetrics(metricsListen); err != nil {
+			lis.Close()
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		if metricsSrv != nil {
+			metricsSrv.Close()
+		}
+		srv.GracefulStop()
+	}()
+
+	statusf("Serving gRPC on %s", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		return fmt.Errorf("gRPC server failed: %v", err)
+	}
+	return nil
+}
+
+// serveMetrics starts serving Prometheus metrics at /metrics on addr
+func serveMetrics(addr string) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+	archivePath, err := resolveArchivePath()
+	if err != nil {
+		archivePath = ""
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", &metrics.Handler{ArchivePath: archivePath})
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+			warnf("metrics server failed: %v", err)
+		}
+	}()
+	statusf("Serving metrics on http://%s/metrics", lis.Addr())
+	return srv, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/site"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	siteOutputDir  string
+	siteDays       int
+	siteTitle      string
+	siteSessionGap time.Duration
+)
+
+var siteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Render browsing history into a static HTML journal",
+	Long: `Generate a static website from your browsing history: an index of days, a page
+per day (visits grouped into sessions), a page per domain, and a search page backed
+by a prebuilt index that works without a server.
+
+The site covers the last --days days (default 90, roughly what browsers retain)
+unless --start-date/--end-date are given. Existing files in the output directory
+are overwritten; re-run it on a schedule to keep the journal current.
+
+The output is plain HTML, CSS, and JavaScript, so it can be opened from disk or
+published to any static host. Pages are marked noindex, but your history is only
+as private as the place you publish it.
+
+Examples:
+  web-recap site --output-dir ./journal
+  web-recap site --output-dir ./journal --days 365 --title "Reading log"
+  web-recap site --output-dir ./journal --start-date 2025-01-01 --end-date 2025-12-31
+`,
+	RunE: runSite,
+}
+
+func init() {
+	siteCmd.Flags().StringVar(&siteOutputDir, "output-dir", "journal", "Directory to write the site to")
+	siteCmd.Flags().IntVar(&siteDays, "days", 90, "Number of days to include, ending today (ignored with --start-date/--end-date)")
+	siteCmd.Flags().StringVar(&siteTitle, "title", "Browsing journal", "Site title")
+	siteCmd.Flags().DurationVar(&siteSessionGap, "session-gap", stats.DefaultSessionGap, "Idle time that starts a new session on day pages")
+}
+
+func runSite(cmd *cobra.Command, args []string) error {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	if siteDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	// Default to the last N days including today
+	now := time.Now().In(loc)
+	endTimeValue := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	startTimeValue := endTimeValue.AddDate(0, 0, -siteDays)
+
+	if startDate != "" {
+		startTimeValue, err = parseDateTimeInLocation(startDate, "", loc)
+		if err != nil {
+			return err
+		}
+	}
+	if endDate != "" {
+		endTimeValue, err = parseDateTimeInLocation(endDate, "", loc)
+		if err != nil {
+			return err
+		}
+		endTimeValue = endTimeValue.AddDate(0, 0, 1)
+	}
+	if !endTimeValue.After(startTimeValue) {
+		return fmt.Errorf("end date must be after start date")
+	}
+
+	entries, _, _, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+
+	result, err := site.Generate(siteOutputDir, entries, site.Options{
+		Title:      siteTitle,
+		Location:   loc,
+		SessionGap: siteSessionGap,
+	})
+	if err != nil {
+		return err
+	}
+
+	statusf("Wrote %d day pages, %d domain pages, and a search index of %d pages to %s",
+		result.Days, result.Domains, result.Pages, siteOutputDir)
+	return nil
+}
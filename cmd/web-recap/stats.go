@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/config"
+	"github.com/rzolkos/web-recap/internal/heatmap"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsDays          int
+	statsHeatmap       bool
+	statsMetric        string
+	statsIdle          time.Duration
+	statsHeatmapFormat string
+	statsTitle         string
+	statsCoOccurrence  bool
+	statsSessionGap    time.Duration
+	statsMinSessions   int
+	statsTop           int
+	statsReading       bool
+	statsWPM           int
+	statsBudgets       bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report daily visit counts, draw them as a calendar heatmap, find domains used together, time spent reading, or time budget overruns",
+	Long: `Count the visits on every day of the range, with the minutes of browsing
+estimated from the gaps between visits (a gap longer than --idle counts as time
+away). The range is the last --days days (default 365) unless --date or
+--start-date/--end-date are given. Days are bucketed in your local timezone (or
+--tz / --utc).
+
+With --heatmap, the days are drawn as a GitHub-style calendar instead: a column
+per week, shaded by the day's visits, or by its estimated minutes with
+--metric time. It is SVG, with month labels and a tooltip per day, or PNG
+(cells only) when --output ends in .png or --heatmap-format png is given.
+
+With --co-occurrence, history is cut into sessions wherever more than
+--session-gap passes without a visit, and every pair of domains used in the
+same session is counted. Each pair has its lift: how many times more often the
+two share a session than if they were used independently. Pairs are ordered by
+lift, so tools used together (github.com, stackoverflow.com, docs.rs) come
+first; --min-sessions drops pairs seen together too rarely to tell.
+
+With --reading, the time is split between reading and apps. Pages that look
+like articles (news, blog posts, documentation) count as reading, each with
+its own estimate; everything else counts as time in apps. A visit's time is
+the foreground time Chromium browsers record for it, or else the gap until the
+next visit. With --fetch-content, each article is fetched and its reading time
+is its word count at --wpm words a minute instead.
+
+With --budgets, the time spent each day in each category of sites is checked
+against the daily budgets in the config file, and the days a category went
+over its budget are listed under overruns. A visit's time is counted as for
+--reading. Categories are built in (social, video, news, shopping) and can be
+replaced or added to in the config file:
+
+  {
+    "categories": {"social": ["reddit.com", "x.com"], "chat": ["discord.com"]},
+    "budgets": {"social": "60m", "video": "1h30m"}
+  }
+
+Examples:
+  web-recap stats                                   # Daily counts for the last year, as JSON
+  web-recap stats --heatmap -o heatmap.svg
+  web-recap stats --heatmap --metric time -o heatmap.png
+  web-recap stats --heatmap --start-date 2025-01-01 --end-date 2025-12-31 --title "2025 in tabs" -o 2025.svg
+  web-recap stats --co-occurrence --days 90 --min-sessions 5 --top 30
+  web-recap stats --reading --days 7 --fetch-content
+  web-recap stats --budgets --days 7
+`,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().IntVar(&statsDays, "days", 365, "Number of days to include, ending today (ignored with --date or --start-date/--end-date)")
+	statsCmd.Flags().BoolVar(&statsHeatmap, "heatmap", false, "Draw a calendar heatmap instead of writing JSON")
+	statsCmd.Flags().StringVar(&statsMetric, "metric", "visits", "What shades the heatmap: visits, or time (estimated minutes)")
+	statsCmd.Flags().DurationVar(&statsIdle, "idle", stats.DefaultIdleGap, "Longest gap between visits still counted as browsing time")
+	statsCmd.Flags().StringVar(&statsHeatmapFormat, "heatmap-format", "", "Heatmap image format: svg or png (default: png for a .png --output, otherwise svg)")
+	statsCmd.Flags().StringVar(&statsTitle, "title", "", "Title drawn above the SVG heatmap")
+	statsCmd.Flags().BoolVar(&statsCoOccurrence, "co-occurrence", false, "Report the pairs of domains used in the same sessions, with their lift")
+	statsCmd.Flags().DurationVar(&statsSessionGap, "session-gap", stats.DefaultSessionGap, "With --co-occurrence, idle time that starts a new session")
+	statsCmd.Flags().IntVar(&statsMinSessions, "min-sessions", 3, "With --co-occurrence, leave out pairs used together in fewer sessions than this")
+	statsCmd.Flags().IntVar(&statsTop, "top", 50, "With --co-occurrence or --reading, limit output to the top N pairs or articles (0 = no limit)")
+	statsCmd.Flags().BoolVar(&statsReading, "reading", false, "Report time reading articles, each with its reading time, against time in apps")
+	statsCmd.Flags().IntVar(&statsWPM, "wpm", stats.DefaultWordsPerMinute, "With --reading, words read a minute, for articles with a word count")
+	statsCmd.Flags().BoolVar(&statsBudgets, "budgets", false, "Report the time spent in each category per day against the budgets in the config file")
+	statsCmd.Flags().BoolVar(&fetchContent, "fetch-content", false, "With --reading, fetch each article to estimate its reading time from its word count (uses the network; pages are cached for a week)")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	modes := 0
+	for _, on := range []bool{statsHeatmap, statsCoOccurrence, statsReading, statsBudgets} {
+		if on {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return fmt.Errorf("choose only one of --heatmap, --co-occurrence, --reading, and --budgets")
+	}
+	if fetchContent && !statsReading {
+		return fmt.Errorf("--fetch-content requires --reading")
+	}
+	if fetchContent && redacting {
+		return fmt.Errorf("--fetch-content cannot be combined with --redact")
+	}
+	if statsWPM <= 0 {
+		return fmt.Errorf("--wpm must be positive")
+	}
+	var categories stats.Categories
+	var budgets map[string]time.Duration
+	if statsBudgets {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if len(cfg.Budgets) == 0 {
+			return fmt.Errorf("--budgets needs daily budgets in the config file, e.g. \"budgets\": {\"social\": \"60m\"}")
+		}
+		if budgets, err = stats.ParseBudgets(cfg.Budgets); err != nil {
+			return err
+		}
+		categories = stats.NewCategories(cfg.Categories)
+		for name := range budgets {
+			if !categories.Has(name) {
+				return fmt.Errorf("budget for unknown category %q: add its sites under \"categories\" in the config file", name)
+			}
+		}
+	}
+	if statsMetric != "visits" && statsMetric != "time" {
+		return fmt.Errorf("invalid --metric %q (use visits or time)", statsMetric)
+	}
+	imageFormat := statsHeatmapFormat
+	if imageFormat == "" {
+		imageFormat = "svg"
+		if strings.EqualFold(filepath.Ext(outputFile), ".png") {
+			imageFormat = "png"
+		}
+	}
+	if imageFormat != "svg" && imageFormat != "png" {
+		return fmt.Errorf("invalid --heatmap-format %q (use svg or png)", imageFormat)
+	}
+	if statsDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	// Default to the last N days including today
+	now := time.Now().In(loc)
+	endTimeValue := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	startTimeValue := endTimeValue.AddDate(0, 0, -statsDays)
+	if date != "" || startDate != "" || endDate != "" {
+		if startTimeValue, endTimeValue, err = resolveTimeRange(loc); err != nil {
+			return err
+		}
+	}
+	if !endTimeValue.After(startTimeValue) {
+		return fmt.Errorf("end date must be after start date")
+	}
+
+	entries, browserName, sources, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+	// Budgets go by the sites listed in categories, so only the other modes
+	// count rolled-up services
+	if statsBudgets {
+		return writeBudgets(entries, browserName, startTimeValue, endTimeValue, loc, categories, budgets, sources)
+	}
+	domainAliases.Apply(entries)
+	if statsCoOccurrence {
+		return writeDomainPairs(entries, browserName, startTimeValue, endTimeValue, sources)
+	}
+	if statsReading {
+		return writeReading(cmd.Context(), entries, browserName, startTimeValue, endTimeValue, sources)
+	}
+	days := stats.DailyActivity(entries, startTimeValue, endTimeValue, loc, statsIdle)
+
+	report := models.DailyActivityReport{
+		Browser:   browserName,
+		StartDate: startTimeValue.UTC(),
+		EndDate:   endTimeValue.UTC(),
+		Timezone:  reportTimezone(),
+		Meta:      newReportMeta(sources),
+		Sources:   sources,
+		Warnings:  output.SourceWarnings(sources),
+		TotalDays: len(days),
+		Days:      days,
+	}
+	for _, d := range days {
+		report.TotalVisits += d.Visits
+		if d.Visits > 0 {
+			report.ActiveDays++
+		}
+	}
+
+	if err := writeOutput(func(out io.Writer) error {
+		if !statsHeatmap {
+			return output.FormatDailyActivityJSON(out, report)
+		}
+		cells := heatmapDays(days)
+		if imageFormat == "png" {
+			return heatmap.PNG(out, cells, heatmap.Options{})
+		}
+		return heatmap.SVG(out, cells, heatmap.Options{Title: statsTitle})
+	}); err != nil {
+		return err
+	}
+	recordOutcome(report.TotalVisits, sources)
+	return nil
+}
+
+// heatmapDays turns daily activity into heatmap cells valued by --metric
+func heatmapDays(days []models.DailyActivity) []heatmap.Day {
+	cells := make([]heatmap.Day, 0, len(days))
+	for _, d := range days {
+		date, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		cell := heatmap.Day{Date: date, Value: float64(d.Visits)}
+		switch {
+		case statsMetric == "time":
+			cell.Value = d.EstimatedMinutes
+			cell.Label = fmt.Sprintf("%s: %.0f minutes", d.Date, d.EstimatedMinutes)
+		case d.Visits == 1:
+			cell.Label = d.Date + ": 1 visit"
+		default:
+			cell.Label = fmt.Sprintf("%s: %d visits", d.Date, d.Visits)
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// writeDomainPairs writes the domains used together in the sessions of
+// entries, for --co-occurrence
+func writeDomainPairs(entries []models.HistoryEntry, browserName string, startTimeValue, endTimeValue time.Time, sources []models.SourceStatus) error {
+	pairs, sessions := stats.DomainPairs(entries, statsSessionGap, statsMinSessions)
+	report := models.DomainPairReport{
+		Browser:           browserName,
+		StartDate:         startTimeValue.UTC(),
+		EndDate:           endTimeValue.UTC(),
+		Timezone:          reportTimezone(),
+		Meta:              newReportMeta(sources),
+		Sources:           sources,
+		Warnings:          output.SourceWarnings(sources),
+		SessionGapMinutes: int(statsSessionGap / time.Minute),
+		TotalSessions:     sessions,
+		TotalPairs:        len(pairs),
+		Pairs:             pairs,
+	}
+	if statsTop > 0 && len(report.Pairs) > statsTop {
+		report.Pairs = report.Pairs[:statsTop]
+	}
+	if err := writeOutput(func(out io.Writer) error {
+		return output.FormatDomainPairsJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(len(entries), sources)
+	return nil
+}
+
+// writeReading writes the time spent reading articles in entries against
+// the time in apps, for --reading
+func writeReading(ctx context.Context, entries []models.HistoryEntry, browserName string, startTimeValue, endTimeValue time.Time, sources []models.SourceStatus) error {
+	var fetchFailed int
+	if fetchContent {
+		fetchFailed = addWordCounts(ctx, entries)
+	}
+
+	articles, reading, apps := stats.EstimateReading(entries, statsIdle, statsWPM)
+	report := models.ReadingReport{
+		Browser:        browserName,
+		StartDate:      startTimeValue.UTC(),
+		EndDate:        endTimeValue.UTC(),
+		Timezone:       reportTimezone(),
+		Meta:           newReportMeta(sources),
+		Sources:        sources,
+		Warnings:       output.SourceWarnings(sources),
+		WordsPerMinute: statsWPM,
+		ReadingMinutes: reading,
+		AppMinutes:     apps,
+		TotalArticles:  len(articles),
+		Articles:       articles,
+	}
+	if fetchFailed > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d pages could not be fetched for --fetch-content", fetchFailed))
+	}
+	if statsTop > 0 && len(report.Articles) > statsTop {
+		report.Articles = report.Articles[:statsTop]
+	}
+	if err := writeOutput(func(out io.Writer) error {
+		return output.FormatReadingJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(len(entries), sources)
+	return nil
+}
+
+// addWordCounts fetches the articles in entries and sets their word counts,
+// returning the number of pages that could not be fetched
+func addWordCounts(ctx context.Context, entries []models.HistoryEntry) int {
+	var articles []models.HistoryEntry
+	for _, e := range entries {
+		if stats.ContentLike(e.URL) {
+			articles = append(articles, e)
+		}
+	}
+	failed := addPageContent(ctx, articles)
+
+	words := make(map[string]int, len(articles))
+	for _, a := range articles {
+		words[a.URL] = a.Words
+	}
+	for i := range entries {
+		entries[i].Words = words[entries[i].URL]
+	}
+	return failed
+}
+
+// writeBudgets writes the time spent in each budgeted category per day of
+// entries, flagging the days over budget, for --budgets
+func writeBudgets(entries []models.HistoryEntry, browserName string, startTimeValue, endTimeValue time.Time, loc *time.Location, categories stats.Categories, budgets map[string]time.Duration, sources []models.SourceStatus) error {
+	days := stats.CheckBudgets(entries, startTimeValue, endTimeValue, loc, statsIdle, categories, budgets)
+	report := models.BudgetReport{
+		Browser:   browserName,
+		StartDate: startTimeValue.UTC(),
+		EndDate:   endTimeValue.UTC(),
+		Timezone:  reportTimezone(),
+		Meta:      newReportMeta(sources),
+		Sources:   sources,
+		Warnings:  output.SourceWarnings(sources),
+		Budgets:   make(map[string]float64, len(budgets)),
+		Days:      days,
+	}
+	for name, limit := range budgets {
+		report.Budgets[name] = limit.Minutes()
+	}
+	// Alert on stderr only for the last day, today by default
+	lastDay := endTimeValue.Add(-time.Nanosecond).In(loc).Format("2006-01-02")
+	for _, d := range days {
+		over := false
+		for _, c := range d.Categories {
+			if !c.Over {
+				continue
+			}
+			over = true
+			report.Overruns = append(report.Overruns, models.BudgetOverrun{Date: d.Date, Category: c.Category, Minutes: c.Minutes, BudgetMinutes: c.BudgetMinutes})
+			if d.Date == lastDay {
+				warnf("%s: %g minutes on %s, over the %g minute budget", d.Date, c.Minutes, c.Category, c.BudgetMinutes)
+			}
+		}
+		if over {
+			report.OverrunDays++
+		}
+	}
+
+	if err := writeOutput(func(out io.Writer) error {
+		return output.FormatBudgetsJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(len(entries), sources)
+	return nil
+}
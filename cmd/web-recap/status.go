@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+var (
+	quietMode bool
+	noColor   bool
+)
+
+// ANSI styles for the Warning: and Error: prefixes
+const (
+	styleWarning = "\x1b[33m"
+	styleError   = "\x1b[1;31m"
+	styleReset   = "\x1b[0m"
+)
+
+// colorStderr reports whether stderr gets colored prefixes: only on a
+// terminal, and never with --no-color, NO_COLOR set, or TERM=dumb, so a
+// redirected log or a wrapper parsing stderr sees plain text
+func colorStderr() bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// styled returns prefix in style when stderr is colored
+func styled(style, prefix string) string {
+	if !colorStderr() {
+		return prefix
+	}
+	return style + prefix + styleReset
+}
+
+// statusf writes a progress or status line to stderr, unless --quiet
+func statusf(format string, args ...interface{}) {
+	if quietMode {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// warnf writes a warning to stderr, unless --quiet
+func warnf(format string, args ...interface{}) {
+	if quietMode {
+		return
+	}
+	fmt.Fprintf(os.Stderr, styled(styleWarning, "Warning:")+" "+format+"\n", args...)
+}
+
+// errorf writes an error to stderr; --quiet does not silence errors
+func errorf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, styled(styleError, "Error:")+" "+format+"\n", args...)
+}
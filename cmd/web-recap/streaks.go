@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	streakDays    int
+	streakMinDays int
+	streakTop     int
+)
+
+var streaksCmd = &cobra.Command{
+	Use:   "streaks",
+	Short: "Report per-domain visit streaks and habits",
+	Long: `Analyze history over a long range and report, for every domain, the current and
+longest run of consecutive days it was visited, first/last seen dates, and how
+consistently it was visited (percentage of days in the range with at least one visit).
+
+Days are bucketed in your local timezone (or --tz / --utc).
+
+Examples:
+  web-recap streaks                                   # Last 90 days, all browsers
+  web-recap streaks --days 30 --top 10                # Top 10 domains over the last 30 days
+  web-recap streaks --start-date 2025-01-01 --end-date 2025-06-30
+  web-recap streaks --browser firefox --min-days 5 -o streaks.json
+`,
+	RunE: runStreaks,
+}
+
+func init() {
+	streaksCmd.Flags().IntVar(&streakDays, "days", 90, "Number of days to analyze, ending today (ignored with --start-date/--end-date)")
+	streaksCmd.Flags().IntVar(&streakMinDays, "min-days", 2, "Only include domains visited on at least this many days")
+	streaksCmd.Flags().IntVar(&streakTop, "top", 0, "Limit output to the top N domains (0 = no limit)")
+}
+
+func runStreaks(cmd *cobra.Command, args []string) error {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	if streakDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	// Default to the last N days including today
+	now := time.Now().In(loc)
+	endTimeValue := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	startTimeValue := endTimeValue.AddDate(0, 0, -streakDays)
+
+	if startDate != "" {
+		startTimeValue, err = parseDateTimeInLocation(startDate, "", loc)
+		if err != nil {
+			return err
+		}
+	}
+	if endDate != "" {
+		endTimeValue, err = parseDateTimeInLocation(endDate, "", loc)
+		if err != nil {
+			return err
+		}
+		endTimeValue = endTimeValue.AddDate(0, 0, 1)
+	}
+	if !endTimeValue.After(startTimeValue) {
+		return fmt.Errorf("end date must be after start date")
+	}
+
+	entries, browserName, _, err := queryHistory(cmd.Context(), startTimeValue.UTC(), endTimeValue.UTC())
+	if err != nil {
+		return err
+	}
+
+	domainAliases.Apply(entries)
+	streaks := stats.ComputeStreaks(entries, startTimeValue, endTimeValue, loc, streakMinDays)
+	if streakTop > 0 && len(streaks) > streakTop {
+		streaks = streaks[:streakTop]
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		totalDays := stats.CountDays(startTimeValue, endTimeValue, loc)
+		return output.FormatStreaksJSON(out, streaks, browserName, startTimeValue.UTC(), endTimeValue.UTC(), totalDays, reportTimezone())
+	})
+}
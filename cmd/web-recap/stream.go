@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/demo"
+	"github.com/rzolkos/web-recap/internal/incremental"
+	"github.com/rzolkos/web-recap/internal/lang"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/redact"
+	"github.com/rzolkos/web-recap/internal/urlutil"
+)
+
+// historySource reads history for the browser selected by the global flags
+// one entry at a time. name is the browser name for the report, known
+// before any entry is read.
+type historySource struct {
+	name string
+	each func(fn func(models.HistoryEntry) error) ([]models.SourceStatus, error)
+}
+
+// openHistorySource resolves the browser selected by the flags, like
+// queryHistory, without reading its history yet
+func openHistorySource(ctx context.Context, startTimeValue, endTimeValue time.Time) (*historySource, error) {
+	// The archive query is not streamed
+	if dataSource == sourceArchive {
+		_, name := archiveBrowser()
+		return &historySource{name: name, each: func(fn func(models.HistoryEntry) error) ([]models.SourceStatus, error) {
+			entries, _, err := queryArchiveHistory(startTimeValue, endTimeValue)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				if err := fn(labelEntry(e)); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}}, nil
+	}
+
+	if demoMode {
+		return &historySource{name: "all", each: func(fn func(models.HistoryEntry) error) ([]models.SourceStatus, error) {
+			entries := setHosts(demo.History(startTimeValue, endTimeValue))
+			for _, e := range entries {
+				if err := fn(labelEntry(e)); err != nil {
+					return nil, err
+				}
+			}
+			return demo.Sources(entries), nil
+		}}, nil
+	}
+
+	detector := newDetector()
+	if allBrowsers || browserType == "auto" {
+		return &historySource{name: "all", each: func(fn func(models.HistoryEntry) error) ([]models.SourceStatus, error) {
+			var writeErr error
+			sources, err := database.StreamMultipleBrowsers(ctx, detector, startTimeValue, endTimeValue, func(e models.HistoryEntry) error {
+				writeErr = fn(labelEntry(e))
+				return writeErr
+			})
+			if writeErr != nil {
+				return sources, writeErr
+			}
+			if err != nil {
+				return sources, fmt.Errorf("failed to query browsers: %v", err)
+			}
+			warnSources(sources)
+			return sources, nil
+		}}, nil
+	}
+
+	b, err := historyBrowser(detector)
+	if err != nil {
+		return nil, err
+	}
+	return &historySource{name: b.Name, each: func(fn func(models.HistoryEntry) error) ([]models.SourceStatus, error) {
+		var writeErr error
+		status := models.SourceStatus{Browser: string(b.Type), Name: b.Name, Path: b.Path}
+		err := database.Stream(ctx, b, startTimeValue, endTimeValue, func(e models.HistoryEntry) error {
+			if writeErr = fn(labelEntry(e)); writeErr == nil {
+				status.Entries++
+			}
+			return writeErr
+		})
+		if writeErr != nil {
+			return nil, writeErr
+		}
+		if err != nil {
+			return nil, browserError(fmt.Errorf("failed to query history: %v", err), err, b.Type, b.Path)
+		}
+		return []models.SourceStatus{status}, nil
+	}}, nil
+}
+
+// labelEntry applies --canonicalize, records --source-label and the title's
+// language on e, and applies --redact. Archived entries keep the label they
+// were synced with.
+func labelEntry(e models.HistoryEntry) models.HistoryEntry {
+	if canonicalizeURLs {
+		e = canonicalizeEntry(e)
+	}
+	if langFilter != nil || detectLanguage {
+		e.Language = lang.Detect(e.Title)
+	}
+	if sourceLabel != "" && dataSource != sourceArchive {
+		e.Source = sourceLabel
+	}
+	if redacting {
+		e = redact.Entry(e, redactMode)
+	}
+	return e
+}
+
+// streamHistory writes history in a stream format (jsonl, csv, or compact)
+// as it is read, so memory stays flat for long all-browser ranges. With
+// --incremental or --since-last-run, entries are filtered by state and the
+// marks advanced once the output is written.
+func streamHistory(ctx context.Context, startTimeValue, endTimeValue time.Time, state *incremental.State) error {
+	src, err := openHistorySource(ctx, startTimeValue, endTimeValue)
+	if err != nil {
+		return err
+	}
+
+	var sources []models.SourceStatus
+	total := 0
+	newest := make(map[string]models.HistoryEntry)
+	err = writeOutput(func(out io.Writer) error {
+		header := output.NewHistoryReport(nil, src.name, startTimeValue, endTimeValue, reportTimezone())
+		header.Source = sourceLabel
+		output.LocalizeHistoryReport(&header, displayLoc)
+		stream, err := output.NewHistoryStream(out, format, header)
+		if err != nil {
+			return err
+		}
+
+		sources, err = src.each(func(e models.HistoryEntry) error {
+			if blocked.Blocks(e.Domain) || (excludeInternal && urlutil.Internal(e.URL)) || !langFilter.Keep(e.Language) {
+				return nil
+			}
+			if state != nil {
+				if !state.Keep(e) {
+					return nil
+				}
+				if key := incremental.Key(e); e.Timestamp.After(newest[key].Timestamp) {
+					newest[key] = e
+				}
+			}
+			total++
+			if displayLoc != nil {
+				e.Timestamp = e.Timestamp.In(displayLoc)
+			}
+			return stream.WriteEntry(e)
+		})
+		if err != nil {
+			return err
+		}
+
+		header.TotalEntries = total
+		header.Meta = newReportMeta(sources)
+		header.Sources = sources
+		header.Warnings = output.SourceWarnings(sources)
+		return stream.Close(header)
+	})
+	if err != nil {
+		return err
+	}
+	recordOutcome(total, sources)
+
+	// Only advance the state once the output has been written
+	if state != nil {
+		if sinceLastRun {
+			state.MarkRun(readBrowsers(sources), endTimeValue)
+		} else {
+			for _, e := range newest {
+				state.Advance([]models.HistoryEntry{e})
+			}
+		}
+		return state.Save(statePath)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/redact"
+)
+
+func TestStreamArchiveRedacted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.db")
+	a, err := archive.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	rawURL := "https://mail.example/inbox?user=me@example.com&token=abc123"
+	if _, err := a.AddVisits("chrome", []models.HistoryEntry{
+		{Timestamp: base, URL: rawURL, Title: "Inbox - me@example.com", Domain: "mail.example", VisitCount: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+
+	saved := []any{dataSource, archivePath, browserType, redacting, redactMode, format, outputFile}
+	defer func() {
+		dataSource, archivePath, browserType = saved[0].(string), saved[1].(string), saved[2].(string)
+		redacting, redactMode = saved[3].(bool), saved[4].(redact.Mode)
+		format, outputFile = saved[5].(string), saved[6].(string)
+	}()
+	dataSource, archivePath, browserType = sourceArchive, path, "auto"
+	redacting, redactMode = true, redact.Hash
+
+	for _, f := range []string{"jsonl", "csv", "compact"} {
+		format = f
+		outputFile = filepath.Join(dir, "history."+f)
+		if err := streamHistory(context.Background(), base.Add(-time.Hour), base.Add(time.Hour), nil); err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(data)
+		if strings.Contains(got, "me@example.com") || strings.Contains(got, "abc123") || strings.Contains(got, "Inbox") {
+			t.Errorf("%s output not redacted:\n%s", f, got)
+		}
+		if !strings.Contains(got, "sha256:") {
+			t.Errorf("%s output has no hashed URL:\n%s", f, got)
+		}
+	}
+}
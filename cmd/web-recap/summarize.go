@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/rzolkos/web-recap/internal/budget"
+	"github.com/rzolkos/web-recap/internal/config"
+	"github.com/rzolkos/web-recap/internal/llm"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var (
+	llmProvider          string
+	llmModel             string
+	summarizePrompt      string
+	summarizeInputTokens int
+	summarizeKeepQuery   bool
+)
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize",
+	Short: "Summarize browsing history into a narrative recap using an LLM",
+	Long: `Send the history report for the selected range to an LLM and print a narrative
+summary of what you worked on and read.
+
+Supported backends are OpenAI, Anthropic, and a local Ollama server. Settings are
+resolved from flags, then environment variables, then the "llm" section of the
+config file:
+
+  LLM_PROVIDER        openai, anthropic, or ollama
+  LLM_MODEL           model name (a small default is used per provider)
+  OPENAI_API_KEY      OpenAI key (OPENAI_BASE_URL for compatible APIs)
+  ANTHROPIC_API_KEY   Anthropic key
+  OLLAMA_HOST         Ollama server (default: http://localhost:11434)
+
+Without an explicit provider, Anthropic or OpenAI is used when its API key is set,
+otherwise a local Ollama server, which keeps history on your machine.
+
+Before sending, query strings and fragments are stripped from URLs (they often
+carry tokens and personal data; use --keep-query to send them), emails and
+token-like query values are replaced with REDACTED, and the report is reduced to
+fit --max-tokens. Use --redact to hash or trim the URLs themselves.
+
+Examples:
+  web-recap summarize                                  # Summarize today's history
+  web-recap summarize --date 2025-12-15 --provider ollama --model llama3.1
+  web-recap summarize --start-date 2025-12-01 --end-date 2025-12-07 --prompt "Focus on work topics"
+  ANTHROPIC_API_KEY=... web-recap summarize -o recap.md
+  web-recap summarize --redact path-trim               # Send only the first path segment of each URL
+`,
+	RunE: runSummarize,
+}
+
+func init() {
+	summarizeCmd.Flags().StringVar(&llmProvider, "provider", "", "LLM provider: openai, anthropic, or ollama (default: LLM_PROVIDER or config)")
+	summarizeCmd.Flags().StringVar(&llmModel, "model", "", "Model name (default: LLM_MODEL, config, or a provider default)")
+	summarizeCmd.Flags().StringVar(&summarizePrompt, "prompt", "", "Extra instructions appended to the recap prompt")
+	summarizeCmd.Flags().IntVar(&summarizeInputTokens, "max-tokens", 12000, "Token budget for the history sent to the LLM")
+	summarizeCmd.Flags().BoolVar(&summarizeKeepQuery, "keep-query", false, "Send URLs with their query strings and fragments")
+}
+
+func runSummarize(cmd *cobra.Command, args []string) error {
+	client, err := newLLMClient()
+	if err != nil {
+		return err
+	}
+
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+	startTimeValue, endTimeValue, err := resolveTimeRange(loc)
+	if err != nil {
+		return err
+	}
+	startTimeValue = startTimeValue.UTC()
+	endTimeValue = endTimeValue.UTC()
+
+	entries, browserName, _, err := queryHistory(cmd.Context(), startTimeValue, endTimeValue)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no history found in the selected range")
+	}
+	if !summarizeKeepQuery {
+		entries = stripQueries(entries)
+	}
+	redact.History(entries, redact.None)
+
+	report := output.NewHistoryReport(entries, browserName, startTimeValue, endTimeValue, reportTimezone())
+	report.Source = sourceLabel
+	output.LocalizeHistoryReport(&report, displayLoc)
+	if summarizeInputTokens > 0 {
+		report, err = budget.FitHistoryReport(report, summarizeInputTokens)
+		if err != nil {
+			return err
+		}
+	}
+
+	prompt, err := llm.BuildSummaryPrompt(report, summarizePrompt)
+	if err != nil {
+		return err
+	}
+
+	summary, err := client.Complete(cmd.Context(), llm.SummarySystemPrompt, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to summarize history: %v", err)
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		_, err := fmt.Fprintln(out, summary)
+		return err
+	})
+}
+
+// newLLMClient creates an LLM client from the --provider/--model flags, the
+// environment, and the config file
+func newLLMClient() (llm.Client, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	flagConfig := llm.Config{Model: llmModel}
+	if llmProvider != "" {
+		flagConfig.Provider, err = llm.ParseProvider(llmProvider)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return llm.NewClient(llm.ResolveConfig(flagConfig, cfg.LLM))
+}
+
+// stripQueries returns a copy of entries with query strings and fragments
+// removed from URLs
+func stripQueries(entries []models.HistoryEntry) []models.HistoryEntry {
+	result := make([]models.HistoryEntry, len(entries))
+	for i, e := range entries {
+		e.URL = stripQuery(e.URL)
+		result[i] = e
+	}
+	return result
+}
+
+// stripQuery removes the query string and fragment from a URL
+func stripQuery(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	siteTimeIdle time.Duration
+	siteTimeTop  int
+)
+
+var timeOnSiteCmd = &cobra.Command{
+	Use:   "time-on-site",
+	Short: "Report time per domain recorded by Chromium-based browsers",
+	Long: `Report how long you spent on each domain, side by side from two sources:
+
+  browser_minutes    foreground time Chrome records for every visit (visit_duration;
+                     segment_duration on very old profiles)
+  segment_visits     Chrome's own daily per-site visit counts (segments/segment_usage)
+  estimated_minutes  web-recap's estimate from gaps between history visits, where a
+                     visit is credited until the next one unless the gap exceeds --idle
+
+Only Chromium-based browsers (Chrome, Edge, Brave, Vivaldi, Whale, and other forks) record these
+figures. With --browser auto (the default) every detected Chromium browser is summed.
+Segment counts are bucketed by day, so partial days count in full.
+
+Examples:
+  web-recap time-on-site                              # Today
+  web-recap time-on-site --start-date 2025-12-01 --end-date 2025-12-07 --top 20
+  web-recap time-on-site --browser brave --idle 5m
+`,
+	RunE: runTimeOnSite,
+}
+
+func init() {
+	timeOnSiteCmd.Flags().DurationVar(&siteTimeIdle, "idle", stats.DefaultIdleGap, "Longest gap between visits still counted as time on the first page")
+	timeOnSiteCmd.Flags().IntVar(&siteTimeTop, "top", 0, "Limit output to the top N domains (0 = no limit)")
+}
+
+func runTimeOnSite(cmd *cobra.Command, args []string) error {
+	if dataSource == sourceArchive {
+		return fmt.Errorf("time-on-site reads Chrome's own usage tables and cannot use --source archive")
+	}
+
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+
+	startTimeValue, endTimeValue, err := resolveTimeRange(loc)
+	if err != nil {
+		return err
+	}
+	startTimeValue = startTimeValue.UTC()
+	endTimeValue = endTimeValue.UTC()
+
+	browsers, browserName, err := chromiumBrowsers("time on site")
+	if err != nil {
+		return err
+	}
+
+	var entries []models.HistoryEntry
+	var usage []models.SiteUsage
+	for i := range browsers {
+		b := &browsers[i]
+		visits, err := database.Query(cmd.Context(), b, startTimeValue, endTimeValue)
+		if err != nil {
+			if len(browsers) == 1 || cmd.Context().Err() != nil {
+				return fmt.Errorf("failed to query history: %v", err)
+			}
+			warnf("%s: %v", b.Name, err)
+			continue
+		}
+		siteUsage, err := database.QuerySiteUsage(cmd.Context(), b, startTimeValue, endTimeValue)
+		if err != nil {
+			if len(browsers) == 1 || cmd.Context().Err() != nil {
+				return fmt.Errorf("failed to query site usage: %v", err)
+			}
+			warnf("%s: %v", b.Name, err)
+			continue
+		}
+		entries = append(entries, blocked.History(visits)...)
+		for _, u := range siteUsage {
+			if !blocked.Blocks(u.Domain) {
+				u.Domain = domainAliases.Of(u.Domain)
+				usage = append(usage, u)
+			}
+		}
+	}
+
+	domainAliases.Apply(entries)
+	sites := stats.BuildSiteTime(entries, usage, siteTimeIdle)
+	if siteTimeTop > 0 && len(sites) > siteTimeTop {
+		sites = sites[:siteTimeTop]
+	}
+
+	// Write output
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatSiteTimeJSON(out, sites, browserName, startTimeValue, endTimeValue, siteTimeIdle, reportTimezone())
+	})
+}
+
+// chromiumBrowsers resolves --browser/--db-path/--all-browsers to the
+// Chromium-based browsers to read for feature, named in errors
+func chromiumBrowsers(feature string) ([]browser.Browser, string, error) {
+	detector := newDetector()
+
+	if allBrowsers || browserType == "auto" {
+		if dbPath != "" {
+			return nil, "", fmt.Errorf("--browser is required when using --db-path")
+		}
+		var browsers []browser.Browser
+		for _, b := range detector.Detect() {
+			if browser.IsChromiumBased(b.Type) {
+				browsers = append(browsers, b)
+			}
+		}
+		if len(browsers) == 0 {
+			return nil, "", fmt.Errorf("no Chromium-based browser found")
+		}
+		return browsers, "all", nil
+	}
+
+	bType := browser.Type(browserType)
+	if !browser.IsChromiumBased(bType) {
+		return nil, "", fmt.Errorf("%s is only supported for Chromium-based browsers (chrome, chromium, edge, brave, vivaldi, whale, samsung, yandex, arc)", feature)
+	}
+
+	if dbPath != "" {
+		info, err := os.Stat(dbPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, "", fmt.Errorf("database file not found: %s", dbPath)
+			}
+			return nil, "", fmt.Errorf("cannot access database file: %v", err)
+		}
+		if info.IsDir() {
+			return nil, "", fmt.Errorf("path is a directory, not a file: %s", dbPath)
+		}
+		return []browser.Browser{{Type: bType, Name: string(bType), Path: dbPath}}, string(bType), nil
+	}
+
+	b, err := detector.GetBrowser(bType)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get browser: %v", err)
+	}
+	return []browser.Browser{*b}, b.Name, nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/triage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	triageFolder        string
+	triageApply         bool
+	triageFromPlan      string
+	triageBookmarksFile string
+)
+
+var tabsTriageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Work through open tabs one key at a time and write an action plan",
+	Long: `Step through your open tabs and decide what to do with each one with a single key:
+
+  k  keep      b  bookmark to a folder    r  add to the read-later queue
+  c  close     s  skip                    u  undo    q  quit and save
+
+The decisions are written as a JSON action plan (-o, default tab-triage.json) that
+scripts can act on. With --apply, bookmark decisions are added to the browser's
+bookmarks under "Other bookmarks/<folder>". Close the browser first: a running
+browser overwrites its bookmarks file. The original file is kept as
+Bookmarks.web-recap.bak.
+
+--apply needs a specific Chromium-based --browser (or --bookmarks-file). Use
+--from-plan to apply a plan saved earlier without triaging again.
+
+Examples:
+  web-recap tabs triage --browser chrome
+  web-recap tabs triage --browser brave --folder "To read" -o plan.json
+  web-recap tabs triage --browser chrome --from-plan plan.json --apply
+`,
+	RunE: runTabsTriage,
+}
+
+func init() {
+	tabsTriageCmd.Flags().StringVar(&triageFolder, "folder", "Triage", "Default bookmark folder (nested folders with '/')")
+	tabsTriageCmd.Flags().BoolVar(&triageApply, "apply", false, "Add bookmark decisions to the browser's bookmarks (browser must be closed)")
+	tabsTriageCmd.Flags().StringVar(&triageFromPlan, "from-plan", "", "Apply an existing plan file instead of triaging")
+	tabsTriageCmd.Flags().StringVar(&triageBookmarksFile, "bookmarks-file", "", "Chromium Bookmarks file to apply to (default: the --browser profile)")
+	tabsTriageCmd.Flags().BoolVar(&noTitleBackfill, "no-title-backfill", false, "Don't fill in missing titles from browser history")
+}
+
+func runTabsTriage(cmd *cobra.Command, args []string) error {
+	var bookmarksPath string
+	if triageApply {
+		var err error
+		bookmarksPath, err = triageBookmarksPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	var plan models.TriagePlan
+	if triageFromPlan != "" {
+		if !triageApply {
+			return fmt.Errorf("--from-plan requires --apply")
+		}
+		data, err := os.ReadFile(triageFromPlan)
+		if err != nil {
+			return fmt.Errorf("failed to read plan: %v", err)
+		}
+		if err := json.Unmarshal(data, &plan); err != nil {
+			return fmt.Errorf("failed to parse plan %s: %v", triageFromPlan, err)
+		}
+	} else {
+		tabs, browserName, _, err := queryTabs(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		opts := triage.Options{Browser: browserName, DefaultFolder: triageFolder}
+		var out io.Writer = os.Stderr
+		restore := func() {}
+		if triage.IsTerminal(os.Stdin) {
+			if r, err := triage.MakeRaw(os.Stdin); err == nil {
+				restore = r
+				out = triage.NewRawWriter(os.Stderr)
+				opts.Echo = true
+			} else {
+				statusf("Single-key input unavailable; press Enter after each key")
+			}
+		}
+
+		plan, err = triage.Run(os.Stdin, out, tabs, opts)
+		restore()
+		if err != nil {
+			return err
+		}
+
+		path := outputPath()
+		if path == "" {
+			path = "tab-triage.json"
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %v", err)
+		}
+		defer f.Close()
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(plan); err != nil {
+			return err
+		}
+		statusf("\nWrote plan to %s: %s", path, triage.Summary(plan))
+	}
+
+	if !triageApply {
+		return nil
+	}
+
+	var bookmarks []database.NewBookmark
+	for _, item := range plan.Items {
+		if item.Action == models.TriageBookmark {
+			bookmarks = append(bookmarks, database.NewBookmark{URL: item.URL, Title: item.Title, Folder: item.Folder})
+		}
+	}
+	if len(bookmarks) == 0 {
+		statusf("No bookmarks to add")
+		return nil
+	}
+
+	added, err := database.AddChromeBookmarks(bookmarksPath, bookmarks)
+	if err != nil {
+		return err
+	}
+	statusf("Added %d bookmarks to %s (%d already present)", added, bookmarksPath, len(bookmarks)-added)
+	return nil
+}
+
+// triageBookmarksPath returns the Chromium Bookmarks file --apply writes to
+func triageBookmarksPath() (string, error) {
+	if triageBookmarksFile != "" {
+		return triageBookmarksFile, nil
+	}
+
+	bType := browser.Type(browserType)
+	if allBrowsers || bType == browser.Auto || !browser.IsChromiumBased(bType) {
+		return "", fmt.Errorf("--apply needs a Chromium-based --browser or --bookmarks-file")
+	}
+	path, err := browser.GetBookmarkPath(bType)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bookmark path: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("bookmark file not found: %s", path)
+	}
+	return path, nil
+}
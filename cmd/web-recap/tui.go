@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse history in an interactive terminal timeline and export a selection",
+	Long: `Explore history in a scrollable terminal timeline before exporting it.
+Shows today's history by default; the usual date flags pick another range.
+
+  ↑/↓ j/k     move               PgUp/PgDn Home/End   scroll
+  /           filter (every word must match title, URL, or domain)
+  space       select the entry, or the whole domain on a group header
+  a           select or deselect everything shown
+  d           group by domain
+  enter / e   export the selection (or everything shown) and quit
+  q / Esc     quit without exporting
+
+The export is written like the default history output: to stdout, -o, or
+an upload or post target.
+
+Examples:
+  web-recap tui
+  web-recap tui --date 2025-12-15 --all-browsers -o picked.json
+`,
+	RunE: runTUI,
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	loc, err := getTimezone(timezone, utcMode)
+	if err != nil {
+		return err
+	}
+	startTimeValue, endTimeValue, err := resolveTimeRange(loc)
+	if err != nil {
+		return err
+	}
+	startTimeValue = startTimeValue.UTC()
+	endTimeValue = endTimeValue.UTC()
+
+	if err := tui.RequireTerminal(); err != nil {
+		return fmt.Errorf("tui: %v", err)
+	}
+
+	entries, browserName, sources, err := queryHistory(cmd.Context(), startTimeValue, endTimeValue)
+	if err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("%s  %s", browserName, startTimeValue.In(loc).Format("2006-01-02"))
+	if endTimeValue.Sub(startTimeValue) > 24*time.Hour {
+		title += " – " + endTimeValue.In(loc).Format("2006-01-02")
+	}
+	timeline := tui.NewTimeline(title, entries, loc)
+	if err := tui.Run(timeline); err != nil {
+		return err
+	}
+	if !timeline.Exported() {
+		return nil
+	}
+
+	report := newHistoryReport(timeline.Selection(), browserName, startTimeValue, endTimeValue, sources)
+	return writeOutput(func(out io.Writer) error {
+		return output.FormatHistoryReportJSON(out, report)
+	})
+}
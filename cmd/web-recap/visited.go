@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var visitedUnvisited bool
+
+var visitedCmd = &cobra.Command{
+	Use:   "visited [file...]",
+	Short: "Check a list of URLs or domains against history",
+	Long: `Read URLs or domains, one per line, from the given files or from stdin, and report
+for each whether it is in your history, how many times it was visited, and when
+it was last visited. Blank lines and lines starting with # are skipped.
+
+A line with a scheme or a path is a URL. It matches visits to the same page:
+http and https, a leading www., the #fragment, a trailing slash, and tracking
+parameters (utm_*, fbclid, ...) are ignored. A bare host is a domain and
+matches visits to it and to its subdomains.
+
+All of history is searched unless --date or --start-date/--end-date are given.
+With --unvisited, only the lines not in history are printed, as given, so a
+reading list can be cut down to the links not yet read.
+
+Examples:
+  web-recap visited < links.txt
+  web-recap visited links.txt --browser firefox
+  web-recap visited --unvisited < reading-list.txt > unread.txt
+  echo news.ycombinator.com | web-recap visited --start-date 2025-12-01
+`,
+	RunE: runVisited,
+}
+
+func init() {
+	visitedCmd.Flags().BoolVar(&visitedUnvisited, "unvisited", false, "Print only the lines not found in history, one per line")
+}
+
+func runVisited(cmd *cobra.Command, args []string) error {
+	if redacting {
+		return fmt.Errorf("visited matches the URLs in history and cannot be combined with --redact")
+	}
+
+	inputs, err := readVisitedInputs(args)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no URLs or domains to check (give them on stdin or in a file, one per line)")
+	}
+
+	// Search all of history unless a range was given
+	var startTimeValue, endTimeValue time.Time
+	if date != "" || startDate != "" || endDate != "" {
+		loc, err := getTimezone(timezone, utcMode)
+		if err != nil {
+			return err
+		}
+		startTimeValue, endTimeValue, err = resolveTimeRange(loc)
+		if err != nil {
+			return err
+		}
+		startTimeValue = startTimeValue.UTC()
+		endTimeValue = endTimeValue.UTC()
+	}
+
+	entries, browserName, sources, err := queryHistory(cmd.Context(), startTimeValue, endTimeValue)
+	if err != nil {
+		return err
+	}
+	checks := stats.CheckVisited(inputs, entries)
+
+	report := models.VisitedReport{
+		Browser:      browserName,
+		Timezone:     reportTimezone(),
+		Meta:         newReportMeta(sources),
+		Sources:      sources,
+		Warnings:     output.SourceWarnings(sources),
+		TotalChecked: len(checks),
+		Results:      checks,
+	}
+	if !startTimeValue.IsZero() {
+		report.StartDate = &startTimeValue
+	}
+	if !endTimeValue.IsZero() {
+		report.EndDate = &endTimeValue
+	}
+	for _, c := range checks {
+		if c.Visited {
+			report.TotalVisited++
+		}
+	}
+	if err := writeOutput(func(out io.Writer) error {
+		if visitedUnvisited {
+			return output.FormatUnvisited(out, checks)
+		}
+		return output.FormatVisitedJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(len(entries), sources)
+	return nil
+}
+
+// readVisitedInputs reads the lines to check from the files, or from stdin
+// when none are given, skipping blank lines and # comments
+func readVisitedInputs(files []string) ([]string, error) {
+	if len(files) == 0 {
+		return scanVisitedInputs(os.Stdin)
+	}
+	var inputs []string
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		lines, err := scanVisitedInputs(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		inputs = append(inputs, lines...)
+	}
+	return inputs, nil
+}
+
+func scanVisitedInputs(r io.Reader) ([]string, error) {
+	var inputs []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		inputs = append(inputs, line)
+	}
+	return inputs, scanner.Err()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/rzolkos/web-recap/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var vivaldiCmd = &cobra.Command{
+	Use:   "vivaldi",
+	Short: "Export Vivaldi's Notes and Speed Dials",
+	Long: `Export the content Vivaldi users curate beyond their history: the notes in the
+Notes panel, with the pages they were taken on, and the Speed Dial pages.
+
+Both are read from the Vivaldi profile that holds the History database. Use
+--db-path with the profile directory, or any file in it, to read another
+profile.
+
+Examples:
+  web-recap vivaldi notes
+  web-recap vivaldi speed-dial -o dials.json
+  web-recap vivaldi notes --db-path ~/.config/vivaldi/Profile\ 1
+`,
+}
+
+var vivaldiNotesCmd = &cobra.Command{
+	Use:   "notes",
+	Short: "Export the notes in Vivaldi's Notes panel",
+	Long: `Export every note in Vivaldi's Notes panel, with its text, the URL of the page it
+was taken on, and the path of the note folders it is filed in. Notes in the
+trash are left out, as are notes on --blocklist domains.`,
+	RunE: runVivaldiNotes,
+}
+
+var vivaldiSpeedDialCmd = &cobra.Command{
+	Use:   "speed-dial",
+	Short: "Export Vivaldi's Speed Dial pages",
+	Long: `Export Vivaldi's Speed Dial pages and their tiles. Vivaldi keeps each page as a
+bookmark folder marked as a Speed Dial; tiles keep the nickname you gave them.
+Tiles on --blocklist domains are left out.`,
+	RunE: runVivaldiSpeedDial,
+}
+
+func init() {
+	vivaldiCmd.AddCommand(vivaldiNotesCmd)
+	vivaldiCmd.AddCommand(vivaldiSpeedDialCmd)
+}
+
+// vivaldiProfile returns the Vivaldi profile directory to read: --db-path, or
+// the directory of the file it names, or the detected Vivaldi's profile
+func vivaldiProfile() (string, error) {
+	if browserType != "auto" && browser.Type(browserType) != browser.Vivaldi {
+		return "", fmt.Errorf("notes and Speed Dials are only kept by Vivaldi, not %s", browserType)
+	}
+	if dbPath != "" {
+		info, err := os.Stat(dbPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("profile path not found: %s", dbPath)
+			}
+			return "", fmt.Errorf("cannot access profile path: %v", err)
+		}
+		if info.IsDir() {
+			return dbPath, nil
+		}
+		return filepath.Dir(dbPath), nil
+	}
+
+	b, err := newDetector().GetBrowser(browser.Vivaldi)
+	if err != nil {
+		return "", browserError(fmt.Errorf("failed to get browser: %v", err), err, browser.Vivaldi, "")
+	}
+	return filepath.Dir(b.Path), nil
+}
+
+// readVivaldi reads from the Vivaldi profile with read, and returns the
+// profile's source status for the report
+func readVivaldi(ctx context.Context, file string, read func(ctx context.Context, profile string) (int, error)) ([]models.SourceStatus, error) {
+	if dataSource == sourceArchive {
+		return nil, fmt.Errorf("vivaldi reads the browser profile and cannot use --source archive")
+	}
+	profile, err := vivaldiProfile()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(profile, file)
+	n, err := read(ctx, profile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, browserError(fmt.Errorf("no %s file in Vivaldi profile: %s", file, profile), err, browser.Vivaldi, path)
+		}
+		return nil, browserError(fmt.Errorf("failed to read Vivaldi %s: %v", file, err), err, browser.Vivaldi, path)
+	}
+	return []models.SourceStatus{{Browser: string(browser.Vivaldi), Name: "Vivaldi", Path: path, Entries: n}}, nil
+}
+
+func runVivaldiNotes(cmd *cobra.Command, args []string) error {
+	var notes []models.VivaldiNote
+	sources, err := readVivaldi(cmd.Context(), "Notes", func(ctx context.Context, profile string) (int, error) {
+		found, err := database.GetVivaldiNotes(ctx, profile)
+		if err != nil {
+			return 0, err
+		}
+		for _, n := range found {
+			if n.Domain == "" || !blocked.Blocks(n.Domain) {
+				notes = append(notes, n)
+			}
+		}
+		return len(notes), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if redacting {
+		redact.VivaldiNotes(notes, redactMode)
+	}
+
+	report := models.VivaldiNotesReport{
+		Browser:    string(browser.Vivaldi),
+		Meta:       newReportMeta(sources),
+		Sources:    sources,
+		Warnings:   output.SourceWarnings(sources),
+		TotalNotes: len(notes),
+		Notes:      notes,
+	}
+	if err := writeOutput(func(out io.Writer) error {
+		return output.FormatVivaldiNotesJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(len(notes), sources)
+	return nil
+}
+
+func runVivaldiSpeedDial(cmd *cobra.Command, args []string) error {
+	var groups []models.SpeedDialGroup
+	total := 0
+	sources, err := readVivaldi(cmd.Context(), "Bookmarks", func(ctx context.Context, profile string) (int, error) {
+		found, err := database.GetVivaldiSpeedDials(ctx, profile)
+		if err != nil {
+			return 0, err
+		}
+		for _, g := range found {
+			dials := g.Dials[:0]
+			for _, d := range g.Dials {
+				if !blocked.Blocks(d.Domain) {
+					dials = append(dials, d)
+				}
+			}
+			g.Dials = dials
+			total += len(dials)
+			groups = append(groups, g)
+		}
+		return total, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if redacting {
+		redact.SpeedDials(groups, redactMode)
+	}
+
+	report := models.SpeedDialReport{
+		Browser:    string(browser.Vivaldi),
+		Meta:       newReportMeta(sources),
+		Sources:    sources,
+		Warnings:   output.SourceWarnings(sources),
+		TotalDials: total,
+		Groups:     groups,
+	}
+	if err := writeOutput(func(out io.Writer) error {
+		return output.FormatSpeedDialsJSON(out, report)
+	}); err != nil {
+		return err
+	}
+	recordOutcome(total, sources)
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/incremental"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/outfile"
+	"github.com/rzolkos/web-recap/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchTabs        bool
+	watchHistory     bool
+	watchInterval    time.Duration
+	watchOnce        bool
+	watchRotateSize  string
+	watchRotateEvery time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Periodically snapshot open tabs into the archive, or export new visits",
+	Long: `Snapshot the open tabs of all detected Chromium browsers (or --browser) into
+the archive every --interval until interrupted. Each snapshot records how many
+tabs and windows each browser has open, and when each tab was first seen, for
+'archive tab-counts' and 'archive open-tabs'.
+
+A tab missing from a snapshot counts as closed, so one that is opened again
+starts over. --once takes a single snapshot, e.g. from cron.
+
+With --history, the visits made since watch started are appended as JSON lines
+to --output (default: stdout) every --interval. The output file name can use
+the {date}, {time}, {browser}, and {host} placeholders, and is rotated when
+--rotate-size or --rotate-every is reached, or when {date} moves to the next
+day. A file rotated out under the same name is renamed with the time it was
+started, e.g. visits.20250301-090000.jsonl.
+
+Examples:
+  web-recap watch --tabs                   # Every 15 minutes until interrupted
+  web-recap watch --tabs --interval 1h --include-cloud
+  web-recap watch --tabs --once            # One snapshot, e.g. from cron
+  web-recap watch --history --interval 5m -o "visits-{date}.jsonl"
+  web-recap watch --history -o visits.jsonl --rotate-size 10MB --rotate-every 24h
+  web-recap archive tab-counts --start-date 2025-03-01
+  web-recap archive open-tabs --open-for 1w
+`,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchTabs, "tabs", false, "Snapshot open tabs")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 15*time.Minute, "Time between snapshots")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Take one snapshot and exit")
+	watchCmd.Flags().BoolVar(&watchHistory, "history", false, "Append new visits to --output as JSON lines")
+	watchCmd.Flags().StringVar(&watchRotateSize, "rotate-size", "", "With --history, start a new output file once it reaches this size (e.g. 10MB)")
+	watchCmd.Flags().DurationVar(&watchRotateEvery, "rotate-every", 0, "With --history, start a new output file after this long (e.g. 24h)")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if !watchTabs && !watchHistory {
+		return fmt.Errorf("nothing to watch (use --tabs or --history)")
+	}
+	if watchInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	if (watchRotateSize != "" || watchRotateEvery != 0) && (!watchHistory || outputFile == "") {
+		return fmt.Errorf("--rotate-size and --rotate-every rotate the --history output file; name it with -o")
+	}
+
+	var a *archive.Archive
+	if watchTabs {
+		path, err := resolveArchivePath()
+		if err != nil {
+			return err
+		}
+		if a, err = archive.Open(path); err != nil {
+			return err
+		}
+		defer a.Close()
+	}
+
+	var visits *visitWatcher
+	if watchHistory {
+		out, err := watchOutput()
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		visits = newVisitWatcher(out, time.Now())
+	}
+
+	ctx := cmd.Context()
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		var err error
+		if a != nil {
+			err = snapshotTabs(ctx, a)
+		}
+		if visits != nil && err == nil {
+			err = visits.poll(ctx)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if watchOnce {
+				return err
+			}
+			warnf("%v", err)
+		}
+		if watchOnce {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshotTabs records the tabs open now in the archive. Having no open tabs
+// is not an error; the snapshot is just skipped.
+func snapshotTabs(ctx context.Context, a *archive.Archive) error {
+	entries, _, _, err := queryTabs(ctx)
+	if errors.Is(err, errNoTabs) {
+		statusf("%s: no open tabs", time.Now().Format(time.RFC3339))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := a.AddTabSnapshot(now, entries); err != nil {
+		return err
+	}
+	statusf("%s: %d open tabs", now.Format(time.RFC3339), len(entries))
+	return nil
+}
+
+// watchOutput returns where watch --history appends visits: stdout, or the
+// --output file, rotated as --rotate-size and --rotate-every say
+func watchOutput() (io.WriteCloser, error) {
+	switch {
+	case remoteOutput():
+		return nil, fmt.Errorf("watch --history appends to a file; --output cannot be a URL")
+	case encrypting():
+		return nil, fmt.Errorf("watch --history appends to its output and cannot be encrypted")
+	case outputFile == "" || outputFile == "-":
+		return nopCloser{os.Stdout}, nil
+	}
+	var rotation outfile.Rotation
+	if watchRotateSize != "" {
+		size, err := outfile.ParseSize(watchRotateSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --rotate-size: %v", err)
+		}
+		rotation.MaxSize = size
+	}
+	if watchRotateEvery < 0 {
+		return nil, fmt.Errorf("--rotate-every must be positive")
+	}
+	rotation.MaxAge = watchRotateEvery
+	return outfile.NewWriter(outputFile, outputFields, rotation), nil
+}
+
+// nopCloser is a WriteCloser for stdout, which watch leaves open
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// visitWatcher appends the visits made since it started, oldest first,
+// keeping the newest visit written per browser like --incremental does, so
+// each visit is written once
+type visitWatcher struct {
+	out   io.Writer
+	start time.Time
+	state *incremental.State
+}
+
+func newVisitWatcher(out io.Writer, start time.Time) *visitWatcher {
+	return &visitWatcher{out: out, start: start, state: &incremental.State{Browsers: map[string]time.Time{}}}
+}
+
+// poll writes the visits newer than those already written
+func (v *visitWatcher) poll(ctx context.Context) error {
+	since := v.state.Since()
+	if since.IsZero() {
+		since = v.start
+	}
+	entries, _, _, err := queryHistory(ctx, since.UTC(), time.Time{})
+	if err != nil {
+		return err
+	}
+	entries = v.state.Filter(entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	written := entries
+	if displayLoc != nil {
+		written = make([]models.HistoryEntry, len(entries))
+		for i, e := range entries {
+			e.Timestamp = e.Timestamp.In(displayLoc)
+			written[i] = e
+		}
+	}
+	if err := output.FormatJSONLines(v.out, written); err != nil {
+		return err
+	}
+	v.state.Advance(entries)
+	statusf("%s: %d new visits", time.Now().Format(time.RFC3339), len(entries))
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package activitywatch converts history visits into ActivityWatch
+// web-watcher events and sends them to an aw-server
+package activitywatch
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// BucketType and Client are what aw-watcher-web registers its buckets with,
+// so backfilled events show up in the same views as live ones
+const (
+	BucketType = "web.tab.current"
+	Client     = "aw-client-web"
+)
+
+// DefaultServerURL is where aw-server listens by default
+const DefaultServerURL = "http://localhost:5600"
+
+// EventData is the data of a web-watcher event
+type EventData struct {
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Audible   bool   `json:"audible"`
+	Incognito bool   `json:"incognito"`
+}
+
+// Event is one ActivityWatch event; Duration is in seconds
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Duration  float64   `json:"duration"`
+	Data      EventData `json:"data"`
+}
+
+// Bucket holds the events of one browser, in aw-server's export format
+type Bucket struct {
+	ID       string    `json:"id"`
+	Created  time.Time `json:"created"`
+	Type     string    `json:"type"`
+	Client   string    `json:"client"`
+	Hostname string    `json:"hostname"`
+	Events   []Event   `json:"events"`
+}
+
+// Export is the file aw-server imports from its web UI or /api/0/import
+type Export struct {
+	Buckets map[string]Bucket `json:"buckets"`
+}
+
+// BucketID names the bucket aw-watcher-web uses for a browser on a host
+func BucketID(browser, hostname string) string {
+	if browser == "" {
+		browser = "unknown"
+	}
+	browser = strings.NewReplacer(" ", "-", ":", "-").Replace(strings.ToLower(browser))
+	return "aw-watcher-web-" + browser + "_" + hostname
+}
+
+// Buckets turns visits into one bucket per browser. Each visit lasts until
+// the next visit in the same browser, unless the gap exceeds idle, in which
+// case it gets no duration, like the gap estimate in time-on-site.
+func Buckets(entries []models.HistoryEntry, hostname string, idle time.Duration, now time.Time) []Bucket {
+	byBrowser := make(map[string][]models.HistoryEntry)
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() {
+			byBrowser[e.Browser] = append(byBrowser[e.Browser], e)
+		}
+	}
+
+	buckets := make([]Bucket, 0, len(byBrowser))
+	for name, visits := range byBrowser {
+		sort.SliceStable(visits, func(i, j int) bool {
+			return visits[i].Timestamp.Before(visits[j].Timestamp)
+		})
+		events := make([]Event, len(visits))
+		for i, v := range visits {
+			events[i] = Event{
+				Timestamp: v.Timestamp.UTC(),
+				Data:      EventData{URL: v.URL, Title: v.Title},
+			}
+			if i+1 < len(visits) {
+				if gap := visits[i+1].Timestamp.Sub(v.Timestamp); gap <= idle {
+					events[i].Duration = gap.Seconds()
+				}
+			}
+		}
+		buckets = append(buckets, Bucket{
+			ID:       BucketID(name, hostname),
+			Created:  now.UTC(),
+			Type:     BucketType,
+			Client:   Client,
+			Hostname: hostname,
+			Events:   events,
+		})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].ID < buckets[j].ID })
+	return buckets
+}
+
+// NewExport builds the import file for buckets
+func NewExport(buckets []Bucket) Export {
+	export := Export{Buckets: make(map[string]Bucket, len(buckets))}
+	for _, b := range buckets {
+		export.Buckets[b.ID] = b
+	}
+	return export
+}
@@ -0,0 +1,70 @@
+package activitywatch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestBuckets(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{URL: "https://b.example", Browser: "chrome", Timestamp: base.Add(2 * time.Minute)},
+		{URL: "https://a.example", Browser: "chrome", Timestamp: base},
+		{URL: "https://c.example", Browser: "chrome", Timestamp: base.Add(time.Hour)},
+		{URL: "https://d.example", Browser: "Firefox", Timestamp: base.Add(time.Minute)},
+	}
+	buckets := Buckets(entries, "laptop", 10*time.Minute, base)
+	if len(buckets) != 2 {
+		t.Fatalf("got %d buckets, want 2", len(buckets))
+	}
+	chrome := buckets[0]
+	if chrome.ID != "aw-watcher-web-chrome_laptop" || buckets[1].ID != "aw-watcher-web-firefox_laptop" {
+		t.Errorf("bucket IDs = %s, %s", chrome.ID, buckets[1].ID)
+	}
+	var durations []float64
+	for _, e := range chrome.Events {
+		durations = append(durations, e.Duration)
+	}
+	if len(durations) != 3 || durations[0] != 120 || durations[1] != 0 || durations[2] != 0 {
+		t.Errorf("durations = %v, want [120 0 0]", durations)
+	}
+	if chrome.Events[0].Data.URL != "https://a.example" {
+		t.Errorf("first event = %s, want the earliest visit", chrome.Events[0].Data.URL)
+	}
+}
+
+func TestServerPushSkipsExisting(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	var inserted []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/0/buckets/aw-watcher-web-chrome_laptop":
+			w.WriteHeader(http.StatusNotModified)
+		case "GET /api/0/buckets/aw-watcher-web-chrome_laptop/events":
+			json.NewEncoder(w).Encode([]Event{{Timestamp: base.In(time.FixedZone("X", 3600)), Data: EventData{URL: "https://a.example"}}})
+		case "POST /api/0/buckets/aw-watcher-web-chrome_laptop/events":
+			json.NewDecoder(r.Body).Decode(&inserted)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	bucket := Bucket{ID: "aw-watcher-web-chrome_laptop", Type: BucketType, Client: Client, Hostname: "laptop", Events: []Event{
+		{Timestamp: base, Data: EventData{URL: "https://a.example"}},
+		{Timestamp: base.Add(time.Minute), Data: EventData{URL: "https://b.example"}},
+	}}
+	n, err := (&Server{URL: server.URL + "/"}).Push(context.Background(), bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(inserted) != 1 || inserted[0].Data.URL != "https://b.example" {
+		t.Errorf("inserted %d events %+v, want only https://b.example", n, inserted)
+	}
+}
@@ -0,0 +1,118 @@
+package activitywatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// eventBatch is the most events sent in one request
+const eventBatch = 1000
+
+// Server is an aw-server reachable over its REST API
+type Server struct {
+	URL string
+}
+
+// Push creates the bucket if needed and inserts its events, skipping events
+// the bucket already has with the same time and URL, so backfilling the same
+// range twice does not count visits twice. It returns the number of events
+// inserted.
+func (s *Server) Push(ctx context.Context, b Bucket) (int, error) {
+	path := "/api/0/buckets/" + url.PathEscape(b.ID)
+	bucket := map[string]string{"client": b.Client, "type": b.Type, "hostname": b.Hostname}
+	if err := s.call(ctx, http.MethodPost, path, bucket, nil); err != nil {
+		return 0, fmt.Errorf("failed to create bucket %s: %v", b.ID, err)
+	}
+	if len(b.Events) == 0 {
+		return 0, nil
+	}
+
+	existing, err := s.existing(ctx, path, b.Events)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read events of bucket %s: %v", b.ID, err)
+	}
+	var events []Event
+	for _, e := range b.Events {
+		if !existing[eventKey(e)] {
+			events = append(events, e)
+		}
+	}
+
+	inserted := 0
+	for start := 0; start < len(events); start += eventBatch {
+		batch := events[start:min(start+eventBatch, len(events))]
+		if err := s.call(ctx, http.MethodPost, path+"/events", batch, nil); err != nil {
+			return inserted, fmt.Errorf("failed to insert events into bucket %s: %v", b.ID, err)
+		}
+		inserted += len(batch)
+	}
+	return inserted, nil
+}
+
+// existing returns the keys of the events the bucket holds in the time range
+// of events, which are sorted by time
+func (s *Server) existing(ctx context.Context, path string, events []Event) (map[string]bool, error) {
+	last := events[len(events)-1]
+	query := url.Values{
+		"start": {events[0].Timestamp.Format(time.RFC3339Nano)},
+		"end":   {last.Timestamp.Add(time.Second).Format(time.RFC3339Nano)},
+		"limit": {"-1"},
+	}
+	var stored []Event
+	if err := s.call(ctx, http.MethodGet, path+"/events?"+query.Encode(), nil, &stored); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(stored))
+	for _, e := range stored {
+		keys[eventKey(e)] = true
+	}
+	return keys, nil
+}
+
+func eventKey(e Event) string {
+	return fmt.Sprintf("%d\x00%s", e.Timestamp.UnixMilli(), e.Data.URL)
+}
+
+// call sends a JSON request and decodes the response into out. aw-server
+// answers 304 when a bucket being created already exists.
+func (s *Server) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(s.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
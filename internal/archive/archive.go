@@ -0,0 +1,483 @@
+package archive
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// Watermark kinds. Imports have their own, so importing visits never makes
+// a sync skip visits the browser still has.
+const (
+	KindHistory     = "history"
+	KindBookmarks   = "bookmarks"
+	KindTakeout     = "takeout"
+	KindFirefoxSync = "firefox-sync"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS visits (
+	id          INTEGER PRIMARY KEY,
+	browser     TEXT NOT NULL,
+	visit_time  INTEGER NOT NULL,
+	url         TEXT NOT NULL,
+	title       TEXT NOT NULL DEFAULT '',
+	domain      TEXT NOT NULL DEFAULT '',
+	visit_count INTEGER NOT NULL DEFAULT 0,
+	source      TEXT NOT NULL DEFAULT '',
+	UNIQUE (browser, url, visit_time)
+);
+CREATE INDEX IF NOT EXISTS visits_time ON visits (visit_time);
+CREATE INDEX IF NOT EXISTS visits_url ON visits (url);
+
+-- One row per archived URL with its latest title, indexed for search
+CREATE TABLE IF NOT EXISTS pages (
+	id    INTEGER PRIMARY KEY,
+	url   TEXT NOT NULL UNIQUE,
+	title TEXT NOT NULL DEFAULT ''
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS pages_fts USING fts5 (
+	title, url,
+	content = 'pages', content_rowid = 'id',
+	tokenize = 'porter unicode61 remove_diacritics 2'
+);
+CREATE TRIGGER IF NOT EXISTS pages_ai AFTER INSERT ON pages BEGIN
+	INSERT INTO pages_fts (rowid, title, url) VALUES (new.id, new.title, new.url);
+END;
+CREATE TRIGGER IF NOT EXISTS pages_ad AFTER DELETE ON pages BEGIN
+	INSERT INTO pages_fts (pages_fts, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+END;
+CREATE TRIGGER IF NOT EXISTS pages_au AFTER UPDATE ON pages BEGIN
+	INSERT INTO pages_fts (pages_fts, rowid, title, url) VALUES ('delete', old.id, old.title, old.url);
+	INSERT INTO pages_fts (rowid, title, url) VALUES (new.id, new.title, new.url);
+END;
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+	id            INTEGER PRIMARY KEY,
+	browser       TEXT NOT NULL,
+	url           TEXT NOT NULL,
+	folder        TEXT NOT NULL DEFAULT '',
+	title         TEXT NOT NULL DEFAULT '',
+	domain        TEXT NOT NULL DEFAULT '',
+	date_added    INTEGER NOT NULL DEFAULT 0,
+	date_modified INTEGER NOT NULL DEFAULT 0,
+	tags          TEXT NOT NULL DEFAULT '',
+	source        TEXT NOT NULL DEFAULT '',
+	guid          TEXT NOT NULL DEFAULT '',
+	UNIQUE (browser, url, folder)
+);
+
+-- Open tab counts per browser, one row for each 'watch --tabs' snapshot
+CREATE TABLE IF NOT EXISTS tab_snapshots (
+	taken_at INTEGER NOT NULL,
+	browser  TEXT NOT NULL,
+	tabs     INTEGER NOT NULL,
+	windows  INTEGER NOT NULL,
+	PRIMARY KEY (taken_at, browser)
+);
+-- One row per URL seen open in a browser, with when it was first and last
+-- seen. A tab missing from a snapshot and opened again starts over.
+CREATE TABLE IF NOT EXISTS open_tabs (
+	browser    TEXT NOT NULL,
+	url        TEXT NOT NULL,
+	title      TEXT NOT NULL DEFAULT '',
+	domain     TEXT NOT NULL DEFAULT '',
+	first_seen INTEGER NOT NULL,
+	last_seen  INTEGER NOT NULL,
+	PRIMARY KEY (browser, url)
+);
+
+CREATE TABLE IF NOT EXISTS watermarks (
+	browser   TEXT NOT NULL,
+	kind      TEXT NOT NULL,
+	last_time INTEGER NOT NULL,
+	synced_at INTEGER NOT NULL,
+	PRIMARY KEY (browser, kind)
+);
+`
+
+// Archive is a local SQLite database of history and bookmarks copied from
+// the browsers, kept after the browsers expire them
+type Archive struct {
+	db   *sql.DB
+	path string
+}
+
+// DefaultPath returns the archive location, e.g.
+// ~/.config/web-recap/archive.db on Linux
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web-recap", "archive.db"), nil
+}
+
+// Open opens the archive at path, creating it if needed
+func Open(path string) (*Archive, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %v", err)
+	}
+	// One connection keeps writes serialized without SQLITE_BUSY retries
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize archive %s: %v", path, err)
+	}
+	if err := addColumn(db, "bookmarks", "guid", `TEXT NOT NULL DEFAULT ''`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to upgrade archive %s: %v", path, err)
+	}
+	a := &Archive{db: db, path: path}
+	if err := a.indexPages(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to build search index: %v", err)
+	}
+	return a, nil
+}
+
+// addColumn adds a column to a table created by an older version of the
+// schema, which CREATE TABLE IF NOT EXISTS leaves as it was
+func addColumn(db *sql.DB, table, column, definition string) error {
+	var exists bool
+	err := db.QueryRow(`SELECT 1 FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&exists)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+// OpenExisting opens an archive for reading, failing if it hasn't been
+// created by a sync yet
+func OpenExisting(path string) (*Archive, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("archive not found: %s (run 'web-recap archive sync' first)", path)
+		}
+		return nil, fmt.Errorf("cannot access archive: %v", err)
+	}
+	return Open(path)
+}
+
+// Path returns the archive file location
+func (a *Archive) Path() string {
+	return a.path
+}
+
+// Close closes the archive
+func (a *Archive) Close() error {
+	return a.db.Close()
+}
+
+// Watermark returns the newest timestamp synced for a browser and kind, or
+// the zero time if it has never been synced
+func (a *Archive) Watermark(browserType, kind string) (time.Time, error) {
+	var last int64
+	err := a.db.QueryRow(`SELECT last_time FROM watermarks WHERE browser = ? AND kind = ?`, browserType, kind).Scan(&last)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return fromMicros(last), nil
+}
+
+// AddVisits stores history entries read from browserType, skipping visits
+// already archived, and advances the browser's history watermark. It
+// returns the number of new visits.
+func (a *Archive) AddVisits(browserType string, entries []models.HistoryEntry) (int, error) {
+	return a.addVisits(browserType, KindHistory, entries)
+}
+
+// ImportVisits stores visits from an import such as Google Takeout under
+// browserType, skipping visits already archived. The watermark of kind is
+// advanced instead of the browser's sync watermark. It returns the number of
+// new visits.
+func (a *Archive) ImportVisits(browserType, kind string, entries []models.HistoryEntry) (int, error) {
+	return a.addVisits(browserType, kind, entries)
+}
+
+func (a *Archive) addVisits(browserType, kind string, entries []models.HistoryEntry) (int, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO visits
+		(browser, visit_time, url, title, domain, visit_count, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	// A page keeps the first non-empty title archived for it
+	pageStmt, err := tx.Prepare(`INSERT INTO pages (url, title) VALUES (?, ?)
+		ON CONFLICT (url) DO UPDATE SET title = excluded.title
+		WHERE excluded.title != '' AND pages.title = ''`)
+	if err != nil {
+		return 0, err
+	}
+	defer pageStmt.Close()
+	pages := make(map[string]bool)
+
+	added := 0
+	var newest time.Time
+	for _, e := range entries {
+		res, err := stmt.Exec(browserType, toMicros(e.Timestamp), e.URL, e.Title, e.Domain, e.VisitCount, e.Source)
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive visit: %v", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added++
+			if !pages[e.URL] {
+				pages[e.URL] = true
+				if _, err := pageStmt.Exec(e.URL, e.Title); err != nil {
+					return 0, fmt.Errorf("failed to index page: %v", err)
+				}
+			}
+		}
+		if e.Timestamp.After(newest) {
+			newest = e.Timestamp
+		}
+	}
+
+	if err := setWatermark(tx, browserType, kind, newest); err != nil {
+		return 0, err
+	}
+	return added, tx.Commit()
+}
+
+// AddBookmarks stores bookmarks read from browserType, updating titles and
+// dates of bookmarks already archived. Bookmarks removed from the browser
+// stay in the archive. It returns the number of new bookmarks.
+func (a *Archive) AddBookmarks(browserType string, entries []models.BookmarkEntry) (int, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	added := 0
+	var newest time.Time
+	for _, e := range entries {
+		var exists bool
+		err := tx.QueryRow(`SELECT 1 FROM bookmarks WHERE browser = ? AND url = ? AND folder = ?`,
+			browserType, e.URL, e.Folder).Scan(&exists)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+
+		tags := ""
+		if len(e.Tags) > 0 {
+			data, _ := json.Marshal(e.Tags)
+			tags = string(data)
+		}
+		_, err = tx.Exec(`INSERT INTO bookmarks
+			(browser, url, folder, title, domain, date_added, date_modified, tags, source, guid)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (browser, url, folder) DO UPDATE SET
+				title = excluded.title,
+				domain = excluded.domain,
+				date_added = excluded.date_added,
+				date_modified = excluded.date_modified,
+				tags = excluded.tags,
+				source = excluded.source,
+				guid = excluded.guid`,
+			browserType, e.URL, e.Folder, e.Title, e.Domain, toMicros(e.DateAdded), toMicros(e.DateModified), tags, e.Source, e.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive bookmark: %v", err)
+		}
+		if !exists {
+			added++
+		}
+		if e.DateAdded.After(newest) {
+			newest = e.DateAdded
+		}
+	}
+
+	if err := setWatermark(tx, browserType, KindBookmarks, newest); err != nil {
+		return 0, err
+	}
+	return added, tx.Commit()
+}
+
+// indexPages adds archived visits missing from the search index, which
+// happens for archives created before search existed
+func (a *Archive) indexPages() error {
+	var missing bool
+	err := a.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM visits WHERE url NOT IN (SELECT url FROM pages))`).Scan(&missing)
+	if err != nil || !missing {
+		return err
+	}
+	_, err = a.db.Exec(`INSERT INTO pages (url, title)
+		SELECT url, title FROM visits v
+		WHERE url NOT IN (SELECT url FROM pages)
+		AND visit_time = (SELECT max(visit_time) FROM visits WHERE url = v.url)
+		GROUP BY url`)
+	return err
+}
+
+// setWatermark records a sync, keeping the newer of the stored and given times
+func setWatermark(tx *sql.Tx, browserType, kind string, newest time.Time) error {
+	_, err := tx.Exec(`INSERT INTO watermarks (browser, kind, last_time, synced_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (browser, kind) DO UPDATE SET
+			last_time = max(last_time, excluded.last_time),
+			synced_at = excluded.synced_at`,
+		browserType, kind, toMicros(newest), toMicros(time.Now()))
+	return err
+}
+
+// History returns archived visits in [start, end), newest first. A zero
+// bound leaves that side open; an empty browserType returns every browser.
+func (a *Archive) History(browserType string, start, end time.Time) ([]models.HistoryEntry, error) {
+	query := `SELECT browser, visit_time, url, title, domain, visit_count, source FROM visits WHERE 1 = 1`
+	var args []interface{}
+	if browserType != "" {
+		query += ` AND browser = ?`
+		args = append(args, browserType)
+	}
+	if !start.IsZero() {
+		query += ` AND visit_time >= ?`
+		args = append(args, toMicros(start))
+	}
+	if !end.IsZero() {
+		query += ` AND visit_time < ?`
+		args = append(args, toMicros(end))
+	}
+	query += ` ORDER BY visit_time DESC, url`
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []models.HistoryEntry
+	for rows.Next() {
+		var e models.HistoryEntry
+		var visitTime int64
+		if err := rows.Scan(&e.Browser, &visitTime, &e.URL, &e.Title, &e.Domain, &e.VisitCount, &e.Source); err != nil {
+			return nil, err
+		}
+		e.Timestamp = fromMicros(visitTime)
+		e.ID = models.HistoryID(e.Browser, e.URL, e.Timestamp)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Bookmarks returns archived bookmarks added in [start, end), most recently
+// added first. Bookmarks without a date are only returned when both bounds
+// are zero.
+func (a *Archive) Bookmarks(browserType string, start, end time.Time) ([]models.BookmarkEntry, error) {
+	query := `SELECT browser, url, folder, title, domain, date_added, date_modified, tags, source, guid FROM bookmarks WHERE 1 = 1`
+	var args []interface{}
+	if browserType != "" {
+		query += ` AND browser = ?`
+		args = append(args, browserType)
+	}
+	if !start.IsZero() || !end.IsZero() {
+		query += ` AND date_added > 0`
+	}
+	if !start.IsZero() {
+		query += ` AND date_added >= ?`
+		args = append(args, toMicros(start))
+	}
+	if !end.IsZero() {
+		query += ` AND date_added < ?`
+		args = append(args, toMicros(end))
+	}
+	query += ` ORDER BY date_added = 0, date_added DESC, url`
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []models.BookmarkEntry
+	for rows.Next() {
+		var e models.BookmarkEntry
+		var added, modified int64
+		var tags string
+		if err := rows.Scan(&e.Browser, &e.URL, &e.Folder, &e.Title, &e.Domain, &added, &modified, &tags, &e.Source, &e.ID); err != nil {
+			return nil, err
+		}
+		e.DateAdded = fromMicros(added)
+		e.DateModified = fromMicros(modified)
+		if tags != "" {
+			json.Unmarshal([]byte(tags), &e.Tags)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Status returns the sync state of every archived browser
+func (a *Archive) Status() ([]models.ArchiveStatus, error) {
+	rows, err := a.db.Query(`SELECT browser, kind, last_time, synced_at FROM watermarks ORDER BY browser, kind`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var status []models.ArchiveStatus
+	for rows.Next() {
+		var s models.ArchiveStatus
+		var last, synced int64
+		if err := rows.Scan(&s.Browser, &s.Kind, &last, &synced); err != nil {
+			return nil, err
+		}
+		s.Newest = fromMicros(last)
+		s.SyncedAt = fromMicros(synced)
+		status = append(status, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range status {
+		table := "visits"
+		if status[i].Kind == KindBookmarks {
+			table = "bookmarks"
+		}
+		err := a.db.QueryRow(`SELECT count(*) FROM `+table+` WHERE browser = ?`, status[i].Browser).Scan(&status[i].Entries)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func toMicros(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMicro()
+}
+
+func fromMicros(us int64) time.Time {
+	if us == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(us).UTC()
+}
@@ -0,0 +1,169 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+func TestSyncIsIncremental(t *testing.T) {
+	path := testutil.NewChromeHistoryDB(t)
+	profile := filepath.Dir(path)
+	if err := browser.Register(browser.Definition{Type: "archivefork", Engine: browser.EngineChromium, Paths: []string{profile}}); err != nil {
+		t.Fatal(err)
+	}
+	b := browser.Browser{Type: "archivefork", Name: "archivefork", Path: path}
+
+	old := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	testutil.AddChromeVisits(t, path,
+		testutil.ChromeVisit{URL: "https://example.com/old", Time: old},
+		testutil.ChromeVisit{URL: "https://go.dev/", Time: old.Add(time.Hour)},
+	)
+
+	a, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	r := SyncBrowser(context.Background(), a, b, "laptop", nil)
+	if r.Visits != 2 {
+		t.Fatalf("first sync: %d new visits (errors %v), want 2", r.Visits, r.Errors)
+	}
+	if wm, _ := a.Watermark("archivefork", KindHistory); !wm.Equal(old.Add(time.Hour)) {
+		t.Errorf("watermark = %v, want %v", wm, old.Add(time.Hour))
+	}
+
+	// The browser has since expired the old visit and recorded a new one
+	testutil.Exec(t, path, `DELETE FROM visits WHERE visit_time = ?`, testutil.ChromeTime(old))
+	testutil.AddChromeVisits(t, path, testutil.ChromeVisit{URL: "https://go.dev/blog", Time: old.AddDate(1, 0, 0)})
+
+	if r := SyncBrowser(context.Background(), a, b, "laptop", nil); r.Visits != 1 {
+		t.Errorf("second sync: %d new visits, want 1", r.Visits)
+	}
+
+	all, err := a.History("", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].URL != "https://go.dev/blog" || all[2].URL != "https://example.com/old" {
+		t.Fatalf("archive = %v, want all three visits newest first", all)
+	}
+	if all[0].Browser != "archivefork" || all[0].Source != "laptop" {
+		t.Errorf("entry browser/source = %q/%q", all[0].Browser, all[0].Source)
+	}
+
+	day, err := a.History("archivefork", old.Truncate(24*time.Hour), old.Truncate(24*time.Hour).AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(day) != 2 {
+		t.Errorf("range query returned %d visits, want 2", len(day))
+	}
+	if other, _ := a.History("chrome", time.Time{}, time.Time{}); len(other) != 0 {
+		t.Errorf("browser filter returned %d visits, want 0", len(other))
+	}
+}
+
+func TestImportVisitsKeepsSyncWatermark(t *testing.T) {
+	a, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	synced := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := a.AddVisits("chrome", []models.HistoryEntry{{URL: "https://go.dev/", Timestamp: synced}}); err != nil {
+		t.Fatal(err)
+	}
+	imported := []models.HistoryEntry{
+		{URL: "https://go.dev/", Timestamp: synced, Source: "takeout"},
+		{URL: "https://example.com/", Timestamp: synced.AddDate(0, 1, 0), Source: "takeout"},
+	}
+	added, err := a.ImportVisits("chrome", KindTakeout, imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 {
+		t.Errorf("imported %d new visits, want 1", added)
+	}
+	if wm, _ := a.Watermark("chrome", KindHistory); !wm.Equal(synced) {
+		t.Errorf("history watermark = %v, want %v", wm, synced)
+	}
+	if wm, _ := a.Watermark("chrome", KindTakeout); !wm.Equal(synced.AddDate(0, 1, 0)) {
+		t.Errorf("takeout watermark = %v, want %v", wm, synced.AddDate(0, 1, 0))
+	}
+}
+
+func TestAddBookmarksUpdatesExisting(t *testing.T) {
+	a, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	added := time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)
+	first := []models.BookmarkEntry{
+		{URL: "https://go.dev/", Title: "Go", Folder: "Dev", DateAdded: added, Tags: []string{"lang"}},
+		{URL: "https://example.com/", Title: "Example", Folder: "Misc"},
+	}
+	if n, err := a.AddBookmarks("firefox", first); err != nil || n != 2 {
+		t.Fatalf("AddBookmarks = %d, %v; want 2", n, err)
+	}
+
+	// Renamed in the browser; the other bookmark was deleted there
+	if n, err := a.AddBookmarks("firefox", []models.BookmarkEntry{{ID: "g1", URL: "https://go.dev/", Title: "The Go Language", Folder: "Dev", DateAdded: added}}); err != nil || n != 0 {
+		t.Fatalf("second AddBookmarks = %d, %v; want 0", n, err)
+	}
+
+	all, err := a.Bookmarks("", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Title != "The Go Language" || all[0].ID != "g1" || all[1].URL != "https://example.com/" {
+		t.Errorf("bookmarks = %+v", all)
+	}
+
+	dated, _ := a.Bookmarks("firefox", added.Add(-time.Hour), added.Add(time.Hour))
+	if len(dated) != 1 || !dated[0].DateAdded.Equal(added) {
+		t.Errorf("dated bookmarks = %+v, want only the dated one", dated)
+	}
+}
+
+func TestOpenAddsGUIDColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The bookmarks table as archives made before the guid column have it
+	_, err = db.Exec(`CREATE TABLE bookmarks (
+		id INTEGER PRIMARY KEY, browser TEXT NOT NULL, url TEXT NOT NULL,
+		folder TEXT NOT NULL DEFAULT '', title TEXT NOT NULL DEFAULT '', domain TEXT NOT NULL DEFAULT '',
+		date_added INTEGER NOT NULL DEFAULT 0, date_modified INTEGER NOT NULL DEFAULT 0,
+		tags TEXT NOT NULL DEFAULT '', source TEXT NOT NULL DEFAULT '',
+		UNIQUE (browser, url, folder))`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if _, err := a.AddBookmarks("chrome", []models.BookmarkEntry{{ID: "g1", URL: "https://go.dev/"}}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := a.Bookmarks("", time.Time{}, time.Time{})
+	if err != nil || len(all) != 1 || all[0].ID != "g1" {
+		t.Errorf("bookmarks = %+v, %v", all, err)
+	}
+}
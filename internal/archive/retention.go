@@ -0,0 +1,145 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Cutoff returns the time before which data is older than age. Age is a
+// count with a unit — d (days), w (weeks), m (months), or y (years), e.g.
+// "90d" or "2y" — or a YYYY-MM-DD date.
+func Cutoff(now time.Time, age string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", age, now.Location()); err == nil {
+		return t, nil
+	}
+	if len(age) < 2 {
+		return time.Time{}, fmt.Errorf("invalid age %q (use e.g. 90d, 12w, 6m, 2y, or YYYY-MM-DD)", age)
+	}
+	n, err := strconv.Atoi(age[:len(age)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid age %q (use e.g. 90d, 12w, 6m, 2y, or YYYY-MM-DD)", age)
+	}
+	switch age[len(age)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	case 'y':
+		return now.AddDate(-n, 0, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid age %q (use e.g. 90d, 12w, 6m, 2y, or YYYY-MM-DD)", age)
+	}
+}
+
+// Prune removes visits before cutoff, and bookmarks added before cutoff when
+// bookmarks is set. Pages left without visits are dropped from the search
+// index. With dryRun nothing is removed and the counts that would be
+// removed are returned.
+func (a *Archive) Prune(cutoff time.Time, bookmarks, dryRun bool) (models.ArchivePruneResult, error) {
+	result := models.ArchivePruneResult{Cutoff: cutoff.UTC(), DryRun: dryRun}
+	before := toMicros(cutoff)
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(`SELECT count(*) FROM visits WHERE visit_time < ?`, before).Scan(&result.Visits); err != nil {
+		return result, err
+	}
+	if bookmarks {
+		err := tx.QueryRow(`SELECT count(*) FROM bookmarks WHERE date_added > 0 AND date_added < ?`, before).Scan(&result.Bookmarks)
+		if err != nil {
+			return result, err
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	if _, err := tx.Exec(`DELETE FROM visits WHERE visit_time < ?`, before); err != nil {
+		return result, fmt.Errorf("failed to prune visits: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM pages WHERE url NOT IN (SELECT url FROM visits)`); err != nil {
+		return result, fmt.Errorf("failed to prune search index: %v", err)
+	}
+	if bookmarks {
+		if _, err := tx.Exec(`DELETE FROM bookmarks WHERE date_added > 0 AND date_added < ?`, before); err != nil {
+			return result, fmt.Errorf("failed to prune bookmarks: %v", err)
+		}
+	}
+	return result, tx.Commit()
+}
+
+// Vacuum compacts the search index and rebuilds the database file to
+// reclaim space freed by pruning. It returns the file size before and after.
+func (a *Archive) Vacuum() (int64, int64, error) {
+	before := a.size()
+	if _, err := a.db.Exec(`INSERT INTO pages_fts (pages_fts) VALUES ('optimize')`); err != nil {
+		return 0, 0, fmt.Errorf("failed to optimize search index: %v", err)
+	}
+	if _, err := a.db.Exec(`VACUUM`); err != nil {
+		return 0, 0, fmt.Errorf("failed to vacuum archive: %v", err)
+	}
+	return before, a.size(), nil
+}
+
+// Stats summarizes what the archive holds
+func (a *Archive) Stats() (models.ArchiveStats, error) {
+	stats := models.ArchiveStats{Path: a.path, SizeBytes: a.size()}
+
+	var oldest, newest int64
+	err := a.db.QueryRow(`SELECT count(*), coalesce(min(visit_time), 0), coalesce(max(visit_time), 0) FROM visits`).
+		Scan(&stats.Visits, &oldest, &newest)
+	if err != nil {
+		return stats, err
+	}
+	stats.OldestVisit = fromMicros(oldest)
+	stats.NewestVisit = fromMicros(newest)
+
+	if err := a.db.QueryRow(`SELECT count(*) FROM pages`).Scan(&stats.Pages); err != nil {
+		return stats, err
+	}
+	if err := a.db.QueryRow(`SELECT count(*) FROM bookmarks`).Scan(&stats.Bookmarks); err != nil {
+		return stats, err
+	}
+
+	rows, err := a.db.Query(`SELECT strftime('%Y', visit_time / 1000000, 'unixepoch') AS year, count(*)
+		FROM visits GROUP BY year ORDER BY year`)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	stats.Years = []models.ArchiveYear{}
+	for rows.Next() {
+		var y models.ArchiveYear
+		if err := rows.Scan(&y.Year, &y.Visits); err != nil {
+			return stats, err
+		}
+		stats.Years = append(stats.Years, y)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	stats.Browsers, err = a.Status()
+	if stats.Browsers == nil {
+		stats.Browsers = []models.ArchiveStatus{}
+	}
+	return stats, err
+}
+
+func (a *Archive) size() int64 {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCutoff(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		age     string
+		want    time.Time
+		wantErr bool
+	}{
+		{"90d", now.AddDate(0, 0, -90), false},
+		{"2w", now.AddDate(0, 0, -14), false},
+		{"6m", time.Date(2024, 12, 15, 12, 0, 0, 0, time.UTC), false},
+		{"2y", time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC), false},
+		{"2020-01-01", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"y", time.Time{}, true},
+		{"0d", time.Time{}, true},
+		{"3h", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Cutoff(now, tt.age)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Cutoff(%q) error = %v, wantErr %v", tt.age, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Cutoff(%q) = %v, want %v", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	a, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	old := time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC)
+	recent := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	visits := []models.HistoryEntry{
+		{URL: "https://old.example/", Title: "Old page", Timestamp: old},
+		{URL: "https://both.example/", Title: "Both", Timestamp: old},
+		{URL: "https://both.example/", Title: "Both", Timestamp: recent},
+		{URL: "https://new.example/", Title: "New page", Timestamp: recent},
+	}
+	if _, err := a.AddVisits("chrome", visits); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks := []models.BookmarkEntry{
+		{URL: "https://old.example/", Title: "Old", DateAdded: old},
+		{URL: "https://undated.example/", Title: "Undated"},
+	}
+	if _, err := a.AddBookmarks("chrome", bookmarks); err != nil {
+		t.Fatal(err)
+	}
+
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dry, err := a.Prune(cutoff, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Visits != 2 || dry.Bookmarks != 1 {
+		t.Errorf("dry run = %+v, want 2 visits and 1 bookmark", dry)
+	}
+	if stats, _ := a.Stats(); stats.Visits != 4 {
+		t.Errorf("dry run removed visits: %d left", stats.Visits)
+	}
+
+	if _, err := a.Prune(cutoff, true, false); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := a.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Visits != 2 || stats.Pages != 2 || stats.Bookmarks != 1 {
+		t.Errorf("stats after prune = %+v", stats)
+	}
+	if !stats.OldestVisit.Equal(recent) || len(stats.Years) != 1 || stats.Years[0].Year != "2025" {
+		t.Errorf("stats after prune = %+v", stats)
+	}
+
+	if results, _ := a.Search("old", SearchOptions{}); len(results) != 0 {
+		t.Errorf("pruned page still searchable: %+v", results)
+	}
+	if results, _ := a.Search("both", SearchOptions{}); len(results) != 1 || results[0].Visits != 1 {
+		t.Errorf("search after prune = %+v", results)
+	}
+
+	if _, _, err := a.Vacuum(); err != nil {
+		t.Fatal(err)
+	}
+}
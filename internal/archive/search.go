@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// SearchOptions narrows a full-text search
+type SearchOptions struct {
+	// Browser limits visits to one browser type; empty searches every browser
+	Browser string
+	// Start and End limit the visits counted to [Start, End); pages with no
+	// visits in range are left out. A zero bound leaves that side open.
+	Start time.Time
+	End   time.Time
+	// Limit is the maximum number of results (default 20)
+	Limit int
+}
+
+// Search returns archived pages whose title or URL matches every term of
+// query, best match first. Terms are stemmed, so "cancel" also finds
+// "cancellation"; a trailing * matches a prefix.
+func (a *Archive) Search(query string, opts SearchOptions) ([]models.SearchResult, error) {
+	match := MatchExpression(query)
+	if match == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+
+	// Titles weigh more than URLs in the ranking
+	sqlQuery := `
+		WITH m AS MATERIALIZED (
+			SELECT rowid, bm25(pages_fts, 10.0, 1.0) AS score
+			FROM pages_fts WHERE pages_fts MATCH ?
+		)
+		SELECT p.url, p.title, max(v.domain), m.score,
+			count(*), min(v.visit_time), max(v.visit_time), group_concat(DISTINCT v.browser)
+		FROM m
+		JOIN pages p ON p.id = m.rowid
+		JOIN visits v ON v.url = p.url`
+	args := []interface{}{match}
+	if opts.Browser != "" {
+		sqlQuery += ` AND v.browser = ?`
+		args = append(args, opts.Browser)
+	}
+	if !opts.Start.IsZero() {
+		sqlQuery += ` AND v.visit_time >= ?`
+		args = append(args, toMicros(opts.Start))
+	}
+	if !opts.End.IsZero() {
+		sqlQuery += ` AND v.visit_time < ?`
+		args = append(args, toMicros(opts.End))
+	}
+	sqlQuery += `
+		GROUP BY p.id
+		ORDER BY m.score, max(v.visit_time) DESC
+		LIMIT ?`
+	args = append(args, opts.Limit)
+
+	rows, err := a.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search archive: %v", err)
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var r models.SearchResult
+		var score float64
+		var first, last int64
+		var browsers string
+		if err := rows.Scan(&r.URL, &r.Title, &r.Domain, &score, &r.Visits, &first, &last, &browsers); err != nil {
+			return nil, err
+		}
+		// bm25 is negative, lower is better; report a positive relevance
+		r.Score = -score
+		r.FirstVisit = fromMicros(first)
+		r.LastVisit = fromMicros(last)
+		r.Browsers = strings.Split(browsers, ",")
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// MatchExpression turns free text into an FTS5 query matching every word.
+// Words are quoted so punctuation such as "c++" or "-" isn't read as query
+// syntax; a trailing * is kept as a prefix match.
+func MatchExpression(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.TrimRight(word, "*")
+		if word == "" {
+			continue
+		}
+		term := `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,79 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestSearch(t *testing.T) {
+	a, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	day := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	visit := func(url, title string, ts time.Time) models.HistoryEntry {
+		return models.HistoryEntry{Timestamp: ts, URL: url, Title: title, Domain: "example.com"}
+	}
+	if _, err := a.AddVisits("chrome", []models.HistoryEntry{
+		visit("https://example.com/rust-cancel", "Cancellation in async Rust", day.AddDate(1, 0, 0)),
+		visit("https://example.com/rust-cancel", "", day),
+		visit("https://example.com/tokio", "Tokio tutorial: async Rust", day),
+		visit("https://example.com/cpp", "C++ coroutines", day),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.AddVisits("firefox", []models.HistoryEntry{
+		visit("https://example.com/rust-cancel", "Cancellation in async Rust", day.AddDate(0, 1, 0)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := a.Search("rust async cancel", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1: %+v", len(results), results)
+	}
+	r := results[0]
+	if r.Visits != 3 || !r.FirstVisit.Equal(day) || !r.LastVisit.Equal(day.AddDate(1, 0, 0)) || len(r.Browsers) != 2 {
+		t.Errorf("result = %+v, want 3 visits from chrome and firefox spanning a year", r)
+	}
+
+	if results, _ := a.Search("async rust", SearchOptions{}); len(results) != 2 {
+		t.Errorf("async rust: got %d results, want 2", len(results))
+	}
+	if results, _ := a.Search("tok*", SearchOptions{}); len(results) != 1 {
+		t.Errorf("prefix search: got %d results, want 1", len(results))
+	}
+	if results, err := a.Search("c++", SearchOptions{}); err != nil || len(results) != 1 {
+		t.Errorf("c++: got %d results, %v", len(results), err)
+	}
+	if results, _ := a.Search("rust", SearchOptions{Browser: "firefox"}); len(results) != 1 || results[0].Visits != 1 {
+		t.Errorf("browser filter: got %+v", results)
+	}
+	if results, _ := a.Search("rust", SearchOptions{Start: day.AddDate(0, 0, 1)}); len(results) != 1 {
+		t.Errorf("date filter: got %d results, want 1", len(results))
+	}
+	if _, err := a.Search(" * ", SearchOptions{}); err == nil {
+		t.Error("empty query: expected error")
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := map[string]string{
+		"rust async":    `"rust" "async"`,
+		`say "hi" now*`: `"say" """hi""" "now"*`,
+		"  ":            "",
+	}
+	for in, want := range tests {
+		if got := MatchExpression(in); got != want {
+			t.Errorf("MatchExpression(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
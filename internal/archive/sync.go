@@ -0,0 +1,64 @@
+package archive
+
+import (
+	"context"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/blocklist"
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// SyncBrowser copies visits newer than the browser's watermark, and all of
+// its bookmarks, into the archive, leaving out blocked domains. Failures are
+// recorded in the result so one unreadable data type doesn't stop the other.
+func SyncBrowser(ctx context.Context, a *Archive, b browser.Browser, source string, blocked *blocklist.List) models.ArchiveSyncResult {
+	result := models.ArchiveSyncResult{Browser: string(b.Type)}
+
+	since, err := a.Watermark(string(b.Type), KindHistory)
+	if err != nil {
+		result.Errors = append(result.Errors, "history: "+err.Error())
+		return result
+	}
+	// The handlers return only the latest visits when no range is given, so
+	// a first sync asks for everything since the epoch instead
+	if since.IsZero() {
+		since = time.Unix(1, 0)
+	}
+
+	entries, err := database.Query(ctx, &b, since, time.Time{})
+	if err != nil {
+		result.Errors = append(result.Errors, "history: "+err.Error())
+	} else {
+		entries = blocked.History(entries)
+		if source != "" {
+			for i := range entries {
+				entries[i].Source = source
+			}
+		}
+		if result.Visits, err = a.AddVisits(string(b.Type), entries); err != nil {
+			result.Errors = append(result.Errors, "history: "+err.Error())
+		}
+	}
+
+	bookmarkPath, err := database.ResolveBookmarkPath(b.Type)
+	if err == nil {
+		var bookmarks []models.BookmarkEntry
+		bookmarks, err = database.QueryBookmarks(ctx, &b, bookmarkPath, time.Time{}, time.Time{})
+		if err == nil {
+			bookmarks = blocked.Bookmarks(bookmarks)
+			if source != "" {
+				for i := range bookmarks {
+					bookmarks[i].Source = source
+				}
+			}
+			result.Bookmarks, err = a.AddBookmarks(string(b.Type), bookmarks)
+		}
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, "bookmarks: "+err.Error())
+	}
+
+	return result
+}
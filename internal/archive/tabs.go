@@ -0,0 +1,134 @@
+package archive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// AddTabSnapshot records the tabs open at a point in time: the tab and
+// window counts of each browser, and when each tab was first and last seen
+// open. Browsers without tabs get no snapshot row.
+func (a *Archive) AddTabSnapshot(at time.Time, entries []models.TabEntry) error {
+	type window struct {
+		id     int
+		device string
+	}
+	var browsers []string
+	byBrowser := make(map[string][]models.TabEntry)
+	for _, e := range entries {
+		if _, ok := byBrowser[e.Browser]; !ok {
+			browsers = append(browsers, e.Browser)
+		}
+		byBrowser[e.Browser] = append(byBrowser[e.Browser], e)
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := toMicros(at)
+	for _, b := range browsers {
+		// Tabs not seen at the previous snapshot were closed in between
+		var previous int64
+		if err := tx.QueryRow(`SELECT coalesce(max(taken_at), 0) FROM tab_snapshots WHERE browser = ?`, b).Scan(&previous); err != nil {
+			return err
+		}
+
+		tabs := byBrowser[b]
+		windows := make(map[window]bool)
+		for _, e := range tabs {
+			windows[window{e.WindowID, e.Device}] = true
+		}
+		_, err := tx.Exec(`INSERT OR REPLACE INTO tab_snapshots (taken_at, browser, tabs, windows) VALUES (?, ?, ?, ?)`,
+			now, b, len(tabs), len(windows))
+		if err != nil {
+			return fmt.Errorf("failed to archive tab snapshot: %v", err)
+		}
+
+		for _, e := range tabs {
+			_, err := tx.Exec(`INSERT INTO open_tabs (browser, url, title, domain, first_seen, last_seen)
+				VALUES (?, ?, ?, ?, ?, ?)
+				ON CONFLICT (browser, url) DO UPDATE SET
+					title = excluded.title,
+					domain = excluded.domain,
+					first_seen = CASE WHEN open_tabs.last_seen < ? THEN excluded.first_seen ELSE open_tabs.first_seen END,
+					last_seen = excluded.last_seen`,
+				b, e.URL, e.Title, e.Domain, now, now, previous)
+			if err != nil {
+				return fmt.Errorf("failed to archive tab: %v", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// TabCounts returns the tab snapshots taken in [start, end), oldest first.
+// A zero bound leaves that side open.
+func (a *Archive) TabCounts(start, end time.Time) ([]models.TabCount, error) {
+	query := `SELECT taken_at, browser, tabs, windows FROM tab_snapshots WHERE 1 = 1`
+	var args []interface{}
+	if !start.IsZero() {
+		query += ` AND taken_at >= ?`
+		args = append(args, toMicros(start))
+	}
+	if !end.IsZero() {
+		query += ` AND taken_at < ?`
+		args = append(args, toMicros(end))
+	}
+	query += ` ORDER BY taken_at, browser`
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive: %v", err)
+	}
+	defer rows.Close()
+
+	var counts []models.TabCount
+	for rows.Next() {
+		var c models.TabCount
+		var takenAt int64
+		if err := rows.Scan(&takenAt, &c.Browser, &c.Tabs, &c.Windows); err != nil {
+			return nil, err
+		}
+		c.Time = fromMicros(takenAt)
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// OpenTabs returns the tabs open at the latest snapshot of their browser
+// that were first seen at or before openSince, longest open first. A zero
+// openSince returns every open tab.
+func (a *Archive) OpenTabs(openSince time.Time) ([]models.ArchivedTab, error) {
+	query := `SELECT url, title, domain, browser, first_seen, last_seen FROM open_tabs t
+		WHERE last_seen = (SELECT max(taken_at) FROM tab_snapshots WHERE browser = t.browser)`
+	var args []interface{}
+	if !openSince.IsZero() {
+		query += ` AND first_seen <= ?`
+		args = append(args, toMicros(openSince))
+	}
+	query += ` ORDER BY first_seen, url`
+
+	rows, err := a.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive: %v", err)
+	}
+	defer rows.Close()
+
+	var tabs []models.ArchivedTab
+	for rows.Next() {
+		var t models.ArchivedTab
+		var first, last int64
+		if err := rows.Scan(&t.URL, &t.Title, &t.Domain, &t.Browser, &first, &last); err != nil {
+			return nil, err
+		}
+		t.FirstSeen = fromMicros(first)
+		t.LastSeen = fromMicros(last)
+		tabs = append(tabs, t)
+	}
+	return tabs, rows.Err()
+}
@@ -0,0 +1,73 @@
+package archive
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestTabSnapshots(t *testing.T) {
+	a, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	day := 24 * time.Hour
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	docs := models.TabEntry{URL: "https://go.dev/doc/", Browser: "Chrome", WindowID: 1}
+	news := models.TabEntry{URL: "https://news.example/", Browser: "Chrome", WindowID: 2}
+	mail := models.TabEntry{URL: "https://mail.example/", Browser: "Chrome", WindowID: 1}
+
+	snapshots := []struct {
+		at   time.Time
+		tabs []models.TabEntry
+	}{
+		{start, []models.TabEntry{docs, news}},
+		{start.Add(3 * day), []models.TabEntry{docs}},
+		// news was closed in between, so it starts over
+		{start.Add(10 * day), []models.TabEntry{docs, news, mail}},
+	}
+	for _, s := range snapshots {
+		if err := a.AddTabSnapshot(s.at, s.tabs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := a.TabCounts(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.TabCount{
+		{Time: start, Browser: "Chrome", Tabs: 2, Windows: 2},
+		{Time: start.Add(3 * day), Browser: "Chrome", Tabs: 1, Windows: 1},
+		{Time: start.Add(10 * day), Browser: "Chrome", Tabs: 3, Windows: 2},
+	}
+	if len(counts) != len(want) {
+		t.Fatalf("TabCounts = %v, want %v", counts, want)
+	}
+	for i := range want {
+		if !counts[i].Time.Equal(want[i].Time) || counts[i].Tabs != want[i].Tabs || counts[i].Windows != want[i].Windows {
+			t.Errorf("TabCounts[%d] = %+v, want %+v", i, counts[i], want[i])
+		}
+	}
+
+	// Open for more than a week at the last snapshot
+	tabs, err := a.OpenTabs(start.Add(10*day - 7*day))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tabs) != 1 || tabs[0].URL != docs.URL || !tabs[0].FirstSeen.Equal(start) {
+		t.Errorf("OpenTabs = %+v, want only %s first seen %v", tabs, docs.URL, start)
+	}
+
+	all, err := a.OpenTabs(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("OpenTabs(zero) = %d tabs, want 3", len(all))
+	}
+}
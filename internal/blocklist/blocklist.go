@@ -0,0 +1,160 @@
+// Package blocklist excludes sensitive sites from everything web-recap reads
+package blocklist
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// List is a set of blocked domains. A nil List blocks nothing.
+type List struct {
+	patterns []string
+}
+
+// DefaultPath returns the blocklist location used when none is configured,
+// e.g. ~/.config/web-recap/blocklist.txt on Linux
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web-recap", "blocklist.txt"), nil
+}
+
+// Load reads the blocklist at path, or the default location when path is
+// empty. A leading ~/ is expanded. A missing default blocklist is not an
+// error and returns nil.
+func Load(path string) (*List, error) {
+	explicit := path != ""
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !explicit {
+		p, err := DefaultPath()
+		if err != nil {
+			return nil, nil
+		}
+		path = p
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read blocklist: %v", err)
+	}
+	defer f.Close()
+	l, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blocklist %s: %v", path, err)
+	}
+	return l, nil
+}
+
+// Parse reads one domain or glob per line. Blank lines and lines starting
+// with # are skipped. A plain domain also blocks its subdomains; globs use
+// path.Match syntax, e.g. *.bank.example or health*.
+func Parse(r io.Reader) (*List, error) {
+	l := &List{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		p := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		// Accept pasted URLs as well as bare domains
+		if i := strings.Index(p, "://"); i >= 0 {
+			p = p[i+3:]
+		}
+		p = strings.TrimPrefix(strings.TrimSuffix(strings.SplitN(p, "/", 2)[0], "."), "www.")
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q", line, p)
+		}
+		l.patterns = append(l.patterns, p)
+	}
+	return l, scanner.Err()
+}
+
+// Len returns the number of patterns
+func (l *List) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.patterns)
+}
+
+// Blocks reports whether the domain matches the list. Ports and a leading
+// www. are ignored.
+func (l *List) Blocks(domain string) bool {
+	if l.Len() == 0 || domain == "" {
+		return false
+	}
+	domain = strings.ToLower(domain)
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	domain = strings.TrimPrefix(domain, "www.")
+	for _, p := range l.patterns {
+		if domain == p || strings.HasSuffix(domain, "."+p) {
+			return true
+		}
+		if ok, _ := path.Match(p, domain); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// History returns the entries whose domains are not blocked
+func (l *List) History(entries []models.HistoryEntry) []models.HistoryEntry {
+	if l.Len() == 0 {
+		return entries
+	}
+	kept := entries[:0:0]
+	for _, e := range entries {
+		if !l.Blocks(e.Domain) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// Bookmarks returns the bookmarks whose domains are not blocked
+func (l *List) Bookmarks(entries []models.BookmarkEntry) []models.BookmarkEntry {
+	if l.Len() == 0 {
+		return entries
+	}
+	kept := entries[:0:0]
+	for _, e := range entries {
+		if !l.Blocks(e.Domain) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// Tabs returns the tabs whose domains are not blocked
+func (l *List) Tabs(entries []models.TabEntry) []models.TabEntry {
+	if l.Len() == 0 {
+		return entries
+	}
+	kept := entries[:0:0]
+	for _, e := range entries {
+		if !l.Blocks(e.Domain) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,81 @@
+package blocklist
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestBlocks(t *testing.T) {
+	l, err := Parse(strings.NewReader(`# Sensitive sites
+mybank.example
+
+https://www.health.example/portal
+*.clinic.example
+therapy*
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		domain string
+		want   bool
+	}{
+		{domain: "mybank.example", want: true},
+		{domain: "www.mybank.example", want: true},
+		{domain: "online.mybank.example", want: true},
+		{domain: "MyBank.Example:8443", want: true},
+		{domain: "notmybank.example", want: false},
+		{domain: "health.example", want: true},
+		{domain: "east.clinic.example", want: true},
+		{domain: "clinic.example", want: false},
+		{domain: "therapy-notes.example", want: true},
+		{domain: "go.dev", want: false},
+		{domain: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := l.Blocks(tt.domain); got != tt.want {
+			t.Errorf("Blocks(%q) = %v; want %v", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	l, _ := Parse(strings.NewReader("mybank.example\n"))
+	history := l.History([]models.HistoryEntry{{Domain: "mybank.example"}, {Domain: "go.dev"}})
+	if len(history) != 1 || history[0].Domain != "go.dev" {
+		t.Errorf("history %+v", history)
+	}
+	bookmarks := l.Bookmarks([]models.BookmarkEntry{{Domain: "www.mybank.example"}})
+	if len(bookmarks) != 0 {
+		t.Errorf("bookmarks %+v", bookmarks)
+	}
+
+	// A nil list blocks nothing
+	var none *List
+	if none.Blocks("mybank.example") || len(none.Tabs([]models.TabEntry{{Domain: "mybank.example"}})) != 1 {
+		t.Error("nil list blocked an entry")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Parse(strings.NewReader("[bad\n")); err == nil {
+		t.Error("expected an error for an invalid glob")
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing explicit blocklist")
+	}
+
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("a.example\nb.example\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Load(path)
+	if err != nil || l.Len() != 2 {
+		t.Errorf("Load = %v, %v", l.Len(), err)
+	}
+}
@@ -0,0 +1,88 @@
+package browser
+
+import "path/filepath"
+
+// chromiumBrowser is a built-in Chromium-based browser. All of them are read
+// by the same engine handlers, so a browser is only its name and where it
+// keeps its default profile: below the home directory on Linux and macOS,
+// and below %LOCALAPPDATA% on Windows. An empty path means the browser is not
+// made for that platform.
+type chromiumBrowser struct {
+	Type    Type
+	Name    string
+	Linux   string
+	Darwin  string
+	Windows string
+}
+
+// chromiumBrowsers are the built-in Chromium-based browsers in detection
+// order. Adding a fork is adding a line here.
+var chromiumBrowsers = []chromiumBrowser{
+	{Chrome, "Google Chrome", ".config/google-chrome/Default", "Library/Application Support/Google/Chrome/Default", `Google\Chrome\User Data\Default`},
+	{Chromium, "Chromium", ".config/chromium/Default", "Library/Application Support/Chromium/Default", `Chromium\User Data\Default`},
+	{Edge, "Microsoft Edge", ".config/microsoft-edge/Default", "Library/Application Support/Microsoft Edge/Default", `Microsoft\Edge\User Data\Default`},
+	{Brave, "Brave", ".config/BraveSoftware/Brave-Browser/Default", "Library/Application Support/BraveSoftware/Brave-Browser/Default", `BraveSoftware\Brave-Browser\User Data\Default`},
+	{Vivaldi, "Vivaldi", ".config/vivaldi/Default", "Library/Application Support/Vivaldi/Default", `Vivaldi\User Data\Default`},
+	{Whale, "Naver Whale", ".config/naver-whale/Default", "Library/Application Support/Naver/Whale/Default", `Naver\Naver Whale\User Data\Default`},
+	{SamsungInternet, "Samsung Internet", "", "", `Samsung\Samsung Internet\User Data\Default`},
+	{Yandex, "Yandex Browser", ".config/yandex-browser/Default", "Library/Application Support/Yandex/YandexBrowser/Default", `Yandex\YandexBrowser\User Data\Default`},
+	{Arc, "Arc", "", "Library/Application Support/Arc/User Data/Default", ""},
+}
+
+func init() {
+	for _, b := range chromiumBrowsers {
+		builtinEngines[b.Type] = EngineChromium
+	}
+}
+
+// builtinChromium returns the built-in Chromium-based browser of type t
+func builtinChromium(t Type) (chromiumBrowser, bool) {
+	for _, b := range chromiumBrowsers {
+		if b.Type == t {
+			return b, true
+		}
+	}
+	return chromiumBrowser{}, false
+}
+
+// file returns the path of file in the profile dir below base, or
+// ErrBrowserNotAvailable when the browser has no profile on this platform
+func (b chromiumBrowser) file(base, dir, file string) (string, error) {
+	if dir == "" {
+		return "", ErrBrowserNotAvailable
+	}
+	return filepath.Join(base, dir, file), nil
+}
+
+// builtinName returns the display name of a built-in browser type
+func builtinName(t Type) string {
+	if b, ok := builtinChromium(t); ok {
+		return b.Name
+	}
+	if b, ok := builtinGecko(t); ok {
+		return b.Name
+	}
+	if b, ok := builtinText(t); ok {
+		return b.Name
+	}
+	if t == Safari {
+		return "Safari"
+	}
+	return string(t)
+}
+
+// builtinTypes returns the built-in browser types in detection order
+func builtinTypes() []Type {
+	types := make([]Type, 0, len(chromiumBrowsers)+len(geckoBrowsers)+1+len(textBrowsers))
+	for _, b := range chromiumBrowsers {
+		types = append(types, b.Type)
+	}
+	for _, b := range geckoBrowsers {
+		types = append(types, b.Type)
+	}
+	types = append(types, Safari)
+	for _, b := range textBrowsers {
+		types = append(types, b.Type)
+	}
+	return types
+}
@@ -0,0 +1,120 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// DataDirBrowsers returns one definition per profile found under dataDir,
+// the user-data root of a browser of type t: a portable Chrome's "User Data",
+// a Firefox profiles directory, or a copy of either from another machine.
+// dataDir may also be a single profile. Each definition has the type
+// "<t>:<profile>", so the profiles can be registered and read side by side.
+func DataDirBrowsers(t Type, dataDir string) ([]Definition, error) {
+	info, err := os.Stat(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("data directory not found: %s", dataDir)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("data directory is not a directory: %s", dataDir)
+	}
+
+	engine := EngineOf(t)
+	var defs []Definition
+	switch engine {
+	case EngineChromium:
+		names := chromiumProfileNames(dataDir)
+		for _, dir := range profileDirs(dataDir, "History", dataDir) {
+			profile := filepath.Base(dir)
+			label := profile
+			if name := names[profile]; name != "" {
+				label = name
+			}
+			defs = append(defs, Definition{
+				Type:   Type(string(t) + ":" + profile),
+				Name:   fmt.Sprintf("%s (%s)", t, label),
+				Engine: engine,
+				Paths:  []string{dir},
+			})
+		}
+	case EngineGecko:
+		// Windows and macOS keep the profiles in a Profiles subdirectory
+		for _, dir := range profileDirs(dataDir, "places.sqlite", dataDir, filepath.Join(dataDir, "Profiles")) {
+			places := []string{filepath.Join(dir, "places.sqlite")}
+			defs = append(defs, Definition{
+				Type:      Type(string(t) + ":" + filepath.Base(dir)),
+				Name:      fmt.Sprintf("%s (%s)", t, filepath.Base(dir)),
+				Engine:    engine,
+				History:   OSPaths{runtime.GOOS: places},
+				Bookmarks: OSPaths{runtime.GOOS: places},
+			})
+		}
+	case EngineWebKit:
+		if fileExists(filepath.Join(dataDir, "History.db")) {
+			defs = append(defs, Definition{
+				Type:   Type(string(t) + ":" + filepath.Base(dataDir)),
+				Name:   string(t),
+				Engine: engine,
+				Paths:  []string{dataDir},
+			})
+		}
+	default:
+		return nil, fmt.Errorf("--data-dir is not supported for %s", t)
+	}
+
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no %s profiles found in %s", t, dataDir)
+	}
+	return defs, nil
+}
+
+// profileDirs returns dataDir itself when it holds marker, otherwise the
+// subdirectories of parents that hold it, sorted by name
+func profileDirs(dataDir, marker string, parents ...string) []string {
+	if fileExists(filepath.Join(dataDir, marker)) {
+		return []string{dataDir}
+	}
+	var dirs []string
+	for _, parent := range parents {
+		entries, err := os.ReadDir(parent)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			dir := filepath.Join(parent, entry.Name())
+			if entry.IsDir() && fileExists(filepath.Join(dir, marker)) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// chromiumProfileNames reads the names users gave their profiles from the
+// "Local State" file of a Chromium user-data root, keyed by directory name
+func chromiumProfileNames(dataDir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dataDir, "Local State"))
+	if err != nil {
+		return nil
+	}
+	var state struct {
+		Profile struct {
+			InfoCache map[string]struct {
+				Name string `json:"name"`
+			} `json:"info_cache"`
+		} `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	names := make(map[string]string, len(state.Profile.InfoCache))
+	for dir, info := range state.Profile.InfoCache {
+		names[dir] = info.Name
+	}
+	return names
+}
@@ -0,0 +1,62 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDataDirBrowsers(t *testing.T) {
+	touch := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	userData := t.TempDir()
+	touch(filepath.Join(userData, "Default", "History"))
+	touch(filepath.Join(userData, "Profile 1", "History"))
+	touch(filepath.Join(userData, "System Profile", "Preferences"))
+	state := `{"profile":{"info_cache":{"Profile 1":{"name":"Work"}}}}`
+	if err := os.WriteFile(filepath.Join(userData, "Local State"), []byte(state), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	firefox := t.TempDir()
+	touch(filepath.Join(firefox, "Profiles", "abcd.default-release", "places.sqlite"))
+
+	tests := []struct {
+		name    string
+		browser Type
+		dir     string
+		want    map[Type]string
+	}{
+		{"chromium root", Chrome, userData, map[Type]string{"chrome:Default": "chrome (Default)", "chrome:Profile 1": "chrome (Work)"}},
+		{"chromium profile", Brave, filepath.Join(userData, "Default"), map[Type]string{"brave:Default": "brave (Default)"}},
+		{"gecko root", Firefox, firefox, map[Type]string{"firefox:abcd.default-release": "firefox (abcd.default-release)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs, err := DataDirBrowsers(tt.browser, tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(defs) != len(tt.want) {
+				t.Fatalf("got %d profiles, want %d: %+v", len(defs), len(tt.want), defs)
+			}
+			for _, def := range defs {
+				if tt.want[def.Type] != def.Name {
+					t.Errorf("%s named %q, want %q", def.Type, def.Name, tt.want[def.Type])
+				}
+			}
+		})
+	}
+
+	if _, err := DataDirBrowsers(Safari, userData); err == nil {
+		t.Error("expected an error when no profiles are found")
+	}
+}
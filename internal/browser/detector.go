@@ -1,7 +1,14 @@
 package browser
 
+import "context"
+
 // Detector detects available browsers on the system
-type Detector struct{}
+type Detector struct {
+	// Only limits detection to these browser types when set
+	Only []Type
+	// Exclude leaves these browser types out of detection
+	Exclude []Type
+}
 
 // NewDetector creates a new browser detector
 func NewDetector() *Detector {
@@ -10,22 +17,34 @@ func NewDetector() *Detector {
 
 // Detect returns a list of available browsers
 func (d *Detector) Detect() []Browser {
+	browsers, _ := d.DetectContext(context.Background())
+	return browsers
+}
+
+// DetectContext is like Detect but stops with ctx's error once it is done
+func (d *Detector) DetectContext(ctx context.Context) ([]Browser, error) {
 	var browsers []Browser
 
 	// Check each browser type
-	for _, bType := range []Type{Chrome, Chromium, Edge, Brave, Vivaldi, Firefox, Safari} {
+	for _, bType := range builtinTypes() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !d.selected(bType) {
+			continue
+		}
 		path, err := GetDatabasePath(bType)
 		if err != nil {
 			continue
 		}
 
-		// For Firefox, handle profile detection
-		if bType == Firefox {
+		// For Firefox and its derivatives, handle profile detection
+		if EngineOf(bType) == EngineGecko {
 			profilePath, err := GetFirefoxProfilePath(path)
 			if err == nil {
 				browsers = append(browsers, Browser{
-					Type: Firefox,
-					Name: "Firefox",
+					Type: bType,
+					Name: builtinName(bType),
 					Path: profilePath,
 				})
 			}
@@ -34,30 +53,46 @@ func (d *Detector) Detect() []Browser {
 
 		// For other browsers, check if the database file exists
 		if fileExists(path) {
-			name := string(bType)
-			if bType == Chrome {
-				name = "Google Chrome"
-			} else if bType == Chromium {
-				name = "Chromium"
-			} else if bType == Edge {
-				name = "Microsoft Edge"
-			} else if bType == Brave {
-				name = "Brave"
-			} else if bType == Vivaldi {
-				name = "Vivaldi"
-			} else if bType == Safari {
-				name = "Safari"
-			}
-
 			browsers = append(browsers, Browser{
 				Type: bType,
-				Name: name,
+				Name: builtinName(bType),
 				Path: path,
 			})
 		}
 	}
 
-	return browsers
+	// Registered browsers, e.g. Chromium forks defined in the config file
+	for _, def := range Registered() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !d.selected(def.Type) {
+			continue
+		}
+		if b, err := d.GetBrowser(def.Type); err == nil {
+			browsers = append(browsers, *b)
+		}
+	}
+
+	return browsers, nil
+}
+
+// selected reports whether Only and Exclude let detection consider t
+func (d *Detector) selected(t Type) bool {
+	for _, excluded := range d.Exclude {
+		if t == excluded {
+			return false
+		}
+	}
+	if len(d.Only) == 0 {
+		return true
+	}
+	for _, only := range d.Only {
+		if t == only {
+			return true
+		}
+	}
+	return false
 }
 
 // GetBrowser returns a specific browser, detecting if necessary
@@ -76,15 +111,32 @@ func (d *Detector) GetBrowser(browserType Type) (*Browser, error) {
 		return nil, err
 	}
 
-	// For Firefox, handle profile detection
-	if browserType == Firefox {
+	// Registered browsers carry their own display name
+	if def, ok := Lookup(browserType); ok {
+		if def.Engine == EngineGecko {
+			path, err = GetFirefoxProfilePath(path)
+			if err != nil {
+				return nil, err
+			}
+		} else if def.Engine != EngineCustom && !fileExists(path) {
+			return nil, ErrDatabaseNotFound
+		}
+		return &Browser{
+			Type: browserType,
+			Name: def.Name,
+			Path: path,
+		}, nil
+	}
+
+	// For Firefox and its derivatives, handle profile detection
+	if EngineOf(browserType) == EngineGecko {
 		profilePath, err := GetFirefoxProfilePath(path)
 		if err != nil {
 			return nil, err
 		}
 		return &Browser{
-			Type: Firefox,
-			Name: "Firefox",
+			Type: browserType,
+			Name: builtinName(browserType),
 			Path: profilePath,
 		}, nil
 	}
@@ -94,24 +146,9 @@ func (d *Detector) GetBrowser(browserType Type) (*Browser, error) {
 		return nil, ErrDatabaseNotFound
 	}
 
-	name := string(browserType)
-	if browserType == Chrome {
-		name = "Google Chrome"
-	} else if browserType == Chromium {
-		name = "Chromium"
-	} else if browserType == Edge {
-		name = "Microsoft Edge"
-	} else if browserType == Brave {
-		name = "Brave"
-	} else if browserType == Vivaldi {
-		name = "Vivaldi"
-	} else if browserType == Safari {
-		name = "Safari"
-	}
-
 	return &Browser{
 		Type: browserType,
-		Name: name,
+		Name: builtinName(browserType),
 		Path: path,
 	}, nil
 }
@@ -0,0 +1,123 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDetectorSelection(t *testing.T) {
+	t.Cleanup(func() { registry = nil })
+	t.Setenv("HOME", t.TempDir())
+
+	for _, name := range []string{"thorium", "cromite", "mercury"} {
+		profile := filepath.Join(t.TempDir(), name)
+		if err := os.MkdirAll(profile, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(profile, "History"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Register(Definition{Type: Type(name), Engine: EngineChromium, Paths: []string{profile}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		detector Detector
+		want     string
+	}{
+		{"all", Detector{}, "thorium,cromite,mercury"},
+		{"only", Detector{Only: []Type{"mercury", "thorium"}}, "thorium,mercury"},
+		{"exclude", Detector{Exclude: []Type{"cromite"}}, "thorium,mercury"},
+		{"only and exclude", Detector{Only: []Type{"thorium", "cromite"}, Exclude: []Type{"thorium"}}, "cromite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, b := range tt.detector.Detect() {
+				got = append(got, string(b.Type))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("detected %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectChromiumFork(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("profile paths below are Linux paths")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	profile := filepath.Join(home, ".config/naver-whale/Default")
+	if err := os.MkdirAll(profile, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(profile, "History"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	browsers := NewDetector().Detect()
+	if len(browsers) != 1 || browsers[0].Type != Whale || browsers[0].Name != "Naver Whale" {
+		t.Fatalf("detected %+v, want Naver Whale", browsers)
+	}
+	if !IsChromiumBased(Whale) {
+		t.Error("whale is not Chromium-based")
+	}
+	if got, err := GetSessionPath(Whale); err != nil || got != filepath.Join(profile, "Sessions") {
+		t.Errorf("GetSessionPath(whale) = %q, %v", got, err)
+	}
+}
+
+func TestDetectGeckoDerivative(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("profile paths below are Linux paths")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	profile := filepath.Join(home, ".mozilla/seamonkey/abcd1234.default")
+	if err := os.MkdirAll(profile, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	places := filepath.Join(profile, "places.sqlite")
+	if err := os.WriteFile(places, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	browsers := NewDetector().Detect()
+	if len(browsers) != 1 || browsers[0].Type != SeaMonkey || browsers[0].Name != "SeaMonkey" || browsers[0].Path != places {
+		t.Fatalf("detected %+v, want SeaMonkey at %s", browsers, places)
+	}
+	if _, err := GetSessionPath(SeaMonkey); err != ErrBrowserNotAvailable {
+		t.Errorf("GetSessionPath(seamonkey) err = %v, want ErrBrowserNotAvailable", err)
+	}
+}
+
+func TestDetectTextBrowser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	history := filepath.Join(home, ".w3m", "history")
+	if err := os.MkdirAll(filepath.Dir(history), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(history, []byte("https://example.com/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewDetector().GetBrowser(W3m)
+	if err != nil || b.Path != history || b.Name != "w3m" {
+		t.Fatalf("GetBrowser(w3m) = %+v, %v", b, err)
+	}
+	if EngineOf(W3m) != EngineText {
+		t.Errorf("EngineOf(w3m) = %s", EngineOf(W3m))
+	}
+	if _, err := GetBookmarkPath(W3m); err != ErrBrowserNotAvailable {
+		t.Errorf("GetBookmarkPath(w3m) err = %v, want ErrBrowserNotAvailable", err)
+	}
+}
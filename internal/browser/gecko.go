@@ -0,0 +1,47 @@
+package browser
+
+import "path/filepath"
+
+// geckoBrowser is a built-in Firefox-family browser. They are read by the
+// same engine handlers, from the directory that holds their profiles: below
+// the home directory on every platform. An empty path means the browser is
+// not made for that platform.
+type geckoBrowser struct {
+	Type    Type
+	Name    string
+	Linux   string
+	Darwin  string
+	Windows string
+}
+
+// geckoBrowsers are the built-in Gecko browsers in detection order
+var geckoBrowsers = []geckoBrowser{
+	{Firefox, "Firefox", ".mozilla/firefox", "Library/Application Support/Firefox/Profiles", "AppData/Roaming/Mozilla/Firefox/Profiles"},
+	{PaleMoon, "Pale Moon", ".moonchild productions/pale moon", "Library/Application Support/Pale Moon/Profiles", "AppData/Roaming/Moonchild Productions/Pale Moon/Profiles"},
+	{SeaMonkey, "SeaMonkey", ".mozilla/seamonkey", "Library/Application Support/SeaMonkey/Profiles", "AppData/Roaming/Mozilla/SeaMonkey/Profiles"},
+}
+
+func init() {
+	for _, b := range geckoBrowsers {
+		builtinEngines[b.Type] = EngineGecko
+	}
+}
+
+// builtinGecko returns the built-in Gecko browser of type t
+func builtinGecko(t Type) (geckoBrowser, bool) {
+	for _, b := range geckoBrowsers {
+		if b.Type == t {
+			return b, true
+		}
+	}
+	return geckoBrowser{}, false
+}
+
+// profiles returns the directory below home holding the browser's profiles,
+// which GetFirefoxProfilePath picks one from
+func (b geckoBrowser) profiles(home, dir string) (string, error) {
+	if dir == "" {
+		return "", ErrBrowserNotAvailable
+	}
+	return filepath.Join(home, filepath.FromSlash(dir)), nil
+}
@@ -9,6 +9,13 @@ import (
 
 // GetDatabasePath returns the database path for a given browser type on the current platform
 func GetDatabasePath(browserType Type) (string, error) {
+	if def, ok := Lookup(browserType); ok {
+		return def.historyPath()
+	}
+	if b, ok := builtinText(browserType); ok {
+		return b.historyPath()
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -27,20 +34,14 @@ func GetDatabasePath(browserType Type) (string, error) {
 }
 
 func getLinuxPath(home string, browserType Type) (string, error) {
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(home, b.Linux, "History")
+	}
+	if b, ok := builtinGecko(browserType); ok {
+		return b.profiles(home, b.Linux)
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(home, ".config/google-chrome/Default/History"), nil
-	case Chromium:
-		return filepath.Join(home, ".config/chromium/Default/History"), nil
-	case Edge:
-		return filepath.Join(home, ".config/microsoft-edge/Default/History"), nil
-	case Brave:
-		return filepath.Join(home, ".config/BraveSoftware/Brave-Browser/Default/History"), nil
-	case Vivaldi:
-		return filepath.Join(home, ".config/vivaldi/Default/History"), nil
-	case Firefox:
-		// Firefox uses profile directory, we'll handle this in detector
-		return filepath.Join(home, ".mozilla/firefox"), nil
 	case Safari:
 		// Safari not available on Linux
 		return "", ErrBrowserNotAvailable
@@ -52,19 +53,14 @@ func getLinuxPath(home string, browserType Type) (string, error) {
 }
 
 func getDarwinPath(home string, browserType Type) (string, error) {
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(home, b.Darwin, "History")
+	}
+	if b, ok := builtinGecko(browserType); ok {
+		return b.profiles(home, b.Darwin)
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(home, "Library/Application Support/Google/Chrome/Default/History"), nil
-	case Chromium:
-		return filepath.Join(home, "Library/Application Support/Chromium/Default/History"), nil
-	case Edge:
-		return filepath.Join(home, "Library/Application Support/Microsoft Edge/Default/History"), nil
-	case Brave:
-		return filepath.Join(home, "Library/Application Support/BraveSoftware/Brave-Browser/Default/History"), nil
-	case Vivaldi:
-		return filepath.Join(home, "Library/Application Support/Vivaldi/Default/History"), nil
-	case Firefox:
-		return filepath.Join(home, "Library/Application Support/Firefox"), nil
 	case Safari:
 		return filepath.Join(home, "Library/Safari/History.db"), nil
 	case Auto:
@@ -84,19 +80,18 @@ func getWindowsPath(browserType Type) (string, error) {
 		appData = filepath.Join(home, "AppData/Local")
 	}
 
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(appData, b.Windows, "History")
+	}
+	if b, ok := builtinGecko(browserType); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return b.profiles(home, b.Windows)
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(appData, `Google\Chrome\User Data\Default\History`), nil
-	case Chromium:
-		return filepath.Join(appData, `Chromium\User Data\Default\History`), nil
-	case Edge:
-		return filepath.Join(appData, `Microsoft\Edge\User Data\Default\History`), nil
-	case Brave:
-		return filepath.Join(appData, `BraveSoftware\Brave-Browser\User Data\Default\History`), nil
-	case Vivaldi:
-		return filepath.Join(appData, `Vivaldi\User Data\Default\History`), nil
-	case Firefox:
-		return filepath.Join(appData, "Mozilla/Firefox"), nil
 	case Safari:
 		// Safari not available on Windows
 		return "", ErrBrowserNotAvailable
@@ -107,11 +102,16 @@ func getWindowsPath(browserType Type) (string, error) {
 	}
 }
 
-// GetFirefoxProfilePath returns the active Firefox profile path
+// GetFirefoxProfilePath returns the active Firefox profile path. A path to
+// a database file, such as a configured places.sqlite, is returned as-is.
 func GetFirefoxProfilePath(profileBaseDir string) (string, error) {
-	if !fileExists(profileBaseDir) {
+	info, err := os.Stat(profileBaseDir)
+	if err != nil {
 		return "", ErrFirefoxProfileNotFound
 	}
+	if !info.IsDir() {
+		return profileBaseDir, nil
+	}
 
 	// Try to find the default profile or most recently modified profile
 	entries, err := os.ReadDir(profileBaseDir)
@@ -166,6 +166,13 @@ func fileExists(path string) bool {
 
 // GetBookmarkPath returns the bookmark database path for a given browser type on the current platform
 func GetBookmarkPath(browserType Type) (string, error) {
+	if def, ok := Lookup(browserType); ok {
+		return def.bookmarkPath()
+	}
+	if _, ok := builtinText(browserType); ok {
+		return "", ErrBrowserNotAvailable
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -184,20 +191,14 @@ func GetBookmarkPath(browserType Type) (string, error) {
 }
 
 func getLinuxBookmarkPath(home string, browserType Type) (string, error) {
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(home, b.Linux, "Bookmarks")
+	}
+	if b, ok := builtinGecko(browserType); ok {
+		return b.profiles(home, b.Linux)
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(home, ".config/google-chrome/Default/Bookmarks"), nil
-	case Chromium:
-		return filepath.Join(home, ".config/chromium/Default/Bookmarks"), nil
-	case Edge:
-		return filepath.Join(home, ".config/microsoft-edge/Default/Bookmarks"), nil
-	case Brave:
-		return filepath.Join(home, ".config/BraveSoftware/Brave-Browser/Default/Bookmarks"), nil
-	case Vivaldi:
-		return filepath.Join(home, ".config/vivaldi/Default/Bookmarks"), nil
-	case Firefox:
-		// Firefox bookmarks are in places.sqlite (same as history)
-		return filepath.Join(home, ".mozilla/firefox"), nil
 	case Safari:
 		// Safari not available on Linux
 		return "", ErrBrowserNotAvailable
@@ -209,19 +210,14 @@ func getLinuxBookmarkPath(home string, browserType Type) (string, error) {
 }
 
 func getDarwinBookmarkPath(home string, browserType Type) (string, error) {
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(home, b.Darwin, "Bookmarks")
+	}
+	if b, ok := builtinGecko(browserType); ok {
+		return b.profiles(home, b.Darwin)
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(home, "Library/Application Support/Google/Chrome/Default/Bookmarks"), nil
-	case Chromium:
-		return filepath.Join(home, "Library/Application Support/Chromium/Default/Bookmarks"), nil
-	case Edge:
-		return filepath.Join(home, "Library/Application Support/Microsoft Edge/Default/Bookmarks"), nil
-	case Brave:
-		return filepath.Join(home, "Library/Application Support/BraveSoftware/Brave-Browser/Default/Bookmarks"), nil
-	case Vivaldi:
-		return filepath.Join(home, "Library/Application Support/Vivaldi/Default/Bookmarks"), nil
-	case Firefox:
-		return filepath.Join(home, "Library/Application Support/Firefox"), nil
 	case Safari:
 		return filepath.Join(home, "Library/Safari/Bookmarks.plist"), nil
 	case Auto:
@@ -241,19 +237,18 @@ func getWindowsBookmarkPath(browserType Type) (string, error) {
 		appData = filepath.Join(home, "AppData/Local")
 	}
 
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(appData, b.Windows, "Bookmarks")
+	}
+	if b, ok := builtinGecko(browserType); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return b.profiles(home, b.Windows)
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(appData, `Google\Chrome\User Data\Default\Bookmarks`), nil
-	case Chromium:
-		return filepath.Join(appData, `Chromium\User Data\Default\Bookmarks`), nil
-	case Edge:
-		return filepath.Join(appData, `Microsoft\Edge\User Data\Default\Bookmarks`), nil
-	case Brave:
-		return filepath.Join(appData, `BraveSoftware\Brave-Browser\User Data\Default\Bookmarks`), nil
-	case Vivaldi:
-		return filepath.Join(appData, `Vivaldi\User Data\Default\Bookmarks`), nil
-	case Firefox:
-		return filepath.Join(appData, "Mozilla/Firefox"), nil
 	case Safari:
 		// Safari not available on Windows
 		return "", ErrBrowserNotAvailable
@@ -267,6 +262,13 @@ func getWindowsBookmarkPath(browserType Type) (string, error) {
 // GetSessionPath returns the session directory path for a given browser type on the current platform
 // This is used for extracting open tabs from Chromium-based browsers
 func GetSessionPath(browserType Type) (string, error) {
+	if def, ok := Lookup(browserType); ok {
+		return def.sessionPath()
+	}
+	if _, ok := builtinText(browserType); ok {
+		return "", ErrBrowserNotAvailable
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -285,18 +287,15 @@ func GetSessionPath(browserType Type) (string, error) {
 }
 
 func getLinuxSessionPath(home string, browserType Type) (string, error) {
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(home, b.Linux, "Sessions")
+	}
+	if _, ok := builtinGecko(browserType); ok {
+		return "", ErrBrowserNotAvailable
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(home, ".config/google-chrome/Default/Sessions"), nil
-	case Chromium:
-		return filepath.Join(home, ".config/chromium/Default/Sessions"), nil
-	case Edge:
-		return filepath.Join(home, ".config/microsoft-edge/Default/Sessions"), nil
-	case Brave:
-		return filepath.Join(home, ".config/BraveSoftware/Brave-Browser/Default/Sessions"), nil
-	case Vivaldi:
-		return filepath.Join(home, ".config/vivaldi/Default/Sessions"), nil
-	case Firefox, Safari:
+	case Safari:
 		return "", ErrBrowserNotAvailable
 	case Auto:
 		return "", nil
@@ -306,18 +305,15 @@ func getLinuxSessionPath(home string, browserType Type) (string, error) {
 }
 
 func getDarwinSessionPath(home string, browserType Type) (string, error) {
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(home, b.Darwin, "Sessions")
+	}
+	if _, ok := builtinGecko(browserType); ok {
+		return "", ErrBrowserNotAvailable
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(home, "Library/Application Support/Google/Chrome/Default/Sessions"), nil
-	case Chromium:
-		return filepath.Join(home, "Library/Application Support/Chromium/Default/Sessions"), nil
-	case Edge:
-		return filepath.Join(home, "Library/Application Support/Microsoft Edge/Default/Sessions"), nil
-	case Brave:
-		return filepath.Join(home, "Library/Application Support/BraveSoftware/Brave-Browser/Default/Sessions"), nil
-	case Vivaldi:
-		return filepath.Join(home, "Library/Application Support/Vivaldi/Default/Sessions"), nil
-	case Firefox, Safari:
+	case Safari:
 		return "", ErrBrowserNotAvailable
 	case Auto:
 		return "", nil
@@ -336,18 +332,15 @@ func getWindowsSessionPath(browserType Type) (string, error) {
 		appData = filepath.Join(home, "AppData/Local")
 	}
 
+	if b, ok := builtinChromium(browserType); ok {
+		return b.file(appData, b.Windows, "Sessions")
+	}
+	if _, ok := builtinGecko(browserType); ok {
+		return "", ErrBrowserNotAvailable
+	}
+
 	switch browserType {
-	case Chrome:
-		return filepath.Join(appData, `Google\Chrome\User Data\Default\Sessions`), nil
-	case Chromium:
-		return filepath.Join(appData, `Chromium\User Data\Default\Sessions`), nil
-	case Edge:
-		return filepath.Join(appData, `Microsoft\Edge\User Data\Default\Sessions`), nil
-	case Brave:
-		return filepath.Join(appData, `BraveSoftware\Brave-Browser\User Data\Default\Sessions`), nil
-	case Vivaldi:
-		return filepath.Join(appData, `Vivaldi\User Data\Default\Sessions`), nil
-	case Firefox, Safari:
+	case Safari:
 		return "", ErrBrowserNotAvailable
 	case Auto:
 		return "", nil
@@ -358,10 +351,27 @@ func getWindowsSessionPath(browserType Type) (string, error) {
 
 // IsChromiumBased returns true if the browser uses Chromium's SNSS session format
 func IsChromiumBased(browserType Type) bool {
-	switch browserType {
-	case Chrome, Chromium, Edge, Brave, Vivaldi:
-		return true
-	default:
-		return false
+	return EngineOf(browserType) == EngineChromium
+}
+
+// GetCloudTabsPath returns Safari's CloudTabs.db, which holds the open tabs
+// of the user's other iCloud devices (macOS only)
+func GetCloudTabsPath() (string, error) {
+	if runtime.GOOS != "darwin" {
+		return "", ErrBrowserNotAvailable
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	// Sandboxed Safari (macOS 10.14 and later) keeps it in its container
+	for _, p := range []string{
+		filepath.Join(home, "Library/Containers/com.apple.Safari/Data/Library/Safari/CloudTabs.db"),
+		filepath.Join(home, "Library/Safari/CloudTabs.db"),
+	} {
+		if fileExists(p) {
+			return p, nil
+		}
 	}
+	return "", ErrDatabaseNotFound
 }
@@ -28,6 +28,21 @@ func TestGetLinuxPath(t *testing.T) {
 			browser:  Firefox,
 			contains: ".mozilla/firefox",
 		},
+		{
+			name:     "Whale",
+			browser:  Whale,
+			contains: ".config/naver-whale/Default/History",
+		},
+		{
+			name:     "Pale Moon",
+			browser:  PaleMoon,
+			contains: ".moonchild productions/pale moon",
+		},
+		{
+			name:      "Samsung Internet not available",
+			browser:   SamsungInternet,
+			expectErr: true,
+		},
 		{
 			name:      "Safari not available",
 			browser:   Safari,
@@ -83,6 +98,11 @@ func TestGetDarwinPath(t *testing.T) {
 			contains:  "Library/Application Support/Microsoft Edge",
 			expectErr: false,
 		},
+		{
+			name:     "Arc",
+			browser:  Arc,
+			contains: "Library/Application Support/Arc/User Data/Default/History",
+		},
 	}
 
 	for _, tt := range tests {
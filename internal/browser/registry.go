@@ -0,0 +1,252 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Engine is the browser engine family, which decides how profile data is read
+type Engine string
+
+const (
+	EngineChromium Engine = "chromium"
+	EngineGecko    Engine = "gecko"
+	EngineWebKit   Engine = "webkit"
+	EngineText     Engine = "text"
+	EngineCustom   Engine = "custom"
+)
+
+// Definition describes a browser registered at runtime, typically a Chromium
+// fork or Firefox derivative that reuses a built-in engine handler
+type Definition struct {
+	// Type is the identifier used with --browser, e.g. "thorium"
+	Type Type `json:"type"`
+	// Name is the display name; defaults to Type
+	Name   string `json:"name,omitempty"`
+	Engine Engine `json:"engine"`
+	// Paths are candidate locations, tried in order; the first that exists is
+	// used. "~" and environment variables are expanded. What a path points at
+	// depends on the engine:
+	//   chromium  profile directory holding History, Bookmarks, and Sessions
+	//             (e.g. ~/.config/thorium/Default)
+	//   gecko     directory holding the profiles (e.g. ~/.librewolf)
+	//   webkit    directory holding History.db and Bookmarks.plist
+	//   text      history file with one URL per line (e.g. ~/.w3m/history)
+	//   custom    passed as-is to the handler registered for the type
+	Paths []string `json:"paths,omitempty"`
+	// History, Bookmarks, and Sessions point straight at one kind of data,
+	// for installs that do not keep it where the engine usually does. Each
+	// lists candidates per operating system and takes precedence over Paths.
+	History   OSPaths `json:"history,omitempty"`
+	Bookmarks OSPaths `json:"bookmarks,omitempty"`
+	Sessions  OSPaths `json:"sessions,omitempty"`
+}
+
+// OSPaths maps an operating system ("linux", "darwin" or "macos", "windows")
+// to candidate paths, tried in order
+type OSPaths map[string][]string
+
+// current returns the candidates for the running operating system
+func (p OSPaths) current() []string {
+	if paths, ok := p[runtime.GOOS]; ok {
+		return paths
+	}
+	if runtime.GOOS == "darwin" {
+		return p["macos"]
+	}
+	return nil
+}
+
+// validate rejects unknown operating systems, so a typo does not silently
+// leave a path unused
+func (p OSPaths) validate() error {
+	for goos := range p {
+		switch goos {
+		case "linux", "darwin", "macos", "windows":
+		default:
+			return fmt.Errorf("unknown operating system %q (use linux, darwin, macos, or windows)", goos)
+		}
+	}
+	return nil
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Definition
+)
+
+// builtinEngines maps the built-in browser types to their engines; the
+// others are added from chromiumBrowsers, geckoBrowsers, and textBrowsers
+var builtinEngines = map[Type]Engine{
+	Safari: EngineWebKit,
+}
+
+// Register adds a browser definition, replacing any earlier definition with
+// the same type. Built-in browsers cannot be replaced.
+func Register(def Definition) error {
+	if def.Type == "" || def.Type == Auto {
+		return fmt.Errorf("browser definition needs a type")
+	}
+	if _, ok := builtinEngines[def.Type]; ok {
+		return fmt.Errorf("browser %s is built in and cannot be redefined", def.Type)
+	}
+	switch def.Engine {
+	case EngineChromium, EngineGecko, EngineWebKit, EngineText, EngineCustom:
+	default:
+		return fmt.Errorf("browser %s: unknown engine %q (use chromium, gecko, webkit, text, or custom)", def.Type, def.Engine)
+	}
+	if len(def.Paths) == 0 && len(def.History) == 0 {
+		return fmt.Errorf("browser %s: paths or a history path is required", def.Type)
+	}
+	for _, paths := range []OSPaths{def.History, def.Bookmarks, def.Sessions} {
+		if err := paths.validate(); err != nil {
+			return fmt.Errorf("browser %s: %v", def.Type, err)
+		}
+	}
+	if def.Name == "" {
+		def.Name = string(def.Type)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Type == def.Type {
+			registry[i] = def
+			return nil
+		}
+	}
+	registry = append(registry, def)
+	return nil
+}
+
+// Lookup returns the registered definition for a browser type
+func Lookup(browserType Type) (Definition, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, def := range registry {
+		if def.Type == browserType {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Registered returns the registered definitions in registration order
+func Registered() []Definition {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]Definition(nil), registry...)
+}
+
+// EngineOf returns the engine of a built-in or registered browser type, or
+// an empty Engine for unknown types
+func EngineOf(browserType Type) Engine {
+	if engine, ok := builtinEngines[browserType]; ok {
+		return engine
+	}
+	if def, ok := Lookup(browserType); ok {
+		return def.Engine
+	}
+	return ""
+}
+
+// resolve returns the first existing candidate path
+func (d Definition) resolve() (string, error) {
+	return firstExisting(d.Paths)
+}
+
+func firstExisting(paths []string) (string, error) {
+	for _, p := range paths {
+		p = expandPath(p)
+		if fileExists(p) {
+			return p, nil
+		}
+	}
+	return "", ErrDatabaseNotFound
+}
+
+// historyPath returns what GetDatabasePath returns for built-in browsers of
+// the same engine. A gecko history override names places.sqlite itself.
+func (d Definition) historyPath() (string, error) {
+	if paths := d.History.current(); len(paths) > 0 {
+		return firstExisting(paths)
+	}
+	dir, err := d.resolve()
+	if err != nil {
+		return "", err
+	}
+	switch d.Engine {
+	case EngineChromium:
+		return filepath.Join(dir, "History"), nil
+	case EngineWebKit:
+		return filepath.Join(dir, "History.db"), nil
+	default:
+		return dir, nil
+	}
+}
+
+func (d Definition) bookmarkPath() (string, error) {
+	if paths := d.Bookmarks.current(); len(paths) > 0 {
+		return firstExisting(paths)
+	}
+	dir, err := d.resolve()
+	if err != nil {
+		return "", err
+	}
+	switch d.Engine {
+	case EngineChromium:
+		return filepath.Join(dir, "Bookmarks"), nil
+	case EngineWebKit:
+		return filepath.Join(dir, "Bookmarks.plist"), nil
+	case EngineText:
+		return "", ErrBrowserNotAvailable
+	default:
+		return dir, nil
+	}
+}
+
+func (d Definition) sessionPath() (string, error) {
+	if d.Engine != EngineChromium {
+		return "", ErrBrowserNotAvailable
+	}
+	if paths := d.Sessions.current(); len(paths) > 0 {
+		return firstExisting(paths)
+	}
+	dir, err := d.resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "Sessions"), nil
+}
+
+// expandPath expands a leading "~" and environment variables ($VAR, ${VAR},
+// and Windows-style %VAR%)
+func expandPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = home + p[1:]
+		}
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(p, "%")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(p[start+1:], "%")
+		if end <= 0 {
+			break
+		}
+		b.WriteString(p[:start])
+		b.WriteString(os.Getenv(p[start+1 : start+1+end]))
+		p = p[start+2+end:]
+	}
+	b.WriteString(p)
+	p = b.String()
+
+	return filepath.Clean(os.ExpandEnv(p))
+}
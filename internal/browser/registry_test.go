@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRegisterChromiumFork(t *testing.T) {
+	t.Cleanup(func() { registry = nil })
+
+	profile := filepath.Join(t.TempDir(), "Thorium", "Default")
+	if err := os.MkdirAll(profile, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(profile, "History"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WEB_RECAP_TEST_ROOT", filepath.Dir(filepath.Dir(profile)))
+
+	err := Register(Definition{
+		Type:   "thorium",
+		Name:   "Thorium",
+		Engine: EngineChromium,
+		Paths:  []string{"/nonexistent/thorium", "$WEB_RECAP_TEST_ROOT/Thorium/Default"},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if !IsChromiumBased("thorium") {
+		t.Error("thorium should be Chromium-based")
+	}
+	if got, err := GetDatabasePath("thorium"); err != nil || got != filepath.Join(profile, "History") {
+		t.Errorf("GetDatabasePath = %q, %v", got, err)
+	}
+	if got, err := GetSessionPath("thorium"); err != nil || got != filepath.Join(profile, "Sessions") {
+		t.Errorf("GetSessionPath = %q, %v", got, err)
+	}
+
+	found := false
+	for _, b := range NewDetector().Detect() {
+		if b.Type == "thorium" {
+			found = b.Name == "Thorium"
+		}
+	}
+	if !found {
+		t.Error("registered browser not detected")
+	}
+}
+
+func TestRegisterPathOverrides(t *testing.T) {
+	t.Cleanup(func() { registry = nil })
+
+	dir := t.TempDir()
+	files := map[string]string{}
+	for _, name := range []string{"hist.db", "marks.json", "places.sqlite"} {
+		files[name] = filepath.Join(dir, name)
+		if err := os.WriteFile(files[name], nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sessions := filepath.Join(dir, "sessions")
+	if err := os.Mkdir(sessions, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	defs := []Definition{
+		{
+			Type:      "portable",
+			Engine:    EngineChromium,
+			History:   OSPaths{runtime.GOOS: {"/nonexistent/History", files["hist.db"]}},
+			Bookmarks: OSPaths{runtime.GOOS: {files["marks.json"]}},
+			Sessions:  OSPaths{runtime.GOOS: {sessions}},
+		},
+		{Type: "waterfox", Engine: EngineGecko, History: OSPaths{runtime.GOOS: {files["places.sqlite"]}}},
+	}
+	for _, def := range defs {
+		if err := Register(def); err != nil {
+			t.Fatalf("Register %s: %v", def.Type, err)
+		}
+	}
+
+	if got, err := GetDatabasePath("portable"); err != nil || got != files["hist.db"] {
+		t.Errorf("GetDatabasePath = %q, %v", got, err)
+	}
+	if got, err := GetBookmarkPath("portable"); err != nil || got != files["marks.json"] {
+		t.Errorf("GetBookmarkPath = %q, %v", got, err)
+	}
+	if got, err := GetSessionPath("portable"); err != nil || got != sessions {
+		t.Errorf("GetSessionPath = %q, %v", got, err)
+	}
+
+	b, err := NewDetector().GetBrowser("waterfox")
+	if err != nil || b.Path != files["places.sqlite"] {
+		t.Errorf("GetBrowser(waterfox) = %+v, %v", b, err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	t.Cleanup(func() { registry = nil })
+
+	tests := []struct {
+		name string
+		def  Definition
+	}{
+		{"builtin", Definition{Type: Chrome, Engine: EngineChromium, Paths: []string{"/x"}}},
+		{"auto", Definition{Type: Auto, Engine: EngineChromium, Paths: []string{"/x"}}},
+		{"engine", Definition{Type: "x", Engine: "trident", Paths: []string{"/x"}}},
+		{"paths", Definition{Type: "x", Engine: EngineGecko}},
+		{"os", Definition{Type: "x", Engine: EngineGecko, History: OSPaths{"beos": {"/x"}}}},
+	}
+	for _, tt := range tests {
+		if err := Register(tt.def); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("WEB_RECAP_TEST_DIR", "/data")
+	for in, want := range map[string]string{
+		"$WEB_RECAP_TEST_DIR/a":   "/data/a",
+		"%WEB_RECAP_TEST_DIR%/b":  "/data/b",
+		"/plain/%not closed":      "/plain/%not closed",
+		"${WEB_RECAP_TEST_DIR}/c": "/data/c",
+	} {
+		if got := expandPath(in); got != filepath.Clean(want) {
+			t.Errorf("expandPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// textBrowser is a built-in terminal browser, whose history is a plain text
+// file below the home directory
+type textBrowser struct {
+	Type    Type
+	Name    string
+	History string
+}
+
+// textBrowsers are the built-in terminal browsers in detection order. Lynx
+// only keeps history across runs in its session file, which it writes when
+// AUTO_SESSION or -sessionout is set.
+var textBrowsers = []textBrowser{
+	{Lynx, "Lynx", ".lynx_session"},
+	{W3m, "w3m", ".w3m/history"},
+}
+
+func init() {
+	for _, b := range textBrowsers {
+		builtinEngines[b.Type] = EngineText
+	}
+}
+
+// builtinText returns the built-in terminal browser of type t
+func builtinText(t Type) (textBrowser, bool) {
+	for _, b := range textBrowsers {
+		if b.Type == t {
+			return b, true
+		}
+	}
+	return textBrowser{}, false
+}
+
+// historyPath returns the browser's history file
+func (b textBrowser) historyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, filepath.FromSlash(b.History)), nil
+}
@@ -11,6 +11,20 @@ const (
 	Brave    Type = "brave"
 	Vivaldi  Type = "vivaldi"
 	Auto     Type = "auto"
+
+	// Chromium forks read by the same engine handlers as Chrome
+	Whale           Type = "whale"
+	SamsungInternet Type = "samsung"
+	Yandex          Type = "yandex"
+	Arc             Type = "arc"
+
+	// Firefox derivatives read by the same engine handlers as Firefox
+	PaleMoon  Type = "palemoon"
+	SeaMonkey Type = "seamonkey"
+
+	// Terminal browsers, read from their text history files
+	Lynx Type = "lynx"
+	W3m  Type = "w3m"
 )
 
 // Browser represents a detected browser with its database path
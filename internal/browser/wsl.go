@@ -0,0 +1,104 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// WindowsUsersDir is where WSL mounts the Windows users' home directories
+const WindowsUsersDir = "/mnt/c/Users"
+
+// windowsHostBrowser is a Windows browser offered inside WSL, with its
+// profile directory relative to the user's home
+type windowsHostBrowser struct {
+	Type   Type
+	Name   string
+	Engine Engine
+	Path   string
+}
+
+// windowsHostBrowsers are the built-in Chromium and Gecko browsers made for
+// Windows
+var windowsHostBrowsers = func() []windowsHostBrowser {
+	var browsers []windowsHostBrowser
+	for _, b := range chromiumBrowsers {
+		if b.Windows != "" {
+			path := "AppData/Local/" + strings.ReplaceAll(b.Windows, `\`, "/")
+			browsers = append(browsers, windowsHostBrowser{b.Type, b.Name, EngineChromium, path})
+		}
+	}
+	for _, b := range geckoBrowsers {
+		if b.Windows != "" {
+			browsers = append(browsers, windowsHostBrowser{b.Type, b.Name, EngineGecko, b.Windows})
+		}
+	}
+	return browsers
+}()
+
+// IsWSL reports whether web-recap runs inside Windows Subsystem for Linux
+func IsWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// WindowsHostBrowsers returns definitions for the browsers installed for the
+// Windows users under usersDir. They are typed "<browser>:windows", or
+// "<browser>:windows:<user>" when several users have browser data.
+func WindowsHostBrowsers(usersDir string) []Definition {
+	entries, err := os.ReadDir(usersDir)
+	if err != nil {
+		return nil
+	}
+
+	found := make(map[string][]Definition)
+	var users []string
+	for _, entry := range entries {
+		switch entry.Name() {
+		case "Public", "Default", "Default User", "All Users":
+			continue
+		}
+		if !entry.IsDir() {
+			continue
+		}
+		home := filepath.Join(usersDir, entry.Name())
+		for _, b := range windowsHostBrowsers {
+			dir := filepath.Join(home, filepath.FromSlash(b.Path))
+			if !fileExists(dir) {
+				continue
+			}
+			found[entry.Name()] = append(found[entry.Name()], Definition{
+				Type:   b.Type,
+				Name:   fmt.Sprintf("%s (Windows, %s)", b.Name, entry.Name()),
+				Engine: b.Engine,
+				Paths:  []string{dir},
+			})
+		}
+		if len(found[entry.Name()]) > 0 {
+			users = append(users, entry.Name())
+		}
+	}
+	sort.Strings(users)
+
+	var defs []Definition
+	for _, user := range users {
+		for _, def := range found[user] {
+			suffix := ":windows"
+			if len(users) > 1 {
+				suffix += ":" + user
+			}
+			def.Type += Type(suffix)
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
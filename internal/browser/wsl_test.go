@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWindowsHostBrowsers(t *testing.T) {
+	users := t.TempDir()
+	for _, dir := range []string{
+		"alice/AppData/Local/Google/Chrome/User Data/Default",
+		"alice/AppData/Roaming/Mozilla/Firefox/Profiles",
+		"bob/AppData/Local/Microsoft/Edge/User Data/Default",
+		"Public/AppData/Local/Google/Chrome/User Data/Default",
+		"carol/Documents",
+	} {
+		if err := os.MkdirAll(filepath.Join(users, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[Type]string{
+		"chrome:windows:alice":  "Google Chrome (Windows, alice)",
+		"firefox:windows:alice": "Firefox (Windows, alice)",
+		"edge:windows:bob":      "Microsoft Edge (Windows, bob)",
+	}
+	defs := WindowsHostBrowsers(users)
+	if len(defs) != len(want) {
+		t.Fatalf("got %d browsers, want %d: %+v", len(defs), len(want), defs)
+	}
+	for _, def := range defs {
+		if want[def.Type] != def.Name {
+			t.Errorf("%s named %q, want %q", def.Type, def.Name, want[def.Type])
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(users, "bob")); err != nil {
+		t.Fatal(err)
+	}
+	for _, def := range WindowsHostBrowsers(users) {
+		if def.Type != "chrome:windows" && def.Type != "firefox:windows" {
+			t.Errorf("single user: unexpected type %s", def.Type)
+		}
+	}
+}
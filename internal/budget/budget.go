@@ -0,0 +1,263 @@
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// EstimateTokens approximates how many tokens an LLM tokenizer (tiktoken's
+// cl100k-style BPE) would produce for s. Words are counted as one token per
+// ~4 characters, punctuation as one token each, and runs of whitespace are
+// folded into the following token. It intentionally errs on the high side.
+func EstimateTokens(s string) int {
+	tokens := 0
+	wordLen := 0
+
+	flushWord := func() {
+		if wordLen > 0 {
+			tokens += (wordLen + 3) / 4
+			wordLen = 0
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if r >= utf8.RuneSelf {
+				// Non-ASCII text tokenizes much less efficiently
+				flushWord()
+				tokens++
+				continue
+			}
+			wordLen++
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+
+	return tokens
+}
+
+// EstimateReportTokens estimates the token count of a report as it would be
+// written by the JSON formatter
+func EstimateReportTokens(report models.HistoryReport) int {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return 0
+	}
+	return EstimateTokens(string(data))
+}
+
+// maxTitleRunes is the title length kept once low-value detail is dropped
+const maxTitleRunes = 80
+
+// FitHistoryReport reduces report until its JSON form is estimated to fit in
+// maxTokens. Reductions are applied in order of how much information they
+// lose, stopping as soon as the report fits:
+//
+//  1. dedupe: keep only the most recent visit of each URL
+//  2. trim: drop query strings/fragments from URLs and shorten long titles
+//  3. collapse: fold the entries of the most repetitive domains into
+//     per-domain summaries, keeping progressively fewer entries per domain
+//  4. truncate: drop the oldest remaining entries
+//
+// The returned report carries a token_budget section describing the steps
+// taken. A budget too small for the report without any entries is an error,
+// as it is for SplitHistoryReport.
+func FitHistoryReport(report models.HistoryReport, maxTokens int) (models.HistoryReport, error) {
+	if maxTokens <= 0 {
+		return report, fmt.Errorf("max tokens must be positive")
+	}
+
+	report.Budget = &models.TokenBudget{
+		MaxTokens:       maxTokens,
+		OriginalEntries: len(report.Entries),
+	}
+
+	empty := report
+	empty.Entries = []models.HistoryEntry{}
+	empty.TotalEntries = 0
+	empty.CollapsedDomains = nil
+	if overhead := EstimateReportTokens(empty); overhead > maxTokens {
+		return report, fmt.Errorf("max tokens of %d is smaller than the report header (~%d tokens)", maxTokens, overhead)
+	}
+
+	// Entries stays non-nil so an emptied report still writes "entries": []
+	report.Entries = append([]models.HistoryEntry{}, report.Entries...)
+
+	fits := func() bool {
+		report.TotalEntries = len(report.Entries)
+		report.Budget.EstimatedTokens = EstimateReportTokens(report)
+		return report.Budget.EstimatedTokens <= maxTokens
+	}
+
+	if fits() {
+		return report, nil
+	}
+
+	report.Entries = dedupeByURL(report.Entries)
+	report.Budget.Steps = append(report.Budget.Steps, "dedupe")
+	if fits() {
+		return report, nil
+	}
+
+	trimEntries(report.Entries)
+	report.Budget.Steps = append(report.Budget.Steps, "trim")
+	if fits() {
+		return report, nil
+	}
+
+	all := report.Entries
+	collapsed := false
+	for _, keep := range []int{10, 5, 3, 1, 0} {
+		entries, summaries := collapseDomains(all, keep)
+		if len(summaries) == 0 {
+			continue
+		}
+		report.Entries, report.CollapsedDomains = entries, summaries
+		if !collapsed {
+			report.Budget.Steps = append(report.Budget.Steps, "collapse")
+			collapsed = true
+		}
+		if fits() {
+			return report, nil
+		}
+	}
+
+	// Entries are newest first; binary search for the largest prefix that fits
+	remaining := report.Entries
+	lo, hi := 0, len(remaining)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		report.Entries = remaining[:mid]
+		if fits() {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	report.Entries = remaining[:lo]
+	report.Budget.Steps = append(report.Budget.Steps, "truncate")
+	if fits() || len(report.CollapsedDomains) == 0 {
+		return report, nil
+	}
+
+	// Even an empty entry list is too big; shorten the domain summaries too
+	summaries := report.CollapsedDomains
+	lo, hi = 0, len(summaries)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		report.CollapsedDomains = summaries[:mid]
+		if fits() {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	report.CollapsedDomains = summaries[:lo]
+	fits()
+
+	return report, nil
+}
+
+// dedupeByURL keeps the most recent entry per URL, preserving order
+func dedupeByURL(entries []models.HistoryEntry) []models.HistoryEntry {
+	seen := make(map[string]int, len(entries))
+	result := []models.HistoryEntry{}
+
+	for _, e := range entries {
+		if i, ok := seen[e.URL]; ok {
+			if e.Timestamp.After(result[i].Timestamp) {
+				result[i].Timestamp = e.Timestamp
+			}
+			if e.VisitCount > result[i].VisitCount {
+				result[i].VisitCount = e.VisitCount
+			}
+			if result[i].Title == "" {
+				result[i].Title = e.Title
+			}
+			continue
+		}
+		seen[e.URL] = len(result)
+		result = append(result, e)
+	}
+
+	return result
+}
+
+// trimEntries drops query strings and fragments and shortens titles in place
+func trimEntries(entries []models.HistoryEntry) {
+	for i := range entries {
+		if u, err := url.Parse(entries[i].URL); err == nil && u.Host != "" {
+			u.RawQuery = ""
+			u.Fragment = ""
+			u.RawFragment = ""
+			entries[i].URL = u.String()
+		}
+
+		if entries[i].Title == entries[i].URL {
+			entries[i].Title = ""
+		}
+		if utf8.RuneCountInString(entries[i].Title) > maxTitleRunes {
+			runes := []rune(entries[i].Title)
+			entries[i].Title = string(runes[:maxTitleRunes-1]) + "…"
+		}
+	}
+}
+
+// collapseDomains keeps at most keep entries for each domain that has more
+// than keep entries and summarizes the rest
+func collapseDomains(entries []models.HistoryEntry, keep int) ([]models.HistoryEntry, []models.CollapsedDomain) {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		counts[e.Domain]++
+	}
+
+	summaries := make(map[string]*models.CollapsedDomain)
+	kept := make(map[string]int)
+	result := []models.HistoryEntry{}
+
+	for _, e := range entries {
+		if counts[e.Domain] <= keep || kept[e.Domain] < keep {
+			kept[e.Domain]++
+			result = append(result, e)
+			continue
+		}
+
+		s, ok := summaries[e.Domain]
+		if !ok {
+			s = &models.CollapsedDomain{Domain: e.Domain, FirstVisit: e.Timestamp, LastVisit: e.Timestamp}
+			summaries[e.Domain] = s
+		}
+		s.Visits++
+		if e.Timestamp.Before(s.FirstVisit) {
+			s.FirstVisit = e.Timestamp
+		}
+		if e.Timestamp.After(s.LastVisit) {
+			s.LastVisit = e.Timestamp
+		}
+	}
+
+	var collapsed []models.CollapsedDomain
+	for _, s := range summaries {
+		collapsed = append(collapsed, *s)
+	}
+	sort.Slice(collapsed, func(i, j int) bool {
+		if collapsed[i].Visits != collapsed[j].Visits {
+			return collapsed[i].Visits > collapsed[j].Visits
+		}
+		return collapsed[i].Domain < collapsed[j].Domain
+	})
+
+	return result, collapsed
+}
@@ -0,0 +1,169 @@
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{in: "", want: 0},
+		{in: "hello world", want: 4},
+		{in: `{"a": 1}`, want: 7},
+		{in: "https://example.com", want: 9},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.in); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFitHistoryReport(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var entries []models.HistoryEntry
+	for i := 0; i < 200; i++ {
+		domain := "github.com"
+		if i%4 == 0 {
+			domain = fmt.Sprintf("site%d.example", i)
+		}
+		entries = append(entries, models.HistoryEntry{
+			Timestamp:  base.Add(-time.Duration(i) * time.Minute),
+			URL:        fmt.Sprintf("https://%s/page/%d?utm_source=newsletter&ref=%d", domain, i%50, i),
+			Title:      fmt.Sprintf("A fairly long page title number %d that goes on for a while", i),
+			VisitCount: 1,
+			Domain:     domain,
+			Browser:    "chrome",
+		})
+	}
+
+	report := models.HistoryReport{Browser: "chrome", Timezone: "UTC", TotalEntries: len(entries), Entries: entries}
+
+	for _, limit := range []int{100000, 4000, 1500, 300} {
+		fitted, err := FitHistoryReport(report, limit)
+		if err != nil {
+			t.Fatalf("FitHistoryReport(%d): %v", limit, err)
+		}
+		if got := EstimateReportTokens(fitted); got > limit {
+			t.Errorf("limit %d: estimated %d tokens, steps %v", limit, got, fitted.Budget.Steps)
+		}
+		if fitted.TotalEntries != len(fitted.Entries) {
+			t.Errorf("limit %d: total_entries %d != len(entries) %d", limit, fitted.TotalEntries, len(fitted.Entries))
+		}
+		if fitted.Budget.OriginalEntries != len(entries) {
+			t.Errorf("limit %d: original_entries = %d, want %d", limit, fitted.Budget.OriginalEntries, len(entries))
+		}
+	}
+
+	if len(report.Entries) != 200 || report.Budget != nil {
+		t.Fatalf("input report was modified")
+	}
+}
+
+func TestFitHistoryReportTinyBudget(t *testing.T) {
+	report := models.HistoryReport{Browser: "chrome", Timezone: "UTC"}
+	if _, err := FitHistoryReport(report, 5); err == nil {
+		t.Error("expected an error for a budget smaller than the header")
+	}
+
+	// Every entry is collapsed or truncated, but entries is still a list
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		report.Entries = append(report.Entries, models.HistoryEntry{Timestamp: base.Add(-time.Duration(i) * time.Minute), URL: fmt.Sprintf("https://github.com/%d", i), Domain: "github.com"})
+	}
+	header := report
+	header.Entries = nil
+	header.Budget = &models.TokenBudget{MaxTokens: 100, OriginalEntries: 20}
+	limit := EstimateReportTokens(header) + 40
+	for _, r := range []models.HistoryReport{report, {Browser: "chrome", Timezone: "UTC"}} {
+		fitted, err := FitHistoryReport(r, limit)
+		if err != nil {
+			t.Fatalf("FitHistoryReport(%d): %v", limit, err)
+		}
+		data, _ := json.Marshal(fitted)
+		if fitted.Entries == nil || !strings.Contains(string(data), `"entries":[`) {
+			t.Errorf("entries should be an empty list, not null: %s", data)
+		}
+	}
+}
+
+func TestSplitHistoryReport(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var entries []models.HistoryEntry
+	for i := 0; i < 100; i++ {
+		entries = append(entries, models.HistoryEntry{
+			Timestamp:  base.Add(-time.Duration(i) * time.Minute),
+			URL:        fmt.Sprintf("https://example.com/page/%d", i),
+			Title:      fmt.Sprintf("Page %d", i),
+			VisitCount: 1,
+			Domain:     "example.com",
+			Browser:    "chrome",
+		})
+	}
+	report := models.HistoryReport{Browser: "chrome", Timezone: "UTC", TotalEntries: len(entries), Entries: entries}
+
+	parts, err := SplitHistoryReport(report, 1000)
+	if err != nil {
+		t.Fatalf("SplitHistoryReport: %v", err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want several", len(parts))
+	}
+
+	next := 0
+	for i, part := range parts {
+		if got := EstimateReportTokens(part); got > 1000 {
+			t.Errorf("part %d: estimated %d tokens", i+1, got)
+		}
+		if part.Part == nil || part.Part.Index != i+1 || part.Part.Total != len(parts) {
+			t.Errorf("part %d: part = %+v", i+1, part.Part)
+		}
+		for _, e := range part.Entries {
+			if e.URL != entries[next].URL {
+				t.Fatalf("part %d: got %s, want %s", i+1, e.URL, entries[next].URL)
+			}
+			next++
+		}
+	}
+	if next != len(entries) {
+		t.Errorf("parts hold %d entries, want %d", next, len(entries))
+	}
+
+	if _, err := SplitHistoryReport(report, 10); err == nil {
+		t.Error("expected error for a budget smaller than the report header")
+	}
+}
+
+func TestSplitHistoryReportByDay(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	entries := []models.HistoryEntry{
+		{Timestamp: time.Date(2026, 3, 3, 2, 0, 0, 0, time.UTC), URL: "https://a.example/3"}, // Mar 2 in EST
+		{Timestamp: time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC), URL: "https://a.example/2"},
+		{Timestamp: time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC), URL: "https://a.example/1"},
+	}
+	report := models.HistoryReport{Entries: entries, TotalEntries: len(entries)}
+
+	parts := SplitHistoryReportByDay(report, loc)
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	if parts[0].TotalEntries != 1 || parts[1].TotalEntries != 2 {
+		t.Errorf("entries per part = %d, %d; want 1, 2", parts[0].TotalEntries, parts[1].TotalEntries)
+	}
+	if want := time.Date(2026, 3, 2, 0, 0, 0, 0, loc); !parts[1].StartDate.Equal(want) || !parts[1].EndDate.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("part 2 range = %v - %v", parts[1].StartDate, parts[1].EndDate)
+	}
+
+	if empty := SplitHistoryReportByDay(models.HistoryReport{}, loc); len(empty) != 1 || empty[0].Entries == nil {
+		t.Errorf("empty report should produce one empty part, got %+v", empty)
+	}
+}
@@ -0,0 +1,105 @@
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// SplitHistoryReport splits report into consecutive parts whose JSON form is
+// each estimated to fit in maxTokens. Entries keep their original order; an
+// entry too large to share a part is written on its own.
+func SplitHistoryReport(report models.HistoryReport, maxTokens int) ([]models.HistoryReport, error) {
+	if maxTokens <= 0 {
+		return nil, fmt.Errorf("split size must be positive")
+	}
+
+	empty := report
+	empty.Entries = []models.HistoryEntry{}
+	empty.TotalEntries = 0
+	empty.Part = &models.ReportPart{Index: 1, Total: 1}
+	overhead := EstimateReportTokens(empty)
+	if overhead >= maxTokens {
+		return nil, fmt.Errorf("split size of %d tokens is smaller than the report header (~%d tokens)", maxTokens, overhead)
+	}
+
+	var chunks [][]models.HistoryEntry
+	var current []models.HistoryEntry
+	used := overhead
+	for _, e := range report.Entries {
+		cost := entryTokens(e)
+		if len(current) > 0 && used+cost > maxTokens {
+			chunks = append(chunks, current)
+			current = nil
+			used = overhead
+		}
+		current = append(current, e)
+		used += cost
+	}
+	if len(current) > 0 || len(chunks) == 0 {
+		chunks = append(chunks, current)
+	}
+
+	parts := make([]models.HistoryReport, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = reportPart(report, chunk, i, len(chunks))
+	}
+	return parts, nil
+}
+
+// SplitHistoryReportByDay splits report into one part per calendar day in loc,
+// earliest day first. Each part's start and end dates cover its day.
+func SplitHistoryReportByDay(report models.HistoryReport, loc *time.Location) []models.HistoryReport {
+	byDay := make(map[time.Time][]models.HistoryEntry)
+	for _, e := range report.Entries {
+		t := e.Timestamp.In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		byDay[day] = append(byDay[day], e)
+	}
+
+	days := make([]time.Time, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	if len(days) == 0 {
+		return []models.HistoryReport{reportPart(report, nil, 0, 1)}
+	}
+
+	parts := make([]models.HistoryReport, len(days))
+	for i, day := range days {
+		parts[i] = reportPart(report, byDay[day], i, len(days))
+		parts[i].StartDate = day.UTC()
+		parts[i].EndDate = day.AddDate(0, 0, 1).UTC()
+	}
+	return parts
+}
+
+func reportPart(report models.HistoryReport, entries []models.HistoryEntry, index, total int) models.HistoryReport {
+	if entries == nil {
+		entries = []models.HistoryEntry{}
+	}
+	part := report
+	part.Entries = entries
+	part.TotalEntries = len(entries)
+	if index > 0 {
+		// Domain summaries describe the whole report, so only the first part carries them
+		part.CollapsedDomains = nil
+	}
+	part.Part = &models.ReportPart{Index: index + 1, Total: total}
+	return part
+}
+
+// entryTokens estimates the tokens an entry adds to the indented report JSON
+func entryTokens(e models.HistoryEntry) int {
+	data, err := json.MarshalIndent(e, "    ", "  ")
+	if err != nil {
+		return 0
+	}
+	// Leading indentation and the separating comma
+	return EstimateTokens(string(data)) + 1
+}
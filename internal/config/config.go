@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/firefoxsync"
+	"github.com/rzolkos/web-recap/internal/llm"
+	"github.com/rzolkos/web-recap/internal/readlater"
+)
+
+// Config holds persistent user settings from the config file
+type Config struct {
+	LLM      llm.Config           `json:"llm"`
+	Browsers []browser.Definition `json:"browsers,omitempty"`
+	// SourceLabel is the default for --source-label, e.g. "laptop"
+	SourceLabel string `json:"source_label,omitempty"`
+	// ReadLater holds the credentials 'bookmarks push' uses
+	ReadLater readlater.Config `json:"read_later,omitempty"`
+	// Blocklist is the default for --blocklist
+	Blocklist string `json:"blocklist,omitempty"`
+	// FirefoxSync is the Mozilla account 'import firefox-sync' signs in to
+	FirefoxSync firefoxsync.Config `json:"firefox_sync,omitempty"`
+	// Offline turns on --offline for every run
+	Offline bool `json:"offline,omitempty"`
+	// Categories lists the sites in each category, e.g. {"social":
+	// ["reddit.com"]}; a name already built in replaces its list
+	Categories map[string][]string `json:"categories,omitempty"`
+	// Aliases roll related hosts up into one service in stats and domain
+	// groups, e.g. {"github": ["github.com", "gist.github.com"]}; a pattern
+	// "*.google.com" covers a domain and its subdomains
+	Aliases map[string][]string `json:"aliases,omitempty"`
+	// Budgets are the daily time limits 'stats --budgets' checks, by
+	// category, e.g. {"social": "60m"}
+	Budgets map[string]string `json:"budgets,omitempty"`
+}
+
+// DefaultPath returns the config file location, e.g.
+// ~/.config/web-recap/config.json on Linux
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web-recap", "config.json"), nil
+}
+
+// Load reads the config file at path, or the default location when path is
+// empty. A missing default config file is not an error.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		p, err := DefaultPath()
+		if err != nil {
+			return &Config{}, nil
+		}
+		path = p
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	return &config, nil
+}
+
+// RegisterBrowsers registers the browsers defined in the config file. Only
+// the chromium, gecko, and webkit engines can be used from config; custom
+// engines need handlers registered from Go code.
+func (c *Config) RegisterBrowsers() error {
+	for _, def := range c.Browsers {
+		if def.Engine == browser.EngineCustom {
+			return fmt.Errorf("browser %s: the custom engine cannot be used from the config file", def.Type)
+		}
+		if err := browser.Register(def); err != nil {
+			return fmt.Errorf("invalid browser in config file: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Package content fetches the pages behind history entries and extracts
+// their readable text and meta description, so summaries can work from what
+// a page says rather than only its title
+package content
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxBytes is the most of a response body read; the rest of a larger page is
+// ignored
+var MaxBytes int64 = 2 << 20
+
+// Page is what was extracted from one URL
+type Page struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	Text        string `json:"text,omitempty"`
+	// Words counts the words of the whole text, before MaxText cut it
+	Words     int       `json:"words,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Options controls fetching
+type Options struct {
+	// Concurrency is how many pages are fetched at once (default 4)
+	Concurrency int
+	// MaxText is the most characters of text kept per page (0: no limit)
+	MaxText int
+	// CacheDir keeps fetched pages for MaxAge; empty disables the cache
+	CacheDir string
+	MaxAge   time.Duration
+	// Timeout bounds each request (default 15s)
+	Timeout time.Duration
+	// UserAgent is sent with each request
+	UserAgent string
+}
+
+// DefaultCacheDir returns web-recap/pages in the user cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web-recap", "pages"), nil
+}
+
+// Fetch fetches each unique http(s) URL in urls, at most opts.Concurrency at
+// a time, and returns the pages by URL with the number that failed. Pages in
+// the cache are not fetched again. Fetching stops when ctx is done.
+func Fetch(ctx context.Context, urls []string, opts Options) (map[string]Page, int) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+
+	seen := make(map[string]bool)
+	var todo []string
+	for _, u := range urls {
+		if !seen[u] && (strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")) {
+			seen[u] = true
+			todo = append(todo, u)
+		}
+	}
+
+	var mu sync.Mutex
+	pages := make(map[string]Page, len(todo))
+	failed := 0
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(opts.Concurrency, len(todo)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				page, err := fetchCached(ctx, u, opts)
+				mu.Lock()
+				if err != nil {
+					failed++
+				} else {
+					pages[u] = page
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for _, u := range todo {
+		select {
+		case jobs <- u:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return pages, failed
+}
+
+// fetchCached returns the cached page for u if it is recent enough, or
+// fetches and caches it
+func fetchCached(ctx context.Context, u string, opts Options) (Page, error) {
+	var path string
+	if opts.CacheDir != "" {
+		sum := sha256.Sum256([]byte(u))
+		path = filepath.Join(opts.CacheDir, hex.EncodeToString(sum[:16])+".json")
+		if data, err := os.ReadFile(path); err == nil {
+			var page Page
+			if json.Unmarshal(data, &page) == nil && page.URL == u && time.Since(page.FetchedAt) < opts.MaxAge {
+				return page, nil
+			}
+		}
+	}
+
+	page, err := fetchPage(ctx, u, opts)
+	if err != nil {
+		return Page{}, err
+	}
+	if path != "" {
+		if err := os.MkdirAll(opts.CacheDir, 0o700); err == nil {
+			if data, err := json.Marshal(page); err == nil {
+				os.WriteFile(path, data, 0o600)
+			}
+		}
+	}
+	return page, nil
+}
+
+// fetchPage downloads u and extracts its description and text. Only HTML
+// and plain text pages are read.
+func fetchPage(ctx context.Context, u string, opts Options) (Page, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Page{}, err
+	}
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Page{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Page{}, fmt.Errorf("%s: %s", u, resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body := io.LimitReader(resp.Body, MaxBytes)
+	page := Page{URL: u, FetchedAt: time.Now().UTC()}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml", "":
+		page.Description, page.Text, err = Extract(body)
+		if err != nil {
+			return Page{}, err
+		}
+	case "text/plain":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Text = collapseSpace(string(data))
+	default:
+		return Page{}, fmt.Errorf("%s: unsupported content type %s", u, mediaType)
+	}
+	page.Words = len(strings.Fields(page.Text))
+	page.Text = truncate(page.Text, opts.MaxText)
+	return page, nil
+}
+
+// WordCount returns the number of words on the page, counted from its text
+// for a page cached before Words was recorded
+func (p Page) WordCount() int {
+	if p.Words > 0 {
+		return p.Words
+	}
+	return len(strings.Fields(p.Text))
+}
+
+// truncate cuts s to at most n characters, at a word boundary where one is
+// close
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	cut := string(r[:n])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)*4/5 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
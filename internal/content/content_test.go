@@ -0,0 +1,83 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExtract(t *testing.T) {
+	page := `<!DOCTYPE html><html><head>
+<title>Post</title>
+<meta property="og:description" content="  A post about
+  cancellation. ">
+<script>var x = "not text";</script>
+</head><body>
+<nav>Home · About</nav>
+<header>Site header</header>
+<article><h1>Cancel<b>lation</b></h1><p>Contexts carry   deadlines.</p><p>Use <code>select</code>.</p></article>
+<footer>Copyright</footer>
+</body></html>`
+
+	description, text, err := Extract(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if description != "A post about cancellation." {
+		t.Errorf("description = %q", description)
+	}
+	if want := "Cancellation Contexts carry deadlines. Use select."; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><meta name="description" content="About Go"></head><body><p>` + strings.Repeat("word ", 100) + `</p></body></html>`))
+		case "/notes.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("plain\n\nnotes"))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	urls := []string{
+		server.URL + "/page", server.URL + "/page", server.URL + "/notes.txt",
+		server.URL + "/image.png", server.URL + "/missing", "chrome://settings",
+	}
+	opts := Options{Concurrency: 2, MaxText: 20, CacheDir: t.TempDir(), MaxAge: time.Hour}
+	pages, failed := Fetch(context.Background(), urls, opts)
+	if failed != 2 {
+		t.Errorf("failed = %d, want 2", failed)
+	}
+	if hits.Load() != 4 {
+		t.Errorf("requests = %d, want 4", hits.Load())
+	}
+	page := pages[server.URL+"/page"]
+	if page.Description != "About Go" || page.Text != "word word word word…" {
+		t.Errorf("page = %+v", page)
+	}
+	if got := pages[server.URL+"/notes.txt"].Text; got != "plain notes" {
+		t.Errorf("text page = %q", got)
+	}
+
+	// A second run is served from the cache
+	pages, _ = Fetch(context.Background(), urls[:1], opts)
+	if hits.Load() != 4 || pages[server.URL+"/page"].Description != "About Go" {
+		t.Errorf("cached fetch made %d requests in total, page %+v", hits.Load(), pages[server.URL+"/page"])
+	}
+}
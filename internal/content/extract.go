@@ -0,0 +1,116 @@
+package content
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skipped are elements whose text is never part of the readable content
+var skipped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Nav: true, atom.Header: true, atom.Footer: true,
+	atom.Aside: true, atom.Form: true, atom.Button: true, atom.Iframe: true,
+}
+
+// inline are elements that don't break words apart
+var inline = map[atom.Atom]bool{
+	atom.A: true, atom.Abbr: true, atom.B: true, atom.Cite: true, atom.Code: true,
+	atom.Em: true, atom.I: true, atom.Kbd: true, atom.Mark: true, atom.Q: true,
+	atom.S: true, atom.Small: true, atom.Span: true, atom.Strong: true,
+	atom.Sub: true, atom.Sup: true, atom.Time: true, atom.U: true, atom.Var: true,
+}
+
+// Extract returns the meta description of an HTML document and its readable
+// text: that of its <article> or <main> element when it has one, otherwise
+// of its body, without scripts, navigation, headers, and footers
+func Extract(r io.Reader) (description, text string, err error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	var body, main, article *html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Meta:
+				if description == "" && isDescription(n) {
+					description = collapseSpace(attr(n, "content"))
+				}
+			case atom.Body:
+				body = n
+			case atom.Main:
+				if main == nil {
+					main = n
+				}
+			case atom.Article:
+				if article == nil {
+					article = n
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	root := body
+	if main != nil {
+		root = main
+	}
+	if article != nil {
+		root = article
+	}
+	if root == nil {
+		return description, "", nil
+	}
+	var b strings.Builder
+	appendText(&b, root)
+	return description, collapseSpace(b.String()), nil
+}
+
+// isDescription reports whether a <meta> element holds the page description
+func isDescription(n *html.Node) bool {
+	name := strings.ToLower(attr(n, "name"))
+	property := strings.ToLower(attr(n, "property"))
+	return name == "description" || property == "og:description"
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// appendText writes the text under n, separating block elements with spaces
+// so words in adjacent blocks don't run together
+func appendText(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if skipped[n.DataAtom] {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		appendText(b, c)
+	}
+	if n.Type == html.ElementNode && !inline[n.DataAtom] {
+		b.WriteByte(' ')
+	}
+}
+
+// collapseSpace replaces each run of whitespace with one space
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
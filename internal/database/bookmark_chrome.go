@@ -1,6 +1,8 @@
 package database
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -25,98 +27,224 @@ func NewChromeBookmarkHandler(bookmarkPath, browserName string) *ChromeBookmarkH
 	}
 }
 
-// Chrome bookmark JSON structure
-type chromeBookmarkFile struct {
-	Checksum string              `json:"checksum"`
-	Roots    chromeBookmarkRoots `json:"roots"`
-	Version  int                 `json:"version"`
+// chromeRoots maps the root keys of the Bookmarks file to the folder names
+// reported for them
+var chromeRoots = map[string]string{
+	"bookmark_bar": "Bookmarks Bar",
+	"other":        "Other Bookmarks",
+	"synced":       "Synced Bookmarks",
 }
 
-type chromeBookmarkRoots struct {
-	BookmarkBar chromeBookmarkNode `json:"bookmark_bar"`
-	Other       chromeBookmarkNode `json:"other"`
-	Synced      chromeBookmarkNode `json:"synced"`
+// chromeFolder is the folder path a node is read under. Chrome writes keys
+// sorted, so a folder's children come before its name; paths are resolved
+// once the whole file has been read.
+type chromeFolder struct {
+	parent *chromeFolder
+	name   string
+	path   string // the root folder name, or the resolved path
+	done   bool
 }
 
-type chromeBookmarkNode struct {
-	DateAdded    string               `json:"date_added"`
-	DateModified string               `json:"date_modified,omitempty"`
-	GUID         string               `json:"guid"`
-	ID           string               `json:"id"`
-	Name         string               `json:"name"`
-	Type         string               `json:"type"` // "folder" or "url"
-	URL          string               `json:"url,omitempty"`
-	Children     []chromeBookmarkNode `json:"children,omitempty"`
-	MetaInfo     map[string]string    `json:"meta_info,omitempty"`
+func (f *chromeFolder) resolve() string {
+	if !f.done {
+		f.path = f.parent.resolve()
+		if f.name != "" {
+			if f.path != "" {
+				f.path += "/" + f.name
+			} else {
+				f.path = f.name
+			}
+		}
+		f.done = true
+	}
+	return f.path
+}
+
+// chromeBookmarkWalk reads the Bookmarks file one token at a time, so memory
+// grows with the entries found and the depth of the tree, not the file size
+type chromeBookmarkWalk struct {
+	ctx       context.Context
+	dec       *json.Decoder
+	h         *ChromeBookmarkHandler
+	bookmarks []models.BookmarkEntry
+	folders   []*chromeFolder // folder of each bookmark
 }
 
 // GetBookmarks retrieves all bookmarks from Chrome
-func (h *ChromeBookmarkHandler) GetBookmarks(startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
+func (h *ChromeBookmarkHandler) GetBookmarks(ctx context.Context, startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	h.startTime = startTime
 	h.endTime = endTime
 
-	data, err := os.ReadFile(h.bookmarkPath)
+	f, err := os.Open(h.bookmarkPath)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	var bookmarkFile chromeBookmarkFile
-	if err := json.Unmarshal(data, &bookmarkFile); err != nil {
-		return nil, err
+	w := &chromeBookmarkWalk{ctx: ctx, dec: json.NewDecoder(bufio.NewReader(f)), h: h}
+	err = w.object(func(key string) error {
+		if key != "roots" {
+			return w.skip()
+		}
+		return w.object(func(root string) error {
+			name, ok := chromeRoots[root]
+			if !ok {
+				return w.skip()
+			}
+			return w.node(&chromeFolder{path: name, done: true})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks file: %v", err)
 	}
 
-	var bookmarks []models.BookmarkEntry
-
-	// Extract from all root folders
-	bookmarks = append(bookmarks, h.extractFromNode(bookmarkFile.Roots.BookmarkBar, "Bookmarks Bar")...)
-	bookmarks = append(bookmarks, h.extractFromNode(bookmarkFile.Roots.Other, "Other Bookmarks")...)
-	bookmarks = append(bookmarks, h.extractFromNode(bookmarkFile.Roots.Synced, "Synced Bookmarks")...)
-
-	return bookmarks, nil
+	for i, folder := range w.folders {
+		w.bookmarks[i].Folder = folder.resolve()
+	}
+	return w.bookmarks, nil
 }
 
-// extractFromNode recursively extracts bookmarks from a node
-func (h *ChromeBookmarkHandler) extractFromNode(node chromeBookmarkNode, folderPath string) []models.BookmarkEntry {
-	var bookmarks []models.BookmarkEntry
+// node reads a bookmark or folder inside parent. Bookmarks in the date range
+// are added as soon as they are read; the children of a node that turns out
+// not to be a folder are dropped again.
+func (w *chromeBookmarkWalk) node(parent *chromeFolder) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	self := &chromeFolder{parent: parent}
+	first := len(w.bookmarks)
+	var nodeType, name, url, guid, dateAdded, dateModified string
 
-	if node.Type == "url" {
-		// This is a bookmark
-		dateAdded := h.convertChromeTimestamp(node.DateAdded)
-		dateModified := h.convertChromeTimestamp(node.DateModified)
+	err := w.object(func(key string) error {
+		switch key {
+		case "children":
+			return w.array(func() error { return w.node(self) })
+		case "type":
+			return w.str(&nodeType)
+		case "name":
+			return w.str(&name)
+		case "url":
+			return w.str(&url)
+		case "guid":
+			return w.str(&guid)
+		case "date_added":
+			return w.str(&dateAdded)
+		case "date_modified":
+			return w.str(&dateModified)
+		default:
+			return w.skip()
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	switch nodeType {
+	case "folder":
+		self.name = name
+	case "url":
+		w.bookmarks, w.folders = w.bookmarks[:first], w.folders[:first]
+		added := w.h.convertChromeTimestamp(dateAdded)
 
 		// Filter by date if time range is specified
-		if !h.startTime.IsZero() || !h.endTime.IsZero() {
-			if !WithinHalfOpenRange(dateAdded, h.startTime, h.endTime) {
-				return bookmarks
+		if !w.h.startTime.IsZero() || !w.h.endTime.IsZero() {
+			if !WithinHalfOpenRange(added, w.h.startTime, w.h.endTime) {
+				return nil
 			}
 		}
 
-		bookmarks = append(bookmarks, models.BookmarkEntry{
-			DateAdded:    dateAdded,
-			DateModified: dateModified,
-			URL:          node.URL,
-			Title:        node.Name,
-			Folder:       folderPath,
-			Domain:       ExtractDomain(node.URL),
-			Browser:      h.browserName,
+		w.bookmarks = append(w.bookmarks, models.BookmarkEntry{
+			ID:           guid,
+			DateAdded:    added,
+			DateModified: w.h.convertChromeTimestamp(dateModified),
+			URL:          url,
+			Title:        name,
+			Domain:       ExtractDomain(url),
+			Browser:      w.h.browserName,
 		})
-	} else if node.Type == "folder" {
-		// Recursively extract from children
-		newFolderPath := folderPath
-		if node.Name != "" {
-			if folderPath != "" {
-				newFolderPath = folderPath + "/" + node.Name
-			} else {
-				newFolderPath = node.Name
-			}
+		w.folders = append(w.folders, parent)
+	default:
+		w.bookmarks, w.folders = w.bookmarks[:first], w.folders[:first]
+	}
+	return nil
+}
+
+// object reads an object, calling fn to read the value of each key
+func (w *chromeBookmarkWalk) object(fn func(key string) error) error {
+	if err := w.delim('{'); err != nil {
+		return err
+	}
+	for w.dec.More() {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(tok.(string)); err != nil {
+			return err
 		}
+	}
+	return w.delim('}')
+}
 
-		for _, child := range node.Children {
-			bookmarks = append(bookmarks, h.extractFromNode(child, newFolderPath)...)
+// array reads an array, calling fn to read each element
+func (w *chromeBookmarkWalk) array(fn func() error) error {
+	if err := w.delim('['); err != nil {
+		return err
+	}
+	for w.dec.More() {
+		if err := fn(); err != nil {
+			return err
 		}
 	}
+	return w.delim(']')
+}
 
-	return bookmarks
+func (w *chromeBookmarkWalk) delim(want json.Delim) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %v, found %v", want, tok)
+	}
+	return nil
+}
+
+// str reads a string value
+func (w *chromeBookmarkWalk) str(s *string) error {
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	v, ok := tok.(string)
+	if !ok {
+		return fmt.Errorf("expected a string, found %v", tok)
+	}
+	*s = v
+	return nil
+}
+
+// skip reads past a value of any type
+func (w *chromeBookmarkWalk) skip() error {
+	depth := 0
+	for {
+		tok, err := w.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
 }
 
 // convertChromeTimestamp converts Chrome's timestamp to time.Time
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chromeTime formats t the way Chrome stores it in the Bookmarks file
+func chromeTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixMicro()+11644473600*1000000, 10)
+}
+
+func TestChromeBookmarkHandler(t *testing.T) {
+	day := func(d int) string { return chromeTime(time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC)) }
+
+	// Keys are sorted as Chrome writes them, so a folder's children come
+	// before its name
+	file := `{
+   "checksum": "abc",
+   "roots": {
+      "bookmark_bar": {"children": [
+         {"date_added": "` + day(1) + `", "guid": "g1", "id": "4", "meta_info": {"k": "v"}, "name": "Go", "type": "url", "url": "https://go.dev/"},
+         {"children": [
+            {"children": [
+               {"date_added": "` + day(2) + `", "id": "7", "name": "Spec", "type": "url", "url": "https://go.dev/ref/spec"}
+            ], "date_added": "1", "id": "6", "name": "Go", "type": "folder"},
+            {"date_added": "` + day(3) + `", "date_modified": "` + day(4) + `", "id": "8", "name": "", "type": "url", "url": "https://pkg.go.dev/"}
+         ], "date_added": "1", "id": "5", "name": "Reading", "type": "folder"}
+      ], "date_added": "1", "id": "1", "name": "Bookmarks bar", "type": "folder"},
+      "other": {"children": [
+         {"date_added": "` + day(5) + `", "id": "9", "name": "News", "type": "url", "url": "https://news.example/"},
+         {"children": [
+            {"date_added": "` + day(5) + `", "id": "11", "name": "Hidden", "type": "url", "url": "https://hidden.example/"}
+         ], "id": "10", "name": "Not a folder", "type": "unknown"}
+      ], "date_added": "1", "id": "2", "name": "", "type": "folder"},
+      "synced": {"children": [], "date_added": "1", "id": "3", "name": "Mobile bookmarks", "type": "folder"}
+   },
+   "sync_metadata": "c29tZQ==",
+   "version": 1
+}`
+	path := filepath.Join(t.TempDir(), "Bookmarks")
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []string
+	}{
+		{
+			name: "all",
+			want: []string{
+				"https://go.dev/ Go [Bookmarks Bar/Bookmarks bar]",
+				"https://go.dev/ref/spec Spec [Bookmarks Bar/Bookmarks bar/Reading/Go]",
+				"https://pkg.go.dev/  [Bookmarks Bar/Bookmarks bar/Reading]",
+				"https://news.example/ News [Other Bookmarks]",
+			},
+		},
+		{
+			name:  "date range",
+			start: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			want: []string{
+				"https://go.dev/ref/spec Spec [Bookmarks Bar/Bookmarks bar/Reading/Go]",
+				"https://pkg.go.dev/  [Bookmarks Bar/Bookmarks bar/Reading]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := NewChromeBookmarkHandler(path, "chrome").GetBookmarks(context.Background(), tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.URL+" "+e.Title+" ["+e.Folder+"]")
+				if e.Browser != "chrome" || e.Domain == "" || e.DateAdded.IsZero() {
+					t.Errorf("incomplete entry: %+v", e)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("entries:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+
+	// The modification date and GUID are read as well
+	entries, _ := NewChromeBookmarkHandler(path, "chrome").GetBookmarks(context.Background(), time.Time{}, time.Time{})
+	if want := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC); len(entries) < 3 || !entries[2].DateModified.Equal(want) {
+		t.Errorf("date modified not read: %+v", entries)
+	}
+	if len(entries) == 0 || entries[0].ID != "g1" {
+		t.Errorf("guid not read: %+v", entries)
+	}
+}
+
+func TestChromeBookmarkHandlerInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Bookmarks")
+	if err := os.WriteFile(path, []byte(`{"roots": {"bookmark_bar": {"children": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewChromeBookmarkHandler(path, "chrome").GetBookmarks(context.Background(), time.Time{}, time.Time{}); err == nil {
+		t.Error("truncated file parsed without error")
+	}
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewBookmark is a bookmark to add to a Chromium Bookmarks file
+type NewBookmark struct {
+	URL    string
+	Title  string
+	Folder string // slash-separated path under "Other bookmarks", e.g. "Reading/Go"
+}
+
+// AddChromeBookmarks adds bookmarks to a Chromium Bookmarks file, creating
+// folders under "Other bookmarks" as needed and skipping URLs already in the
+// target folder. It returns the number of bookmarks added.
+//
+// The browser must be closed: a running browser keeps its bookmarks in memory
+// and overwrites the file. The original file is kept as <path>.web-recap.bak,
+// and the checksum is dropped so the browser accepts the edited file.
+func AddChromeBookmarks(path string, bookmarks []NewBookmark) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read bookmarks file: %v", err)
+	}
+
+	// Decode generically so fields web-recap doesn't model survive the rewrite
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var file map[string]interface{}
+	if err := decoder.Decode(&file); err != nil {
+		return 0, fmt.Errorf("failed to parse bookmarks file: %v", err)
+	}
+	roots, _ := file["roots"].(map[string]interface{})
+	other, _ := roots["other"].(map[string]interface{})
+	if other == nil {
+		return 0, fmt.Errorf("bookmarks file has no \"other\" root")
+	}
+
+	nextID := maxBookmarkID(roots) + 1
+	now := strconv.FormatInt((time.Now().Unix()+11644473600)*1000000, 10)
+	newNode := func(kind, name string) map[string]interface{} {
+		node := map[string]interface{}{
+			"date_added": now,
+			"guid":       newGUID(),
+			"id":         strconv.FormatInt(nextID, 10),
+			"name":       name,
+			"type":       kind,
+		}
+		nextID++
+		return node
+	}
+
+	added := 0
+	for _, b := range bookmarks {
+		folder := other
+		for _, name := range strings.Split(strings.Trim(b.Folder, "/"), "/") {
+			if name == "" {
+				continue
+			}
+			child := findFolder(folder, name)
+			if child == nil {
+				child = newNode("folder", name)
+				child["children"] = []interface{}{}
+				child["date_modified"] = now
+				appendChild(folder, child)
+			}
+			folder = child
+		}
+
+		if folderHasURL(folder, b.URL) {
+			continue
+		}
+		title := b.Title
+		if title == "" {
+			title = b.URL
+		}
+		node := newNode("url", title)
+		node["url"] = b.URL
+		appendChild(folder, node)
+		folder["date_modified"] = now
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	delete(file, "checksum")
+
+	out, err := json.MarshalIndent(file, "", "   ")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.WriteFile(path+".web-recap.bak", data, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to back up bookmarks file: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".web-recap-bookmarks-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to write bookmarks file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("failed to write bookmarks file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write bookmarks file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to write bookmarks file: %v", err)
+	}
+
+	return added, nil
+}
+
+func children(node map[string]interface{}) []interface{} {
+	c, _ := node["children"].([]interface{})
+	return c
+}
+
+func appendChild(parent, child map[string]interface{}) {
+	parent["children"] = append(children(parent), child)
+}
+
+func findFolder(parent map[string]interface{}, name string) map[string]interface{} {
+	for _, c := range children(parent) {
+		node, _ := c.(map[string]interface{})
+		if node != nil && node["type"] == "folder" && node["name"] == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func folderHasURL(folder map[string]interface{}, url string) bool {
+	for _, c := range children(folder) {
+		node, _ := c.(map[string]interface{})
+		if node != nil && node["type"] == "url" && node["url"] == url {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBookmarkID returns the largest node id in the tree
+func maxBookmarkID(v interface{}) int64 {
+	var max int64
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if id, ok := node["id"].(string); ok {
+			if n, err := strconv.ParseInt(id, 10, 64); err == nil && n > max {
+				max = n
+			}
+		}
+		for _, child := range node {
+			if n := maxBookmarkID(child); n > max {
+				max = n
+			}
+		}
+	case []interface{}:
+		for _, child := range node {
+			if n := maxBookmarkID(child); n > max {
+				max = n
+			}
+		}
+	}
+	return max
+}
+
+// newGUID returns a random version 4 UUID
+func newGUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddChromeBookmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Bookmarks")
+	original := `{
+   "checksum": "abc",
+   "roots": {
+      "bookmark_bar": {"children": [], "date_added": "1", "guid": "g1", "id": "1", "name": "Bookmarks bar", "type": "folder"},
+      "other": {"children": [
+         {"children": [{"date_added": "1", "guid": "g4", "id": "7", "name": "Old", "type": "url", "url": "https://old.example/"}],
+          "date_added": "1", "guid": "g3", "id": "5", "name": "Reading", "type": "folder"}
+      ], "date_added": "1", "guid": "g2", "id": "2", "name": "Other bookmarks", "type": "folder"},
+      "synced": {"children": [], "date_added": "1", "guid": "g5", "id": "3", "name": "Mobile bookmarks", "type": "folder"}
+   },
+   "sync_metadata": "keep-me",
+   "version": 1
+}`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := AddChromeBookmarks(path, []NewBookmark{
+		{URL: "https://old.example/", Title: "Old", Folder: "Reading"},
+		{URL: "https://go.dev/", Title: "Go", Folder: "Reading/Go"},
+		{URL: "https://news.example/", Folder: "Reading"},
+	})
+	if err != nil {
+		t.Fatalf("AddChromeBookmarks: %v", err)
+	}
+	if added != 2 {
+		t.Errorf("added = %d, want 2", added)
+	}
+
+	backup, err := os.ReadFile(path + ".web-recap.bak")
+	if err != nil || string(backup) != original {
+		t.Errorf("backup missing or changed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "checksum") || !strings.Contains(string(data), `"keep-me"`) {
+		t.Errorf("checksum should be dropped and unknown fields kept:\n%s", data)
+	}
+
+	entries, err := NewChromeBookmarkHandler(path, "chrome").GetBookmarks(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetBookmarks: %v", err)
+	}
+	folders := make(map[string]string)
+	for _, e := range entries {
+		folders[e.URL] = e.Folder
+	}
+	if len(entries) != 3 || !strings.HasSuffix(folders["https://go.dev/"], "Reading/Go") {
+		t.Errorf("unexpected bookmarks: %v", folders)
+	}
+}
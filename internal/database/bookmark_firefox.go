@@ -1,41 +1,38 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"io"
-	"os"
 	"time"
 
 	"github.com/rzolkos/web-recap/internal/models"
 	_ "modernc.org/sqlite"
 )
 
-// FirefoxBookmarkHandler handles Firefox bookmark extraction
+// FirefoxBookmarkHandler handles Firefox, Pale Moon, SeaMonkey, and other
+// Gecko bookmark extraction
 type FirefoxBookmarkHandler struct {
-	dbPath string
+	dbPath      string
+	browserName string
 }
 
-// NewFirefoxBookmarkHandler creates a new Firefox bookmark handler
-func NewFirefoxBookmarkHandler(dbPath string) *FirefoxBookmarkHandler {
+// NewFirefoxBookmarkHandler creates a new Gecko bookmark handler whose
+// entries are labelled browserName
+func NewFirefoxBookmarkHandler(dbPath, browserName string) *FirefoxBookmarkHandler {
 	return &FirefoxBookmarkHandler{
-		dbPath: dbPath,
+		dbPath:      dbPath,
+		browserName: browserName,
 	}
 }
 
 // GetBookmarks retrieves all bookmarks from Firefox
-func (h *FirefoxBookmarkHandler) GetBookmarks(startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
-	// Copy database to temp location to avoid locking issues
-	tempDB, err := h.copyDatabase()
+func (h *FirefoxBookmarkHandler) GetBookmarks(ctx context.Context, startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
+	// Read the database in place, or a copy when the browser has it locked
+	db, closeDB, err := h.openDatabase(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tempDB)
-
-	db, err := sql.Open("sqlite", tempDB)
-	if err != nil {
-		return nil, err
-	}
-	defer db.Close()
+	defer closeDB()
 
 	// Firefox stores bookmarks in moz_bookmarks and moz_places tables
 	// Type 1 = bookmark, Type 2 = folder, Type 3 = separator
@@ -46,15 +43,28 @@ func (h *FirefoxBookmarkHandler) GetBookmarks(startTime, endTime time.Time) ([]m
 			p.url,
 			b.title,
 			b.parent,
-			p.id
+			p.id,
+			IFNULL(b.guid, '')
 		FROM moz_bookmarks b
 		JOIN moz_places p ON b.fk = p.id
 		WHERE b.type = 1
 		AND p.url IS NOT NULL
-		ORDER BY b.dateAdded DESC
 	`
+	var args []interface{}
+
+	// Filter in SQL on [startTime, endTime), like the Chromium tree walk.
+	// Firefox uses microseconds since epoch.
+	if !startTime.IsZero() {
+		query += ` AND b.dateAdded >= ?`
+		args = append(args, startTime.UnixMicro())
+	}
+	if !endTime.IsZero() {
+		query += ` AND b.dateAdded < ?`
+		args = append(args, endTime.UnixMicro())
+	}
+	query += ` ORDER BY b.dateAdded DESC`
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -67,22 +77,15 @@ func (h *FirefoxBookmarkHandler) GetBookmarks(startTime, endTime time.Time) ([]m
 		var url string
 		var title sql.NullString
 		var parent, placeID int64
+		var guid string
 
-		if err := rows.Scan(&dateAdded, &dateModified, &url, &title, &parent, &placeID); err != nil {
+		if err := rows.Scan(&dateAdded, &dateModified, &url, &title, &parent, &placeID, &guid); err != nil {
 			continue
 		}
 
 		// Convert timestamp
 		dateAddedTime := ConvertFirefoxTimestamp(dateAdded)
 
-		// Filter by date if time range is specified
-		if !startTime.IsZero() && dateAddedTime.Before(startTime) {
-			continue
-		}
-		if !endTime.IsZero() && dateAddedTime.After(endTime) {
-			continue
-		}
-
 		// Get folder path
 		folderPath := h.getFolderPath(db, parent)
 
@@ -95,13 +98,14 @@ func (h *FirefoxBookmarkHandler) GetBookmarks(startTime, endTime time.Time) ([]m
 		}
 
 		bookmarks = append(bookmarks, models.BookmarkEntry{
+			ID:           guid,
 			DateAdded:    dateAddedTime,
 			DateModified: ConvertFirefoxTimestamp(dateModified),
 			URL:          url,
 			Title:        titleStr,
 			Folder:       folderPath,
 			Domain:       ExtractDomain(url),
-			Browser:      "firefox",
+			Browser:      h.browserName,
 			Tags:         tags,
 		})
 	}
@@ -178,25 +182,7 @@ func (h *FirefoxBookmarkHandler) getTags(db *sql.DB, placeID int64) []string {
 	return tags
 }
 
-// copyDatabase copies the Firefox database to a temporary file
-func (h *FirefoxBookmarkHandler) copyDatabase() (string, error) {
-	src, err := os.Open(h.dbPath)
-	if err != nil {
-		return "", err
-	}
-	defer src.Close()
-
-	dst, err := os.CreateTemp("", "web-recap-firefox-bookmarks-*.db")
-	if err != nil {
-		return "", err
-	}
-	tmpFile := dst.Name()
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, src); err != nil {
-		os.Remove(tmpFile)
-		return "", err
-	}
-
-	return tmpFile, nil
+// openDatabase opens the Firefox database in place, or a copy when it is locked
+func (h *FirefoxBookmarkHandler) openDatabase(ctx context.Context) (*sql.DB, func(), error) {
+	return openSnapshot(ctx, h.dbPath, "web-recap-firefox-bookmarks-*.db")
 }
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
@@ -11,31 +12,36 @@ import (
 
 // BookmarkQuerier defines the interface for querying browser bookmarks
 type BookmarkQuerier interface {
-	GetBookmarks(startTime, endTime time.Time) ([]models.BookmarkEntry, error)
+	GetBookmarks(ctx context.Context, startTime, endTime time.Time) ([]models.BookmarkEntry, error)
 }
 
 // NewBookmarkQuerier creates a new bookmark querier for the given browser
 func NewBookmarkQuerier(b *browser.Browser, bookmarkPath string) (BookmarkQuerier, error) {
-	switch b.Type {
-	case browser.Chrome, browser.Chromium, browser.Edge, browser.Brave, browser.Vivaldi:
+	switch browser.EngineOf(b.Type) {
+	case browser.EngineChromium:
 		return NewChromeBookmarkHandler(bookmarkPath, string(b.Type)), nil
-	case browser.Firefox:
-		return NewFirefoxBookmarkHandler(bookmarkPath), nil
-	case browser.Safari:
+	case browser.EngineGecko:
+		return NewFirefoxBookmarkHandler(bookmarkPath, string(b.Type)), nil
+	case browser.EngineWebKit:
 		return NewSafariBookmarkHandler(bookmarkPath), nil
+	case browser.EngineCustom:
+		if h, ok := lookupHandlers(b.Type); ok && h.Bookmarks != nil {
+			return h.Bookmarks(bookmarkPath), nil
+		}
+		return nil, ErrUnsupportedBrowser
 	default:
 		return nil, ErrUnsupportedBrowser
 	}
 }
 
 // QueryBookmarks retrieves bookmark entries from a specific browser
-func QueryBookmarks(b *browser.Browser, bookmarkPath string, startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
+func QueryBookmarks(ctx context.Context, b *browser.Browser, bookmarkPath string, startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
 	querier, err := NewBookmarkQuerier(b, bookmarkPath)
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := querier.GetBookmarks(startTime, endTime)
+	entries, err := querier.GetBookmarks(ctx, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
@@ -48,42 +54,46 @@ func QueryBookmarks(b *browser.Browser, bookmarkPath string, startTime, endTime
 	return entries, nil
 }
 
-// QueryMultipleBrowsersBookmarks retrieves bookmarks from all detected browsers
-func QueryMultipleBrowsersBookmarks(detector *browser.Detector, startTime, endTime time.Time) ([]models.BookmarkEntry, []string) {
+// QueryMultipleBrowsersBookmarks retrieves bookmarks from all detected
+// browsers. Each browser is reported in the returned sources, with the error
+// that caused it to be skipped; the error is only set when ctx is done.
+func QueryMultipleBrowsersBookmarks(ctx context.Context, detector *browser.Detector, startTime, endTime time.Time) ([]models.BookmarkEntry, []models.SourceStatus, error) {
 	var allEntries []models.BookmarkEntry
-	var warnings []string
+	var sources []models.SourceStatus
 
-	detectedBrowsers := detector.Detect()
+	detectedBrowsers, err := detector.DetectContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	for _, b := range detectedBrowsers {
-		br := b // Copy to avoid pointer issues
-
-		// Get bookmark path for this browser
-		bookmarkPath, err := browser.GetBookmarkPath(br.Type)
-		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("%s: failed to resolve bookmark path: %v", br.Type, err))
+		// Terminal browsers keep history only
+		if browser.EngineOf(b.Type) == browser.EngineText {
 			continue
 		}
+		br := b // Copy to avoid pointer issues
+		status := models.SourceStatus{Browser: string(br.Type), Name: br.Name}
 
-		// Check if bookmark file exists
-		if bookmarkPath == "" {
-			warnings = append(warnings, fmt.Sprintf("%s: bookmark path is empty", br.Type))
+		bookmarkPath, err := ResolveBookmarkPath(br.Type)
+		if err != nil {
+			status.Error = describeError(err)
+			status.Code = ErrorCode(err)
+			sources = append(sources, status)
 			continue
 		}
+		status.Path = bookmarkPath
 
-		// For Firefox, we need to find the profile
-		if br.Type == browser.Firefox {
-			bookmarkPath, err = browser.GetFirefoxProfilePath(bookmarkPath)
-			if err != nil {
-				warnings = append(warnings, fmt.Sprintf("%s: failed to resolve profile path: %v", br.Type, err))
-				continue
-			}
-		}
-
-		entries, err := QueryBookmarks(&br, bookmarkPath, startTime, endTime)
+		entries, err := QueryBookmarks(ctx, &br, bookmarkPath, startTime, endTime)
 		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("%s: failed to query bookmarks: %v", br.Type, err))
+			if ctx.Err() != nil {
+				return nil, sources, ctx.Err()
+			}
+			status.Error = "failed to query bookmarks: " + describeError(err)
+			status.Code = ErrorCode(err)
+			sources = append(sources, status)
 			continue
 		}
+		status.Entries = len(entries)
+		sources = append(sources, status)
 		allEntries = append(allEntries, entries...)
 	}
 
@@ -92,7 +102,28 @@ func QueryMultipleBrowsersBookmarks(detector *browser.Detector, startTime, endTi
 		return bookmarkEntryLess(allEntries[i], allEntries[j])
 	})
 
-	return allEntries, warnings
+	return allEntries, sources, nil
+}
+
+// ResolveBookmarkPath returns the bookmark location QueryBookmarks expects
+// for a browser type: the bookmarks file, or the profile database for
+// Firefox and its derivatives
+func ResolveBookmarkPath(browserType browser.Type) (string, error) {
+	bookmarkPath, err := browser.GetBookmarkPath(browserType)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve bookmark path: %v", err)
+	}
+	if bookmarkPath == "" {
+		return "", fmt.Errorf("bookmark path is empty")
+	}
+
+	if browser.EngineOf(browserType) == browser.EngineGecko {
+		bookmarkPath, err = browser.GetFirefoxProfilePath(bookmarkPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve profile path: %v", err)
+		}
+	}
+	return bookmarkPath, nil
 }
 
 func bookmarkEntryLess(a, b models.BookmarkEntry) bool {
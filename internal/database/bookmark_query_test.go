@@ -1,6 +1,10 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,3 +55,58 @@ func TestBookmarkEntryLess(t *testing.T) {
 		})
 	}
 }
+
+func TestFirefoxBookmarksDateRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "places.sqlite")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) int64 {
+		return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC).UnixMicro()
+	}
+	_, err = db.Exec(`
+		CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT);
+		CREATE TABLE moz_bookmarks (id INTEGER PRIMARY KEY, type INTEGER, fk INTEGER,
+			parent INTEGER, title TEXT, dateAdded INTEGER, lastModified INTEGER, guid TEXT);
+		INSERT INTO moz_places VALUES (1, 'https://a.example/'), (2, 'https://b.example/'), (3, 'https://c.example/');
+		INSERT INTO moz_bookmarks VALUES
+			(10, 1, 1, 0, 'A', ?, 0, 'guidA'),
+			(11, 1, 2, 0, 'B', ?, 0, 'guidB'),
+			(12, 1, 3, 0, 'C', ?, 0, 'guidC');
+	`, day(1), day(2), day(3))
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []string
+	}{
+		{"unbounded", time.Time{}, time.Time{}, []string{"C", "B", "A"}},
+		{"from start", start, time.Time{}, []string{"C", "B"}},
+		{"single day", start, start.AddDate(0, 0, 1), []string{"B"}},
+		{"end is exclusive", time.Time{}, time.UnixMicro(day(2)), []string{"A"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := NewFirefoxBookmarkHandler(path, "firefox").GetBookmarks(context.Background(), tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Title)
+				if e.ID != "guid"+e.Title {
+					t.Errorf("%s: id = %q, want the bookmark GUID", e.Title, e.ID)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("titles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
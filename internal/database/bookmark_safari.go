@@ -1,7 +1,7 @@
 package database
 
 import (
-	"fmt"
+	"context"
 	"os"
 	"time"
 
@@ -40,15 +40,16 @@ type safariBookmarkNode struct {
 	WebBookmarkFileVersion int                    `plist:"WebBookmarkFileVersion"`
 }
 
-// GetBookmarks retrieves all bookmarks from Safari
-func (h *SafariBookmarkHandler) GetBookmarks(startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
+// GetBookmarks retrieves bookmarks from Safari. Safari only records when
+// Reading List items were added (ReadingList.DateAdded); plain bookmarks
+// carry no date, so a time range leaves them out.
+func (h *SafariBookmarkHandler) GetBookmarks(ctx context.Context, startTime, endTime time.Time) ([]models.BookmarkEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	h.startTime = startTime
 	h.endTime = endTime
 
-	if !startTime.IsZero() || !endTime.IsZero() {
-		return nil, fmt.Errorf("safari bookmarks do not provide date_added metadata; date filtering is not supported")
-	}
-
 	data, err := os.ReadFile(h.plistPath)
 	if err != nil {
 		return nil, err
@@ -77,8 +78,10 @@ func (h *SafariBookmarkHandler) extractFromNode(node safariBookmarkNode, folderP
 	case "WebBookmarkTypeLeaf":
 		// This is a bookmark
 		if node.URLString != "" {
-			// Safari bookmark plist doesn't expose stable date_added metadata for regular bookmarks.
-			// Keep timestamp unset instead of emitting a fabricated zero or incorrect value.
+			added := node.dateAdded()
+			if !h.inRange(added) {
+				return nil
+			}
 			if node.URIDictionary != nil {
 				if title, ok := node.URIDictionary["title"].(string); ok && title != "" && node.Title == "" {
 					node.Title = title
@@ -86,11 +89,13 @@ func (h *SafariBookmarkHandler) extractFromNode(node safariBookmarkNode, folderP
 			}
 
 			bookmarks = append(bookmarks, models.BookmarkEntry{
-				URL:     node.URLString,
-				Title:   node.Title,
-				Folder:  folderPath,
-				Domain:  ExtractDomain(node.URLString),
-				Browser: "safari",
+				ID:        node.WebBookmarkUUID,
+				URL:       node.URLString,
+				Title:     node.Title,
+				Folder:    folderPath,
+				Domain:    ExtractDomain(node.URLString),
+				DateAdded: added,
+				Browser:   "safari",
 			})
 		}
 
@@ -111,13 +116,15 @@ func (h *SafariBookmarkHandler) extractFromNode(node safariBookmarkNode, folderP
 
 	case "WebBookmarkTypeProxy":
 		// Reading list or other special items - extract if they have URLs
-		if node.URLString != "" {
+		if added := node.dateAdded(); node.URLString != "" && h.inRange(added) {
 			bookmarks = append(bookmarks, models.BookmarkEntry{
-				URL:     node.URLString,
-				Title:   node.Title,
-				Folder:  folderPath + "/Reading List",
-				Domain:  ExtractDomain(node.URLString),
-				Browser: "safari",
+				ID:        node.WebBookmarkUUID,
+				URL:       node.URLString,
+				Title:     node.Title,
+				Folder:    folderPath + "/Reading List",
+				Domain:    ExtractDomain(node.URLString),
+				DateAdded: added,
+				Browser:   "safari",
 			})
 		}
 
@@ -129,3 +136,21 @@ func (h *SafariBookmarkHandler) extractFromNode(node safariBookmarkNode, folderP
 
 	return bookmarks
 }
+
+// dateAdded returns when a Reading List item was added, or the zero time for
+// other bookmarks, which Safari keeps no date for
+func (n safariBookmarkNode) dateAdded() time.Time {
+	if added, ok := n.ReadingList["DateAdded"].(time.Time); ok {
+		return added.UTC()
+	}
+	return time.Time{}
+}
+
+// inRange reports whether a bookmark added at added falls in the requested
+// time range; with a range set, bookmarks without a date are left out
+func (h *SafariBookmarkHandler) inRange(added time.Time) bool {
+	if h.startTime.IsZero() && h.endTime.IsZero() {
+		return true
+	}
+	return WithinHalfOpenRange(added, h.startTime, h.endTime)
+}
@@ -1,18 +1,68 @@
 package database
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 )
 
-func TestSafariBookmarkHandlerRejectsDateFiltering(t *testing.T) {
-	h := NewSafariBookmarkHandler("/does/not/matter.plist")
+func TestSafariBookmarkHandlerFiltersByDate(t *testing.T) {
+	plistPath := filepath.Join(t.TempDir(), "Bookmarks.plist")
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Children</key>
+  <array>
+    <dict>
+      <key>WebBookmarkType</key><string>WebBookmarkTypeLeaf</string>
+      <key>URLString</key><string>https://example.com/favorite</string>
+    </dict>
+    <dict>
+      <key>WebBookmarkType</key><string>WebBookmarkTypeList</string>
+      <key>Title</key><string>com.apple.ReadingList</string>
+      <key>Children</key>
+      <array>
+        <dict>
+          <key>WebBookmarkType</key><string>WebBookmarkTypeLeaf</string>
+          <key>URLString</key><string>https://example.com/old</string>
+          <key>ReadingList</key>
+          <dict><key>DateAdded</key><date>2025-12-01T10:00:00Z</date></dict>
+        </dict>
+        <dict>
+          <key>WebBookmarkType</key><string>WebBookmarkTypeLeaf</string>
+          <key>URLString</key><string>https://example.com/new</string>
+          <key>ReadingList</key>
+          <dict><key>DateAdded</key><date>2026-01-02T10:00:00Z</date></dict>
+        </dict>
+      </array>
+    </dict>
+  </array>
+</dict>
+</plist>`
+	if err := os.WriteFile(plistPath, []byte(plist), 0o600); err != nil {
+		t.Fatalf("write plist: %v", err)
+	}
+	h := NewSafariBookmarkHandler(plistPath)
 
-	_, err := h.GetBookmarks(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{})
-	if err == nil {
-		t.Fatalf("expected date filtering to be rejected for Safari bookmarks")
+	all, err := h.GetBookmarks(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if len(all) != 3 || !all[0].DateAdded.IsZero() || !all[2].DateAdded.Equal(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unfiltered bookmarks = %+v", all)
+	}
+
+	// A range keeps the Reading List items added in it and leaves out
+	// bookmarks Safari keeps no date for
+	entries, err := h.GetBookmarks(context.Background(), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+	if err != nil {
+		t.Fatalf("GetBookmarks() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "https://example.com/new" {
+		t.Fatalf("filtered bookmarks = %+v", entries)
 	}
 }
 
@@ -55,7 +105,7 @@ func TestSafariBookmarkHandlerExtractsBookmarks(t *testing.T) {
 	}
 
 	h := NewSafariBookmarkHandler(plistPath)
-	entries, err := h.GetBookmarks(time.Time{}, time.Time{})
+	entries, err := h.GetBookmarks(context.Background(), time.Time{}, time.Time{})
 	if err != nil {
 		t.Fatalf("GetBookmarks() error = %v", err)
 	}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Capabilities reports which data types can be read from a detected browser
+// on this platform, with the reason for each one that can't
+func Capabilities(b browser.Browser) models.BrowserCapabilities {
+	engine := browser.EngineOf(b.Type)
+	history := historyCapability(b, engine)
+
+	searches := models.Capability{Data: models.DataSearches, Supported: history.Supported}
+	if history.Supported {
+		searches.Reason = "derived from search engine URLs in history"
+	} else {
+		searches.Reason = "needs history: " + history.Reason
+	}
+
+	return models.BrowserCapabilities{
+		Browser: string(b.Type),
+		Name:    b.Name,
+		Engine:  string(engine),
+		Path:    b.Path,
+		Capabilities: []models.Capability{
+			history,
+			bookmarkCapability(b, engine),
+			tabCapability(b, engine),
+			{Data: models.DataDownloads, Reason: "web-recap does not read downloads yet"},
+			searches,
+		},
+	}
+}
+
+func historyCapability(b browser.Browser, engine browser.Engine) models.Capability {
+	c := models.Capability{Data: models.DataHistory}
+	switch engine {
+	case browser.EngineChromium, browser.EngineGecko, browser.EngineWebKit, browser.EngineText:
+		c.Supported, c.Reason = checkReadable(b.Path)
+	case browser.EngineCustom:
+		if _, ok := lookupHandlers(b.Type); ok {
+			c.Supported = true
+		} else {
+			c.Reason = "no handler registered for this custom browser"
+		}
+	default:
+		c.Reason = fmt.Sprintf("unknown browser type %s", b.Type)
+	}
+	return c
+}
+
+func bookmarkCapability(b browser.Browser, engine browser.Engine) models.Capability {
+	c := models.Capability{Data: models.DataBookmarks}
+	switch engine {
+	case browser.EngineChromium, browser.EngineWebKit:
+		path, err := browser.GetBookmarkPath(b.Type)
+		if err != nil {
+			c.Reason = fmt.Sprintf("no bookmarks location on %s", runtime.GOOS)
+			return c
+		}
+		c.Supported, c.Reason = checkReadable(path)
+	case browser.EngineGecko:
+		// Firefox keeps bookmarks in places.sqlite alongside history
+		c.Supported, c.Reason = checkReadable(b.Path)
+	case browser.EngineText:
+		c.Reason = "terminal browsers keep no bookmarks web-recap reads"
+	case browser.EngineCustom:
+		if h, ok := lookupHandlers(b.Type); ok && h.Bookmarks != nil {
+			c.Supported = true
+		} else {
+			c.Reason = "no bookmark handler registered for this custom browser"
+		}
+	default:
+		c.Reason = fmt.Sprintf("unknown browser type %s", b.Type)
+	}
+	return c
+}
+
+func tabCapability(b browser.Browser, engine browser.Engine) models.Capability {
+	c := models.Capability{Data: models.DataTabs}
+	if engine != browser.EngineChromium {
+		c.Reason = "open tabs are only read from Chromium session files"
+		return c
+	}
+	path, err := browser.GetSessionPath(b.Type)
+	if err != nil {
+		c.Reason = fmt.Sprintf("no session location on %s", runtime.GOOS)
+		return c
+	}
+	c.Supported, c.Reason = checkReadable(path)
+	return c
+}
+
+// checkReadable reports whether path can be opened, and why not
+func checkReadable(path string) (bool, string) {
+	f, err := os.Open(path)
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return false, fmt.Sprintf("not found: %s", path)
+		case errors.Is(err, os.ErrPermission):
+			if runtime.GOOS == "darwin" {
+				return false, fmt.Sprintf("permission denied: %s (grant Full Disk Access to your terminal)", path)
+			}
+			return false, fmt.Sprintf("permission denied: %s", path)
+		default:
+			return false, fmt.Sprintf("cannot open %s: %v", path, err)
+		}
+	}
+	f.Close()
+	return true, ""
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCapabilities(t *testing.T) {
+	profile := t.TempDir()
+	for _, name := range []string{"History", "Bookmarks"} {
+		if err := os.WriteFile(filepath.Join(profile, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := browser.Register(browser.Definition{Type: "capfork", Engine: browser.EngineChromium, Paths: []string{profile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		b         browser.Browser
+		supported map[string]bool
+		reason    map[string]string
+	}{
+		{
+			name:      "chromium fork without sessions",
+			b:         browser.Browser{Type: "capfork", Name: "capfork", Path: filepath.Join(profile, "History")},
+			supported: map[string]bool{models.DataHistory: true, models.DataBookmarks: true, models.DataSearches: true},
+			reason:    map[string]string{models.DataTabs: "not found", models.DataDownloads: "does not read downloads"},
+		},
+		{
+			name:   "firefox with missing places database",
+			b:      browser.Browser{Type: browser.Firefox, Name: "Firefox", Path: filepath.Join(profile, "missing", "places.sqlite")},
+			reason: map[string]string{models.DataHistory: "not found", models.DataTabs: "Chromium session files", models.DataSearches: "needs history"},
+		},
+	}
+
+	for _, tt := range tests {
+		caps := Capabilities(tt.b)
+		if len(caps.Capabilities) != 5 {
+			t.Fatalf("%s: got %d capabilities, want 5", tt.name, len(caps.Capabilities))
+		}
+		for _, c := range caps.Capabilities {
+			if c.Supported != tt.supported[c.Data] {
+				t.Errorf("%s: %s supported = %v (%s)", tt.name, c.Data, c.Supported, c.Reason)
+			}
+			if want, ok := tt.reason[c.Data]; ok && !strings.Contains(c.Reason, want) {
+				t.Errorf("%s: %s reason = %q, want it to mention %q", tt.name, c.Data, c.Reason, want)
+			}
+		}
+	}
+}
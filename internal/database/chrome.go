@@ -1,9 +1,9 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"io"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/rzolkos/web-recap/internal/models"
@@ -23,19 +23,26 @@ func NewChromeHandler(dbPath string) *ChromeHandler {
 }
 
 // GetHistory retrieves history entries from Chrome
-func (h *ChromeHandler) GetHistory(startDate, endDate time.Time) ([]models.HistoryEntry, error) {
-	// Copy database to temp location to avoid locking issues
-	tempDB, err := h.copyDatabase()
+func (h *ChromeHandler) GetHistory(ctx context.Context, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+	return collectHistory(ctx, h, startDate, endDate)
+}
+
+// StreamHistory passes Chrome history entries to fn, newest first, without
+// holding them in memory
+func (h *ChromeHandler) StreamHistory(ctx context.Context, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error {
+	// Read the database in place, or a copy when the browser has it locked
+	db, closeDB, err := h.openDatabase(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer os.Remove(tempDB)
+	defer closeDB()
 
-	db, err := sql.Open("sqlite", tempDB)
-	if err != nil {
-		return nil, err
+	contextColumns, contextJoin := visitContextQuery(db)
+	referrerColumn, referrerJoin := chromeReferrerQuery(db)
+	durationColumn := "NULL"
+	if columnExists(db, "visits", "visit_duration") {
+		durationColumn = "v.visit_duration"
 	}
-	defer db.Close()
 
 	// Prepare date filters
 	// Query the visits table joined with urls to get individual visit records
@@ -49,9 +56,14 @@ func (h *ChromeHandler) GetHistory(startDate, endDate time.Time) ([]models.Histo
 			v.visit_time,
 			u.url,
 			u.title,
-			u.visit_count
+			u.visit_count,
+			` + contextColumns + `,
+			` + referrerColumn + `,
+			` + durationColumn + `
 		FROM visits v
 		JOIN urls u ON v.url = u.id
+		` + contextJoin + `
+		` + referrerJoin + `
 		WHERE v.visit_time > 0
 		`
 
@@ -79,29 +91,35 @@ func (h *ChromeHandler) GetHistory(startDate, endDate time.Time) ([]models.Histo
 			v.visit_time,
 			u.url,
 			u.title,
-			u.visit_count
+			u.visit_count,
+			` + contextColumns + `,
+			` + referrerColumn + `,
+			` + durationColumn + `
 		FROM visits v
 		JOIN urls u ON v.url = u.id
+		` + contextJoin + `
+		` + referrerJoin + `
 		WHERE v.visit_time > 0
 		ORDER BY v.visit_time DESC
 		LIMIT 10000
 		`
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var entries []models.HistoryEntry
-
 	for rows.Next() {
 		var chromeTime int64
 		var url, title string
 		var visitCount int
+		var ids [len(visitContextColumns)]sql.NullInt64
+		var referrer sql.NullString
+		var duration sql.NullInt64
 
-		if err := rows.Scan(&chromeTime, &url, &title, &visitCount); err != nil {
+		if err := rows.Scan(&chromeTime, &url, &title, &visitCount, &ids[0], &ids[1], &ids[2], &ids[3], &ids[4], &referrer, &duration); err != nil {
 			continue
 		}
 
@@ -110,38 +128,83 @@ func (h *ChromeHandler) GetHistory(startDate, endDate time.Time) ([]models.Histo
 			continue
 		}
 
-		entries = append(entries, models.HistoryEntry{
+		if err := fn(models.HistoryEntry{
 			Timestamp:  timestamp,
 			URL:        url,
 			Title:      title,
 			VisitCount: visitCount,
 			Domain:     ExtractDomain(url),
 			Browser:    "chrome",
-		})
+			Context:    newVisitContext(ids),
+			Referrer:   referrer.String,
+			// visit_duration is in microseconds
+			Duration: time.Duration(max(duration.Int64, 0)) * time.Microsecond,
+		}); err != nil {
+			return err
+		}
 	}
 
-	return entries, rows.Err()
+	return rows.Err()
 }
 
-// copyDatabase copies the Chrome database to a temporary file
-func (h *ChromeHandler) copyDatabase() (string, error) {
-	src, err := os.Open(h.dbPath)
-	if err != nil {
-		return "", err
-	}
-	defer src.Close()
+// openDatabase opens the Chrome database in place, or a copy when it is locked
+func (h *ChromeHandler) openDatabase(ctx context.Context) (*sql.DB, func(), error) {
+	return openSnapshot(ctx, h.dbPath, "web-recap-chrome-*.db")
+}
 
-	dst, err := os.CreateTemp("", "web-recap-chrome-*.db")
-	if err != nil {
-		return "", err
+// visitContextColumns are the context_annotations columns read into a
+// models.VisitContext, in its field order
+var visitContextColumns = [...]string{"window_id", "tab_id", "task_id", "root_task_id", "parent_task_id"}
+
+// visitContextQuery returns the select list and join that read each visit's
+// context_annotations. Chrome added the table in 2021 and its columns
+// over several releases; those missing from db read as NULL.
+func visitContextQuery(db *sql.DB) (columns, join string) {
+	exists := tableExists(db, "context_annotations")
+	selects := make([]string, len(visitContextColumns))
+	for i, col := range visitContextColumns {
+		if exists && columnExists(db, "context_annotations", col) {
+			selects[i] = "c." + col
+		} else {
+			selects[i] = "NULL"
+		}
+	}
+	if exists {
+		join = "LEFT JOIN context_annotations c ON c.visit_id = v.id"
 	}
-	tmpFile := dst.Name()
-	defer dst.Close()
+	return strings.Join(selects, ", "), join
+}
 
-	if _, err := io.Copy(dst, src); err != nil {
-		os.Remove(tmpFile)
-		return "", err
+// chromeReferrerQuery returns the select column and join that read the URL
+// a visit navigated from: from_visit for links and redirects in the same
+// tab, or opener_visit for a link opened in a new tab
+func chromeReferrerQuery(db *sql.DB) (column, join string) {
+	from := "NULL"
+	if columnExists(db, "visits", "from_visit") {
+		from = "NULLIF(v.from_visit, 0)"
+	}
+	if columnExists(db, "visits", "opener_visit") {
+		from = "COALESCE(" + from + ", NULLIF(v.opener_visit, 0))"
+	}
+	if from == "NULL" {
+		return "NULL", ""
 	}
+	return "ru.url", "LEFT JOIN visits rv ON rv.id = " + from + " LEFT JOIN urls ru ON ru.id = rv.url"
+}
 
-	return tmpFile, nil
+// newVisitContext builds the context of a visit from its context_annotations
+// IDs, or nil when none was recorded. Chrome stores -1 for an unknown ID.
+func newVisitContext(ids [len(visitContextColumns)]sql.NullInt64) *models.VisitContext {
+	var v [len(visitContextColumns)]int64
+	found := false
+	for i, id := range ids {
+		if id.Valid && id.Int64 > 0 {
+			v[i] = id.Int64
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &models.VisitContext{WindowID: v[0], TabID: v[1], TaskID: v[2], RootTaskID: v[3], ParentTaskID: v[4]}
 }
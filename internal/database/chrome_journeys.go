@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// ErrNoJourneys is returned for a Chromium profile without Journeys tables
+var ErrNoJourneys = errors.New("no Journeys data in this profile (Chrome stores it only while Journeys is enabled)")
+
+// QueryJourneys retrieves the Journeys clusters a Chromium-based browser
+// made of the visits in [startDate, endDate)
+func QueryJourneys(ctx context.Context, b *browser.Browser, startDate, endDate time.Time) ([]models.Journey, error) {
+	if !browser.IsChromiumBased(b.Type) {
+		return nil, fmt.Errorf("journeys are only supported for Chromium-based browsers")
+	}
+	journeys, err := NewChromeHandler(b.Path).GetJourneys(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for i := range journeys {
+		journeys[i].Browser = string(b.Type)
+	}
+	return journeys, nil
+}
+
+// GetJourneys reads Chrome's Journeys: the clusters table, each cluster's
+// visits from clusters_and_visits, and its keywords from cluster_keywords.
+// Only visits in [startDate, endDate) are included, so a cluster that spans
+// the boundary is cut at it. Journeys are returned newest first, and the
+// visits in each oldest first.
+func (h *ChromeHandler) GetJourneys(ctx context.Context, startDate, endDate time.Time) ([]models.Journey, error) {
+	// Read the database in place, or a copy when the browser has it locked
+	db, closeDB, err := h.openDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+
+	if !tableExists(db, "clusters") || !tableExists(db, "clusters_and_visits") {
+		return nil, ErrNoJourneys
+	}
+
+	start, end := int64(0), int64(math.MaxInt64)
+	if !startDate.IsZero() {
+		start = toChromeTimestamp(startDate)
+	}
+	if !endDate.IsZero() {
+		end = toChromeTimestamp(endDate)
+	}
+
+	// Older profiles have no label columns; raw_label is the label without
+	// the quotes Chrome adds around search terms
+	label := "NULL"
+	if columnExists(db, "clusters", "raw_label") {
+		label = "c.raw_label"
+	}
+	if columnExists(db, "clusters", "label") {
+		label = "COALESCE(NULLIF(" + label + ", ''), c.label)"
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT cv.cluster_id, `+label+`, v.visit_time, u.url, u.title, cv.score
+		FROM clusters_and_visits cv
+		JOIN clusters c ON c.cluster_id = cv.cluster_id
+		JOIN visits v ON v.id = cv.visit_id
+		JOIN urls u ON u.id = v.url
+		WHERE v.visit_time >= ? AND v.visit_time < ?
+		ORDER BY cv.cluster_id, v.visit_time`, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var journeys []models.Journey
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id, chromeTime int64
+		var label, title sql.NullString
+		var url string
+		var score sql.NullFloat64
+		if err := rows.Scan(&id, &label, &chromeTime, &url, &title, &score); err != nil {
+			continue
+		}
+		timestamp := ConvertChromeTimestamp(chromeTime)
+		if timestamp.IsZero() {
+			continue
+		}
+
+		i, ok := index[id]
+		if !ok {
+			i = len(journeys)
+			index[id] = i
+			journeys = append(journeys, models.Journey{ID: id, Label: label.String, StartTime: timestamp})
+		}
+		j := &journeys[i]
+		j.EndTime = timestamp
+		j.Visits = append(j.Visits, models.JourneyVisit{
+			Timestamp: timestamp,
+			URL:       url,
+			Title:     title.String,
+			Domain:    ExtractDomain(url),
+			Score:     score.Float64,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(journeys) > 0 && tableExists(db, "cluster_keywords") {
+		if err := addJourneyKeywords(ctx, db, journeys, index); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(journeys, func(i, j int) bool {
+		return journeys[i].EndTime.After(journeys[j].EndTime)
+	})
+	return journeys, nil
+}
+
+// addJourneyKeywords sets the keywords of each journey, highest scored first
+func addJourneyKeywords(ctx context.Context, db *sql.DB, journeys []models.Journey, index map[int64]int) error {
+	rows, err := db.QueryContext(ctx, `SELECT cluster_id, keyword FROM cluster_keywords ORDER BY cluster_id, score DESC, keyword`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var keyword sql.NullString
+		if err := rows.Scan(&id, &keyword); err != nil || keyword.String == "" {
+			continue
+		}
+		if i, ok := index[id]; ok {
+			journeys[i].Keywords = append(journeys[i].Keywords, keyword.String)
+		}
+	}
+	return rows.Err()
+}
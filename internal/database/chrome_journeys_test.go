@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+func TestGetJourneys(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	// The last visit, the day before, is out of range
+	path := testutil.NewChromeHistoryDB(t,
+		testutil.ChromeVisit{URL: "https://search.example/?q=sourdough", Title: "sourdough - Search", Time: base},
+		testutil.ChromeVisit{URL: "https://recipes.example/sourdough", Title: "Sourdough Loaf", Time: base.Add(5 * time.Minute)},
+		testutil.ChromeVisit{URL: "https://docs.example/go", Title: "Go docs", Time: base.Add(30 * time.Minute)},
+		testutil.ChromeVisit{URL: "https://docs.example/go", Title: "Go docs", Time: base.AddDate(0, 0, -1)},
+	)
+	for _, stmt := range []string{
+		`CREATE TABLE clusters (cluster_id INTEGER PRIMARY KEY, should_show_on_prominent_ui_surfaces BOOLEAN, label VARCHAR, raw_label VARCHAR)`,
+		`CREATE TABLE clusters_and_visits (cluster_id INTEGER, visit_id INTEGER, score NUMERIC DEFAULT 0, PRIMARY KEY (cluster_id, visit_id))`,
+		`CREATE TABLE cluster_keywords (cluster_id INTEGER, keyword VARCHAR, type INTEGER, score NUMERIC)`,
+		`INSERT INTO clusters VALUES (10, 1, '“sourdough”', 'sourdough'), (11, 1, 'Go', '')`,
+		`INSERT INTO clusters_and_visits VALUES (10, 1, 0.5), (10, 2, 1), (11, 3, 0.8), (11, 4, 0.2)`,
+		`INSERT INTO cluster_keywords VALUES (10, 'bread', 0, 0.4), (10, 'sourdough', 0, 0.9)`,
+	} {
+		testutil.Exec(t, path, stmt)
+	}
+
+	journeys, err := NewChromeHandler(path).GetJourneys(context.Background(), base.Add(-time.Hour), base.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(journeys) != 2 {
+		t.Fatalf("got %d journeys, want 2", len(journeys))
+	}
+
+	// Newest first; the visit from the day before is out of range
+	goJourney, bread := journeys[0], journeys[1]
+	if goJourney.ID != 11 || goJourney.Label != "Go" || len(goJourney.Visits) != 1 {
+		t.Errorf("first journey = %+v, want cluster 11 labelled Go with one visit", goJourney)
+	}
+	if bread.ID != 10 || bread.Label != "sourdough" {
+		t.Errorf("second journey = %d %q, want cluster 10 labelled sourdough", bread.ID, bread.Label)
+	}
+	if len(bread.Keywords) != 2 || bread.Keywords[0] != "sourdough" {
+		t.Errorf("keywords = %v, want highest scored first", bread.Keywords)
+	}
+	if len(bread.Visits) != 2 || bread.Visits[0].URL != "https://search.example/?q=sourdough" || bread.Visits[1].Score != 1 {
+		t.Errorf("visits = %+v, want oldest first with scores", bread.Visits)
+	}
+	if !bread.StartTime.Equal(base) || !bread.EndTime.Equal(base.Add(5*time.Minute)) {
+		t.Errorf("span = %v - %v", bread.StartTime, bread.EndTime)
+	}
+}
+
+func TestGetJourneysWithoutTables(t *testing.T) {
+	_, err := NewChromeHandler(chromeHistory(t, false)).GetJourneys(context.Background(), time.Time{}, time.Time{})
+	if !errors.Is(err, ErrNoJourneys) {
+		t.Errorf("err = %v, want ErrNoJourneys", err)
+	}
+}
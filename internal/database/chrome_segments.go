@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// QuerySiteUsage retrieves the per-domain activity a Chromium-based browser
+// aggregates itself
+func QuerySiteUsage(ctx context.Context, b *browser.Browser, startDate, endDate time.Time) ([]models.SiteUsage, error) {
+	if !browser.IsChromiumBased(b.Type) {
+		return nil, fmt.Errorf("time on site is only supported for Chromium-based browsers")
+	}
+	return NewChromeHandler(b.Path).GetSiteUsage(ctx, startDate, endDate)
+}
+
+// GetSiteUsage reads Chrome's per-site aggregates for [startDate, endDate):
+// daily visit counts from segment_usage and time in the foreground from
+// visits.visit_duration (segment_duration on Chrome versions before 2017).
+// segment_usage is bucketed by local day, so partial days count in full.
+func (h *ChromeHandler) GetSiteUsage(ctx context.Context, startDate, endDate time.Time) ([]models.SiteUsage, error) {
+	// Read the database in place, or a copy when the browser has it locked
+	db, closeDB, err := h.openDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB()
+
+	start, end := int64(0), int64(math.MaxInt64)
+	if !startDate.IsZero() {
+		start = toChromeTimestamp(startDate)
+	}
+	if !endDate.IsZero() {
+		end = toChromeTimestamp(endDate)
+	}
+
+	usage := make(map[string]*models.SiteUsage)
+	site := func(rawURL string) *models.SiteUsage {
+		domain := ExtractDomain(rawURL)
+		u, ok := usage[domain]
+		if !ok {
+			u = &models.SiteUsage{Domain: domain}
+			usage[domain] = u
+		}
+		return u
+	}
+
+	if tableExists(db, "segments") && tableExists(db, "segment_usage") {
+		err := eachRow(ctx, db, `
+			SELECT s.name, SUM(u.visit_count)
+			FROM segment_usage u
+			JOIN segments s ON u.segment_id = s.id
+			WHERE u.time_slot >= ? AND u.time_slot < ?
+			GROUP BY s.name`,
+			[]interface{}{start, end},
+			func(name string, value int64) { site(name).SegmentVisits += int(value) })
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// visit_duration is in microseconds
+	if columnExists(db, "visits", "visit_duration") {
+		err := eachRow(ctx, db, `
+			SELECT u.url, SUM(v.visit_duration)
+			FROM visits v
+			JOIN urls u ON v.url = u.id
+			WHERE v.visit_time >= ? AND v.visit_time < ? AND v.visit_duration > 0
+			GROUP BY u.url`,
+			[]interface{}{start, end},
+			func(url string, value int64) { site(url).BrowserSeconds += float64(value) / 1e6 })
+		if err != nil {
+			return nil, err
+		}
+	} else if tableExists(db, "segments") && tableExists(db, "segment_duration") {
+		err := eachRow(ctx, db, `
+			SELECT s.name, SUM(d.duration)
+			FROM segment_duration d
+			JOIN segments s ON d.segment_id = s.id
+			WHERE d.time_slot >= ? AND d.time_slot < ?
+			GROUP BY s.name`,
+			[]interface{}{start, end},
+			func(name string, value int64) { site(name).BrowserSeconds += float64(value) / 1e6 })
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]models.SiteUsage, 0, len(usage))
+	for _, u := range usage {
+		if u.Domain == "" {
+			continue
+		}
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Domain < result[j].Domain })
+
+	return result, nil
+}
+
+// eachRow runs a query returning (text, integer) rows
+func eachRow(ctx context.Context, db *sql.DB, query string, args []interface{}, fn func(string, int64)) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name sql.NullString
+		var value sql.NullInt64
+		if err := rows.Scan(&name, &value); err != nil {
+			continue
+		}
+		fn(name.String, value.Int64)
+	}
+	return rows.Err()
+}
+
+func tableExists(db *sql.DB, table string) bool {
+	var name string
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&name)
+	return err == nil
+}
+
+func columnExists(db *sql.DB, table, column string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	return err == nil && count > 0
+}
+
+// toChromeTimestamp converts t to microseconds since 1601-01-01 UTC
+func toChromeTimestamp(t time.Time) int64 {
+	return (t.Unix()+11644473600)*1000000 + int64(t.Nanosecond()/1000)
+}
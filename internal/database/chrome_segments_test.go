@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+func TestChromeHandlerGetSiteUsage(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	dbPath := testutil.NewChromeHistoryDB(t,
+		testutil.ChromeVisit{URL: "https://go.dev/doc", Title: "Docs", Time: day.Add(9 * time.Hour), Duration: 90 * time.Second},
+		testutil.ChromeVisit{URL: "https://go.dev/doc", Title: "Docs", Time: day.Add(10 * time.Hour), Duration: 30 * time.Second},
+		testutil.ChromeVisit{URL: "https://news.example/a", Title: "A", Time: day.AddDate(0, 0, 1), Duration: 10 * time.Minute},
+	)
+	for _, stmt := range []string{
+		`CREATE TABLE segments (id INTEGER PRIMARY KEY, name TEXT, url_id INTEGER)`,
+		`CREATE TABLE segment_usage (id INTEGER PRIMARY KEY, segment_id INTEGER, time_slot INTEGER, visit_count INTEGER)`,
+		`INSERT INTO segments VALUES (1, 'http://go.dev/', 1), (2, 'http://news.example/', 2)`,
+	} {
+		testutil.Exec(t, dbPath, stmt)
+	}
+	testutil.Exec(t, dbPath, `INSERT INTO segment_usage (segment_id, time_slot, visit_count) VALUES (?, ?, ?), (?, ?, ?)`,
+		1, testutil.ChromeTime(day), 4, 2, testutil.ChromeTime(day.AddDate(0, 0, 1)), 7)
+
+	usage, err := NewChromeHandler(dbPath).GetSiteUsage(context.Background(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetSiteUsage() error = %v", err)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("expected 1 domain in range, got %+v", usage)
+	}
+	if u := usage[0]; u.Domain != "go.dev" || u.SegmentVisits != 4 || u.BrowserSeconds != 120 {
+		t.Errorf("got %+v, want go.dev with 4 segment visits and 120s", u)
+	}
+}
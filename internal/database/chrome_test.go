@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+// chromeHistory creates a Chrome History database with three visits, and a
+// context_annotations table when annotations is set
+func chromeHistory(t *testing.T, annotations bool) string {
+	t.Helper()
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	path := testutil.NewChromeHistoryDB(t,
+		testutil.ChromeVisit{URL: "https://go.dev/", Title: "Go", Time: base},
+		testutil.ChromeVisit{URL: "https://pkg.go.dev/", Title: "Packages", Time: base.Add(time.Minute)},
+		testutil.ChromeVisit{URL: "https://go.dev/", Title: "Go", Time: base.Add(2 * time.Minute)},
+	)
+	if annotations {
+		testutil.Exec(t, path, `CREATE TABLE context_annotations (visit_id INTEGER PRIMARY KEY, window_id INTEGER, tab_id INTEGER, task_id INTEGER, root_task_id INTEGER, parent_task_id INTEGER)`)
+		testutil.Exec(t, path, `INSERT INTO context_annotations VALUES (1, 7, 42, 100, 100, -1), (2, 7, 43, 101, 100, 100), (3, -1, -1, -1, -1, -1)`)
+	}
+	return path
+}
+
+func TestChromeVisitContext(t *testing.T) {
+	entries, err := NewChromeHandler(chromeHistory(t, true)).GetHistory(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	// Newest first: visit 3 has no context, visit 2 was opened from visit 1
+	if entries[0].Context != nil {
+		t.Errorf("visit without IDs: context = %+v, want nil", entries[0].Context)
+	}
+	want := models.VisitContext{WindowID: 7, TabID: 43, TaskID: 101, RootTaskID: 100, ParentTaskID: 100}
+	if entries[1].Context == nil || *entries[1].Context != want {
+		t.Errorf("opened visit: context = %+v, want %+v", entries[1].Context, want)
+	}
+	want = models.VisitContext{WindowID: 7, TabID: 42, TaskID: 100, RootTaskID: 100}
+	if entries[2].Context == nil || *entries[2].Context != want {
+		t.Errorf("root visit: context = %+v, want %+v", entries[2].Context, want)
+	}
+}
+
+func TestChromeWithoutContextAnnotations(t *testing.T) {
+	entries, err := NewChromeHandler(chromeHistory(t, false)).GetHistory(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for _, e := range entries {
+		if e.Context != nil {
+			t.Errorf("%s: context = %+v, want nil", e.URL, e.Context)
+		}
+	}
+}
+
+func TestChromeReferrer(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	search := "https://www.google.com/search?q=go"
+	// Visit 2 follows a link in the same tab, visit 3 opens one in a new tab
+	path := testutil.NewChromeHistoryDB(t,
+		testutil.ChromeVisit{URL: search, Title: "go - Google Search", Time: base},
+		testutil.ChromeVisit{URL: "https://go.dev/", Title: "Go", Time: base.Add(time.Minute), FromVisit: 1},
+		testutil.ChromeVisit{URL: "https://pkg.go.dev/", Title: "Packages", Time: base.Add(2 * time.Minute), OpenerVisit: 1},
+	)
+
+	entries, err := NewChromeHandler(path).GetHistory(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, want := range []string{search, search, ""} {
+		if entries[i].Referrer != want {
+			t.Errorf("%s: referrer = %q, want %q", entries[i].URL, entries[i].Referrer, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// QueryCloudTabs reads the tabs open on the user's other devices from
+// Safari's CloudTabs.db, ordered by device name. Each tab carries the name
+// of its device.
+func QueryCloudTabs(ctx context.Context, path string) ([]models.TabEntry, error) {
+	db, release, err := openSnapshot(ctx, path, "web-recap-cloudtabs-*.db")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// is_pinned was added in later macOS releases
+	pinned := "0"
+	var exists bool
+	if db.QueryRowContext(ctx, `SELECT 1 FROM pragma_table_info('cloud_tabs') WHERE name = 'is_pinned'`).Scan(&exists) == nil {
+		pinned = "t.is_pinned"
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT IFNULL(d.device_name, ''), IFNULL(t.title, ''), t.url, IFNULL(`+pinned+`, 0)
+		FROM cloud_tabs t
+		LEFT JOIN cloud_tab_devices d ON d.device_uuid = t.device_uuid
+		WHERE t.url IS NOT NULL AND t.url != ''
+		ORDER BY d.device_name, t.rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.TabEntry
+	for rows.Next() {
+		var e models.TabEntry
+		if err := rows.Scan(&e.Device, &e.Title, &e.URL, &e.Pinned); err != nil {
+			return nil, err
+		}
+		e.Domain = ExtractDomain(e.URL)
+		e.Browser = "safari"
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -1,9 +1,48 @@
 package database
 
-import "errors"
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+)
 
 var (
 	ErrSafariNotAvailable = errors.New("Safari is only available on macOS")
 	ErrUnsupportedBrowser = errors.New("unsupported browser type")
 	ErrDatabaseError      = errors.New("database error")
+	ErrCorrupt            = errors.New("database copy is corrupt")
+)
+
+// Codes classifying why a browser could not be read
+const (
+	CodeNotFound         = "not_found"
+	CodePermissionDenied = "permission_denied"
+	CodeQueryFailed      = "query_failed"
+	CodeCorrupt          = "corrupt"
 )
+
+// ErrorCode classifies a failure to read a browser
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, os.ErrPermission):
+		return CodePermissionDenied
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, browser.ErrDatabaseNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrCorrupt), sqliteCorrupt(err):
+		return CodeCorrupt
+	default:
+		return CodeQueryFailed
+	}
+}
+
+// describeError explains a failure to read a browser, pointing at Full Disk
+// Access when macOS blocks the read
+func describeError(err error) string {
+	if errors.Is(err, os.ErrPermission) && runtime.GOOS == "darwin" {
+		return err.Error() + " (grant Full Disk Access to your terminal)"
+	}
+	return err.Error()
+}
@@ -1,16 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"io"
-	"os"
 	"time"
 
 	"github.com/rzolkos/web-recap/internal/models"
 	_ "modernc.org/sqlite"
 )
 
-// FirefoxHandler handles Firefox browser history
+// FirefoxHandler handles the history of Firefox and other Gecko browsers
 type FirefoxHandler struct {
 	dbPath string
 }
@@ -23,19 +22,21 @@ func NewFirefoxHandler(dbPath string) *FirefoxHandler {
 }
 
 // GetHistory retrieves history entries from Firefox
-func (h *FirefoxHandler) GetHistory(startDate, endDate time.Time) ([]models.HistoryEntry, error) {
-	// Copy database to temp location to avoid locking issues
-	tempDB, err := h.copyDatabase()
-	if err != nil {
-		return nil, err
-	}
-	defer os.Remove(tempDB)
+func (h *FirefoxHandler) GetHistory(ctx context.Context, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+	return collectHistory(ctx, h, startDate, endDate)
+}
 
-	db, err := sql.Open("sqlite", tempDB)
+// StreamHistory passes Firefox history entries to fn, newest first, without
+// holding them in memory
+func (h *FirefoxHandler) StreamHistory(ctx context.Context, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error {
+	// Read the database in place, or a copy when the browser has it locked
+	db, closeDB, err := h.openDatabase(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer db.Close()
+	defer closeDB()
+
+	referrerColumn, referrerJoin := firefoxReferrerQuery(db)
 
 	// Prepare date filters
 	var query string
@@ -47,9 +48,11 @@ func (h *FirefoxHandler) GetHistory(startDate, endDate time.Time) ([]models.Hist
 			h.visit_date,
 			p.url,
 			p.title,
-			p.visit_count
+			p.visit_count,
+			` + referrerColumn + `
 		FROM moz_historyvisits h
 		JOIN moz_places p ON h.place_id = p.id
+		` + referrerJoin + `
 		WHERE h.visit_date > 0
 		`
 
@@ -79,29 +82,30 @@ func (h *FirefoxHandler) GetHistory(startDate, endDate time.Time) ([]models.Hist
 			h.visit_date,
 			p.url,
 			p.title,
-			p.visit_count
+			p.visit_count,
+			` + referrerColumn + `
 		FROM moz_historyvisits h
 		JOIN moz_places p ON h.place_id = p.id
+		` + referrerJoin + `
 		WHERE h.visit_date > 0
 		ORDER BY h.visit_date DESC
 		LIMIT 10000
 		`
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var entries []models.HistoryEntry
-
 	for rows.Next() {
 		var firefoxTime int64
 		var url, title string
 		var visitCount int
+		var referrer sql.NullString
 
-		if err := rows.Scan(&firefoxTime, &url, &title, &visitCount); err != nil {
+		if err := rows.Scan(&firefoxTime, &url, &title, &visitCount, &referrer); err != nil {
 			continue
 		}
 
@@ -110,38 +114,32 @@ func (h *FirefoxHandler) GetHistory(startDate, endDate time.Time) ([]models.Hist
 			continue
 		}
 
-		entries = append(entries, models.HistoryEntry{
+		if err := fn(models.HistoryEntry{
 			Timestamp:  timestamp,
 			URL:        url,
 			Title:      title,
 			VisitCount: visitCount,
 			Domain:     ExtractDomain(url),
 			Browser:    "firefox",
-		})
+			Referrer:   referrer.String,
+		}); err != nil {
+			return err
+		}
 	}
 
-	return entries, rows.Err()
+	return rows.Err()
 }
 
-// copyDatabase copies the Firefox database to a temporary file
-func (h *FirefoxHandler) copyDatabase() (string, error) {
-	src, err := os.Open(h.dbPath)
-	if err != nil {
-		return "", err
-	}
-	defer src.Close()
-
-	dst, err := os.CreateTemp("", "web-recap-firefox-*.db")
-	if err != nil {
-		return "", err
-	}
-	tmpFile := dst.Name()
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, src); err != nil {
-		os.Remove(tmpFile)
-		return "", err
+// firefoxReferrerQuery returns the select column and join that read the URL
+// a visit navigated from, recorded in from_visit for links and redirects
+func firefoxReferrerQuery(db *sql.DB) (column, join string) {
+	if !columnExists(db, "moz_historyvisits", "from_visit") {
+		return "NULL", ""
 	}
+	return "rp.url", "LEFT JOIN moz_historyvisits rh ON rh.id = NULLIF(h.from_visit, 0) LEFT JOIN moz_places rp ON rp.id = rh.place_id"
+}
 
-	return tmpFile, nil
+// openDatabase opens the Firefox database in place, or a copy when it is locked
+func (h *FirefoxHandler) openDatabase(ctx context.Context) (*sql.DB, func(), error) {
+	return openSnapshot(ctx, h.dbPath, "web-recap-firefox-*.db")
 }
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// maxIntegrityProblems caps the problems quick_check reports for one copy
+const maxIntegrityProblems = 10
+
+// IntegrityError reports a database copy that does not match its source or
+// fails SQLite's quick_check, so its rows cannot be trusted
+type IntegrityError struct {
+	Path     string   // source database
+	Problems []string // what the checks found, at most maxIntegrityProblems
+}
+
+func (e *IntegrityError) Error() string {
+	msg := fmt.Sprintf("copy of %s failed integrity check: %s", e.Path, e.Problems[0])
+	if n := len(e.Problems) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more)", n)
+	}
+	return msg
+}
+
+func (e *IntegrityError) Is(target error) bool {
+	return target == ErrCorrupt
+}
+
+// verifyCopy checks the copy at copyPath of the database at path. The copy
+// must be as large as the source, when the source still matches stamp, and
+// pass quick_check. A source changed mid-copy may leave a torn copy, which
+// quick_check catches.
+func verifyCopy(ctx context.Context, path string, stamp fileStamp, copyPath string) error {
+	if now, err := stampOf(path); err == nil && now == stamp {
+		info, err := os.Stat(copyPath)
+		if err != nil {
+			return err
+		}
+		if info.Size() != stamp.Size {
+			return &IntegrityError{Path: path, Problems: []string{
+				fmt.Sprintf("copy is %d bytes, source is %d", info.Size(), stamp.Size),
+			}}
+		}
+	}
+
+	problems, err := quickCheck(ctx, copyPath)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &IntegrityError{Path: path, Problems: problems}
+	}
+	return nil
+}
+
+// quickCheck runs PRAGMA quick_check on the database at path and returns the
+// problems it reports. A file SQLite cannot read as a database at all is a
+// problem too.
+func quickCheck(ctx context.Context, path string) ([]string, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA quick_check(%d)", maxIntegrityProblems))
+	if err != nil {
+		return corruptionOf(err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		if line != "ok" {
+			problems = append(problems, strings.TrimSpace(line))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return corruptionOf(err)
+	}
+	return problems, nil
+}
+
+// corruptionOf turns a SQLite error that means the file is damaged into a
+// problem, and returns other errors as they are
+func corruptionOf(err error) ([]string, error) {
+	if sqliteCorrupt(err) {
+		return []string{err.Error()}, nil
+	}
+	return nil, err
+}
+
+// sqliteCorrupt reports whether err is SQLite finding a damaged database or
+// a file that is not a database
+func sqliteCorrupt(err error) bool {
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	switch serr.Code() & 0xff {
+	case sqlite3.SQLITE_CORRUPT, sqlite3.SQLITE_NOTADB:
+		return true
+	}
+	return false
+}
+
+// fileSum returns the SHA-256 of the file at path
+func fileSum(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
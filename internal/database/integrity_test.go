@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+// pagedDatabase creates a database with a table spread over several pages
+// and returns its path and page size
+func pagedDatabase(t *testing.T) (string, int64) {
+	t.Helper()
+	path := testutil.NewChromeHistoryDB(t)
+	for _, stmt := range []string{
+		`CREATE INDEX urls_url ON urls (url)`,
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 500)
+		 INSERT INTO urls (url) SELECT 'https://example.com/page/' || i FROM n`,
+	} {
+		testutil.Exec(t, path, stmt)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var pageSize int64
+	if err := db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		t.Fatal(err)
+	}
+	return path, pageSize
+}
+
+func TestCopySnapshotVerifies(t *testing.T) {
+	ctx := context.Background()
+	path, pageSize := pagedDatabase(t)
+
+	stamp, err := stampOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyPath, _, err := copySnapshot(ctx, path, "verify-*.db", stamp)
+	if err != nil {
+		t.Fatalf("intact database: %v", err)
+	}
+	removeTemp(copyPath)
+
+	// Scribble over the b-tree pages after the schema
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, 2*pageSize)
+	for i := range garbage {
+		garbage[i] = 0x5a
+	}
+	if _, err := f.WriteAt(garbage, 2*pageSize); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	stamp, err = stampOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = copySnapshot(ctx, path, "verify-*.db", stamp)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("corrupt database: err = %v, want ErrCorrupt", err)
+	}
+	var ierr *IntegrityError
+	if !errors.As(err, &ierr) || len(ierr.Problems) == 0 || ierr.Path != path {
+		t.Errorf("err = %#v, want an IntegrityError for %s", err, path)
+	}
+	if code := ErrorCode(err); code != CodeCorrupt {
+		t.Errorf("ErrorCode = %q, want %q", code, CodeCorrupt)
+	}
+}
+
+func TestVerifyCopySize(t *testing.T) {
+	ctx := context.Background()
+	path, pageSize := pagedDatabase(t)
+	stamp, err := stampOf(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A copy cut short while the source did not change
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short := filepath.Join(t.TempDir(), "short.db")
+	if err := os.WriteFile(short, data[:len(data)-int(pageSize)], 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyCopy(ctx, path, stamp, short); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("truncated copy: err = %v, want ErrCorrupt", err)
+	}
+}
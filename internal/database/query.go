@@ -1,43 +1,74 @@
 package database
 
 import (
+	"context"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/rzolkos/web-recap/internal/browser"
 	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/urlutil"
 )
 
-// HistoryQuerier defines the interface for querying browser history
+// HistoryQuerier defines the interface for querying browser history.
+// GetHistory returns one entry per visit, not per URL, so the visit sequence
+// can be rebuilt; the built-in handlers join each browser's visits table.
 type HistoryQuerier interface {
-	GetHistory(startDate, endDate time.Time) ([]models.HistoryEntry, error)
+	GetHistory(ctx context.Context, startDate, endDate time.Time) ([]models.HistoryEntry, error)
+}
+
+// HistoryStreamer is implemented by queriers that can pass entries to a
+// callback, newest first, instead of collecting them
+type HistoryStreamer interface {
+	StreamHistory(ctx context.Context, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error
+}
+
+// collectHistory gathers the entries of a streamer into a slice
+func collectHistory(ctx context.Context, s HistoryStreamer, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+	var entries []models.HistoryEntry
+	err := s.StreamHistory(ctx, startDate, endDate, func(e models.HistoryEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
 }
 
 // NewQuerier creates a new history querier for the given browser
 func NewQuerier(b *browser.Browser) (HistoryQuerier, error) {
-	switch b.Type {
-	case browser.Chrome, browser.Chromium, browser.Edge, browser.Brave, browser.Vivaldi:
+	switch browser.EngineOf(b.Type) {
+	case browser.EngineChromium:
 		return NewChromeHandler(b.Path), nil
-	case browser.Firefox:
+	case browser.EngineGecko:
 		return NewFirefoxHandler(b.Path), nil
-	case browser.Safari:
+	case browser.EngineWebKit:
 		return NewSafariHandler(b.Path), nil
+	case browser.EngineText:
+		return NewTextHandler(b.Path, string(b.Type)), nil
+	case browser.EngineCustom:
+		if h, ok := lookupHandlers(b.Type); ok && h.History != nil {
+			return h.History(b.Path), nil
+		}
+		return nil, ErrUnsupportedBrowser
 	default:
 		return nil, ErrUnsupportedBrowser
 	}
 }
 
 // Query retrieves history entries from a specific browser
-func Query(b *browser.Browser, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+func Query(ctx context.Context, b *browser.Browser, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
 	querier, err := NewQuerier(b)
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := querier.GetHistory(startDate, endDate)
+	entries, err := querier.GetHistory(ctx, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
+	for i := range entries {
+		tagHistoryEntry(&entries[i], b.Type)
+	}
 
 	// Sort by timestamp descending
 	sort.Slice(entries, func(i, j int) bool {
@@ -47,18 +78,65 @@ func Query(b *browser.Browser, startDate, endDate time.Time) ([]models.HistoryEn
 	return entries, nil
 }
 
-// QueryMultipleBrowsers retrieves history from all detected browsers
-func QueryMultipleBrowsers(detector *browser.Detector, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
-	var allEntries []models.HistoryEntry
+// tagHistoryEntry records the browser type, host, and registrable domain on
+// e and gives it its stable ID, unless its querier already set one. The ID
+// uses the browser type rather than e.Browser, which is "chrome" for every
+// Chromium browser, to match the label the archive stores.
+func tagHistoryEntry(e *models.HistoryEntry, t browser.Type) {
+	e.BrowserType = string(t)
+	if e.ID == "" {
+		e.ID = models.HistoryID(string(t), e.URL, e.Timestamp)
+	}
+	SetHost(e)
+}
 
-	detectedBrowsers := detector.Detect()
-	for _, b := range detectedBrowsers {
-		browser := b // Copy to avoid pointer issues
-		entries, err := Query(&browser, startDate, endDate)
-		if err != nil {
-			// Log error but continue with other browsers
-			continue
-		}
+// SetHost sets the host and registrable domain of e from its URL, and the
+// Unicode form and lookalike flag of an internationalized host
+func SetHost(e *models.HistoryEntry) {
+	e.Host = ExtractHost(e.URL)
+	e.RegistrableDomain = RegistrableDomain(e.Host)
+	e.DisplayDomain = urlutil.DisplayHost(e.Domain)
+	e.DisplayURL = urlutil.DisplayURL(e.URL)
+	e.Lookalike = urlutil.Lookalike(e.Host)
+}
+
+// QueryMultipleBrowsers retrieves history from all detected browsers,
+// reading them in parallel. Each browser is reported in the returned
+// sources, in detection order, with the error that caused it to be skipped;
+// the error is only set when ctx is done.
+func QueryMultipleBrowsers(ctx context.Context, detector *browser.Detector, startDate, endDate time.Time) ([]models.HistoryEntry, []models.SourceStatus, error) {
+	detectedBrowsers, err := detector.DetectContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([][]models.HistoryEntry, len(detectedBrowsers))
+	sources := make([]models.SourceStatus, len(detectedBrowsers))
+	var wg sync.WaitGroup
+	for i, b := range detectedBrowsers {
+		sources[i] = models.SourceStatus{Browser: string(b.Type), Name: b.Name, Path: b.Path}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries, err := Query(ctx, &b, startDate, endDate)
+			if err != nil {
+				sources[i].Error = describeError(err)
+				sources[i].Code = ErrorCode(err)
+				return
+			}
+			sources[i].Entries = len(entries)
+			results[i] = entries
+		}()
+	}
+	wg.Wait()
+
+	// Give up once the deadline passes; otherwise failed browsers are skipped
+	if err := ctx.Err(); err != nil {
+		return nil, sources, err
+	}
+
+	var allEntries []models.HistoryEntry
+	for _, entries := range results {
 		allEntries = append(allEntries, entries...)
 	}
 
@@ -67,5 +145,130 @@ func QueryMultipleBrowsers(detector *browser.Detector, startDate, endDate time.T
 		return allEntries[i].Timestamp.After(allEntries[j].Timestamp)
 	})
 
-	return allEntries, nil
+	return allEntries, sources, nil
+}
+
+// Stream passes history entries from a specific browser to fn, newest
+// first. Built-in browsers are read row by row; other queriers are
+// collected and sorted first.
+func Stream(ctx context.Context, b *browser.Browser, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error {
+	querier, err := NewQuerier(b)
+	if err != nil {
+		return err
+	}
+	if s, ok := querier.(HistoryStreamer); ok {
+		return s.StreamHistory(ctx, startDate, endDate, func(e models.HistoryEntry) error {
+			tagHistoryEntry(&e, b.Type)
+			return fn(e)
+		})
+	}
+
+	entries, err := Query(ctx, b, startDate, endDate)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// browserStream is one browser's entries arriving over a channel
+type browserStream struct {
+	entries <-chan models.HistoryEntry
+	done    <-chan error
+	head    models.HistoryEntry
+	status  models.SourceStatus
+}
+
+// StreamMultipleBrowsers passes history from all detected browsers to fn,
+// newest first. The browsers are read concurrently and merged as their
+// rows arrive, so memory stays flat however large the range. Like
+// QueryMultipleBrowsers, a browser that fails is reported in the returned
+// sources rather than as an error.
+func StreamMultipleBrowsers(ctx context.Context, detector *browser.Detector, startDate, endDate time.Time, fn func(models.HistoryEntry) error) ([]models.SourceStatus, error) {
+	detectedBrowsers, err := detector.DetectContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streams := make([]*browserStream, 0, len(detectedBrowsers))
+	for _, b := range detectedBrowsers {
+		entries := make(chan models.HistoryEntry, 64)
+		done := make(chan error, 1)
+		go func() {
+			defer close(entries)
+			done <- Stream(ctx, &b, startDate, endDate, func(e models.HistoryEntry) error {
+				select {
+				case entries <- e:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}()
+		streams = append(streams, &browserStream{
+			entries: entries,
+			done:    done,
+			status:  models.SourceStatus{Browser: string(b.Type), Name: b.Name, Path: b.Path},
+		})
+	}
+
+	sources := make([]models.SourceStatus, len(streams))
+	var open []int
+	// finish records how a browser's stream ended
+	finish := func(i int) {
+		s := streams[i]
+		if err := <-s.done; err != nil {
+			s.status.Error = describeError(err)
+			s.status.Code = ErrorCode(err)
+		}
+		sources[i] = s.status
+	}
+	for i, s := range streams {
+		if e, ok := <-s.entries; ok {
+			s.head = e
+			open = append(open, i)
+		} else {
+			finish(i)
+		}
+	}
+
+	for len(open) > 0 {
+		// Emit the newest head among the open streams
+		newest := 0
+		for k := 1; k < len(open); k++ {
+			if streams[open[k]].head.Timestamp.After(streams[open[newest]].head.Timestamp) {
+				newest = k
+			}
+		}
+		s := streams[open[newest]]
+		if err := fn(s.head); err != nil {
+			cancel()
+			for _, i := range open {
+				for range streams[i].entries {
+				}
+				finish(i)
+			}
+			return sources, err
+		}
+		s.status.Entries++
+
+		if e, ok := <-s.entries; ok {
+			s.head = e
+		} else {
+			finish(open[newest])
+			open = append(open[:newest], open[newest+1:]...)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return sources, err
+	}
+	return sources, nil
 }
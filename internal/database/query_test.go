@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// historyFunc adapts a function to HistoryQuerier
+type historyFunc func(ctx context.Context, start, end time.Time) ([]models.HistoryEntry, error)
+
+func (f historyFunc) GetHistory(ctx context.Context, start, end time.Time) ([]models.HistoryEntry, error) {
+	return f(ctx, start, end)
+}
+
+func TestQueryMultipleBrowsersReportsSources(t *testing.T) {
+	now := time.Now().UTC()
+	ok := historyFunc(func(context.Context, time.Time, time.Time) ([]models.HistoryEntry, error) {
+		return []models.HistoryEntry{{URL: "https://go.dev/", Timestamp: now}, {URL: "https://pkg.go.dev/", Timestamp: now}}, nil
+	})
+	denied := historyFunc(func(context.Context, time.Time, time.Time) ([]models.HistoryEntry, error) {
+		return nil, fmt.Errorf("open History.db: %w", os.ErrPermission)
+	})
+
+	dir := t.TempDir()
+	okPath := filepath.Join(dir, "ok.db")
+	deniedPath := filepath.Join(dir, "denied.db")
+	for _, p := range []string{okPath, deniedPath} {
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RegisterHandlers(browser.Definition{Type: "srcok", Engine: browser.EngineCustom, Paths: []string{okPath}},
+		Handlers{History: func(string) HistoryQuerier { return ok }}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterHandlers(browser.Definition{Type: "srcdenied", Engine: browser.EngineCustom, Paths: []string{deniedPath}},
+		Handlers{History: func(string) HistoryQuerier { return denied }}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, sources, err := QueryMultipleBrowsers(context.Background(), browser.NewDetector(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) < 2 {
+		t.Errorf("entries = %d, want at least 2", len(entries))
+	}
+
+	byType := make(map[string]models.SourceStatus)
+	for _, s := range sources {
+		byType[s.Browser] = s
+	}
+	if s := byType["srcok"]; s.Entries != 2 || s.Error != "" || s.Path != okPath {
+		t.Errorf("srcok source = %+v", s)
+	}
+	if s := byType["srcdenied"]; s.Entries != 0 || s.Error == "" || s.Code != CodePermissionDenied {
+		t.Errorf("srcdenied source = %+v, want a permission error", s)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&os.PathError{Op: "open", Path: "History", Err: os.ErrPermission}, CodePermissionDenied},
+		{fmt.Errorf("copy: %w", os.ErrNotExist), CodeNotFound},
+		{browser.ErrDatabaseNotFound, CodeNotFound},
+		{fmt.Errorf("no such table: urls"), CodeQueryFailed},
+	}
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestStreamMultipleBrowsersMergesNewestFirst(t *testing.T) {
+	base := time.Date(2025, 12, 15, 9, 0, 0, 0, time.UTC)
+	handler := func(name string, minutes ...int) Handlers {
+		return Handlers{History: func(string) HistoryQuerier {
+			return historyFunc(func(context.Context, time.Time, time.Time) ([]models.HistoryEntry, error) {
+				var entries []models.HistoryEntry
+				for _, m := range minutes {
+					entries = append(entries, models.HistoryEntry{URL: fmt.Sprintf("https://%s/%d", name, m), Browser: name, Timestamp: base.Add(time.Duration(m) * time.Minute)})
+				}
+				return entries, nil
+			})
+		}}
+	}
+
+	dir := t.TempDir()
+	for name, minutes := range map[string][]int{"streama": {1, 4, 5}, "streamb": {2, 3, 6}} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		def := browser.Definition{Type: browser.Type(name), Engine: browser.EngineCustom, Paths: []string{path}}
+		if err := RegisterHandlers(def, handler(name, minutes...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []models.HistoryEntry
+	sources, err := StreamMultipleBrowsers(context.Background(), browser.NewDetector(), time.Time{}, time.Time{}, func(e models.HistoryEntry) error {
+		got = append(got, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < len(got); i++ {
+		if got[i].Timestamp.After(got[i-1].Timestamp) {
+			t.Fatalf("entry %d (%s) is newer than entry %d (%s)", i, got[i].URL, i-1, got[i-1].URL)
+		}
+	}
+	counts := make(map[string]int)
+	for _, s := range sources {
+		counts[s.Browser] = s.Entries
+	}
+	if counts["streama"] != 3 || counts["streamb"] != 3 {
+		t.Errorf("source counts = %v", counts)
+	}
+
+	// Stop early when fn fails
+	stop := fmt.Errorf("stop")
+	n := 0
+	_, err = StreamMultipleBrowsers(context.Background(), browser.NewDetector(), time.Time{}, time.Time{}, func(models.HistoryEntry) error {
+		n++
+		if n == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 2 {
+		t.Errorf("err = %v after %d entries, want stop after 2", err, n)
+	}
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+)
+
+// Handlers reads data for a browser registered with the custom engine. Each
+// function receives the path resolved from the browser definition.
+type Handlers struct {
+	History   func(path string) HistoryQuerier
+	Bookmarks func(path string) BookmarkQuerier
+}
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = make(map[browser.Type]Handlers)
+)
+
+// RegisterHandlers registers a browser with the custom engine and the
+// handlers that read it
+func RegisterHandlers(def browser.Definition, h Handlers) error {
+	if def.Engine != browser.EngineCustom {
+		return fmt.Errorf("browser %s: handlers can only be registered for the custom engine", def.Type)
+	}
+	if h.History == nil {
+		return fmt.Errorf("browser %s: a history handler is required", def.Type)
+	}
+	if err := browser.Register(def); err != nil {
+		return err
+	}
+
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[def.Type] = h
+	return nil
+}
+
+func lookupHandlers(browserType browser.Type) (Handlers, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	h, ok := handlers[browserType]
+	return h, ok
+}
@@ -1,9 +1,8 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"io"
-	"os"
 	"runtime"
 	"time"
 
@@ -24,46 +23,81 @@ func NewSafariHandler(dbPath string) *SafariHandler {
 }
 
 // GetHistory retrieves history entries from Safari
-func (h *SafariHandler) GetHistory(startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+func (h *SafariHandler) GetHistory(ctx context.Context, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+	return collectHistory(ctx, h, startDate, endDate)
+}
+
+// StreamHistory passes Safari history entries to fn, newest first, without
+// holding them in memory
+func (h *SafariHandler) StreamHistory(ctx context.Context, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error {
 	// Safari is only available on macOS
 	if runtime.GOOS != "darwin" {
-		return nil, ErrSafariNotAvailable
+		return ErrSafariNotAvailable
 	}
 
 	// Copy database to temp location to avoid locking issues
-	tempDB, err := h.copyDatabase()
+	tempDB, release, err := h.copyDatabase(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer os.Remove(tempDB)
+	defer release()
 
 	db, err := sql.Open("sqlite", tempDB)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer db.Close()
 
-	// Prepare date filters
-	// Query history_visits joined with history_items to get individual visit records
-	// (not just the last visit per URL)
-	var query string
-	var args []interface{}
+	return streamSafariHistory(ctx, db, startDate, endDate, fn)
+}
 
-	if !startDate.IsZero() || !endDate.IsZero() {
-		query = `
+// streamSafariHistory queries history_visits joined with history_items for
+// individual visit records (not just the last visit per URL), newest first.
+// Safari's visit_time is fractional, so it is truncated to whole seconds in
+// the query. Visits synced from another device and the redirects leading to
+// a visit are read when the database records them.
+func streamSafariHistory(ctx context.Context, db *sql.DB, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error {
+	// Safari uses seconds since 2001-01-01
+	const safariEpochDiff = 978307200
+
+	redirectSource, origin := "NULL", "NULL"
+	if columnExists(db, "history_visits", "redirect_source") {
+		redirectSource = "hv.redirect_source"
+	}
+	if columnExists(db, "history_visits", "origin") {
+		origin = "hv.origin"
+	}
+
+	var redirects map[int64]safariRedirect
+	if redirectSource != "NULL" {
+		var since int64
+		if !startDate.IsZero() {
+			// A chain can start just before the range
+			since = startDate.Unix() - safariEpochDiff - maxRedirectChainSeconds
+		}
+		var err error
+		if redirects, err = safariRedirects(ctx, db, since); err != nil {
+			return err
+		}
+	}
+
+	// Prepare date filters
+	query := `
 		SELECT
-			hv.visit_time,
+			CAST(hv.visit_time AS INTEGER),
 			hi.url,
 			COALESCE(hv.title, hi.url) as title,
-			hi.visit_count
+			hi.visit_count,
+			` + redirectSource + `,
+			` + origin + `
 		FROM history_visits hv
 		JOIN history_items hi ON hv.history_item = hi.id
 		WHERE hv.visit_time > 0
 		`
+	var args []interface{}
 
+	if !startDate.IsZero() || !endDate.IsZero() {
 		if !startDate.IsZero() {
-			// Safari uses seconds since 2001-01-01
-			const safariEpochDiff = 978307200
 			safariStart := startDate.Unix() - safariEpochDiff
 			query += ` AND hv.visit_time >= ?`
 			args = append(args, safariStart)
@@ -75,8 +109,6 @@ func (h *SafariHandler) GetHistory(startDate, endDate time.Time) ([]models.Histo
 			if endDate.Hour() == 0 && endDate.Minute() == 0 && endDate.Second() == 0 {
 				endTimestamp += 86400
 			}
-			// Safari uses seconds since 2001-01-01
-			const safariEpochDiff = 978307200
 			safariEnd := endTimestamp - safariEpochDiff
 			query += ` AND hv.visit_time < ?`
 			args = append(args, safariEnd)
@@ -84,34 +116,25 @@ func (h *SafariHandler) GetHistory(startDate, endDate time.Time) ([]models.Histo
 
 		query += ` ORDER BY hv.visit_time DESC`
 	} else {
-		query = `
-		SELECT
-			hv.visit_time,
-			hi.url,
-			COALESCE(hv.title, hi.url) as title,
-			hi.visit_count
-		FROM history_visits hv
-		JOIN history_items hi ON hv.history_item = hi.id
-		WHERE hv.visit_time > 0
+		query += `
 		ORDER BY hv.visit_time DESC
 		LIMIT 10000
 		`
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var entries []models.HistoryEntry
-
 	for rows.Next() {
 		var safariTime int64
 		var url, title string
 		var visitCount int
+		var source, visitOrigin sql.NullInt64
 
-		if err := rows.Scan(&safariTime, &url, &title, &visitCount); err != nil {
+		if err := rows.Scan(&safariTime, &url, &title, &visitCount, &source, &visitOrigin); err != nil {
 			continue
 		}
 
@@ -120,38 +143,87 @@ func (h *SafariHandler) GetHistory(startDate, endDate time.Time) ([]models.Histo
 			continue
 		}
 
-		entries = append(entries, models.HistoryEntry{
+		if err := fn(models.HistoryEntry{
 			Timestamp:  timestamp,
 			URL:        url,
 			Title:      title,
 			VisitCount: visitCount,
 			Domain:     ExtractDomain(url),
 			Browser:    "safari",
-		})
+			Redirects:  redirectChain(redirects, source),
+			Synced:     visitOrigin.Int64 == safariOriginSynced,
+		}); err != nil {
+			return err
+		}
 	}
 
-	return entries, rows.Err()
+	return rows.Err()
 }
 
-// copyDatabase copies the Safari database to a temporary file
-func (h *SafariHandler) copyDatabase() (string, error) {
-	src, err := os.Open(h.dbPath)
+// safariOriginSynced is history_visits.origin for a visit made on another
+// device and synced through iCloud; 0 is a visit made on this Mac
+const safariOriginSynced = 1
+
+// maxRedirectChainSeconds is how long before the start of the range a
+// redirect chain ending in it is still read
+const maxRedirectChainSeconds = 60
+
+// maxRedirectChain caps the redirects followed back from one visit, in
+// case the table links visits in a loop
+const maxRedirectChain = 20
+
+// safariRedirect is a visit that redirected to another: its URL and the
+// visit that redirected to it, if any
+type safariRedirect struct {
+	url    string
+	source sql.NullInt64
+}
+
+// safariRedirects returns the visits since the given Safari time that
+// redirected to another visit, by visit id
+func safariRedirects(ctx context.Context, db *sql.DB, since int64) (map[int64]safariRedirect, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT hv.id, hi.url, hv.redirect_source
+		FROM history_visits hv
+		JOIN history_items hi ON hv.history_item = hi.id
+		WHERE hv.redirect_destination IS NOT NULL AND hv.visit_time >= ?`, since)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer src.Close()
+	defer rows.Close()
 
-	dst, err := os.CreateTemp("", "web-recap-safari-*.db")
-	if err != nil {
-		return "", err
+	redirects := make(map[int64]safariRedirect)
+	for rows.Next() {
+		var id int64
+		var r safariRedirect
+		if err := rows.Scan(&id, &r.url, &r.source); err != nil {
+			continue
+		}
+		redirects[id] = r
 	}
-	tmpFile := dst.Name()
-	defer dst.Close()
+	return redirects, rows.Err()
+}
 
-	if _, err := io.Copy(dst, src); err != nil {
-		os.Remove(tmpFile)
-		return "", err
+// redirectChain follows redirect sources back from a visit and returns their
+// URLs, the first redirect first
+func redirectChain(redirects map[int64]safariRedirect, source sql.NullInt64) []string {
+	var chain []string
+	for source.Valid && len(chain) < maxRedirectChain {
+		r, ok := redirects[source.Int64]
+		if !ok {
+			break
+		}
+		chain = append(chain, r.url)
+		source = r.source
 	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
 
-	return tmpFile, nil
+// copyDatabase returns a copy of the Safari database and a func that
+// releases it
+func (h *SafariHandler) copyDatabase(ctx context.Context) (string, func(), error) {
+	return temp.acquire(ctx, h.dbPath, "web-recap-safari-*.db")
 }
@@ -1,12 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/rzolkos/web-recap/internal/models"
 	_ "modernc.org/sqlite"
 )
 
@@ -18,7 +20,7 @@ func TestSafariHandlerGetHistoryReadsVisits(t *testing.T) {
 	dbPath := createSafariHistoryDB(t)
 	h := NewSafariHandler(dbPath)
 
-	entries, err := h.GetHistory(time.Time{}, time.Time{})
+	entries, err := h.GetHistory(context.Background(), time.Time{}, time.Time{})
 	if err != nil {
 		t.Fatalf("GetHistory() error = %v", err)
 	}
@@ -57,7 +59,7 @@ func TestSafariHandlerGetHistoryFiltersByDateRange(t *testing.T) {
 	start := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
 	end := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
 
-	entries, err := h.GetHistory(start, end)
+	entries, err := h.GetHistory(context.Background(), start, end)
 	if err != nil {
 		t.Fatalf("GetHistory() error = %v", err)
 	}
@@ -97,3 +99,90 @@ func createSafariHistoryDB(t *testing.T) string {
 
 	return dbPath
 }
+
+func TestQueryCloudTabs(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "CloudTabs.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE cloud_tab_devices (device_uuid TEXT PRIMARY KEY, device_name TEXT)`,
+		`CREATE TABLE cloud_tabs (tab_uuid TEXT PRIMARY KEY, device_uuid TEXT, title TEXT, url TEXT)`,
+		`INSERT INTO cloud_tab_devices VALUES ('d1', 'iPhone'), ('d2', 'iPad')`,
+		`INSERT INTO cloud_tabs VALUES
+			('t1', 'd1', 'Go', 'https://go.dev/'),
+			('t2', 'd2', NULL, 'https://www.example.com/a'),
+			('t3', 'd1', 'Empty', '')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	entries, err := QueryCloudTabs(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("QueryCloudTabs() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 tabs, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Device != "iPad" || entries[0].Domain != "www.example.com" || entries[0].Title != "" {
+		t.Errorf("first tab = %+v", entries[0])
+	}
+	if entries[1].Device != "iPhone" || entries[1].Title != "Go" || entries[1].Browser != "safari" {
+		t.Errorf("second tab = %+v", entries[1])
+	}
+}
+
+func TestStreamSafariHistoryRedirectsAndOrigin(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "History.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Safari stores fractional seconds since 2001-01-01
+	for _, stmt := range []string{
+		`CREATE TABLE history_items (id INTEGER PRIMARY KEY, url TEXT NOT NULL, visit_count INTEGER NOT NULL)`,
+		`CREATE TABLE history_visits (id INTEGER PRIMARY KEY, history_item INTEGER NOT NULL, visit_time REAL NOT NULL, title TEXT,
+			redirect_source INTEGER, redirect_destination INTEGER, origin INTEGER NOT NULL DEFAULT 0)`,
+		`INSERT INTO history_items VALUES (1, 'http://example.com/', 4), (2, 'https://example.com/', 4), (3, 'https://www.example.com/', 9), (4, 'https://news.example/', 1)`,
+		`INSERT INTO history_visits VALUES
+			(1, 1, 790171200.25, NULL, NULL, 2, 0),
+			(2, 2, 790171200.5, NULL, 1, 3, 0),
+			(3, 3, 790171201.75, 'Example', 2, NULL, 0),
+			(4, 4, 790174800.125, 'News', NULL, NULL, 1)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var entries []models.HistoryEntry
+	err = streamSafariHistory(context.Background(), db, time.Time{}, time.Time{}, func(e models.HistoryEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+
+	news, final := entries[0], entries[1]
+	if !news.Synced || news.URL != "https://news.example/" {
+		t.Errorf("newest entry = %+v, want the synced news visit", news)
+	}
+	if want := time.Date(2026, 1, 15, 13, 0, 0, 0, time.UTC); !news.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", news.Timestamp, want)
+	}
+	if final.Synced || len(final.Redirects) != 2 || final.Redirects[0] != "http://example.com/" || final.Redirects[1] != "https://example.com/" {
+		t.Errorf("final visit redirects = %v, want http then https example.com", final.Redirects)
+	}
+	if len(entries[2].Redirects) != 1 || len(entries[3].Redirects) != 0 {
+		t.Errorf("chain visits redirects = %v, %v", entries[2].Redirects, entries[3].Redirects)
+	}
+}
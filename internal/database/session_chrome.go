@@ -4,13 +4,16 @@ package database
 // Adapted from https://github.com/lemnos/chrome-session-dump
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 	"unicode/utf16"
 
 	"github.com/rzolkos/web-recap/internal/browser"
@@ -27,10 +30,20 @@ const (
 	kCommandTabClosed                  = 16
 	kCommandWindowClosed               = 17
 	kCommandSetActiveWindow            = 20
+	kCommandLastActiveTime             = 21
 	kCommandSetTabGroup                = 25
 	kCommandSetTabGroupMetadata2       = 27
 )
 
+// MaxSessionSize is the largest session file parsed, in bytes; larger files
+// are refused rather than read. 0 means no limit.
+var MaxSessionSize int64 = 64 << 20
+
+// maxTabHistory is how many navigations are kept per tab. Only the current
+// one is reported, but the current index can move back to an earlier
+// navigation later in the file, so a few around it are kept.
+const maxTabHistory = 8
+
 // Internal structures for parsing
 type tabGroup struct {
 	high uint64
@@ -59,6 +72,7 @@ type sessionTab struct {
 	deleted           bool
 	currentHistoryIdx uint32
 	group             *tabGroup
+	lastActive        time.Time
 }
 
 // SessionParser holds the state for parsing a session file
@@ -133,20 +147,40 @@ func readUint64(r io.Reader) (uint64, error) {
 		uint64(b[3])<<24 | uint64(b[2])<<16 | uint64(b[1])<<8 | uint64(b[0]), nil
 }
 
+// readBytes reads n bytes. From a *bytes.Buffer they are returned in place
+// rather than copied, so a length read from a corrupt file cannot allocate
+// more than the command holds.
+func readBytes(r io.Reader, n uint64) ([]byte, error) {
+	if buf, ok := r.(*bytes.Buffer); ok {
+		if uint64(buf.Len()) < n {
+			buf.Reset()
+			return nil, io.ErrUnexpectedEOF
+		}
+		return buf.Next(int(n)), nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// pickleSize rounds n up to Chrome's 32-bit alignment of pickled data
+func pickleSize(n uint64) uint64 {
+	if n%4 != 0 {
+		n += 4 - n%4
+	}
+	return n
+}
+
 func readString(r io.Reader) (string, error) {
 	sz, err := readUint32(r)
 	if err != nil {
 		return "", err
 	}
 
-	// Chrome 32-bit aligns pickled data
-	rsz := sz
-	if rsz%4 != 0 {
-		rsz += 4 - (rsz % 4)
-	}
-
-	b := make([]byte, rsz)
-	if _, err := io.ReadFull(r, b); err != nil {
+	b, err := readBytes(r, pickleSize(uint64(sz)))
+	if err != nil {
 		return "", err
 	}
 
@@ -159,88 +193,164 @@ func readString16(r io.Reader) (string, error) {
 		return "", err
 	}
 
-	rsz := sz * 2
-	if rsz%4 != 0 {
-		rsz += 4 - (rsz % 4)
-	}
-
-	b := make([]byte, rsz)
-	if _, err := io.ReadFull(r, b); err != nil {
+	b, err := readBytes(r, pickleSize(uint64(sz)*2))
+	if err != nil {
 		return "", err
 	}
 
-	var s []uint16
-	for i := 0; i < int(sz*2); i += 2 {
-		s = append(s, uint16(b[i+1])<<8|uint16(b[i]))
+	s := make([]uint16, sz)
+	for i := range s {
+		s[i] = uint16(b[2*i+1])<<8 | uint16(b[2*i])
 	}
 
 	return string(utf16.Decode(s)), nil
 }
 
 // parseSessionFile parses a Chrome SNSS session file and returns tab entries
-func parseSessionFile(path string, browserName string) ([]models.TabEntry, error) {
+// and the number of commands skipped as unreadable. A malformed command is
+// skipped, and a truncated one at the end (the browser was mid-write) ends
+// the file, so an imperfect session still yields the tabs it can.
+func parseSessionFile(ctx context.Context, path string, browserName string) ([]models.TabEntry, int, error) {
 	fh, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open session file: %w", err)
+		return nil, 0, fmt.Errorf("failed to open session file: %w", err)
 	}
 	defer fh.Close()
 
+	if info, err := fh.Stat(); err == nil && MaxSessionSize > 0 && info.Size() > MaxSessionSize {
+		return nil, 0, fmt.Errorf("session file %s is %.1f MB, over the %.1f MB limit",
+			path, float64(info.Size())/(1<<20), float64(MaxSessionSize)/(1<<20))
+	}
+	r := bufio.NewReaderSize(fh, 64<<10)
+
 	// Check magic header
 	var magic [4]byte
-	if _, err := io.ReadFull(fh, magic[:]); err != nil {
-		return nil, fmt.Errorf("failed to read magic header: %w", err)
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, 0, fmt.Errorf("failed to read magic header: %w", err)
 	}
 
 	if magic != [4]byte{0x53, 0x4E, 0x53, 0x53} { // "SNSS"
-		return nil, fmt.Errorf("invalid SNSS file: bad magic header")
+		return nil, 0, fmt.Errorf("invalid SNSS file: bad magic header")
 	}
 
-	ver, err := readUint32(fh)
+	ver, err := readUint32(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read version: %w", err)
+		return nil, 0, fmt.Errorf("failed to read version: %w", err)
 	}
 
 	if ver != 1 && ver != 3 {
-		return nil, fmt.Errorf("unsupported SNSS version: %d", ver)
+		return nil, 0, fmt.Errorf("unsupported SNSS version: %d", ver)
 	}
 
 	parser := newSessionParser()
 
-	// Read commands
+	// Read commands into one reused buffer
+	var data bytes.Buffer
+	skipped := 0
 	for {
-		sz, err := readUint16(fh)
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		sz, err := readUint16(r)
 		if err == io.EOF {
 			break
 		}
+		if truncated(err) {
+			skipped++
+			break
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to read command size: %w", err)
+			return nil, 0, fmt.Errorf("failed to read command size: %w", err)
+		}
+		if sz == 0 {
+			// No type byte; the next command follows directly
+			skipped++
+			continue
 		}
 
-		typ, err := readUint8(fh)
+		typ, err := readUint8(r)
+		if err == nil {
+			data.Reset()
+			_, err = io.CopyN(&data, r, int64(sz)-1)
+		}
+		if truncated(err) {
+			skipped++
+			break
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to read command type: %w", err)
+			return nil, 0, fmt.Errorf("failed to read command: %w", err)
 		}
 
-		buf := make([]byte, int(sz)-1)
-		if _, err := io.ReadFull(fh, buf); err != nil {
-			return nil, fmt.Errorf("failed to read command payload: %w", err)
+		if err := parser.processCommand(typ, &data); err != nil {
+			skipped++
 		}
+	}
+
+	return parser.buildTabEntries(browserName), skipped, nil
+}
+
+// truncated reports whether err means the file ended inside a command
+func truncated(err error) bool {
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// commandReader reads the fields of one command, keeping the first error so
+// a command is only applied when all of it could be read
+type commandReader struct {
+	data *bytes.Buffer
+	err  error
+}
 
-		data := bytes.NewBuffer(buf)
-		parser.processCommand(typ, data)
+func (r *commandReader) uint32() uint32 {
+	if r.err != nil {
+		return 0
 	}
+	v, err := readUint32(r.data)
+	r.err = err
+	return v
+}
 
-	return parser.buildTabEntries(browserName), nil
+func (r *commandReader) uint64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := readUint64(r.data)
+	r.err = err
+	return v
+}
+
+func (r *commandReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	v, err := readString(r.data)
+	r.err = err
+	return v
 }
 
-func (p *SessionParser) processCommand(typ uint8, data *bytes.Buffer) {
+func (r *commandReader) string16() string {
+	if r.err != nil {
+		return ""
+	}
+	v, err := readString16(r.data)
+	r.err = err
+	return v
+}
+
+// processCommand applies one command, or returns an error without applying
+// anything when the command is too short for its fields
+func (p *SessionParser) processCommand(typ uint8, data *bytes.Buffer) error {
+	r := &commandReader{data: data}
 	switch typ {
 	case kCommandUpdateTabNavigation:
-		readUint32(data) // size of the data (again)
-		id, _ := readUint32(data)
-		histIdx, _ := readUint32(data)
-		urlStr, _ := readString(data)
-		title, _ := readString16(data)
+		r.uint32() // size of the data (again)
+		id := r.uint32()
+		histIdx := r.uint32()
+		urlStr := r.string()
+		title := r.string16()
+		if r.err != nil {
+			return r.err
+		}
 
 		t := p.getTab(id)
 
@@ -259,53 +369,122 @@ func (p *SessionParser) processCommand(typ uint8, data *bytes.Buffer) {
 
 		item.url = urlStr
 		item.title = title
+		t.trimHistory()
 
 	case kCommandSetSelectedTabInIndex:
-		id, _ := readUint32(data)
-		idx, _ := readUint32(data)
+		id, idx := r.uint32(), r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.getWindow(id).activeTabIdx = idx
 
 	case kCommandSetTabGroupMetadata2:
-		readUint32(data) // Size
-		high, _ := readUint64(data)
-		low, _ := readUint64(data)
-		name, _ := readString16(data)
+		r.uint32() // Size
+		high, low := r.uint64(), r.uint64()
+		name := r.string16()
+		if r.err != nil {
+			return r.err
+		}
 		p.getGroup(high, low).name = name
 
 	case kCommandSetTabGroup:
-		id, _ := readUint32(data)
-		readUint32(data) // Struct padding
-		high, _ := readUint64(data)
-		low, _ := readUint64(data)
+		id := r.uint32()
+		r.uint32() // Struct padding
+		high, low := r.uint64(), r.uint64()
+		if r.err != nil {
+			return r.err
+		}
 		p.getTab(id).group = p.getGroup(high, low)
 
 	case kCommandSetTabWindow:
-		win, _ := readUint32(data)
-		id, _ := readUint32(data)
+		win, id := r.uint32(), r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.getTab(id).win = win
 
 	case kCommandWindowClosed:
-		id, _ := readUint32(data)
+		id := r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.getWindow(id).deleted = true
 
 	case kCommandTabClosed:
-		id, _ := readUint32(data)
+		id := r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.getTab(id).deleted = true
 
 	case kCommandSetTabIndexInWindow:
-		id, _ := readUint32(data)
-		index, _ := readUint32(data)
+		id, index := r.uint32(), r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.getTab(id).idx = index
 
 	case kCommandSetActiveWindow:
-		id, _ := readUint32(data)
+		id := r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.activeWindow = p.getWindow(id)
 
+	case kCommandLastActiveTime:
+		id := r.uint32()
+		r.uint32() // Struct padding
+		active := r.uint64()
+		if r.err != nil {
+			return r.err
+		}
+		p.getTab(id).lastActive = lastActiveTime(int64(active))
+
 	case kCommandSetSelectedNavigationIndex:
-		id, _ := readUint32(data)
-		idx, _ := readUint32(data)
+		id, idx := r.uint32(), r.uint32()
+		if r.err != nil {
+			return r.err
+		}
 		p.getTab(id).currentHistoryIdx = idx
 	}
+	return nil
+}
+
+// lastActiveTime converts the time a tab was last focused, in microseconds
+// since 1601 like history timestamps. Older Chrome versions stored a
+// monotonic clock instead, which gives a date long past and is dropped.
+func lastActiveTime(us int64) time.Time {
+	t := ConvertChromeTimestamp(us)
+	if t.Year() < 2000 || t.After(time.Now().Add(24*time.Hour)) {
+		return time.Time{}
+	}
+	return t
+}
+
+// trimHistory keeps at most maxTabHistory navigations: the newest one, which
+// is reported when the current index is not found, and those closest to the
+// current index
+func (t *sessionTab) trimHistory() {
+	if len(t.history) <= maxTabHistory {
+		return
+	}
+	newest := 0
+	for i, h := range t.history {
+		if h.idx > t.history[newest].idx {
+			newest = i
+		}
+	}
+	farthest, farthestDist := -1, int64(-1)
+	for i, h := range t.history {
+		dist := int64(h.idx) - int64(t.currentHistoryIdx)
+		if dist < 0 {
+			dist = -dist
+		}
+		if i != newest && dist > farthestDist {
+			farthest, farthestDist = i, dist
+		}
+	}
+	t.history = append(t.history[:farthest], t.history[farthest+1:]...)
 }
 
 func (p *SessionParser) buildTabEntries(browserName string) []models.TabEntry {
@@ -386,10 +565,15 @@ func (p *SessionParser) buildTabEntries(browserName string) []models.TabEntry {
 				Title:    tabTitle,
 				Domain:   domain,
 				Active:   isActiveWindow && idx == int(w.activeTabIdx),
+				Selected: idx == int(w.activeTabIdx),
 				Group:    groupName,
 				WindowID: windowID,
 				Browser:  browserName,
 			}
+			if !t.lastActive.IsZero() {
+				lastActive := t.lastActive
+				entry.LastActive = &lastActive
+			}
 
 			entries = append(entries, entry)
 			idx++
@@ -439,24 +623,31 @@ func findLatestSessionFile(sessionDir string) (string, error) {
 	return latestFile, nil
 }
 
-// QueryTabs queries open tabs from a Chromium-based browser
-func QueryTabs(b *browser.Browser, sessionPath string) ([]models.TabEntry, error) {
+// QueryTabs queries open tabs from a Chromium-based browser. It also
+// returns the number of session commands skipped as unreadable; the tabs are
+// still those that could be read.
+func QueryTabs(ctx context.Context, b *browser.Browser, sessionPath string) ([]models.TabEntry, int, error) {
 	if !browser.IsChromiumBased(b.Type) {
-		return nil, fmt.Errorf("tabs extraction only supported for Chromium-based browsers")
+		return nil, 0, fmt.Errorf("tabs extraction only supported for Chromium-based browsers")
 	}
 
 	sessionFile, err := findLatestSessionFile(sessionPath)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return parseSessionFile(sessionFile, b.Name)
+	return parseSessionFile(ctx, sessionFile, b.Name)
 }
 
-// QueryMultipleBrowsersTabs queries open tabs from all detected Chromium-based browsers
-func QueryMultipleBrowsersTabs(detector *browser.Detector) ([]models.TabEntry, error) {
-	browsers := detector.Detect()
+// QueryMultipleBrowsersTabs queries open tabs from all detected Chromium-based
+// browsers, returning the total number of session commands skipped
+func QueryMultipleBrowsersTabs(ctx context.Context, detector *browser.Detector) ([]models.TabEntry, int, error) {
+	browsers, err := detector.DetectContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
 	var allEntries []models.TabEntry
+	skipped := 0
 
 	for _, b := range browsers {
 		if !browser.IsChromiumBased(b.Type) {
@@ -468,13 +659,17 @@ func QueryMultipleBrowsersTabs(detector *browser.Detector) ([]models.TabEntry, e
 			continue
 		}
 
-		entries, err := QueryTabs(&b, sessionPath)
+		entries, n, err := QueryTabs(ctx, &b, sessionPath)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, 0, ctx.Err()
+			}
 			continue
 		}
 
 		allEntries = append(allEntries, entries...)
+		skipped += n
 	}
 
-	return allEntries, nil
+	return allEntries, skipped, nil
 }
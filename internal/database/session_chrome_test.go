@@ -0,0 +1,203 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// snssWriter builds an SNSS session file command by command
+type snssWriter struct {
+	bytes.Buffer
+}
+
+func newSNSSWriter() *snssWriter {
+	w := &snssWriter{}
+	w.WriteString("SNSS")
+	binary.Write(w, binary.LittleEndian, uint32(3))
+	return w
+}
+
+func (w *snssWriter) command(typ uint8, payload []byte) {
+	binary.Write(w, binary.LittleEndian, uint16(len(payload)+1))
+	w.WriteByte(typ)
+	w.Write(payload)
+}
+
+// ids writes a command whose payload is a list of uint32 values
+func (w *snssWriter) ids(typ uint8, values ...uint32) {
+	var p bytes.Buffer
+	for _, v := range values {
+		binary.Write(&p, binary.LittleEndian, v)
+	}
+	w.command(typ, p.Bytes())
+}
+
+func (w *snssWriter) navigation(tab, idx uint32, url, title string) {
+	var p bytes.Buffer
+	binary.Write(&p, binary.LittleEndian, uint32(0)) // pickle size
+	binary.Write(&p, binary.LittleEndian, tab)
+	binary.Write(&p, binary.LittleEndian, idx)
+	binary.Write(&p, binary.LittleEndian, uint32(len(url)))
+	p.WriteString(url)
+	p.Write(make([]byte, pickleSize(uint64(len(url)))-uint64(len(url))))
+	title16 := utf16.Encode([]rune(title))
+	binary.Write(&p, binary.LittleEndian, uint32(len(title16)))
+	binary.Write(&p, binary.LittleEndian, title16)
+	p.Write(make([]byte, pickleSize(uint64(len(title16))*2)-uint64(len(title16))*2))
+	w.command(kCommandUpdateTabNavigation, p.Bytes())
+}
+
+func (w *snssWriter) save(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Session_1")
+	if err := os.WriteFile(path, w.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSessionFile(t *testing.T) {
+	w := newSNSSWriter()
+	w.ids(kCommandSetActiveWindow, 1)
+	w.ids(kCommandSetSelectedTabInIndex, 1, 1)
+
+	// Tab 10 navigates through 20 pages, then goes back two
+	w.ids(kCommandSetTabWindow, 1, 10)
+	w.ids(kCommandSetTabIndexInWindow, 10, 0)
+	for i := uint32(0); i < 20; i++ {
+		w.navigation(10, i, "https://example.com/"+string(rune('a'+i)), "Page")
+		w.ids(kCommandSetSelectedNavigationIndex, 10, i)
+	}
+	w.ids(kCommandSetSelectedNavigationIndex, 10, 17)
+
+	w.ids(kCommandSetTabWindow, 1, 11)
+	w.ids(kCommandSetTabIndexInWindow, 11, 1)
+	w.navigation(11, 0, "https://go.dev/", "Go – Home")
+
+	w.ids(kCommandSetTabWindow, 1, 12)
+	w.ids(kCommandSetTabIndexInWindow, 12, 2)
+	w.navigation(12, 0, "https://closed.example/", "Closed")
+	w.ids(kCommandTabClosed, 12)
+
+	entries, skipped, err := parseSessionFile(context.Background(), w.save(t), "Chrome")
+	if err != nil || skipped != 0 {
+		t.Fatalf("skipped %d, %v", skipped, err)
+	}
+	var got []string
+	for _, e := range entries {
+		line := e.URL + " " + e.Title
+		if e.Active {
+			line += " (active)"
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"https://example.com/r Page",
+		"https://go.dev/ Go – Home (active)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tabs:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseSessionFileLastActive(t *testing.T) {
+	focused := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	lastActive := func(w *snssWriter, tab uint32, us int64) {
+		var p bytes.Buffer
+		binary.Write(&p, binary.LittleEndian, tab)
+		binary.Write(&p, binary.LittleEndian, uint32(0)) // padding
+		binary.Write(&p, binary.LittleEndian, us)
+		w.command(kCommandLastActiveTime, p.Bytes())
+	}
+
+	w := newSNSSWriter()
+	w.ids(kCommandSetTabWindow, 1, 10)
+	w.navigation(10, 0, "https://go.dev/", "Go")
+	lastActive(w, 10, focused.UnixMicro()+11644473600*1000000)
+	// Older versions wrote a monotonic clock, which is not a date
+	w.ids(kCommandSetTabWindow, 1, 11)
+	w.ids(kCommandSetTabIndexInWindow, 11, 1)
+	w.navigation(11, 0, "https://example.com/", "Example")
+	lastActive(w, 11, 86400*1000000)
+
+	entries, _, err := parseSessionFile(context.Background(), w.save(t), "Chrome")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("entries = %v, %v", entries, err)
+	}
+	if entries[0].LastActive == nil || !entries[0].LastActive.Equal(focused) {
+		t.Errorf("last active = %v, want %v", entries[0].LastActive, focused)
+	}
+	if entries[1].LastActive != nil {
+		t.Errorf("monotonic last active = %v, want none", entries[1].LastActive)
+	}
+}
+
+func TestTrimHistoryKeepsCurrentAndNewest(t *testing.T) {
+	tab := &sessionTab{currentHistoryIdx: 2}
+	for i := uint32(0); i < 20; i++ {
+		tab.history = append(tab.history, &historyItem{idx: i})
+		tab.trimHistory()
+	}
+	if len(tab.history) != maxTabHistory {
+		t.Fatalf("kept %d navigations, want %d", len(tab.history), maxTabHistory)
+	}
+	kept := make(map[uint32]bool)
+	for _, h := range tab.history {
+		kept[h.idx] = true
+	}
+	if !kept[2] || !kept[19] {
+		t.Errorf("kept %v, want the current (2) and newest (19) navigations", kept)
+	}
+}
+
+func TestParseSessionFileLimits(t *testing.T) {
+	w := newSNSSWriter()
+	w.ids(kCommandSetTabWindow, 1, 10)
+	w.navigation(10, 0, "https://go.dev/", "Go")
+	path := w.save(t)
+
+	defer func(limit int64) { MaxSessionSize = limit }(MaxSessionSize)
+	MaxSessionSize = 16
+	if _, _, err := parseSessionFile(context.Background(), path, "Chrome"); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Errorf("oversized file error = %v, want the size limit", err)
+	}
+	MaxSessionSize = 0
+
+	// A string length past the end of its command must not be trusted
+	w = newSNSSWriter()
+	w.ids(kCommandUpdateTabNavigation, 0, 10, 0, 0xffffffff)
+	if _, skipped, err := parseSessionFile(context.Background(), w.save(t), "Chrome"); err != nil || skipped != 1 {
+		t.Errorf("corrupt string length: skipped %d, %v", skipped, err)
+	}
+}
+
+func TestParseSessionFileSkipsCorruptCommands(t *testing.T) {
+	w := newSNSSWriter()
+	w.ids(kCommandSetTabWindow, 1, 10)
+	w.navigation(10, 0, "https://go.dev/", "Go")
+	w.command(kCommandSetTabWindow, []byte{1, 0}) // too short for its fields
+	w.WriteString("\x00\x00")                     // zero-size command
+	w.ids(kCommandSetTabWindow, 1, 11)
+	w.ids(kCommandSetTabIndexInWindow, 11, 1)
+	w.navigation(11, 0, "https://pkg.go.dev/", "Packages")
+	// The browser was writing the last command when the file was read
+	w.Write([]byte{40, 0, kCommandUpdateTabNavigation, 1, 2})
+
+	entries, skipped, err := parseSessionFile(context.Background(), w.save(t), "Chrome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 3 {
+		t.Errorf("skipped = %d, want 3", skipped)
+	}
+	if len(entries) != 2 || entries[0].URL != "https://go.dev/" || entries[1].URL != "https://pkg.go.dev/" {
+		t.Errorf("entries = %+v, want both tabs", entries)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package database
+
+import (
+	"context"
+	"errors"
+)
+
+// lockedByProcess reports whether err is Windows refusing to read a file
+// another process has locked; other systems do not lock files this way
+func lockedByProcess(err error) bool {
+	return false
+}
+
+// copyFromShadow is only available on Windows
+func copyFromShadow(ctx context.Context, path, pattern string) (string, error) {
+	return "", errors.New("shadow copies are only available on Windows")
+}
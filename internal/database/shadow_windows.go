@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Windows errors for a file another process holds open without sharing it,
+// or with a byte-range lock over the part being read. A running Chrome keeps
+// its History file this way, so even a plain copy fails.
+const (
+	errSharingViolation syscall.Errno = 32
+	errLockViolation    syscall.Errno = 33
+)
+
+// lockedByProcess reports whether err is Windows refusing to read a file
+// another process has locked
+func lockedByProcess(err error) bool {
+	return errors.Is(err, errSharingViolation) || errors.Is(err, errLockViolation)
+}
+
+// shadowCopy is a Volume Shadow Copy: a read-only, point-in-time image of a
+// drive that the locks on the live files do not apply to
+type shadowCopy struct {
+	ID     string
+	Device string // \\?\GLOBALROOT\Device\HarddiskVolumeShadowCopyN
+}
+
+// copyFromShadow copies the database at path, with its companions, out of a
+// temporary shadow copy of its drive. The shadow copy is deleted before
+// returning. Creating one needs an elevated (administrator) process.
+func copyFromShadow(ctx context.Context, path, pattern string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	volume := filepath.VolumeName(abs)
+	if len(volume) != 2 || volume[1] != ':' {
+		return "", fmt.Errorf("shadow copies need a local drive, not %s", abs)
+	}
+
+	shadow, err := createShadow(ctx, volume)
+	if err != nil {
+		return "", fmt.Errorf("failed to create shadow copy of %s (run as administrator): %v", volume, err)
+	}
+	defer deleteShadow(shadow.ID)
+
+	return copyToTemp(ctx, shadow.Device+abs[len(volume):], pattern)
+}
+
+// createShadow creates a shadow copy of volume ("C:") through WMI
+func createShadow(ctx context.Context, volume string) (*shadowCopy, error) {
+	script := fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+$r = Invoke-CimMethod -ClassName Win32_ShadowCopy -MethodName Create -Arguments @{Volume = '%s\'; Context = 'ClientAccessible'}
+if ($r.ReturnValue -ne 0) { throw "Win32_ShadowCopy.Create returned $($r.ReturnValue)" }
+$s = Get-CimInstance Win32_ShadowCopy | Where-Object ID -eq $r.ShadowID
+$s.ID
+$s.DeviceObject`, volume)
+
+	out, err := powershell(ctx, script)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Fields(out)
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], `\\?\GLOBALROOT\`) {
+		return nil, fmt.Errorf("unexpected shadow copy details: %q", out)
+	}
+	return &shadowCopy{ID: lines[0], Device: lines[1]}, nil
+}
+
+// deleteShadow deletes the shadow copy with the given ID. It runs even when
+// the query was canceled, so it does not use the query's context.
+func deleteShadow(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	powershell(ctx, fmt.Sprintf(`Get-CimInstance Win32_ShadowCopy | Where-Object ID -eq '%s' | Remove-CimInstance`, id))
+}
+
+// powershell runs script and returns its output, or an error carrying what
+// it printed on failure
+func powershell(ctx context.Context, script string) (string, error) {
+	cmd := exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
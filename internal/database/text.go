@@ -0,0 +1,121 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// TextHandler reads the history file of a terminal browser: w3m's list of
+// URLs, one per line and oldest first, or a Lynx session file, whose lines
+// are a one-letter record type followed by tab-separated fields. Neither
+// records when a page was visited, so every entry is dated when the file was
+// last written, which is when the browser last exited.
+type TextHandler struct {
+	path        string
+	browserName string
+}
+
+// NewTextHandler creates a history handler for a terminal browser's history
+// file, whose entries are labelled browserName
+func NewTextHandler(path, browserName string) *TextHandler {
+	return &TextHandler{path: path, browserName: browserName}
+}
+
+// GetHistory retrieves the history entries of a terminal browser
+func (h *TextHandler) GetHistory(ctx context.Context, startDate, endDate time.Time) ([]models.HistoryEntry, error) {
+	return collectHistory(ctx, h, startDate, endDate)
+}
+
+// StreamHistory passes one entry per URL in the history file to fn, most
+// recent first, when the file was written in [startDate, endDate). VisitCount
+// is the number of times the URL is listed.
+func (h *TextHandler) StreamHistory(ctx context.Context, startDate, endDate time.Time, fn func(models.HistoryEntry) error) error {
+	f, err := os.Open(h.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	written := info.ModTime().UTC().Truncate(time.Second)
+	if (!startDate.IsZero() && written.Before(startDate)) || (!endDate.IsZero() && !written.Before(endDate)) {
+		return nil
+	}
+
+	type page struct {
+		url, title string
+		count      int
+	}
+	var pages []*page
+	seen := make(map[string]*page)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		url, title := parseTextHistoryLine(scanner.Text())
+		if url == "" {
+			continue
+		}
+		if p, ok := seen[url]; ok {
+			p.count++
+			if title != "" {
+				p.title = title
+			}
+			continue
+		}
+		p := &page{url: url, title: title, count: 1}
+		seen[url] = p
+		pages = append(pages, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for i := len(pages) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := pages[i]
+		if err := fn(models.HistoryEntry{
+			Timestamp:  written,
+			URL:        p.url,
+			Title:      p.title,
+			VisitCount: p.count,
+			Domain:     ExtractDomain(p.url),
+			Browser:    h.browserName,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTextHistoryLine returns the URL and title on a history file line. A
+// w3m line is just the URL; in a Lynx session line the URL is the first
+// field that has a scheme and the title is the field after it. Comments,
+// search strings, and other records without a URL yield "".
+func parseTextHistoryLine(line string) (url, title string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	fields := strings.Split(line, "\t")
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if !strings.Contains(field, "://") || strings.ContainsAny(field, " ") {
+			continue
+		}
+		if i+1 < len(fields) {
+			title = strings.TrimSpace(fields[i+1])
+		}
+		return field, title
+	}
+	return "", ""
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTextHistory(t *testing.T, content string, written time.Time) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, written, written); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTextHistoryW3m(t *testing.T) {
+	written := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	path := writeTextHistory(t, "https://news.example/\nhttps://docs.example/go\nhttps://news.example/\n", written)
+
+	entries, err := NewTextHandler(path, "w3m").GetHistory(context.Background(), written.Add(-time.Hour), written.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want one per URL: %+v", len(entries), entries)
+	}
+	// The file lists the newest last
+	if entries[0].URL != "https://docs.example/go" || entries[1].URL != "https://news.example/" {
+		t.Errorf("order = %s, %s", entries[0].URL, entries[1].URL)
+	}
+	if entries[1].VisitCount != 2 || entries[1].Browser != "w3m" || !entries[1].Timestamp.Equal(written) {
+		t.Errorf("news entry = %+v", entries[1])
+	}
+
+	entries, err = NewTextHandler(path, "w3m").GetHistory(context.Background(), written.Add(time.Hour), time.Time{})
+	if err != nil || len(entries) != 0 {
+		t.Errorf("file written before the range: %d entries, %v", len(entries), err)
+	}
+}
+
+func TestTextHistoryLynxSession(t *testing.T) {
+	written := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	session := "# lynx session\n" +
+		"/\tsourdough\n" +
+		"g\thttps://search.example/?q=sourdough\n" +
+		"h\t0\thttps://recipes.example/rye\tRye Loaf\n" +
+		"V\thttps://recipes.example/rye\tRye Loaf\n"
+	entries, err := NewTextHandler(writeTextHistory(t, session, written), "lynx").GetHistory(context.Background(), time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].URL != "https://recipes.example/rye" || entries[0].Title != "Rye Loaf" || entries[0].VisitCount != 2 {
+		t.Errorf("first entry = %+v", entries[0])
+	}
+	if entries[1].URL != "https://search.example/?q=sourdough" || entries[1].Title != "" {
+		t.Errorf("second entry = %+v", entries[1])
+	}
+}
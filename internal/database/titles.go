@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// HistoryTitles maps each URL to the title of its most recent history visit
+// that has a real title (not empty and not the URL itself)
+func HistoryTitles(entries []models.HistoryEntry) map[string]string {
+	titles := make(map[string]string)
+	latest := make(map[string]models.HistoryEntry)
+
+	for _, e := range entries {
+		if !hasTitle(e.Title, e.URL) {
+			continue
+		}
+		if prev, ok := latest[e.URL]; ok && !e.Timestamp.After(prev.Timestamp) {
+			continue
+		}
+		latest[e.URL] = e
+		titles[e.URL] = e.Title
+	}
+
+	return titles
+}
+
+// QueryHistoryTitles reads recent history from all detected browsers and
+// returns the titles for the given URLs, skipping browsers that fail
+func QueryHistoryTitles(ctx context.Context, detector *browser.Detector, urls []string) map[string]string {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	// Without a date range each handler reads its most recent visits
+	entries, _, err := QueryMultipleBrowsers(ctx, detector, time.Time{}, time.Time{})
+	if err != nil {
+		return nil
+	}
+
+	wanted := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		wanted[u] = true
+	}
+	titles := HistoryTitles(entries)
+	for u := range titles {
+		if !wanted[u] {
+			delete(titles, u)
+		}
+	}
+	return titles
+}
+
+// BackfillTabTitles fills in missing tab titles from the history of all
+// browsers, returning the number of tabs updated
+func BackfillTabTitles(ctx context.Context, detector *browser.Detector, tabs []models.TabEntry) int {
+	var missing []string
+	for _, t := range tabs {
+		if !hasTitle(t.Title, t.URL) {
+			missing = append(missing, t.URL)
+		}
+	}
+
+	titles := QueryHistoryTitles(ctx, detector, missing)
+	filled := 0
+	for i := range tabs {
+		if title, ok := titles[tabs[i].URL]; ok && !hasTitle(tabs[i].Title, tabs[i].URL) {
+			tabs[i].Title = title
+			filled++
+		}
+	}
+	return filled
+}
+
+// BackfillBookmarkTitles fills in missing bookmark titles from the history of
+// all browsers, returning the number of bookmarks updated
+func BackfillBookmarkTitles(ctx context.Context, detector *browser.Detector, bookmarks []models.BookmarkEntry) int {
+	var missing []string
+	for _, b := range bookmarks {
+		if !hasTitle(b.Title, b.URL) {
+			missing = append(missing, b.URL)
+		}
+	}
+
+	titles := QueryHistoryTitles(ctx, detector, missing)
+	filled := 0
+	for i := range bookmarks {
+		if title, ok := titles[bookmarks[i].URL]; ok && !hasTitle(bookmarks[i].Title, bookmarks[i].URL) {
+			bookmarks[i].Title = title
+			filled++
+		}
+	}
+	return filled
+}
+
+// hasTitle reports whether title is more than a placeholder for rawURL
+func hasTitle(title, rawURL string) bool {
+	return title != "" && title != rawURL
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestHistoryTitles(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: base, URL: "https://a.example/", Title: "Old title", Browser: "chrome"},
+		{Timestamp: base.Add(time.Hour), URL: "https://a.example/", Title: "New title", Browser: "firefox"},
+		{Timestamp: base.Add(2 * time.Hour), URL: "https://a.example/", Title: "", Browser: "chrome"},
+		{Timestamp: base, URL: "https://b.example/", Title: "https://b.example/", Browser: "safari"},
+		{Timestamp: base, URL: "https://c.example/", Title: "C", Browser: "chrome"},
+	}
+
+	titles := HistoryTitles(entries)
+	tests := []struct {
+		url   string
+		title string
+		ok    bool
+	}{
+		{"https://a.example/", "New title", true},
+		{"https://b.example/", "", false},
+		{"https://c.example/", "C", true},
+		{"https://d.example/", "", false},
+	}
+	for _, tt := range tests {
+		title, ok := titles[tt.url]
+		if ok != tt.ok || title != tt.title {
+			t.Errorf("%s: got %q (%v), want %q (%v)", tt.url, title, ok, tt.title, tt.ok)
+		}
+	}
+}
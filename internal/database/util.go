@@ -1,9 +1,22 @@
 package database
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"golang.org/x/net/publicsuffix"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 // ConvertChromeTimestamp converts Chrome's timestamp format (microseconds since 1601-01-01) to Unix time
@@ -86,6 +99,33 @@ func ExtractDomain(urlStr string) string {
 	return urlStr
 }
 
+// ExtractHost returns the lowercase host name of a URL, without the port
+// that ExtractDomain keeps
+func ExtractHost(urlStr string) string {
+	host := ExtractDomain(urlStr)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// RegistrableDomain returns the registrable domain (eTLD+1) of a host by the
+// public suffix list, e.g. bbc.co.uk for news.bbc.co.uk and bar.github.io
+// for bar.github.io, whose suffix is github.io. IP addresses, single-label
+// hosts such as localhost, and hosts that are a public suffix themselves
+// are returned as they are.
+func RegistrableDomain(host string) string {
+	if host == "" || net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
 // FilterByDateRange filters history entries by date range
 func FilterByDateRange(entries []interface{}, startDate, endDate time.Time) []interface{} {
 	if startDate.IsZero() && endDate.IsZero() {
@@ -108,3 +148,175 @@ func FilterByDateRange(entries []interface{}, startDate, endDate time.Time) []in
 
 	return filtered
 }
+
+// openSnapshot opens the SQLite database at path for reading. The live file
+// is opened read-only and immutable, which skips copying a database that can
+// be hundreds of megabytes and ignores the lock a running browser holds.
+// When that fails because the file is locked, unreadable, or mid-write, or
+// when a write-ahead log or rollback journal holds changes an immutable read
+// would miss or tear, it reads
+// a copy named after pattern instead, shared with other queries of the same
+// database. The returned func closes the database and releases the copy.
+func openSnapshot(ctx context.Context, path, pattern string) (*sql.DB, func(), error) {
+	if !hasPendingLog(path) {
+		db, err := sql.Open("sqlite", immutableURI(path))
+		if err == nil {
+			// Opening is lazy; reading the schema surfaces lock and format errors
+			_, err = db.ExecContext(ctx, "SELECT count(*) FROM sqlite_master")
+			if err == nil {
+				return db, func() { db.Close() }, nil
+			}
+			db.Close()
+		}
+		if ctx.Err() != nil || !retryWithCopy(err) {
+			return nil, nil, err
+		}
+	}
+
+	copyPath, release, err := temp.acquire(ctx, path, pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := sql.Open("sqlite", copyPath)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return db, func() {
+		db.Close()
+		release()
+	}, nil
+}
+
+// immutableURI is the SQLite URI that opens path read-only without locking
+func immutableURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive letters: file:///C:/...
+	}
+	u := url.URL{Scheme: "file", Path: p, RawQuery: "mode=ro&immutable=1"}
+	return u.String()
+}
+
+// hasPendingLog reports whether the database at path has a non-empty
+// write-ahead log, whose changes an immutable open would not see, or a
+// non-empty rollback journal, whose transaction may have left the file half
+// written
+func hasPendingLog(path string) bool {
+	for _, suffix := range []string{"-wal", "-journal"} {
+		if info, err := os.Stat(path + suffix); err == nil && info.Size() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// retryWithCopy reports whether opening a database in place failed in a way
+// a copy can avoid. A copy also turns an unopenable file into a plain file
+// error, such as permission denied.
+func retryWithCopy(err error) bool {
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	switch serr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_CORRUPT:
+		return true
+	}
+	return false
+}
+
+// sqliteCompanions are the files SQLite keeps next to a database. Changes a
+// browser has not checkpointed yet exist only in the -wal file, and the
+// -journal file holds the original pages of a transaction in progress, which
+// SQLite rolls back when the copy is opened.
+var sqliteCompanions = []string{"-wal", "-shm", "-journal"}
+
+// copyToTemp copies the database at path to a new temporary file named after
+// pattern, stopping early when ctx is done. Its companions are copied
+// alongside, so recent visits still in the write-ahead log are not lost and
+// a transaction in progress is rolled back. The files are not copied
+// atomically: a write, checkpoint, or log reset during the copy can leave it
+// without pages or frames, so copySnapshot copies again when the source
+// changed. The copy is checksummed against the bytes read. Callers go
+// through the workspace, which removes the copy.
+func copyToTemp(ctx context.Context, path, pattern string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	tmpFile := dst.Name()
+	defer dst.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(dst, h), &contextReader{ctx: ctx, r: src}); err != nil {
+		removeTemp(tmpFile)
+		return "", err
+	}
+	// The copy must hold exactly the bytes read from the source
+	if sum, err := fileSum(tmpFile); err != nil || !bytes.Equal(sum[:], h.Sum(nil)) {
+		removeTemp(tmpFile)
+		if err == nil {
+			err = &IntegrityError{Path: path, Problems: []string{"copy checksum does not match the bytes read"}}
+		}
+		return "", err
+	}
+
+	for _, suffix := range sqliteCompanions {
+		if err := copyFile(ctx, path+suffix, tmpFile+suffix); err != nil && !os.IsNotExist(err) {
+			removeTemp(tmpFile)
+			return "", err
+		}
+	}
+
+	return tmpFile, nil
+}
+
+// copyFile copies src to a new file dst
+func copyFile(ctx context.Context, src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, &contextReader{ctx: ctx, r: in}); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// removeTemp removes a copy made by copyToTemp along with its companions
+func removeTemp(path string) {
+	os.Remove(path)
+	for _, suffix := range sqliteCompanions {
+		os.Remove(path + suffix)
+	}
+}
+
+// contextReader fails reads once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -1,8 +1,15 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
 )
 
 func TestConvertChromeTimestamp(t *testing.T) {
@@ -164,6 +171,32 @@ func TestExtractDomain(t *testing.T) {
 	}
 }
 
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		url, host, domain string
+	}{
+		{"https://news.bbc.co.uk/sport", "news.bbc.co.uk", "bbc.co.uk"},
+		{"https://foo.co.uk/", "foo.co.uk", "foo.co.uk"},
+		{"https://bar.github.io/blog", "bar.github.io", "bar.github.io"},
+		{"https://api.GitHub.com/repos", "api.github.com", "github.com"},
+		{"https://WWW.Example.com.:443/", "www.example.com", "example.com"},
+		{"https://localhost:8080/app", "localhost", "localhost"},
+		{"http://127.0.0.1:18765/", "127.0.0.1", "127.0.0.1"},
+		{"http://[::1]:8080/", "::1", "::1"},
+		{"https://github.io/", "github.io", "github.io"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		host := ExtractHost(tt.url)
+		if host != tt.host {
+			t.Errorf("ExtractHost(%q) = %q, want %q", tt.url, host, tt.host)
+		}
+		if got := RegistrableDomain(host); got != tt.domain {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", host, got, tt.domain)
+		}
+	}
+}
+
 func TestFilterByDateRange(t *testing.T) {
 	startDate := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(2025, 12, 16, 0, 0, 0, 0, time.UTC)
@@ -232,3 +265,177 @@ func TestWithinHalfOpenRange(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyToTempHonorsContext(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "History")
+	if err := os.WriteFile(src, []byte("sqlite"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tmp, err := copyToTemp(context.Background(), src, "web-recap-test-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(tmp)
+	os.Remove(tmp)
+	if string(data) != "sqlite" {
+		t.Errorf("copy = %q", data)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := copyToTemp(ctx, src, "web-recap-test-*.db"); !errors.Is(err, context.Canceled) {
+		t.Errorf("copyToTemp with canceled context = %v, want context.Canceled", err)
+	}
+	if _, err := NewChromeHandler(src).GetHistory(ctx, time.Time{}, time.Time{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetHistory with canceled context = %v, want context.Canceled", err)
+	}
+}
+
+// snapshotFile returns the file the snapshot database was opened from
+func snapshotFile(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var seq int
+	var name, file string
+	if err := db.QueryRow("PRAGMA database_list").Scan(&seq, &name, &file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestOpenSnapshot(t *testing.T) {
+	ctx := context.Background()
+	path := testutil.NewChromeHistoryDB(t, testutil.ChromeVisit{URL: "https://go.dev/"})
+	live, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+	live.SetMaxOpenConns(1)
+
+	// A running browser holds an exclusive lock; the in-place open ignores it
+	if _, err := live.Exec(`PRAGMA locking_mode = EXCLUSIVE; BEGIN EXCLUSIVE`); err != nil {
+		t.Fatal(err)
+	}
+	db, closeDB, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var url string
+	if err := db.QueryRow("SELECT url FROM urls").Scan(&url); err != nil || url != "https://go.dev/" {
+		t.Errorf("url = %q, %v", url, err)
+	}
+	if file := snapshotFile(t, db); file != path {
+		t.Errorf("opened %s, want the live file %s", file, path)
+	}
+	closeDB()
+	if _, err := live.Exec(`COMMIT; PRAGMA locking_mode = NORMAL`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes still in the write-ahead log need a copy
+	if err := os.WriteFile(path+"-wal", []byte("pending"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, closeDB, err = openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyPath := snapshotFile(t, db)
+	if copyPath == path {
+		t.Error("opened the live file despite a pending WAL")
+	}
+	closeDB()
+	CleanupTemp()
+	if _, err := os.Stat(copyPath); !os.IsNotExist(err) {
+		t.Errorf("copy %s not removed: %v", copyPath, err)
+	}
+
+	// A missing database reports a plain file error
+	_, _, err = openSnapshot(ctx, filepath.Join(t.TempDir(), "missing"), "snapshot-*.db")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing database error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestCopyToTempKeepsWAL(t *testing.T) {
+	ctx := context.Background()
+	path := testutil.NewChromeHistoryDB(t)
+	live, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+	live.SetMaxOpenConns(1)
+
+	// Rows a running browser has not checkpointed live only in the -wal file
+	for _, stmt := range []string{
+		`PRAGMA journal_mode = WAL`,
+		`PRAGMA wal_autocheckpoint = 0`,
+		`INSERT INTO urls (url) VALUES ('https://go.dev/'), ('https://pkg.go.dev/')`,
+	} {
+		if _, err := live.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, closeDB, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM urls").Scan(&count); err != nil || count != 2 {
+		t.Errorf("count = %d, %v; want 2 rows from the WAL", count, err)
+	}
+	copyPath := snapshotFile(t, db)
+	closeDB()
+	CleanupTemp()
+
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if _, err := os.Stat(copyPath + suffix); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", copyPath+suffix, err)
+		}
+	}
+}
+
+func TestOpenSnapshotRollsBackJournal(t *testing.T) {
+	ctx := context.Background()
+	path := testutil.NewChromeHistoryDB(t, testutil.ChromeVisit{URL: "https://go.dev/"})
+	live, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+	live.SetMaxOpenConns(1)
+
+	// A transaction too large for the page cache spills uncommitted pages
+	// into the database file, with their originals in the -journal file
+	for _, stmt := range []string{
+		`PRAGMA journal_mode = DELETE`,
+		`PRAGMA cache_size = 2`,
+		`BEGIN`,
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 5000)
+			INSERT INTO urls (url) SELECT 'https://example.com/' || i || printf('%.100c', 'x') FROM n`,
+	} {
+		if _, err := live.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer live.Exec(`ROLLBACK`)
+	if info, err := os.Stat(path + "-journal"); err != nil || info.Size() == 0 {
+		t.Fatalf("no hot journal: %v", err)
+	}
+
+	db, closeDB, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+	if file := snapshotFile(t, db); file == path {
+		t.Error("opened the live file despite a pending journal")
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM urls").Scan(&count); err != nil || count != 1 {
+		t.Errorf("count = %d, %v; want only the committed row", count, err)
+	}
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// ErrNoSpeedDials is returned for a Vivaldi profile whose bookmarks have no
+// Speed Dial folders
+var ErrNoSpeedDials = errors.New("no Speed Dial folders in this profile's bookmarks")
+
+// vivaldiNode is a node of Vivaldi's Notes file or of its Bookmarks file,
+// which share the layout of Chrome's Bookmarks: folders with children, and
+// leaves with a URL. Vivaldi has written dates both as strings and numbers.
+type vivaldiNode struct {
+	ID        json.RawMessage   `json:"id"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Subject   string            `json:"subject"`
+	Content   string            `json:"content"`
+	URL       string            `json:"url"`
+	DateAdded json.RawMessage   `json:"date_added"`
+	Special   string            `json:"special"`
+	MetaInfo  map[string]string `json:"meta_info"`
+	Children  []vivaldiNode     `json:"children"`
+}
+
+// id returns the node's ID, written as a string or a number
+func (n *vivaldiNode) id() string {
+	return strings.Trim(string(n.ID), `"`)
+}
+
+// added returns the node's creation time, or nil when it has none
+func (n *vivaldiNode) added() *time.Time {
+	t := vivaldiTime(strings.Trim(string(n.DateAdded), `"`))
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// vivaldiTime converts a Vivaldi date: microseconds since 1601 like Chrome,
+// or in older Notes files milliseconds or seconds since 1970
+func vivaldiTime(s string) time.Time {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case v > 1e16:
+		return ConvertChromeTimestamp(v)
+	case v > 1e11:
+		return time.UnixMilli(v).UTC()
+	default:
+		return time.Unix(v, 0).UTC()
+	}
+}
+
+// readVivaldiFile decodes a Notes or Bookmarks file from a Vivaldi profile
+func readVivaldiFile(ctx context.Context, path string, v any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// GetVivaldiNotes reads the notes in the Notes file of a Vivaldi profile
+// directory, in the order of the Notes panel. Notes in the trash are left
+// out.
+func GetVivaldiNotes(ctx context.Context, profileDir string) ([]models.VivaldiNote, error) {
+	var root vivaldiNode
+	if err := readVivaldiFile(ctx, filepath.Join(profileDir, "Notes"), &root); err != nil {
+		return nil, err
+	}
+
+	var notes []models.VivaldiNote
+	var walk func(n *vivaldiNode, folder string)
+	walk = func(n *vivaldiNode, folder string) {
+		for i := range n.Children {
+			c := &n.Children[i]
+			if c.Special == "trash" || c.Type == "trash" {
+				continue
+			}
+			switch {
+			case c.Type == "folder" || (c.Type == "" && len(c.Children) > 0):
+				path := c.Subject
+				if folder != "" {
+					path = folder + "/" + c.Subject
+				}
+				walk(c, path)
+			case c.Type == "note" || c.Type == "":
+				note := models.VivaldiNote{
+					ID:        c.id(),
+					Title:     c.Subject,
+					Content:   c.Content,
+					URL:       c.URL,
+					Folder:    folder,
+					DateAdded: c.added(),
+				}
+				if c.URL != "" {
+					note.Domain = ExtractDomain(c.URL)
+				}
+				notes = append(notes, note)
+			}
+		}
+	}
+	walk(&root, "")
+	return notes, nil
+}
+
+// GetVivaldiSpeedDials reads the Speed Dial pages of a Vivaldi profile
+// directory. Vivaldi keeps them in the Bookmarks file as folders marked
+// Speeddial in their meta_info; dials in a folder inside one of those keep
+// the folder's name.
+func GetVivaldiSpeedDials(ctx context.Context, profileDir string) ([]models.SpeedDialGroup, error) {
+	var file struct {
+		Roots map[string]vivaldiNode `json:"roots"`
+	}
+	if err := readVivaldiFile(ctx, filepath.Join(profileDir, "Bookmarks"), &file); err != nil {
+		return nil, err
+	}
+
+	var groups []models.SpeedDialGroup
+	var walk func(n *vivaldiNode, path string)
+	walk = func(n *vivaldiNode, path string) {
+		for i := range n.Children {
+			c := &n.Children[i]
+			if c.Type != "folder" {
+				continue
+			}
+			sub := path + "/" + c.Name
+			if c.MetaInfo["Speeddial"] == "true" {
+				group := models.SpeedDialGroup{Name: c.Name, Path: sub}
+				speedDials(c, "", &group)
+				groups = append(groups, group)
+				continue
+			}
+			walk(c, sub)
+		}
+	}
+	// Same root order as bookmarks; Vivaldi's trash root is left out
+	for _, key := range []string{"bookmark_bar", "other", "synced"} {
+		if root, ok := file.Roots[key]; ok {
+			walk(&root, chromeRoots[key])
+		}
+	}
+	if len(groups) == 0 {
+		return nil, ErrNoSpeedDials
+	}
+	return groups, nil
+}
+
+// speedDials adds the dials in folder n to group, with folder the path of
+// the folder below the Speed Dial page
+func speedDials(n *vivaldiNode, folder string, group *models.SpeedDialGroup) {
+	for i := range n.Children {
+		c := &n.Children[i]
+		switch c.Type {
+		case "folder":
+			sub := c.Name
+			if folder != "" {
+				sub = folder + "/" + c.Name
+			}
+			speedDials(c, sub, group)
+		case "url":
+			if c.URL == "" {
+				continue
+			}
+			group.Dials = append(group.Dials, models.SpeedDial{
+				Title:     c.Name,
+				Nickname:  c.MetaInfo["Nickname"],
+				URL:       c.URL,
+				Domain:    ExtractDomain(c.URL),
+				Folder:    folder,
+				DateAdded: c.added(),
+			})
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetVivaldiNotes(t *testing.T) {
+	dir := t.TempDir()
+	added := time.Date(2025, 2, 21, 0, 0, 0, 0, time.UTC)
+	notes := `{
+		"checksum": "x",
+		"children": [
+			{"id": "2", "type": "note", "subject": "Recipe", "content": "Use rye", "url": "https://recipes.example/rye",
+			 "date_added": "` + strconv.FormatInt(toChromeTimestamp(added), 10) + `"},
+			{"id": "3", "type": "folder", "subject": "Work", "children": [
+				{"id": "4", "type": "folder", "subject": "Go", "children": [
+					{"id": 5, "type": "note", "subject": "Generics", "content": "constraints", "date_added": 1735732800000}
+				]}
+			]},
+			{"id": "6", "type": "trash", "subject": "Trash", "special": "trash", "children": [
+				{"id": "7", "type": "note", "subject": "Deleted"}
+			]}
+		]
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "Notes"), []byte(notes), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetVivaldiNotes(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d notes, want 2 (trash left out): %+v", len(got), got)
+	}
+	recipe, generics := got[0], got[1]
+	if recipe.Title != "Recipe" || recipe.Domain != "recipes.example" || recipe.Folder != "" {
+		t.Errorf("first note = %+v", recipe)
+	}
+	if recipe.DateAdded == nil || !recipe.DateAdded.Equal(added) {
+		t.Errorf("first note added %v, want %v", recipe.DateAdded, added)
+	}
+	if generics.ID != "5" || generics.Folder != "Work/Go" || generics.URL != "" {
+		t.Errorf("second note = %+v", generics)
+	}
+	if want := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC); generics.DateAdded == nil || !generics.DateAdded.Equal(want) {
+		t.Errorf("second note added %v, want %v", generics.DateAdded, want)
+	}
+}
+
+func TestGetVivaldiSpeedDials(t *testing.T) {
+	dir := t.TempDir()
+	bookmarks := `{
+		"roots": {
+			"bookmark_bar": {"type": "folder", "name": "Bookmarks Bar", "children": [
+				{"type": "folder", "name": "Speed Dial", "meta_info": {"Speeddial": "true"}, "children": [
+					{"type": "url", "name": "Mail", "url": "https://mail.example/", "meta_info": {"Nickname": "m"}},
+					{"type": "folder", "name": "News", "children": [
+						{"type": "url", "name": "Daily", "url": "https://news.example/"}
+					]}
+				]},
+				{"type": "url", "name": "Plain bookmark", "url": "https://plain.example/"}
+			]},
+			"other": {"type": "folder", "name": "Other", "children": []},
+			"trash": {"type": "folder", "name": "Trash", "children": [
+				{"type": "folder", "name": "Old Dials", "meta_info": {"Speeddial": "true"}, "children": []}
+			]}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "Bookmarks"), []byte(bookmarks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := GetVivaldiSpeedDials(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Path != "Bookmarks Bar/Speed Dial" {
+		t.Fatalf("groups = %+v, want the one Speed Dial outside the trash", groups)
+	}
+	dials := groups[0].Dials
+	if len(dials) != 2 {
+		t.Fatalf("got %d dials, want 2", len(dials))
+	}
+	if dials[0].Nickname != "m" || dials[0].Domain != "mail.example" {
+		t.Errorf("first dial = %+v", dials[0])
+	}
+	if dials[1].Folder != "News" || dials[1].Title != "Daily" {
+		t.Errorf("second dial = %+v", dials[1])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Bookmarks"), []byte(`{"roots": {}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetVivaldiSpeedDials(context.Background(), dir); !errors.Is(err, ErrNoSpeedDials) {
+		t.Errorf("err = %v, want ErrNoSpeedDials", err)
+	}
+}
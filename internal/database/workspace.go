@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// workspace tracks the database copies made during one run. A copy is
+// shared by every query of the same database (history, bookmarks, title
+// backfill) until the database changes, and temporary copies are removed by
+// CleanupTemp. With a cache directory, copies are kept there between runs.
+type workspace struct {
+	mu       sync.Mutex
+	cacheDir string
+	copies   map[string]*snapshotCopy // by source database path
+}
+
+// snapshotCopy is a copy of a database and the state of the source it was
+// copied from
+type snapshotCopy struct {
+	path   string
+	source fileStamp
+	cached bool // in the cache directory, kept after the run
+	refs   int
+	stale  bool // replaced by a newer copy; removed once released
+}
+
+// fileStamp identifies a version of a database by the size and
+// modification time of the file, its write-ahead log, and its rollback
+// journal
+type fileStamp struct {
+	Size           int64 `json:"size"`
+	ModTime        int64 `json:"mod_time"`
+	WALSize        int64 `json:"wal_size"`
+	WALModTime     int64 `json:"wal_mod_time"`
+	JournalSize    int64 `json:"journal_size"`
+	JournalModTime int64 `json:"journal_mod_time"`
+}
+
+var temp = &workspace{copies: make(map[string]*snapshotCopy)}
+
+// SetCacheDir keeps database copies in dir between runs, so a database that
+// has not changed since the previous run is not copied again
+func SetCacheDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	temp.mu.Lock()
+	defer temp.mu.Unlock()
+	temp.cacheDir = dir
+	return nil
+}
+
+// CleanupTemp removes the temporary database copies made so far. Copies in
+// the cache directory are kept. It is safe to call more than once.
+func CleanupTemp() {
+	temp.mu.Lock()
+	defer temp.mu.Unlock()
+	for source, c := range temp.copies {
+		if !c.cached {
+			removeTemp(c.path)
+		}
+		delete(temp.copies, source)
+	}
+}
+
+// acquire returns a copy of the database at path, reusing one made earlier
+// in the run (or cached by an earlier run) while the database is unchanged.
+// release must be called once the copy is no longer read.
+func (w *workspace) acquire(ctx context.Context, path, pattern string) (string, func(), error) {
+	stamp, err := stampOf(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	w.mu.Lock()
+	c := w.copies[path]
+	cacheDir := w.cacheDir
+	if c != nil && c.source == stamp {
+		c.refs++
+		w.mu.Unlock()
+		return c.path, w.releaser(c), nil
+	}
+	w.mu.Unlock()
+
+	if cacheDir != "" {
+		c, err = cachedCopy(ctx, cacheDir, path, pattern, stamp)
+	} else {
+		var copyPath string
+		if copyPath, stamp, err = copySnapshot(ctx, path, pattern, stamp); err == nil {
+			c = &snapshotCopy{path: copyPath, source: stamp}
+		}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	w.mu.Lock()
+	if old := w.copies[path]; old != nil && old.path != c.path {
+		old.stale = true
+		if old.refs == 0 && !old.cached {
+			removeTemp(old.path)
+		}
+	}
+	c.refs = 1
+	w.copies[path] = c
+	w.mu.Unlock()
+	return c.path, w.releaser(c), nil
+}
+
+// releaser returns the release func for one use of c
+func (w *workspace) releaser(c *snapshotCopy) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			c.refs--
+			if c.stale && c.refs == 0 && !c.cached {
+				removeTemp(c.path)
+			}
+		})
+	}
+}
+
+// maxCopyAttempts bounds how often a database that keeps changing while it
+// is copied is copied again
+const maxCopyAttempts = 3
+
+// copySnapshot copies the database at path, last seen as stamp, to a
+// temporary file and verifies the copy. The database and its companions are
+// copied one after the other, so a write or checkpoint in the meantime can
+// leave the copy without pages or log frames; when the source changed by the
+// time the copy is done, it is copied again, up to maxCopyAttempts times,
+// after which the last copy that verifies is kept. A copy that fails
+// verification is reported as corrupt. It returns the stamp of the source
+// the copy was made from.
+func copySnapshot(ctx context.Context, path, pattern string, stamp fileStamp) (string, fileStamp, error) {
+	for attempt := 1; ; attempt++ {
+		copyPath, err := copyFileSnapshot(ctx, path, pattern)
+		if err != nil {
+			return "", stamp, err
+		}
+		if now, err := stampOf(path); err == nil && now != stamp && attempt < maxCopyAttempts {
+			removeTemp(copyPath)
+			stamp = now
+			continue
+		}
+		if err := verifyCopy(ctx, path, stamp, copyPath); err != nil {
+			removeTemp(copyPath)
+			return "", stamp, err
+		}
+		return copyPath, stamp, nil
+	}
+}
+
+// copyFileSnapshot copies the database at path to a temporary file, falling
+// back to a shadow copy of the drive where Windows refuses to read it
+func copyFileSnapshot(ctx context.Context, path, pattern string) (string, error) {
+	copyPath, err := copyToTemp(ctx, path, pattern)
+	if err != nil && lockedByProcess(err) {
+		// A running browser on Windows can lock even a plain read
+		var shadowErr error
+		if copyPath, shadowErr = copyFromShadow(ctx, path, pattern); shadowErr != nil {
+			return "", fmt.Errorf("%v; %v", err, shadowErr)
+		}
+		err = nil
+	}
+	return copyPath, err
+}
+
+// cachedCopy returns the copy of path in dir when it was made from the same
+// version of the database, or makes a new one. A cached copy is a single
+// file: its write-ahead log is folded in before it is moved into place.
+func cachedCopy(ctx context.Context, dir, path, pattern string, stamp fileStamp) (*snapshotCopy, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := strings.Replace(pattern, "*", fmt.Sprintf("%x", sum[:8]), 1)
+	copyPath := filepath.Join(dir, name)
+	stampPath := copyPath + ".json"
+
+	var cached fileStamp
+	if data, err := os.ReadFile(stampPath); err == nil && json.Unmarshal(data, &cached) == nil && cached == stamp {
+		if _, err := os.Stat(copyPath); err == nil {
+			return &snapshotCopy{path: copyPath, source: stamp, cached: true}, nil
+		}
+	}
+
+	tempPath, stamp, err := copySnapshot(ctx, path, pattern, stamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkpointCopy(ctx, tempPath); err != nil {
+		removeTemp(tempPath)
+		return nil, fmt.Errorf("failed to prepare cached copy: %v", err)
+	}
+	// Rename within the cache directory, since the temporary directory may
+	// be on another filesystem
+	staged := copyPath + ".tmp"
+	if err := copyFile(ctx, tempPath, staged); err != nil {
+		os.Remove(staged)
+		// Still usable for this run, just not cached
+		return &snapshotCopy{path: tempPath, source: stamp}, nil
+	}
+	removeTemp(tempPath)
+	os.Remove(stampPath)
+	if err := os.Rename(staged, copyPath); err != nil {
+		os.Remove(staged)
+		return nil, fmt.Errorf("failed to store cached copy: %v", err)
+	}
+	if data, err := json.Marshal(stamp); err == nil {
+		os.WriteFile(stampPath, data, 0600)
+	}
+	return &snapshotCopy{path: copyPath, source: stamp, cached: true}, nil
+}
+
+// checkpointCopy folds the write-ahead log of the copy at path into the
+// database file and removes it
+func checkpointCopy(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.ExecContext(ctx, "PRAGMA journal_mode = DELETE")
+	return err
+}
+
+// stampOf returns the current fileStamp of the database at path
+func stampOf(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	s := fileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	if wal, err := os.Stat(path + "-wal"); err == nil {
+		s.WALSize, s.WALModTime = wal.Size(), wal.ModTime().UnixNano()
+	}
+	if journal, err := os.Stat(path + "-journal"); err == nil {
+		s.JournalSize, s.JournalModTime = journal.Size(), journal.ModTime().UnixNano()
+	}
+	return s, nil
+}
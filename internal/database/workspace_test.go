@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+// walDatabase creates a WAL-mode database whose rows stay in the -wal file
+// while the returned connection is open, so openSnapshot has to copy it
+func walDatabase(t *testing.T) (string, *sql.DB) {
+	t.Helper()
+	path := testutil.NewChromeHistoryDB(t)
+	live, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { live.Close() })
+	live.SetMaxOpenConns(1)
+	for _, stmt := range []string{
+		`PRAGMA journal_mode = WAL`,
+		`PRAGMA wal_autocheckpoint = 0`,
+		`INSERT INTO urls (url) VALUES ('https://go.dev/')`,
+	} {
+		if _, err := live.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path, live
+}
+
+func TestWorkspaceReusesCopies(t *testing.T) {
+	t.Cleanup(CleanupTemp)
+	ctx := context.Background()
+	path, live := walDatabase(t)
+
+	db1, close1, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db2, close2, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := snapshotFile(t, db1)
+	if second := snapshotFile(t, db2); second != first {
+		t.Errorf("second query copied again: %s, want %s", second, first)
+	}
+	close1()
+	close2()
+	if _, err := os.Stat(first); err != nil {
+		t.Errorf("copy removed before the end of the run: %v", err)
+	}
+
+	// A changed database is copied again and the old copy removed
+	if _, err := live.Exec(`INSERT INTO urls (url) VALUES ('https://pkg.go.dev/')`); err != nil {
+		t.Fatal(err)
+	}
+	db3, close3, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close3()
+	if snapshotFile(t, db3) == first {
+		t.Error("reused the copy of a changed database")
+	}
+	var count int
+	if err := db3.QueryRow("SELECT COUNT(*) FROM urls").Scan(&count); err != nil || count != 2 {
+		t.Errorf("count = %d, %v; want 2", count, err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("stale copy %s not removed: %v", first, err)
+	}
+}
+
+func TestWorkspaceCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := SetCacheDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		CleanupTemp()
+		temp.cacheDir = ""
+	})
+	ctx := context.Background()
+	path, _ := walDatabase(t)
+
+	db, closeDB, err := openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := snapshotFile(t, db)
+	closeDB()
+	if filepath.Dir(cached) != dir {
+		t.Fatalf("copy %s is not in the cache directory %s", cached, dir)
+	}
+	before, err := os.Stat(cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The next run finds the copy still valid
+	CleanupTemp()
+	db, closeDB, err = openSnapshot(ctx, path, "snapshot-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeDB()
+	if got := snapshotFile(t, db); got != cached {
+		t.Errorf("second run read %s, want the cached %s", got, cached)
+	}
+	after, err := os.Stat(cached)
+	if err != nil || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("cached copy was rewritten: %v", err)
+	}
+	var url string
+	if err := db.QueryRow("SELECT url FROM urls").Scan(&url); err != nil || url != "https://go.dev/" {
+		t.Errorf("url = %q, %v", url, err)
+	}
+}
+
+func TestCopySnapshotRecopiesChangedSource(t *testing.T) {
+	path, live := walDatabase(t)
+	defer live.Close()
+
+	// The source changed since it was last seen, so the first copy may have
+	// missed the change and is made again
+	copyPath, stamp, err := copySnapshot(context.Background(), path, "recopy-*.db", fileStamp{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeTemp(copyPath)
+	if now, err := stampOf(path); err != nil || stamp != now {
+		t.Errorf("stamp = %+v, want the source's %+v", stamp, now)
+	}
+}
@@ -0,0 +1,195 @@
+// Package datasette writes history to a SQLite database laid out for
+// exploring with Datasette
+package datasette
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/stats"
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE sessions (
+	id               INTEGER PRIMARY KEY,
+	started_at       TEXT NOT NULL,
+	ended_at         TEXT NOT NULL,
+	duration_minutes INTEGER NOT NULL,
+	visits           INTEGER NOT NULL,
+	top_domains      TEXT NOT NULL,
+	sample_titles    TEXT NOT NULL
+);
+
+CREATE TABLE visits (
+	id          INTEGER PRIMARY KEY,
+	visited_at  TEXT NOT NULL,
+	date        TEXT NOT NULL,
+	hour        INTEGER NOT NULL,
+	url         TEXT NOT NULL,
+	title       TEXT NOT NULL,
+	domain      TEXT NOT NULL,
+	browser     TEXT NOT NULL,
+	visit_count INTEGER NOT NULL,
+	source      TEXT NOT NULL,
+	session_id  INTEGER REFERENCES sessions (id)
+);
+CREATE INDEX visits_visited_at ON visits (visited_at);
+CREATE INDEX visits_date ON visits (date);
+CREATE INDEX visits_domain ON visits (domain);
+CREATE INDEX visits_browser ON visits (browser);
+CREATE INDEX visits_session_id ON visits (session_id);
+
+CREATE VIRTUAL TABLE visits_fts USING fts5 (
+	title, url,
+	content = 'visits', content_rowid = 'id',
+	tokenize = 'porter unicode61 remove_diacritics 2'
+);
+
+CREATE VIEW daily_counts AS
+SELECT date, count(*) AS visits, count(DISTINCT domain) AS domains,
+	count(DISTINCT url) AS pages, count(DISTINCT session_id) AS sessions
+FROM visits GROUP BY date ORDER BY date;
+
+CREATE VIEW top_domains AS
+SELECT domain, count(*) AS visits, count(DISTINCT url) AS pages,
+	count(DISTINCT date) AS days, min(visited_at) AS first_visit, max(visited_at) AS last_visit
+FROM visits WHERE domain != '' GROUP BY domain ORDER BY visits DESC;
+
+CREATE VIEW hourly_activity AS
+SELECT hour, count(*) AS visits, count(DISTINCT date) AS days
+FROM visits GROUP BY hour ORDER BY hour;
+`
+
+// timeFormat is how times are stored: UTC, so they sort as text
+const timeFormat = "2006-01-02T15:04:05Z"
+
+// Write creates the database at path, replacing any existing file, with
+// the visits, the browsing sessions they form, and views of daily counts,
+// top domains, and activity by hour. Dates and hours are in loc.
+func Write(path string, entries []models.HistoryEntry, loc *time.Location) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %v", path, err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create schema: %v", err)
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	sessions := stats.DetectSessions(sorted, stats.DefaultSessionGap)
+	for i, s := range sessions {
+		domains := make([]string, len(s.TopDomains))
+		for j, d := range s.TopDomains {
+			domains[j] = d.Domain
+		}
+		titles, _ := json.Marshal(s.SampleTitles)
+		_, err := tx.Exec(`INSERT INTO sessions (id, started_at, ended_at, duration_minutes, visits, top_domains, sample_titles)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			i+1, s.Start.UTC().Format(timeFormat), s.End.UTC().Format(timeFormat), s.DurationMinutes, s.Visits,
+			strings.Join(domains, ", "), string(titles))
+		if err != nil {
+			return fmt.Errorf("failed to write session: %v", err)
+		}
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO visits
+		(visited_at, date, hour, url, title, domain, browser, visit_count, source, session_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	// Sessions cover consecutive runs of the sorted visits
+	session := 0
+	for _, e := range sorted {
+		for session < len(sessions)-1 && e.Timestamp.After(sessions[session].End) {
+			session++
+		}
+		local := e.Timestamp.In(loc)
+		_, err := stmt.Exec(e.Timestamp.UTC().Format(timeFormat), local.Format("2006-01-02"), local.Hour(),
+			e.URL, e.Title, e.Domain, e.Browser, e.VisitCount, e.Source, session+1)
+		if err != nil {
+			return fmt.Errorf("failed to write visit: %v", err)
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO visits_fts (visits_fts) VALUES ('rebuild')`); err != nil {
+		return fmt.Errorf("failed to build search index: %v", err)
+	}
+	return tx.Commit()
+}
+
+// Metadata returns Datasette metadata for the database at dbPath: table
+// descriptions, default sorting and facets, and a canned search query
+func Metadata(dbPath string, start, end time.Time, loc *time.Location) map[string]interface{} {
+	name := strings.TrimSuffix(filepath.Base(dbPath), filepath.Ext(dbPath))
+	return map[string]interface{}{
+		"title": "web-recap",
+		"description": fmt.Sprintf("Browser history from %s to %s (dates and hours in %s)",
+			start.In(loc).Format("2006-01-02"), end.In(loc).Add(-time.Nanosecond).Format("2006-01-02"), loc),
+		"databases": map[string]interface{}{
+			name: map[string]interface{}{
+				"tables": map[string]interface{}{
+					"visits": map[string]interface{}{
+						"description":  "One row per page visit; visited_at is UTC, date and hour are local",
+						"sort_desc":    "visited_at",
+						"facets":       []string{"browser", "domain", "date"},
+						"fts_table":    "visits_fts",
+						"fts_pk":       "id",
+						"label_column": "title",
+					},
+					"sessions": map[string]interface{}{
+						"description":  "Runs of visits with no gap over 30 minutes",
+						"sort_desc":    "started_at",
+						"label_column": "top_domains",
+					},
+					"daily_counts": map[string]interface{}{
+						"description": "Visits, domains, pages, and sessions per day",
+					},
+					"top_domains": map[string]interface{}{
+						"description": "Domains by number of visits",
+					},
+					"hourly_activity": map[string]interface{}{
+						"description": "Visits by hour of day",
+					},
+				},
+				"queries": map[string]interface{}{
+					"search": map[string]interface{}{
+						"title":       "Search titles and URLs",
+						"description": "Full-text search; words are stemmed and a trailing * matches a prefix",
+						"sql": `SELECT visits.visited_at, visits.title, visits.url, visits.browser
+FROM visits_fts JOIN visits ON visits.id = visits_fts.rowid
+WHERE visits_fts MATCH :query
+ORDER BY visits_fts.rank, visits.visited_at DESC
+LIMIT 200`,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package datasette
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestWrite(t *testing.T) {
+	base := time.Date(2025, 3, 1, 23, 50, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{URL: "https://go.dev/doc", Title: "Documentation", Domain: "go.dev", Browser: "chrome", Timestamp: base},
+		{URL: "https://go.dev/blog", Title: "Cancellation in Go", Domain: "go.dev", Browser: "chrome", Timestamp: base.Add(20 * time.Minute)},
+		{URL: "https://example.com/", Title: "Example", Domain: "example.com", Browser: "firefox", Timestamp: base.Add(5 * time.Hour)},
+	}
+	path := filepath.Join(t.TempDir(), "recap.db")
+	// The file is replaced, not appended to
+	for i := 0; i < 2; i++ {
+		if err := Write(path, entries, time.UTC); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var visits, sessions int
+	db.QueryRow(`SELECT count(*), count(DISTINCT session_id) FROM visits`).Scan(&visits, &sessions)
+	if visits != 3 || sessions != 2 {
+		t.Errorf("visits, sessions = %d, %d, want 3, 2", visits, sessions)
+	}
+
+	rows, err := db.Query(`SELECT date, visits FROM daily_counts`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var days []string
+	for rows.Next() {
+		var day string
+		var n int
+		rows.Scan(&day, &n)
+		days = append(days, day)
+	}
+	rows.Close()
+	if len(days) != 2 || days[0] != "2025-03-01" || days[1] != "2025-03-02" {
+		t.Errorf("daily_counts dates = %v", days)
+	}
+
+	var domain string
+	var domainVisits int
+	db.QueryRow(`SELECT domain, visits FROM top_domains LIMIT 1`).Scan(&domain, &domainVisits)
+	if domain != "go.dev" || domainVisits != 2 {
+		t.Errorf("top domain = %s (%d), want go.dev (2)", domain, domainVisits)
+	}
+
+	var url string
+	if err := db.QueryRow(`SELECT visits.url FROM visits_fts JOIN visits ON visits.id = visits_fts.rowid WHERE visits_fts MATCH 'cancel'`).Scan(&url); err != nil || url != "https://go.dev/blog" {
+		t.Errorf("full-text search = %q, %v", url, err)
+	}
+}
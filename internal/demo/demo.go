@@ -0,0 +1,231 @@
+// Package demo makes up browsing history on fake domains, so a report can be
+// shared as an example without revealing anything about the user
+package demo
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// fakeBrowser is a made-up source: the browser label, its type, and name
+type fakeBrowser struct {
+	label, typ, name string
+	share            int // percent of sessions
+}
+
+var browsers = []fakeBrowser{
+	{"chrome", "chrome", "Google Chrome", 70},
+	{"firefox", "firefox", "Firefox", 30},
+}
+
+// page is a visit a session can make, with its URL and title
+type page struct {
+	url, title string
+}
+
+// site is a fake website; pages makes a page from the session's random source
+type site struct {
+	domain string
+	pages  func(r *rand.Rand) page
+}
+
+var (
+	topics = []string{
+		"context cancellation", "sqlite write-ahead log", "http retries", "css grid layout",
+		"docker volumes", "postgres indexes", "rust lifetimes", "kubernetes probes",
+		"react hooks", "bash arrays", "git rebase", "unicode normalization",
+	}
+	headlines = []string{
+		"City council approves new bike lanes", "Storm expected to reach the coast by Friday",
+		"Local bakery wins national award", "Researchers map deep sea vents",
+		"Transit fares to stay flat next year", "Museum reopens after renovation",
+		"Startup unveils quieter heat pump", "Library extends weekend hours",
+	}
+	products = []string{
+		"Mechanical Keyboard", "Noise Cancelling Headphones", "Standing Desk Mat",
+		"USB-C Dock", "Cast Iron Skillet", "Trail Running Shoes", "Pour-Over Kettle",
+	}
+	videos = []string{
+		"Building a Workbench in a Weekend", "Ten Minute Morning Stretch",
+		"How Compilers Work", "Sourdough for Beginners", "Live Jazz Session #12",
+		"Restoring an Old Bicycle",
+	}
+	articles = []string{
+		"Hash table", "Byzantine Empire", "Photosynthesis", "Great Barrier Reef",
+		"Lambda calculus", "Silk Road", "Plate tectonics",
+	}
+	repos = []string{"acme/api", "acme/web", "acme/infra", "acme/cli"}
+)
+
+// sites are grouped by the kind of session that visits them
+var sites = map[string][]site{
+	"work": {
+		{"mail.example.com", func(r *rand.Rand) page {
+			return page{"https://mail.example.com/inbox", fmt.Sprintf("Inbox (%d) - Example Mail", r.Intn(30))}
+		}},
+		{"code.example.com", func(r *rand.Rand) page {
+			repo := repos[r.Intn(len(repos))]
+			n := 100 + r.Intn(900)
+			return page{
+				fmt.Sprintf("https://code.example.com/%s/pull/%d", repo, n),
+				fmt.Sprintf("Fix %s by octo · Pull Request #%d · %s", topics[r.Intn(len(topics))], n, repo),
+			}
+		}},
+		{"docs.example.org", func(r *rand.Rand) page {
+			topic := topics[r.Intn(len(topics))]
+			return page{"https://docs.example.org/guide/" + slug(topic), capitalize(topic) + " - Example Docs"}
+		}},
+		{"search.example.com", func(r *rand.Rand) page {
+			q := topics[r.Intn(len(topics))]
+			return page{"https://search.example.com/search?q=" + url.QueryEscape(q), q + " - Example Search"}
+		}},
+		{"chat.example.com", func(r *rand.Rand) page {
+			return page{"https://chat.example.com/team/general", "#general | Example Chat"}
+		}},
+	},
+	"news": {
+		{"news.example.net", func(r *rand.Rand) page {
+			h := headlines[r.Intn(len(headlines))]
+			return page{"https://news.example.net/local/" + slug(h), h + " | Example News"}
+		}},
+		{"forum.example.net", func(r *rand.Rand) page {
+			topic := topics[r.Intn(len(topics))]
+			return page{
+				fmt.Sprintf("https://forum.example.net/t/%s/%d", slug(topic), 1000+r.Intn(9000)),
+				"Question about " + topic + " - Example Forum",
+			}
+		}},
+		{"wiki.example.org", func(r *rand.Rand) page {
+			a := articles[r.Intn(len(articles))]
+			return page{"https://wiki.example.org/wiki/" + strings.ReplaceAll(a, " ", "_"), a + " - Example Wiki"}
+		}},
+	},
+	"leisure": {
+		{"video.example.com", func(r *rand.Rand) page {
+			return page{
+				fmt.Sprintf("https://video.example.com/watch?v=%08x", r.Uint32()),
+				videos[r.Intn(len(videos))] + " - ExampleTube",
+			}
+		}},
+		{"shop.example.com", func(r *rand.Rand) page {
+			p := products[r.Intn(len(products))]
+			return page{fmt.Sprintf("https://shop.example.com/p/%s-%d", slug(p), 10000+r.Intn(90000)), p + " | Example Shop"}
+		}},
+		{"recipes.example.org", func(r *rand.Rand) page {
+			return page{"https://recipes.example.org/weeknight-dinners", "Weeknight Dinners - Example Recipes"}
+		}},
+	},
+}
+
+// sessions are the times of day browsing tends to happen: the earliest
+// start hour, the spread in hours, the kind of sites, and how many pages
+var sessions = []struct {
+	hour, spread int
+	kind         string
+	pages        int
+}{
+	{8, 2, "news", 6},
+	{9, 2, "work", 18},
+	{12, 1, "leisure", 8},
+	{13, 3, "work", 20},
+	{19, 3, "leisure", 10},
+	{21, 2, "news", 6},
+}
+
+// History returns made-up visits between start and end, newest first. The
+// same range always gives the same visits. Weekends have fewer work
+// sessions, and a session wanders between a few sites, returning to some.
+func History(start, end time.Time) []models.HistoryEntry {
+	r := rand.New(rand.NewSource(start.Unix()))
+	counts := make(map[string]int)
+	var entries []models.HistoryEntry
+
+	loc := start.Location()
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+		for _, s := range sessions {
+			if (weekend && s.kind == "work") || r.Intn(5) == 0 {
+				continue
+			}
+			b := pickBrowser(r)
+			t := day.Add(time.Duration(s.hour)*time.Hour + time.Duration(r.Intn(s.spread*3600))*time.Second)
+			var visited []page
+			for n := s.pages/2 + r.Intn(s.pages); n > 0; n-- {
+				var p page
+				if len(visited) > 0 && r.Intn(4) == 0 {
+					p = visited[r.Intn(len(visited))] // back to an earlier page
+				} else {
+					kind := sites[s.kind]
+					p = kind[r.Intn(len(kind))].pages(r)
+					visited = append(visited, p)
+				}
+				// Browsers keep visit times to the microsecond
+				t = t.Truncate(time.Second).Add(time.Duration(10+r.Intn(300))*time.Second + time.Duration(r.Intn(1e6))*time.Microsecond)
+				if t.Before(start) || !t.Before(end) {
+					continue
+				}
+				counts[p.url]++
+				u, _ := url.Parse(p.url)
+				entries = append(entries, models.HistoryEntry{
+					ID:          models.HistoryID(b.typ, p.url, t.UTC()),
+					Timestamp:   t.UTC(),
+					URL:         p.url,
+					Title:       p.title,
+					VisitCount:  counts[p.url],
+					Domain:      u.Hostname(),
+					Browser:     b.label,
+					BrowserType: b.typ,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	return entries
+}
+
+// Sources returns the status of each made-up browser in entries
+func Sources(entries []models.HistoryEntry) []models.SourceStatus {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		counts[e.BrowserType]++
+	}
+	sources := make([]models.SourceStatus, len(browsers))
+	for i, b := range browsers {
+		sources[i] = models.SourceStatus{Browser: b.typ, Name: b.name, Entries: counts[b.typ]}
+	}
+	return sources
+}
+
+// pickBrowser picks the browser of a session by its share
+func pickBrowser(r *rand.Rand) fakeBrowser {
+	n := r.Intn(100)
+	for _, b := range browsers {
+		if n < b.share {
+			return b
+		}
+		n -= b.share
+	}
+	return browsers[0]
+}
+
+// slug turns s into a lowercase, hyphenated URL path segment
+func slug(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(s, "#", ""))), "-")
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
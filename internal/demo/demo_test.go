@@ -0,0 +1,53 @@
+package demo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC) // a Monday
+	end := start.AddDate(0, 0, 7)
+
+	entries := History(start, end)
+	if len(entries) == 0 {
+		t.Fatal("History returned no entries")
+	}
+	for i, e := range entries {
+		if e.Timestamp.Before(start) || !e.Timestamp.Before(end) {
+			t.Errorf("entry %d at %v is outside the range", i, e.Timestamp)
+		}
+		if i > 0 && e.Timestamp.After(entries[i-1].Timestamp) {
+			t.Errorf("entry %d is newer than the one before it", i)
+		}
+		if !strings.Contains(e.Domain, ".example.") || !strings.Contains(e.URL, e.Domain) {
+			t.Errorf("entry %d is not on a fake domain: %s", i, e.URL)
+		}
+		if e.ID == "" || e.Title == "" || e.VisitCount < 1 {
+			t.Errorf("entry %d is incomplete: %+v", i, e)
+		}
+	}
+
+	again := History(start, end)
+	if len(again) != len(entries) || again[0].ID != entries[0].ID {
+		t.Error("History is not the same for the same range")
+	}
+
+	total := 0
+	for _, s := range Sources(entries) {
+		total += s.Entries
+	}
+	if total != len(entries) {
+		t.Errorf("sources count %d entries, want %d", total, len(entries))
+	}
+}
+
+func TestHistoryWeekend(t *testing.T) {
+	saturday := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	for _, e := range History(saturday, saturday.AddDate(0, 0, 2)) {
+		if e.Domain == "code.example.com" || e.Domain == "mail.example.com" {
+			t.Errorf("work site %s visited on a weekend", e.Domain)
+		}
+	}
+}
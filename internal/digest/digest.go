@@ -0,0 +1,130 @@
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Period identifies the span a digest covers
+type Period string
+
+const (
+	Daily  Period = "daily"
+	Weekly Period = "weekly"
+)
+
+// ParsePeriod validates a period name from the command line
+func ParsePeriod(s string) (Period, error) {
+	switch Period(s) {
+	case Daily, Weekly:
+		return Period(s), nil
+	default:
+		return "", fmt.Errorf("invalid digest period %q (use daily or weekly)", s)
+	}
+}
+
+// Range returns the [start, end) window for a period ending with the day
+// containing now. A weekly digest covers the last seven days including today.
+func (p Period) Range(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	now = now.In(loc)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	if p == Weekly {
+		return end.AddDate(0, 0, -7), end
+	}
+	return end.AddDate(0, 0, -1), end
+}
+
+// Options controls how much detail goes into a digest
+type Options struct {
+	Period     Period
+	TopDomains int
+	TopPages   int
+}
+
+// Build summarizes history entries into a digest report. Days are bucketed in loc.
+func Build(entries []models.HistoryEntry, browser string, start, end time.Time, loc *time.Location, opts Options) models.DigestReport {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	report := models.DigestReport{
+		Period:    string(opts.Period),
+		Browser:   browser,
+		StartDate: start.UTC(),
+		EndDate:   end.UTC(),
+		Timezone:  loc.String(),
+	}
+
+	lastDay := end.Add(-time.Nanosecond).In(loc)
+	if opts.Period == Weekly {
+		report.Title = fmt.Sprintf("Weekly browsing digest: %s – %s",
+			start.In(loc).Format("Jan 2"), lastDay.Format("Jan 2, 2006"))
+	} else {
+		report.Title = fmt.Sprintf("Daily browsing digest: %s", lastDay.Format("Mon, Jan 2, 2006"))
+	}
+
+	dayCounts := make(map[string]int)
+	domainCounts := make(map[string]int)
+	pages := make(map[string]*models.DigestPage)
+
+	for _, e := range entries {
+		if e.Timestamp.IsZero() || e.Timestamp.Before(start) || !e.Timestamp.Before(end) {
+			continue
+		}
+
+		report.TotalVisits++
+		dayCounts[e.Timestamp.In(loc).Format("2006-01-02")]++
+		if e.Domain != "" {
+			domainCounts[e.Domain]++
+		}
+
+		page, ok := pages[e.URL]
+		if !ok {
+			page = &models.DigestPage{URL: e.URL, Domain: e.Domain}
+			pages[e.URL] = page
+		}
+		if page.Title == "" {
+			page.Title = e.Title
+		}
+		page.Visits++
+	}
+
+	// Every day in the period is listed, including days without visits
+	for day := start.In(loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		report.Days = append(report.Days, models.DigestDay{Date: key, Visits: dayCounts[key]})
+	}
+
+	report.UniqueDomains = len(domainCounts)
+	for domain, visits := range domainCounts {
+		report.TopDomains = append(report.TopDomains, models.DigestDomain{Domain: domain, Visits: visits})
+	}
+	sort.Slice(report.TopDomains, func(i, j int) bool {
+		if report.TopDomains[i].Visits != report.TopDomains[j].Visits {
+			return report.TopDomains[i].Visits > report.TopDomains[j].Visits
+		}
+		return report.TopDomains[i].Domain < report.TopDomains[j].Domain
+	})
+	if opts.TopDomains > 0 && len(report.TopDomains) > opts.TopDomains {
+		report.TopDomains = report.TopDomains[:opts.TopDomains]
+	}
+
+	report.UniquePages = len(pages)
+	for _, page := range pages {
+		report.TopPages = append(report.TopPages, *page)
+	}
+	sort.Slice(report.TopPages, func(i, j int) bool {
+		if report.TopPages[i].Visits != report.TopPages[j].Visits {
+			return report.TopPages[i].Visits > report.TopPages[j].Visits
+		}
+		return report.TopPages[i].URL < report.TopPages[j].URL
+	})
+	if opts.TopPages > 0 && len(report.TopPages) > opts.TopPages {
+		report.TopPages = report.TopPages[:opts.TopPages]
+	}
+
+	return report
+}
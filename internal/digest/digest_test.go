@@ -0,0 +1,45 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestBuildWeeklyDigest(t *testing.T) {
+	now := time.Date(2026, 2, 7, 15, 0, 0, 0, time.UTC)
+	start, end := Weekly.Range(now, time.UTC)
+
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Fatalf("weekly start = %v, want %v", start, want)
+	}
+
+	entries := []models.HistoryEntry{
+		{Timestamp: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC), URL: "https://go.dev/doc", Title: "Docs", Domain: "go.dev"},
+		{Timestamp: time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC), URL: "https://go.dev/doc", Title: "", Domain: "go.dev"},
+		{Timestamp: time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC), URL: "https://news.example/a", Title: "A", Domain: "news.example"},
+		{Timestamp: time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC), URL: "https://old.example/", Title: "Old", Domain: "old.example"},
+	}
+
+	report := Build(entries, "all", start, end, time.UTC, Options{Period: Weekly, TopDomains: 1, TopPages: 5})
+
+	if report.TotalVisits != 3 {
+		t.Errorf("TotalVisits = %d, want 3", report.TotalVisits)
+	}
+	if report.UniqueDomains != 2 || report.UniquePages != 2 {
+		t.Errorf("unique domains/pages = %d/%d, want 2/2", report.UniqueDomains, report.UniquePages)
+	}
+	if len(report.Days) != 7 {
+		t.Fatalf("len(Days) = %d, want 7", len(report.Days))
+	}
+	if report.Days[2].Date != "2026-02-03" || report.Days[2].Visits != 2 {
+		t.Errorf("Days[2] = %+v, want 2026-02-03 with 2 visits", report.Days[2])
+	}
+	if len(report.TopDomains) != 1 || report.TopDomains[0].Domain != "go.dev" {
+		t.Errorf("TopDomains = %+v, want only go.dev", report.TopDomains)
+	}
+	if report.TopPages[0].Title != "Docs" || report.TopPages[0].Visits != 2 {
+		t.Errorf("TopPages[0] = %+v, want Docs with 2 visits", report.TopPages[0])
+	}
+}
@@ -0,0 +1,29 @@
+// Package encrypt encrypts output for age or GPG recipients, so exports
+// stored in shared or cloud folders aren't plaintext
+package encrypt
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Age returns a writer that encrypts to w in the age format for the given
+// X25519 recipients, e.g. age1ql3z7h... Close must be called to finish the
+// output; it does not close w.
+func Age(w io.Writer, recipients []string) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no age recipients")
+	}
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, r := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(r))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %v", r, err)
+		}
+		parsed = append(parsed, recipient)
+	}
+	return age.Encrypt(w, parsed...)
+}
@@ -0,0 +1,62 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestAgeRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := age.GenerateX25519Identity()
+	recipients := []string{other.Recipient().String(), identity.Recipient().String()}
+
+	for _, size := range []int{0, 5, 64 * 1024, 64*1024*2 + 7} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		var buf bytes.Buffer
+		w, err := Age(&buf, recipients)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(plain); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := age.Decrypt(&buf, identity)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("size %d: decrypted %d bytes that differ from the input", size, len(got))
+		}
+	}
+}
+
+func TestAgeInvalidRecipient(t *testing.T) {
+	for _, bad := range []string{
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q", // checksum
+		"age2ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+		"not a key",
+	} {
+		if _, err := Age(io.Discard, []string{bad}); err == nil {
+			t.Errorf("Age(%q) succeeded", bad)
+		}
+	}
+	if _, err := Age(io.Discard, []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"}); err != nil {
+		t.Errorf("README recipient: %v", err)
+	}
+}
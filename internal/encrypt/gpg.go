@@ -0,0 +1,59 @@
+package encrypt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// GPG returns a writer that encrypts to w with the gpg command for the given
+// key IDs, fingerprints, or emails. The keys must be in the user's keyring.
+// Close must be called to finish the output; it does not close w.
+func GPG(w io.Writer, recipients []string) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no GPG recipients")
+	}
+	// The recipients were named explicitly, so don't ask whether to trust them
+	args := []string{"--batch", "--yes", "--trust-model", "always", "--encrypt", "--output", "-"}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+	cmd := exec.Command("gpg", args...)
+	cmd.Stdout = w
+	g := &gpgWriter{cmd: cmd}
+	cmd.Stderr = &g.stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	g.stdin = stdin
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run gpg (is GnuPG installed?): %v", err)
+	}
+	return g, nil
+}
+
+type gpgWriter struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+}
+
+func (g *gpgWriter) Write(p []byte) (int, error) {
+	return g.stdin.Write(p)
+}
+
+// Close ends the input and waits for gpg to finish, reporting its error
+// output if it failed
+func (g *gpgWriter) Close() error {
+	g.stdin.Close()
+	if err := g.cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(g.stderr.String()); msg != "" {
+			return fmt.Errorf("gpg failed: %s", msg)
+		}
+		return fmt.Errorf("gpg failed: %v", err)
+	}
+	return nil
+}
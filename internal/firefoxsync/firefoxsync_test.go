@@ -0,0 +1,83 @@
+package firefoxsync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+// The request examples from the Hawk specification
+func TestHawk(t *testing.T) {
+	key := []byte("werxhqb98rpaxn39848xrunpaw3489ruxnpa98w4rxn")
+	if got, want := hawkMAC(key, "1353832234", "j4h3g2", "GET", "/resource/1?b=1&a=2", "example.com", "8000", "", "some-app-ext-data"), "6R4rV5iE+NPoym+WwjeHzjAGXUtLNIxmo1vpMofpLAE="; got != want {
+		t.Errorf("mac = %s, want %s", got, want)
+	}
+	if got, want := hawkPayloadHash("text/plain; charset=utf-8", []byte("Thank you for flying Hawk")), "Yi9LfIIFRtBEPt74PVmbTF/xVAwPn7ub15ePICfgnuY="; got != want {
+		t.Errorf("payload hash = %s, want %s", got, want)
+	}
+}
+
+// encrypt builds a record payload the way Firefox does
+func encrypt(t *testing.T, keys keyBundle, v interface{}) string {
+	t.Helper()
+	plain, _ := json.Marshal(v)
+	pad := aes.BlockSize - len(plain)%aes.BlockSize
+	for i := 0; i < pad; i++ {
+		plain = append(plain, byte(pad))
+	}
+	iv := make([]byte, aes.BlockSize)
+	block, err := aes.NewCipher(keys.encKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plain)
+
+	b64 := base64.StdEncoding.EncodeToString(ciphertext)
+	mac := hmac.New(sha256.New, keys.hmacKey)
+	mac.Write([]byte(b64))
+	payload, _ := json.Marshal(map[string]string{"ciphertext": b64, "IV": base64.StdEncoding.EncodeToString(iv), "hmac": hex.EncodeToString(mac.Sum(nil))})
+	return string(payload)
+}
+
+func TestDecrypt(t *testing.T) {
+	keys := keyBundle{encKey: make([]byte, 32), hmacKey: []byte("hmac key")}
+	payload := encrypt(t, keys, map[string]string{"histUri": "https://example.com/"})
+
+	var r historyRecord
+	if err := decrypt(keys, payload, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.URL != "https://example.com/" {
+		t.Errorf("url = %q", r.URL)
+	}
+	if err := decrypt(keyBundle{encKey: keys.encKey, hmacKey: []byte("other")}, payload, &r); err == nil {
+		t.Error("decrypt with the wrong HMAC key succeeded")
+	}
+}
+
+func TestBookmarkEntries(t *testing.T) {
+	records := []bookmarkRecord{
+		{ID: "toolbar", Type: "folder", Title: "toolbar", ParentID: "places"},
+		{ID: "work", Type: "folder", Title: "Work", ParentID: "toolbar"},
+		{ID: "mobile", Type: "folder", Title: "mobile", ParentID: "places"},
+		{ID: "b1", Type: "bookmark", Title: "Go", URL: "https://go.dev/", ParentID: "work", DateAdded: 1700000000000},
+		{ID: "b2", Type: "bookmark", Title: "Phone", URL: "https://m.example.com/", ParentID: "mobile"},
+		{ID: "q1", Type: "query", Title: "Recent", URL: "place:sort=8", ParentID: "toolbar"},
+	}
+	entries := bookmarkEntries(records, "firefox")
+	if len(entries) != 2 {
+		t.Fatalf("got %d bookmarks, want 2", len(entries))
+	}
+	if entries[0].Folder != "Work" || entries[0].DateAdded.UnixMilli() != 1700000000000 || entries[0].Domain != "go.dev" {
+		t.Errorf("first bookmark = %+v", entries[0])
+	}
+	if entries[1].Folder != "mobile" || entries[1].Browser != "firefox" {
+		t.Errorf("second bookmark = %+v", entries[1])
+	}
+}
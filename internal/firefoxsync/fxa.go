@@ -0,0 +1,264 @@
+// Package firefoxsync reads history and bookmarks from Firefox Sync, signing
+// in to a Mozilla account the way Firefox does
+package firefoxsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Service URLs, replaced in tests
+var (
+	accountsURL = "https://api.accounts.firefox.com/v1"
+	tokenURL    = "https://token.services.mozilla.com/1.0/sync/1.5"
+)
+
+// Firefox desktop's OAuth client ID and the scope of Sync's keys
+const (
+	clientID  = "5882386c6d801776"
+	syncScope = "https://identity.mozilla.com/apps/oldsync"
+)
+
+// errnoIncorrectEmailCase is returned when the email differs in case from the
+// one the account was created with, which the password is stretched with
+const errnoIncorrectEmailCase = 120
+
+// Config holds the Mozilla account to read; the password is only needed
+// until a session has been saved
+type Config struct {
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// WithEnv fills in missing credentials from FXA_EMAIL and FXA_PASSWORD
+func (c Config) WithEnv() Config {
+	if c.Email == "" {
+		c.Email = os.Getenv("FXA_EMAIL")
+	}
+	if c.Password == "" {
+		c.Password = os.Getenv("FXA_PASSWORD")
+	}
+	return c
+}
+
+// Session is a signed-in Mozilla account session and the Sync key it
+// unlocked. Saving it avoids a sign-in, and its confirmation code, on every
+// run; it grants full access to the Sync data, so keep it private.
+type Session struct {
+	Email        string `json:"email"`
+	UID          string `json:"uid"`
+	SessionToken string `json:"session_token"`
+	KB           string `json:"kb"`
+}
+
+// Prompt asks the user for a sign-in confirmation code
+type Prompt func(message string) (string, error)
+
+// Login signs in with the account password. New sign-ins must be confirmed
+// with a code Mozilla emails, or one from an authenticator app when the
+// account has two-step authentication; prompt asks for it.
+func Login(ctx context.Context, config Config, prompt Prompt) (*Session, error) {
+	if config.Email == "" || config.Password == "" {
+		return nil, fmt.Errorf("a Mozilla account email and password are required to sign in (firefox_sync in the config file, or FXA_EMAIL / FXA_PASSWORD)")
+	}
+
+	email := config.Email
+	var login struct {
+		UID                string `json:"uid"`
+		SessionToken       string `json:"sessionToken"`
+		KeyFetchToken      string `json:"keyFetchToken"`
+		Verified           bool   `json:"verified"`
+		VerificationMethod string `json:"verificationMethod"`
+	}
+	var unwrapBKey []byte
+	for attempt := 0; ; attempt++ {
+		quick, err := pbkdf2.Key(sha256.New, config.Password, []byte("identity.mozilla.com/picl/v1/quickStretch:"+email), 1000, 32)
+		if err != nil {
+			return nil, err
+		}
+		authPW, _ := hkdf.Key(sha256.New, quick, nil, "identity.mozilla.com/picl/v1/authPW", 32)
+		unwrapBKey, _ = hkdf.Key(sha256.New, quick, nil, "identity.mozilla.com/picl/v1/unwrapBkey", 32)
+
+		body := map[string]string{
+			"email":              email,
+			"authPW":             hex.EncodeToString(authPW),
+			"reason":             "login",
+			"service":            "sync",
+			"verificationMethod": "email-otp",
+		}
+		err = call(ctx, http.MethodPost, accountsURL+"/account/login?keys=true", nil, body, &login)
+		if apiErr, ok := err.(*accountError); ok && apiErr.Errno == errnoIncorrectEmailCase && apiErr.Email != "" && attempt == 0 {
+			email = apiErr.Email
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign in to Mozilla account: %v", err)
+		}
+		break
+	}
+
+	session := &Session{Email: email, UID: login.UID, SessionToken: login.SessionToken}
+	if !login.Verified {
+		if err := session.confirm(ctx, login.VerificationMethod, prompt); err != nil {
+			return nil, err
+		}
+	}
+
+	kB, err := fetchKB(ctx, login.KeyFetchToken, unwrapBKey)
+	if err != nil {
+		return nil, err
+	}
+	session.KB = hex.EncodeToString(kB)
+	return session, nil
+}
+
+// confirm completes a sign-in with the code sent by email or shown by an
+// authenticator app
+func (s *Session) confirm(ctx context.Context, method string, prompt Prompt) error {
+	path, message := "/session/verify_code", "Enter the confirmation code Mozilla emailed to "+s.Email+": "
+	switch method {
+	case "email-otp":
+	case "totp-2fa":
+		path, message = "/session/verify/totp", "Enter the code from your authenticator app: "
+	default:
+		return fmt.Errorf("the sign-in must be confirmed by %s, which is not supported", method)
+	}
+	if prompt == nil {
+		return fmt.Errorf("the sign-in must be confirmed with a code; run the command in a terminal")
+	}
+	code, err := prompt(message)
+	if err != nil {
+		return err
+	}
+	creds, err := tokenCredentials(s.SessionToken, "sessionToken")
+	if err != nil {
+		return err
+	}
+	body := map[string]string{"code": strings.TrimSpace(code)}
+	if err := call(ctx, http.MethodPost, accountsURL+path, creds, body, nil); err != nil {
+		return fmt.Errorf("failed to confirm sign-in: %v", err)
+	}
+	return nil
+}
+
+// fetchKB fetches the account keys and unwraps kB, the key Sync's keys are
+// derived from
+func fetchKB(ctx context.Context, keyFetchToken string, unwrapBKey []byte) ([]byte, error) {
+	token, err := hex.DecodeString(keyFetchToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key fetch token: %v", err)
+	}
+	derived, _ := hkdf.Key(sha256.New, token, nil, "identity.mozilla.com/picl/v1/keyFetchToken", 96)
+	creds := &hawkCredentials{ID: hex.EncodeToString(derived[:32]), Key: derived[32:64]}
+
+	var keys struct {
+		Bundle string `json:"bundle"`
+	}
+	if err := call(ctx, http.MethodGet, accountsURL+"/account/keys", creds, nil, &keys); err != nil {
+		return nil, fmt.Errorf("failed to fetch account keys: %v", err)
+	}
+	bundle, err := hex.DecodeString(keys.Bundle)
+	if err != nil || len(bundle) != 96 {
+		return nil, fmt.Errorf("invalid account key bundle")
+	}
+
+	bundleKeys, _ := hkdf.Key(sha256.New, derived[64:96], nil, "identity.mozilla.com/picl/v1/account/keys", 96)
+	mac := hmac.New(sha256.New, bundleKeys[:32])
+	mac.Write(bundle[:64])
+	if !hmac.Equal(mac.Sum(nil), bundle[64:]) {
+		return nil, fmt.Errorf("account key bundle failed verification")
+	}
+	kB := make([]byte, 32)
+	for i := range kB {
+		// The bundle is kA then wrap(kB), XORed with the second half of the keys
+		kB[i] = bundle[32+i] ^ bundleKeys[64+i] ^ unwrapBKey[i]
+	}
+	return kB, nil
+}
+
+// tokenCredentials derives the Hawk credentials of a session or key fetch
+// token
+func tokenCredentials(token, name string) (*hawkCredentials, error) {
+	raw, err := hex.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	derived, _ := hkdf.Key(sha256.New, raw, nil, "identity.mozilla.com/picl/v1/"+name, 64)
+	return &hawkCredentials{ID: hex.EncodeToString(derived[:32]), Key: derived[32:]}, nil
+}
+
+// accountError is an error response from the accounts or token server
+type accountError struct {
+	Status     string `json:"-"`
+	StatusCode int    `json:"-"`
+	Errno      int    `json:"errno"`
+	Message    string `json:"message"`
+	Email      string `json:"email"`
+}
+
+func (e *accountError) Error() string {
+	if e.Message == "" {
+		return e.Status
+	}
+	return e.Status + ": " + e.Message
+}
+
+// call sends a JSON request, signed with Hawk when creds is set, and decodes
+// the response into out
+func call(ctx context.Context, method, url string, creds *hawkCredentials, body, out interface{}) error {
+	_, err := send(ctx, method, url, creds, nil, body, out)
+	return err
+}
+
+// send is call with extra request headers, returning the response headers
+func send(ctx context.Context, method, url string, creds *hawkCredentials, header http.Header, body, out interface{}) (http.Header, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if creds != nil {
+		req.Header.Set("Authorization", creds.header(req, data))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		apiErr := &accountError{Status: resp.Status, StatusCode: resp.StatusCode}
+		if json.Unmarshal(msg, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(msg))
+		}
+		return nil, apiErr
+	}
+	if out == nil {
+		return resp.Header, nil
+	}
+	return resp.Header, json.NewDecoder(resp.Body).Decode(out)
+}
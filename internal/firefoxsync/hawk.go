@@ -0,0 +1,67 @@
+package firefoxsync
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hawkCredentials sign requests with the Hawk scheme the accounts and Sync
+// storage servers use
+type hawkCredentials struct {
+	ID  string
+	Key []byte
+}
+
+// header returns the Authorization header for req with the given body
+func (c *hawkCredentials) header(req *http.Request, body []byte) string {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := make([]byte, 6)
+	rand.Read(nonce)
+	nonceStr := hex.EncodeToString(nonce)
+
+	hash := ""
+	if len(body) > 0 {
+		hash = hawkPayloadHash(req.Header.Get("Content-Type"), body)
+	}
+	host, port := req.URL.Hostname(), req.URL.Port()
+	if port == "" {
+		port = "443"
+		if req.URL.Scheme == "http" {
+			port = "80"
+		}
+	}
+	mac := hawkMAC(c.Key, ts, nonceStr, req.Method, req.URL.RequestURI(), host, port, hash, "")
+
+	header := fmt.Sprintf(`Hawk id="%s", ts="%s", nonce="%s"`, c.ID, ts, nonceStr)
+	if hash != "" {
+		header += fmt.Sprintf(`, hash="%s"`, hash)
+	}
+	return header + fmt.Sprintf(`, mac="%s"`, mac)
+}
+
+// hawkMAC computes the request MAC over Hawk's normalized string
+func hawkMAC(key []byte, ts, nonce, method, resource, host, port, hash, ext string) string {
+	normalized := strings.Join([]string{"hawk.1.header", ts, nonce, strings.ToUpper(method), resource, strings.ToLower(host), port, hash, ext, ""}, "\n")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(normalized))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// hawkPayloadHash hashes a request body with its content type, without
+// parameters
+func hawkPayloadHash(contentType string, body []byte) string {
+	contentType, _, _ = strings.Cut(contentType, ";")
+	h := sha256.New()
+	h.Write([]byte("hawk.1.payload\n" + strings.ToLower(strings.TrimSpace(contentType)) + "\n"))
+	h.Write(body)
+	h.Write([]byte("\n"))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
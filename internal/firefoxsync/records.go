@@ -0,0 +1,146 @@
+package firefoxsync
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// rootFolders are the bookmark roots left out of folder paths, as in the
+// bookmarks read from a local Firefox profile
+var rootFolders = map[string]bool{"places": true, "menu": true, "toolbar": true, "unfiled": true}
+
+type historyRecord struct {
+	Deleted bool   `json:"deleted"`
+	URL     string `json:"histUri"`
+	Title   string `json:"title"`
+	Visits  []struct {
+		Date int64 `json:"date"` // microseconds since the Unix epoch
+	} `json:"visits"`
+}
+
+type bookmarkRecord struct {
+	ID        string   `json:"id"`
+	Deleted   bool     `json:"deleted"`
+	Type      string   `json:"type"`
+	Title     string   `json:"title"`
+	URL       string   `json:"bmkUri"`
+	ParentID  string   `json:"parentid"`
+	Tags      []string `json:"tags"`
+	DateAdded int64    `json:"dateAdded"` // milliseconds since the Unix epoch
+}
+
+// Device is a device signed in to the Sync account
+type Device struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// History returns every visit Sync holds, as history entries for browser.
+// Sync keeps the recent visits of each page from every device, without
+// recording which device made them.
+func (c *Client) History(ctx context.Context, browser string) ([]models.HistoryEntry, error) {
+	var entries []models.HistoryEntry
+	err := c.collection(ctx, "history", func(data json.RawMessage) error {
+		var r historyRecord
+		if err := json.Unmarshal(data, &r); err != nil || r.Deleted || r.URL == "" {
+			return nil
+		}
+		for _, v := range r.Visits {
+			if v.Date <= 0 {
+				continue
+			}
+			entries = append(entries, models.HistoryEntry{
+				Timestamp:  time.UnixMicro(v.Date).UTC(),
+				URL:        r.URL,
+				Title:      r.Title,
+				VisitCount: len(r.Visits),
+				Domain:     database.ExtractDomain(r.URL),
+				Browser:    browser,
+			})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Bookmarks returns the bookmarks Sync holds, with folder paths built like
+// those of a local Firefox profile
+func (c *Client) Bookmarks(ctx context.Context, browser string) ([]models.BookmarkEntry, error) {
+	var records []bookmarkRecord
+	err := c.collection(ctx, "bookmarks", func(data json.RawMessage) error {
+		var r bookmarkRecord
+		if err := json.Unmarshal(data, &r); err == nil && !r.Deleted {
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bookmarkEntries(records, browser), nil
+}
+
+func bookmarkEntries(records []bookmarkRecord, browser string) []models.BookmarkEntry {
+	folders := make(map[string]bookmarkRecord)
+	for _, r := range records {
+		if r.Type == "folder" {
+			folders[r.ID] = r
+		}
+	}
+	folderPath := func(id string) string {
+		var path []string
+		for depth := 0; depth < 100 && id != ""; depth++ {
+			f, ok := folders[id]
+			if !ok || rootFolders[id] {
+				break
+			}
+			if f.Title != "" {
+				path = append([]string{f.Title}, path...)
+			}
+			id = f.ParentID
+		}
+		return strings.Join(path, "/")
+	}
+
+	var entries []models.BookmarkEntry
+	for _, r := range records {
+		if r.Type != "bookmark" || r.URL == "" {
+			continue
+		}
+		e := models.BookmarkEntry{
+			ID:      r.ID,
+			URL:     r.URL,
+			Title:   r.Title,
+			Folder:  folderPath(r.ParentID),
+			Domain:  database.ExtractDomain(r.URL),
+			Browser: browser,
+			Tags:    r.Tags,
+		}
+		if r.DateAdded > 0 {
+			e.DateAdded = time.UnixMilli(r.DateAdded).UTC()
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].DateAdded.After(entries[j].DateAdded) })
+	return entries
+}
+
+// Devices returns the devices signed in to Sync
+func (c *Client) Devices(ctx context.Context) ([]Device, error) {
+	var devices []Device
+	err := c.collection(ctx, "clients", func(data json.RawMessage) error {
+		var d Device
+		if err := json.Unmarshal(data, &d); err == nil && d.Name != "" {
+			devices = append(devices, d)
+		}
+		return nil
+	})
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices, err
+}
@@ -0,0 +1,50 @@
+package firefoxsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultSessionPath returns where the signed-in session is saved, e.g.
+// ~/.config/web-recap/firefox-sync.json on Linux
+func DefaultSessionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web-recap", "firefox-sync.json"), nil
+}
+
+// LoadSession reads a saved session, returning nil when there is none
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Firefox Sync session: %v", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse Firefox Sync session %s: %v", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the session readable only by the current user
+func (s *Session) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to save Firefox Sync session: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save Firefox Sync session: %v", err)
+	}
+	return nil
+}
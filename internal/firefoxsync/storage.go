@@ -0,0 +1,230 @@
+package firefoxsync
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// pageSize is the most records fetched in one storage request
+const pageSize = 1000
+
+// ErrSessionExpired is returned when a saved session was signed out or
+// expired, and a new sign-in is needed
+var ErrSessionExpired = errors.New("the Mozilla account session has expired")
+
+// keyBundle decrypts Sync records
+type keyBundle struct {
+	encKey  []byte
+	hmacKey []byte
+}
+
+// Client reads a Sync account's storage
+type Client struct {
+	endpoint string
+	creds    *hawkCredentials
+	keys     map[string]keyBundle // by collection; "" is the default
+}
+
+// Connect exchanges the session for Sync storage credentials and fetches the
+// collection keys
+func Connect(ctx context.Context, session *Session) (*Client, error) {
+	kB, err := hex.DecodeString(session.KB)
+	if err != nil || len(kB) != 32 {
+		return nil, fmt.Errorf("invalid Sync key in the saved session")
+	}
+	creds, err := tokenCredentials(session.SessionToken, "sessionToken")
+	if err != nil {
+		return nil, err
+	}
+
+	var oauth struct {
+		AccessToken string `json:"access_token"`
+	}
+	body := map[string]string{"client_id": clientID, "grant_type": "fxa-credentials", "scope": syncScope, "access_type": "online"}
+	if err := call(ctx, http.MethodPost, accountsURL+"/oauth/token", creds, body, &oauth); err != nil {
+		if isUnauthorized(err) {
+			return nil, ErrSessionExpired
+		}
+		return nil, fmt.Errorf("failed to get a Sync access token: %v", err)
+	}
+
+	var scoped map[string]struct {
+		KeyRotationTimestamp int64 `json:"keyRotationTimestamp"`
+	}
+	body = map[string]string{"client_id": clientID, "scope": syncScope}
+	if err := call(ctx, http.MethodPost, accountsURL+"/account/scoped-key-data", creds, body, &scoped); err != nil {
+		return nil, fmt.Errorf("failed to read Sync key data: %v", err)
+	}
+
+	// The token server identifies the key by its rotation time and a hash
+	sum := sha256.Sum256(kB)
+	keyID := strconv.FormatInt(scoped[syncScope].KeyRotationTimestamp, 10) + "-" + base64.RawURLEncoding.EncodeToString(sum[:16])
+	var token struct {
+		ID          string `json:"id"`
+		Key         string `json:"key"`
+		APIEndpoint string `json:"api_endpoint"`
+	}
+	header := http.Header{"Authorization": {"Bearer " + oauth.AccessToken}, "X-KeyID": {keyID}}
+	if _, err := send(ctx, http.MethodGet, tokenURL, nil, header, nil, &token); err != nil {
+		return nil, fmt.Errorf("failed to get Sync storage credentials: %v", err)
+	}
+
+	c := &Client{endpoint: token.APIEndpoint, creds: &hawkCredentials{ID: token.ID, Key: []byte(token.Key)}}
+	root, _ := hkdf.Key(sha256.New, kB, nil, "identity.mozilla.com/picl/v1/oldsync", 64)
+	if err := c.loadKeys(ctx, keyBundle{encKey: root[:32], hmacKey: root[32:]}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadKeys decrypts the collection keys with the account's root key bundle
+func (c *Client) loadKeys(ctx context.Context, root keyBundle) error {
+	var bso record
+	if err := c.get(ctx, "/storage/crypto/keys", nil, &bso); err != nil {
+		return fmt.Errorf("failed to fetch Sync keys: %v", err)
+	}
+	var keys struct {
+		Default     []string            `json:"default"`
+		Collections map[string][]string `json:"collections"`
+	}
+	if err := decrypt(root, bso.Payload, &keys); err != nil {
+		return fmt.Errorf("failed to decrypt Sync keys: %v", err)
+	}
+
+	c.keys = make(map[string]keyBundle)
+	add := func(name string, pair []string) error {
+		if len(pair) != 2 {
+			return fmt.Errorf("invalid Sync key for %q", name)
+		}
+		enc, err1 := base64.StdEncoding.DecodeString(pair[0])
+		mac, err2 := base64.StdEncoding.DecodeString(pair[1])
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("invalid Sync key for %q", name)
+		}
+		c.keys[name] = keyBundle{encKey: enc, hmacKey: mac}
+		return nil
+	}
+	if err := add("", keys.Default); err != nil {
+		return err
+	}
+	for name, pair := range keys.Collections {
+		if err := add(name, pair); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record is a stored Sync object (BSO); its payload is encrypted JSON
+type record struct {
+	ID       string  `json:"id"`
+	Modified float64 `json:"modified"`
+	Payload  string  `json:"payload"`
+}
+
+// collection fetches and decrypts every record in a collection, calling fn
+// with each record's JSON
+func (c *Client) collection(ctx context.Context, name string, fn func(json.RawMessage) error) error {
+	keys, ok := c.keys[name]
+	if !ok {
+		keys = c.keys[""]
+	}
+	offset := ""
+	for {
+		query := url.Values{"full": {"1"}, "limit": {strconv.Itoa(pageSize)}}
+		if offset != "" {
+			query.Set("offset", offset)
+		}
+		var page []record
+		header, err := c.send(ctx, "/storage/"+name, query, &page)
+		if err != nil {
+			return fmt.Errorf("failed to fetch Sync %s: %v", name, err)
+		}
+		for _, bso := range page {
+			var data json.RawMessage
+			if err := decrypt(keys, bso.Payload, &data); err != nil {
+				return fmt.Errorf("failed to decrypt Sync %s record %s: %v", name, bso.ID, err)
+			}
+			if err := fn(data); err != nil {
+				return err
+			}
+		}
+		if offset = header.Get("X-Weave-Next-Offset"); offset == "" {
+			return nil
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	_, err := c.send(ctx, path, query, out)
+	return err
+}
+
+func (c *Client) send(ctx context.Context, path string, query url.Values, out interface{}) (http.Header, error) {
+	u := c.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return send(ctx, http.MethodGet, u, c.creds, nil, nil, out)
+}
+
+// decrypt checks and decrypts a record payload into out
+func decrypt(keys keyBundle, payload string, out interface{}) error {
+	var envelope struct {
+		Ciphertext string `json:"ciphertext"`
+		IV         string `json:"IV"`
+		HMAC       string `json:"hmac"`
+	}
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil {
+		return err
+	}
+
+	// The HMAC covers the base64 ciphertext, not the decoded bytes
+	mac := hmac.New(sha256.New, keys.hmacKey)
+	mac.Write([]byte(envelope.Ciphertext))
+	want, err := hex.DecodeString(envelope.HMAC)
+	if err != nil || !hmac.Equal(mac.Sum(nil), want) {
+		return fmt.Errorf("HMAC mismatch")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return err
+	}
+	iv, err := base64.StdEncoding.DecodeString(envelope.IV)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(keys.encKey)
+	if err != nil {
+		return err
+	}
+	if len(iv) != block.BlockSize() || len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return fmt.Errorf("invalid ciphertext")
+	}
+	plain := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, ciphertext)
+
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > block.BlockSize() {
+		return fmt.Errorf("invalid padding")
+	}
+	return json.Unmarshal(plain[:len(plain)-pad], out)
+}
+
+func isUnauthorized(err error) bool {
+	var apiErr *accountError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
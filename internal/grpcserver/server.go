@@ -0,0 +1,218 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/blocklist"
+	"github.com/rzolkos/web-recap/internal/digest"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/pkg/webrecap"
+	pb "github.com/rzolkos/web-recap/pkg/webrecap/webrecapv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the WebRecap gRPC service on top of pkg/webrecap
+type Server struct {
+	pb.UnimplementedWebRecapServer
+
+	// Source is recorded on every entry, like --source-label
+	Source string
+	// Blocklist leaves out entries from blocked domains, like --blocklist
+	Blocklist *blocklist.List
+}
+
+// New returns a server that labels entries with source
+func New(source string) *Server {
+	return &Server{Source: source}
+}
+
+// Register creates a gRPC server with the WebRecap service registered
+func Register(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	pb.RegisterWebRecapServer(srv, s)
+	return srv
+}
+
+// ListHistory streams history entries, newest first, sending each one as it
+// is read from the browser
+func (s *Server) ListHistory(req *pb.HistoryRequest, stream grpc.ServerStreamingServer[pb.HistoryEntry]) error {
+	var sendErr error
+	err := webrecap.StreamHistory(stream.Context(), webrecap.HistoryOptions{
+		Browser: webrecap.BrowserType(req.GetBrowser()),
+		Start:   asTime(req.GetStart()),
+		End:     asTime(req.GetEnd()),
+	}, func(e webrecap.HistoryEntry) error {
+		if s.Blocklist.Blocks(e.Domain) {
+			return nil
+		}
+		msg := &pb.HistoryEntry{
+			Timestamp:  timestamppb.New(e.Timestamp),
+			Url:        e.URL,
+			Title:      e.Title,
+			VisitCount: int32(e.VisitCount),
+			Domain:     e.Domain,
+			Browser:    e.Browser,
+			Source:     s.Source,
+		}
+		sendErr = stream.Send(msg)
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return statusError(err)
+	}
+	return nil
+}
+
+// ListBookmarks streams bookmarks, most recently added first
+func (s *Server) ListBookmarks(req *pb.BookmarksRequest, stream grpc.ServerStreamingServer[pb.BookmarkEntry]) error {
+	entries, err := webrecap.QueryBookmarks(stream.Context(), webrecap.BookmarkOptions{
+		Browser: webrecap.BrowserType(req.GetBrowser()),
+		Start:   asTime(req.GetStart()),
+		End:     asTime(req.GetEnd()),
+	})
+	if err != nil {
+		return statusError(err)
+	}
+
+	for _, e := range s.Blocklist.Bookmarks(entries) {
+		msg := &pb.BookmarkEntry{
+			DateAdded:    asTimestamp(e.DateAdded),
+			DateModified: asTimestamp(e.DateModified),
+			Url:          e.URL,
+			Title:        e.Title,
+			Folder:       e.Folder,
+			Domain:       e.Domain,
+			Browser:      e.Browser,
+			Tags:         e.Tags,
+			Source:       s.Source,
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTabs returns the open tabs of Chromium-based browsers
+func (s *Server) ListTabs(ctx context.Context, req *pb.TabsRequest) (*pb.TabsResponse, error) {
+	tabs, err := webrecap.QueryTabs(ctx, webrecap.TabOptions{Browser: webrecap.BrowserType(req.GetBrowser())})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &pb.TabsResponse{Tabs: make([]*pb.TabEntry, 0, len(tabs))}
+	for _, t := range s.Blocklist.Tabs(tabs) {
+		resp.Tabs = append(resp.Tabs, &pb.TabEntry{
+			Url:      t.URL,
+			Title:    t.Title,
+			Domain:   t.Domain,
+			Active:   t.Active,
+			Pinned:   t.Pinned,
+			Group:    t.Group,
+			WindowId: int32(t.WindowID),
+			Browser:  t.Browser,
+			Source:   s.Source,
+		})
+	}
+	return resp, nil
+}
+
+// GetStats summarizes history for a range, as in the digest command
+func (s *Server) GetStats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	loc := time.UTC
+	if req.GetTimezone() != "" {
+		var err error
+		loc, err = time.LoadLocation(req.GetTimezone())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid timezone %q: %v", req.GetTimezone(), err)
+		}
+	}
+
+	end := asTime(req.GetEnd())
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := asTime(req.GetStart())
+	if start.IsZero() {
+		start = end.AddDate(0, 0, -7)
+	}
+	if !start.Before(end) {
+		return nil, status.Error(codes.InvalidArgument, "start must be before end")
+	}
+
+	entries, err := webrecap.QueryHistory(ctx, webrecap.HistoryOptions{
+		Browser: webrecap.BrowserType(req.GetBrowser()),
+		Start:   start,
+		End:     end,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	opts := digest.Options{TopDomains: int(req.GetTopDomains()), TopPages: int(req.GetTopPages())}
+	if opts.TopDomains <= 0 {
+		opts.TopDomains = 10
+	}
+	if opts.TopPages <= 0 {
+		opts.TopPages = 15
+	}
+	report := digest.Build(s.Blocklist.History(entries), req.GetBrowser(), start, end, loc, opts)
+
+	return statsResponse(report), nil
+}
+
+func statsResponse(report models.DigestReport) *pb.StatsResponse {
+	resp := &pb.StatsResponse{
+		TotalVisits:   int32(report.TotalVisits),
+		UniquePages:   int32(report.UniquePages),
+		UniqueDomains: int32(report.UniqueDomains),
+	}
+	for _, d := range report.Days {
+		resp.Days = append(resp.Days, &pb.DayCount{Date: d.Date, Visits: int32(d.Visits)})
+	}
+	for _, d := range report.TopDomains {
+		resp.TopDomains = append(resp.TopDomains, &pb.DomainCount{Domain: d.Domain, Visits: int32(d.Visits)})
+	}
+	for _, p := range report.TopPages {
+		resp.TopPages = append(resp.TopPages, &pb.PageCount{Url: p.URL, Title: p.Title, Domain: p.Domain, Visits: int32(p.Visits)})
+	}
+	return resp
+}
+
+// statusError maps library errors to gRPC status codes
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, webrecap.ErrDatabaseNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, webrecap.ErrUnsupportedBrowser):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func asTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func asTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,141 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
+	"github.com/rzolkos/web-recap/pkg/webrecap"
+	pb "github.com/rzolkos/web-recap/pkg/webrecap/webrecapv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestClient serves s over an in-memory connection
+func newTestClient(t *testing.T, s *Server) pb.WebRecapClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := Register(s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewWebRecapClient(conn)
+}
+
+// registerChromeFixture registers a Chromium fork whose History has one
+// visit per URL at the given times
+func registerChromeFixture(t *testing.T, browserType string, visits map[string]time.Time) {
+	t.Helper()
+
+	var rows []testutil.ChromeVisit
+	for url, ts := range visits {
+		rows = append(rows, testutil.ChromeVisit{URL: url, Title: url, Time: ts})
+	}
+	profile := filepath.Dir(testutil.NewChromeHistoryDB(t, rows...))
+
+	err := webrecap.RegisterBrowser(webrecap.BrowserDefinition{
+		Type:   webrecap.BrowserType(browserType),
+		Engine: webrecap.EngineChromium,
+		Paths:  []string{profile},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListHistoryAndStats(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	registerChromeFixture(t, "grpcfork", map[string]time.Time{
+		"https://go.dev/doc":      day.Add(9 * time.Hour),
+		"https://go.dev/blog":     day.Add(10 * time.Hour),
+		"https://example.com/old": day.Add(-48 * time.Hour),
+	})
+	client := newTestClient(t, New("laptop"))
+	ctx := context.Background()
+
+	stream, err := client.ListHistory(ctx, &pb.HistoryRequest{
+		Browser: "grpcfork",
+		Start:   timestamppb.New(day),
+		End:     timestamppb.New(day.AddDate(0, 0, 1)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.GetSource() != "laptop" {
+			t.Errorf("source = %q, want laptop", entry.GetSource())
+		}
+		urls = append(urls, entry.GetUrl())
+	}
+	if len(urls) != 2 || urls[0] != "https://go.dev/blog" {
+		t.Errorf("history = %v, want the two go.dev visits newest first", urls)
+	}
+
+	stats, err := client.GetStats(ctx, &pb.StatsRequest{
+		Browser: "grpcfork",
+		Start:   timestamppb.New(day.AddDate(0, 0, -3)),
+		End:     timestamppb.New(day.AddDate(0, 0, 1)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.GetTotalVisits() != 3 || stats.GetUniqueDomains() != 2 || len(stats.GetDays()) != 4 {
+		t.Errorf("stats = %d visits, %d domains, %d days; want 3, 2, 4",
+			stats.GetTotalVisits(), stats.GetUniqueDomains(), len(stats.GetDays()))
+	}
+	if top := stats.GetTopDomains(); len(top) == 0 || top[0].GetDomain() != "go.dev" || top[0].GetVisits() != 2 {
+		t.Errorf("top domains = %v", top)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	client := newTestClient(t, New(""))
+	ctx := context.Background()
+
+	_, err := client.GetStats(ctx, &pb.StatsRequest{Browser: "chrome", Timezone: "Not/AZone"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("bad timezone: got %v, want InvalidArgument", err)
+	}
+
+	// A registered browser whose profile has been removed
+	profile := t.TempDir()
+	if err := os.WriteFile(filepath.Join(profile, "History"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := webrecap.RegisterBrowser(webrecap.BrowserDefinition{Type: "gonefork", Engine: webrecap.EngineChromium, Paths: []string{profile}}); err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(profile)
+
+	_, err = client.ListTabs(ctx, &pb.TabsRequest{Browser: "gonefork"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("missing profile: got %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package heatmap renders daily values as a GitHub-style calendar: a column
+// per week, a row per weekday from Sunday, and each day shaded by its value.
+package heatmap
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"time"
+)
+
+// Day is one cell of the calendar. Date is the day at midnight UTC, and
+// Label is the tooltip shown for it in SVG output.
+type Day struct {
+	Date  time.Time
+	Value float64
+	Label string
+}
+
+// Options are the title drawn above the SVG calendar and the scale of PNG
+// output (default 2, so cells are 22 pixels)
+type Options struct {
+	Title string
+	Scale int
+}
+
+// Cell geometry, in SVG user units
+const (
+	cellSize   = 11
+	cellStep   = 14
+	leftMargin = 32
+	topMargin  = 22
+	titleSpace = 24
+	legendRoom = 30
+	rightRoom  = 12
+)
+
+// palette is GitHub's contribution scale, from no activity to the most
+var palette = []color.RGBA{
+	{0xeb, 0xed, 0xf0, 0xff},
+	{0x9b, 0xe9, 0xa8, 0xff},
+	{0x40, 0xc4, 0x63, 0xff},
+	{0x30, 0xa1, 0x4e, 0xff},
+	{0x21, 0x6e, 0x39, 0xff},
+}
+
+// cell is a day's square in the calendar
+type cell struct {
+	x, y  int
+	level int
+	day   Day
+}
+
+// grid lays out days, which must be consecutive and oldest first
+type grid struct {
+	width, height int
+	top           int
+	// legendX and legendY are where the legend's first square goes
+	legendX, legendY int
+	cells            []cell
+	months           []monthLabel
+}
+
+type monthLabel struct {
+	x    int
+	name string
+}
+
+// level buckets v into one of the palette's shades: 0 for nothing, and 1
+// to 4 in quarters of the largest value
+func level(v, max float64) int {
+	if v <= 0 || max <= 0 {
+		return 0
+	}
+	l := int(math.Ceil(v / max * 4))
+	if l < 1 {
+		return 1
+	}
+	if l > 4 {
+		return 4
+	}
+	return l
+}
+
+func layout(days []Day, opts Options) grid {
+	g := grid{top: topMargin}
+	if opts.Title != "" {
+		g.top += titleSpace
+	}
+	max := 0.0
+	for _, d := range days {
+		max = math.Max(max, d.Value)
+	}
+
+	offset := 0
+	if len(days) > 0 {
+		offset = int(days[0].Date.Weekday())
+	}
+	columns := (offset + len(days) + 6) / 7
+	for i, d := range days {
+		col, row := (offset+i)/7, (offset+i)%7
+		x, y := leftMargin+col*cellStep, g.top+row*cellStep
+		g.cells = append(g.cells, cell{x: x, y: y, level: level(d.Value, max), day: d})
+		// Name each month above the week its first day is in. The partial
+		// month the calendar starts in loses its name when the next one
+		// would crowd it.
+		if i == 0 || d.Date.Day() == 1 {
+			if n := len(g.months); n > 0 && x-g.months[n-1].x < 3*cellStep {
+				g.months = g.months[:n-1]
+			}
+			g.months = append(g.months, monthLabel{x: x, name: d.Date.Format("Jan")})
+		}
+	}
+	g.width = leftMargin + columns*cellStep + rightRoom
+	g.height = g.top + 7*cellStep + legendRoom
+	g.legendX = g.width - rightRoom - len(palette)*cellStep - 30
+	g.legendY = g.top + 7*cellStep + 8
+	return g
+}
+
+// SVG writes the calendar as an SVG image, with month and weekday labels,
+// a legend, and a tooltip on every day
+func SVG(w io.Writer, days []Day, opts Options) error {
+	g := layout(days, opts)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="-apple-system, Segoe UI, Helvetica, Arial, sans-serif" font-size="10" fill="#57606a">`+"\n",
+		g.width, g.height, g.width, g.height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", g.width, g.height)
+	if opts.Title != "" {
+		fmt.Fprintf(bw, `<text x="%d" y="16" font-size="14" fill="#24292f">%s</text>`+"\n", leftMargin, html.EscapeString(opts.Title))
+	}
+	for _, m := range g.months {
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", m.x, g.top-6, m.name)
+	}
+	for _, row := range []int{1, 3, 5} {
+		fmt.Fprintf(bw, `<text x="0" y="%d">%s</text>`+"\n", g.top+row*cellStep+9, time.Weekday(row).String()[:3])
+	}
+	for _, c := range g.cells {
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s</title></rect>`+"\n",
+			c.x, c.y, cellSize, cellSize, hex(palette[c.level]), html.EscapeString(c.day.Label))
+	}
+
+	// Less [] [] [] [] [] More, under the last weeks
+	x, legendY := g.legendX, g.legendY
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">Less</text>`+"\n", x-4, legendY+9)
+	for i, c := range palette {
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/>`+"\n", x+i*cellStep, legendY, cellSize, cellSize, hex(c))
+	}
+	fmt.Fprintf(bw, `<text x="%d" y="%d">More</text>`+"\n", x+len(palette)*cellStep+2, legendY+9)
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// PNG writes the calendar as a PNG image. It has the cells and legend
+// squares of the SVG but no text.
+func PNG(w io.Writer, days []Day, opts Options) error {
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = 2
+	}
+	g := layout(days, opts)
+	img := image.NewRGBA(image.Rect(0, 0, g.width*scale, g.height*scale))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	square := func(x, y int, c color.RGBA) {
+		r := image.Rect(x*scale, y*scale, (x+cellSize)*scale, (y+cellSize)*scale)
+		draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	for _, c := range g.cells {
+		square(c.x, c.y, palette[c.level])
+	}
+	for i, c := range palette {
+		square(g.legendX+i*cellStep, g.legendY, c)
+	}
+	return png.Encode(w, img)
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
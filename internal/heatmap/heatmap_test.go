@@ -0,0 +1,59 @@
+package heatmap
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevel(t *testing.T) {
+	for _, tt := range []struct {
+		v, max float64
+		want   int
+	}{{0, 10, 0}, {0.1, 10, 1}, {2.5, 10, 1}, {2.6, 10, 2}, {10, 10, 4}, {5, 0, 0}} {
+		if got := level(tt.v, tt.max); got != tt.want {
+			t.Errorf("level(%v, %v) = %d, want %d", tt.v, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	// Wednesday, so the first week has three empty rows above it
+	start := time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC)
+	var days []Day
+	for i := 0; i < 40; i++ {
+		days = append(days, Day{Date: start.AddDate(0, 0, i), Value: float64(i % 5), Label: "day <" + start.AddDate(0, 0, i).Format("2006-01-02") + ">"})
+	}
+
+	g := layout(days, Options{})
+	if first := g.cells[0]; first.y != g.top+3*cellStep || first.x != leftMargin {
+		t.Errorf("first cell at %d,%d, want the Wednesday row of the first column", first.x, first.y)
+	}
+	if len(g.months) != 2 || g.months[0].name != "Feb" || g.months[1].name != "Mar" {
+		t.Errorf("month labels = %+v, want Feb and Mar (Jan is crowded out)", g.months)
+	}
+
+	var svg bytes.Buffer
+	if err := SVG(&svg, days, Options{Title: "Tabs & visits"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Tabs &amp; visits", "<title>day &lt;2025-01-29&gt;</title>", ">Mon<", "</svg>"} {
+		if !strings.Contains(svg.String(), want) {
+			t.Errorf("SVG is missing %q", want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, days, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := img.Bounds().Dx(); w != 2*g.width {
+		t.Errorf("PNG is %d pixels wide, want %d", w, 2*g.width)
+	}
+}
@@ -0,0 +1,146 @@
+package incremental
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// State records the newest entry exported for each browser so repeated
+// runs only emit entries newer than the previous one. Runs records, for
+// --since-last-run, the end of the range each browser was last read up to.
+type State struct {
+	Browsers  map[string]time.Time `json:"browsers"`
+	Runs      map[string]time.Time `json:"runs,omitempty"`
+	UpdatedAt time.Time            `json:"updated_at"`
+
+	// ByRun makes Since and Keep use Runs instead of the newest entries
+	ByRun bool `json:"-"`
+}
+
+// DefaultPath returns web-recap/state.json in the user cache directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web-recap", "state.json"), nil
+}
+
+// Load reads the state file at path. A missing file is an empty state.
+func Load(path string) (*State, error) {
+	s := &State{Browsers: map[string]time.Time{}, Runs: map[string]time.Time{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %v", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %v", path, err)
+	}
+	if s.Browsers == nil {
+		s.Browsers = map[string]time.Time{}
+	}
+	if s.Runs == nil {
+		s.Runs = map[string]time.Time{}
+	}
+	return s, nil
+}
+
+// Save writes the state to path, replacing the previous file atomically
+func (s *State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+	s.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	return nil
+}
+
+// Since returns the oldest high-water mark, or with ByRun the oldest run
+// end, or the zero time when no run has been recorded yet
+func (s *State) Since() time.Time {
+	marks := s.Browsers
+	if s.ByRun {
+		marks = s.Runs
+	}
+	var since time.Time
+	for _, t := range marks {
+		if since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+	return since
+}
+
+// Filter returns the entries newer than their browser's high-water mark
+func (s *State) Filter(entries []models.HistoryEntry) []models.HistoryEntry {
+	filtered := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if s.Keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Keep reports whether e is newer than its browser's high-water mark, or
+// with ByRun whether it is at or after the end of its browser's last run
+func (s *State) Keep(e models.HistoryEntry) bool {
+	if s.ByRun {
+		return !e.Timestamp.Before(s.Runs[Key(e)])
+	}
+	return e.Timestamp.After(s.Browsers[Key(e)])
+}
+
+// Advance moves each browser's high-water mark up to its newest entry
+func (s *State) Advance(entries []models.HistoryEntry) {
+	for _, e := range entries {
+		if e.Timestamp.After(s.Browsers[Key(e)]) {
+			s.Browsers[Key(e)] = e.Timestamp.UTC()
+		}
+	}
+}
+
+// MarkRun records that browsers were read up to end, so the next ByRun
+// range starts there
+func (s *State) MarkRun(browsers []string, end time.Time) {
+	for _, b := range browsers {
+		s.Runs[b] = end.UTC()
+	}
+}
+
+// Key returns the browser e's high-water mark is kept under: its browser
+// type, so Chrome, Edge, Brave, and other Chromium browsers each have their
+// own, or e.Browser for entries read from the archive
+func Key(e models.HistoryEntry) string {
+	if e.BrowserType != "" {
+		return e.BrowserType
+	}
+	return e.Browser
+}
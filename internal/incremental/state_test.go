@@ -0,0 +1,126 @@
+package incremental
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "state.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Since().IsZero() {
+		t.Fatalf("new state Since = %v, want zero", s.Since())
+	}
+
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	first := []models.HistoryEntry{
+		{URL: "https://a.example/", Browser: "chrome", Timestamp: base},
+		{URL: "https://b.example/", Browser: "chrome", Timestamp: base.Add(time.Hour)},
+		{URL: "https://c.example/", Browser: "firefox", Timestamp: base.Add(30 * time.Minute)},
+	}
+	if got := s.Filter(first); len(got) != 3 {
+		t.Fatalf("first run Filter = %d entries, want 3", len(got))
+	}
+	s.Advance(first)
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := base.Add(30 * time.Minute); !s.Since().Equal(want) {
+		t.Errorf("Since = %v, want %v", s.Since(), want)
+	}
+
+	// The second run re-reads overlapping history
+	second := append(first,
+		models.HistoryEntry{URL: "https://d.example/", Browser: "chrome", Timestamp: base.Add(2 * time.Hour)},
+		models.HistoryEntry{URL: "https://e.example/", Browser: "firefox", Timestamp: base.Add(45 * time.Minute)},
+		models.HistoryEntry{URL: "https://f.example/", Browser: "safari", Timestamp: base},
+	)
+	got := s.Filter(second)
+	var urls []string
+	for _, e := range got {
+		urls = append(urls, e.URL)
+	}
+	want := []string{"https://d.example/", "https://e.example/", "https://f.example/"}
+	if len(urls) != len(want) {
+		t.Fatalf("second run Filter = %v, want %v", urls, want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("second run Filter = %v, want %v", urls, want)
+		}
+	}
+}
+
+func TestStateChromiumBrowsers(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := &State{Browsers: map[string]time.Time{}}
+
+	// Edge is locked on the first run, so only Chrome is read
+	s.Advance([]models.HistoryEntry{
+		{URL: "https://a.example/", Browser: "chrome", BrowserType: "chrome", Timestamp: base.Add(2 * time.Hour)},
+	})
+
+	// Edge's older visits are still new to Edge
+	edge := models.HistoryEntry{URL: "https://b.example/", Browser: "chrome", BrowserType: "edge", Timestamp: base}
+	if got := s.Filter([]models.HistoryEntry{edge}); len(got) != 1 {
+		t.Fatalf("Edge entry older than Chrome's mark was dropped")
+	}
+	s.Advance([]models.HistoryEntry{edge})
+	if !s.Browsers["edge"].Equal(base) || !s.Browsers["chrome"].Equal(base.Add(2*time.Hour)) {
+		t.Errorf("marks = %v, want chrome and edge kept apart", s.Browsers)
+	}
+	if !s.Since().Equal(base) {
+		t.Errorf("Since = %v, want %v", s.Since(), base)
+	}
+}
+
+func TestStateByRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ByRun = true
+
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	s.Advance([]models.HistoryEntry{{Browser: "chrome", Timestamp: base.Add(-time.Hour)}})
+	if !s.Since().IsZero() {
+		t.Fatalf("Since = %v, want zero: newest entries are not run ends", s.Since())
+	}
+	s.MarkRun([]string{"chrome", "firefox"}, base)
+	s.MarkRun([]string{"chrome"}, base.Add(time.Hour))
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ByRun = true
+	if !s.Since().Equal(base) {
+		t.Errorf("Since = %v, want the oldest run end %v", s.Since(), base)
+	}
+	entries := []models.HistoryEntry{
+		{URL: "https://a.example/", BrowserType: "chrome", Timestamp: base.Add(time.Hour)},
+		{URL: "https://b.example/", BrowserType: "chrome", Timestamp: base.Add(30 * time.Minute)},
+		{URL: "https://c.example/", BrowserType: "firefox", Timestamp: base},
+		{URL: "https://d.example/", BrowserType: "edge", Timestamp: base},
+	}
+	got := s.Filter(entries)
+	if len(got) != 3 || got[0].URL != "https://a.example/" || got[1].URL != "https://c.example/" {
+		t.Errorf("Filter kept %+v, want a, c, and d: a run's end is the next run's start", got)
+	}
+}
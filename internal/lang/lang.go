@@ -0,0 +1,200 @@
+// Package lang guesses the language of short texts such as page titles
+package lang
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Undetermined is the ISO 639 code a filter uses for texts whose language
+// could not be guessed
+const Undetermined = "und"
+
+// stopwords are common function words of the Latin-script languages told
+// apart by Detect. Words shared by several languages count for each.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "for", "is", "on", "with", "how", "what", "your", "you", "are", "from", "this", "that", "why", "best", "new", "at", "by", "an", "or", "my", "it", "can", "not"},
+	"de": {"der", "die", "das", "und", "ist", "mit", "von", "für", "den", "ein", "eine", "nicht", "auf", "im", "zu", "wie", "auch", "sich", "dem", "des", "bei", "oder", "aus", "nach", "über", "ich", "sie", "wir"},
+	"fr": {"le", "la", "les", "des", "et", "est", "une", "un", "du", "pour", "dans", "sur", "avec", "pas", "qui", "que", "au", "aux", "ce", "plus", "par", "comment", "vous", "nous", "ou", "sont", "être", "je"},
+	"es": {"el", "la", "los", "las", "de", "y", "que", "en", "un", "una", "es", "por", "con", "para", "del", "al", "como", "más", "qué", "cómo", "su", "se", "lo", "no", "pero", "sus", "muy", "sobre"},
+	"it": {"il", "di", "che", "è", "la", "per", "un", "una", "con", "non", "del", "della", "dei", "delle", "gli", "nel", "nella", "sono", "come", "anche", "più", "da", "su", "alla", "ai", "questo", "cosa", "perché"},
+	"pt": {"o", "os", "as", "de", "e", "que", "do", "da", "dos", "das", "em", "um", "uma", "para", "com", "não", "por", "mais", "como", "no", "na", "são", "seu", "sua", "ao", "você", "também", "é"},
+	"nl": {"de", "het", "een", "en", "van", "is", "op", "voor", "met", "niet", "zijn", "te", "dat", "die", "aan", "ook", "bij", "naar", "hoe", "wat", "uit", "je", "jij", "worden", "door", "maar", "nog", "over"},
+	"sv": {"och", "att", "det", "är", "som", "en", "på", "för", "med", "av", "till", "den", "inte", "om", "har", "ett", "jag", "vi", "hur", "vad", "från", "kan", "eller", "så", "nya", "bästa", "din", "sig"},
+	"pl": {"i", "w", "na", "z", "się", "nie", "do", "jest", "to", "że", "jak", "co", "od", "po", "dla", "o", "czy", "przez", "są", "oraz", "ale", "jego", "jej", "tak", "już", "może", "być", "ze"},
+}
+
+// letters are characters that only some Latin-script languages use
+var letters = map[rune][]string{
+	'ß': {"de"}, 'ä': {"de", "sv"}, 'ö': {"de", "sv"}, 'ü': {"de"},
+	'ç': {"fr", "pt"}, 'œ': {"fr"}, 'è': {"fr", "it"}, 'ê': {"fr", "pt"}, 'à': {"fr", "it", "pt"}, 'ù': {"fr", "it"},
+	'ñ': {"es"}, '¿': {"es"}, '¡': {"es"}, 'ó': {"es", "pt", "pl"}, 'í': {"es", "pt"}, 'á': {"es", "pt"},
+	'ã': {"pt"}, 'õ': {"pt"}, 'â': {"pt", "fr"},
+	'å': {"sv"},
+	'ą': {"pl"}, 'ę': {"pl"}, 'ł': {"pl"}, 'ś': {"pl"}, 'ź': {"pl"}, 'ż': {"pl"}, 'ć': {"pl"}, 'ń': {"pl"},
+	'ĳ': {"nl"},
+}
+
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}()
+
+// Codes returns the languages Detect can return, sorted
+func Codes() []string {
+	codes := []string{"ar", "el", "fa", "he", "hi", "ja", "ko", "ru", "th", "uk", "zh"}
+	for lang := range stopwords {
+		codes = append(codes, lang)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Detect returns the ISO 639-1 code of the language text is most likely in,
+// or "" when it can't tell, as with a title of one brand name. Non-Latin
+// scripts are recognized by their letters; Latin-script languages by their
+// common words and accented letters.
+func Detect(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	scores := make(map[string]int)
+	for _, r := range strings.ToLower(text) {
+		for _, lang := range letters[r] {
+			scores[lang]++
+		}
+	}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		for _, lang := range stopwordIndex[word] {
+			scores[lang] += 2
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < 2 || tied {
+		return ""
+	}
+	return best
+}
+
+// detectScript returns the language of a text mostly in a script used by
+// one language, or "" when most of its letters are Latin
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	latin, total := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				counts["uk"] += 100
+			}
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Arabic, r):
+			if strings.ContainsRune("پچژگ", r) {
+				counts["fa"] += 100
+			}
+			counts["ar"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+	}
+	if total == 0 || latin*2 >= total {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	switch {
+	case counts["uk"] > counts["ru"]:
+		return "uk"
+	case counts["fa"] > counts["ar"]:
+		return "fa"
+	}
+	best, bestCount := "", 0
+	for lang, n := range counts {
+		if lang == "uk" || lang == "fa" {
+			continue
+		}
+		if n > bestCount || (n == bestCount && lang < best) {
+			best, bestCount = lang, n
+		}
+	}
+	return best
+}
+
+// Filter keeps texts in a set of languages
+type Filter struct {
+	langs map[string]bool
+}
+
+// ParseFilter parses a list of ISO 639-1 codes such as ["en", "de"]. "und"
+// keeps texts whose language could not be detected, which are otherwise left
+// out. An empty list gives a nil filter, which keeps everything.
+func ParseFilter(codes []string) (*Filter, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]bool)
+	for _, c := range Codes() {
+		known[c] = true
+	}
+	f := &Filter{langs: make(map[string]bool)}
+	for _, c := range codes {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c != Undetermined && !known[c] {
+			return nil, fmt.Errorf("unsupported language %q (use %s, or und for undetected)", c, strings.Join(Codes(), ", "))
+		}
+		f.langs[c] = true
+	}
+	return f, nil
+}
+
+// Keep reports whether a text detected as lang ("" when undetected) passes
+// the filter. A nil filter keeps everything.
+func (f *Filter) Keep(lang string) bool {
+	if f == nil {
+		return true
+	}
+	if lang == "" {
+		return f.langs[Undetermined]
+	}
+	return f.langs[lang]
+}
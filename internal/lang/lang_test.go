@@ -0,0 +1,54 @@
+package lang
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"How to cancel a context in Go - Stack Overflow", "en"},
+		{"Die besten Wanderwege in der Schweiz für Familien", "de"},
+		{"Comment installer Python sur Windows et macOS", "fr"},
+		{"¿Cómo funciona la inflación en España?", "es"},
+		{"Come fare il pane in casa con il lievito madre", "it"},
+		{"Receitas fáceis para o jantar de domingo", "pt"},
+		{"Hoe werkt de belasting voor zzp'ers in het nieuwe jaar", "nl"},
+		{"Så här fungerar det nya skattesystemet för företag", "sv"},
+		{"Jak zrobić pierogi ruskie – przepis krok po kroku", "pl"},
+		{"Как научиться программировать на Go", "ru"},
+		{"Як навчитися програмувати: поради для початківців", "uk"},
+		{"東京の天気予報 - ウェザーニュース", "ja"},
+		{"北京天气预报", "zh"},
+		{"서울 날씨 예보", "ko"},
+		{"Ελληνική κουζίνα συνταγές", "el"},
+		{"أخبار اليوم", "ar"},
+		{"GitHub", ""},
+		{"Inbox (3)", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f, err := ParseFilter([]string{"en", " DE "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Keep("en") || !f.Keep("de") || f.Keep("fr") || f.Keep("") {
+		t.Errorf("en,de filter kept the wrong languages")
+	}
+	if f, _ := ParseFilter([]string{"en", "und"}); !f.Keep("") {
+		t.Errorf("und did not keep undetected titles")
+	}
+	if f, _ := ParseFilter(nil); !f.Keep("fr") || !f.Keep("") {
+		t.Errorf("nil filter dropped entries")
+	}
+	if _, err := ParseFilter([]string{"xx"}); err == nil {
+		t.Errorf("ParseFilter accepted an unknown code")
+	}
+}
@@ -0,0 +1,217 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Provider identifies an LLM backend
+type Provider string
+
+const (
+	ProviderOpenAI    Provider = "openai"
+	ProviderAnthropic Provider = "anthropic"
+	ProviderOllama    Provider = "ollama"
+)
+
+// Default models used when none is configured
+var defaultModels = map[Provider]string{
+	ProviderOpenAI:    "gpt-4o-mini",
+	ProviderAnthropic: "claude-3-5-haiku-latest",
+	ProviderOllama:    "llama3.1",
+}
+
+// Default API endpoints, overridable with Config.BaseURL
+var defaultBaseURLs = map[Provider]string{
+	ProviderOpenAI:    "https://api.openai.com",
+	ProviderAnthropic: "https://api.anthropic.com",
+	ProviderOllama:    "http://localhost:11434",
+}
+
+// Config holds LLM backend settings
+type Config struct {
+	Provider  Provider `json:"provider,omitempty"`
+	Model     string   `json:"model,omitempty"`
+	APIKey    string   `json:"api_key,omitempty"`
+	BaseURL   string   `json:"base_url,omitempty"`
+	MaxTokens int      `json:"max_tokens,omitempty"`
+}
+
+// Client sends a single prompt to an LLM and returns its reply
+type Client interface {
+	Complete(ctx context.Context, system, prompt string) (string, error)
+}
+
+// ParseProvider validates a provider name
+func ParseProvider(s string) (Provider, error) {
+	switch p := Provider(strings.ToLower(strings.TrimSpace(s))); p {
+	case ProviderOpenAI, ProviderAnthropic, ProviderOllama:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported LLM provider: %s (use openai, anthropic, or ollama)", s)
+	}
+}
+
+// LoadConfigFromEnv reads settings for provider from environment variables
+func LoadConfigFromEnv(provider Provider) Config {
+	config := Config{
+		Provider: provider,
+		Model:    os.Getenv("LLM_MODEL"),
+	}
+
+	switch provider {
+	case ProviderOpenAI:
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+		config.BaseURL = os.Getenv("OPENAI_BASE_URL")
+	case ProviderAnthropic:
+		config.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		config.BaseURL = os.Getenv("ANTHROPIC_BASE_URL")
+	case ProviderOllama:
+		config.BaseURL = os.Getenv("OLLAMA_HOST")
+	}
+
+	return config
+}
+
+// ResolveConfig combines flag, environment, and config file settings.
+// Flags take precedence over environment variables, which take precedence
+// over the config file. Without an explicit provider, one is picked from
+// whichever API key is present, falling back to a local Ollama server.
+func ResolveConfig(flags, file Config) Config {
+	provider := flags.Provider
+	if provider == "" {
+		provider = Provider(strings.ToLower(os.Getenv("LLM_PROVIDER")))
+	}
+	if provider == "" {
+		provider = file.Provider
+	}
+	if provider == "" {
+		switch {
+		case os.Getenv("ANTHROPIC_API_KEY") != "":
+			provider = ProviderAnthropic
+		case os.Getenv("OPENAI_API_KEY") != "":
+			provider = ProviderOpenAI
+		default:
+			provider = ProviderOllama
+		}
+	}
+
+	merged := flags
+	merged.Provider = provider
+	merged = mergeConfig(merged, LoadConfigFromEnv(provider))
+	// File settings for another provider don't apply
+	if file.Provider == "" || file.Provider == provider {
+		merged = mergeConfig(merged, file)
+	}
+	return merged
+}
+
+// mergeConfig fills empty fields of primary from fallback
+func mergeConfig(primary, fallback Config) Config {
+	if primary.Model == "" {
+		primary.Model = fallback.Model
+	}
+	if primary.APIKey == "" {
+		primary.APIKey = fallback.APIKey
+	}
+	if primary.BaseURL == "" {
+		primary.BaseURL = fallback.BaseURL
+	}
+	if primary.MaxTokens == 0 {
+		primary.MaxTokens = fallback.MaxTokens
+	}
+	return primary
+}
+
+// NewClient creates a client for the configured provider, defaulting to a
+// local Ollama server when no provider is set
+func NewClient(config Config) (Client, error) {
+	if config.Provider == "" {
+		config.Provider = ProviderOllama
+	}
+	provider, err := ParseProvider(string(config.Provider))
+	if err != nil {
+		return nil, err
+	}
+	config.Provider = provider
+
+	if config.Model == "" {
+		config.Model = defaultModels[provider]
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultBaseURLs[provider]
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if provider == ProviderOllama && !strings.Contains(config.BaseURL, "://") {
+		// OLLAMA_HOST is commonly set as host:port
+		config.BaseURL = "http://" + config.BaseURL
+	}
+	if config.MaxTokens == 0 {
+		config.MaxTokens = 1024
+	}
+
+	switch provider {
+	case ProviderOpenAI:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("openai requires an API key (OPENAI_API_KEY or config)")
+		}
+		return &openAIClient{config: config}, nil
+	case ProviderAnthropic:
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("anthropic requires an API key (ANTHROPIC_API_KEY or config)")
+		}
+		return &anthropicClient{config: config}, nil
+	default:
+		return &ollamaClient{config: config}, nil
+	}
+}
+
+// LLM responses can take a while for large prompts and local models
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// postJSON sends payload to url with headers and decodes the JSON reply into result
+func postJSON(ctx context.Context, url string, headers map[string]string, payload, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read LLM response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 512 {
+			msg = msg[:512]
+		}
+		return fmt.Errorf("LLM API returned %s: %s", resp.Status, msg)
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientsComplete(t *testing.T) {
+	tests := []struct {
+		provider Provider
+		path     string
+		reply    string
+		check    func(*testing.T, *http.Request, map[string]interface{})
+	}{
+		{
+			provider: ProviderOpenAI,
+			path:     "/v1/chat/completions",
+			reply:    `{"choices":[{"message":{"role":"assistant","content":" recap "}}]}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if got := r.Header.Get("Authorization"); got != "Bearer key" {
+					t.Errorf("Authorization = %q", got)
+				}
+			},
+		},
+		{
+			provider: ProviderAnthropic,
+			path:     "/v1/messages",
+			reply:    `{"content":[{"type":"text","text":"recap"}]}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if got := r.Header.Get("x-api-key"); got != "key" {
+					t.Errorf("x-api-key = %q", got)
+				}
+				if body["system"] != "sys" {
+					t.Errorf("system = %v, want sys", body["system"])
+				}
+			},
+		},
+		{
+			provider: ProviderOllama,
+			path:     "/api/chat",
+			reply:    `{"message":{"role":"assistant","content":"recap"}}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if body["stream"] != false {
+					t.Errorf("stream = %v, want false", body["stream"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("path = %s, want %s", r.URL.Path, tt.path)
+				}
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatalf("decode request: %v", err)
+				}
+				if body["model"] != "test-model" {
+					t.Errorf("model = %v, want test-model", body["model"])
+				}
+				tt.check(t, r, body)
+				w.Write([]byte(tt.reply))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Config{Provider: tt.provider, Model: "test-model", APIKey: "key", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			got, err := client.Complete(context.Background(), "sys", "prompt")
+			if err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			if got != "recap" {
+				t.Errorf("Complete = %q, want recap", got)
+			}
+		})
+	}
+}
+
+func TestCompleteErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"bad key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{Provider: ProviderOpenAI, APIKey: "key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if _, err := client.Complete(context.Background(), "sys", "prompt"); err == nil {
+		t.Fatal("expected error for 401 response")
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	t.Setenv("LLM_PROVIDER", "")
+	t.Setenv("LLM_MODEL", "")
+	t.Setenv("OPENAI_API_KEY", "env-openai")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("OLLAMA_HOST", "")
+
+	// API key in the environment selects the provider
+	got := ResolveConfig(Config{}, Config{})
+	if got.Provider != ProviderOpenAI || got.APIKey != "env-openai" {
+		t.Errorf("auto: got %+v", got)
+	}
+
+	// Flags win over env, file settings for the chosen provider fill gaps
+	got = ResolveConfig(Config{Provider: ProviderOllama}, Config{Provider: ProviderOllama, Model: "mistral", BaseURL: "http://gpu:11434"})
+	if got.Provider != ProviderOllama || got.Model != "mistral" || got.BaseURL != "http://gpu:11434" || got.APIKey != "" {
+		t.Errorf("flag provider: got %+v", got)
+	}
+
+	// File settings for a different provider are ignored
+	got = ResolveConfig(Config{Provider: ProviderOpenAI}, Config{Provider: ProviderAnthropic, APIKey: "file-key", Model: "claude"})
+	if got.APIKey != "env-openai" || got.Model != "" {
+		t.Errorf("other provider file: got %+v", got)
+	}
+
+	if _, err := NewClient(Config{Provider: ProviderAnthropic}); err == nil {
+		t.Error("expected error for anthropic without API key")
+	}
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// SummarySystemPrompt sets up the model as a browsing-history summarizer
+const SummarySystemPrompt = `You summarize a person's web browsing history for them.
+Write a short narrative recap in plain prose: what they worked on, researched, or read,
+grouped into a few themes, mentioning notable pages or sites where helpful.
+Do not list every URL, do not speculate about private matters, and do not invent activity
+that is not in the data. Use Markdown headings or bullets only if they help readability.`
+
+// BuildSummaryPrompt renders the report as the user prompt for a summary,
+// followed by any extra instructions
+func BuildSummaryPrompt(report models.HistoryReport, instructions string) (string, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report: %v", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Here is my browsing history from %s to %s (%s, %d entries) as JSON.\n",
+		report.StartDate.Format("2006-01-02 15:04"), report.EndDate.Format("2006-01-02 15:04"),
+		report.Timezone, report.TotalEntries)
+	b.WriteString("Write a recap of what I did.\n")
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		b.WriteString("\n")
+		b.WriteString(instructions)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n```json\n")
+	b.Write(data)
+	b.WriteString("\n```\n")
+
+	return b.String(), nil
+}
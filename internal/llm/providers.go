@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type openAIClient struct {
+	config Config
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Complete calls the OpenAI chat completions API
+func (c *openAIClient) Complete(ctx context.Context, system, prompt string) (string, error) {
+	payload := map[string]interface{}{
+		"model":      c.config.Model,
+		"max_tokens": c.config.MaxTokens,
+		"messages": []openAIMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+	}
+
+	var result struct {
+		Choices []struct {
+			Message openAIMessage `json:"message"`
+		} `json:"choices"`
+	}
+	headers := map[string]string{"Authorization": "Bearer " + c.config.APIKey}
+	if err := postJSON(ctx, c.config.BaseURL+"/v1/chat/completions", headers, payload, &result); err != nil {
+		return "", err
+	}
+
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
+
+type anthropicClient struct {
+	config Config
+}
+
+// Complete calls the Anthropic messages API
+func (c *anthropicClient) Complete(ctx context.Context, system, prompt string) (string, error) {
+	payload := map[string]interface{}{
+		"model":      c.config.Model,
+		"max_tokens": c.config.MaxTokens,
+		"system":     system,
+		"messages": []map[string]string{
+			{"role": "user", "content": prompt},
+		},
+	}
+
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	headers := map[string]string{
+		"x-api-key":         c.config.APIKey,
+		"anthropic-version": "2023-06-01",
+	}
+	if err := postJSON(ctx, c.config.BaseURL+"/v1/messages", headers, payload, &result); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("anthropic returned no text content")
+	}
+	return strings.TrimSpace(text.String()), nil
+}
+
+type ollamaClient struct {
+	config Config
+}
+
+// Complete calls a local Ollama server's chat API
+func (c *ollamaClient) Complete(ctx context.Context, system, prompt string) (string, error) {
+	payload := map[string]interface{}{
+		"model":  c.config.Model,
+		"stream": false,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": prompt},
+		},
+		"options": map[string]int{"num_predict": c.config.MaxTokens},
+	}
+
+	var result struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := postJSON(ctx, c.config.BaseURL+"/api/chat", nil, payload, &result); err != nil {
+		return "", err
+	}
+
+	if result.Message.Content == "" {
+		return "", fmt.Errorf("ollama returned an empty response")
+	}
+	return strings.TrimSpace(result.Message.Content), nil
+}
@@ -0,0 +1,150 @@
+// Package metrics exposes browsing activity in the Prometheus text format
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/archive"
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// MinInterval is how long collected metrics are reused, so frequent scrapes
+// don't copy the browser databases every time
+const MinInterval = 15 * time.Second
+
+// Snapshot holds the values of one collection
+type Snapshot struct {
+	// VisitsLastHour counts visits in the hour before collection, by browser
+	VisitsLastHour map[string]int
+	// OpenTabs counts open tabs of Chromium-based browsers, by browser
+	OpenTabs map[string]int
+	// Up is 1 for browsers whose history could be read, 0 otherwise
+	Up map[string]int
+	// Archive is the sync state of every archived browser, if there is an
+	// archive
+	Archive []models.ArchiveStatus
+	// Duration is how long the collection took
+	Duration time.Duration
+}
+
+// Handler serves /metrics, collecting at most once per MinInterval
+type Handler struct {
+	// ArchivePath is the archive whose sync times are reported; empty
+	// leaves them out
+	ArchivePath string
+
+	mu        sync.Mutex
+	collected time.Time
+	body      []byte
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.body == nil || time.Since(h.collected) >= MinInterval {
+		var buf bytes.Buffer
+		if err := Write(&buf, Collect(r.Context(), h.ArchivePath)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.body, h.collected = buf.Bytes(), time.Now()
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(h.body)
+}
+
+// Collect reads the detected browsers and the archive. Failures are
+// reported through the up metric rather than failing the scrape.
+func Collect(ctx context.Context, archivePath string) Snapshot {
+	started := time.Now()
+	s := Snapshot{VisitsLastHour: map[string]int{}, OpenTabs: map[string]int{}, Up: map[string]int{}}
+
+	for _, b := range browser.NewDetector().Detect() {
+		name := string(b.Type)
+		visits, err := database.Query(ctx, &b, started.Add(-time.Hour).UTC(), started.UTC())
+		if err != nil {
+			s.Up[name] = 0
+			continue
+		}
+		s.Up[name] = 1
+		s.VisitsLastHour[name] += len(visits)
+
+		if browser.IsChromiumBased(b.Type) {
+			if path, err := browser.GetSessionPath(b.Type); err == nil {
+				if tabs, _, err := database.QueryTabs(ctx, &b, path); err == nil {
+					s.OpenTabs[name] += len(tabs)
+				}
+			}
+		}
+	}
+
+	if archivePath != "" {
+		if _, err := os.Stat(archivePath); err == nil {
+			if a, err := archive.OpenExisting(archivePath); err == nil {
+				s.Archive, _ = a.Status()
+				a.Close()
+			}
+		}
+	}
+
+	s.Duration = time.Since(started)
+	return s
+}
+
+// Write writes a snapshot in the Prometheus text exposition format
+func Write(w io.Writer, s Snapshot) error {
+	var b strings.Builder
+	gauge := func(name, help string, values map[string]int) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, k := range sortedKeys(values) {
+			fmt.Fprintf(&b, "%s{browser=\"%s\"} %d\n", name, escape(k), values[k])
+		}
+	}
+	gauge("web_recap_up", "Whether the browser's history could be read (1) or not (0).", s.Up)
+	gauge("web_recap_visits_last_hour", "Visits recorded in the last hour.", s.VisitsLastHour)
+	gauge("web_recap_open_tabs", "Open tabs (Chromium-based browsers only).", s.OpenTabs)
+
+	name := "web_recap_archive_last_sync_timestamp_seconds"
+	fmt.Fprintf(&b, "# HELP %s Unix time of the last archive sync or import.\n# TYPE %s gauge\n", name, name)
+	for _, a := range s.Archive {
+		fmt.Fprintf(&b, "%s{browser=\"%s\",kind=\"%s\"} %d\n", name, escape(a.Browser), escape(a.Kind), a.SyncedAt.Unix())
+	}
+	name = "web_recap_archive_entries"
+	fmt.Fprintf(&b, "# HELP %s Visits or bookmarks in the archive.\n# TYPE %s gauge\n", name, name)
+	for _, a := range s.Archive {
+		fmt.Fprintf(&b, "%s{browser=\"%s\",kind=\"%s\"} %d\n", name, escape(a.Browser), escape(a.Kind), a.Entries)
+	}
+
+	name = "web_recap_collect_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Time taken to collect these metrics.\n# TYPE %s gauge\n%s %s\n", name, name, name,
+		strconv.FormatFloat(s.Duration.Seconds(), 'f', -1, 64))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escape escapes a label value
+func escape(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestWrite(t *testing.T) {
+	s := Snapshot{
+		VisitsLastHour: map[string]int{"firefox": 3, "chrome": 12},
+		OpenTabs:       map[string]int{"chrome": 40},
+		Up:             map[string]int{"chrome": 1, "firefox": 1, `odd"name`: 0},
+		Archive: []models.ArchiveStatus{
+			{Browser: "chrome", Kind: "history", Entries: 500, SyncedAt: time.Unix(1700000000, 0)},
+		},
+		Duration: 250 * time.Millisecond,
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE web_recap_visits_last_hour gauge\nweb_recap_visits_last_hour{browser=\"chrome\"} 12\nweb_recap_visits_last_hour{browser=\"firefox\"} 3\n",
+		"web_recap_open_tabs{browser=\"chrome\"} 40\n",
+		"web_recap_up{browser=\"odd\\\"name\"} 0\n",
+		"web_recap_archive_last_sync_timestamp_seconds{browser=\"chrome\",kind=\"history\"} 1700000000\n",
+		"web_recap_archive_entries{browser=\"chrome\",kind=\"history\"} 500\n",
+		"web_recap_collect_duration_seconds 0.25\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// DailyActivity is the browsing on one day: its visits, and the minutes
+// estimated from the gaps between them
+type DailyActivity struct {
+	Date             string  `json:"date"`
+	Visits           int     `json:"visits"`
+	EstimatedMinutes float64 `json:"estimated_minutes"`
+}
+
+// DailyActivityReport represents the browsing on every day of a period
+type DailyActivityReport struct {
+	Browser     string          `json:"browser"`
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     time.Time       `json:"end_date"`
+	Timezone    string          `json:"timezone"`
+	Meta        *ReportMeta     `json:"meta,omitempty"`
+	Sources     []SourceStatus  `json:"sources,omitempty"`
+	Warnings    []string        `json:"warnings,omitempty"`
+	TotalDays   int             `json:"total_days"`
+	ActiveDays  int             `json:"active_days"`
+	TotalVisits int             `json:"total_visits"`
+	Days        []DailyActivity `json:"days"`
+}
+
+// DomainPair is two domains used in the same browsing sessions. Lift is how
+// many times more often they share a session than if they were used
+// independently: above 1 they go together, below 1 they tend not to.
+type DomainPair struct {
+	Domains   [2]string `json:"domains"`
+	Sessions  int       `json:"sessions"`
+	SessionsA int       `json:"sessions_a"`
+	SessionsB int       `json:"sessions_b"`
+	Lift      float64   `json:"lift"`
+}
+
+// DomainPairReport represents the domains used together in the sessions of
+// a period
+type DomainPairReport struct {
+	Browser           string         `json:"browser"`
+	StartDate         time.Time      `json:"start_date"`
+	EndDate           time.Time      `json:"end_date"`
+	Timezone          string         `json:"timezone"`
+	Meta              *ReportMeta    `json:"meta,omitempty"`
+	Sources           []SourceStatus `json:"sources,omitempty"`
+	Warnings          []string       `json:"warnings,omitempty"`
+	SessionGapMinutes int            `json:"session_gap_minutes"`
+	TotalSessions     int            `json:"total_sessions"`
+	TotalPairs        int            `json:"total_pairs"`
+	Pairs             []DomainPair   `json:"pairs"`
+}
+
+// ArticleReading is the time spent reading one content page. Minutes come
+// from the page's word count when it was fetched, otherwise from the time
+// its visits were in the foreground or the gaps after them; Source says
+// which: words, visit_duration, or gaps.
+type ArticleReading struct {
+	URL            string  `json:"url"`
+	Title          string  `json:"title,omitempty"`
+	Domain         string  `json:"domain"`
+	Visits         int     `json:"visits"`
+	Words          int     `json:"words,omitempty"`
+	ReadingMinutes float64 `json:"reading_minutes"`
+	Source         string  `json:"source"`
+}
+
+// ReadingReport splits the browsing of a period into time reading content
+// pages (news, blogs, docs) and time in everything else, the apps
+type ReadingReport struct {
+	Browser        string           `json:"browser"`
+	StartDate      time.Time        `json:"start_date"`
+	EndDate        time.Time        `json:"end_date"`
+	Timezone       string           `json:"timezone"`
+	Meta           *ReportMeta      `json:"meta,omitempty"`
+	Sources        []SourceStatus   `json:"sources,omitempty"`
+	Warnings       []string         `json:"warnings,omitempty"`
+	WordsPerMinute int              `json:"words_per_minute"`
+	ReadingMinutes float64          `json:"reading_minutes"`
+	AppMinutes     float64          `json:"app_minutes"`
+	TotalArticles  int              `json:"total_articles"`
+	Articles       []ArticleReading `json:"articles"`
+}
+
+// CategoryTime is the time spent on one category's sites in a day, against
+// its daily budget
+type CategoryTime struct {
+	Category      string  `json:"category"`
+	Minutes       float64 `json:"minutes"`
+	BudgetMinutes float64 `json:"budget_minutes"`
+	Over          bool    `json:"over,omitempty"`
+}
+
+// BudgetDay is the time spent in each budgeted category on one day
+type BudgetDay struct {
+	Date       string         `json:"date"`
+	Categories []CategoryTime `json:"categories"`
+}
+
+// BudgetOverrun is a day a category went over its budget
+type BudgetOverrun struct {
+	Date          string  `json:"date"`
+	Category      string  `json:"category"`
+	Minutes       float64 `json:"minutes"`
+	BudgetMinutes float64 `json:"budget_minutes"`
+}
+
+// BudgetReport checks the time spent in each category, every day of a
+// period, against the daily budgets set in the config file
+type BudgetReport struct {
+	Browser     string             `json:"browser"`
+	StartDate   time.Time          `json:"start_date"`
+	EndDate     time.Time          `json:"end_date"`
+	Timezone    string             `json:"timezone"`
+	Meta        *ReportMeta        `json:"meta,omitempty"`
+	Sources     []SourceStatus     `json:"sources,omitempty"`
+	Warnings    []string           `json:"warnings,omitempty"`
+	Budgets     map[string]float64 `json:"budgets"`
+	OverrunDays int                `json:"overrun_days"`
+	Overruns    []BudgetOverrun    `json:"overruns"`
+	Days        []BudgetDay        `json:"days"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// ArchiveStatus is the sync state of one browser and data kind in the archive
+type ArchiveStatus struct {
+	Browser  string    `json:"browser"`
+	Kind     string    `json:"kind"` // "history", "bookmarks", or an import ("takeout", "firefox-sync")
+	Entries  int       `json:"entries"`
+	Newest   time.Time `json:"newest"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// ArchiveSyncResult reports what one sync copied from a browser
+type ArchiveSyncResult struct {
+	Browser   string   `json:"browser"`
+	Visits    int      `json:"new_visits"`
+	Bookmarks int      `json:"new_bookmarks"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// TabCount is the number of tabs open in a browser at one tab snapshot
+type TabCount struct {
+	Time    time.Time `json:"time"`
+	Browser string    `json:"browser"`
+	Tabs    int       `json:"tabs"`
+	Windows int       `json:"windows"`
+}
+
+// ArchivedTab is a tab open at the latest snapshot of its browser, with when
+// it was first seen open
+type ArchivedTab struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Domain    string    `json:"domain"`
+	Browser   string    `json:"browser"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SearchResult is an archived page matching a full-text search
+type SearchResult struct {
+	URL        string    `json:"url"`
+	Title      string    `json:"title"`
+	Domain     string    `json:"domain"`
+	Score      float64   `json:"score"`
+	Visits     int       `json:"visits"`
+	FirstVisit time.Time `json:"first_visit"`
+	LastVisit  time.Time `json:"last_visit"`
+	Browsers   []string  `json:"browsers"`
+}
+
+// SearchReport is the output of the search command
+type SearchReport struct {
+	Query        string         `json:"query"`
+	TotalResults int            `json:"total_results"`
+	Results      []SearchResult `json:"results"`
+}
+
+// ArchiveYear is the number of archived visits in a calendar year (UTC)
+type ArchiveYear struct {
+	Year   string `json:"year"`
+	Visits int    `json:"visits"`
+}
+
+// ArchiveStats summarizes the contents of the archive
+type ArchiveStats struct {
+	Path        string          `json:"path"`
+	SizeBytes   int64           `json:"size_bytes"`
+	Visits      int             `json:"visits"`
+	Pages       int             `json:"pages"`
+	Bookmarks   int             `json:"bookmarks"`
+	OldestVisit time.Time       `json:"oldest_visit"`
+	NewestVisit time.Time       `json:"newest_visit"`
+	Years       []ArchiveYear   `json:"years"`
+	Browsers    []ArchiveStatus `json:"browsers"`
+}
+
+// ArchivePruneResult reports what a prune removed, or would remove
+type ArchivePruneResult struct {
+	Cutoff    time.Time `json:"cutoff"`
+	DryRun    bool      `json:"dry_run"`
+	Visits    int       `json:"visits"`
+	Bookmarks int       `json:"bookmarks"`
+}
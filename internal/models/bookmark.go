@@ -2,11 +2,14 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
-// BookmarkEntry represents a single browser bookmark entry
+// BookmarkEntry represents a single browser bookmark entry. ID is the GUID
+// the browser gave the bookmark, where it keeps one.
 type BookmarkEntry struct {
+	ID           string    `json:"id,omitempty"`
 	DateAdded    time.Time `json:"date_added"`
 	DateModified time.Time `json:"date_modified,omitempty"`
 	URL          string    `json:"url"`
@@ -14,12 +17,14 @@ type BookmarkEntry struct {
 	Folder       string    `json:"folder,omitempty"`
 	Domain       string    `json:"domain"`
 	Browser      string    `json:"browser"`
+	Source       string    `json:"source,omitempty"`
 	Tags         []string  `json:"tags,omitempty"`
 }
 
 // MarshalJSON ensures unset bookmark timestamps are omitted from JSON output.
 func (b BookmarkEntry) MarshalJSON() ([]byte, error) {
 	type bookmarkEntryJSON struct {
+		ID           string     `json:"id,omitempty"`
 		DateAdded    *time.Time `json:"date_added,omitempty"`
 		DateModified *time.Time `json:"date_modified,omitempty"`
 		URL          string     `json:"url"`
@@ -27,6 +32,7 @@ func (b BookmarkEntry) MarshalJSON() ([]byte, error) {
 		Folder       string     `json:"folder,omitempty"`
 		Domain       string     `json:"domain"`
 		Browser      string     `json:"browser"`
+		Source       string     `json:"source,omitempty"`
 		Tags         []string   `json:"tags,omitempty"`
 	}
 
@@ -41,6 +47,7 @@ func (b BookmarkEntry) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(bookmarkEntryJSON{
+		ID:           b.ID,
 		DateAdded:    dateAdded,
 		DateModified: dateModified,
 		URL:          b.URL,
@@ -48,6 +55,7 @@ func (b BookmarkEntry) MarshalJSON() ([]byte, error) {
 		Folder:       b.Folder,
 		Domain:       b.Domain,
 		Browser:      b.Browser,
+		Source:       b.Source,
 		Tags:         b.Tags,
 	})
 }
@@ -58,10 +66,51 @@ type BookmarkReport struct {
 	StartDate    *time.Time      `json:"start_date,omitempty"`
 	EndDate      *time.Time      `json:"end_date,omitempty"`
 	Timezone     string          `json:"timezone,omitempty"`
+	Meta         *ReportMeta     `json:"meta,omitempty"`
+	Source       string          `json:"source,omitempty"`
+	Sources      []SourceStatus  `json:"sources,omitempty"`
+	Warnings     []string        `json:"warnings,omitempty"`
 	TotalEntries int             `json:"total_entries"`
 	Entries      []BookmarkEntry `json:"entries"`
 }
 
+// BookmarkTreeReport is a BookmarkReport with the bookmarks nested in their
+// folders, one root folder per browser
+type BookmarkTreeReport struct {
+	Browser      string           `json:"browser"`
+	StartDate    *time.Time       `json:"start_date,omitempty"`
+	EndDate      *time.Time       `json:"end_date,omitempty"`
+	Timezone     string           `json:"timezone,omitempty"`
+	Meta         *ReportMeta      `json:"meta,omitempty"`
+	Source       string           `json:"source,omitempty"`
+	Sources      []SourceStatus   `json:"sources,omitempty"`
+	Warnings     []string         `json:"warnings,omitempty"`
+	TotalEntries int              `json:"total_entries"`
+	Browsers     []BookmarkFolder `json:"browsers"`
+}
+
+// BookmarkFolderStats describes one bookmark folder. Bookmarks counts the
+// bookmarks directly in the folder and Total those in its subfolders too;
+// the dates cover Total and are omitted when the browser stores none.
+type BookmarkFolderStats struct {
+	Browser    string     `json:"browser"`
+	Path       string     `json:"path"`
+	Bookmarks  int        `json:"bookmarks"`
+	Total      int        `json:"total"`
+	FirstAdded *time.Time `json:"first_added,omitempty"`
+	LastAdded  *time.Time `json:"last_added,omitempty"`
+}
+
+// BookmarkFoldersReport lists the bookmark folders of one or more browsers
+type BookmarkFoldersReport struct {
+	Browser      string                `json:"browser"`
+	Meta         *ReportMeta           `json:"meta,omitempty"`
+	Sources      []SourceStatus        `json:"sources,omitempty"`
+	Warnings     []string              `json:"warnings,omitempty"`
+	TotalFolders int                   `json:"total_folders"`
+	Folders      []BookmarkFolderStats `json:"folders"`
+}
+
 // BookmarkFolder represents a folder/directory structure in bookmarks
 type BookmarkFolder struct {
 	Name     string         `json:"name"`
@@ -74,3 +123,28 @@ type BookmarkNode struct {
 	Bookmark *BookmarkEntry  `json:"bookmark,omitempty"`
 	Folder   *BookmarkFolder `json:"folder,omitempty"`
 }
+
+// FolderTags returns the bookmark's tags followed by one tag per folder it
+// is in, below the browser's root folder, e.g. "Work" and "Projects" for
+// "Bookmarks Bar/Work/Projects". Spaces in folder names become dashes, so
+// the tags suit services with space-separated tags.
+func (b BookmarkEntry) FolderTags() []string {
+	var tags []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = strings.Join(strings.Fields(tag), "-")
+		if tag != "" && !seen[strings.ToLower(tag)] {
+			seen[strings.ToLower(tag)] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range b.Tags {
+		add(tag)
+	}
+	if folders := strings.Split(b.Folder, "/"); len(folders) > 1 {
+		for _, folder := range folders[1:] {
+			add(folder)
+		}
+	}
+	return tags
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -62,3 +63,20 @@ func TestBookmarkEntryMarshalJSON_IncludesSetTimestamps(t *testing.T) {
 		t.Fatalf("expected date_modified to be included when set")
 	}
 }
+
+func TestBookmarkEntryFolderTags(t *testing.T) {
+	tests := []struct {
+		entry BookmarkEntry
+		want  string
+	}{
+		{BookmarkEntry{Folder: "Bookmarks Bar/Work/Side Projects"}, "Work,Side-Projects"},
+		{BookmarkEntry{Folder: "Bookmarks Bar"}, ""},
+		{BookmarkEntry{Folder: "menu/go", Tags: []string{"Go", "reading"}}, "Go,reading"},
+		{BookmarkEntry{}, ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.entry.FolderTags(), ","); got != tt.want {
+			t.Errorf("FolderTags(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+// Data types covered by the capabilities report
+const (
+	DataHistory   = "history"
+	DataBookmarks = "bookmarks"
+	DataTabs      = "tabs"
+	DataDownloads = "downloads"
+	DataSearches  = "searches"
+)
+
+// Capability says whether one data type can be read from a browser, and why
+// not when it can't
+type Capability struct {
+	Data      string `json:"data"`
+	Supported bool   `json:"supported"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// BrowserCapabilities lists the data types web-recap can read from a browser
+type BrowserCapabilities struct {
+	Browser      string       `json:"browser"`
+	Name         string       `json:"name"`
+	Engine       string       `json:"engine"`
+	Path         string       `json:"path"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// CapabilitiesReport is the support matrix for the detected browsers
+type CapabilitiesReport struct {
+	Platform string                `json:"platform"`
+	Browsers []BrowserCapabilities `json:"browsers"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// ExportDiff compares two saved reports of the same kind, "history" or
+// "bookmarks". Added and Removed count entries found in only one report;
+// for bookmarks they are also listed, along with bookmarks that moved to
+// another folder.
+type ExportDiff struct {
+	Kind           string          `json:"kind"`
+	Meta           *ReportMeta     `json:"meta,omitempty"`
+	Old            DiffSide        `json:"old"`
+	New            DiffSide        `json:"new"`
+	EntriesDelta   int             `json:"entries_delta"`
+	Added          int             `json:"added"`
+	Removed        int             `json:"removed"`
+	NewDomains     []string        `json:"new_domains,omitempty"`
+	RemovedDomains []string        `json:"removed_domains,omitempty"`
+	DomainDeltas   []DomainDelta   `json:"domain_deltas,omitempty"`
+	AddedEntries   []BookmarkEntry `json:"added_entries,omitempty"`
+	RemovedEntries []BookmarkEntry `json:"removed_entries,omitempty"`
+	Moved          []BookmarkMove  `json:"moved,omitempty"`
+}
+
+// DiffSide summarizes one of the reports an ExportDiff compares
+type DiffSide struct {
+	File    string `json:"file"`
+	Browser string `json:"browser"`
+	Entries int    `json:"entries"`
+	Domains int    `json:"domains"`
+}
+
+// DomainDelta is the change in a domain's entry count between two reports
+type DomainDelta struct {
+	Domain string `json:"domain"`
+	Old    int    `json:"old"`
+	New    int    `json:"new"`
+	Delta  int    `json:"delta"`
+}
+
+// BookmarkMove is a bookmark found in a different folder in the newer report
+type BookmarkMove struct {
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Browser string `json:"browser"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// DigestDay holds the number of visits on a single day of a digest
+type DigestDay struct {
+	Date   string `json:"date"` // YYYY-MM-DD in the digest timezone
+	Visits int    `json:"visits"`
+}
+
+// DigestDomain holds the number of visits to a domain in a digest
+type DigestDomain struct {
+	Domain string `json:"domain"`
+	Visits int    `json:"visits"`
+}
+
+// DigestPage holds the number of visits to a single page in a digest
+type DigestPage struct {
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Domain string `json:"domain"`
+	Visits int    `json:"visits"`
+}
+
+// DigestReport is a human-oriented summary of browsing activity for a period
+type DigestReport struct {
+	Title         string         `json:"title"`
+	Period        string         `json:"period"` // "daily" or "weekly"
+	Browser       string         `json:"browser"`
+	StartDate     time.Time      `json:"start_date"`
+	EndDate       time.Time      `json:"end_date"`
+	Timezone      string         `json:"timezone"`
+	TotalVisits   int            `json:"total_visits"`
+	UniquePages   int            `json:"unique_pages"`
+	UniqueDomains int            `json:"unique_domains"`
+	Days          []DigestDay    `json:"days"`
+	TopDomains    []DigestDomain `json:"top_domains"`
+	TopPages      []DigestPage   `json:"top_pages"`
+}
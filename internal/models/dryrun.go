@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// DryRunBrowser is a browser profile a run would read. Exists is false when
+// its database is missing, which the run would report as an error.
+type DryRunBrowser struct {
+	Browser string `json:"browser"`
+	Name    string `json:"name"`
+	Engine  string `json:"engine,omitempty"`
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+}
+
+// DryRunWrite is a place a run would write to: stdout, a file, an HTTP
+// POST, an object storage upload, or the state file
+type DryRunWrite struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target,omitempty"`
+}
+
+// DryRunPlan is what a run would read and write, printed by --dry-run in
+// place of running it. Filters holds the filters in effect, by flag name.
+type DryRunPlan struct {
+	Command   string            `json:"command"`
+	Source    string            `json:"source"`
+	Archive   string            `json:"archive,omitempty"`
+	Browsers  []DryRunBrowser   `json:"browsers"`
+	StartDate time.Time         `json:"start_date"`
+	EndDate   time.Time         `json:"end_date"`
+	Timezone  string            `json:"timezone"`
+	Format    string            `json:"format"`
+	Filters   map[string]string `json:"filters,omitempty"`
+	Encrypted bool              `json:"encrypted,omitempty"`
+	Writes    []DryRunWrite     `json:"writes"`
+	Warnings  []string          `json:"warnings,omitempty"`
+}
@@ -1,37 +1,168 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
 
-// HistoryEntry represents a single browser history entry
+// HistoryEntry represents a single browser history entry. Browsers lists
+// every browser with the visit when entries were merged across browsers.
 type HistoryEntry struct {
+	ID         string    `json:"id,omitempty"`
 	Timestamp  time.Time `json:"timestamp"`
 	URL        string    `json:"url"`
 	Title      string    `json:"title"`
 	VisitCount int       `json:"visit_count"`
 	Domain     string    `json:"domain"`
-	Browser    string    `json:"browser"`
+	// Host is the domain without its port, and RegistrableDomain the part
+	// of it registered under a public suffix (eTLD+1), e.g. bbc.co.uk
+	Host              string `json:"host,omitempty"`
+	RegistrableDomain string `json:"registrable_domain,omitempty"`
+	// DisplayDomain and DisplayURL are the domain and URL with an
+	// internationalized (xn--) host decoded to Unicode, and Lookalike flags
+	// a host that could pass for another, such as one mixing Latin and
+	// Cyrillic letters
+	DisplayDomain string   `json:"display_domain,omitempty"`
+	DisplayURL    string   `json:"display_url,omitempty"`
+	Lookalike     bool     `json:"lookalike,omitempty"`
+	Browser       string   `json:"browser"`
+	Browsers      []string `json:"browsers,omitempty"`
+	// Repeats counts the visits --collapse merged into this one, when
+	// there was more than one
+	Repeats int    `json:"repeats,omitempty"`
+	Source  string `json:"source,omitempty"`
+	// Language is the ISO 639-1 code detected from the title, set by --lang
+	// and --detect-language; empty when it could not be detected
+	Language string `json:"language,omitempty"`
+	// Description and Content are the page's meta description and readable
+	// text, set by --fetch-content
+	Description string `json:"description,omitempty"`
+	Content     string `json:"content,omitempty"`
+	// Words is the number of words on the page, set by --fetch-content
+	Words int `json:"words,omitempty"`
+	// Redirects are the URLs that redirected to this visit, the first one
+	// first, for browsers that record redirect chains
+	Redirects []string `json:"redirects,omitempty"`
+	// Synced is set on visits made on another device and synced to this one
+	Synced bool `json:"synced,omitempty"`
+	// Context is the tab, window, and task Chromium recorded for the visit
+	Context *VisitContext `json:"context,omitempty"`
+	// Referrer is the URL of the visit this one navigated from, a link click
+	// or redirect, when the browser recorded it. It is not exported.
+	Referrer string `json:"-"`
+	// Duration is how long the visit's tab was in the foreground, for
+	// browsers that record it. It is not exported.
+	Duration time.Duration `json:"-"`
+	// BrowserType is the browser read, e.g. edge or brave where Browser is
+	// chrome. It is not exported.
+	BrowserType string `json:"-"`
+}
+
+// VisitContext is where a visit happened in a Chromium browser. A task is
+// a chain of navigations; visits opened from the same tab, directly or
+// through other tabs, share a root task. IDs the browser did not record are
+// zero.
+type VisitContext struct {
+	WindowID     int64 `json:"window_id,omitempty"`
+	TabID        int64 `json:"tab_id,omitempty"`
+	TaskID       int64 `json:"task_id,omitempty"`
+	RootTaskID   int64 `json:"root_task_id,omitempty"`
+	ParentTaskID int64 `json:"parent_task_id,omitempty"`
+}
+
+// HistoryID derives a stable ID for a visit from its browser, URL, and time
+// to the microsecond, so the same visit has the same ID in every export
+func HistoryID(browser, url string, t time.Time) string {
+	sum := sha256.Sum256([]byte(browser + "\x00" + url + "\x00" + strconv.FormatInt(t.UnixMicro(), 10)))
+	return hex.EncodeToString(sum[:8])
 }
 
 // HistoryReport represents a collection of history entries for a specific time period
 type HistoryReport struct {
-	Browser      string          `json:"browser"`
-	StartDate    time.Time       `json:"start_date"`
-	EndDate      time.Time       `json:"end_date"`
-	Timezone     string          `json:"timezone"`
-	TotalEntries int             `json:"total_entries"`
-	Entries      []HistoryEntry  `json:"entries"`
+	Browser   string         `json:"browser"`
+	StartDate time.Time      `json:"start_date"`
+	EndDate   time.Time      `json:"end_date"`
+	Timezone  string         `json:"timezone"`
+	Meta      *ReportMeta    `json:"meta,omitempty"`
+	Source    string         `json:"source,omitempty"`
+	Sources   []SourceStatus `json:"sources,omitempty"`
+	Warnings  []string       `json:"warnings,omitempty"`
+	// Granularity is "url" when visits were collapsed to one entry per URL
+	Granularity string `json:"granularity,omitempty"`
+	// GroupBy is "task" when entries are ordered by Chromium task; the other
+	// --group-by values write a GroupedHistoryReport instead
+	GroupBy          string            `json:"group_by,omitempty"`
+	TotalEntries     int               `json:"total_entries"`
+	Part             *ReportPart       `json:"part,omitempty"`
+	Budget           *TokenBudget      `json:"token_budget,omitempty"`
+	CollapsedDomains []CollapsedDomain `json:"collapsed_domains,omitempty"`
+	Entries          []HistoryEntry    `json:"entries"`
+}
+
+// HistoryGroup is the visits sharing a domain, hour, category, or session,
+// with counts so a reader can skim the groups before their entries
+type HistoryGroup struct {
+	Key        string         `json:"key"`
+	Visits     int            `json:"visits"`
+	UniqueURLs int            `json:"unique_urls"`
+	FirstVisit time.Time      `json:"first_visit"`
+	LastVisit  time.Time      `json:"last_visit"`
+	Entries    []HistoryEntry `json:"entries"`
+}
+
+// GroupedHistoryReport is a history report with the entries nested in
+// groups, written for --group-by domain, hour, category, or session
+type GroupedHistoryReport struct {
+	Browser      string         `json:"browser"`
+	StartDate    time.Time      `json:"start_date"`
+	EndDate      time.Time      `json:"end_date"`
+	Timezone     string         `json:"timezone"`
+	Meta         *ReportMeta    `json:"meta,omitempty"`
+	Source       string         `json:"source,omitempty"`
+	Sources      []SourceStatus `json:"sources,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+	Granularity  string         `json:"granularity,omitempty"`
+	GroupBy      string         `json:"group_by"`
+	TotalEntries int            `json:"total_entries"`
+	TotalGroups  int            `json:"total_groups"`
+	Groups       []HistoryGroup `json:"groups"`
+}
+
+// ReportPart identifies one file of a report written in several parts
+type ReportPart struct {
+	Index int `json:"index"`
+	Total int `json:"total"`
+}
+
+// TokenBudget describes how a report was reduced to fit a token limit
+type TokenBudget struct {
+	MaxTokens       int      `json:"max_tokens"`
+	EstimatedTokens int      `json:"estimated_tokens"`
+	OriginalEntries int      `json:"original_entries"`
+	Steps           []string `json:"steps,omitempty"`
+}
+
+// CollapsedDomain summarizes entries for a domain that were folded out of the
+// entry list to save space
+type CollapsedDomain struct {
+	Domain     string    `json:"domain"`
+	Visits     int       `json:"visits"`
+	FirstVisit time.Time `json:"first_visit"`
+	LastVisit  time.Time `json:"last_visit"`
 }
 
 // BrowserType represents the type of browser
 type BrowserType string
 
 const (
-	BrowserChrome    BrowserType = "chrome"
-	BrowserChromium  BrowserType = "chromium"
-	BrowserEdge      BrowserType = "edge"
-	BrowserFirefox   BrowserType = "firefox"
-	BrowserSafari    BrowserType = "safari"
-	BrowserUnknown   BrowserType = "unknown"
+	BrowserChrome   BrowserType = "chrome"
+	BrowserChromium BrowserType = "chromium"
+	BrowserEdge     BrowserType = "edge"
+	BrowserFirefox  BrowserType = "firefox"
+	BrowserSafari   BrowserType = "safari"
+	BrowserUnknown  BrowserType = "unknown"
 )
 
 func (b BrowserType) String() string {
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryID(t *testing.T) {
+	ts := time.Date(2026, 1, 10, 9, 30, 0, 123456789, time.UTC)
+	id := HistoryID("chrome", "https://example.com/", ts)
+	if len(id) != 16 {
+		t.Fatalf("id = %q, want 16 hex characters", id)
+	}
+
+	// The same visit read at another precision or offset keeps its ID
+	same := ts.Truncate(time.Microsecond).In(time.FixedZone("EST", -5*3600))
+	if got := HistoryID("chrome", "https://example.com/", same); got != id {
+		t.Errorf("id changed with precision or zone: %s, want %s", got, id)
+	}
+
+	for _, other := range []string{
+		HistoryID("firefox", "https://example.com/", ts),
+		HistoryID("chrome", "https://example.com/a", ts),
+		HistoryID("chrome", "https://example.com/", ts.Add(time.Microsecond)),
+	} {
+		if other == id {
+			t.Errorf("distinct visits share id %s", id)
+		}
+	}
+}
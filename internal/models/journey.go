@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// JourneyVisit is a visit Chrome placed in a Journeys cluster
+type JourneyVisit struct {
+	Timestamp time.Time `json:"timestamp"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Domain    string    `json:"domain"`
+	// Score is how central Chrome rates the visit to the cluster, 0 to 1
+	Score float64 `json:"score"`
+}
+
+// Journey is one of Chrome's Journeys: visits it clustered by topic, with
+// the label and keywords it gave the cluster
+type Journey struct {
+	ID        int64          `json:"id"`
+	Label     string         `json:"label,omitempty"`
+	Keywords  []string       `json:"keywords,omitempty"`
+	Browser   string         `json:"browser"`
+	StartTime time.Time      `json:"start_time"`
+	EndTime   time.Time      `json:"end_time"`
+	Visits    []JourneyVisit `json:"visits"`
+}
+
+// JourneyReport represents the Journeys with visits in a time period
+type JourneyReport struct {
+	Browser       string         `json:"browser"`
+	StartDate     time.Time      `json:"start_date"`
+	EndDate       time.Time      `json:"end_date"`
+	Timezone      string         `json:"timezone"`
+	Meta          *ReportMeta    `json:"meta,omitempty"`
+	Sources       []SourceStatus `json:"sources,omitempty"`
+	Warnings      []string       `json:"warnings,omitempty"`
+	TotalJourneys int            `json:"total_journeys"`
+	Journeys      []Journey      `json:"journeys"`
+}
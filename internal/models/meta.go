@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ReportMeta describes how and where a report was made, so an archived
+// export can be read on its own and re-run
+type ReportMeta struct {
+	// GeneratedAt is left out of canonical output, which must not change
+	// between runs
+	GeneratedAt *time.Time  `json:"generated_at,omitempty"`
+	Version     string      `json:"version"`
+	Hostname    string      `json:"hostname,omitempty"`
+	Username    string      `json:"username,omitempty"`
+	Query       ReportQuery `json:"query"`
+}
+
+// ReportQuery holds the effective query parameters of a report
+type ReportQuery struct {
+	Command  string            `json:"command"`
+	Browsers []string          `json:"browsers,omitempty"`
+	Profiles []string          `json:"profiles,omitempty"`
+	Flags    map[string]string `json:"flags,omitempty"`
+}
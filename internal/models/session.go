@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// BrowsingSession is a run of visits with no idle gap longer than the
+// session threshold
+type BrowsingSession struct {
+	Start           time.Time      `json:"start"`
+	End             time.Time      `json:"end"`
+	DurationMinutes int            `json:"duration_minutes"`
+	Visits          int            `json:"visits"`
+	TopDomains      []DigestDomain `json:"top_domains"`
+	SampleTitles    []string       `json:"sample_titles,omitempty"`
+}
+
+// SearchQuery is a query typed into a search engine
+type SearchQuery struct {
+	Timestamp time.Time `json:"timestamp"`
+	Engine    string    `json:"engine"`
+	Query     string    `json:"query"`
+	URL       string    `json:"url"`
+	Browser   string    `json:"browser,omitempty"`
+	// Clicks are the results opened from the query's result pages, first
+	// clicked first, for browsers that record where a visit came from
+	Clicks []SearchClick `json:"clicks,omitempty"`
+}
+
+// SearchClick is a page opened from a search results page: the source
+// that answered the query
+type SearchClick struct {
+	Timestamp time.Time `json:"timestamp"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Domain    string    `json:"domain,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SiteUsage is the activity a browser itself recorded for a domain
+type SiteUsage struct {
+	Domain         string  `json:"domain"`
+	SegmentVisits  int     `json:"segment_visits"`
+	BrowserSeconds float64 `json:"browser_seconds"`
+}
+
+// SiteTime compares browser-recorded time on a domain with the time estimated
+// from gaps between history visits
+type SiteTime struct {
+	Domain           string  `json:"domain"`
+	Visits           int     `json:"visits"`
+	SegmentVisits    int     `json:"segment_visits"`
+	BrowserMinutes   float64 `json:"browser_minutes"`
+	EstimatedMinutes float64 `json:"estimated_minutes"`
+}
+
+// SiteTimeReport represents time on site for every domain in a time period
+type SiteTimeReport struct {
+	Browser      string     `json:"browser"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      time.Time  `json:"end_date"`
+	Timezone     string     `json:"timezone"`
+	IdleGap      string     `json:"idle_gap"`
+	TotalDomains int        `json:"total_domains"`
+	Sites        []SiteTime `json:"sites"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// SourceStatus reports how reading one browser went, so an empty result can
+// be told apart from a browser that was skipped
+type SourceStatus struct {
+	Browser string `json:"browser"`
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Entries int    `json:"entries"`
+	Error   string `json:"error,omitempty"`
+	// Code classifies Error: not_found, permission_denied, corrupt, or
+	// query_failed
+	Code string `json:"code,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// DomainStreak summarizes how consistently a domain was visited over a period
+type DomainStreak struct {
+	Domain         string    `json:"domain"`
+	CurrentStreak  int       `json:"current_streak"`
+	LongestStreak  int       `json:"longest_streak"`
+	ActiveDays     int       `json:"active_days"`
+	TotalVisits    int       `json:"total_visits"`
+	ConsistencyPct float64   `json:"consistency_pct"`
+	FirstSeen      time.Time `json:"first_seen"`
+	LastSeen       time.Time `json:"last_seen"`
+}
+
+// StreakReport represents visit streaks for every domain in a time period
+type StreakReport struct {
+	Browser      string         `json:"browser"`
+	StartDate    time.Time      `json:"start_date"`
+	EndDate      time.Time      `json:"end_date"`
+	Timezone     string         `json:"timezone"`
+	TotalDays    int            `json:"total_days"`
+	TotalDomains int            `json:"total_domains"`
+	Domains      []DomainStreak `json:"domains"`
+}
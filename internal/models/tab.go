@@ -1,21 +1,68 @@
 package models
 
+import "time"
+
 // TabEntry represents a single open browser tab
 type TabEntry struct {
-	URL       string `json:"url"`
-	Title     string `json:"title"`
-	Domain    string `json:"domain"`
-	Active    bool   `json:"active"`
-	Pinned    bool   `json:"pinned,omitempty"`
-	Group     string `json:"group,omitempty"`
-	WindowID  int    `json:"window_id"`
-	Browser   string `json:"browser"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Domain string `json:"domain"`
+	Active bool   `json:"active"`
+	// Selected marks the tab shown in its window; Active only marks the one
+	// in the focused window
+	Selected bool   `json:"selected,omitempty"`
+	Pinned   bool   `json:"pinned,omitempty"`
+	Group    string `json:"group,omitempty"`
+	// LastActive is when the tab was last focused, where the browser
+	// records it
+	LastActive *time.Time `json:"last_active,omitempty"`
+	WindowID   int        `json:"window_id"`
+	Browser    string     `json:"browser"`
+	Source     string     `json:"source,omitempty"`
+	// Device names the other device a Safari iCloud tab is open on
+	Device string `json:"device,omitempty"`
 }
 
-// TabReport represents a collection of open tabs
+// TabReport represents a collection of open tabs. SkippedCommands counts
+// session file commands that could not be read.
 type TabReport struct {
-	Browser      string     `json:"browser"`
-	TotalTabs    int        `json:"total_tabs"`
-	TotalWindows int        `json:"total_windows"`
-	Entries      []TabEntry `json:"entries"`
+	Browser         string      `json:"browser"`
+	Meta            *ReportMeta `json:"meta,omitempty"`
+	Source          string      `json:"source,omitempty"`
+	TotalTabs       int         `json:"total_tabs"`
+	TotalWindows    int         `json:"total_windows"`
+	SkippedCommands int         `json:"skipped_commands,omitempty"`
+	// StaleBefore is the --stale cutoff: every tab was last focused before it
+	StaleBefore *time.Time `json:"stale_before,omitempty"`
+	Warnings    []string   `json:"warnings,omitempty"`
+	Entries     []TabEntry `json:"entries"`
+}
+
+// TabWindow is one browser window, or another device's iCloud tabs, with its
+// tabs in tab strip order
+type TabWindow struct {
+	Browser  string `json:"browser"`
+	WindowID int    `json:"window_id,omitempty"`
+	Device   string `json:"device,omitempty"`
+	// Active marks the focused window
+	Active bool `json:"active"`
+	// ActiveTab is the index in Tabs of the tab shown in the window, or -1
+	// when it is not known
+	ActiveTab int `json:"active_tab"`
+	// Groups names the window's tab groups in the order they first appear
+	Groups []string   `json:"groups,omitempty"`
+	Tabs   []TabEntry `json:"tabs"`
+}
+
+// TabWindowReport is a tab report with the tabs nested in their windows
+type TabWindowReport struct {
+	Browser         string      `json:"browser"`
+	Meta            *ReportMeta `json:"meta,omitempty"`
+	Source          string      `json:"source,omitempty"`
+	TotalTabs       int         `json:"total_tabs"`
+	TotalWindows    int         `json:"total_windows"`
+	SkippedCommands int         `json:"skipped_commands,omitempty"`
+	StaleBefore     *time.Time  `json:"stale_before,omitempty"`
+	Warnings        []string    `json:"warnings,omitempty"`
+	Windows         []TabWindow `json:"windows"`
 }
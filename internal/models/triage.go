@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Tab triage actions
+const (
+	TriageKeep      = "keep"
+	TriageBookmark  = "bookmark"
+	TriageReadLater = "read_later"
+	TriageClose     = "close"
+)
+
+// TriageItem is the decision made for one open tab
+type TriageItem struct {
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	Domain   string `json:"domain"`
+	Browser  string `json:"browser"`
+	WindowID int    `json:"window_id"`
+	Action   string `json:"action"`
+	Folder   string `json:"folder,omitempty"`
+}
+
+// TriagePlan is the result of a tab triage session. Tabs left undecided are
+// not listed.
+type TriagePlan struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Browser   string         `json:"browser"`
+	TotalTabs int            `json:"total_tabs"`
+	Decided   int            `json:"decided"`
+	Counts    map[string]int `json:"counts"`
+	Items     []TriageItem   `json:"items"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// VisitedCheck is whether a URL or domain given to the visited command is in
+// history. Visits counts the matching visits in the range, and URL and
+// Title are those of the most recent one.
+type VisitedCheck struct {
+	Input       string     `json:"input"`
+	Kind        string     `json:"kind"`
+	Visited     bool       `json:"visited"`
+	Visits      int        `json:"visits"`
+	LastVisited *time.Time `json:"last_visited,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Browsers    []string   `json:"browsers,omitempty"`
+}
+
+// VisitedReport represents the history checks of a list of URLs and domains.
+// StartDate and EndDate are omitted when all of history was searched.
+type VisitedReport struct {
+	Browser      string         `json:"browser"`
+	StartDate    *time.Time     `json:"start_date,omitempty"`
+	EndDate      *time.Time     `json:"end_date,omitempty"`
+	Timezone     string         `json:"timezone"`
+	Meta         *ReportMeta    `json:"meta,omitempty"`
+	Sources      []SourceStatus `json:"sources,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+	TotalChecked int            `json:"total_checked"`
+	TotalVisited int            `json:"total_visited"`
+	Results      []VisitedCheck `json:"results"`
+}
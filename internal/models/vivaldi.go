@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// VivaldiNote is a note from Vivaldi's Notes panel. URL is the page the note
+// was taken on, when it has one, and Folder the path of note folders it is
+// filed in.
+type VivaldiNote struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	URL       string     `json:"url,omitempty"`
+	Domain    string     `json:"domain,omitempty"`
+	Folder    string     `json:"folder,omitempty"`
+	DateAdded *time.Time `json:"date_added,omitempty"`
+}
+
+// VivaldiNotesReport represents the notes in a Vivaldi profile
+type VivaldiNotesReport struct {
+	Browser    string         `json:"browser"`
+	Meta       *ReportMeta    `json:"meta,omitempty"`
+	Sources    []SourceStatus `json:"sources,omitempty"`
+	Warnings   []string       `json:"warnings,omitempty"`
+	TotalNotes int            `json:"total_notes"`
+	Notes      []VivaldiNote  `json:"notes"`
+}
+
+// SpeedDial is a tile on a Vivaldi Speed Dial page. Nickname is the short
+// name the user gave the tile, if any.
+type SpeedDial struct {
+	Title     string     `json:"title"`
+	Nickname  string     `json:"nickname,omitempty"`
+	URL       string     `json:"url"`
+	Domain    string     `json:"domain"`
+	Folder    string     `json:"folder,omitempty"`
+	DateAdded *time.Time `json:"date_added,omitempty"`
+}
+
+// SpeedDialGroup is one Speed Dial page: a bookmark folder Vivaldi shows as
+// dials, with Path the folder's place in the bookmark tree
+type SpeedDialGroup struct {
+	Name  string      `json:"name"`
+	Path  string      `json:"path"`
+	Dials []SpeedDial `json:"dials"`
+}
+
+// SpeedDialReport represents the Speed Dial pages of a Vivaldi profile
+type SpeedDialReport struct {
+	Browser    string           `json:"browser"`
+	Meta       *ReportMeta      `json:"meta,omitempty"`
+	Sources    []SourceStatus   `json:"sources,omitempty"`
+	Warnings   []string         `json:"warnings,omitempty"`
+	TotalDials int              `json:"total_dials"`
+	Groups     []SpeedDialGroup `json:"groups"`
+}
@@ -0,0 +1,204 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Telegram rejects messages longer than this many characters
+const telegramMessageLimit = 4096
+
+// telegramAPIURL is the Bot API base URL, replaced in tests
+var telegramAPIURL = "https://api.telegram.org"
+
+// TelegramConfig identifies a bot and the chat it posts to
+type TelegramConfig struct {
+	BotToken string
+	ChatID   string
+}
+
+// LoadTelegramConfigFromEnv reads TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID
+func LoadTelegramConfigFromEnv() TelegramConfig {
+	return TelegramConfig{
+		BotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+		ChatID:   os.Getenv("TELEGRAM_CHAT_ID"),
+	}
+}
+
+// Validate reports a descriptive error when required settings are missing
+func (c TelegramConfig) Validate() error {
+	if c.BotToken == "" {
+		return fmt.Errorf("Telegram bot token is not configured (set TELEGRAM_BOT_TOKEN)")
+	}
+	if c.ChatID == "" {
+		return fmt.Errorf("Telegram chat is not configured (set TELEGRAM_CHAT_ID or --telegram-chat)")
+	}
+	return nil
+}
+
+// MatrixConfig identifies a Matrix account and the room it posts to
+type MatrixConfig struct {
+	Homeserver  string
+	AccessToken string
+	RoomID      string
+}
+
+// LoadMatrixConfigFromEnv reads MATRIX_HOMESERVER, MATRIX_ACCESS_TOKEN and
+// MATRIX_ROOM_ID
+func LoadMatrixConfigFromEnv() MatrixConfig {
+	return MatrixConfig{
+		Homeserver:  os.Getenv("MATRIX_HOMESERVER"),
+		AccessToken: os.Getenv("MATRIX_ACCESS_TOKEN"),
+		RoomID:      os.Getenv("MATRIX_ROOM_ID"),
+	}
+}
+
+// Validate reports a descriptive error when required settings are missing
+func (c MatrixConfig) Validate() error {
+	if c.Homeserver == "" {
+		return fmt.Errorf("Matrix homeserver is not configured (set MATRIX_HOMESERVER or --matrix-homeserver)")
+	}
+	if c.AccessToken == "" {
+		return fmt.Errorf("Matrix access token is not configured (set MATRIX_ACCESS_TOKEN)")
+	}
+	if c.RoomID == "" {
+		return fmt.Errorf("Matrix room is not configured (set MATRIX_ROOM_ID or --matrix-room)")
+	}
+	return nil
+}
+
+// SendTelegramMessage posts an HTML-formatted message to a Telegram chat
+func SendTelegramMessage(config TelegramConfig, text string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIURL, config.BotToken)
+	err := postJSON(endpoint, map[string]interface{}{
+		"chat_id":                  config.ChatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		// Request errors include the URL, which contains the bot token
+		return errors.New(strings.ReplaceAll(err.Error(), config.BotToken, "<token>"))
+	}
+	return nil
+}
+
+// SendMatrixMessage posts a message to a Matrix room with a plain-text body
+// and an HTML formatted body
+func SendMatrixMessage(config MatrixConfig, text, htmlText string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	homeserver := strings.TrimRight(config.Homeserver, "/")
+	if !strings.Contains(homeserver, "://") {
+		homeserver = "https://" + homeserver
+	}
+	txnID := fmt.Sprintf("web-recap-%d", time.Now().UnixNano())
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		homeserver, url.PathEscape(config.RoomID), txnID)
+
+	payload := map[string]string{
+		"msgtype": "m.text",
+		"body":    text,
+	}
+	if htmlText != "" {
+		payload["format"] = "org.matrix.custom.html"
+		payload["formatted_body"] = htmlText
+	}
+
+	return sendJSON(http.MethodPut, endpoint, map[string]string{
+		"Authorization": "Bearer " + config.AccessToken,
+	}, payload)
+}
+
+// PostTelegramDigest sends the digest summary to a Telegram chat
+func PostTelegramDigest(config TelegramConfig, report models.DigestReport) error {
+	return SendTelegramMessage(config, truncateHTMLLines(digestHTML(report), telegramMessageLimit))
+}
+
+// PostMatrixDigest sends the digest summary to a Matrix room
+func PostMatrixDigest(config MatrixConfig, report models.DigestReport) error {
+	// Matrix renders formatted_body as real HTML, so line breaks need tags
+	htmlText := strings.ReplaceAll(digestHTML(report), "\n", "<br>\n")
+	return SendMatrixMessage(config, digestText(report), htmlText)
+}
+
+// digestText renders a compact plain-text digest for chat messages
+func digestText(report models.DigestReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", report.Title)
+	fmt.Fprintf(&b, "%d visits · %d unique pages · %d domains · browser: %s\n",
+		report.TotalVisits, report.UniquePages, report.UniqueDomains, report.Browser)
+
+	if len(report.TopDomains) > 0 {
+		b.WriteString("\nTop domains\n")
+		for i, d := range report.TopDomains {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, d.Domain, d.Visits)
+		}
+	}
+	if len(report.TopPages) > 0 {
+		b.WriteString("\nTop pages\n")
+		for i, p := range report.TopPages {
+			fmt.Fprintf(&b, "%d. %s - %s (%d)\n", i+1, pageTitle(p), p.URL, p.Visits)
+		}
+	}
+
+	return b.String()
+}
+
+// digestHTML renders the digest using the small HTML subset that both
+// Telegram and Matrix clients support, one item per line
+func digestHTML(report models.DigestReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(report.Title))
+	fmt.Fprintf(&b, "%d visits · %d unique pages · %d domains · browser: %s\n",
+		report.TotalVisits, report.UniquePages, report.UniqueDomains, html.EscapeString(report.Browser))
+
+	if len(report.TopDomains) > 0 {
+		b.WriteString("\n<b>Top domains</b>\n")
+		for i, d := range report.TopDomains {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, html.EscapeString(d.Domain), d.Visits)
+		}
+	}
+	if len(report.TopPages) > 0 {
+		b.WriteString("\n<b>Top pages</b>\n")
+		for i, p := range report.TopPages {
+			fmt.Fprintf(&b, "%d. <a href=\"%s\">%s</a> (%d)\n", i+1,
+				html.EscapeString(p.URL), html.EscapeString(pageTitle(p)), p.Visits)
+		}
+	}
+
+	return b.String()
+}
+
+// truncateHTMLLines shortens a line-oriented HTML message to at most limit
+// characters by dropping whole lines, so no tag is cut in half
+func truncateHTMLLines(s string, limit int) string {
+	if len([]rune(s)) <= limit {
+		return s
+	}
+	var b strings.Builder
+	n := 0
+	for _, line := range strings.SplitAfter(s, "\n") {
+		l := len([]rune(line))
+		if n+l > limit-1 {
+			break
+		}
+		b.WriteString(line)
+		n += l
+	}
+	return b.String() + "…"
+}
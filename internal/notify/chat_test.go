@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func testDigest() models.DigestReport {
+	return models.DigestReport{
+		Title:         "Weekly digest <test>",
+		Period:        "weekly",
+		Browser:       "chrome",
+		TotalVisits:   3,
+		UniquePages:   2,
+		UniqueDomains: 1,
+		TopDomains:    []models.DigestDomain{{Domain: "go.dev", Visits: 3}},
+		TopPages:      []models.DigestPage{{URL: "https://go.dev/doc?a=1&b=2", Title: "Docs", Visits: 2}},
+	}
+}
+
+func TestPostTelegramDigest(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bot123:abc/sendMessage" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	old := telegramAPIURL
+	telegramAPIURL = server.URL
+	defer func() { telegramAPIURL = old }()
+
+	if err := PostTelegramDigest(TelegramConfig{BotToken: "123:abc", ChatID: "42"}, testDigest()); err != nil {
+		t.Fatalf("PostTelegramDigest: %v", err)
+	}
+	if body["chat_id"] != "42" || body["parse_mode"] != "HTML" {
+		t.Errorf("unexpected payload %v", body)
+	}
+	text, _ := body["text"].(string)
+	if !strings.Contains(text, "<b>Weekly digest &lt;test&gt;</b>") {
+		t.Errorf("title not escaped: %q", text)
+	}
+	if !strings.Contains(text, `<a href="https://go.dev/doc?a=1&amp;b=2">Docs</a>`) {
+		t.Errorf("link not rendered: %q", text)
+	}
+}
+
+func TestTelegramErrorHidesToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	server.Close()
+
+	old := telegramAPIURL
+	telegramAPIURL = server.URL
+	defer func() { telegramAPIURL = old }()
+
+	err := SendTelegramMessage(TelegramConfig{BotToken: "secret-token", ChatID: "42"}, "hi")
+	if err == nil {
+		t.Fatal("expected error from closed server")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error leaks bot token: %v", err)
+	}
+}
+
+func TestPostMatrixDigest(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("method = %s, want PUT", r.Method)
+		}
+		if !strings.HasPrefix(r.URL.EscapedPath(), "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/") {
+			t.Errorf("path = %s", r.URL.EscapedPath())
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"event_id":"$1"}`))
+	}))
+	defer server.Close()
+
+	config := MatrixConfig{Homeserver: server.URL + "/", AccessToken: "tok", RoomID: "!room:example.org"}
+	if err := PostMatrixDigest(config, testDigest()); err != nil {
+		t.Fatalf("PostMatrixDigest: %v", err)
+	}
+	if body["msgtype"] != "m.text" || !strings.HasPrefix(body["body"], "Weekly digest <test>\n") {
+		t.Errorf("unexpected body %v", body)
+	}
+	if !strings.Contains(body["formatted_body"], "<br>") {
+		t.Errorf("formatted_body missing line breaks: %q", body["formatted_body"])
+	}
+}
+
+func TestTruncateHTMLLines(t *testing.T) {
+	s := "<b>a</b>\n<b>b</b>\n<b>c</b>\n"
+	got := truncateHTMLLines(s, 20)
+	if got != "<b>a</b>\n<b>b</b>\n…" {
+		t.Errorf("truncateHTMLLines = %q", got)
+	}
+	if truncateHTMLLines(s, 100) != s {
+		t.Error("short message should be unchanged")
+	}
+}
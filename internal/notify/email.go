@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the settings needed to deliver mail through an SMTP relay
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// LoadSMTPConfigFromEnv reads SMTP settings from SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. The port defaults to 587.
+func LoadSMTPConfigFromEnv() SMTPConfig {
+	config := SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+
+	if port, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil && port > 0 {
+		config.Port = port
+	}
+
+	return config
+}
+
+// Validate reports a descriptive error when required settings are missing
+func (c SMTPConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("SMTP host is not configured (set SMTP_HOST or --smtp-host)")
+	}
+	if c.From == "" && c.Username == "" {
+		return fmt.Errorf("SMTP sender is not configured (set SMTP_FROM or --smtp-from)")
+	}
+	return nil
+}
+
+// Email is a message with a plain-text body and an optional HTML alternative
+type Email struct {
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// SendEmail delivers the message using the given SMTP configuration.
+// Port 465 uses implicit TLS; other ports upgrade with STARTTLS when offered.
+func SendEmail(config SMTPConfig, email Email) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if len(email.To) == 0 {
+		return fmt.Errorf("no email recipients given")
+	}
+
+	from := config.From
+	if from == "" {
+		from = config.Username
+	}
+
+	msg, err := buildMessage(from, email, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	if config.Port != 465 {
+		if err := smtp.SendMail(addr, auth, from, email.To, msg); err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: config.Host})
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	for _, to := range email.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", to, err)
+		}
+	}
+	wc, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := wc.Write(msg); err != nil {
+		wc.Close()
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders the RFC 5322 message, using multipart/alternative
+// when an HTML body is present
+func buildMessage(from string, email Email, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(email.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if email.HTMLBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		buf.WriteString(normalizeNewlines(email.TextBody))
+		return buf.Bytes(), nil
+	}
+
+	var raw [12]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return nil, err
+	}
+	boundary := "web-recap-" + hex.EncodeToString(raw[:])
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&buf, "--%s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n", boundary)
+	buf.WriteString(normalizeNewlines(email.TextBody))
+	fmt.Fprintf(&buf, "\r\n--%s\r\nContent-Type: text/html; charset=utf-8\r\n\r\n", boundary)
+	buf.WriteString(normalizeNewlines(email.HTMLBody))
+	fmt.Fprintf(&buf, "\r\n--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func normalizeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pushBackoff is the delay before the first retry; it doubles on each attempt
+var pushBackoff = time.Second
+
+// maxRetryAfter caps how long a Retry-After header can make Push wait
+const maxRetryAfter = time.Minute
+
+// PushConfig describes an HTTP endpoint that receives reports
+type PushConfig struct {
+	URL   string
+	Token string
+	// Retries is how many times a failed request is retried
+	Retries int
+}
+
+// Push POSTs body to the configured URL, with a bearer token when set.
+// Network errors, 429 and 5xx responses are retried with exponential
+// backoff; other non-2xx responses fail immediately. Cancelling ctx stops
+// the request and any wait between retries.
+func Push(ctx context.Context, cfg PushConfig, body []byte) error {
+	contentType := "application/json"
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		contentType = http.DetectContentType(body)
+	}
+
+	delay := pushBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := pushOnce(ctx, cfg, contentType, body)
+		if err == nil || retryAfter < 0 || attempt >= cfg.Retries {
+			return err
+		}
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// pushOnce sends one request. It returns the delay requested by the server
+// (0 when none), or -1 when the error should not be retried.
+func pushOnce(ctx context.Context, cfg PushConfig, contentType string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("invalid post URL: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "web-recap")
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, fmt.Errorf("post request failed: %w", err)
+		}
+		return 0, fmt.Errorf("post request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("post returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return -1, err
+	}
+	if secs, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && secs > 0 {
+		return min(time.Duration(secs)*time.Second, maxRetryAfter), err
+	}
+	return 0, err
+}
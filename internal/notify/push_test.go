@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPush(t *testing.T) {
+	old := pushBackoff
+	pushBackoff = time.Millisecond
+	defer func() { pushBackoff = old }()
+
+	tests := []struct {
+		name     string
+		statuses []int
+		body     string
+		retries  int
+		wantErr  bool
+		wantHits int
+		wantType string
+	}{
+		{"ok", []int{200}, `{"entries":[]}`, 3, false, 1, "application/json"},
+		{"retries server errors", []int{502, 503, 204}, `{}`, 3, false, 3, "application/json"},
+		{"gives up after retries", []int{500, 500, 500}, `{}`, 2, true, 3, "application/json"},
+		{"no retry on client error", []int{401, 200}, `{}`, 3, true, 1, "application/json"},
+		{"retries rate limit", []int{429, 200}, `{}`, 1, false, 2, "application/json"},
+		{"plain text", []int{200}, "# Recap\n", 0, false, 1, "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("Authorization = %q", got)
+				}
+				if got := r.Header.Get("Content-Type"); got != tt.wantType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+				}
+				w.WriteHeader(tt.statuses[hits])
+				hits++
+			}))
+			defer server.Close()
+
+			err := Push(context.Background(), PushConfig{URL: server.URL, Token: "secret", Retries: tt.retries}, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Push error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret") {
+				t.Errorf("error leaks token: %v", err)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("requests = %d, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestPushCanceled(t *testing.T) {
+	old := pushBackoff
+	pushBackoff = time.Hour
+	defer func() { pushBackoff = old }()
+
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := Push(ctx, PushConfig{URL: server.URL, Retries: 3}, []byte(`{}`)); err == nil {
+		t.Fatal("Push succeeded against a failing server")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Push waited %v after its context was done", elapsed)
+	}
+	if hits != 1 {
+		t.Errorf("requests = %d, want 1", hits)
+	}
+}
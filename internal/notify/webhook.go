@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Slack and Discord reject payloads whose text blocks exceed these lengths
+const (
+	slackSectionLimit       = 3000
+	discordDescriptionLimit = 4096
+	discordFieldLimit       = 1024
+)
+
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// PostSlackDigest posts the digest to a Slack incoming webhook using Block Kit
+func PostSlackDigest(webhookURL string, report models.DigestReport) error {
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]string{"type": "plain_text", "text": truncate(report.Title, 150)},
+		},
+		{
+			"type": "section",
+			"fields": []map[string]string{
+				{"type": "mrkdwn", "text": fmt.Sprintf("*Visits*\n%d", report.TotalVisits)},
+				{"type": "mrkdwn", "text": fmt.Sprintf("*Unique pages*\n%d", report.UniquePages)},
+				{"type": "mrkdwn", "text": fmt.Sprintf("*Domains*\n%d", report.UniqueDomains)},
+				{"type": "mrkdwn", "text": fmt.Sprintf("*Browser*\n%s", report.Browser)},
+			},
+		},
+	}
+
+	if len(report.TopDomains) > 0 {
+		var b strings.Builder
+		b.WriteString("*Top domains*\n")
+		for i, d := range report.TopDomains {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, d.Domain, d.Visits)
+		}
+		blocks = append(blocks, slackSection(b.String()))
+	}
+
+	if len(report.TopPages) > 0 {
+		var b strings.Builder
+		b.WriteString("*Top pages*\n")
+		for i, p := range report.TopPages {
+			fmt.Fprintf(&b, "%d. <%s|%s> (%d)\n", i+1, p.URL, slackEscape(pageTitle(p)), p.Visits)
+		}
+		blocks = append(blocks, slackSection(b.String()))
+	}
+
+	return postJSON(webhookURL, map[string]interface{}{
+		"text":   report.Title,
+		"blocks": blocks,
+	})
+}
+
+// PostDiscordDigest posts the digest to a Discord webhook as an embed
+func PostDiscordDigest(webhookURL string, report models.DigestReport) error {
+	description := fmt.Sprintf("**%d** visits · **%d** unique pages · **%d** domains · browser: %s",
+		report.TotalVisits, report.UniquePages, report.UniqueDomains, report.Browser)
+
+	var fields []map[string]interface{}
+	if len(report.TopDomains) > 0 {
+		var b strings.Builder
+		for i, d := range report.TopDomains {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, d.Domain, d.Visits)
+		}
+		fields = append(fields, map[string]interface{}{
+			"name":  "Top domains",
+			"value": truncate(b.String(), discordFieldLimit),
+		})
+	}
+	if len(report.TopPages) > 0 {
+		var b strings.Builder
+		for i, p := range report.TopPages {
+			fmt.Fprintf(&b, "%d. [%s](%s) (%d)\n", i+1, discordEscape(pageTitle(p)), p.URL, p.Visits)
+		}
+		fields = append(fields, map[string]interface{}{
+			"name":  "Top pages",
+			"value": truncate(b.String(), discordFieldLimit),
+		})
+	}
+
+	return postJSON(webhookURL, map[string]interface{}{
+		"embeds": []map[string]interface{}{
+			{
+				"title":       truncate(report.Title, 256),
+				"description": truncate(description, discordDescriptionLimit),
+				"fields":      fields,
+				"timestamp":   report.EndDate.Format(time.RFC3339),
+			},
+		},
+	})
+}
+
+// postJSON sends payload to url and treats any non-2xx response as an error
+func postJSON(url string, payload interface{}) error {
+	return sendJSON(http.MethodPost, url, nil, payload)
+}
+
+// sendJSON sends payload to url with the given method and headers and treats
+// any non-2xx response as an error
+func sendJSON(method, url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+func slackSection(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]string{"type": "mrkdwn", "text": truncate(text, slackSectionLimit)},
+	}
+}
+
+func pageTitle(p models.DigestPage) string {
+	if p.Title == "" {
+		return p.URL
+	}
+	return p.Title
+}
+
+// slackEscape escapes the control characters Slack's mrkdwn reserves
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "|", "¦").Replace(s)
+}
+
+// discordEscape keeps titles from breaking Markdown link syntax
+func discordEscape(s string) string {
+	return strings.NewReplacer("[", "(", "]", ")").Replace(s)
+}
+
+// truncate shortens s to at most limit bytes, cutting at a line boundary
+// when possible so lists don't end mid-entry
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := s[:limit-len("…")]
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		cut = cut[:i+1]
+	}
+	return strings.ToValidUTF8(cut, "") + "…"
+}
@@ -0,0 +1,67 @@
+// Package offline enforces --offline, which guarantees that no browsing
+// data leaves the machine: network features refuse to start, and any HTTP
+// request that slips past those checks fails instead of being sent.
+package offline
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var enabled bool
+
+// Error reports a network feature that was requested in offline mode
+type Error struct {
+	Feature string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s needs network access, which --offline disables", e.Feature)
+}
+
+// Enable turns on offline mode for the rest of the process. Clients built on
+// http.DefaultTransport, which is all of web-recap's, fail every request.
+func Enable() {
+	enabled = true
+	http.DefaultTransport = transport{}
+}
+
+// Enabled reports whether offline mode is on
+func Enabled() bool {
+	return enabled
+}
+
+// Check returns an *Error for feature when offline mode is on
+func Check(feature string) error {
+	if !enabled {
+		return nil
+	}
+	return &Error{Feature: feature}
+}
+
+// IsLoopback reports whether addr, a host or host:port, only reaches this
+// machine. An empty host listens on every interface, so it is not loopback.
+func IsLoopback(addr string) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// transport fails every request without opening a connection
+type transport struct{}
+
+func (transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, &Error{Feature: "request to " + req.URL.Host}
+}
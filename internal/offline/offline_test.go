@@ -0,0 +1,58 @@
+package offline
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsLoopback(t *testing.T) {
+	tests := map[string]bool{
+		"localhost:7337": true,
+		"127.0.0.1:9090": true,
+		"[::1]:7337":     true,
+		"localhost":      true,
+		":7337":          false,
+		"0.0.0.0:7337":   false,
+		"192.168.1.5:80": false,
+		"example.com:80": false,
+	}
+	for addr, want := range tests {
+		if got := IsLoopback(addr); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestEnable(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer server.Close()
+
+	saved := http.DefaultTransport
+	defer func() {
+		http.DefaultTransport = saved
+		enabled = false
+	}()
+
+	if err := Check("summarize"); err != nil {
+		t.Fatalf("Check before Enable = %v", err)
+	}
+	Enable()
+
+	var offlineErr *Error
+	if err := Check("summarize"); !errors.As(err, &offlineErr) || offlineErr.Feature != "summarize" {
+		t.Errorf("Check = %v, want an offline error", err)
+	}
+
+	client := &http.Client{}
+	if _, err := client.Get(server.URL); !errors.As(err, &offlineErr) {
+		t.Errorf("request error = %v, want an offline error", err)
+	}
+	if hits != 0 {
+		t.Errorf("server received %d requests in offline mode", hits)
+	}
+}
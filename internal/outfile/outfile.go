@@ -0,0 +1,213 @@
+// Package outfile names output files from templates and rotates the files
+// long-running exports append to.
+package outfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields are the values of the placeholders in an output file template
+type Fields struct {
+	// Date is the day the output covers, written as {date} (YYYY-MM-DD)
+	Date time.Time
+	// Time is when the file is created, written as {time} (HHMMSS)
+	Time    time.Time
+	Browser string
+	Host    string
+}
+
+// Expand fills in the {date}, {time}, {browser}, and {host} placeholders
+// of template. Other text, including unknown placeholders, is kept as is.
+func Expand(template string, f Fields) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	return strings.NewReplacer(
+		"{date}", f.Date.Format("2006-01-02"),
+		"{time}", f.Time.Format("150405"),
+		"{browser}", pathSafe(f.Browser),
+		"{host}", pathSafe(f.Host),
+	).Replace(template)
+}
+
+// Templated reports whether template has any of the placeholders Expand
+// fills in
+func Templated(template string) bool {
+	for _, p := range []string{"{date}", "{time}", "{browser}", "{host}"} {
+		if strings.Contains(template, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSafe replaces the characters that can't be in a file name
+func pathSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '-'
+		}
+		return r
+	}, s)
+}
+
+// ParseSize parses a file size such as 512KB, 10MB, or 1GB. Units are
+// powers of 1024, and a plain number is bytes.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if n, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(n), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512KB, 10MB, or 1GB)", s)
+	}
+	return n * multiplier, nil
+}
+
+// Rotation is when a Writer starts a new file. Zero values never rotate.
+type Rotation struct {
+	MaxSize int64
+	MaxAge  time.Duration
+}
+
+// Writer appends to a file named by a template, and moves on to a new file
+// when the template names another one, e.g. {date} at midnight, or when the
+// file reaches the Rotation's size or age. {time} is the time each file is
+// opened. A file
+// rotated out under a name that is still current is renamed with the time it
+// was opened, e.g. history.jsonl to history.20250301-090000.jsonl.
+//
+// Rotation happens between writes, so a single Write is never split across
+// files.
+type Writer struct {
+	template string
+	fields   func(now time.Time) Fields
+	rotation Rotation
+	now      func() time.Time
+
+	f      *os.File
+	path   string
+	size   int64
+	opened time.Time
+}
+
+// NewWriter returns a Writer for the files named by template, with the
+// placeholders for a write at now given by fields. No file is opened until
+// the first Write.
+func NewWriter(template string, fields func(now time.Time) Fields, rotation Rotation) *Writer {
+	return &Writer{template: template, fields: fields, rotation: rotation, now: time.Now}
+}
+
+// name returns the file template names for a write at now, with {time} set
+// to opened
+func (w *Writer) name(now, opened time.Time) string {
+	f := w.fields(now)
+	f.Time = opened
+	return Expand(w.template, f)
+}
+
+// Path returns the file being written, or "" before the first Write
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Write appends p to the current file, first rotating it when it is due
+func (w *Writer) Write(p []byte) (int, error) {
+	now := w.now()
+	if w.f != nil && w.due(now) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	if w.f == nil {
+		if err := w.open(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (w *Writer) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close()
+	w.f = nil
+	return err
+}
+
+// due reports whether the current file should be rotated before a write at
+// now
+func (w *Writer) due(now time.Time) bool {
+	switch {
+	case w.name(now, w.opened) != w.path:
+		return true
+	case w.rotation.MaxSize > 0 && w.size >= w.rotation.MaxSize:
+		return true
+	case w.rotation.MaxAge > 0 && now.Sub(w.opened) >= w.rotation.MaxAge:
+		return true
+	}
+	return false
+}
+
+// rotate closes the current file, renaming it when the next file would
+// have the same name
+func (w *Writer) rotate(now time.Time) error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if w.name(now, now) != w.path {
+		return nil
+	}
+	ext := filepath.Ext(w.path)
+	stem := strings.TrimSuffix(w.path, ext) + "." + w.opened.Format("20060102-150405")
+	rotated := stem + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+		rotated = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	if err := os.Rename(w.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate %s: %v", w.path, err)
+	}
+	return nil
+}
+
+// open opens the file named for now, appending to it when it exists
+func (w *Writer) open(now time.Time) error {
+	path := w.name(now, now)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.path, w.size, w.opened = f, path, info.Size(), now
+	return nil
+}
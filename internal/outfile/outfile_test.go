@@ -0,0 +1,87 @@
+package outfile
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	f := Fields{
+		Date:    time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		Time:    time.Date(2025, 3, 2, 9, 5, 7, 0, time.UTC),
+		Browser: "all",
+		Host:    "laptop",
+	}
+	got := Expand("recaps/{host}/recap-{date}-{browser}-{time}.{ext}.json", f)
+	if want := "recaps/laptop/recap-2025-03-01-all-090507.{ext}.json"; got != want {
+		t.Errorf("Expand = %q, want %q", got, want)
+	}
+	if got := Expand("history.json", f); got != "history.json" {
+		t.Errorf("Expand without placeholders = %q", got)
+	}
+	if !Templated("recap-{date}.json") || Templated("recap-{ext}.json") {
+		t.Error("Templated only reports the known placeholders")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "512KB": 512 << 10, "10mb": 10 << 20, "1G": 1 << 30} {
+		if got, err := ParseSize(in); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "MB", "-1MB", "1.5MB", "ten"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestWriterRotation(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC)
+	fields := func(at time.Time) Fields { return Fields{Date: at} }
+	w := NewWriter(filepath.Join(dir, "visits-{date}.jsonl"), fields, Rotation{MaxSize: 10, MaxAge: time.Hour})
+	w.now = func() time.Time { return now }
+	write := func(s string) {
+		t.Helper()
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("aaaaaa\n")
+	write("bbb\n") // 11 bytes, over MaxSize from here on
+	write("ccc\n") // rotated by size
+	now = now.Add(150 * time.Minute)
+	write("ddd\n") // past MaxAge and midnight: the next day's file
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	want := map[string]string{
+		"visits-2025-03-01.20250301-220000.jsonl": "aaaaaa\nbbb\n",
+		"visits-2025-03-01.jsonl":                 "ccc\n",
+		"visits-2025-03-02.jsonl":                 "ddd\n",
+	}
+	if len(files) != len(want) {
+		t.Fatalf("files = %v, want %d", files, len(want))
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content, ok := want[filepath.Base(f)]; !ok || string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(f), data, content)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// alfredApps are the macOS apps whose icons mark which browser a result
+// was visited in
+var alfredApps = map[string]string{
+	"chrome":    "/Applications/Google Chrome.app",
+	"chromium":  "/Applications/Chromium.app",
+	"edge":      "/Applications/Microsoft Edge.app",
+	"brave":     "/Applications/Brave Browser.app",
+	"vivaldi":   "/Applications/Vivaldi.app",
+	"whale":     "/Applications/Whale.app",
+	"yandex":    "/Applications/Yandex.app",
+	"arc":       "/Applications/Arc.app",
+	"firefox":   "/Applications/Firefox.app",
+	"palemoon":  "/Applications/Pale Moon.app",
+	"seamonkey": "/Applications/SeaMonkey.app",
+	"safari":    "/Applications/Safari.app",
+}
+
+type alfredItem struct {
+	UID          string      `json:"uid"`
+	Title        string      `json:"title"`
+	Subtitle     string      `json:"subtitle"`
+	Arg          string      `json:"arg"`
+	Autocomplete string      `json:"autocomplete"`
+	QuickLookURL string      `json:"quicklookurl"`
+	Icon         *alfredIcon `json:"icon,omitempty"`
+	Text         alfredText  `json:"text"`
+}
+
+type alfredIcon struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+type alfredText struct {
+	Copy      string `json:"copy"`
+	LargeType string `json:"largetype"`
+}
+
+// FormatSearchAlfred writes search results as Alfred script filter JSON.
+// Each item opens its URL, and its subtitle has the domain, visit count, and
+// last visit date in loc. The icon is that of the first browser the page was
+// visited in.
+func FormatSearchAlfred(w io.Writer, results []models.SearchResult, loc *time.Location) error {
+	items := make([]alfredItem, len(results))
+	for i, r := range results {
+		title := r.Title
+		if title == "" {
+			title = r.URL
+		}
+		visits := "1 visit"
+		if r.Visits != 1 {
+			visits = fmt.Sprintf("%d visits", r.Visits)
+		}
+		items[i] = alfredItem{
+			UID:          r.URL,
+			Title:        title,
+			Subtitle:     fmt.Sprintf("%s · %s · last %s", r.Domain, visits, r.LastVisit.In(loc).Format("2006-01-02")),
+			Arg:          r.URL,
+			Autocomplete: title,
+			QuickLookURL: r.URL,
+			Text:         alfredText{Copy: r.URL, LargeType: title},
+		}
+		if len(r.Browsers) > 0 {
+			if app, ok := alfredApps[r.Browsers[0]]; ok {
+				items[i].Icon = &alfredIcon{Type: "fileicon", Path: app}
+			}
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(map[string][]alfredItem{"items": items})
+}
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestFormatSearchAlfred(t *testing.T) {
+	results := []models.SearchResult{
+		{URL: "https://go.dev/blog", Title: "The Go Blog", Domain: "go.dev", Visits: 3, LastVisit: time.Date(2025, 1, 2, 23, 30, 0, 0, time.UTC), Browsers: []string{"firefox", "chrome"}},
+		{URL: "https://example.com/?a=1&b=2", Domain: "example.com", Visits: 1, LastVisit: time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), Browsers: []string{"archivefork"}},
+	}
+	var buf bytes.Buffer
+	if err := FormatSearchAlfred(&buf, results, time.FixedZone("UTC+2", 2*3600)); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Items []alfredItem `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(got.Items))
+	}
+	first := got.Items[0]
+	if first.Arg != "https://go.dev/blog" || first.Subtitle != "go.dev · 3 visits · last 2025-01-03" {
+		t.Errorf("first item = %+v", first)
+	}
+	if first.Icon == nil || first.Icon.Path != "/Applications/Firefox.app" {
+		t.Errorf("first icon = %+v, want Firefox's", first.Icon)
+	}
+	second := got.Items[1]
+	if second.Title != "https://example.com/?a=1&b=2" || second.Subtitle != "example.com · 1 visit · last 2025-01-02" || second.Icon != nil {
+		t.Errorf("second item = %+v", second)
+	}
+}
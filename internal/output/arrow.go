@@ -0,0 +1,406 @@
+package output
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// FormatArrow writes history entries as an Arrow IPC file (also readable as
+// Feather v2) with columns timestamp (µs, UTC), url, title, visit_count,
+// domain, browser, and source. Polars and pandas can load it with read_ipc /
+// read_feather.
+//
+// The format is written directly rather than through the Arrow library to
+// keep the binary small; only the subset needed for this fixed schema is
+// implemented.
+func FormatArrow(w io.Writer, entries []models.HistoryEntry) error {
+	cw := &countingWriter{w: w}
+
+	if _, err := cw.Write(arrowMagicPadded); err != nil {
+		return err
+	}
+
+	schemaMsg := buildFlatbuffer(func(b *fbBuilder) int {
+		return arrowMessage(b, arrowHeaderSchema, func(b *fbBuilder) int { return arrowSchema(b) }, 0)
+	})
+	if _, err := writeArrowMessage(cw, schemaMsg, nil); err != nil {
+		return err
+	}
+
+	// At least one batch is written so empty extractions still have a batch
+	var blocks []arrowBlock
+	for start := 0; ; start += arrowBatchRows {
+		end := min(start+arrowBatchRows, len(entries))
+		block, err := writeArrowBatch(cw, entries[start:end])
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, block)
+		if end == len(entries) {
+			break
+		}
+	}
+
+	// End-of-stream marker, then the footer that indexes the record batches
+	if _, err := cw.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0}); err != nil {
+		return err
+	}
+
+	footer := buildFlatbuffer(func(b *fbBuilder) int {
+		return b.table(
+			fbField{slot: 0, size: 2, value: arrowMetadataV5},
+			fbField{slot: 1, size: 4, child: func(b *fbBuilder) int { return arrowSchema(b) }},
+			fbField{slot: 2, size: 4, child: func(b *fbBuilder) int { return b.structVector(0, nil) }},
+			fbField{slot: 3, size: 4, child: func(b *fbBuilder) int {
+				data := make([]byte, 0, 24*len(blocks))
+				for _, blk := range blocks {
+					data = binary.LittleEndian.AppendUint64(data, uint64(blk.offset))
+					data = binary.LittleEndian.AppendUint32(data, uint32(blk.metaDataLength))
+					data = append(data, 0, 0, 0, 0)
+					data = binary.LittleEndian.AppendUint64(data, uint64(blk.bodyLength))
+				}
+				return b.structVector(len(blocks), data)
+			}},
+		)
+	})
+	if _, err := cw.Write(footer); err != nil {
+		return err
+	}
+	if _, err := cw.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	_, err := cw.Write(arrowMagic)
+	return err
+}
+
+// Rows per record batch; keeps memory bounded for very large extractions
+const arrowBatchRows = 64 * 1024
+
+var (
+	arrowMagic       = []byte("ARROW1")
+	arrowMagicPadded = []byte("ARROW1\x00\x00")
+)
+
+// Constants from the Arrow flatbuffer schema (Schema.fbs, Message.fbs)
+const (
+	arrowMetadataV5       = 4
+	arrowHeaderSchema     = 1
+	arrowHeaderRecord     = 3
+	arrowTypeInt          = 2
+	arrowTypeUtf8         = 5
+	arrowTypeTimestamp    = 10
+	arrowTimeUnitMicrosec = 2
+)
+
+// arrowColumns is the fixed history schema
+var arrowColumns = []struct {
+	name string
+	typ  int
+}{
+	{"timestamp", arrowTypeTimestamp},
+	{"url", arrowTypeUtf8},
+	{"title", arrowTypeUtf8},
+	{"visit_count", arrowTypeInt},
+	{"domain", arrowTypeUtf8},
+	{"browser", arrowTypeUtf8},
+	{"source", arrowTypeUtf8},
+	{"id", arrowTypeUtf8},
+	{"host", arrowTypeUtf8},
+	{"registrable_domain", arrowTypeUtf8},
+}
+
+type arrowBlock struct {
+	offset         int64
+	metaDataLength int32
+	bodyLength     int64
+}
+
+// arrowSchema writes a Schema table for arrowColumns
+func arrowSchema(b *fbBuilder) int {
+	fields := make([]func(*fbBuilder) int, len(arrowColumns))
+	for i, col := range arrowColumns {
+		col := col
+		fields[i] = func(b *fbBuilder) int {
+			return b.table(
+				fbField{slot: 0, size: 4, child: func(b *fbBuilder) int { return b.str(col.name) }},
+				fbField{slot: 1, size: 1, value: 1}, // nullable
+				fbField{slot: 2, size: 1, value: uint64(col.typ)},
+				fbField{slot: 3, size: 4, child: func(b *fbBuilder) int { return arrowType(b, col.typ) }},
+				fbField{slot: 5, size: 4, child: func(b *fbBuilder) int { return b.tableVector(nil) }},
+			)
+		}
+	}
+
+	return b.table(fbField{slot: 1, size: 4, child: func(b *fbBuilder) int { return b.tableVector(fields) }})
+}
+
+// arrowType writes the type table for a column
+func arrowType(b *fbBuilder, typ int) int {
+	switch typ {
+	case arrowTypeTimestamp:
+		return b.table(
+			fbField{slot: 0, size: 2, value: arrowTimeUnitMicrosec},
+			fbField{slot: 1, size: 4, child: func(b *fbBuilder) int { return b.str("UTC") }},
+		)
+	case arrowTypeInt:
+		return b.table(
+			fbField{slot: 0, size: 4, value: 64}, // bitWidth
+			fbField{slot: 1, size: 1, value: 1},  // is_signed
+		)
+	default:
+		return b.table()
+	}
+}
+
+// arrowMessage writes a Message table wrapping header
+func arrowMessage(b *fbBuilder, headerType int, header func(*fbBuilder) int, bodyLength int64) int {
+	return b.table(
+		fbField{slot: 0, size: 2, value: arrowMetadataV5},
+		fbField{slot: 1, size: 1, value: uint64(headerType)},
+		fbField{slot: 2, size: 4, child: header},
+		fbField{slot: 3, size: 8, value: uint64(bodyLength)},
+	)
+}
+
+// writeArrowBatch encodes entries as one record batch message
+func writeArrowBatch(cw *countingWriter, entries []models.HistoryEntry) (arrowBlock, error) {
+	var body, nodes, buffers []byte
+
+	addBuffer := func(data []byte) {
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(data)))
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+	addStrings := func(value func(models.HistoryEntry) string) {
+		offsets := make([]byte, 0, 4*(len(entries)+1))
+		var data []byte
+		offsets = binary.LittleEndian.AppendUint32(offsets, 0)
+		for _, e := range entries {
+			// Readers validate Utf8 columns; browser titles are not always valid
+			data = append(data, strings.ToValidUTF8(value(e), "\uFFFD")...)
+			offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(data)))
+		}
+		addBuffer(offsets)
+		addBuffer(data)
+	}
+
+	for _, col := range arrowColumns {
+		// Every column has no nulls, so the validity bitmap is omitted
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(len(entries)))
+		nodes = binary.LittleEndian.AppendUint64(nodes, 0)
+		addBuffer(nil)
+
+		switch col.name {
+		case "timestamp":
+			values := make([]byte, 0, 8*len(entries))
+			for _, e := range entries {
+				values = binary.LittleEndian.AppendUint64(values, uint64(e.Timestamp.UnixMicro()))
+			}
+			addBuffer(values)
+		case "visit_count":
+			values := make([]byte, 0, 8*len(entries))
+			for _, e := range entries {
+				values = binary.LittleEndian.AppendUint64(values, uint64(int64(e.VisitCount)))
+			}
+			addBuffer(values)
+		case "url":
+			addStrings(func(e models.HistoryEntry) string { return e.URL })
+		case "title":
+			addStrings(func(e models.HistoryEntry) string { return e.Title })
+		case "domain":
+			addStrings(func(e models.HistoryEntry) string { return e.Domain })
+		case "browser":
+			addStrings(func(e models.HistoryEntry) string { return e.Browser })
+		case "source":
+			addStrings(func(e models.HistoryEntry) string { return e.Source })
+		case "id":
+			addStrings(func(e models.HistoryEntry) string { return e.ID })
+		case "host":
+			addStrings(func(e models.HistoryEntry) string { return e.Host })
+		case "registrable_domain":
+			addStrings(func(e models.HistoryEntry) string { return e.RegistrableDomain })
+		}
+	}
+
+	meta := buildFlatbuffer(func(b *fbBuilder) int {
+		return arrowMessage(b, arrowHeaderRecord, func(b *fbBuilder) int {
+			return b.table(
+				fbField{slot: 0, size: 8, value: uint64(len(entries))},
+				fbField{slot: 1, size: 4, child: func(b *fbBuilder) int { return b.structVector(len(nodes)/16, nodes) }},
+				fbField{slot: 2, size: 4, child: func(b *fbBuilder) int { return b.structVector(len(buffers)/16, buffers) }},
+			)
+		}, int64(len(body)))
+	})
+
+	return writeArrowMessage(cw, meta, body)
+}
+
+// writeArrowMessage writes an encapsulated IPC message: continuation marker,
+// metadata length, flatbuffer metadata padded to 8 bytes, then the body
+func writeArrowMessage(cw *countingWriter, meta, body []byte) (arrowBlock, error) {
+	block := arrowBlock{offset: cw.n, bodyLength: int64(len(body))}
+
+	padded := (len(meta) + 7) / 8 * 8
+	block.metaDataLength = int32(8 + padded)
+
+	header := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	header = binary.LittleEndian.AppendUint32(header, uint32(padded))
+	for _, chunk := range [][]byte{header, meta, make([]byte, padded-len(meta)), body} {
+		if _, err := cw.Write(chunk); err != nil {
+			return block, err
+		}
+	}
+
+	return block, nil
+}
+
+// countingWriter tracks the file offset for the footer's block index
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// fbBuilder is a minimal front-to-back flatbuffer encoder. Objects are
+// appended after the field that references them, so offsets always point
+// forward as the format requires.
+type fbBuilder struct {
+	buf []byte
+}
+
+// fbField is a table field: a little-endian scalar, or an offset to a child
+// object (string, vector, or table) written after the table
+type fbField struct {
+	slot  int
+	size  int
+	value uint64
+	child func(*fbBuilder) int
+}
+
+// buildFlatbuffer returns a finished buffer whose root is written by root
+func buildFlatbuffer(root func(*fbBuilder) int) []byte {
+	b := &fbBuilder{buf: make([]byte, 4)}
+	pos := root(b)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	return b.buf
+}
+
+func (b *fbBuilder) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// table writes a vtable followed by the table and its children, returning
+// the table position
+func (b *fbBuilder) table(fields ...fbField) int {
+	numSlots := 0
+	for _, f := range fields {
+		if f.slot+1 > numSlots {
+			numSlots = f.slot + 1
+		}
+	}
+
+	// Place fields largest first after the vtable offset so each is
+	// naturally aligned; the table itself starts 8-byte aligned
+	ordered := append([]fbField(nil), fields...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].size > ordered[j].size })
+	fieldOffsets := make([]int, numSlots)
+	size := 4
+	for _, f := range ordered {
+		for size%f.size != 0 {
+			size++
+		}
+		fieldOffsets[f.slot] = size
+		size += f.size
+	}
+
+	b.align(2)
+	vtable := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(4+2*numSlots))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	for _, off := range fieldOffsets {
+		b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(off))
+	}
+
+	b.align(8)
+	start := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[start:], uint32(int32(start-vtable)))
+
+	for _, f := range fields {
+		if f.child != nil {
+			continue
+		}
+		p := b.buf[start+fieldOffsets[f.slot]:]
+		switch f.size {
+		case 1:
+			p[0] = byte(f.value)
+		case 2:
+			binary.LittleEndian.PutUint16(p, uint16(f.value))
+		case 4:
+			binary.LittleEndian.PutUint32(p, uint32(f.value))
+		case 8:
+			binary.LittleEndian.PutUint64(p, f.value)
+		}
+	}
+	for _, f := range fields {
+		if f.child == nil {
+			continue
+		}
+		at := start + fieldOffsets[f.slot]
+		child := f.child(b)
+		binary.LittleEndian.PutUint32(b.buf[at:], uint32(child-at))
+	}
+
+	return start
+}
+
+// str writes a length-prefixed, zero-terminated string
+func (b *fbBuilder) str(s string) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(s)))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// tableVector writes a vector of offsets to tables written by children
+func (b *fbBuilder) tableVector(children []func(*fbBuilder) int) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(children)))
+	slots := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4*len(children))...)
+
+	for i, child := range children {
+		at := slots + 4*i
+		c := child(b)
+		binary.LittleEndian.PutUint32(b.buf[at:], uint32(c-at))
+	}
+	return pos
+}
+
+// structVector writes a vector of n inline 8-byte-aligned structs
+func (b *fbBuilder) structVector(n int, data []byte) int {
+	for (len(b.buf)+4)%8 != 0 {
+		b.buf = append(b.buf, 0)
+	}
+	pos := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(n))
+	b.buf = append(b.buf, data...)
+	return pos
+}
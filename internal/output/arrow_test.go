@@ -0,0 +1,182 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// fbTable reads fields of a flatbuffer table for round-trip checks
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+func fbRoot(buf []byte) fbTable {
+	return fbTable{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+func (t fbTable) offset(slot int) int {
+	vt := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*slot >= int(binary.LittleEndian.Uint16(t.buf[vt:])) {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint16(t.buf[vt+4+2*slot:]))
+}
+
+func (t fbTable) scalar(slot, size int) uint64 {
+	off := t.offset(slot)
+	if off == 0 {
+		return 0
+	}
+	p := t.buf[t.pos+off:]
+	switch size {
+	case 1:
+		return uint64(p[0])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(p))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(p))
+	default:
+		return binary.LittleEndian.Uint64(p)
+	}
+}
+
+func (t fbTable) ref(slot int) int {
+	p := t.pos + t.offset(slot)
+	return p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t fbTable) table(slot int) fbTable {
+	return fbTable{buf: t.buf, pos: t.ref(slot)}
+}
+
+func (t fbTable) str(slot int) string {
+	p := t.ref(slot)
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	return string(t.buf[p+4 : p+4+n])
+}
+
+// vector returns the element count and the position of the first element
+func (t fbTable) vector(slot int) (int, int) {
+	p := t.ref(slot)
+	return int(binary.LittleEndian.Uint32(t.buf[p:])), p + 4
+}
+
+func TestFormatArrowRoundTrip(t *testing.T) {
+	ts := time.Date(2026, 3, 2, 9, 30, 0, 123456000, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: ts, URL: "https://go.dev/doc", Title: "Docs", VisitCount: 3, Domain: "go.dev", Browser: "chrome", ID: "3f2a9c01d4e5b677", Host: "blog.go.dev", RegistrableDomain: "go.dev"},
+		{Timestamp: ts.Add(time.Minute), URL: "https://example.com/ü", Title: "bad \xff title", VisitCount: 1, Domain: "example.com", Browser: "firefox"},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatArrow(&buf, entries); err != nil {
+		t.Fatalf("FormatArrow: %v", err)
+	}
+	file := buf.Bytes()
+
+	if !bytes.HasPrefix(file, []byte("ARROW1\x00\x00")) || !bytes.HasSuffix(file, []byte("ARROW1")) {
+		t.Fatal("missing ARROW1 magic")
+	}
+
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-10:]))
+	footer := fbRoot(file[len(file)-10-footerLen : len(file)-10])
+	if v := footer.scalar(0, 2); v != arrowMetadataV5 {
+		t.Errorf("footer version = %d, want V5", v)
+	}
+
+	// Schema
+	schema := footer.table(1)
+	n, first := schema.vector(1)
+	if n != len(arrowColumns) {
+		t.Fatalf("schema has %d fields, want %d", n, len(arrowColumns))
+	}
+	for i, col := range arrowColumns {
+		at := first + 4*i
+		field := fbTable{buf: schema.buf, pos: at + int(binary.LittleEndian.Uint32(schema.buf[at:]))}
+		if name := field.str(0); name != col.name {
+			t.Errorf("field %d name = %q, want %q", i, name, col.name)
+		}
+		if typ := field.scalar(2, 1); int(typ) != col.typ {
+			t.Errorf("field %s type = %d, want %d", col.name, typ, col.typ)
+		}
+	}
+
+	// Record batch block
+	nBlocks, blockAt := footer.vector(3)
+	if nBlocks != 1 || blockAt%8 != 0 {
+		t.Fatalf("blocks = %d at %d, want 1 block 8-byte aligned", nBlocks, blockAt)
+	}
+	fb := footer.buf
+	offset := int(binary.LittleEndian.Uint64(fb[blockAt:]))
+	metaLen := int(binary.LittleEndian.Uint32(fb[blockAt+8:]))
+	bodyLen := int(binary.LittleEndian.Uint64(fb[blockAt+16:]))
+	if offset%8 != 0 || metaLen%8 != 0 {
+		t.Errorf("block offset %d / metadata length %d not 8-byte aligned", offset, metaLen)
+	}
+	if binary.LittleEndian.Uint32(file[offset:]) != 0xFFFFFFFF {
+		t.Fatal("record batch missing continuation marker")
+	}
+
+	msg := fbRoot(file[offset+8 : offset+metaLen])
+	if msg.scalar(1, 1) != arrowHeaderRecord {
+		t.Fatalf("header type = %d, want RecordBatch", msg.scalar(1, 1))
+	}
+	if got := int(msg.scalar(3, 8)); got != bodyLen {
+		t.Errorf("message bodyLength = %d, block says %d", got, bodyLen)
+	}
+	if (msg.pos+msg.offset(3))%8 != 0 {
+		t.Error("bodyLength field is not 8-byte aligned")
+	}
+
+	batch := msg.table(2)
+	if rows := batch.scalar(0, 8); rows != 2 {
+		t.Errorf("batch length = %d, want 2", rows)
+	}
+	nBuffers, buffersAt := batch.vector(2)
+	if nBuffers != 28 {
+		t.Fatalf("buffers = %d, want 28", nBuffers)
+	}
+	body := file[offset+metaLen : offset+metaLen+bodyLen]
+	buffer := func(i int) []byte {
+		p := buffersAt + 16*i
+		off := int(binary.LittleEndian.Uint64(msg.buf[p:]))
+		length := int(binary.LittleEndian.Uint64(msg.buf[p+8:]))
+		if off%8 != 0 {
+			t.Errorf("buffer %d offset %d not 8-byte aligned", i, off)
+		}
+		return body[off : off+length]
+	}
+
+	// timestamp values are buffer 1, url offsets/data are buffers 3/4,
+	// title offsets/data are buffers 6/7
+	if got := int64(binary.LittleEndian.Uint64(buffer(1)[8:])); got != ts.Add(time.Minute).UnixMicro() {
+		t.Errorf("timestamp[1] = %d, want %d", got, ts.Add(time.Minute).UnixMicro())
+	}
+	offsets, data := buffer(3), buffer(4)
+	start, end := binary.LittleEndian.Uint32(offsets[4:]), binary.LittleEndian.Uint32(offsets[8:])
+	if got := string(data[start:end]); got != "https://example.com/ü" {
+		t.Errorf("url[1] = %q", got)
+	}
+	offsets, data = buffer(6), buffer(7)
+	start, end = binary.LittleEndian.Uint32(offsets[4:]), binary.LittleEndian.Uint32(offsets[8:])
+	if got := string(data[start:end]); got != "bad � title" {
+		t.Errorf("title[1] = %q, want invalid UTF-8 replaced", got)
+	}
+	// id, host, and registrable_domain offsets/data are the last buffers
+	for _, col := range []struct {
+		name string
+		buf  int
+		want string
+	}{{"id", 20, "3f2a9c01d4e5b677"}, {"host", 23, "blog.go.dev"}, {"registrable_domain", 26, "go.dev"}} {
+		offsets, data = buffer(col.buf), buffer(col.buf+1)
+		start, end = binary.LittleEndian.Uint32(offsets[0:]), binary.LittleEndian.Uint32(offsets[4:])
+		if got := string(data[start:end]); got != col.want {
+			t.Errorf("%s[0] = %q, want %q", col.name, got, col.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// NewBookmarkTreeReport nests the report's bookmarks in their folders, with
+// one root folder per browser. Folders and bookmarks keep the order they
+// were first seen in, and each bookmark's Folder is cleared since its place
+// in the tree gives the path.
+func NewBookmarkTreeReport(report models.BookmarkReport) models.BookmarkTreeReport {
+	tree := models.BookmarkTreeReport{
+		Browser:      report.Browser,
+		StartDate:    report.StartDate,
+		EndDate:      report.EndDate,
+		Timezone:     report.Timezone,
+		Meta:         report.Meta,
+		Source:       report.Source,
+		Sources:      report.Sources,
+		Warnings:     report.Warnings,
+		TotalEntries: report.TotalEntries,
+		Browsers:     []models.BookmarkFolder{},
+	}
+
+	roots := make(map[string]*treeFolder)
+	var order []*treeFolder
+	for _, e := range report.Entries {
+		root := roots[e.Browser]
+		if root == nil {
+			root = &treeFolder{name: e.Browser}
+			roots[e.Browser] = root
+			order = append(order, root)
+		}
+		folder := root
+		if e.Folder != "" {
+			for _, name := range strings.Split(e.Folder, "/") {
+				folder = folder.child(name)
+			}
+		}
+		e.Folder = ""
+		folder.nodes = append(folder.nodes, treeNode{bookmark: &e})
+	}
+
+	for _, root := range order {
+		tree.Browsers = append(tree.Browsers, root.build())
+	}
+	return tree
+}
+
+// treeFolder is a folder being built, with its subfolders by name
+type treeFolder struct {
+	name    string
+	nodes   []treeNode
+	folders map[string]*treeFolder
+}
+
+type treeNode struct {
+	bookmark *models.BookmarkEntry
+	folder   *treeFolder
+}
+
+// child returns the subfolder called name, adding it after the nodes seen
+// so far when it is new
+func (f *treeFolder) child(name string) *treeFolder {
+	if c := f.folders[name]; c != nil {
+		return c
+	}
+	c := &treeFolder{name: name}
+	if f.folders == nil {
+		f.folders = make(map[string]*treeFolder)
+	}
+	f.folders[name] = c
+	f.nodes = append(f.nodes, treeNode{folder: c})
+	return c
+}
+
+func (f *treeFolder) build() models.BookmarkFolder {
+	folder := models.BookmarkFolder{Name: f.name}
+	for _, n := range f.nodes {
+		if n.bookmark != nil {
+			folder.Children = append(folder.Children, models.BookmarkNode{Type: "url", Bookmark: n.bookmark})
+			continue
+		}
+		sub := n.folder.build()
+		folder.Children = append(folder.Children, models.BookmarkNode{Type: "folder", Folder: &sub})
+	}
+	return folder
+}
+
+// FormatBookmarkTreeJSON writes a bookmark tree report as JSON
+func FormatBookmarkTreeJSON(w io.Writer, report models.BookmarkTreeReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestNewBookmarkTreeReport(t *testing.T) {
+	entries := []models.BookmarkEntry{
+		{URL: "https://go.dev/", Folder: "Bookmarks Bar", Browser: "chrome"},
+		{URL: "https://go.dev/ref/spec", Folder: "Bookmarks Bar/Go", Browser: "chrome"},
+		{URL: "https://news.example/", Folder: "Bookmarks Bar", Browser: "chrome"},
+		{URL: "https://mdn.example/", Folder: "toolbar", Browser: "firefox"},
+		{URL: "https://loose.example/", Browser: "firefox"},
+		{URL: "https://pkg.go.dev/", Folder: "Bookmarks Bar/Go", Browser: "chrome"},
+	}
+	report := NewBookmarkTreeReport(NewBookmarkReport(entries, "all", time.Time{}, time.Time{}, ""))
+	if report.TotalEntries != len(entries) {
+		t.Errorf("total_entries = %d", report.TotalEntries)
+	}
+
+	var lines []string
+	var walk func(f models.BookmarkFolder, depth int)
+	walk = func(f models.BookmarkFolder, depth int) {
+		lines = append(lines, strings.Repeat("  ", depth)+f.Name+"/")
+		for _, n := range f.Children {
+			switch n.Type {
+			case "url":
+				if n.Bookmark.Folder != "" {
+					t.Errorf("%s kept its folder %q", n.Bookmark.URL, n.Bookmark.Folder)
+				}
+				lines = append(lines, strings.Repeat("  ", depth+1)+n.Bookmark.URL)
+			case "folder":
+				walk(*n.Folder, depth+1)
+			}
+		}
+	}
+	for _, root := range report.Browsers {
+		walk(root, 0)
+	}
+
+	want := []string{
+		"chrome/",
+		"  Bookmarks Bar/",
+		"    https://go.dev/",
+		"    Go/",
+		"      https://go.dev/ref/spec",
+		"      https://pkg.go.dev/",
+		"    https://news.example/",
+		"firefox/",
+		"  toolbar/",
+		"    https://mdn.example/",
+		"  https://loose.example/",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tree:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if entries[1].Folder != "Bookmarks Bar/Go" {
+		t.Error("input entries were modified")
+	}
+}
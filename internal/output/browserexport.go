@@ -0,0 +1,42 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// browserexportVisit is one visit in the format browserexport writes with
+// `merge --json`, which promnesia and HPI's browser module read
+type browserexportVisit struct {
+	URL      string                 `json:"url"`
+	DT       int64                  `json:"dt"`
+	Metadata *browserexportMetadata `json:"metadata"`
+}
+
+type browserexportMetadata struct {
+	Title        *string `json:"title"`
+	Description  *string `json:"description"`
+	PreviewImage *string `json:"preview_image"`
+	Duration     *int    `json:"duration"`
+}
+
+// FormatBrowserExport writes history entries as a JSON list of browserexport
+// visits: the URL, the visit time in epoch seconds (UTC), and metadata with
+// the title. Browsers web-recap reads don't record descriptions, preview
+// images, or visit durations, so those are null.
+func FormatBrowserExport(w io.Writer, entries []models.HistoryEntry) error {
+	visits := make([]browserexportVisit, len(entries))
+	for i, e := range entries {
+		visits[i] = browserexportVisit{URL: e.URL, DT: e.Timestamp.Unix()}
+		if e.Title != "" {
+			title := e.Title
+			visits[i].Metadata = &browserexportMetadata{Title: &title}
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(visits)
+}
@@ -0,0 +1,24 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestFormatBrowserExport(t *testing.T) {
+	entries := []models.HistoryEntry{
+		{URL: "https://example.com/a?x=1&y=2", Title: "A <b>", Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{URL: "https://example.com/b", Timestamp: time.Unix(1700000000, 0).In(time.FixedZone("X", 3600))},
+	}
+	var buf bytes.Buffer
+	if err := FormatBrowserExport(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"url":"https://example.com/a?x=1&y=2","dt":1704164645,"metadata":{"title":"A <b>","description":null,"preview_image":null,"duration":null}},{"url":"https://example.com/b","dt":1700000000,"metadata":null}]` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
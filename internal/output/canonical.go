@@ -0,0 +1,41 @@
+package output
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// CanonicalizeEntries returns a copy of entries in a deterministic form, so
+// exports of the same history are byte-identical across runs: timestamps are
+// converted to UTC and truncated to microseconds (the finest precision any
+// supported browser stores), and entries are ordered newest first, with ties
+// broken by URL, browser, title, and visit count. JSON field order is fixed
+// by the model structs.
+func CanonicalizeEntries(entries []models.HistoryEntry) []models.HistoryEntry {
+	result := make([]models.HistoryEntry, len(entries))
+	for i, e := range entries {
+		e.Timestamp = e.Timestamp.UTC().Truncate(time.Microsecond)
+		result[i] = e
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		if a.URL != b.URL {
+			return a.URL < b.URL
+		}
+		if a.Browser != b.Browser {
+			return a.Browser < b.Browser
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.VisitCount < b.VisitCount
+	})
+
+	return result
+}
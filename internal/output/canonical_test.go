@@ -0,0 +1,50 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCanonicalizeEntries(t *testing.T) {
+	est := time.FixedZone("EST", -5*3600)
+	ts := time.Date(2026, 3, 2, 9, 0, 0, 123456789, est)
+	entries := []models.HistoryEntry{
+		{Timestamp: ts.Add(-time.Hour), URL: "https://a.example/old", Browser: "chrome"},
+		{Timestamp: ts, URL: "https://b.example/", Browser: "firefox"},
+		{Timestamp: ts, URL: "https://a.example/", Browser: "safari"},
+		{Timestamp: ts, URL: "https://a.example/", Browser: "chrome"},
+	}
+
+	got := CanonicalizeEntries(entries)
+	want := []string{"https://a.example/ chrome", "https://a.example/ safari", "https://b.example/ firefox", "https://a.example/old chrome"}
+	for i, e := range got {
+		if key := e.URL + " " + e.Browser; key != want[i] {
+			t.Errorf("entry %d = %s, want %s", i, key, want[i])
+		}
+		if e.Timestamp.Location() != time.UTC || e.Timestamp.Nanosecond()%1000 != 0 {
+			t.Errorf("entry %d timestamp not normalized: %v", i, e.Timestamp)
+		}
+	}
+	if entries[0].URL != "https://a.example/old" || entries[0].Timestamp.Location() != est {
+		t.Error("input entries were modified")
+	}
+
+	// Reversed input produces identical JSON
+	reversed := make([]models.HistoryEntry, len(entries))
+	for i, e := range entries {
+		reversed[len(entries)-1-i] = e
+	}
+	var a, b bytes.Buffer
+	if err := FormatJSON(&a, CanonicalizeEntries(entries), "all", ts, ts, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	if err := FormatJSON(&b, CanonicalizeEntries(reversed), "all", ts, ts, "UTC"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Errorf("output differs:\n%s\n%s", a.String(), b.String())
+	}
+}
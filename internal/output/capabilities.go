@@ -0,0 +1,40 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// FormatCapabilitiesText writes a capabilities report as a readable list,
+// one line per data type, e.g.
+//
+//	Google Chrome (chrome, chromium engine)
+//	  history    yes
+//	  tabs       no   open tabs are only read from Chromium session files
+func FormatCapabilitiesText(w io.Writer, report models.CapabilitiesReport) error {
+	if len(report.Browsers) == 0 {
+		_, err := fmt.Fprintf(w, "No browsers detected on %s\n", report.Platform)
+		return err
+	}
+
+	fmt.Fprintf(w, "Platform: %s\n", report.Platform)
+	for _, b := range report.Browsers {
+		fmt.Fprintf(w, "\n%s (%s, %s engine)\n", b.Name, b.Browser, b.Engine)
+		for _, c := range b.Capabilities {
+			status := "no "
+			if c.Supported {
+				status = "yes"
+			}
+			line := fmt.Sprintf("  %-10s %s", c.Data, status)
+			if c.Reason != "" {
+				line += "  " + c.Reason
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// CollapseRepeats merges repeated visits to the same URL in the same
+// browser, each within window of the one before, into the first visit of the
+// run, with Repeats counting the visits merged. This folds auto-refreshing
+// pages and redirect loops without touching visits further apart. The first
+// non-empty title of a run is kept. Entries are returned newest first, with
+// ties broken by URL.
+func CollapseRepeats(entries []models.HistoryEntry, window time.Duration) []models.HistoryEntry {
+	type key struct {
+		browser, url string
+	}
+	visits := make(map[key][]models.HistoryEntry)
+	var order []key
+	for _, e := range entries {
+		k := key{e.BrowserType, e.URL}
+		if k.browser == "" {
+			k.browser = e.Browser
+		}
+		if _, ok := visits[k]; !ok {
+			order = append(order, k)
+		}
+		visits[k] = append(visits[k], e)
+	}
+
+	result := make([]models.HistoryEntry, 0, len(entries))
+	for _, k := range order {
+		vs := visits[k]
+		sort.SliceStable(vs, func(i, j int) bool {
+			return vs[i].Timestamp.Before(vs[j].Timestamp)
+		})
+		first := -1
+		for i, v := range vs {
+			if first >= 0 && v.Timestamp.Sub(vs[i-1].Timestamp) <= window {
+				run := &result[first]
+				if run.Repeats == 0 {
+					run.Repeats = 1
+				}
+				run.Repeats++
+				if run.Title == "" {
+					run.Title = v.Title
+				}
+				continue
+			}
+			first = len(result)
+			result = append(result, v)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		return a.URL < b.URL
+	})
+	return result
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+	entries := []models.HistoryEntry{
+		// A dashboard refreshing every 20s, then opened again later
+		{URL: "https://grafana.example/d/1", Browser: "chrome", Timestamp: at(600)},
+		{URL: "https://grafana.example/d/1", Browser: "chrome", Timestamp: at(40)},
+		{URL: "https://grafana.example/d/1", Browser: "chrome", Timestamp: at(20)},
+		{URL: "https://grafana.example/d/1", Title: "Dashboard", Browser: "chrome", Timestamp: at(0)},
+		// The same URL in another browser is kept apart
+		{URL: "https://grafana.example/d/1", Browser: "firefox", Timestamp: at(10)},
+		{URL: "https://login.example/", Title: "Sign in", Browser: "chrome", Timestamp: at(5)},
+	}
+
+	got := CollapseRepeats(entries, 30*time.Second)
+	want := []struct {
+		browser string
+		at      time.Time
+		title   string
+		repeats int
+	}{
+		{"chrome", at(600), "", 0},
+		{"firefox", at(10), "", 0},
+		{"chrome", at(5), "Sign in", 0},
+		{"chrome", at(0), "Dashboard", 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CollapseRepeats = %+v, want %d entries", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Browser != w.browser || !g.Timestamp.Equal(w.at) || g.Title != w.title || g.Repeats != w.repeats {
+			t.Errorf("entry %d = %s %v %q repeats %d, want %s %v %q repeats %d",
+				i, g.Browser, g.Timestamp, g.Title, g.Repeats, w.browser, w.at, w.title, w.repeats)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// CombineHistoryReports merges history reports, e.g. exports from several
+// machines or date ranges, into one. A visit found in more than one report is
+// kept once, with the highest visit count and the first non-empty title.
+// Entries are ordered newest first, then by URL, and the report covers the
+// earliest start and latest end date. Browser, timezone, and source are
+// kept when all reports agree; sources and warnings are concatenated.
+func CombineHistoryReports(reports []models.HistoryReport) models.HistoryReport {
+	var combined models.HistoryReport
+	index := make(map[string]int)
+	for i, r := range reports {
+		if i == 0 || r.StartDate.Before(combined.StartDate) {
+			combined.StartDate = r.StartDate
+		}
+		if i == 0 || r.EndDate.After(combined.EndDate) {
+			combined.EndDate = r.EndDate
+		}
+		combined.Browser = agree(i, combined.Browser, r.Browser, "all")
+		combined.Timezone = agree(i, combined.Timezone, r.Timezone, "")
+		combined.Source = agree(i, combined.Source, r.Source, "")
+		combined.Sources = append(combined.Sources, r.Sources...)
+		combined.Warnings = appendUnique(combined.Warnings, r.Warnings...)
+
+		for _, e := range r.Entries {
+			k := historyKey(e)
+			j, ok := index[k]
+			if !ok {
+				index[k] = len(combined.Entries)
+				combined.Entries = append(combined.Entries, e)
+				continue
+			}
+			kept := &combined.Entries[j]
+			kept.VisitCount = max(kept.VisitCount, e.VisitCount)
+			if kept.Title == "" {
+				kept.Title = e.Title
+			}
+			if len(e.Browsers) > 0 {
+				kept.Browsers = appendUnique(kept.Browsers, e.Browsers...)
+				sort.Strings(kept.Browsers)
+			}
+		}
+	}
+
+	sort.SliceStable(combined.Entries, func(i, j int) bool {
+		a, b := combined.Entries[i], combined.Entries[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		return a.URL < b.URL
+	})
+	combined.TotalEntries = len(combined.Entries)
+	return combined
+}
+
+// CombineBookmarkReports merges bookmark reports like CombineHistoryReports.
+// A bookmark is the same when its browser and ID match, or, without an ID,
+// its browser, folder, and URL. Bookmarks are ordered by when they were
+// added, newest first, with undated ones last by title.
+func CombineBookmarkReports(reports []models.BookmarkReport) models.BookmarkReport {
+	var combined models.BookmarkReport
+	seen := make(map[string]bool)
+	for i, r := range reports {
+		if r.StartDate != nil && (combined.StartDate == nil || r.StartDate.Before(*combined.StartDate)) {
+			combined.StartDate = r.StartDate
+		}
+		if r.EndDate != nil && (combined.EndDate == nil || r.EndDate.After(*combined.EndDate)) {
+			combined.EndDate = r.EndDate
+		}
+		combined.Browser = agree(i, combined.Browser, r.Browser, "all")
+		combined.Timezone = agree(i, combined.Timezone, r.Timezone, "")
+		combined.Source = agree(i, combined.Source, r.Source, "")
+		combined.Sources = append(combined.Sources, r.Sources...)
+		combined.Warnings = appendUnique(combined.Warnings, r.Warnings...)
+
+		for _, e := range r.Entries {
+			k := e.Browser + "\x00" + e.Folder + "\x00" + e.URL
+			if e.ID != "" {
+				k = e.Browser + "\x00" + e.ID
+			}
+			if !seen[k] {
+				seen[k] = true
+				combined.Entries = append(combined.Entries, e)
+			}
+		}
+	}
+
+	sort.SliceStable(combined.Entries, func(i, j int) bool {
+		a, b := combined.Entries[i], combined.Entries[j]
+		if a.DateAdded.IsZero() != b.DateAdded.IsZero() {
+			return !a.DateAdded.IsZero()
+		}
+		if !a.DateAdded.Equal(b.DateAdded) {
+			return a.DateAdded.After(b.DateAdded)
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.URL < b.URL
+	})
+	combined.TotalEntries = len(combined.Entries)
+	return combined
+}
+
+// historyKey identifies a visit across reports: its stable ID, or for
+// reports written before IDs existed, its browser, URL, and time
+func historyKey(e models.HistoryEntry) string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return e.Browser + "\x00" + e.URL + "\x00" + strconv.FormatInt(e.Timestamp.UnixMicro(), 10)
+}
+
+// agree returns value for the first report, and fallback once a later report
+// disagrees with what the earlier ones had
+func agree(i int, current, value, fallback string) string {
+	if i == 0 || current == value {
+		return value
+	}
+	return fallback
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
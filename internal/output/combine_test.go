@@ -0,0 +1,83 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCombineHistoryReports(t *testing.T) {
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	laptop := models.HistoryReport{
+		Browser:   "chrome",
+		StartDate: day,
+		EndDate:   day.AddDate(0, 0, 1),
+		Timezone:  "UTC",
+		Warnings:  []string{"slow disk"},
+		Entries: []models.HistoryEntry{
+			{ID: "a", Timestamp: day.Add(10 * time.Hour), URL: "https://go.dev/", VisitCount: 1, Browser: "chrome"},
+			{ID: "b", Timestamp: day.Add(9 * time.Hour), URL: "https://b.example/", Title: "B", VisitCount: 1, Browser: "chrome"},
+		},
+	}
+	desktop := models.HistoryReport{
+		Browser:   "firefox",
+		StartDate: day.AddDate(0, 0, -1),
+		EndDate:   day.AddDate(0, 0, 1),
+		Timezone:  "UTC",
+		Warnings:  []string{"slow disk"},
+		Entries: []models.HistoryEntry{
+			{ID: "a", Timestamp: day.Add(10 * time.Hour), URL: "https://go.dev/", Title: "Go", VisitCount: 4, Browser: "chrome"},
+			{Timestamp: day.Add(9 * time.Hour), URL: "https://a.example/", Title: "A", VisitCount: 1, Browser: "firefox"},
+			{Timestamp: day.Add(9 * time.Hour), URL: "https://a.example/", Title: "A", VisitCount: 1, Browser: "firefox"},
+		},
+	}
+
+	got := CombineHistoryReports([]models.HistoryReport{laptop, desktop})
+	var lines []string
+	for _, e := range got.Entries {
+		lines = append(lines, fmt.Sprintf("%s %s %s %d", e.Timestamp.Format("15:04"), e.URL, e.Title, e.VisitCount))
+	}
+	want := []string{
+		"10:00 https://go.dev/ Go 4",
+		"09:00 https://a.example/ A 1",
+		"09:00 https://b.example/ B 1",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("combined:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if got.TotalEntries != 3 || got.Browser != "all" || got.Timezone != "UTC" || len(got.Warnings) != 1 {
+		t.Errorf("report = %+v", got)
+	}
+	if !got.StartDate.Equal(desktop.StartDate) || !got.EndDate.Equal(laptop.EndDate) {
+		t.Errorf("range = %s to %s", got.StartDate, got.EndDate)
+	}
+}
+
+func TestCombineBookmarkReports(t *testing.T) {
+	added := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	a := models.BookmarkReport{Browser: "chrome", Entries: []models.BookmarkEntry{
+		{ID: "g1", URL: "https://go.dev/", Title: "Go", Browser: "chrome", DateAdded: added},
+		{URL: "https://undated.example/", Title: "Undated", Browser: "safari", Folder: "Reading"},
+	}}
+	b := models.BookmarkReport{Browser: "chrome", Entries: []models.BookmarkEntry{
+		{ID: "g1", URL: "https://go.dev/", Title: "Go (renamed)", Browser: "chrome", DateAdded: added},
+		{URL: "https://undated.example/", Title: "Undated", Browser: "safari", Folder: "Reading"},
+		{URL: "https://undated.example/", Title: "Undated", Browser: "safari", Folder: "Other"},
+		{ID: "g2", URL: "https://new.example/", Title: "New", Browser: "chrome", DateAdded: added.Add(time.Hour)},
+	}}
+
+	got := CombineBookmarkReports([]models.BookmarkReport{a, b})
+	var titles []string
+	for _, e := range got.Entries {
+		titles = append(titles, e.Title+"/"+e.Folder)
+	}
+	if want := "New/,Go/,Undated/Reading,Undated/Other"; strings.Join(titles, ",") != want {
+		t.Errorf("combined %s, want %s", strings.Join(titles, ","), want)
+	}
+	if got.Browser != "chrome" || got.TotalEntries != 4 {
+		t.Errorf("report = %+v", got)
+	}
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// FormatDigestJSON writes a digest report as JSON to the given writer
+func FormatDigestJSON(w io.Writer, report models.DigestReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatDigestMarkdown writes a digest report as Markdown to the given writer
+func FormatDigestMarkdown(w io.Writer, report models.DigestReport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", report.Title)
+	fmt.Fprintf(&b, "- **Visits:** %d\n", report.TotalVisits)
+	fmt.Fprintf(&b, "- **Unique pages:** %d\n", report.UniquePages)
+	fmt.Fprintf(&b, "- **Unique domains:** %d\n", report.UniqueDomains)
+	fmt.Fprintf(&b, "- **Browser:** %s\n\n", report.Browser)
+
+	if len(report.Days) > 1 {
+		b.WriteString("## Activity by day\n\n")
+		b.WriteString("| Date | Visits |\n|------|-------:|\n")
+		for _, d := range report.Days {
+			fmt.Fprintf(&b, "| %s | %d |\n", d.Date, d.Visits)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.TopDomains) > 0 {
+		b.WriteString("## Top domains\n\n")
+		for i, d := range report.TopDomains {
+			fmt.Fprintf(&b, "%d. %s (%d)\n", i+1, d.Domain, d.Visits)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(report.TopPages) > 0 {
+		b.WriteString("## Top pages\n\n")
+		for i, p := range report.TopPages {
+			fmt.Fprintf(&b, "%d. [%s](%s) (%d)\n", i+1, markdownLinkText(p), p.URL, p.Visits)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownLinkText returns a title safe to use as Markdown link text
+func markdownLinkText(p models.DigestPage) string {
+	title := p.Title
+	if title == "" {
+		title = p.URL
+	}
+	replacer := strings.NewReplacer("[", "\\[", "]", "\\]", "\n", " ")
+	return replacer.Replace(title)
+}
+
+var digestHTMLTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"pageTitle": func(p models.DigestPage) string {
+		if p.Title == "" {
+			return p.URL
+		}
+		return p.Title
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body style="font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; max-width: 720px; margin: 0 auto; color: #222;">
+<h1 style="font-size: 22px;">{{.Title}}</h1>
+<p>
+  <strong>{{.TotalVisits}}</strong> visits ·
+  <strong>{{.UniquePages}}</strong> unique pages ·
+  <strong>{{.UniqueDomains}}</strong> domains ·
+  browser: {{.Browser}}
+</p>
+{{if gt (len .Days) 1}}
+<h2 style="font-size: 18px;">Activity by day</h2>
+<table cellpadding="4" style="border-collapse: collapse;">
+{{range .Days}}<tr><td>{{.Date}}</td><td style="text-align: right;">{{.Visits}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .TopDomains}}
+<h2 style="font-size: 18px;">Top domains</h2>
+<ol>
+{{range .TopDomains}}<li>{{.Domain}} ({{.Visits}})</li>
+{{end}}</ol>
+{{end}}
+{{if .TopPages}}
+<h2 style="font-size: 18px;">Top pages</h2>
+<ol>
+{{range .TopPages}}<li><a href="{{.URL}}">{{pageTitle .}}</a> ({{.Visits}})</li>
+{{end}}</ol>
+{{end}}
+</body>
+</html>
+`))
+
+// FormatDigestHTML writes a digest report as a standalone HTML document
+func FormatDigestHTML(w io.Writer, report models.DigestReport) error {
+	return digestHTMLTemplate.Execute(w, report)
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"sort"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// CollapseURLs turns visit-level history into one entry per URL and browser.
+// Each entry is the URL's most recent visit, with the newest non-empty title
+// and VisitCount set to the number of visits collapsed into it. Browsers are
+// told apart by type, so Chrome and Edge visits stay separate. Entries are
+// returned newest first, with ties broken by URL.
+func CollapseURLs(entries []models.HistoryEntry) []models.HistoryEntry {
+	type key struct {
+		browser, url string
+	}
+	index := make(map[key]int)
+	var result []models.HistoryEntry
+	for _, e := range entries {
+		k := key{e.BrowserType, e.URL}
+		if k.browser == "" {
+			k.browser = e.Browser
+		}
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(result)
+			e.VisitCount = 1
+			result = append(result, e)
+			continue
+		}
+		c := &result[i]
+		c.VisitCount++
+		if e.Timestamp.After(c.Timestamp) {
+			title := c.Title
+			e.VisitCount = c.VisitCount
+			*c = e
+			if c.Title == "" {
+				c.Title = title
+			}
+		} else if c.Title == "" {
+			c.Title = e.Title
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		return a.URL < b.URL
+	})
+	return result
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCollapseURLs(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{URL: "https://go.dev/", Title: "", Browser: "chrome", BrowserType: "chrome", Timestamp: base.Add(3 * time.Hour), VisitCount: 40},
+		{URL: "https://go.dev/", Title: "Go", Browser: "chrome", BrowserType: "chrome", Timestamp: base.Add(time.Hour), VisitCount: 40},
+		{URL: "https://go.dev/", Title: "Go (Edge)", Browser: "chrome", BrowserType: "edge", Timestamp: base.Add(2 * time.Hour), VisitCount: 3},
+		{URL: "https://example.com/", Title: "Example", Browser: "firefox", Timestamp: base, VisitCount: 9},
+		{URL: "https://go.dev/", Title: "Go old", Browser: "chrome", BrowserType: "chrome", Timestamp: base, VisitCount: 40},
+	}
+
+	got := CollapseURLs(entries)
+	want := []struct {
+		browserType, title string
+		at                 time.Time
+		visits             int
+	}{
+		{"chrome", "Go", base.Add(3 * time.Hour), 3},
+		{"edge", "Go (Edge)", base.Add(2 * time.Hour), 1},
+		{"", "Example", base, 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("CollapseURLs = %+v, want %d entries", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.BrowserType != w.browserType || g.Title != w.title || !g.Timestamp.Equal(w.at) || g.VisitCount != w.visits {
+			t.Errorf("entry %d = %s %q %v %d visits, want %s %q %v %d visits",
+				i, g.BrowserType, g.Title, g.Timestamp, g.VisitCount, w.browserType, w.title, w.at, w.visits)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// GroupOptions controls how NewGroupedHistoryReport groups entries
+type GroupOptions struct {
+	// Loc is the timezone of the hours grouped by "hour"
+	Loc *time.Location
+	// Domain names the group of a domain for "domain" and "site", e.g. the
+	// service it rolls up into; nil groups by the domain itself
+	Domain func(domain string) string
+	// Category names the category of a domain, or "" for none, for "category"
+	Category func(domain string) string
+	// SessionGap is the idle time that starts a new session, for "session"
+	SessionGap time.Duration
+}
+
+// NewGroupedHistoryReport nests the entries of report in groups by domain,
+// site (registrable domain), hour, category, or session. Domain, site, and
+// category groups are ordered by visits, most first; hour and session groups
+// by time, newest first. Entries keep their order within a group.
+func NewGroupedHistoryReport(report models.HistoryReport, by string, opts GroupOptions) models.GroupedHistoryReport {
+	grouped := models.GroupedHistoryReport{
+		Browser:      report.Browser,
+		StartDate:    report.StartDate,
+		EndDate:      report.EndDate,
+		Timezone:     report.Timezone,
+		Meta:         report.Meta,
+		Source:       report.Source,
+		Sources:      report.Sources,
+		Warnings:     report.Warnings,
+		Granularity:  report.Granularity,
+		GroupBy:      by,
+		TotalEntries: len(report.Entries),
+		Groups:       []models.HistoryGroup{},
+	}
+
+	var keys []string
+	switch by {
+	case "session":
+		keys = sessionKeys(report.Entries, opts)
+	default:
+		keys = make([]string, len(report.Entries))
+		for i, e := range report.Entries {
+			keys[i] = groupKey(e, by, opts)
+		}
+	}
+
+	index := make(map[string]int)
+	var urls []map[string]bool
+	for i, e := range report.Entries {
+		g, ok := index[keys[i]]
+		if !ok {
+			g = len(grouped.Groups)
+			index[keys[i]] = g
+			grouped.Groups = append(grouped.Groups, models.HistoryGroup{Key: keys[i], FirstVisit: e.Timestamp, LastVisit: e.Timestamp})
+			urls = append(urls, make(map[string]bool))
+		}
+		group := &grouped.Groups[g]
+		group.Visits++
+		group.Entries = append(group.Entries, e)
+		if e.Timestamp.Before(group.FirstVisit) {
+			group.FirstVisit = e.Timestamp
+		}
+		if e.Timestamp.After(group.LastVisit) {
+			group.LastVisit = e.Timestamp
+		}
+		if !urls[g][e.URL] {
+			urls[g][e.URL] = true
+			group.UniqueURLs++
+		}
+	}
+
+	groups := grouped.Groups
+	switch by {
+	case "domain", "site", "category":
+		sort.SliceStable(groups, func(i, j int) bool {
+			if groups[i].Visits != groups[j].Visits {
+				return groups[i].Visits > groups[j].Visits
+			}
+			return groups[i].LastVisit.After(groups[j].LastVisit)
+		})
+	default:
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].LastVisit.After(groups[j].LastVisit)
+		})
+	}
+	grouped.TotalGroups = len(groups)
+	return grouped
+}
+
+// groupKey returns the domain, site, hour, or category group of e
+func groupKey(e models.HistoryEntry, by string, opts GroupOptions) string {
+	switch by {
+	case "hour":
+		loc := opts.Loc
+		if loc == nil {
+			loc = time.UTC
+		}
+		return e.Timestamp.In(loc).Format("2006-01-02T15:00")
+	case "category":
+		if opts.Category != nil {
+			if name := opts.Category(e.Domain); name != "" {
+				return name
+			}
+		}
+		return "other"
+	default:
+		domain := e.Domain
+		if by == "site" && e.RegistrableDomain != "" {
+			domain = e.RegistrableDomain
+		}
+		if opts.Domain != nil {
+			domain = opts.Domain(domain)
+		}
+		if domain == "" {
+			return "(none)"
+		}
+		return strings.TrimPrefix(strings.ToLower(domain), "www.")
+	}
+}
+
+// sessionKeys returns the session of each entry: runs of visits with no
+// gap longer than opts.SessionGap, keyed by the time the run started
+func sessionKeys(entries []models.HistoryEntry, opts GroupOptions) []string {
+	loc := opts.Loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return entries[order[a]].Timestamp.Before(entries[order[b]].Timestamp)
+	})
+
+	keys := make([]string, len(entries))
+	var key string
+	for n, i := range order {
+		if n == 0 || entries[i].Timestamp.Sub(entries[order[n-1]].Timestamp) > opts.SessionGap {
+			key = entries[i].Timestamp.In(loc).Format(time.RFC3339)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// FormatGroupedHistoryJSON writes a grouped history report as JSON
+func FormatGroupedHistoryJSON(w io.Writer, report models.GroupedHistoryReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
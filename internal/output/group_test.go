@@ -0,0 +1,91 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestNewGroupedHistoryReport(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	visit := func(url, domain string, minute int) models.HistoryEntry {
+		return models.HistoryEntry{URL: url, Domain: domain, Timestamp: base.Add(time.Duration(minute) * time.Minute)}
+	}
+	// Newest first, as read
+	report := models.HistoryReport{Entries: []models.HistoryEntry{
+		visit("https://news.example/b", "news.example", 95),
+		visit("https://go.dev/doc/", "go.dev", 50),
+		visit("https://www.go.dev/", "www.go.dev", 20),
+		visit("https://go.dev/", "go.dev", 10),
+		visit("https://news.example/a", "news.example", 0),
+	}}
+	opts := GroupOptions{
+		Loc:        time.UTC,
+		SessionGap: 30 * time.Minute,
+		Category: func(domain string) string {
+			if domain == "news.example" {
+				return "news"
+			}
+			return ""
+		},
+	}
+
+	tests := []struct {
+		by   string
+		want []models.HistoryGroup
+	}{
+		{"domain", []models.HistoryGroup{
+			{Key: "go.dev", Visits: 3, UniqueURLs: 3},
+			{Key: "news.example", Visits: 2, UniqueURLs: 2},
+		}},
+		{"hour", []models.HistoryGroup{
+			{Key: "2025-03-01T10:00", Visits: 1, UniqueURLs: 1},
+			{Key: "2025-03-01T09:00", Visits: 4, UniqueURLs: 4},
+		}},
+		{"category", []models.HistoryGroup{
+			{Key: "other", Visits: 3, UniqueURLs: 3},
+			{Key: "news", Visits: 2, UniqueURLs: 2},
+		}},
+		// A 45 minute gap after 09:50 starts a new session
+		{"session", []models.HistoryGroup{
+			{Key: "2025-03-01T10:35:00Z", Visits: 1, UniqueURLs: 1},
+			{Key: "2025-03-01T09:00:00Z", Visits: 4, UniqueURLs: 4},
+		}},
+	}
+	for _, tt := range tests {
+		grouped := NewGroupedHistoryReport(report, tt.by, opts)
+		if grouped.GroupBy != tt.by || grouped.TotalEntries != 5 || grouped.TotalGroups != len(tt.want) {
+			t.Fatalf("%s: report = %+v", tt.by, grouped)
+		}
+		for i, want := range tt.want {
+			g := grouped.Groups[i]
+			if g.Key != want.Key || g.Visits != want.Visits || g.UniqueURLs != want.UniqueURLs || len(g.Entries) != g.Visits {
+				t.Errorf("%s: group %d = %s with %d visits, %d URLs; want %s with %d, %d", tt.by, i, g.Key, g.Visits, g.UniqueURLs, want.Key, want.Visits, want.UniqueURLs)
+			}
+		}
+	}
+
+	// Domains roll up into the group opts.Domain names
+	opts.Domain = func(domain string) string { return "all" }
+	if g := NewGroupedHistoryReport(report, "domain", opts).Groups; len(g) != 1 || g[0].Key != "all" || g[0].Entries[0].Domain != "news.example" {
+		t.Errorf("rolled up groups = %+v, want one group keeping the entries' domains", g)
+	}
+	opts.Domain = nil
+
+	// Sites group hosts by registrable domain, falling back to the domain
+	sites := models.HistoryReport{Entries: []models.HistoryEntry{
+		{URL: "https://news.bbc.co.uk/", Domain: "news.bbc.co.uk", RegistrableDomain: "bbc.co.uk"},
+		{URL: "http://localhost:8080/", Domain: "localhost:8080"},
+		{URL: "https://www.bbc.co.uk/", Domain: "www.bbc.co.uk", RegistrableDomain: "bbc.co.uk"},
+	}}
+	if g := NewGroupedHistoryReport(sites, "site", opts).Groups; len(g) != 2 || g[0].Key != "bbc.co.uk" || g[0].Visits != 2 || g[1].Key != "localhost:8080" {
+		t.Errorf("site groups = %+v, want bbc.co.uk with 2 visits, then localhost:8080", g)
+	}
+
+	// Entries keep their order, and the group spans its visits
+	g := NewGroupedHistoryReport(report, "domain", opts).Groups[0]
+	if g.Entries[0].URL != "https://go.dev/doc/" || !g.FirstVisit.Equal(base.Add(10*time.Minute)) || !g.LastVisit.Equal(base.Add(50*time.Minute)) {
+		t.Errorf("go.dev group = %+v", g)
+	}
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// SortJourneys orders journeys from several browsers newest first, by their
+// last visit, with ties broken by browser and ID
+func SortJourneys(journeys []models.Journey) {
+	sort.SliceStable(journeys, func(i, j int) bool {
+		a, b := journeys[i], journeys[j]
+		if !a.EndTime.Equal(b.EndTime) {
+			return a.EndTime.After(b.EndTime)
+		}
+		if a.Browser != b.Browser {
+			return a.Browser < b.Browser
+		}
+		return a.ID < b.ID
+	})
+}
+
+// FormatJourneysJSON writes a Journeys report as JSON to the given writer
+func FormatJourneysJSON(w io.Writer, report models.JourneyReport) error {
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Journeys == nil {
+		report.Journeys = []models.Journey{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
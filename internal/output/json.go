@@ -2,6 +2,7 @@ package output
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"time"
 
@@ -10,11 +11,16 @@ import (
 
 // FormatJSON writes history report as JSON to the given writer
 func FormatJSON(w io.Writer, entries []models.HistoryEntry, browser string, startDate, endDate time.Time, tz string) error {
+	return FormatHistoryReportJSON(w, NewHistoryReport(entries, browser, startDate, endDate, tz))
+}
+
+// NewHistoryReport builds the history report that FormatJSON writes
+func NewHistoryReport(entries []models.HistoryEntry, browser string, startDate, endDate time.Time, tz string) models.HistoryReport {
 	if tz == "" {
 		tz = "UTC"
 	}
 
-	report := models.HistoryReport{
+	return models.HistoryReport{
 		Browser:      browser,
 		StartDate:    startDate,
 		EndDate:      endDate,
@@ -22,7 +28,10 @@ func FormatJSON(w io.Writer, entries []models.HistoryEntry, browser string, star
 		TotalEntries: len(entries),
 		Entries:      entries,
 	}
+}
 
+// FormatHistoryReportJSON writes an already-built history report as JSON to the given writer
+func FormatHistoryReportJSON(w io.Writer, report models.HistoryReport) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	encoder.SetEscapeHTML(false)
@@ -54,7 +63,14 @@ func FormatJSONLines(w io.Writer, entries []models.HistoryEntry) error {
 }
 
 // FormatBookmarksJSON writes bookmark report as JSON to the given writer
-func FormatBookmarksJSON(w io.Writer, entries []models.BookmarkEntry, browser string, startDate, endDate time.Time, tz string) error {
+func FormatBookmarksJSON(w io.Writer, entries []models.BookmarkEntry, browser string, startDate, endDate time.Time, tz, source string) error {
+	report := NewBookmarkReport(entries, browser, startDate, endDate, tz)
+	report.Source = source
+	return FormatBookmarkReportJSON(w, report)
+}
+
+// NewBookmarkReport builds a bookmark report; zero dates are omitted
+func NewBookmarkReport(entries []models.BookmarkEntry, browser string, startDate, endDate time.Time, tz string) models.BookmarkReport {
 	var startPtr, endPtr *time.Time
 	if tz == "" {
 		tz = "UTC"
@@ -68,7 +84,7 @@ func FormatBookmarksJSON(w io.Writer, entries []models.BookmarkEntry, browser st
 		endPtr = &endDate
 	}
 
-	report := models.BookmarkReport{
+	return models.BookmarkReport{
 		Browser:      browser,
 		StartDate:    startPtr,
 		EndDate:      endPtr,
@@ -76,7 +92,10 @@ func FormatBookmarksJSON(w io.Writer, entries []models.BookmarkEntry, browser st
 		TotalEntries: len(entries),
 		Entries:      entries,
 	}
+}
 
+// FormatBookmarkReportJSON writes a prepared bookmark report as JSON
+func FormatBookmarkReportJSON(w io.Writer, report models.BookmarkReport) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	encoder.SetEscapeHTML(false)
@@ -84,6 +103,26 @@ func FormatBookmarksJSON(w io.Writer, entries []models.BookmarkEntry, browser st
 	return encoder.Encode(report)
 }
 
+// SourceWarnings turns the sources that failed into report warnings
+func SourceWarnings(sources []models.SourceStatus) []string {
+	var warnings []string
+	for _, s := range sources {
+		if s.Error != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", s.Browser, s.Error))
+		}
+	}
+	return warnings
+}
+
+// SessionWarnings describes session file commands skipped while reading
+// open tabs
+func SessionWarnings(skipped int) []string {
+	if skipped == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("skipped %d unreadable session file commands; some tabs may be missing or out of date", skipped)}
+}
+
 // FormatBookmarksJSONCompact writes bookmark report as compact JSON to the given writer
 func FormatBookmarksJSONCompact(w io.Writer, entries []models.BookmarkEntry, browser string, startDate, endDate time.Time) error {
 	var startPtr, endPtr *time.Time
@@ -124,21 +163,32 @@ func FormatBookmarksJSONLines(w io.Writer, entries []models.BookmarkEntry) error
 	return nil
 }
 
-// FormatTabsJSON writes tab report as JSON to the given writer
-func FormatTabsJSON(w io.Writer, entries []models.TabEntry, browser string) error {
-	// Count unique windows
+// NewTabReport creates a tab report with window and tab counts
+func NewTabReport(entries []models.TabEntry, browser, source string) models.TabReport {
+	// Count unique windows; tabs from other devices are not in one
 	windowSet := make(map[int]bool)
 	for _, e := range entries {
-		windowSet[e.WindowID] = true
+		if e.Device == "" {
+			windowSet[e.WindowID] = true
+		}
 	}
 
-	report := models.TabReport{
+	return models.TabReport{
 		Browser:      browser,
+		Source:       source,
 		TotalTabs:    len(entries),
 		TotalWindows: len(windowSet),
 		Entries:      entries,
 	}
+}
+
+// FormatTabsJSON writes tab report as JSON to the given writer
+func FormatTabsJSON(w io.Writer, entries []models.TabEntry, browser, source string) error {
+	return FormatTabReportJSON(w, NewTabReport(entries, browser, source))
+}
 
+// FormatTabReportJSON writes a prepared tab report as JSON
+func FormatTabReportJSON(w io.Writer, report models.TabReport) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	encoder.SetEscapeHTML(false)
@@ -202,3 +252,166 @@ func FormatTwitterBookmarksJSON(w io.Writer, report models.TwitterBookmarksRepor
 
 	return encoder.Encode(report)
 }
+
+// FormatStreaksJSON writes a domain streak report as JSON to the given writer
+func FormatStreaksJSON(w io.Writer, streaks []models.DomainStreak, browser string, startDate, endDate time.Time, totalDays int, tz string) error {
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	report := models.StreakReport{
+		Browser:      browser,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Timezone:     tz,
+		TotalDays:    totalDays,
+		TotalDomains: len(streaks),
+		Domains:      streaks,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatSiteTimeJSON writes a time-on-site report as JSON to the given writer
+func FormatSiteTimeJSON(w io.Writer, sites []models.SiteTime, browser string, startDate, endDate time.Time, idleGap time.Duration, tz string) error {
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	report := models.SiteTimeReport{
+		Browser:      browser,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Timezone:     tz,
+		IdleGap:      idleGap.String(),
+		TotalDomains: len(sites),
+		Sites:        sites,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatCapabilitiesJSON writes a capabilities report as JSON to the given writer
+func FormatCapabilitiesJSON(w io.Writer, report models.CapabilitiesReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatSearchJSON writes archive search results as JSON to the given writer
+func FormatSearchJSON(w io.Writer, query string, results []models.SearchResult) error {
+	report := models.SearchReport{
+		Query:        query,
+		TotalResults: len(results),
+		Results:      results,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatBookmarkFoldersJSON writes a bookmark folder listing as JSON
+func FormatBookmarkFoldersJSON(w io.Writer, report models.BookmarkFoldersReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatExportDiffJSON writes the comparison of two saved reports as JSON
+func FormatExportDiffJSON(w io.Writer, diff models.ExportDiff) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(diff)
+}
+
+// FormatDryRunJSON writes the plan of a --dry-run as JSON
+func FormatDryRunJSON(w io.Writer, plan models.DryRunPlan) error {
+	if plan.Browsers == nil {
+		plan.Browsers = []models.DryRunBrowser{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(plan)
+}
+
+// FormatDailyActivityJSON writes a daily activity report as JSON
+func FormatDailyActivityJSON(w io.Writer, report models.DailyActivityReport) error {
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Days == nil {
+		report.Days = []models.DailyActivity{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatDomainPairsJSON writes a domain co-occurrence report as JSON
+func FormatDomainPairsJSON(w io.Writer, report models.DomainPairReport) error {
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Pairs == nil {
+		report.Pairs = []models.DomainPair{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatReadingJSON writes a reading time report as JSON
+func FormatReadingJSON(w io.Writer, report models.ReadingReport) error {
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Articles == nil {
+		report.Articles = []models.ArticleReading{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatBudgetsJSON writes a time budget report as JSON
+func FormatBudgetsJSON(w io.Writer, report models.BudgetReport) error {
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Overruns == nil {
+		report.Overruns = []models.BudgetOverrun{}
+	}
+	if report.Days == nil {
+		report.Days = []models.BudgetDay{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
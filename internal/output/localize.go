@@ -0,0 +1,51 @@
+package output
+
+import (
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// LocalizeHistoryReport writes the report's dates and entry timestamps with
+// loc's UTC offset instead of UTC. The instants are unchanged, and so is a
+// report with a nil loc. Entries are updated in place.
+func LocalizeHistoryReport(report *models.HistoryReport, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	report.StartDate = inLocation(report.StartDate, loc)
+	report.EndDate = inLocation(report.EndDate, loc)
+	for i := range report.Entries {
+		report.Entries[i].Timestamp = inLocation(report.Entries[i].Timestamp, loc)
+	}
+	for i := range report.CollapsedDomains {
+		d := &report.CollapsedDomains[i]
+		d.FirstVisit = inLocation(d.FirstVisit, loc)
+		d.LastVisit = inLocation(d.LastVisit, loc)
+	}
+}
+
+// LocalizeBookmarkReport is LocalizeHistoryReport for bookmark reports
+func LocalizeBookmarkReport(report *models.BookmarkReport, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	for _, t := range []*time.Time{report.StartDate, report.EndDate} {
+		if t != nil {
+			*t = inLocation(*t, loc)
+		}
+	}
+	for i := range report.Entries {
+		e := &report.Entries[i]
+		e.DateAdded = inLocation(e.DateAdded, loc)
+		e.DateModified = inLocation(e.DateModified, loc)
+	}
+}
+
+// inLocation returns t in loc, leaving zero times zero
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestLocalizeHistoryReport(t *testing.T) {
+	visit := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+	report := NewHistoryReport([]models.HistoryEntry{{Timestamp: visit, URL: "https://go.dev/"}},
+		"chrome", visit.Truncate(24*time.Hour), time.Time{}, "America/New_York")
+	report.CollapsedDomains = []models.CollapsedDomain{{Domain: "go.dev", FirstVisit: visit, LastVisit: visit}}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no timezone database:", err)
+	}
+	LocalizeHistoryReport(&report, ny)
+
+	got := report.Entries[0].Timestamp
+	if !got.Equal(visit) || got.Format(time.RFC3339) != "2025-12-15T09:30:00-05:00" {
+		t.Errorf("timestamp = %s, want the same instant at -05:00", got.Format(time.RFC3339))
+	}
+	if report.StartDate.Location() != ny || report.CollapsedDomains[0].LastVisit.Location() != ny {
+		t.Error("report dates not localized")
+	}
+	if !report.EndDate.IsZero() {
+		t.Errorf("zero end date became %s", report.EndDate)
+	}
+
+	// A nil location keeps UTC
+	report = NewHistoryReport([]models.HistoryEntry{{Timestamp: visit}}, "chrome", visit, visit, "")
+	LocalizeHistoryReport(&report, nil)
+	if report.Entries[0].Timestamp.Location() != time.UTC {
+		t.Error("nil location changed the timestamps")
+	}
+}
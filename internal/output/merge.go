@@ -0,0 +1,69 @@
+package output
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// MergeEntries collapses visits to the same URL in the same minute, from any
+// browser, into one entry whose Browsers lists where the visit was seen. The
+// merged entry keeps the earliest timestamp with its browser and ID, the first
+// non-empty title, and the sum of each browser's visit count. Entries are
+// returned newest first, with ties broken by URL so the order is strict.
+func MergeEntries(entries []models.HistoryEntry) []models.HistoryEntry {
+	type key struct {
+		url    string
+		minute int64
+	}
+	type group struct {
+		entry  models.HistoryEntry
+		counts map[string]int // highest visit count per browser
+	}
+
+	groups := make(map[key]*group)
+	var order []key
+	for _, e := range entries {
+		k := key{e.URL, e.Timestamp.Truncate(time.Minute).Unix()}
+		g := groups[k]
+		if g == nil {
+			g = &group{entry: e, counts: make(map[string]int)}
+			groups[k] = g
+			order = append(order, k)
+		} else {
+			if e.Timestamp.Before(g.entry.Timestamp) {
+				g.entry.ID = e.ID
+				g.entry.Timestamp = e.Timestamp
+				g.entry.Browser = e.Browser
+			}
+			if g.entry.Title == "" {
+				g.entry.Title = e.Title
+			}
+		}
+		g.counts[e.Browser] = max(g.counts[e.Browser], e.VisitCount)
+	}
+
+	result := make([]models.HistoryEntry, 0, len(order))
+	for _, k := range order {
+		g := groups[k]
+		e := g.entry
+		e.VisitCount = 0
+		e.Browsers = make([]string, 0, len(g.counts))
+		for name, count := range g.counts {
+			e.VisitCount += count
+			e.Browsers = append(e.Browsers, name)
+		}
+		sort.Strings(e.Browsers)
+		result = append(result, e)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.After(b.Timestamp)
+		}
+		return a.URL < b.URL
+	})
+	return result
+}
@@ -0,0 +1,37 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestMergeEntries(t *testing.T) {
+	ts := time.Date(2025, 12, 15, 9, 30, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: ts.Add(40 * time.Second), URL: "https://go.dev/", Title: "Go", VisitCount: 5, Browser: "chrome"},
+		{Timestamp: ts.Add(50 * time.Second), URL: "https://go.dev/", Title: "Go", VisitCount: 5, Browser: "chrome"},
+		{Timestamp: ts.Add(10 * time.Second), URL: "https://go.dev/", VisitCount: 2, Browser: "firefox"},
+		{Timestamp: ts.Add(time.Minute), URL: "https://go.dev/", Title: "Go", VisitCount: 2, Browser: "firefox"},
+		{Timestamp: ts.Add(10 * time.Second), URL: "https://a.example/", Title: "A", VisitCount: 1, Browser: "safari"},
+	}
+
+	got := MergeEntries(entries)
+	var lines []string
+	for _, e := range got {
+		lines = append(lines, e.Timestamp.Format("15:04:05")+" "+e.URL+" "+e.Title+" "+e.Browser+" "+strings.Join(e.Browsers, ",")+" "+string(rune('0'+e.VisitCount)))
+	}
+	want := []string{
+		"09:31:00 https://go.dev/ Go firefox firefox 2",
+		"09:30:10 https://a.example/ A safari safari 1",
+		"09:30:10 https://go.dev/ Go firefox chrome,firefox 7",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("merged:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if entries[2].Title != "" || entries[0].Browsers != nil {
+		t.Error("input entries were modified")
+	}
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// pinboardPost is one bookmark in Pinboard's JSON export format, which
+// Pinboard and most bookmark managers import
+type pinboardPost struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Hash        string `json:"hash"`
+	Time        string `json:"time,omitempty"`
+	Shared      string `json:"shared"`
+	ToRead      string `json:"toread"`
+	Tags        string `json:"tags"`
+}
+
+// FormatBookmarksPinboardJSON writes bookmarks in Pinboard's JSON export
+// format. Folders become tags, and bookmarks are private.
+func FormatBookmarksPinboardJSON(w io.Writer, entries []models.BookmarkEntry) error {
+	posts := make([]pinboardPost, len(entries))
+	for i, e := range entries {
+		sum := md5.Sum([]byte(e.URL))
+		posts[i] = pinboardPost{
+			Href:        e.URL,
+			Description: e.Title,
+			Hash:        hex.EncodeToString(sum[:]),
+			Shared:      "no",
+			ToRead:      "no",
+			Tags:        strings.Join(e.FolderTags(), " "),
+		}
+		if !e.DateAdded.IsZero() {
+			posts[i].Time = e.DateAdded.UTC().Format("2006-01-02T15:04:05Z")
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(posts)
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// HistoryStream writes history entries as they are read, so an export
+// never holds every entry in memory
+type HistoryStream interface {
+	WriteEntry(entry models.HistoryEntry) error
+	// Close finishes the output. The report carries what is only known at
+	// the end (entry count, sources, warnings); its Entries are ignored.
+	Close(report models.HistoryReport) error
+}
+
+// IsStreamFormat reports whether format is written by NewHistoryStream
+func IsStreamFormat(format string) bool {
+	switch format {
+	case "jsonl", "csv", "compact":
+		return true
+	}
+	return false
+}
+
+// NewHistoryStream starts a streamed history export: "jsonl" (one entry per
+// line), "csv" (with a header row), or "compact" (the JSON report on one line,
+// with total_entries, meta, sources, and warnings after the entries). header
+// supplies the report fields written before the entries.
+func NewHistoryStream(w io.Writer, format string, header models.HistoryReport) (HistoryStream, error) {
+	switch format {
+	case "jsonl":
+		return &jsonLinesStream{enc: newCompactEncoder(w)}, nil
+	case "csv":
+		s := &csvStream{w: csv.NewWriter(w)}
+		return s, s.w.Write(csvHeader)
+	case "compact":
+		return newCompactStream(w, header)
+	default:
+		return nil, fmt.Errorf("unsupported stream format: %s", format)
+	}
+}
+
+func newCompactEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+type jsonLinesStream struct {
+	enc *json.Encoder
+}
+
+func (s *jsonLinesStream) WriteEntry(entry models.HistoryEntry) error {
+	return s.enc.Encode(entry)
+}
+
+func (s *jsonLinesStream) Close(models.HistoryReport) error {
+	return nil
+}
+
+// csvHeader adds new columns at the end, so the earlier ones keep their
+// positions
+var csvHeader = []string{"timestamp", "url", "title", "visit_count", "domain", "browser", "source", "id", "host", "registrable_domain"}
+
+type csvStream struct {
+	w *csv.Writer
+}
+
+func (s *csvStream) WriteEntry(e models.HistoryEntry) error {
+	return s.w.Write([]string{
+		e.Timestamp.Format(time.RFC3339Nano),
+		e.URL,
+		e.Title,
+		strconv.Itoa(e.VisitCount),
+		e.Domain,
+		e.Browser,
+		e.Source,
+		e.ID,
+		e.Host,
+		e.RegistrableDomain,
+	})
+}
+
+func (s *csvStream) Close(models.HistoryReport) error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// compactStream writes the report object by hand around the entries, since
+// the entry count and sources are not known until the end
+type compactStream struct {
+	w       io.Writer
+	buf     bytes.Buffer
+	enc     *json.Encoder
+	written int
+}
+
+// compactHeader is the part of the report written before the entries
+type compactHeader struct {
+	Browser   string    `json:"browser"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Timezone  string    `json:"timezone"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// compactTrailer is the part of the report written after the entries
+type compactTrailer struct {
+	TotalEntries int                   `json:"total_entries"`
+	Meta         *models.ReportMeta    `json:"meta,omitempty"`
+	Sources      []models.SourceStatus `json:"sources,omitempty"`
+	Warnings     []string              `json:"warnings,omitempty"`
+}
+
+func newCompactStream(w io.Writer, r models.HistoryReport) (*compactStream, error) {
+	s := &compactStream{w: w}
+	s.enc = newCompactEncoder(&s.buf)
+
+	head, err := s.marshal(compactHeader{r.Browser, r.StartDate, r.EndDate, r.Timezone, r.Source})
+	if err != nil {
+		return nil, err
+	}
+	// Reopen the object to continue with the entries
+	head = append(head[:len(head)-1], `,"entries":[`...)
+	_, err = w.Write(head)
+	return s, err
+}
+
+// marshal encodes v without HTML escaping or a trailing newline
+func (s *compactStream) marshal(v any) ([]byte, error) {
+	s.buf.Reset()
+	if err := s.enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(s.buf.Bytes(), []byte("\n")), nil
+}
+
+func (s *compactStream) WriteEntry(entry models.HistoryEntry) error {
+	data, err := s.marshal(entry)
+	if err != nil {
+		return err
+	}
+	if s.written > 0 {
+		data = append([]byte{','}, data...)
+	}
+	s.written++
+	_, err = s.w.Write(data)
+	return err
+}
+
+func (s *compactStream) Close(r models.HistoryReport) error {
+	tail, err := s.marshal(compactTrailer{r.TotalEntries, r.Meta, r.Sources, r.Warnings})
+	if err != nil {
+		return err
+	}
+	// Splice the trailer fields in after the entries array
+	_, err = fmt.Fprintf(s.w, "],%s\n", tail[1:])
+	return err
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func streamEntries() []models.HistoryEntry {
+	ts := time.Date(2025, 12, 15, 9, 30, 0, 0, time.UTC)
+	return []models.HistoryEntry{
+		{ID: "e1", Timestamp: ts, URL: "https://example.com/?a=1&b=<2>", Title: `Say "hi", world`, VisitCount: 2, Domain: "example.com", Browser: "chrome", Host: "example.com", RegistrableDomain: "example.com"},
+		{Timestamp: ts.Add(-time.Minute), URL: "https://go.dev/", Title: "Go", VisitCount: 1, Domain: "go.dev", Browser: "firefox", Source: "laptop", Host: "blog.go.dev", RegistrableDomain: "go.dev"},
+	}
+}
+
+func writeStream(t *testing.T, format string, entries []models.HistoryEntry) string {
+	t.Helper()
+	header := NewHistoryReport(nil, "all", time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 16, 0, 0, 0, 0, time.UTC), "")
+	var buf bytes.Buffer
+	s, err := NewHistoryStream(&buf, format, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if err := s.WriteEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header.TotalEntries = len(entries)
+	header.Meta = &models.ReportMeta{Version: "1.0.0", Query: models.ReportQuery{Command: "web-recap", Browsers: []string{"chrome", "safari"}}}
+	header.Sources = []models.SourceStatus{{Browser: "chrome", Entries: 1}, {Browser: "safari", Error: "denied"}}
+	header.Warnings = SourceWarnings(header.Sources)
+	if err := s.Close(header); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCompactStreamMatchesReport(t *testing.T) {
+	for _, entries := range [][]models.HistoryEntry{streamEntries(), nil} {
+		out := writeStream(t, "compact", entries)
+		if strings.Count(out, "\n") != 1 {
+			t.Errorf("compact output should be one line: %q", out)
+		}
+
+		var report models.HistoryReport
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("invalid JSON %q: %v", out, err)
+		}
+		if report.Browser != "all" || report.TotalEntries != len(entries) || len(report.Entries) != len(entries) {
+			t.Errorf("report = %+v", report)
+		}
+		if len(report.Sources) != 2 || len(report.Warnings) != 1 {
+			t.Errorf("sources = %+v, warnings = %v", report.Sources, report.Warnings)
+		}
+		if report.Meta == nil || report.Meta.Version != "1.0.0" || len(report.Meta.Query.Browsers) != 2 {
+			t.Errorf("meta = %+v", report.Meta)
+		}
+		if len(entries) > 0 && report.Entries[0].URL != entries[0].URL {
+			t.Errorf("entry URL = %q", report.Entries[0].URL)
+		}
+	}
+}
+
+func TestJSONLinesAndCSVStreams(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeStream(t, "jsonl", streamEntries())), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "a=1&b=<2>") {
+		t.Errorf("jsonl = %q", lines)
+	}
+
+	want := `timestamp,url,title,visit_count,domain,browser,source,id,host,registrable_domain
+2025-12-15T09:30:00Z,https://example.com/?a=1&b=<2>,"Say ""hi"", world",2,example.com,chrome,,e1,example.com,example.com
+2025-12-15T09:29:00Z,https://go.dev/,Go,1,go.dev,firefox,laptop,,blog.go.dev,go.dev
+`
+	if got := writeStream(t, "csv", streamEntries()); got != want {
+		t.Errorf("csv =\n%s\nwant\n%s", got, want)
+	}
+}
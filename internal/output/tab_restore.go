@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// restoreWindow is one window of the restore page, its tabs split into runs
+// of the same tab group
+type restoreWindow struct {
+	Name     string
+	Active   bool
+	Sections []restoreSection
+}
+
+type restoreSection struct {
+	Group string
+	Tabs  []restoreTab
+}
+
+type restoreTab struct {
+	URL    string
+	Title  string
+	Active bool
+}
+
+var tabRestoreHTMLTemplate = template.Must(template.New("restore").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Open tabs ({{.TotalTabs}})</title>
+</head>
+<body style="font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; max-width: 720px; margin: 0 auto; color: #222;">
+<h1 style="font-size: 22px;">Open tabs</h1>
+<p>
+  <strong>{{.TotalTabs}}</strong> tabs ·
+  <strong>{{len .Windows}}</strong> windows ·
+  browser: {{.Browser}}
+</p>
+{{range .Windows}}<section>
+<h2 style="font-size: 18px;">{{.Name}}{{if .Active}} (focused){{end}}
+  <button onclick="for (const a of this.closest('section').querySelectorAll('a')) window.open(a.href)">Open all</button></h2>
+{{range .Sections}}{{if .Group}}<h3 style="font-size: 15px;">{{.Group}}</h3>
+{{end}}<ul>
+{{range .Tabs}}<li><a href="{{.URL}}">{{.Title}}</a>{{if .Active}} <em>(shown)</em>{{end}}</li>
+{{end}}</ul>
+{{end}}</section>
+{{end}}</body>
+</html>
+`))
+
+// FormatTabRestoreHTML writes the tabs as a standalone HTML page of links,
+// one section per window with its tab groups, so a session can be reopened
+// in any browser. Each window has a button that opens all of its tabs.
+func FormatTabRestoreHTML(w io.Writer, report models.TabWindowReport) error {
+	windows := make([]restoreWindow, 0, len(report.Windows))
+	for i, win := range report.Windows {
+		rw := restoreWindow{Active: win.Active}
+		switch {
+		case win.Device != "":
+			rw.Name = fmt.Sprintf("%s on %s", win.Browser, win.Device)
+		default:
+			rw.Name = fmt.Sprintf("%s window %d", win.Browser, i+1)
+		}
+		for j, t := range win.Tabs {
+			if len(rw.Sections) == 0 || rw.Sections[len(rw.Sections)-1].Group != t.Group {
+				rw.Sections = append(rw.Sections, restoreSection{Group: t.Group})
+			}
+			title := t.Title
+			if title == "" {
+				title = t.URL
+			}
+			s := &rw.Sections[len(rw.Sections)-1]
+			s.Tabs = append(s.Tabs, restoreTab{URL: t.URL, Title: title, Active: j == win.ActiveTab})
+		}
+		windows = append(windows, rw)
+	}
+
+	return tabRestoreHTMLTemplate.Execute(w, struct {
+		Browser   string
+		TotalTabs int
+		Windows   []restoreWindow
+	}{report.Browser, report.TotalTabs, windows})
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestFormatTabRestoreHTML(t *testing.T) {
+	report := NewTabWindowReport(NewTabReport([]models.TabEntry{
+		{URL: "https://go.dev/", Title: "Go", Browser: "Chrome", WindowID: 1},
+		{URL: "https://pkg.go.dev/", Title: "Packages", Group: "Research", Browser: "Chrome", WindowID: 1, Selected: true},
+		{URL: "https://example.com/?a=1&b=<2>", Group: "Research", Browser: "Chrome", WindowID: 1},
+		{URL: "https://news.example/", Title: "<script>alert(1)</script>", Browser: "Chrome", WindowID: 2},
+		{URL: "https://phone.example/", Title: "Phone", Browser: "Safari", Device: "iPhone"},
+	}, "all", ""))
+
+	var b strings.Builder
+	if err := FormatTabRestoreHTML(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+
+	for _, want := range []string{
+		"<title>Open tabs (5)</title>",
+		"Chrome window 1",
+		"Chrome window 2",
+		"Safari on iPhone",
+		`<h3 style="font-size: 15px;">Research</h3>`,
+		`<a href="https://pkg.go.dev/">Packages</a> <em>(shown)</em>`,
+		`href="https://example.com/?a=1&amp;b=%3c2%3e"`,
+		"&lt;script&gt;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("restore page is missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Count(html, "<h3") != 1 {
+		t.Errorf("want one tab group heading:\n%s", html)
+	}
+}
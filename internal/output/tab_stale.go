@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// StaleTabs returns the tabs last focused before cutoff, least recently
+// focused first, and the number of tabs left out because when they were
+// last focused is not known. Tabs without a last active time fall back to
+// firstSeen, the time 'watch --tabs' first saw them open, which they have
+// not been focused since as far as web-recap knows.
+func StaleTabs(entries []models.TabEntry, cutoff time.Time, firstSeen func(browser, url string) (time.Time, bool)) ([]models.TabEntry, int) {
+	type staleTab struct {
+		entry models.TabEntry
+		since time.Time
+	}
+	var stale []staleTab
+	unknown := 0
+	for _, e := range entries {
+		var since time.Time
+		if e.LastActive != nil {
+			since = *e.LastActive
+		} else if t, ok := firstSeen(e.Browser, e.URL); ok {
+			since = t
+		} else {
+			unknown++
+			continue
+		}
+		if since.Before(cutoff) {
+			stale = append(stale, staleTab{e, since})
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].since.Before(stale[j].since)
+	})
+
+	kept := make([]models.TabEntry, len(stale))
+	for i, s := range stale {
+		kept[i] = s.entry
+	}
+	return kept, unknown
+}
+
+// FormatTabURLs writes the URL of each tab on its own line, e.g. to save
+// before closing them or to open them again later
+func FormatTabURLs(w io.Writer, entries []models.TabEntry) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range entries {
+		bw.WriteString(e.URL)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestStaleTabs(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	entries := []models.TabEntry{
+		{URL: "https://recent.example/", Browser: "Chrome", LastActive: at(1)},
+		{URL: "https://old.example/", Browser: "Chrome", LastActive: at(10)},
+		{URL: "https://older.example/", Browser: "Chrome", LastActive: at(30)},
+		{URL: "https://watched.example/", Browser: "Chrome"},
+		{URL: "https://unknown.example/", Browser: "Chrome"},
+	}
+	firstSeen := func(browser, url string) (time.Time, bool) {
+		if url == "https://watched.example/" {
+			return *at(14), true
+		}
+		return time.Time{}, false
+	}
+
+	stale, unknown := StaleTabs(entries, now.AddDate(0, 0, -7), firstSeen)
+	var urls []string
+	for _, e := range stale {
+		urls = append(urls, e.URL)
+	}
+	want := "https://older.example/ https://watched.example/ https://old.example/"
+	if got := strings.Join(urls, " "); got != want {
+		t.Errorf("StaleTabs = %s, want %s", got, want)
+	}
+	if unknown != 1 {
+		t.Errorf("unknown = %d, want 1", unknown)
+	}
+
+	var b strings.Builder
+	if err := FormatTabURLs(&b, stale); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != strings.ReplaceAll(want, " ", "\n")+"\n" {
+		t.Errorf("FormatTabURLs = %q", got)
+	}
+}
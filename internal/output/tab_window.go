@@ -0,0 +1,79 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// NewTabWindowReport nests the report's tabs in their windows. Windows keep
+// the order they were first seen in, and each device's iCloud tabs form one
+// window.
+func NewTabWindowReport(report models.TabReport) models.TabWindowReport {
+	grouped := models.TabWindowReport{
+		Browser:         report.Browser,
+		Meta:            report.Meta,
+		Source:          report.Source,
+		TotalTabs:       report.TotalTabs,
+		TotalWindows:    report.TotalWindows,
+		SkippedCommands: report.SkippedCommands,
+		StaleBefore:     report.StaleBefore,
+		Warnings:        report.Warnings,
+		Windows:         []models.TabWindow{},
+	}
+
+	type windowKey struct {
+		browser, device string
+		id              int
+	}
+	index := make(map[windowKey]int)
+	for _, e := range report.Entries {
+		key := windowKey{browser: e.Browser, device: e.Device}
+		if e.Device == "" {
+			key.id = e.WindowID
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(grouped.Windows)
+			index[key] = i
+			grouped.Windows = append(grouped.Windows, models.TabWindow{
+				Browser:   key.browser,
+				WindowID:  key.id,
+				Device:    key.device,
+				ActiveTab: -1,
+			})
+		}
+
+		w := &grouped.Windows[i]
+		if e.Selected || e.Active {
+			w.ActiveTab = len(w.Tabs)
+		}
+		if e.Active {
+			w.Active = true
+		}
+		if e.Group != "" && !containsString(w.Groups, e.Group) {
+			w.Groups = append(w.Groups, e.Group)
+		}
+		w.Tabs = append(w.Tabs, e)
+	}
+	return grouped
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatTabWindowReportJSON writes a tab report grouped by window as JSON
+func FormatTabWindowReportJSON(w io.Writer, report models.TabWindowReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
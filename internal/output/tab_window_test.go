@@ -0,0 +1,43 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestNewTabWindowReport(t *testing.T) {
+	entries := []models.TabEntry{
+		{URL: "https://a.example/", Browser: "Chrome", WindowID: 1, Group: "Work"},
+		{URL: "https://b.example/", Browser: "Chrome", WindowID: 1, Selected: true},
+		{URL: "https://c.example/", Browser: "Chrome", WindowID: 2, Group: "Trip", Active: true, Selected: true},
+		{URL: "https://d.example/", Browser: "Edge", WindowID: 1},
+		{URL: "https://e.example/", Browser: "Safari", Device: "iPhone"},
+		{URL: "https://f.example/", Browser: "Chrome", WindowID: 1, Group: "Work"},
+	}
+	report := NewTabWindowReport(NewTabReport(entries, "all", ""))
+
+	if len(report.Windows) != 4 {
+		t.Fatalf("windows = %d, want 4", len(report.Windows))
+	}
+	first := report.Windows[0]
+	if first.Browser != "Chrome" || first.WindowID != 1 || len(first.Tabs) != 3 {
+		t.Errorf("first window = %+v", first)
+	}
+	if first.Active || first.ActiveTab != 1 || !reflect.DeepEqual(first.Groups, []string{"Work"}) {
+		t.Errorf("first window active = %v, active tab = %d, groups = %v", first.Active, first.ActiveTab, first.Groups)
+	}
+	if w := report.Windows[1]; !w.Active || w.ActiveTab != 0 {
+		t.Errorf("focused window = %+v", w)
+	}
+	if w := report.Windows[2]; w.Browser != "Edge" || w.ActiveTab != -1 {
+		t.Errorf("Edge window = %+v, want its own window with no known active tab", w)
+	}
+	if w := report.Windows[3]; w.Device != "iPhone" || w.WindowID != 0 {
+		t.Errorf("iCloud window = %+v", w)
+	}
+	if report.TotalTabs != 6 {
+		t.Errorf("total tabs = %d, want 6", report.TotalTabs)
+	}
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"sort"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// GroupByTask orders entries so visits sharing a Chromium root task, those
+// opened from the same originating tab, sit together. Groups are ordered by
+// their newest visit, newest first, and visits within a group keep their
+// order. A visit without a task is a group of its own. Task IDs are only
+// unique within one browser, so browsers are told apart by type.
+func GroupByTask(entries []models.HistoryEntry) []models.HistoryEntry {
+	type key struct {
+		browser string
+		task    int64
+	}
+	type group struct {
+		newest  models.HistoryEntry
+		entries []models.HistoryEntry
+	}
+	index := make(map[key]int)
+	var groups []*group
+	for _, e := range entries {
+		task := taskOf(e)
+		k := key{e.BrowserType, task}
+		if k.browser == "" {
+			k.browser = e.Browser
+		}
+		i, ok := index[k]
+		if !ok || task == 0 {
+			i = len(groups)
+			groups = append(groups, &group{newest: e})
+			if task != 0 {
+				index[k] = i
+			}
+		}
+		g := groups[i]
+		g.entries = append(g.entries, e)
+		if e.Timestamp.After(g.newest.Timestamp) {
+			g.newest = e
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].newest.Timestamp.After(groups[j].newest.Timestamp)
+	})
+	result := make([]models.HistoryEntry, 0, len(entries))
+	for _, g := range groups {
+		result = append(result, g.entries...)
+	}
+	return result
+}
+
+// taskOf returns the task a visit is grouped under: its root task, or its
+// own task when the root was not recorded
+func taskOf(e models.HistoryEntry) int64 {
+	if e.Context == nil {
+		return 0
+	}
+	if e.Context.RootTaskID != 0 {
+		return e.Context.RootTaskID
+	}
+	return e.Context.TaskID
+}
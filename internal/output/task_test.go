@@ -0,0 +1,48 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestGroupByTask(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	visit := func(url string, minute int, browserType string, root, task int64) models.HistoryEntry {
+		e := models.HistoryEntry{URL: url, Browser: "chrome", BrowserType: browserType, Timestamp: base.Add(time.Duration(minute) * time.Minute)}
+		if task != 0 {
+			e.Context = &models.VisitContext{TaskID: task, RootTaskID: root}
+		}
+		return e
+	}
+	// Newest first, as read
+	entries := []models.HistoryEntry{
+		visit("https://news.example/story", 50, "chrome", 200, 200),
+		visit("https://docs.example/b", 40, "chrome", 100, 102),
+		visit("https://edge.example/", 35, "edge", 100, 100),
+		visit("https://mail.example/", 30, "chrome", 0, 0),
+		visit("https://docs.example/a", 20, "chrome", 100, 101),
+		visit("https://news.example/", 10, "chrome", 200, 200),
+		visit("https://search.example/", 5, "chrome", 0, 100),
+	}
+
+	got := GroupByTask(entries)
+	want := []string{
+		"https://news.example/story",
+		"https://news.example/",
+		"https://docs.example/b",
+		"https://docs.example/a",
+		"https://search.example/",
+		"https://edge.example/",
+		"https://mail.example/",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].URL != want[i] {
+			t.Errorf("entry %d = %s, want %s", i, got[i].URL, want[i])
+		}
+	}
+}
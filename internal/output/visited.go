@@ -0,0 +1,38 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// FormatVisitedJSON writes a visited report as JSON to the given writer
+func FormatVisitedJSON(w io.Writer, report models.VisitedReport) error {
+	if report.Timezone == "" {
+		report.Timezone = "UTC"
+	}
+	if report.Results == nil {
+		report.Results = []models.VisitedCheck{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
+
+// FormatUnvisited writes each input that is not in history on its own line,
+// as it was given, so a reading list can be filtered down to the unread links
+func FormatUnvisited(w io.Writer, checks []models.VisitedCheck) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range checks {
+		if !c.Visited {
+			bw.WriteString(c.Input)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,38 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// FormatVivaldiNotesJSON writes a Vivaldi notes report as JSON to the given
+// writer
+func FormatVivaldiNotesJSON(w io.Writer, report models.VivaldiNotesReport) error {
+	if report.Notes == nil {
+		report.Notes = []models.VivaldiNote{}
+	}
+	return encodeVivaldi(w, report)
+}
+
+// FormatSpeedDialsJSON writes a Speed Dial report as JSON to the given writer
+func FormatSpeedDialsJSON(w io.Writer, report models.SpeedDialReport) error {
+	if report.Groups == nil {
+		report.Groups = []models.SpeedDialGroup{}
+	}
+	for i := range report.Groups {
+		if report.Groups[i].Dials == nil {
+			report.Groups[i].Dials = []models.SpeedDial{}
+		}
+	}
+	return encodeVivaldi(w, report)
+}
+
+func encodeVivaldi(w io.Writer, report any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(report)
+}
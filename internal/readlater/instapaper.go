@@ -0,0 +1,57 @@
+package readlater
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// instapaperAPIURL is the Simple API base URL, replaced in tests
+var instapaperAPIURL = "https://www.instapaper.com/api"
+
+// InstapaperConfig holds the account the Simple API signs in with. Accounts
+// without a password leave Password empty.
+type InstapaperConfig struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type instapaper struct {
+	config InstapaperConfig
+}
+
+func (i *instapaper) Name() string { return "Instapaper" }
+
+// Push adds each item to the unread list. The Simple API has no folders, so
+// collections are ignored.
+func (i *instapaper) Push(ctx context.Context, items []Item) (int, error) {
+	for n, item := range items {
+		form := url.Values{
+			"username": {i.config.Username},
+			"password": {i.config.Password},
+			"url":      {item.Bookmark.URL},
+			"title":    {item.Bookmark.Title},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, instapaperAPIURL+"/add", strings.NewReader(form.Encode()))
+		if err != nil {
+			return n, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return n, fmt.Errorf("failed to save %s to Instapaper: %v", item.Bookmark.URL, err)
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusCreated, http.StatusOK:
+		case http.StatusForbidden:
+			return n, fmt.Errorf("Instapaper rejected the username or password")
+		default:
+			return n, fmt.Errorf("failed to save %s to Instapaper: %s", item.Bookmark.URL, resp.Status)
+		}
+	}
+	return len(items), nil
+}
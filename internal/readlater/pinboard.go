@@ -0,0 +1,118 @@
+package readlater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// pinboardAPIURL is the v1 API base URL, replaced in tests
+var pinboardAPIURL = "https://api.pinboard.in/v1"
+
+// pinboardDelay is the wait between API calls Pinboard asks clients to keep
+var pinboardDelay = 3 * time.Second
+
+// Pinboard truncates descriptions longer than this
+const pinboardDescriptionLimit = 255
+
+// PinboardConfig holds a Pinboard API token, "username:HEX" from the
+// settings page
+type PinboardConfig struct {
+	Token string `json:"token,omitempty"`
+}
+
+type pinboard struct {
+	config PinboardConfig
+}
+
+func (p *pinboard) Name() string { return "Pinboard" }
+
+// Push adds each item as a private bookmark tagged with its folders and
+// collection. URLs already on Pinboard are left as they are and still
+// counted as saved.
+func (p *pinboard) Push(ctx context.Context, items []Item) (int, error) {
+	for n, item := range items {
+		if n > 0 {
+			select {
+			case <-ctx.Done():
+				return n, ctx.Err()
+			case <-time.After(pinboardDelay):
+			}
+		}
+
+		b := item.Bookmark
+		tags := b.FolderTags()
+		if c := strings.Join(strings.Fields(item.Collection), "-"); c != "" && !containsFold(tags, c) {
+			tags = append(tags, c)
+		}
+		description := b.Title
+		if description == "" {
+			description = b.URL
+		}
+		if len([]rune(description)) > pinboardDescriptionLimit {
+			description = string([]rune(description)[:pinboardDescriptionLimit])
+		}
+		query := url.Values{
+			"auth_token":  {p.config.Token},
+			"format":      {"json"},
+			"url":         {b.URL},
+			"description": {description},
+			"tags":        {strings.Join(tags, " ")},
+			"shared":      {"no"},
+			"replace":     {"no"},
+		}
+		if !b.DateAdded.IsZero() {
+			query.Set("dt", b.DateAdded.UTC().Format("2006-01-02T15:04:05Z"))
+		}
+
+		if err := p.add(ctx, query); err != nil {
+			return n, fmt.Errorf("failed to save %s to Pinboard: %v", b.URL, err)
+		}
+	}
+	return len(items), nil
+}
+
+func (p *pinboard) add(ctx context.Context, query url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pinboardAPIURL+"/posts/add?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// Request errors include the URL, which contains the token
+		return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), url.QueryEscape(p.config.Token), "<token>"))
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("Pinboard rejected the API token")
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("Pinboard rate limit reached; try again later")
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	var result struct {
+		ResultCode string `json:"result_code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.ResultCode != "done" && result.ResultCode != "item already exists" {
+		return fmt.Errorf("%s", result.ResultCode)
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package readlater
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// raindropAPIURL is the REST API base URL, replaced in tests
+var raindropAPIURL = "https://api.raindrop.io/rest/v1"
+
+// raindropBatch is the most raindrops the API creates in one request
+const raindropBatch = 100
+
+// raindropUnsorted is the ID of the built-in Unsorted collection
+const raindropUnsorted = -1
+
+// RaindropConfig holds a Raindrop.io test token or OAuth access token
+type RaindropConfig struct {
+	Token string `json:"token,omitempty"`
+}
+
+type raindrop struct {
+	config      RaindropConfig
+	collections map[string]int // lower-cased title to ID
+}
+
+func (r *raindrop) Name() string { return "Raindrop.io" }
+
+// Push files each item in the top-level collection with its name, creating
+// collections that do not exist yet
+func (r *raindrop) Push(ctx context.Context, items []Item) (int, error) {
+	type raindropItem struct {
+		Link       string         `json:"link"`
+		Title      string         `json:"title,omitempty"`
+		Tags       []string       `json:"tags,omitempty"`
+		Created    *time.Time     `json:"created,omitempty"`
+		Collection map[string]int `json:"collection"`
+	}
+
+	saved := 0
+	for start := 0; start < len(items); start += raindropBatch {
+		batch := items[start:min(start+raindropBatch, len(items))]
+		payload := make([]raindropItem, len(batch))
+		for i, item := range batch {
+			id, err := r.collectionID(ctx, item.Collection)
+			if err != nil {
+				return saved, err
+			}
+			b := item.Bookmark
+			payload[i] = raindropItem{Link: b.URL, Title: b.Title, Tags: b.Tags, Collection: map[string]int{"$id": id}}
+			if !b.DateAdded.IsZero() {
+				payload[i].Created = &b.DateAdded
+			}
+		}
+		if err := r.call(ctx, http.MethodPost, "/raindrops", map[string]interface{}{"items": payload}, nil); err != nil {
+			return saved, fmt.Errorf("failed to save bookmarks to Raindrop.io: %v", err)
+		}
+		saved += len(batch)
+	}
+	return saved, nil
+}
+
+// collectionID returns the ID of the collection named name, creating it if
+// needed
+func (r *raindrop) collectionID(ctx context.Context, name string) (int, error) {
+	if name == "" {
+		return raindropUnsorted, nil
+	}
+	if r.collections == nil {
+		if err := r.loadCollections(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if id, ok := r.collections[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+
+	var created struct {
+		Item struct {
+			ID int `json:"_id"`
+		} `json:"item"`
+	}
+	if err := r.call(ctx, http.MethodPost, "/collection", map[string]string{"title": name}, &created); err != nil {
+		return 0, fmt.Errorf("failed to create Raindrop.io collection %q: %v", name, err)
+	}
+	r.collections[strings.ToLower(name)] = created.Item.ID
+	return created.Item.ID, nil
+}
+
+// loadCollections reads the titles of the top-level collections
+func (r *raindrop) loadCollections(ctx context.Context) error {
+	var list struct {
+		Items []struct {
+			ID    int    `json:"_id"`
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	if err := r.call(ctx, http.MethodGet, "/collections", nil, &list); err != nil {
+		return fmt.Errorf("failed to list Raindrop.io collections: %v", err)
+	}
+	r.collections = make(map[string]int, len(list.Items))
+	for _, c := range list.Items {
+		r.collections[strings.ToLower(c.Title)] = c.ID
+	}
+	return nil
+}
+
+// call sends a JSON request to the API and decodes the response into out
+func (r *raindrop) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, raindropAPIURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.config.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
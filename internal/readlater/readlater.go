@@ -0,0 +1,108 @@
+// Package readlater saves bookmarks to read-later services
+package readlater
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Config holds the read-later service credentials from the config file.
+// Environment variables fill in what the file leaves out.
+type Config struct {
+	Raindrop   RaindropConfig   `json:"raindrop,omitempty"`
+	Instapaper InstapaperConfig `json:"instapaper,omitempty"`
+	Pinboard   PinboardConfig   `json:"pinboard,omitempty"`
+}
+
+// Item is a bookmark to save, with the collection it is filed under. An
+// empty Collection leaves it unsorted.
+type Item struct {
+	Bookmark   models.BookmarkEntry
+	Collection string
+}
+
+// Service saves bookmarks to a read-later service
+type Service interface {
+	Name() string
+	// Push saves the items in order, stopping at the first error, and
+	// returns how many were saved
+	Push(ctx context.Context, items []Item) (int, error)
+}
+
+// Services lists the names New accepts
+var Services = []string{"raindrop", "instapaper", "pinboard"}
+
+// New returns the named service, configured from config and the environment
+func New(name string, config Config) (Service, error) {
+	switch strings.ToLower(name) {
+	case "raindrop":
+		c := config.Raindrop
+		if c.Token == "" {
+			c.Token = os.Getenv("RAINDROP_TOKEN")
+		}
+		if c.Token == "" {
+			return nil, fmt.Errorf("Raindrop.io token is not configured (set read_later.raindrop.token in the config file or RAINDROP_TOKEN)")
+		}
+		return &raindrop{config: c}, nil
+	case "instapaper":
+		c := config.Instapaper
+		if c.Username == "" {
+			c.Username = os.Getenv("INSTAPAPER_USERNAME")
+		}
+		if c.Password == "" {
+			c.Password = os.Getenv("INSTAPAPER_PASSWORD")
+		}
+		if c.Username == "" {
+			return nil, fmt.Errorf("Instapaper username is not configured (set read_later.instapaper.username in the config file or INSTAPAPER_USERNAME)")
+		}
+		return &instapaper{config: c}, nil
+	case "pinboard":
+		c := config.Pinboard
+		if c.Token == "" {
+			c.Token = os.Getenv("PINBOARD_TOKEN")
+		}
+		if c.Token == "" {
+			return nil, fmt.Errorf("Pinboard API token is not configured (set read_later.pinboard.token in the config file or PINBOARD_TOKEN)")
+		}
+		return &pinboard{config: c}, nil
+	case "pocket":
+		return nil, fmt.Errorf("Pocket shut down in July 2025 and no longer accepts bookmarks")
+	default:
+		return nil, fmt.Errorf("unknown read-later service %q (use %s)", name, strings.Join(Services, ", "))
+	}
+}
+
+// CollectionFor returns the collection a bookmark in folder is filed under.
+// A non-empty fixed collection is used for every bookmark. Otherwise the
+// longest folder in mapping that holds the bookmark names its collection,
+// and failing that the bookmark's own folder does. Bookmarks directly in a
+// browser's root folder, such as "Bookmarks Bar", are left unsorted.
+func CollectionFor(folder, fixed string, mapping map[string]string) string {
+	if fixed != "" {
+		return fixed
+	}
+
+	best := -1
+	var collection string
+	for from, to := range mapping {
+		from = strings.Trim(from, "/")
+		if !strings.EqualFold(folder, from) && !strings.HasPrefix(strings.ToLower(folder), strings.ToLower(from)+"/") {
+			continue
+		}
+		if len(from) > best {
+			best, collection = len(from), to
+		}
+	}
+	if best >= 0 {
+		return collection
+	}
+
+	if i := strings.LastIndex(folder, "/"); i >= 0 {
+		return folder[i+1:]
+	}
+	return ""
+}
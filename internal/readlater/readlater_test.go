@@ -0,0 +1,158 @@
+package readlater
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCollectionFor(t *testing.T) {
+	mapping := map[string]string{"Bookmarks Bar/Work": "Work", "Bookmarks Bar/Work/Old": "Archive"}
+	tests := []struct {
+		folder, fixed, want string
+	}{
+		{"Bookmarks Bar/Reading", "", "Reading"},
+		{"Bookmarks Bar/Reading/Go", "", "Go"},
+		{"Bookmarks Bar", "", ""},
+		{"", "", ""},
+		{"bookmarks bar/work/projects", "", "Work"},
+		{"Bookmarks Bar/Work/Old/2019", "", "Archive"},
+		{"Bookmarks Bar/Workshop", "", "Workshop"},
+		{"Bookmarks Bar/Reading", "Inbox", "Inbox"},
+	}
+	for _, tt := range tests {
+		if got := CollectionFor(tt.folder, tt.fixed, mapping); got != tt.want {
+			t.Errorf("CollectionFor(%q, %q) = %q, want %q", tt.folder, tt.fixed, got, tt.want)
+		}
+	}
+}
+
+func TestRaindropPush(t *testing.T) {
+	var created []string
+	var saved []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /collections":
+			w.Write([]byte(`{"result":true,"items":[{"_id":10,"title":"Reading"}]}`))
+		case "POST /collection":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body["title"])
+			w.Write([]byte(`{"result":true,"item":{"_id":20}}`))
+		case "POST /raindrops":
+			var body struct {
+				Items []map[string]interface{} `json:"items"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			saved = append(saved, body.Items...)
+			w.Write([]byte(`{"result":true}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	old := raindropAPIURL
+	raindropAPIURL = server.URL
+	defer func() { raindropAPIURL = old }()
+
+	service, err := New("raindrop", Config{Raindrop: RaindropConfig{Token: "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []Item{
+		{Bookmark: models.BookmarkEntry{URL: "https://go.dev/", Title: "Go"}, Collection: "reading"},
+		{Bookmark: models.BookmarkEntry{URL: "https://a.example/"}, Collection: "Work"},
+		{Bookmark: models.BookmarkEntry{URL: "https://b.example/"}, Collection: "Work"},
+		{Bookmark: models.BookmarkEntry{URL: "https://c.example/"}},
+	}
+	n, err := service.Push(context.Background(), items)
+	if err != nil || n != 4 {
+		t.Fatalf("Push = %d, %v", n, err)
+	}
+	if len(created) != 1 || created[0] != "Work" {
+		t.Errorf("created collections %v", created)
+	}
+	var ids []float64
+	for _, item := range saved {
+		ids = append(ids, item["collection"].(map[string]interface{})["$id"].(float64))
+	}
+	if len(ids) != 4 || ids[0] != 10 || ids[1] != 20 || ids[2] != 20 || ids[3] != -1 {
+		t.Errorf("collection ids %v", ids)
+	}
+}
+
+func TestInstapaperPush(t *testing.T) {
+	var urls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("username") != "me@example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		urls = append(urls, r.Form.Get("url"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	old := instapaperAPIURL
+	instapaperAPIURL = server.URL
+	defer func() { instapaperAPIURL = old }()
+
+	items := []Item{{Bookmark: models.BookmarkEntry{URL: "https://go.dev/"}}, {Bookmark: models.BookmarkEntry{URL: "https://a.example/"}}}
+	service, _ := New("instapaper", Config{Instapaper: InstapaperConfig{Username: "me@example.com"}})
+	if n, err := service.Push(context.Background(), items); err != nil || n != 2 || len(urls) != 2 {
+		t.Errorf("Push = %d, %v; urls %v", n, err, urls)
+	}
+
+	service, _ = New("instapaper", Config{Instapaper: InstapaperConfig{Username: "wrong"}})
+	if n, err := service.Push(context.Background(), items); err == nil || n != 0 {
+		t.Errorf("Push with a bad login = %d, %v", n, err)
+	}
+}
+
+func TestPinboardPush(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/posts/add" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query())
+		if len(queries) == 2 {
+			w.Write([]byte(`{"result_code":"item already exists"}`))
+			return
+		}
+		w.Write([]byte(`{"result_code":"done"}`))
+	}))
+	defer server.Close()
+	oldURL, oldDelay := pinboardAPIURL, pinboardDelay
+	pinboardAPIURL, pinboardDelay = server.URL, 0
+	defer func() { pinboardAPIURL, pinboardDelay = oldURL, oldDelay }()
+
+	added := time.Date(2025, 12, 1, 9, 30, 0, 0, time.UTC)
+	items := []Item{
+		{Bookmark: models.BookmarkEntry{URL: "https://go.dev/", Title: "Go", Folder: "Bookmarks Bar/Dev Tools", DateAdded: added}, Collection: "Dev Tools"},
+		{Bookmark: models.BookmarkEntry{URL: "https://a.example/"}, Collection: "Inbox"},
+	}
+	service, err := New("pinboard", Config{Pinboard: PinboardConfig{Token: "me:ABC"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := service.Push(context.Background(), items); err != nil || n != 2 {
+		t.Fatalf("Push = %d, %v", n, err)
+	}
+	q := queries[0]
+	if q.Get("auth_token") != "me:ABC" || q.Get("tags") != "Dev-Tools" || q.Get("dt") != "2025-12-01T09:30:00Z" || q.Get("shared") != "no" {
+		t.Errorf("first query %v", q)
+	}
+	if q := queries[1]; q.Get("description") != "https://a.example/" || q.Get("tags") != "Inbox" {
+		t.Errorf("second query %v", q)
+	}
+}
@@ -0,0 +1,268 @@
+package recap
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/digest"
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/stats"
+	"github.com/rzolkos/web-recap/internal/urlutil"
+)
+
+// Style selects the prompt template and default time range of a recap
+type Style string
+
+const (
+	Daily   Style = "daily"
+	Weekly  Style = "weekly"
+	Standup Style = "standup"
+)
+
+// ParseStyle validates a style name from the command line
+func ParseStyle(s string) (Style, error) {
+	switch Style(s) {
+	case Daily, Weekly, Standup:
+		return Style(s), nil
+	default:
+		return "", fmt.Errorf("invalid recap style %q (use daily, weekly, or standup)", s)
+	}
+}
+
+// Range returns the default [start, end) window for a style: today for
+// daily, the last seven days for weekly, and the previous workday through
+// today for standup.
+func (s Style) Range(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	end := today.AddDate(0, 0, 1)
+
+	switch s {
+	case Weekly:
+		return end.AddDate(0, 0, -7), end
+	case Standup:
+		start := today.AddDate(0, 0, -1)
+		for start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+			start = start.AddDate(0, 0, -1)
+		}
+		return start, end
+	default:
+		return today, end
+	}
+}
+
+// Limits that keep the prompt a manageable size
+const (
+	maxTopDomains = 10
+	maxTopPages   = 15
+	maxSessions   = 30
+	maxSearches   = 60
+	maxClicks     = 3
+)
+
+// Data is everything a recap prompt is built from
+type Data struct {
+	Style    Style                    `json:"style"`
+	Browser  string                   `json:"browser"`
+	Start    time.Time                `json:"start"`
+	End      time.Time                `json:"end"`
+	Timezone string                   `json:"timezone"`
+	Stats    models.DigestReport      `json:"stats"`
+	Sessions []models.BrowsingSession `json:"sessions"`
+	Searches []models.SearchQuery     `json:"searches"`
+}
+
+// Options controls how recap data is gathered
+type Options struct {
+	Style      Style
+	SessionGap time.Duration
+}
+
+// Build collects stats, sessions, and searches for entries in [start, end)
+func Build(entries []models.HistoryEntry, browser string, start, end time.Time, loc *time.Location, opts Options) Data {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var inRange []models.HistoryEntry
+	for _, e := range entries {
+		if !e.Timestamp.Before(start) && e.Timestamp.Before(end) {
+			inRange = append(inRange, e)
+		}
+	}
+
+	period := digest.Daily
+	if end.Sub(start) > 24*time.Hour {
+		period = digest.Weekly
+	}
+
+	data := Data{
+		Style:    opts.Style,
+		Browser:  browser,
+		Start:    start.UTC(),
+		End:      end.UTC(),
+		Timezone: loc.String(),
+		Stats: digest.Build(inRange, browser, start, end, loc, digest.Options{
+			Period:     period,
+			TopDomains: maxTopDomains,
+			TopPages:   maxTopPages,
+		}),
+		Sessions: stats.DetectSessions(inRange, opts.SessionGap),
+		Searches: stats.ExtractSearches(inRange),
+	}
+
+	// Keep the busiest sessions, still in chronological order
+	if len(data.Sessions) > maxSessions {
+		sessions := append([]models.BrowsingSession(nil), data.Sessions...)
+		sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Visits > sessions[j].Visits })
+		sessions = sessions[:maxSessions]
+		sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Start.Before(sessions[j].Start) })
+		data.Sessions = sessions
+	}
+	// Keep the most recent searches
+	if len(data.Searches) > maxSearches {
+		data.Searches = data.Searches[len(data.Searches)-maxSearches:]
+	}
+
+	return data
+}
+
+// SystemPrompt is sent alongside the recap prompt when calling an LLM
+const SystemPrompt = `You turn a summary of someone's web browsing into a written recap for them.
+Only describe activity supported by the data, do not speculate about private matters,
+and write in the first person as if they wrote it themselves.`
+
+var instructions = map[Style]string{
+	Daily: `Write a short recap of my day from the browsing data below. Group the activity into
+a few themes, say what I was researching (the searches show my questions, and after →
+the pages I opened to answer them) and the main things I read or worked on. Keep it
+under 200 words.`,
+	Weekly: `Write a weekly review from the browsing data below. Describe the main themes of the
+week, how my focus shifted from day to day, sites I kept returning to, and open questions
+suggested by my searches. Use a short heading per theme. Keep it under 400 words.`,
+	Standup: `Write my standup update from the browsing data below, as terse bullet points in three
+sections: "Yesterday" (what I worked on), "Today" (what I appear to be continuing), and
+"Blockers" (problems suggested by my searches, or "None"). Ignore entertainment and
+personal browsing.`,
+}
+
+var promptTemplate = template.Must(template.New("recap").Funcs(template.FuncMap{
+	"day":   func(t time.Time) string { return t.Format("Mon Jan 2") },
+	"clock": func(t time.Time) string { return t.Format("15:04") },
+	"title": func(p models.DigestPage) string {
+		if p.Title == "" {
+			return p.URL
+		}
+		return p.Title
+	},
+	"domains": func(ds []models.DigestDomain) string {
+		parts := make([]string, len(ds))
+		for i, d := range ds {
+			parts[i] = fmt.Sprintf("%s (%d)", displayDomain(d.Domain), d.Visits)
+		}
+		return strings.Join(parts, ", ")
+	},
+	"answers": func(cs []models.SearchClick) string {
+		if len(cs) > maxClicks {
+			cs = cs[:maxClicks]
+		}
+		parts := make([]string, len(cs))
+		for i, c := range cs {
+			if c.Title == "" {
+				parts[i] = c.URL
+			} else {
+				parts[i] = fmt.Sprintf("%q (%s)", c.Title, displayDomain(c.Domain))
+			}
+		}
+		return strings.Join(parts, "; ")
+	},
+	"quote": func(ss []string) string {
+		parts := make([]string, len(ss))
+		for i, s := range ss {
+			parts[i] = fmt.Sprintf("%q", s)
+		}
+		return strings.Join(parts, ", ")
+	},
+}).Parse(`{{.Instructions}}
+
+## Period
+{{day .Start}} {{clock .Start}} – {{day .End}} {{clock .End}} ({{.Timezone}}), browser: {{.Browser}}
+
+## Stats
+- Visits: {{.Stats.TotalVisits}}, unique pages: {{.Stats.UniquePages}}, domains: {{.Stats.UniqueDomains}}
+{{- if gt (len .Stats.Days) 1}}
+- Visits per day:{{range .Stats.Days}} {{.Date}}={{.Visits}}{{end}}
+{{- end}}
+{{- if .Stats.TopDomains}}
+- Top domains: {{domains .Stats.TopDomains}}
+{{- end}}
+{{if .Stats.TopPages}}
+## Most visited pages
+{{range .Stats.TopPages}}- {{title .}} — {{.URL}} ({{.Visits}} visits)
+{{end}}{{end}}
+## Sessions
+{{range .Sessions}}- {{day .Start}} {{clock .Start}}–{{clock .End}} ({{.DurationMinutes}} min, {{.Visits}} visits): {{domains .TopDomains}}{{if .SampleTitles}}; pages: {{quote .SampleTitles}}{{end}}
+{{else}}- none
+{{end}}
+## Searches
+{{range .Searches}}- {{day .Timestamp}} {{clock .Timestamp}} [{{.Engine}}] {{printf "%q" .Query}}{{if .Clicks}} → {{answers .Clicks}}{{end}}
+{{else}}- none
+{{end}}`))
+
+// displayDomain shows an internationalized domain in Unicode rather than
+// punycode, and marks one that could pass for another site
+func displayDomain(domain string) string {
+	shown := domain
+	if d := urlutil.DisplayHost(domain); d != "" {
+		shown = d
+	}
+	if urlutil.Lookalike(domain) {
+		shown += " [possible lookalike]"
+	}
+	return shown
+}
+
+// BuildPrompt renders the ready-to-paste prompt for a recap, with times in
+// loc and any extra instructions appended to the style's instructions
+func BuildPrompt(data Data, loc *time.Location, extra string) (string, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	text := instructions[data.Style]
+	if text == "" {
+		text = instructions[Daily]
+	}
+	if extra = strings.TrimSpace(extra); extra != "" {
+		text += "\n" + extra
+	}
+
+	// Present times in the recap timezone
+	local := data
+	local.Start = data.Start.In(loc)
+	local.End = data.End.In(loc)
+	local.Sessions = make([]models.BrowsingSession, len(data.Sessions))
+	for i, s := range data.Sessions {
+		s.Start, s.End = s.Start.In(loc), s.End.In(loc)
+		local.Sessions[i] = s
+	}
+	local.Searches = make([]models.SearchQuery, len(data.Searches))
+	for i, s := range data.Searches {
+		s.Timestamp = s.Timestamp.In(loc)
+		local.Searches[i] = s
+	}
+
+	var buf bytes.Buffer
+	if err := promptTemplate.Execute(&buf, struct {
+		Data
+		Instructions string
+	}{local, text}); err != nil {
+		return "", fmt.Errorf("failed to render recap prompt: %v", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,67 @@
+package recap
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestStandupRangeSkipsWeekend(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	start, end := Standup.Range(monday, time.UTC)
+
+	if want := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %v, want Friday %v", start, want)
+	}
+	if want := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %v, want %v", end, want)
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: base, URL: "https://www.google.com/search?q=sqlite+wal+mode", Title: "sqlite wal mode - Google Search", Domain: "google.com"},
+		{Timestamp: base.Add(2 * time.Minute), URL: "https://sqlite.org/wal.html", Title: "Write-Ahead Logging", Domain: "sqlite.org", Referrer: "https://www.google.com/search?q=sqlite+wal+mode"},
+		{Timestamp: base.Add(10 * time.Minute), URL: "https://sqlite.org/wal.html", Title: "Write-Ahead Logging", Domain: "sqlite.org"},
+		// Second session after a long gap
+		{Timestamp: base.Add(3 * time.Hour), URL: "https://news.example/a", Title: "Article", Domain: "news.example"},
+		// Outside the range
+		{Timestamp: base.AddDate(0, 0, -3), URL: "https://old.example/", Title: "Old", Domain: "old.example"},
+	}
+
+	start, end := Daily.Range(base, time.UTC)
+	data := Build(entries, "chrome", start, end, time.UTC, Options{Style: Daily})
+
+	if data.Stats.TotalVisits != 4 {
+		t.Errorf("TotalVisits = %d, want 4", data.Stats.TotalVisits)
+	}
+	if len(data.Sessions) != 2 || data.Sessions[0].Visits != 3 || data.Sessions[0].DurationMinutes != 10 {
+		t.Errorf("Sessions = %+v, want 2 sessions, first with 3 visits over 10 min", data.Sessions)
+	}
+	if len(data.Searches) != 1 || data.Searches[0].Query != "sqlite wal mode" {
+		t.Errorf("Searches = %+v", data.Searches)
+	}
+
+	prompt, err := BuildPrompt(data, time.UTC, "Mention SQLite by name.")
+	if err != nil {
+		t.Fatalf("BuildPrompt: %v", err)
+	}
+	for _, want := range []string{
+		"Write a short recap of my day",
+		"Mention SQLite by name.",
+		"- Visits: 4, unique pages: 3, domains: 3",
+		"- Write-Ahead Logging — https://sqlite.org/wal.html (2 visits)",
+		"- Mon Mar 2 09:00–09:10 (10 min, 3 visits): sqlite.org (2), google.com (1)",
+		`- Mon Mar 2 09:00 [google] "sqlite wal mode" → "Write-Ahead Logging" (sqlite.org)`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "old.example") {
+		t.Errorf("prompt includes out-of-range entry:\n%s", prompt)
+	}
+}
@@ -0,0 +1,215 @@
+// Package redact removes personal data from URLs and titles before history
+// leaves the machine
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/urlutil"
+)
+
+// Mode is how URLs are redacted
+type Mode string
+
+const (
+	// None keeps URLs, only scrubbing emails and tokens
+	None Mode = ""
+	// Hash replaces each URL with a hash of it, so repeat visits can still be
+	// matched without revealing the page
+	Hash Mode = "hash"
+	// DomainOnly keeps only the scheme and host
+	DomainOnly Mode = "domain-only"
+	// PathTrim keeps the scheme, host, and first path segment
+	PathTrim Mode = "path-trim"
+)
+
+// Placeholder replaces scrubbed values
+const Placeholder = "REDACTED"
+
+// ParseMode parses a --redact value
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case None, Hash, DomainOnly, PathTrim:
+		return m, nil
+	default:
+		return None, fmt.Errorf("invalid redact mode %q (use hash, domain-only, or path-trim)", s)
+	}
+}
+
+// emailPattern matches email addresses, including percent-encoded @ signs
+var emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+-]+(@|%40)[a-z0-9.-]+\.[a-z]{2,}`)
+
+// tokenPattern matches values that may be secrets: JWTs, long hex strings,
+// and long runs of base64 characters
+var tokenPattern = regexp.MustCompile(`^(eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*|[A-Fa-f0-9]{32,}|[A-Za-z0-9_\-+/=]{32,})$`)
+
+// looksLikeToken reports whether a query value looks like a secret. Base64
+// runs must mix upper case, lower case, and digits, so search terms and
+// slugs are kept.
+func looksLikeToken(v string) bool {
+	if !tokenPattern.MatchString(v) {
+		return false
+	}
+	if strings.HasPrefix(v, "eyJ") || strings.Trim(v, "0123456789abcdefABCDEF") == "" {
+		return true
+	}
+	return strings.IndexFunc(v, unicode.IsUpper) >= 0 && strings.IndexFunc(v, unicode.IsLower) >= 0 &&
+		strings.IndexFunc(v, unicode.IsDigit) >= 0
+}
+
+// secretParams are query parameters whose values are always scrubbed
+var secretParams = map[string]bool{
+	"access_token": true, "api_key": true, "apikey": true, "auth": true, "code": true,
+	"id_token": true, "key": true, "password": true, "passwd": true, "pwd": true,
+	"refresh_token": true, "secret": true, "session": true, "sessionid": true,
+	"sid": true, "sig": true, "signature": true, "token": true,
+}
+
+// URL redacts a URL for mode and scrubs emails and tokens from what is kept.
+// Values without a host, such as mailto: links, are only scrubbed.
+func URL(raw string, mode Mode) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return emailPattern.ReplaceAllString(raw, Placeholder)
+	}
+	switch mode {
+	case Hash:
+		sum := sha256.Sum256([]byte(raw))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	case DomainOnly:
+		return u.Scheme + "://" + u.Host + "/"
+	case PathTrim:
+		path := "/"
+		if segments := strings.SplitN(strings.TrimPrefix(u.EscapedPath(), "/"), "/", 2); segments[0] != "" {
+			path += segments[0]
+		}
+		return u.Scheme + "://" + u.Host + emailPattern.ReplaceAllString(path, Placeholder)
+	}
+	return scrubURL(u)
+}
+
+// scrubURL replaces emails in the path and fragment, and emails and
+// token-like values in the query string
+func scrubURL(u *url.URL) string {
+	u.User = nil
+	if u.RawQuery != "" {
+		parts := strings.Split(u.RawQuery, "&")
+		for i, part := range parts {
+			name, value, ok := strings.Cut(part, "=")
+			if !ok {
+				continue
+			}
+			decoded, err := url.QueryUnescape(value)
+			if err != nil {
+				decoded = value
+			}
+			if secretParams[strings.ToLower(name)] || looksLikeToken(decoded) || emailPattern.MatchString(decoded) {
+				parts[i] = name + "=" + Placeholder
+			}
+		}
+		u.RawQuery = strings.Join(parts, "&")
+	}
+	s := u.String()
+	return emailPattern.ReplaceAllString(s, Placeholder)
+}
+
+// Text scrubs emails from free text such as page titles
+func Text(s string) string {
+	return emailPattern.ReplaceAllString(s, Placeholder)
+}
+
+// Entry redacts the URL and scrubs the title of a history entry. With Hash
+// the title goes too; the hash stands in for the page and the domain stays
+// for grouping. The display URL follows the redacted one.
+func Entry(e models.HistoryEntry, mode Mode) models.HistoryEntry {
+	e.URL = URL(e.URL, mode)
+	if e.DisplayURL != "" {
+		e.DisplayURL = urlutil.DisplayURL(e.URL)
+	}
+	e.Title = Text(e.Title)
+	if mode == Hash {
+		e.Title = ""
+	}
+	return e
+}
+
+// History redacts entries in place
+func History(entries []models.HistoryEntry, mode Mode) {
+	for i := range entries {
+		entries[i] = Entry(entries[i], mode)
+	}
+}
+
+// Tabs redacts the URLs and scrubs the titles of tabs in place
+func Tabs(tabs []models.TabEntry, mode Mode) {
+	for i := range tabs {
+		tabs[i].URL = URL(tabs[i].URL, mode)
+		tabs[i].Title = Text(tabs[i].Title)
+		if mode == Hash {
+			tabs[i].Title = ""
+		}
+	}
+}
+
+// Journeys redacts the URLs and scrubs the titles of the visits in journeys
+// in place, and scrubs their labels and keywords. With Hash the titles,
+// labels, and keywords go too, since they name the pages.
+func Journeys(journeys []models.Journey, mode Mode) {
+	for i := range journeys {
+		j := &journeys[i]
+		j.Label = Text(j.Label)
+		for k := range j.Keywords {
+			j.Keywords[k] = Text(j.Keywords[k])
+		}
+		if mode == Hash {
+			j.Label, j.Keywords = "", nil
+		}
+		for k := range j.Visits {
+			v := &j.Visits[k]
+			v.URL = URL(v.URL, mode)
+			v.Title = Text(v.Title)
+			if mode == Hash {
+				v.Title = ""
+			}
+		}
+	}
+}
+
+// VivaldiNotes redacts the URLs and scrubs the titles and text of Vivaldi
+// notes in place. With Hash the titles and text go too.
+func VivaldiNotes(notes []models.VivaldiNote, mode Mode) {
+	for i := range notes {
+		n := &notes[i]
+		if n.URL != "" {
+			n.URL = URL(n.URL, mode)
+		}
+		n.Title = Text(n.Title)
+		n.Content = Text(n.Content)
+		if mode == Hash {
+			n.Title, n.Content = "", ""
+		}
+	}
+}
+
+// SpeedDials redacts the URLs and scrubs the titles of Speed Dial tiles in
+// place. With Hash the titles and nicknames go too.
+func SpeedDials(groups []models.SpeedDialGroup, mode Mode) {
+	for i := range groups {
+		for k := range groups[i].Dials {
+			d := &groups[i].Dials[k]
+			d.URL = URL(d.URL, mode)
+			d.Title = Text(d.Title)
+			d.Nickname = Text(d.Nickname)
+			if mode == Hash {
+				d.Title, d.Nickname = "", ""
+			}
+		}
+	}
+}
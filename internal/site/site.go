@@ -0,0 +1,397 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/stats"
+)
+
+// Options controls how the journal site is rendered
+type Options struct {
+	Title      string
+	Location   *time.Location
+	SessionGap time.Duration
+}
+
+// Result describes what Generate wrote
+type Result struct {
+	Days    int
+	Domains int
+	Pages   int
+}
+
+// pageEntry is a single visit as shown on day and domain pages
+type pageEntry struct {
+	At         time.Time
+	Time       string
+	Date       string
+	Title      string
+	URL        string
+	Domain     string
+	DomainSlug string
+}
+
+type daySession struct {
+	Start   string
+	End     string
+	Entries []pageEntry
+}
+
+type dayPage struct {
+	PageTitle  string
+	Root       string
+	SiteTitle  string
+	Date       string
+	Label      string
+	Visits     int
+	TopDomains []domainLink
+	Sessions   []daySession
+	Prev       string
+	Next       string
+}
+
+type domainDay struct {
+	Date    string
+	Label   string
+	Entries []pageEntry
+}
+
+type domainPage struct {
+	PageTitle string
+	Root      string
+	SiteTitle string
+	Domain    string
+	Visits    int
+	FirstSeen string
+	LastSeen  string
+	Days      []domainDay
+}
+
+type domainLink struct {
+	Domain     string
+	Slug       string
+	Visits     int
+	ActiveDays int
+	LastSeen   string
+}
+
+type dayLink struct {
+	Date       string
+	Label      string
+	Visits     int
+	TopDomains []domainLink
+}
+
+type indexPage struct {
+	PageTitle   string
+	Root        string
+	SiteTitle   string
+	FirstDay    string
+	LastDay     string
+	TotalVisits int
+	Days        []dayLink
+	Domains     []domainLink
+}
+
+// searchItem is one unique page in the prebuilt search index
+type searchItem struct {
+	Title  string `json:"t"`
+	URL    string `json:"u"`
+	Domain string `json:"d"`
+	Date   string `json:"l"` // last visit date, links to the day page
+	Visits int    `json:"n"`
+}
+
+// Generate renders entries into a static HTML journal in dir: an index of
+// days, one page per day and per domain, a domain list, and a client-side
+// search page backed by a prebuilt index. Existing files are overwritten.
+func Generate(dir string, entries []models.HistoryEntry, opts Options) (Result, error) {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if opts.Title == "" {
+		opts.Title = "Browsing journal"
+	}
+	if opts.SessionGap <= 0 {
+		opts.SessionGap = stats.DefaultSessionGap
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	for _, sub := range []string{"", "day", "domain"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return Result{}, fmt.Errorf("failed to create output directory: %v", err)
+		}
+	}
+
+	// Bucket visits by day and by domain
+	byDay := make(map[string][]pageEntry)
+	var dayOrder []string
+	byDomain := make(map[string][]pageEntry)
+	var domainOrder []string
+	search := make(map[string]*searchItem)
+	var searchOrder []string
+
+	for _, e := range sorted {
+		t := e.Timestamp.In(loc)
+		domain := e.Domain
+		if domain == "" {
+			domain = noDomain
+		}
+		pe := pageEntry{
+			At:         t,
+			Time:       t.Format("15:04"),
+			Date:       t.Format("2006-01-02"),
+			Title:      e.Title,
+			URL:        e.URL,
+			Domain:     domain,
+			DomainSlug: domainSlug(domain),
+		}
+		if pe.Title == "" {
+			pe.Title = e.URL
+		}
+
+		if _, ok := byDay[pe.Date]; !ok {
+			dayOrder = append(dayOrder, pe.Date)
+		}
+		byDay[pe.Date] = append(byDay[pe.Date], pe)
+
+		if _, ok := byDomain[domain]; !ok {
+			domainOrder = append(domainOrder, domain)
+		}
+		byDomain[domain] = append(byDomain[domain], pe)
+
+		item, ok := search[e.URL]
+		if !ok {
+			item = &searchItem{URL: e.URL, Domain: domain}
+			search[e.URL] = item
+			searchOrder = append(searchOrder, e.URL)
+		}
+		if e.Title != "" {
+			item.Title = e.Title
+		}
+		item.Date = pe.Date
+		item.Visits++
+	}
+
+	// Domain summaries, busiest first
+	domainLinks := make(map[string]domainLink, len(byDomain))
+	var allDomains []domainLink
+	for _, domain := range domainOrder {
+		visits := byDomain[domain]
+		days := make(map[string]bool)
+		for _, v := range visits {
+			days[v.Date] = true
+		}
+		link := domainLink{
+			Domain:     domain,
+			Slug:       domainSlug(domain),
+			Visits:     len(visits),
+			ActiveDays: len(days),
+			LastSeen:   visits[len(visits)-1].Date,
+		}
+		domainLinks[domain] = link
+		allDomains = append(allDomains, link)
+	}
+	sortDomainLinks(allDomains)
+
+	// Day pages
+	var dayLinks []dayLink
+	for i, day := range dayOrder {
+		visits := byDay[day]
+		page := dayPage{
+			PageTitle:  day,
+			Root:       "../",
+			SiteTitle:  opts.Title,
+			Date:       day,
+			Label:      dayLabel(day),
+			Visits:     len(visits),
+			TopDomains: topDomains(visits, domainLinks, 8),
+			Sessions:   splitSessions(visits, opts.SessionGap),
+		}
+		if i > 0 {
+			page.Prev = dayOrder[i-1]
+		}
+		if i < len(dayOrder)-1 {
+			page.Next = dayOrder[i+1]
+		}
+		if err := writePage(filepath.Join(dir, "day", day+".html"), "day", page); err != nil {
+			return Result{}, err
+		}
+		dayLinks = append(dayLinks, dayLink{Date: day, Label: page.Label, Visits: page.Visits, TopDomains: page.TopDomains[:min(3, len(page.TopDomains))]})
+	}
+
+	// Domain pages, with the most recent day first
+	for _, domain := range domainOrder {
+		visits := byDomain[domain]
+		link := domainLinks[domain]
+		page := domainPage{
+			PageTitle: domain,
+			Root:      "../",
+			SiteTitle: opts.Title,
+			Domain:    domain,
+			Visits:    link.Visits,
+			FirstSeen: visits[0].Date,
+			LastSeen:  link.LastSeen,
+		}
+		for _, v := range visits {
+			if n := len(page.Days); n == 0 || page.Days[n-1].Date != v.Date {
+				page.Days = append(page.Days, domainDay{Date: v.Date, Label: dayLabel(v.Date)})
+			}
+			page.Days[len(page.Days)-1].Entries = append(page.Days[len(page.Days)-1].Entries, v)
+		}
+		for l, r := 0, len(page.Days)-1; l < r; l, r = l+1, r-1 {
+			page.Days[l], page.Days[r] = page.Days[r], page.Days[l]
+		}
+		if err := writePage(filepath.Join(dir, "domain", link.Slug+".html"), "domain", page); err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Index and domain list, newest day first
+	for l, r := 0, len(dayLinks)-1; l < r; l, r = l+1, r-1 {
+		dayLinks[l], dayLinks[r] = dayLinks[r], dayLinks[l]
+	}
+	index := indexPage{
+		Root:        "",
+		SiteTitle:   opts.Title,
+		TotalVisits: len(sorted),
+		Days:        dayLinks,
+		Domains:     allDomains,
+	}
+	if len(dayOrder) > 0 {
+		index.FirstDay = dayOrder[0]
+		index.LastDay = dayOrder[len(dayOrder)-1]
+	}
+	for _, p := range []struct{ file, name, title string }{
+		{"index.html", "index", "Days"},
+		{"domains.html", "domains", "Domains"},
+		{"search.html", "search", "Search"},
+	} {
+		index.PageTitle = p.title
+		if err := writePage(filepath.Join(dir, p.file), p.name, index); err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Search index as a script so search also works when opened from disk
+	items := make([]searchItem, 0, len(searchOrder))
+	for _, u := range searchOrder {
+		items = append(items, *search[u])
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode search index: %v", err)
+	}
+	script := "window.WEB_RECAP_INDEX = " + string(data) + ";\n"
+	if err := os.WriteFile(filepath.Join(dir, "search-index.js"), []byte(script), 0o644); err != nil {
+		return Result{}, fmt.Errorf("failed to write search index: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), []byte(styleCSS), 0o644); err != nil {
+		return Result{}, fmt.Errorf("failed to write stylesheet: %v", err)
+	}
+
+	return Result{Days: len(dayOrder), Domains: len(domainOrder), Pages: len(items)}, nil
+}
+
+// splitSessions breaks a day's visits into sessions separated by idle gaps
+func splitSessions(visits []pageEntry, gap time.Duration) []daySession {
+	var sessions []daySession
+	for i, v := range visits {
+		if i == 0 || v.At.Sub(visits[i-1].At) > gap {
+			sessions = append(sessions, daySession{Start: v.Time})
+		}
+		s := &sessions[len(sessions)-1]
+		s.End = v.Time
+		s.Entries = append(s.Entries, v)
+	}
+	return sessions
+}
+
+// topDomains returns the most visited domains among visits
+func topDomains(visits []pageEntry, links map[string]domainLink, limit int) []domainLink {
+	counts := make(map[string]int)
+	for _, v := range visits {
+		counts[v.Domain]++
+	}
+	result := make([]domainLink, 0, len(counts))
+	for domain, n := range counts {
+		link := links[domain]
+		link.Visits = n
+		result = append(result, link)
+	}
+	sortDomainLinks(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func sortDomainLinks(links []domainLink) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Visits != links[j].Visits {
+			return links[i].Visits > links[j].Visits
+		}
+		return links[i].Domain < links[j].Domain
+	})
+}
+
+// noDomain labels visits without a domain, such as file:// URLs
+const noDomain = "(none)"
+
+// domainSlug turns a domain into a safe file name
+func domainSlug(domain string) string {
+	if domain == noDomain {
+		return "_none"
+	}
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, domain)
+	if strings.Trim(slug, "._") == "" {
+		return "_"
+	}
+	return slug
+}
+
+// dayLabel formats a YYYY-MM-DD date for headings
+func dayLabel(day string) string {
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return day
+	}
+	return t.Format("Monday, January 2, 2006")
+}
+
+func writePage(path, name string, data interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer f.Close()
+
+	if err := templates.ExecuteTemplate(f, name, data); err != nil {
+		return fmt.Errorf("failed to render %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: base, URL: "https://go.dev/doc", Title: "Docs <Go>", Domain: "go.dev"},
+		{Timestamp: base.Add(5 * time.Minute), URL: "https://go.dev/blog", Title: "Blog", Domain: "go.dev"},
+		{Timestamp: base.Add(3 * time.Hour), URL: "https://news.example/a", Title: "Article", Domain: "news.example"},
+		{Timestamp: base.AddDate(0, 0, 1), URL: "https://go.dev/doc", Title: "Docs <Go>", Domain: "go.dev"},
+		{Timestamp: base.AddDate(0, 0, 1).Add(time.Minute), URL: "javascript:alert(1)", Title: "bad", Domain: ""},
+	}
+
+	result, err := Generate(dir, entries, Options{Title: "Journal", Location: time.UTC})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if result.Days != 2 || result.Domains != 3 || result.Pages != 4 {
+		t.Errorf("result = %+v, want 2 days, 3 domains, 4 pages", result)
+	}
+
+	for _, name := range []string{
+		"index.html", "domains.html", "search.html", "search-index.js", "style.css",
+		"day/2026-03-02.html", "day/2026-03-03.html",
+		"domain/go.dev.html", "domain/news.example.html", "domain/_none.html",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+
+	day := readFile(t, filepath.Join(dir, "day/2026-03-02.html"))
+	for _, want := range []string{
+		"Monday, March 2, 2026",
+		"3 visits · 2 sessions",
+		"Docs &lt;Go&gt;",
+		`href="../domain/go.dev.html"`,
+		`href="2026-03-03.html"`,
+	} {
+		if !strings.Contains(day, want) {
+			t.Errorf("day page missing %q", want)
+		}
+	}
+
+	next := readFile(t, filepath.Join(dir, "day/2026-03-03.html"))
+	if strings.Contains(next, `href="javascript:`) {
+		t.Error("javascript: URL rendered as a link")
+	}
+
+	domain := readFile(t, filepath.Join(dir, "domain/go.dev.html"))
+	if !strings.Contains(domain, "3 visits · 2 days") {
+		t.Errorf("domain page missing summary:\n%s", domain)
+	}
+	// Most recent day first
+	if strings.Index(domain, "Tuesday, March 3") > strings.Index(domain, "Monday, March 2") {
+		t.Error("domain page days not newest first")
+	}
+
+	index := readFile(t, filepath.Join(dir, "search-index.js"))
+	if !strings.HasPrefix(index, "window.WEB_RECAP_INDEX = [") || !strings.Contains(index, `"n":2`) {
+		t.Errorf("unexpected search index: %s", index)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
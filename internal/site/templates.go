@@ -0,0 +1,150 @@
+package site
+
+import "html/template"
+
+// entryList is the data for the shared "entries" template
+type entryList struct {
+	Root    string
+	Entries []pageEntry
+}
+
+var templates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"entries": func(root string, entries []pageEntry) entryList {
+		return entryList{Root: root, Entries: entries}
+	},
+}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.PageTitle}} · {{.SiteTitle}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<header>
+  <a class="brand" href="{{.Root}}index.html">{{.SiteTitle}}</a>
+  <nav><a href="{{.Root}}index.html">Days</a> <a href="{{.Root}}domains.html">Domains</a> <a href="{{.Root}}search.html">Search</a></nav>
+</header>
+<main>
+{{end}}
+
+{{define "footer"}}</main>
+<footer>Generated by web-recap</footer>
+</body>
+</html>
+{{end}}
+
+{{define "entries"}}<ul class="entries">
+{{range .Entries}}<li><time>{{.Time}}</time> <a href="{{.URL}}" rel="noreferrer">{{.Title}}</a> <a class="domain" href="{{$.Root}}domain/{{.DomainSlug}}.html">{{.Domain}}</a></li>
+{{end}}</ul>
+{{end}}
+
+{{define "index"}}{{template "header" .}}
+<h1>{{.SiteTitle}}</h1>
+{{if .Days}}<p class="meta">{{.TotalVisits}} visits over {{len .Days}} days, {{.FirstDay}} – {{.LastDay}}</p>
+<table class="days">
+<thead><tr><th>Day</th><th class="num">Visits</th><th>Top domains</th></tr></thead>
+<tbody>
+{{range .Days}}<tr><td><a href="day/{{.Date}}.html">{{.Label}}</a></td><td class="num">{{.Visits}}</td><td>{{range $i, $d := .TopDomains}}{{if $i}}, {{end}}<a href="domain/{{$d.Slug}}.html">{{$d.Domain}}</a>{{end}}</td></tr>
+{{end}}</tbody>
+</table>
+{{else}}<p>No history in this range.</p>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "domains"}}{{template "header" .}}
+<h1>Domains</h1>
+<table class="days">
+<thead><tr><th>Domain</th><th class="num">Visits</th><th class="num">Days</th><th>Last seen</th></tr></thead>
+<tbody>
+{{range .Domains}}<tr><td><a href="domain/{{.Slug}}.html">{{.Domain}}</a></td><td class="num">{{.Visits}}</td><td class="num">{{.ActiveDays}}</td><td><a href="day/{{.LastSeen}}.html">{{.LastSeen}}</a></td></tr>
+{{end}}</tbody>
+</table>
+{{template "footer"}}{{end}}
+
+{{define "day"}}{{template "header" .}}
+<p class="pager">{{if .Prev}}<a href="{{.Prev}}.html">← {{.Prev}}</a>{{end}} {{if .Next}}<a href="{{.Next}}.html">{{.Next}} →</a>{{end}}</p>
+<h1>{{.Label}}</h1>
+<p class="meta">{{.Visits}} visits · {{len .Sessions}} sessions · {{range $i, $d := .TopDomains}}{{if $i}}, {{end}}<a href="../domain/{{$d.Slug}}.html">{{$d.Domain}}</a> ({{$d.Visits}}){{end}}</p>
+{{range .Sessions}}<section>
+<h2>{{.Start}} – {{.End}} <span class="meta">{{len .Entries}} visits</span></h2>
+{{template "entries" (entries $.Root .Entries)}}</section>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "domain"}}{{template "header" .}}
+<h1>{{.Domain}}</h1>
+<p class="meta">{{.Visits}} visits · {{len .Days}} days · first seen {{.FirstSeen}} · last seen {{.LastSeen}}</p>
+{{range .Days}}<section>
+<h2><a href="../day/{{.Date}}.html">{{.Label}}</a> <span class="meta">{{len .Entries}} visits</span></h2>
+{{template "entries" (entries $.Root .Entries)}}</section>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "search"}}{{template "header" .}}
+<h1>Search</h1>
+<input id="q" type="search" placeholder="Search titles and URLs" autofocus>
+<p id="count" class="meta"></p>
+<ul id="results" class="entries"></ul>
+<script src="search-index.js"></script>
+<script>
+(function () {
+  var index = window.WEB_RECAP_INDEX || [];
+  var input = document.getElementById("q");
+  var results = document.getElementById("results");
+  var count = document.getElementById("count");
+  for (var i = 0; i < index.length; i++) {
+    index[i].s = ((index[i].t || "") + " " + index[i].u).toLowerCase();
+  }
+  function el(tag, text, attrs) {
+    var e = document.createElement(tag);
+    if (text) e.textContent = text;
+    for (var k in attrs || {}) e.setAttribute(k, attrs[k]);
+    return e;
+  }
+  function run() {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.textContent = "";
+    if (!terms.length) { count.textContent = index.length + " pages indexed"; return; }
+    var hits = index.filter(function (item) {
+      return terms.every(function (t) { return item.s.indexOf(t) !== -1; });
+    });
+    hits.sort(function (a, b) { return b.n - a.n || (a.l < b.l ? 1 : -1); });
+    count.textContent = hits.length + " matches";
+    hits.slice(0, 200).forEach(function (item) {
+      var li = el("li");
+      li.appendChild(el("a", item.l, { href: "day/" + item.l + ".html", class: "date" }));
+      li.appendChild(document.createTextNode(" "));
+      var safe = /^(https?|ftp):/i.test(item.u);
+      li.appendChild(el("a", item.t || item.u, safe ? { href: item.u, rel: "noreferrer" } : {}));
+      li.appendChild(document.createTextNode(" "));
+      li.appendChild(el("span", item.d + " · " + item.n + " visits", { class: "domain" }));
+      results.appendChild(li);
+    });
+  }
+  input.addEventListener("input", run);
+  if (location.hash.length > 1) input.value = decodeURIComponent(location.hash.slice(1));
+  run();
+})();
+</script>
+{{template "footer"}}{{end}}
+`))
+
+const styleCSS = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 860px; margin: 0 auto; padding: 0 16px; color: #222; line-height: 1.45; }
+header { display: flex; justify-content: space-between; align-items: baseline; border-bottom: 1px solid #ddd; padding: 12px 0; }
+header .brand { font-weight: 600; color: #222; text-decoration: none; }
+nav a { margin-left: 12px; }
+a { color: #1a56db; }
+h1 { font-size: 24px; margin-bottom: 4px; }
+h2 { font-size: 16px; margin: 20px 0 6px; }
+.meta { color: #666; font-size: 14px; font-weight: normal; }
+.pager { display: flex; justify-content: space-between; font-size: 14px; }
+table.days { border-collapse: collapse; width: 100%; }
+table.days th, table.days td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #eee; }
+table.days .num { text-align: right; }
+ul.entries { list-style: none; padding: 0; margin: 0; }
+ul.entries li { padding: 3px 0; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+ul.entries time, ul.entries .date { color: #666; font-variant-numeric: tabular-nums; margin-right: 6px; }
+ul.entries .domain { color: #888; font-size: 13px; margin-left: 6px; }
+input[type=search] { width: 100%; font-size: 16px; padding: 8px; box-sizing: border-box; }
+footer { color: #999; font-size: 12px; border-top: 1px solid #ddd; margin-top: 32px; padding: 12px 0; }
+`
@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Aliases rolls related hosts up into the service they belong to, so that
+// github.com, gist.github.com, and raw.githubusercontent.com all count as
+// github. The zero value leaves every domain as it is.
+type Aliases struct {
+	exact  map[string]string
+	suffix map[string]string
+}
+
+// NewAliases builds aliases from the config file's rules: each service
+// name lists host patterns, either a host such as "gist.github.com" or
+// "*.google.com" for a domain and all its subdomains. A leading "www." is
+// ignored, as it is for domains.
+func NewAliases(rules map[string][]string) (Aliases, error) {
+	a := Aliases{exact: make(map[string]string), suffix: make(map[string]string)}
+
+	// Check services in order so a pattern listed twice always names the same two
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return Aliases{}, fmt.Errorf("alias with an empty service name")
+		}
+		for _, pattern := range rules[name] {
+			host, table := strings.TrimSpace(pattern), a.exact
+			if rest, ok := strings.CutPrefix(host, "*."); ok {
+				host, table = rest, a.suffix
+			}
+			host = siteKey(host)
+			if host == "" || strings.Contains(host, "*") {
+				return Aliases{}, fmt.Errorf("invalid alias pattern %q for %s (use a host such as gist.github.com, or *.google.com)", pattern, name)
+			}
+			if other, ok := table[host]; ok && other != name {
+				return Aliases{}, fmt.Errorf("alias pattern %q is listed under both %s and %s", pattern, other, name)
+			}
+			table[host] = name
+		}
+	}
+	return a, nil
+}
+
+// Of returns the service domain rolls up into, or domain itself when no
+// rule matches. A host listed exactly wins over a wildcard, and a more
+// specific wildcard over a broader one.
+func (a Aliases) Of(domain string) string {
+	if len(a.exact) == 0 && len(a.suffix) == 0 {
+		return domain
+	}
+	host := siteKey(domain)
+	if name, ok := a.exact[host]; ok {
+		return name
+	}
+	for host != "" {
+		if name, ok := a.suffix[host]; ok {
+			return name
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return domain
+}
+
+// Apply replaces the domain of each entry with the service it rolls up into
+func (a Aliases) Apply(entries []models.HistoryEntry) {
+	if len(a.exact) == 0 && len(a.suffix) == 0 {
+		return
+	}
+	for i := range entries {
+		entries[i].Domain = a.Of(entries[i].Domain)
+	}
+}
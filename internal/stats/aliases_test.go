@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestAliases(t *testing.T) {
+	a, err := NewAliases(map[string][]string{
+		"github":   {"github.com", "gist.github.com", "raw.githubusercontent.com"},
+		"google":   {"*.google.com"},
+		"calendar": {"calendar.google.com"},
+		"aws":      {"*.aws.amazon.com", "*.amazonaws.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"github.com":                 "github",
+		"www.github.com":             "github",
+		"gist.github.com":            "github",
+		"raw.githubusercontent.com":  "github",
+		"api.github.com":             "api.github.com", // not listed, and no wildcard
+		"google.com":                 "google",
+		"mail.google.com":            "google",
+		"calendar.google.com":        "calendar", // exact host wins over the wildcard
+		"google.co.uk":               "google.co.uk",
+		"console.aws.amazon.com":     "aws",
+		"amazon.com":                 "amazon.com",
+		"s3.us-east-1.amazonaws.com": "aws",
+		"":                           "",
+	}
+	for domain, want := range tests {
+		if got := a.Of(domain); got != want {
+			t.Errorf("Of(%q) = %q, want %q", domain, got, want)
+		}
+	}
+
+	entries := []models.HistoryEntry{{Domain: "gist.github.com"}, {Domain: "example.com"}}
+	a.Apply(entries)
+	if entries[0].Domain != "github" || entries[1].Domain != "example.com" {
+		t.Errorf("Apply: domains = %q, %q", entries[0].Domain, entries[1].Domain)
+	}
+
+	var none Aliases
+	if got := none.Of("www.github.com"); got != "www.github.com" {
+		t.Errorf("zero Aliases: Of = %q, want the domain unchanged", got)
+	}
+}
+
+func TestNewAliasesInvalid(t *testing.T) {
+	for _, rules := range []map[string][]string{
+		{"google": {"mail.*.com"}},
+		{"google": {""}},
+		{"": {"google.com"}},
+		{"a": {"*.example.com"}, "b": {"*.example.com"}},
+	} {
+		if _, err := NewAliases(rules); err == nil {
+			t.Errorf("NewAliases(%v) succeeded, want an error", rules)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// DefaultCategories are the sites in each built-in category. A category in
+// the config file replaces the built-in one of the same name.
+var DefaultCategories = map[string][]string{
+	"social": {
+		"facebook.com", "instagram.com", "twitter.com", "x.com", "reddit.com",
+		"tiktok.com", "linkedin.com", "threads.net", "bsky.app", "mastodon.social",
+		"pinterest.com", "tumblr.com",
+	},
+	"video": {
+		"youtube.com", "netflix.com", "twitch.tv", "vimeo.com", "primevideo.com",
+		"disneyplus.com", "hulu.com",
+	},
+	"news": {
+		"news.ycombinator.com", "nytimes.com", "theguardian.com", "bbc.com",
+		"bbc.co.uk", "cnn.com", "reuters.com", "washingtonpost.com", "apnews.com",
+	},
+	"shopping": {
+		"amazon.com", "ebay.com", "etsy.com", "aliexpress.com", "walmart.com",
+	},
+}
+
+// Categories maps sites to the category they belong to. A site covers its
+// subdomains, and the most specific site listed wins.
+type Categories map[string]string
+
+// NewCategories builds the categories from DefaultCategories and the
+// categories from the config file, which replace built-in ones of the same
+// name
+func NewCategories(custom map[string][]string) Categories {
+	merged := make(map[string][]string, len(DefaultCategories)+len(custom))
+	for name, sites := range DefaultCategories {
+		merged[name] = sites
+	}
+	for name, sites := range custom {
+		merged[name] = sites
+	}
+
+	// Visit names in order so a site listed twice always lands in the same one
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := make(Categories)
+	for _, name := range names {
+		for _, site := range merged[name] {
+			if site = siteKey(strings.TrimSpace(site)); site != "" {
+				if _, taken := c[site]; !taken {
+					c[site] = name
+				}
+			}
+		}
+	}
+	return c
+}
+
+// Of returns the category of domain, or "" when it has none
+func (c Categories) Of(domain string) string {
+	host := siteKey(domain)
+	for host != "" {
+		if name, ok := c[host]; ok {
+			return name
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return ""
+}
+
+// Has reports whether any site belongs to the category name
+func (c Categories) Has(name string) bool {
+	for _, n := range c {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseBudgets parses the daily budgets from the config file, a duration
+// such as "60m" or "1h30m" per category
+func ParseBudgets(budgets map[string]string) (map[string]time.Duration, error) {
+	parsed := make(map[string]time.Duration, len(budgets))
+	for name, value := range budgets {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid budget %q for %s (use a duration such as 60m or 1h30m)", value, name)
+		}
+		parsed[name] = d
+	}
+	return parsed, nil
+}
+
+// CheckBudgets returns the time spent in each budgeted category on every day
+// of [start, end) in loc that has any, oldest first. A visit's time is the
+// foreground time its browser recorded, or else the gap until the next visit
+// unless that exceeds idle, and counts toward the day the visit was on.
+func CheckBudgets(entries []models.HistoryEntry, start, end time.Time, loc *time.Location, idle time.Duration, categories Categories, budgets map[string]time.Duration) []models.BudgetDay {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if idle <= 0 {
+		idle = DefaultIdleGap
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() && !e.Timestamp.Before(start) && e.Timestamp.Before(end) {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	spent := make(map[int]map[string]time.Duration)
+	for i, e := range sorted {
+		category := categories.Of(e.Domain)
+		if _, budgeted := budgets[category]; !budgeted {
+			continue
+		}
+		t := timeSpent(sorted, i, idle)
+		if t <= 0 {
+			continue
+		}
+		day := dayNumber(e.Timestamp, loc)
+		if spent[day] == nil {
+			spent[day] = make(map[string]time.Duration)
+		}
+		spent[day][category] += t
+	}
+
+	dayNumbers := make([]int, 0, len(spent))
+	for day := range spent {
+		dayNumbers = append(dayNumbers, day)
+	}
+	sort.Ints(dayNumbers)
+
+	names := make([]string, 0, len(budgets))
+	for name := range budgets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	days := make([]models.BudgetDay, 0, len(dayNumbers))
+	for _, day := range dayNumbers {
+		d := models.BudgetDay{Date: time.Unix(int64(day)*86400, 0).UTC().Format("2006-01-02")}
+		for _, name := range names {
+			t, ok := spent[day][name]
+			if !ok {
+				continue
+			}
+			d.Categories = append(d.Categories, models.CategoryTime{
+				Category:      name,
+				Minutes:       roundTenth(t.Minutes()),
+				BudgetMinutes: roundTenth(budgets[name].Minutes()),
+				Over:          t > budgets[name],
+			})
+		}
+		days = append(days, d)
+	}
+	return days
+}
@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCategoriesOf(t *testing.T) {
+	c := NewCategories(map[string][]string{
+		"social": {"reddit.com"},
+		"work":   {"github.com", "gist.github.com"},
+		"gists":  {"gist.github.com"},
+	})
+	tests := map[string]string{
+		"www.reddit.com":  "social",
+		"old.reddit.com":  "social",
+		"twitter.com":     "", // the config replaced the built-in social list
+		"youtube.com":     "video",
+		"github.com":      "work",
+		"gist.github.com": "gists", // listed twice: the first name in order wins
+		"api.github.com":  "work",
+		"notgithub.com":   "",
+		"":                "",
+		"m.youtube.com":   "video",
+	}
+	for domain, want := range tests {
+		if got := c.Of(domain); got != want {
+			t.Errorf("Of(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets(map[string]string{"social": "60m", "video": "1h30m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budgets["social"] != time.Hour || budgets["video"] != 90*time.Minute {
+		t.Errorf("budgets = %v", budgets)
+	}
+	for _, bad := range []string{"60", "-5m", "0s", "soon"} {
+		if _, err := ParseBudgets(map[string]string{"social": bad}); err == nil {
+			t.Errorf("ParseBudgets(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	day1 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	at := func(day time.Time, minutes int) time.Time { return day.Add(time.Duration(minutes) * time.Minute) }
+	entries := []models.HistoryEntry{
+		// Day 1: 8 minutes of social in gaps, then 5 on youtube
+		{Timestamp: at(day1, 0), Domain: "reddit.com"},
+		{Timestamp: at(day1, 8), Domain: "www.youtube.com", Duration: 5 * time.Minute},
+		{Timestamp: at(day1, 20), Domain: "twitter.com"},
+		// Day 2: 3 minutes of social, and unbudgeted news
+		{Timestamp: at(day2, 0), Domain: "x.com", Duration: 3 * time.Minute},
+		{Timestamp: at(day2, 1), Domain: "nytimes.com"},
+		{Timestamp: at(day2, 5), Domain: "go.dev"},
+	}
+	categories := NewCategories(nil)
+	budgets := map[string]time.Duration{"social": 5 * time.Minute, "video": 10 * time.Minute}
+
+	days := CheckBudgets(entries, day1.Add(-9*time.Hour), day2.Add(15*time.Hour), time.UTC, 10*time.Minute, categories, budgets)
+	want := []models.BudgetDay{
+		{Date: "2026-03-02", Categories: []models.CategoryTime{
+			{Category: "social", Minutes: 8, BudgetMinutes: 5, Over: true},
+			{Category: "video", Minutes: 5, BudgetMinutes: 10},
+		}},
+		{Date: "2026-03-03", Categories: []models.CategoryTime{
+			{Category: "social", Minutes: 3, BudgetMinutes: 5},
+		}},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("days = %+v, want %+v", days, want)
+	}
+	for i := range want {
+		if days[i].Date != want[i].Date || len(days[i].Categories) != len(want[i].Categories) {
+			t.Fatalf("days[%d] = %+v, want %+v", i, days[i], want[i])
+		}
+		for j := range want[i].Categories {
+			if days[i].Categories[j] != want[i].Categories[j] {
+				t.Errorf("days[%d].Categories[%d] = %+v, want %+v", i, j, days[i].Categories[j], want[i].Categories[j])
+			}
+		}
+	}
+}
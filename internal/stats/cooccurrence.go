@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// DomainPairs finds the domains used together in the same session, where a
+// session ends after more than gap without a visit. Domains differing only
+// by "www." are one domain. Pairs that shared fewer than minSessions
+// sessions are left out. The rest are ordered by lift, then by sessions
+// together, along with the number of sessions read.
+func DomainPairs(entries []models.HistoryEntry, gap time.Duration, minSessions int) ([]models.DomainPair, int) {
+	sessions := splitSessions(entries, gap)
+	domainSessions := make(map[string]int)
+	together := make(map[[2]string]int)
+	for _, visits := range sessions {
+		seen := make(map[string]bool)
+		var domains []string
+		for _, e := range visits {
+			if d := siteKey(e.Domain); d != "" && !seen[d] {
+				seen[d] = true
+				domains = append(domains, d)
+			}
+		}
+		sort.Strings(domains)
+		for i, a := range domains {
+			domainSessions[a]++
+			for _, b := range domains[i+1:] {
+				together[[2]string{a, b}]++
+			}
+		}
+	}
+
+	n := float64(len(sessions))
+	var pairs []models.DomainPair
+	for key, count := range together {
+		if count < minSessions {
+			continue
+		}
+		a, b := domainSessions[key[0]], domainSessions[key[1]]
+		pairs = append(pairs, models.DomainPair{
+			Domains:   key,
+			Sessions:  count,
+			SessionsA: a,
+			SessionsB: b,
+			Lift:      math.Round(float64(count)*n/float64(a*b)*100) / 100,
+		})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		p, q := pairs[i], pairs[j]
+		if p.Lift != q.Lift {
+			return p.Lift > q.Lift
+		}
+		if p.Sessions != q.Sessions {
+			return p.Sessions > q.Sessions
+		}
+		if p.Domains[0] != q.Domains[0] {
+			return p.Domains[0] < q.Domains[0]
+		}
+		return p.Domains[1] < q.Domains[1]
+	})
+	return pairs, len(sessions)
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestDomainPairs(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	var entries []models.HistoryEntry
+	// Four sessions two hours apart, a few minutes between visits in each
+	for i, domains := range [][]string{
+		{"github.com", "stackoverflow.com", "docs.rs"},
+		{"www.github.com", "docs.rs", "github.com"},
+		{"github.com", "news.example"},
+		{"news.example", "weather.example"},
+	} {
+		for j, d := range domains {
+			entries = append(entries, models.HistoryEntry{Domain: d, Timestamp: base.Add(time.Duration(i)*2*time.Hour + time.Duration(j)*5*time.Minute)})
+		}
+	}
+
+	pairs, sessions := DomainPairs(entries, 30*time.Minute, 1)
+	if sessions != 4 {
+		t.Fatalf("sessions = %d, want 4", sessions)
+	}
+	byDomains := make(map[[2]string]models.DomainPair)
+	for _, p := range pairs {
+		byDomains[p.Domains] = p
+	}
+	if len(byDomains) != 5 {
+		t.Errorf("got %d pairs, want 5: %+v", len(byDomains), pairs)
+	}
+
+	// docs.rs is in 2 of 4 sessions, github.com (with www.) in 3, together in 2
+	p := byDomains[[2]string{"docs.rs", "github.com"}]
+	if p.Sessions != 2 || p.SessionsA != 2 || p.SessionsB != 3 || p.Lift != 1.33 {
+		t.Errorf("docs.rs + github.com = %+v, want 2 sessions, lift 1.33", p)
+	}
+	if first := pairs[0]; first.Domains != [2]string{"docs.rs", "stackoverflow.com"} || first.Lift != 2 {
+		t.Errorf("first pair = %+v, want docs.rs + stackoverflow.com with the highest lift", first)
+	}
+
+	if pairs, _ := DomainPairs(entries, 30*time.Minute, 2); len(pairs) != 1 {
+		t.Errorf("with minSessions 2 got %d pairs, want 1", len(pairs))
+	}
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// DailyActivity returns the visits on each day of [start, end) in loc, oldest
+// first, with every day included. Minutes are estimated as EstimateSiteTime
+// does: the gap after a visit counts toward the visit's day unless it
+// exceeds idle.
+func DailyActivity(entries []models.HistoryEntry, start, end time.Time, loc *time.Location, idle time.Duration) []models.DailyActivity {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if idle <= 0 {
+		idle = DefaultIdleGap
+	}
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		return nil
+	}
+
+	first := dayNumber(start, loc)
+	last := dayNumber(end.Add(-time.Nanosecond), loc)
+	days := make([]models.DailyActivity, last-first+1)
+	for i := range days {
+		days[i].Date = time.Unix(int64(first+i)*86400, 0).UTC().Format("2006-01-02")
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() && !e.Timestamp.Before(start) && e.Timestamp.Before(end) {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	minutes := make([]time.Duration, len(days))
+	for i, e := range sorted {
+		day := dayNumber(e.Timestamp, loc) - first
+		days[day].Visits++
+		if i+1 < len(sorted) {
+			if gap := sorted[i+1].Timestamp.Sub(e.Timestamp); gap <= idle {
+				minutes[day] += gap
+			}
+		}
+	}
+	for i := range days {
+		days[i].EstimatedMinutes = roundTenth(minutes[i].Minutes())
+	}
+	return days
+}
@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestDailyActivity(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*3600)
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 3)
+	at := func(day, hour, minute int) models.HistoryEntry {
+		return models.HistoryEntry{Timestamp: time.Date(2025, 3, 1+day, hour, minute, 0, 0, loc).UTC()}
+	}
+	entries := []models.HistoryEntry{
+		at(0, 9, 0),
+		at(0, 9, 4),  // 4 minutes after the first
+		at(0, 23, 0), // an hour later is time away
+		at(2, 1, 0),  // 01:00 local is the previous day in UTC
+		at(3, 9, 0),  // past the end
+	}
+
+	days := DailyActivity(entries, start, end, loc, 10*time.Minute)
+	want := []models.DailyActivity{
+		{Date: "2025-03-01", Visits: 3, EstimatedMinutes: 4},
+		{Date: "2025-03-02", Visits: 0},
+		{Date: "2025-03-03", Visits: 1},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("got %d days, want %d", len(days), len(want))
+	}
+	for i := range want {
+		if days[i] != want[i] {
+			t.Errorf("day %d = %+v, want %+v", i, days[i], want[i])
+		}
+	}
+}
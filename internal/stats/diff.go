@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// DiffHistory compares the visits of two history reports, filling in the
+// entry and domain counts of each side. Visits match by
+// ID, or by browser, URL, and time when a report has no IDs. DomainDeltas
+// holds the domains whose visit count changed, largest change first; top
+// limits how many are kept, and 0 keeps all.
+func DiffHistory(old, new []models.HistoryEntry, top int) models.ExportDiff {
+	key := func(e models.HistoryEntry) string {
+		if e.ID != "" {
+			return e.ID
+		}
+		return e.Browser + "\x00" + e.URL + "\x00" + strconv.FormatInt(e.Timestamp.UnixMicro(), 10)
+	}
+	oldKeys, newKeys := make(map[string]bool), make(map[string]bool)
+	oldDomains, newDomains := make(map[string]int), make(map[string]int)
+	for _, e := range old {
+		oldKeys[key(e)] = true
+		oldDomains[e.Domain]++
+	}
+	for _, e := range new {
+		newKeys[key(e)] = true
+		newDomains[e.Domain]++
+	}
+
+	diff := models.ExportDiff{Kind: "history", EntriesDelta: len(new) - len(old)}
+	for k := range newKeys {
+		if !oldKeys[k] {
+			diff.Added++
+		}
+	}
+	for k := range oldKeys {
+		if !newKeys[k] {
+			diff.Removed++
+		}
+	}
+	diffDomains(&diff, oldDomains, newDomains, top)
+	diff.Old = models.DiffSide{Entries: len(old), Domains: len(oldDomains)}
+	diff.New = models.DiffSide{Entries: len(new), Domains: len(newDomains)}
+	return diff
+}
+
+// DiffBookmarks compares two bookmark reports. Bookmarks match by browser
+// and ID, or by browser and URL when they have no ID; with byURL they match
+// by URL alone, which compares bookmarks across browsers. A matched bookmark
+// in another folder is reported as moved.
+func DiffBookmarks(old, new []models.BookmarkEntry, byURL bool, top int) models.ExportDiff {
+	key := func(e models.BookmarkEntry) string {
+		switch {
+		case byURL:
+			return e.URL
+		case e.ID != "":
+			return e.Browser + "\x00id\x00" + e.ID
+		default:
+			return e.Browser + "\x00url\x00" + e.URL
+		}
+	}
+	first := func(entries []models.BookmarkEntry) (map[string]models.BookmarkEntry, map[string]int) {
+		byKey := make(map[string]models.BookmarkEntry)
+		domains := make(map[string]int)
+		for _, e := range entries {
+			if _, ok := byKey[key(e)]; !ok {
+				byKey[key(e)] = e
+			}
+			domains[e.Domain]++
+		}
+		return byKey, domains
+	}
+	oldByKey, oldDomains := first(old)
+	newByKey, newDomains := first(new)
+
+	diff := models.ExportDiff{Kind: "bookmarks", EntriesDelta: len(new) - len(old)}
+	// Walk the reports rather than the maps to keep their order
+	seen := make(map[string]bool)
+	for _, e := range new {
+		k := key(e)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		before, ok := oldByKey[k]
+		switch {
+		case !ok:
+			diff.AddedEntries = append(diff.AddedEntries, e)
+		case before.Folder != e.Folder:
+			diff.Moved = append(diff.Moved, models.BookmarkMove{
+				URL: e.URL, Title: e.Title, Browser: e.Browser, From: before.Folder, To: e.Folder,
+			})
+		}
+	}
+	for _, e := range old {
+		k := key(e)
+		if _, ok := newByKey[k]; !ok && !seen[k] {
+			seen[k] = true
+			diff.RemovedEntries = append(diff.RemovedEntries, e)
+		}
+	}
+	diff.Added, diff.Removed = len(diff.AddedEntries), len(diff.RemovedEntries)
+	diffDomains(&diff, oldDomains, newDomains, top)
+	diff.Old = models.DiffSide{Entries: len(old), Domains: len(oldDomains)}
+	diff.New = models.DiffSide{Entries: len(new), Domains: len(newDomains)}
+	return diff
+}
+
+// diffDomains fills in the domains found in only one report and the domains
+// whose entry count changed
+func diffDomains(diff *models.ExportDiff, old, new map[string]int, top int) {
+	for domain, n := range new {
+		if old[domain] == 0 {
+			diff.NewDomains = append(diff.NewDomains, domain)
+		}
+		if n != old[domain] {
+			diff.DomainDeltas = append(diff.DomainDeltas, models.DomainDelta{Domain: domain, Old: old[domain], New: n, Delta: n - old[domain]})
+		}
+	}
+	for domain, n := range old {
+		if new[domain] == 0 {
+			diff.RemovedDomains = append(diff.RemovedDomains, domain)
+			diff.DomainDeltas = append(diff.DomainDeltas, models.DomainDelta{Domain: domain, Old: n, Delta: -n})
+		}
+	}
+	sort.Strings(diff.NewDomains)
+	sort.Strings(diff.RemovedDomains)
+	sort.Slice(diff.DomainDeltas, func(i, j int) bool {
+		a, b := diff.DomainDeltas[i], diff.DomainDeltas[j]
+		if abs(a.Delta) != abs(b.Delta) {
+			return abs(a.Delta) > abs(b.Delta)
+		}
+		return a.Domain < b.Domain
+	})
+	if top > 0 && len(diff.DomainDeltas) > top {
+		diff.DomainDeltas = diff.DomainDeltas[:top]
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
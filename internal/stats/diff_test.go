@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestDiffHistory(t *testing.T) {
+	ts := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	old := []models.HistoryEntry{
+		{ID: "1", URL: "https://go.dev/", Domain: "go.dev", Timestamp: ts},
+		{ID: "2", URL: "https://old.example/", Domain: "old.example", Timestamp: ts},
+	}
+	new := []models.HistoryEntry{
+		{ID: "1", URL: "https://go.dev/", Domain: "go.dev", Timestamp: ts},
+		{ID: "3", URL: "https://go.dev/doc", Domain: "go.dev", Timestamp: ts},
+		{URL: "https://new.example/", Domain: "new.example", Timestamp: ts},
+	}
+
+	diff := DiffHistory(old, new, 0)
+	if diff.Added != 2 || diff.Removed != 1 || diff.EntriesDelta != 1 {
+		t.Errorf("added %d, removed %d, delta %d", diff.Added, diff.Removed, diff.EntriesDelta)
+	}
+	if strings.Join(diff.NewDomains, ",") != "new.example" || strings.Join(diff.RemovedDomains, ",") != "old.example" {
+		t.Errorf("new domains %v, removed domains %v", diff.NewDomains, diff.RemovedDomains)
+	}
+	var deltas []string
+	for _, d := range diff.DomainDeltas {
+		deltas = append(deltas, d.Domain)
+	}
+	if strings.Join(deltas, ",") != "go.dev,new.example,old.example" {
+		t.Errorf("domain deltas %v", deltas)
+	}
+	if diff.Old.Domains != 2 || diff.New.Entries != 3 {
+		t.Errorf("sides %+v %+v", diff.Old, diff.New)
+	}
+	if got := DiffHistory(old, new, 1).DomainDeltas; len(got) != 1 {
+		t.Errorf("top 1 kept %d deltas", len(got))
+	}
+}
+
+func TestDiffBookmarks(t *testing.T) {
+	old := []models.BookmarkEntry{
+		{ID: "a", URL: "https://go.dev/", Browser: "chrome", Folder: "Dev"},
+		{ID: "b", URL: "https://gone.example/", Browser: "chrome", Domain: "gone.example"},
+		{URL: "https://same.example/", Browser: "firefox", Folder: "Misc"},
+	}
+	new := []models.BookmarkEntry{
+		{ID: "a", URL: "https://go.dev/", Browser: "chrome", Folder: "Dev/Go"},
+		{URL: "https://same.example/", Browser: "firefox", Folder: "Misc"},
+		{ID: "c", URL: "https://same.example/", Browser: "chrome", Folder: "Misc"},
+	}
+
+	tests := []struct {
+		name                  string
+		byURL                 bool
+		added, removed, moved int
+	}{
+		{"by id", false, 1, 1, 1},
+		{"by url", true, 0, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffBookmarks(old, new, tt.byURL, 0)
+			if diff.Added != tt.added || diff.Removed != tt.removed || len(diff.Moved) != tt.moved {
+				t.Errorf("added %d, removed %d, moved %d", diff.Added, diff.Removed, len(diff.Moved))
+			}
+			if len(diff.Moved) > 0 && (diff.Moved[0].From != "Dev" || diff.Moved[0].To != "Dev/Go") {
+				t.Errorf("moved %+v", diff.Moved[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// BookmarkFolders lists every folder holding bookmarks, and the folders above
+// them, per browser, ordered by browser and then path. Bookmarks outside any
+// folder are not counted.
+func BookmarkFolders(entries []models.BookmarkEntry) []models.BookmarkFolderStats {
+	type key struct{ browser, path string }
+	folders := make(map[key]*models.BookmarkFolderStats)
+
+	for _, e := range entries {
+		if e.Folder == "" {
+			continue
+		}
+		names := strings.Split(e.Folder, "/")
+		for i := range names {
+			k := key{e.Browser, strings.Join(names[:i+1], "/")}
+			f := folders[k]
+			if f == nil {
+				f = &models.BookmarkFolderStats{Browser: k.browser, Path: k.path}
+				folders[k] = f
+			}
+			f.Total++
+			if i == len(names)-1 {
+				f.Bookmarks++
+			}
+			addDate(f, e.DateAdded)
+		}
+	}
+
+	result := make([]models.BookmarkFolderStats, 0, len(folders))
+	for _, f := range folders {
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Browser != result[j].Browser {
+			return result[i].Browser < result[j].Browser
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// addDate widens the folder's date range to include t
+func addDate(f *models.BookmarkFolderStats, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if f.FirstAdded == nil || t.Before(*f.FirstAdded) {
+		f.FirstAdded = &t
+	}
+	if f.LastAdded == nil || t.After(*f.LastAdded) {
+		f.LastAdded = &t
+	}
+}
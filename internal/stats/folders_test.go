@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestBookmarkFolders(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	entries := []models.BookmarkEntry{
+		{Folder: "Bar/Go", Browser: "chrome", DateAdded: day(3)},
+		{Folder: "Bar", Browser: "chrome", DateAdded: day(5)},
+		{Folder: "Bar/Go", Browser: "chrome", DateAdded: day(1)},
+		{Folder: "Bar", Browser: "firefox"},
+		{Browser: "firefox", DateAdded: day(9)},
+	}
+
+	var got []string
+	for _, f := range BookmarkFolders(entries) {
+		line := fmt.Sprintf("%s %s %d/%d", f.Browser, f.Path, f.Bookmarks, f.Total)
+		if f.FirstAdded != nil {
+			line += " " + f.FirstAdded.Format("01-02") + ".." + f.LastAdded.Format("01-02")
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"chrome Bar 1/3 03-01..03-05",
+		"chrome Bar/Go 2/2 03-01..03-03",
+		"firefox Bar 1/1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("folders:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,211 @@
+package stats
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/urlutil"
+)
+
+// DefaultWordsPerMinute is the reading speed used to turn a page's word
+// count into reading time
+const DefaultWordsPerMinute = 230
+
+// appHosts are web apps: time on them is work in a tool, not reading, even
+// on paths that look like documents
+var appHosts = []string{
+	"mail.google.com",
+	"calendar.google.com",
+	"docs.google.com",
+	"drive.google.com",
+	"meet.google.com",
+	"outlook.live.com",
+	"outlook.office.com",
+	"teams.microsoft.com",
+	"app.slack.com",
+	"discord.com",
+	"web.whatsapp.com",
+	"figma.com",
+	"github.com",
+	"gitlab.com",
+	"notion.so",
+	"linear.app",
+	"trello.com",
+	"chatgpt.com",
+}
+
+// contentHosts are sites whose pages are read, whatever their path
+var contentHosts = []string{
+	"wikipedia.org",
+	"medium.com",
+	"substack.com",
+	"dev.to",
+	"news.ycombinator.com",
+	"lobste.rs",
+}
+
+// contentHostPrefixes mark subdomains that hold documentation or articles
+var contentHostPrefixes = []string{"blog.", "docs.", "developer.", "developers.", "news.", "wiki."}
+
+// contentSegments are path segments under which sites keep articles and
+// documentation
+var contentSegments = map[string]bool{
+	"article": true, "articles": true, "blog": true, "blogs": true, "doc": true,
+	"docs": true, "documentation": true, "guide": true, "guides": true,
+	"learn": true, "manual": true, "news": true, "p": true, "post": true,
+	"posts": true, "reference": true, "stories": true, "story": true,
+	"tutorial": true, "tutorials": true, "wiki": true,
+}
+
+// ContentLike reports whether rawURL looks like a page to read (a news
+// story, blog post, or documentation page) rather than an app, a search,
+// or a site's front page. It goes by the host and path alone.
+func ContentLike(rawURL string) bool {
+	if urlutil.Internal(rawURL) {
+		return false
+	}
+	if _, _, ok := ParseSearchURL(rawURL); ok {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if matchesHost(host, appHosts) {
+		return false
+	}
+	if matchesHost(host, contentHosts) {
+		return true
+	}
+
+	segments := strings.FieldsFunc(strings.ToLower(u.Path), func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return false
+	}
+	for _, prefix := range contentHostPrefixes {
+		if strings.HasPrefix(host, prefix) {
+			return true
+		}
+	}
+	for _, s := range segments[:len(segments)-1] {
+		if contentSegments[s] {
+			return true
+		}
+	}
+	// A last segment like /how-to-read-a-paper or /intro.html names a page
+	last := segments[len(segments)-1]
+	switch path.Ext(last) {
+	case ".html", ".htm", ".md", ".txt":
+		return true
+	}
+	return strings.Count(last, "-") >= 2
+}
+
+// matchesHost reports whether host is one of hosts or a subdomain of one
+func matchesHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimateReading splits the time spent in entries between reading content
+// pages and using everything else. A visit's time is how long the browser
+// recorded its tab in the foreground, or else the gap until the next visit
+// unless that exceeds idle. A content page whose word count is known is
+// credited with the time to read it at wpm words a minute instead, once
+// however often it was visited. Articles are ordered by reading time.
+func EstimateReading(entries []models.HistoryEntry, idle time.Duration, wpm int) (articles []models.ArticleReading, readingMinutes, appMinutes float64) {
+	if idle <= 0 {
+		idle = DefaultIdleGap
+	}
+	if wpm <= 0 {
+		wpm = DefaultWordsPerMinute
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	type article struct {
+		models.ArticleReading
+		time     time.Duration
+		recorded bool
+	}
+	byURL := make(map[string]*article)
+	var order []*article
+	var apps time.Duration
+	for i, e := range sorted {
+		spent := timeSpent(sorted, i, idle)
+		if !ContentLike(e.URL) {
+			apps += spent
+			continue
+		}
+
+		a, ok := byURL[e.URL]
+		if !ok {
+			a = &article{ArticleReading: models.ArticleReading{URL: e.URL, Domain: e.Domain}}
+			byURL[e.URL] = a
+			order = append(order, a)
+		}
+		a.Visits++
+		a.time += spent
+		a.recorded = a.recorded || e.Duration > 0
+		a.Words = max(a.Words, e.Words)
+		if e.Title != "" {
+			a.Title = e.Title
+		}
+	}
+
+	articles = make([]models.ArticleReading, 0, len(order))
+	for _, a := range order {
+		switch {
+		case a.Words > 0:
+			a.ReadingMinutes = roundTenth(float64(a.Words) / float64(wpm))
+			a.Source = "words"
+		case a.recorded:
+			a.ReadingMinutes = roundTenth(a.time.Minutes())
+			a.Source = "visit_duration"
+		default:
+			a.ReadingMinutes = roundTenth(a.time.Minutes())
+			a.Source = "gaps"
+		}
+		readingMinutes += a.ReadingMinutes
+		articles = append(articles, a.ArticleReading)
+	}
+	sort.SliceStable(articles, func(i, j int) bool {
+		if articles[i].ReadingMinutes != articles[j].ReadingMinutes {
+			return articles[i].ReadingMinutes > articles[j].ReadingMinutes
+		}
+		return articles[i].Visits > articles[j].Visits
+	})
+	return articles, roundTenth(readingMinutes), roundTenth(apps.Minutes())
+}
+
+// timeSpent returns the time credited to sorted[i]: the foreground time the
+// browser recorded for it, or else the gap until the next visit unless that
+// exceeds idle. sorted is oldest first.
+func timeSpent(sorted []models.HistoryEntry, i int, idle time.Duration) time.Duration {
+	if sorted[i].Duration > 0 {
+		return sorted[i].Duration
+	}
+	if i+1 < len(sorted) {
+		if gap := sorted[i+1].Timestamp.Sub(sorted[i].Timestamp); gap <= idle {
+			return gap
+		}
+	}
+	return 0
+}
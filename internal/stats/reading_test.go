@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestContentLike(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://go.dev/doc/effective_go", true},
+		{"https://blog.golang.org/", false},
+		{"https://blog.golang.org/go1.22", true},
+		{"https://en.wikipedia.org/wiki/SQLite", true},
+		{"https://example.com/2025/12/how-to-read-a-paper", true},
+		{"https://example.com/guide/intro.html", true},
+		{"https://news.example/", false},
+		{"https://example.com/", false},
+		{"https://example.com/settings", false},
+		{"https://mail.google.com/mail/u/0/#inbox", false},
+		{"https://github.com/rzolkos/web-recap-extra-tools", false},
+		{"https://www.google.com/search?q=how-to-read", false},
+		{"chrome://settings/", false},
+	}
+	for _, tt := range tests {
+		if got := ContentLike(tt.url); got != tt.want {
+			t.Errorf("ContentLike(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateReading(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	entries := []models.HistoryEntry{
+		// Gaps: 4 minutes in mail, then 6 on a post, which is fetched
+		{Timestamp: at(0), URL: "https://mail.google.com/mail/u/0/", Domain: "mail.google.com"},
+		{Timestamp: at(4), URL: "https://example.com/posts/one", Title: "One", Domain: "example.com", Words: 1150},
+		// Chrome recorded 3 minutes in the foreground, not the 20 to the next visit
+		{Timestamp: at(10), URL: "https://go.dev/doc/faq", Title: "FAQ", Domain: "go.dev", Duration: 3 * time.Minute},
+		{Timestamp: at(30), URL: "https://app.slack.com/client", Domain: "app.slack.com"},
+		{Timestamp: at(32), URL: "https://en.wikipedia.org/wiki/Go", Title: "Go", Domain: "en.wikipedia.org"},
+		{Timestamp: at(33), URL: "https://example.com/posts/one", Title: "One", Domain: "example.com", Words: 1150},
+		// Walked away: the gap after the last visit is not counted
+		{Timestamp: at(60), URL: "https://app.slack.com/client", Domain: "app.slack.com"},
+	}
+
+	articles, reading, apps := EstimateReading(entries, 10*time.Minute, 230)
+	if len(articles) != 3 {
+		t.Fatalf("articles = %+v, want 3", articles)
+	}
+	want := []models.ArticleReading{
+		{URL: "https://example.com/posts/one", Title: "One", Domain: "example.com", Visits: 2, Words: 1150, ReadingMinutes: 5, Source: "words"},
+		{URL: "https://go.dev/doc/faq", Title: "FAQ", Domain: "go.dev", Visits: 1, ReadingMinutes: 3, Source: "visit_duration"},
+		{URL: "https://en.wikipedia.org/wiki/Go", Title: "Go", Domain: "en.wikipedia.org", Visits: 1, ReadingMinutes: 1, Source: "gaps"},
+	}
+	for i := range want {
+		if articles[i] != want[i] {
+			t.Errorf("articles[%d] = %+v, want %+v", i, articles[i], want[i])
+		}
+	}
+	if reading != 9 {
+		t.Errorf("reading = %v, want 9", reading)
+	}
+	// mail 4 + slack 2
+	if apps != 6 {
+		t.Errorf("apps = %v, want 6", apps)
+	}
+}
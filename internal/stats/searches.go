@@ -0,0 +1,173 @@
+package stats
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// searchEngine describes how to recognize a search results URL
+type searchEngine struct {
+	name  string
+	match func(host, path string) bool
+	param string
+}
+
+var searchEngines = []searchEngine{
+	{name: "google", param: "q", match: func(host, path string) bool {
+		return (host == "google.com" || strings.HasPrefix(host, "google.")) && path == "/search"
+	}},
+	{name: "bing", param: "q", match: func(host, path string) bool {
+		return host == "bing.com" && path == "/search"
+	}},
+	{name: "duckduckgo", param: "q", match: func(host, path string) bool {
+		return host == "duckduckgo.com" && (path == "/" || path == "")
+	}},
+	{name: "brave", param: "q", match: func(host, path string) bool {
+		return host == "search.brave.com" && path == "/search"
+	}},
+	{name: "kagi", param: "q", match: func(host, path string) bool {
+		return host == "kagi.com" && path == "/search"
+	}},
+	{name: "ecosia", param: "q", match: func(host, path string) bool {
+		return host == "ecosia.org" && path == "/search"
+	}},
+	{name: "yahoo", param: "p", match: func(host, path string) bool {
+		return host == "search.yahoo.com" && strings.HasPrefix(path, "/search")
+	}},
+	{name: "youtube", param: "search_query", match: func(host, path string) bool {
+		return (host == "youtube.com" || host == "m.youtube.com") && path == "/results"
+	}},
+}
+
+// ParseSearchURL returns the engine name and query when rawURL is a search
+// results page of a known search engine
+func ParseSearchURL(rawURL string) (engine, query string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	for _, se := range searchEngines {
+		if !se.match(host, u.Path) {
+			continue
+		}
+		q := strings.Join(strings.Fields(u.Query().Get(se.param)), " ")
+		if q == "" {
+			return "", "", false
+		}
+		return se.name, q, true
+	}
+
+	return "", "", false
+}
+
+// maxReferrerHops bounds how many redirects or pages are followed back from
+// a visit to the search it came from
+const maxReferrerHops = 5
+
+// redirectWindow is how soon after a click a visit reached through it counts
+// as where a redirect landed rather than further reading from the result
+const redirectWindow = 5 * time.Second
+
+// ExtractSearches finds search engine queries in entries, oldest first.
+// Repeated visits to the same query on the same engine (result pages,
+// reloads, back navigation) are reported once, at the first visit.
+//
+// Each query's clicked results are found by following visits' referrers
+// back to one of its result pages. A click through a redirect, such as a
+// click-tracking URL, is reported at the page it landed on, and pages read
+// on from a result are not reported as clicks of their own.
+func ExtractSearches(entries []models.HistoryEntry) []models.SearchQuery {
+	var searches []models.SearchQuery
+	index := make(map[string]int)
+
+	sorted := append([]models.HistoryEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	// referrers holds the referrer of the latest visit to each URL so far,
+	// to follow a chain back through redirects and links
+	referrers := make(map[string]string)
+
+	for _, e := range sorted {
+		if e.Referrer != "" {
+			referrers[e.URL] = e.Referrer
+		}
+		engine, query, ok := ParseSearchURL(e.URL)
+		if !ok {
+			if i, via, found := searchOrigin(e.Referrer, referrers, index); found {
+				addClick(&searches[i], e, via)
+			}
+			continue
+		}
+		key := searchKey(engine, query)
+		if _, seen := index[key]; seen {
+			continue
+		}
+		index[key] = len(searches)
+		searches = append(searches, models.SearchQuery{
+			Timestamp: e.Timestamp,
+			Engine:    engine,
+			Query:     query,
+			URL:       e.URL,
+			Browser:   e.Browser,
+		})
+	}
+
+	return searches
+}
+
+// searchKey identifies a query on an engine, ignoring case
+func searchKey(engine, query string) string {
+	return engine + "\x00" + strings.ToLower(query)
+}
+
+// searchOrigin follows referrer back through earlier visits until it
+// reaches the result page of a search in index. It returns the search and
+// the URLs passed through on the way.
+func searchOrigin(referrer string, referrers map[string]string, index map[string]int) (int, []string, bool) {
+	var via []string
+	for hop := 0; referrer != "" && hop <= maxReferrerHops; hop++ {
+		if engine, query, ok := ParseSearchURL(referrer); ok {
+			i, found := index[searchKey(engine, query)]
+			return i, via, found
+		}
+		via = append(via, referrer)
+		referrer = referrers[referrer]
+	}
+	return 0, nil, false
+}
+
+// addClick records e as a result clicked from s, reached through the pages
+// in via. A visit reached through an earlier click of s replaces it when it
+// followed as quickly as a redirect, and is otherwise left out.
+func addClick(s *models.SearchQuery, e models.HistoryEntry, via []string) {
+	click := models.SearchClick{
+		Timestamp: e.Timestamp,
+		URL:       e.URL,
+		Title:     e.Title,
+		Domain:    e.Domain,
+	}
+	for i, c := range s.Clicks {
+		if c.URL == e.URL {
+			return
+		}
+		for _, u := range via {
+			if c.URL != u {
+				continue
+			}
+			if e.Timestamp.Sub(c.Timestamp) <= redirectWindow {
+				click.Timestamp = c.Timestamp
+				s.Clicks[i] = click
+			}
+			return
+		}
+	}
+	s.Clicks = append(s.Clicks, click)
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestParseSearchURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		engine string
+		query  string
+		ok     bool
+	}{
+		{url: "https://www.google.com/search?q=rust+async+cancellation&oq=rust", engine: "google", query: "rust async cancellation", ok: true},
+		{url: "https://www.google.co.uk/search?q=tea", engine: "google", query: "tea", ok: true},
+		{url: "https://www.bing.com/search?q=go%20generics", engine: "bing", query: "go generics", ok: true},
+		{url: "https://duckduckgo.com/?q=sqlite+wal&ia=web", engine: "duckduckgo", query: "sqlite wal", ok: true},
+		{url: "https://search.brave.com/search?q=brave", engine: "brave", query: "brave", ok: true},
+		{url: "https://search.yahoo.com/search?p=weather", engine: "yahoo", query: "weather", ok: true},
+		{url: "https://www.youtube.com/results?search_query=gophercon", engine: "youtube", query: "gophercon", ok: true},
+		{url: "https://www.google.com/maps?q=berlin", ok: false},
+		{url: "https://www.google.com/search?q=", ok: false},
+		{url: "https://example.com/search?q=not+an+engine", ok: false},
+		{url: "not a url", ok: false},
+	}
+
+	for _, tt := range tests {
+		engine, query, ok := ParseSearchURL(tt.url)
+		if ok != tt.ok || engine != tt.engine || query != tt.query {
+			t.Errorf("ParseSearchURL(%q) = %q, %q, %v; want %q, %q, %v", tt.url, engine, query, ok, tt.engine, tt.query, tt.ok)
+		}
+	}
+}
+
+func TestExtractSearchesDedupes(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: base.Add(2 * time.Minute), URL: "https://www.google.com/search?q=Go+modules&start=10"},
+		{Timestamp: base, URL: "https://www.google.com/search?q=go+modules"},
+		{Timestamp: base.Add(time.Minute), URL: "https://go.dev/ref/mod"},
+		{Timestamp: base.Add(3 * time.Minute), URL: "https://duckduckgo.com/?q=go+modules"},
+	}
+
+	searches := ExtractSearches(entries)
+	if len(searches) != 2 {
+		t.Fatalf("len(searches) = %d, want 2: %+v", len(searches), searches)
+	}
+	if searches[0].Engine != "google" || !searches[0].Timestamp.Equal(base) {
+		t.Errorf("searches[0] = %+v, want first google visit", searches[0])
+	}
+	if searches[1].Engine != "duckduckgo" {
+		t.Errorf("searches[1] = %+v, want duckduckgo", searches[1])
+	}
+}
+
+func TestExtractSearchesClicks(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	search := "https://www.google.com/search?q=sqlite+wal"
+	redirect := "https://www.google.com/url?q=https://sqlite.org/wal.html"
+	entries := []models.HistoryEntry{
+		{Timestamp: base, URL: search},
+		// A click through a tracking redirect lands on the result
+		{Timestamp: base.Add(10 * time.Second), URL: redirect, Referrer: search},
+		{Timestamp: base.Add(11 * time.Second), URL: "https://sqlite.org/wal.html", Title: "Write-Ahead Logging", Domain: "sqlite.org", Referrer: redirect},
+		// Reading on from the result is not another click
+		{Timestamp: base.Add(time.Minute), URL: "https://sqlite.org/pragma.html", Referrer: "https://sqlite.org/wal.html"},
+		// The second page of results leads to a second click
+		{Timestamp: base.Add(2 * time.Minute), URL: search + "&start=10", Referrer: search},
+		{Timestamp: base.Add(3 * time.Minute), URL: "https://stackoverflow.com/q/1", Domain: "stackoverflow.com", Referrer: search + "&start=10"},
+		// A visit with no referrer is not a click
+		{Timestamp: base.Add(4 * time.Minute), URL: "https://news.example/"},
+	}
+
+	searches := ExtractSearches(entries)
+	if len(searches) != 1 {
+		t.Fatalf("len(searches) = %d, want 1: %+v", len(searches), searches)
+	}
+	clicks := searches[0].Clicks
+	if len(clicks) != 2 {
+		t.Fatalf("clicks = %+v, want 2", clicks)
+	}
+	if clicks[0].URL != "https://sqlite.org/wal.html" || clicks[0].Title != "Write-Ahead Logging" || !clicks[0].Timestamp.Equal(base.Add(10*time.Second)) {
+		t.Errorf("clicks[0] = %+v, want the redirect's landing page at the click", clicks[0])
+	}
+	if clicks[1].URL != "https://stackoverflow.com/q/1" {
+		t.Errorf("clicks[1] = %+v, want the result from the second page", clicks[1])
+	}
+}
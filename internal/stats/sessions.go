@@ -0,0 +1,93 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// DefaultSessionGap is the idle time after which a new session starts
+const DefaultSessionGap = 30 * time.Minute
+
+// Limits on the per-session detail kept for reports
+const (
+	sessionTopDomains   = 5
+	sessionSampleTitles = 5
+)
+
+// DetectSessions groups entries into browsing sessions, starting a new
+// session whenever more than gap passes between consecutive visits.
+// Sessions are returned oldest first.
+func DetectSessions(entries []models.HistoryEntry, gap time.Duration) []models.BrowsingSession {
+	var sessions []models.BrowsingSession
+	for _, visits := range splitSessions(entries, gap) {
+		sessions = append(sessions, buildSession(visits))
+	}
+	return sessions
+}
+
+// splitSessions sorts entries oldest first and cuts them into sessions
+// wherever more than gap passes between consecutive visits
+func splitSessions(entries []models.HistoryEntry, gap time.Duration) [][]models.HistoryEntry {
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var sessions [][]models.HistoryEntry
+	start := 0
+	for i := 1; i <= len(sorted); i++ {
+		if i == len(sorted) || sorted[i].Timestamp.Sub(sorted[i-1].Timestamp) > gap {
+			sessions = append(sessions, sorted[start:i])
+			start = i
+		}
+	}
+	return sessions
+}
+
+// buildSession summarizes a run of chronologically sorted entries
+func buildSession(entries []models.HistoryEntry) models.BrowsingSession {
+	session := models.BrowsingSession{
+		Start:  entries[0].Timestamp,
+		End:    entries[len(entries)-1].Timestamp,
+		Visits: len(entries),
+	}
+	session.DurationMinutes = int(session.End.Sub(session.Start).Round(time.Minute) / time.Minute)
+
+	domainCounts := make(map[string]int)
+	seenTitles := make(map[string]bool)
+	for _, e := range entries {
+		if e.Domain != "" {
+			domainCounts[e.Domain]++
+		}
+		if e.Title != "" && !seenTitles[e.Title] && len(session.SampleTitles) < sessionSampleTitles {
+			seenTitles[e.Title] = true
+			session.SampleTitles = append(session.SampleTitles, e.Title)
+		}
+	}
+
+	for domain, visits := range domainCounts {
+		session.TopDomains = append(session.TopDomains, models.DigestDomain{Domain: domain, Visits: visits})
+	}
+	sort.Slice(session.TopDomains, func(i, j int) bool {
+		if session.TopDomains[i].Visits != session.TopDomains[j].Visits {
+			return session.TopDomains[i].Visits > session.TopDomains[j].Visits
+		}
+		return session.TopDomains[i].Domain < session.TopDomains[j].Domain
+	})
+	if len(session.TopDomains) > sessionTopDomains {
+		session.TopDomains = session.TopDomains[:sessionTopDomains]
+	}
+
+	return session
+}
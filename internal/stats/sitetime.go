@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// DefaultIdleGap is the longest time between two visits that is still
+// counted as time spent on the first page
+const DefaultIdleGap = 10 * time.Minute
+
+// EstimateSiteTime estimates time per domain from history alone: each visit
+// is credited with the time until the next visit, unless that exceeds idle,
+// in which case the user is assumed to have walked away and it gets nothing
+func EstimateSiteTime(entries []models.HistoryEntry, idle time.Duration) map[string]time.Duration {
+	if idle <= 0 {
+		idle = DefaultIdleGap
+	}
+
+	sorted := make([]models.HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.Timestamp.IsZero() {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	result := make(map[string]time.Duration)
+	for i := 0; i+1 < len(sorted); i++ {
+		gap := sorted[i+1].Timestamp.Sub(sorted[i].Timestamp)
+		if gap <= idle {
+			result[siteKey(sorted[i].Domain)] += gap
+		}
+	}
+	return result
+}
+
+// BuildSiteTime merges history visits, gap-based estimates, and the usage a
+// browser recorded itself into one row per domain, ordered by browser-recorded
+// time, then estimated time. Domains differing only by "www." are combined.
+func BuildSiteTime(entries []models.HistoryEntry, usage []models.SiteUsage, idle time.Duration) []models.SiteTime {
+	sites := make(map[string]*models.SiteTime)
+	site := func(domain string) *models.SiteTime {
+		key := siteKey(domain)
+		s, ok := sites[key]
+		if !ok {
+			s = &models.SiteTime{Domain: key}
+			sites[key] = s
+		}
+		return s
+	}
+
+	for _, e := range entries {
+		if e.Domain != "" {
+			site(e.Domain).Visits++
+		}
+	}
+	for domain, d := range EstimateSiteTime(entries, idle) {
+		if domain != "" {
+			site(domain).EstimatedMinutes += d.Minutes()
+		}
+	}
+	for _, u := range usage {
+		s := site(u.Domain)
+		s.SegmentVisits += u.SegmentVisits
+		s.BrowserMinutes += u.BrowserSeconds / 60
+	}
+
+	result := make([]models.SiteTime, 0, len(sites))
+	for _, s := range sites {
+		s.BrowserMinutes = roundTenth(s.BrowserMinutes)
+		s.EstimatedMinutes = roundTenth(s.EstimatedMinutes)
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.BrowserMinutes != b.BrowserMinutes {
+			return a.BrowserMinutes > b.BrowserMinutes
+		}
+		if a.EstimatedMinutes != b.EstimatedMinutes {
+			return a.EstimatedMinutes > b.EstimatedMinutes
+		}
+		return a.Domain < b.Domain
+	})
+	return result
+}
+
+func siteKey(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(domain), "www.")
+}
+
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
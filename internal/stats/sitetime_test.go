@@ -0,0 +1,34 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestBuildSiteTime(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	entries := []models.HistoryEntry{
+		{Timestamp: base, Domain: "www.go.dev"},
+		{Timestamp: base.Add(4 * time.Minute), Domain: "news.example"},
+		{Timestamp: base.Add(6 * time.Minute), Domain: "go.dev"},
+		// Idle gap: the go.dev visit above gets no credit
+		{Timestamp: base.Add(2 * time.Hour), Domain: "news.example"},
+	}
+	usage := []models.SiteUsage{{Domain: "news.example", SegmentVisits: 3, BrowserSeconds: 90}}
+
+	sites := BuildSiteTime(entries, usage, 10*time.Minute)
+	if len(sites) != 2 {
+		t.Fatalf("got %d sites, want 2: %+v", len(sites), sites)
+	}
+	want := []models.SiteTime{
+		{Domain: "news.example", Visits: 2, SegmentVisits: 3, BrowserMinutes: 1.5, EstimatedMinutes: 2},
+		{Domain: "go.dev", Visits: 2, EstimatedMinutes: 4},
+	}
+	for i := range want {
+		if sites[i] != want[i] {
+			t.Errorf("site %d = %+v, want %+v", i, sites[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// dayNumber returns the number of calendar days between the Unix epoch and
+// the local date of t in loc. Working on civil dates keeps DST transitions
+// from producing 23 or 25 hour "days".
+func dayNumber(t time.Time, loc *time.Location) int {
+	local := t.In(loc)
+	civil := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	return int(civil.Unix() / 86400)
+}
+
+// CountDays returns the number of calendar days covered by [start, end) in loc
+func CountDays(start, end time.Time, loc *time.Location) int {
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		return 0
+	}
+	return dayNumber(end.Add(-time.Nanosecond), loc) - dayNumber(start, loc) + 1
+}
+
+// ComputeStreaks calculates per-domain visit streaks for entries in [start, end).
+// Days are bucketed in loc. The current streak counts back from the last day of
+// the range, or from the day before it when the last day has no visits yet, so
+// an in-progress day doesn't break a streak. Domains active on fewer than
+// minDays days are omitted.
+func ComputeStreaks(entries []models.HistoryEntry, start, end time.Time, loc *time.Location, minDays int) []models.DomainStreak {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	type domainDays struct {
+		days      map[int]bool
+		visits    int
+		firstSeen time.Time
+		lastSeen  time.Time
+	}
+
+	byDomain := make(map[string]*domainDays)
+	for _, e := range entries {
+		if e.Domain == "" || e.Timestamp.IsZero() {
+			continue
+		}
+		if !start.IsZero() && e.Timestamp.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !e.Timestamp.Before(end) {
+			continue
+		}
+
+		d, ok := byDomain[e.Domain]
+		if !ok {
+			d = &domainDays{days: make(map[int]bool)}
+			byDomain[e.Domain] = d
+		}
+
+		d.days[dayNumber(e.Timestamp, loc)] = true
+		d.visits++
+		if d.firstSeen.IsZero() || e.Timestamp.Before(d.firstSeen) {
+			d.firstSeen = e.Timestamp
+		}
+		if e.Timestamp.After(d.lastSeen) {
+			d.lastSeen = e.Timestamp
+		}
+	}
+
+	totalDays := CountDays(start, end, loc)
+	lastDay := 0
+	if !end.IsZero() {
+		lastDay = dayNumber(end.Add(-time.Nanosecond), loc)
+	}
+
+	var streaks []models.DomainStreak
+	for domain, d := range byDomain {
+		if len(d.days) < minDays {
+			continue
+		}
+
+		days := make([]int, 0, len(d.days))
+		for day := range d.days {
+			days = append(days, day)
+		}
+		sort.Ints(days)
+
+		longest, run := 1, 1
+		for i := 1; i < len(days); i++ {
+			if days[i] == days[i-1]+1 {
+				run++
+			} else {
+				run = 1
+			}
+			if run > longest {
+				longest = run
+			}
+		}
+
+		day := lastDay
+		if day == 0 {
+			day = days[len(days)-1]
+		}
+		current := 0
+		if !d.days[day] {
+			day--
+		}
+		for d.days[day] {
+			current++
+			day--
+		}
+
+		consistency := 0.0
+		if totalDays > 0 {
+			consistency = math.Round(float64(len(days))/float64(totalDays)*1000) / 10
+		}
+
+		streaks = append(streaks, models.DomainStreak{
+			Domain:         domain,
+			CurrentStreak:  current,
+			LongestStreak:  longest,
+			ActiveDays:     len(days),
+			TotalVisits:    d.visits,
+			ConsistencyPct: consistency,
+			FirstSeen:      d.firstSeen,
+			LastSeen:       d.lastSeen,
+		})
+	}
+
+	// Sort by longest streak, then by how many days the domain was active
+	sort.Slice(streaks, func(i, j int) bool {
+		if streaks[i].LongestStreak != streaks[j].LongestStreak {
+			return streaks[i].LongestStreak > streaks[j].LongestStreak
+		}
+		if streaks[i].ActiveDays != streaks[j].ActiveDays {
+			return streaks[i].ActiveDays > streaks[j].ActiveDays
+		}
+		return streaks[i].Domain < streaks[j].Domain
+	})
+
+	return streaks
+}
@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func visitOn(domain string, day int) models.HistoryEntry {
+	return models.HistoryEntry{
+		Timestamp: time.Date(2026, 1, day, 12, 0, 0, 0, time.UTC),
+		URL:       "https://" + domain + "/",
+		Domain:    domain,
+	}
+}
+
+func TestComputeStreaks(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC) // 10 days
+
+	entries := []models.HistoryEntry{
+		// leetcode: days 1-3, then 6-10 (current streak through the last day)
+		visitOn("leetcode.com", 1), visitOn("leetcode.com", 2), visitOn("leetcode.com", 3),
+		visitOn("leetcode.com", 6), visitOn("leetcode.com", 7), visitOn("leetcode.com", 8),
+		visitOn("leetcode.com", 9), visitOn("leetcode.com", 10), visitOn("leetcode.com", 10),
+		// news: days 4-9, not yet visited on the last day
+		visitOn("news.example", 4), visitOn("news.example", 5), visitOn("news.example", 6),
+		visitOn("news.example", 7), visitOn("news.example", 8), visitOn("news.example", 9),
+		// once: below min-days
+		visitOn("once.example", 5),
+		// outside the range
+		visitOn("late.example", 11), visitOn("late.example", 12),
+	}
+
+	streaks := ComputeStreaks(entries, start, end, time.UTC, 2)
+	if len(streaks) != 2 {
+		t.Fatalf("expected 2 domains, got %d: %+v", len(streaks), streaks)
+	}
+
+	tests := []struct {
+		domain      string
+		current     int
+		longest     int
+		activeDays  int
+		visits      int
+		consistency float64
+	}{
+		{domain: "news.example", current: 6, longest: 6, activeDays: 6, visits: 6, consistency: 60},
+		{domain: "leetcode.com", current: 5, longest: 5, activeDays: 8, visits: 9, consistency: 80},
+	}
+
+	for i, tt := range tests {
+		got := streaks[i]
+		if got.Domain != tt.domain {
+			t.Fatalf("streaks[%d].Domain = %q, want %q", i, got.Domain, tt.domain)
+		}
+		if got.CurrentStreak != tt.current {
+			t.Errorf("%s: CurrentStreak = %d, want %d", tt.domain, got.CurrentStreak, tt.current)
+		}
+		if got.LongestStreak != tt.longest {
+			t.Errorf("%s: LongestStreak = %d, want %d", tt.domain, got.LongestStreak, tt.longest)
+		}
+		if got.ActiveDays != tt.activeDays {
+			t.Errorf("%s: ActiveDays = %d, want %d", tt.domain, got.ActiveDays, tt.activeDays)
+		}
+		if got.TotalVisits != tt.visits {
+			t.Errorf("%s: TotalVisits = %d, want %d", tt.domain, got.TotalVisits, tt.visits)
+		}
+		if got.ConsistencyPct != tt.consistency {
+			t.Errorf("%s: ConsistencyPct = %v, want %v", tt.domain, got.ConsistencyPct, tt.consistency)
+		}
+	}
+}
+
+func TestCountDays(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// Spans the March DST transition; should still be 7 calendar days.
+	start := time.Date(2026, 3, 5, 0, 0, 0, 0, loc)
+	end := time.Date(2026, 3, 12, 0, 0, 0, 0, loc)
+	if got := CountDays(start, end, loc); got != 7 {
+		t.Fatalf("CountDays() = %d, want 7", got)
+	}
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/rzolkos/web-recap/internal/models"
+	"github.com/rzolkos/web-recap/internal/urlutil"
+)
+
+// Kinds of input to CheckVisited
+const (
+	VisitedURL    = "url"
+	VisitedDomain = "domain"
+)
+
+// visitedKind reports whether input names a page or a whole domain: a
+// scheme or a path makes it a URL, a bare host a domain
+func visitedKind(input string) string {
+	if strings.Contains(input, "://") || strings.Contains(input, "/") {
+		return VisitedURL
+	}
+	return VisitedDomain
+}
+
+// visitedHost lowercases a host and drops its port and a leading www.
+func visitedHost(host string) string {
+	host = strings.ToLower(host)
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
+
+// visitedKey reduces a URL to what identifies the page: the scheme, a
+// leading www., the fragment, a trailing slash, and tracking parameters
+// don't make it another page. A URL without a scheme is taken as https.
+func visitedKey(rawURL string) string {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(urlutil.CleanURL(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(rawURL, "/")
+	}
+	key := visitedHost(u.Host) + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// CheckVisited looks up each input in entries. A URL matches the visits to
+// the same page, ignoring the differences visitedKey drops; a domain
+// matches the visits to it and its subdomains. Results are in input order.
+func CheckVisited(inputs []string, entries []models.HistoryEntry) []models.VisitedCheck {
+	checks := make([]models.VisitedCheck, len(inputs))
+	byURL := make(map[string][]int)
+	byDomain := make(map[string][]int)
+	for i, input := range inputs {
+		checks[i] = models.VisitedCheck{Input: input, Kind: visitedKind(input)}
+		if checks[i].Kind == VisitedURL {
+			key := visitedKey(input)
+			byURL[key] = append(byURL[key], i)
+		} else {
+			domain := visitedHost(input)
+			byDomain[domain] = append(byDomain[domain], i)
+		}
+	}
+
+	browsers := make([]map[string]bool, len(inputs))
+	match := func(i int, e models.HistoryEntry) {
+		c := &checks[i]
+		c.Visited = true
+		c.Visits++
+		if c.LastVisited == nil || e.Timestamp.After(*c.LastVisited) {
+			t := e.Timestamp
+			c.LastVisited = &t
+			c.URL, c.Title = e.URL, e.Title
+		}
+		if browsers[i] == nil {
+			browsers[i] = make(map[string]bool)
+		}
+		names := e.Browsers
+		if len(names) == 0 && e.Browser != "" {
+			names = []string{e.Browser}
+		}
+		for _, b := range names {
+			browsers[i][b] = true
+		}
+	}
+
+	for _, e := range entries {
+		if len(byURL) > 0 {
+			for _, i := range byURL[visitedKey(e.URL)] {
+				match(i, e)
+			}
+		}
+		if len(byDomain) > 0 {
+			// Try the host and every parent domain of it
+			host := visitedHost(e.Domain)
+			for host != "" {
+				for _, i := range byDomain[host] {
+					match(i, e)
+				}
+				dot := strings.Index(host, ".")
+				if dot < 0 {
+					break
+				}
+				host = host[dot+1:]
+			}
+		}
+	}
+
+	for i := range checks {
+		for b := range browsers[i] {
+			checks[i].Browsers = append(checks[i].Browsers, b)
+		}
+		sort.Strings(checks[i].Browsers)
+	}
+	return checks
+}
@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestCheckVisited(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	visit := func(url, domain, browser string, minute int) models.HistoryEntry {
+		return models.HistoryEntry{URL: url, Domain: domain, Browser: browser, Title: url, Timestamp: base.Add(time.Duration(minute) * time.Minute)}
+	}
+	entries := []models.HistoryEntry{
+		visit("https://www.example.com/post/?utm_source=feed#comments", "www.example.com", "chrome", 30),
+		visit("http://example.com/post", "example.com", "firefox", 10),
+		visit("https://docs.example.com/guide", "docs.example.com", "chrome", 20),
+		visit("https://other.example/post", "other.example", "chrome", 40),
+	}
+
+	checks := CheckVisited([]string{
+		"https://example.com/post",
+		"example.com/post/",
+		"example.com",
+		"docs.example.com",
+		"https://example.com/unread",
+		"notexample.com",
+	}, entries)
+
+	want := []struct {
+		kind     string
+		visits   int
+		minute   int
+		browsers int
+	}{
+		{VisitedURL, 2, 30, 2},
+		{VisitedURL, 2, 30, 2},
+		{VisitedDomain, 3, 30, 2},
+		{VisitedDomain, 1, 20, 1},
+		{VisitedURL, 0, 0, 0},
+		{VisitedDomain, 0, 0, 0},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("got %d checks, want %d", len(checks), len(want))
+	}
+	for i, w := range want {
+		c := checks[i]
+		if c.Kind != w.kind || c.Visits != w.visits || c.Visited != (w.visits > 0) || len(c.Browsers) != w.browsers {
+			t.Errorf("check %q = %+v, want kind %s with %d visits in %d browsers", c.Input, c, w.kind, w.visits, w.browsers)
+			continue
+		}
+		if w.visits == 0 {
+			if c.LastVisited != nil || c.URL != "" {
+				t.Errorf("check %q has a last visit for an unvisited input", c.Input)
+			}
+		} else if c.LastVisited == nil || !c.LastVisited.Equal(base.Add(time.Duration(w.minute)*time.Minute)) {
+			t.Errorf("check %q last visited %v, want minute %d", c.Input, c.LastVisited, w.minute)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Package takeout reads browser history exported by Google Takeout
+package takeout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// historyKey is the key of the visit list in BrowserHistory.json
+const historyKey = "Browser History"
+
+// visit is one entry of BrowserHistory.json; time_usec is microseconds since
+// the Unix epoch
+type visit struct {
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	TimeUsec int64  `json:"time_usec"`
+}
+
+// ReadBrowserHistory reads the Chrome history in a Takeout
+// BrowserHistory.json file as history entries for browser. The file is
+// decoded as a stream, since years of history can be hundreds of
+// megabytes. Takeout lists each visit separately, so the visit count of an
+// entry is the number of visits to its URL in the file.
+func ReadBrowserHistory(r io.Reader, browser string) ([]models.HistoryEntry, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var entries []models.HistoryEntry
+	found := false
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid Takeout file: %v", err)
+		}
+		if key, _ := tok.(string); key != historyKey {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, fmt.Errorf("invalid Takeout file: %v", err)
+			}
+			continue
+		}
+
+		found = true
+		if err := expectDelim(dec, '['); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			var v visit
+			if err := dec.Decode(&v); err != nil {
+				return nil, fmt.Errorf("invalid visit %d in Takeout file: %v", len(entries)+1, err)
+			}
+			if v.URL == "" || v.TimeUsec <= 0 {
+				continue
+			}
+			entries = append(entries, models.HistoryEntry{
+				Timestamp: time.UnixMicro(v.TimeUsec).UTC(),
+				URL:       v.URL,
+				Title:     v.Title,
+				Domain:    database.ExtractDomain(v.URL),
+				Browser:   browser,
+			})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, fmt.Errorf("invalid Takeout file: %v", err)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("not a Takeout BrowserHistory.json file: no %q list", historyKey)
+	}
+
+	counts := make(map[string]int)
+	for _, e := range entries {
+		counts[e.URL]++
+	}
+	for i := range entries {
+		entries[i].VisitCount = counts[entries[i].URL]
+	}
+	return entries, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid Takeout file: %v", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("not a Takeout BrowserHistory.json file: expected %q, got %v", want, tok)
+	}
+	return nil
+}
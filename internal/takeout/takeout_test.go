@@ -0,0 +1,44 @@
+package takeout
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadBrowserHistory(t *testing.T) {
+	data := `{
+  "Browser History": [
+    {"favicon_url": "https://go.dev/favicon.ico", "page_transition": "LINK", "title": "Go", "url": "https://go.dev/", "client_id": "abc", "time_usec": 1609459200123456},
+    {"page_transition": "TYPED", "title": "", "url": "https://go.dev/", "client_id": "abc", "time_usec": 1609459300000000},
+    {"title": "No time", "url": "https://example.com/"},
+    {"title": "Docs", "url": "https://pkg.go.dev/net/http", "time_usec": 1609459400000000}
+  ],
+  "Other": {"ignored": [1, 2]}
+}`
+	entries, err := ReadBrowserHistory(strings.NewReader(data), "chrome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	first := entries[0]
+	if want := time.Date(2021, 1, 1, 0, 0, 0, 123456000, time.UTC); !first.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", first.Timestamp, want)
+	}
+	if first.Domain != "go.dev" || first.Browser != "chrome" || first.Title != "Go" || first.VisitCount != 2 {
+		t.Errorf("first entry = %+v", first)
+	}
+	if entries[2].VisitCount != 1 {
+		t.Errorf("visit count of %s = %d, want 1", entries[2].URL, entries[2].VisitCount)
+	}
+}
+
+func TestReadBrowserHistoryRejectsOtherFiles(t *testing.T) {
+	for _, data := range []string{`[]`, `{"Bookmarks": []}`, `{"Browser History": {}}`, `not json`} {
+		if _, err := ReadBrowserHistory(strings.NewReader(data), "chrome"); err == nil {
+			t.Errorf("ReadBrowserHistory(%s) succeeded, want an error", data)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package triage
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// MakeRaw switches the terminal on f to single-key input without echo and
+// returns a function that restores the previous state. Raw mode also stops
+// the terminal turning "\n" into a new line and Ctrl-C into an interrupt, so
+// the session should be drawn through NewRawWriter; Run reads Ctrl-C and
+// Ctrl-D as quit.
+func MakeRaw(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		term.Restore(fd, state)
+	}, nil
+}
+
+// rawWriter writes "\r\n" for each "\n", as a terminal in raw mode needs
+type rawWriter struct {
+	w io.Writer
+}
+
+// NewRawWriter returns a writer for drawing on a terminal in raw mode
+func NewRawWriter(w io.Writer) io.Writer {
+	return rawWriter{w: w}
+}
+
+func (r rawWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
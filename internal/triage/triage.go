@@ -0,0 +1,213 @@
+package triage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// Options configures a triage session
+type Options struct {
+	// Browser is recorded in the plan
+	Browser string
+	// DefaultFolder is offered for the first bookmark; later bookmarks default
+	// to the previous folder
+	DefaultFolder string
+	// Echo writes typed characters back, for terminals in raw mode
+	Echo bool
+}
+
+const help = `  k  keep        b  bookmark to folder   r  add to read-later queue
+  c  close       s  skip (decide later)  u  undo previous
+  q  quit and save the plan              ?  this help
+`
+
+// Run walks through tabs one at a time, reading single-key decisions from r
+// and drawing the prompt on w, and returns the resulting plan. Quitting early
+// or reaching the end of input keeps the decisions made so far.
+func Run(r io.Reader, w io.Writer, tabs []models.TabEntry, opts Options) (models.TriagePlan, error) {
+	in := bufio.NewReader(r)
+	decisions := make([]*models.TriageItem, len(tabs))
+	folder := opts.DefaultFolder
+	if folder == "" {
+		folder = "Triage"
+	}
+
+	fmt.Fprintf(w, "Triaging %d tabs. Press ? for help.\n", len(tabs))
+
+	seen := make(map[string]int)
+	for i := 0; i < len(tabs); {
+		tab := tabs[i]
+		fmt.Fprintf(w, "\n[%d/%d] %s\n        %s\n", i+1, len(tabs), displayTitle(tab), tab.URL)
+		if first, ok := seen[tab.URL]; ok && first != i {
+			fmt.Fprintf(w, "        (duplicate of tab %d)\n", first+1)
+		} else {
+			seen[tab.URL] = i
+		}
+		fmt.Fprint(w, "  (k)eep (b)ookmark (r)ead later (c)lose (s)kip (u)ndo (q)uit > ")
+
+		key, err := readKey(in)
+		if err == io.EOF {
+			fmt.Fprintln(w)
+			break
+		}
+		if err != nil {
+			return models.TriagePlan{}, err
+		}
+		fmt.Fprintf(w, "%c\n", key)
+
+		item := &models.TriageItem{
+			URL:      tab.URL,
+			Title:    tab.Title,
+			Domain:   tab.Domain,
+			Browser:  tab.Browser,
+			WindowID: tab.WindowID,
+		}
+
+		switch key {
+		case 'k':
+			item.Action = models.TriageKeep
+		case 'r':
+			item.Action = models.TriageReadLater
+		case 'c':
+			item.Action = models.TriageClose
+		case 'b':
+			fmt.Fprintf(w, "  folder [%s]: ", folder)
+			name, err := readLine(in, w, opts.Echo)
+			if err != nil && err != io.EOF {
+				return models.TriagePlan{}, err
+			}
+			if name = strings.Trim(strings.TrimSpace(name), "/"); name != "" {
+				folder = name
+			}
+			item.Action = models.TriageBookmark
+			item.Folder = folder
+		case 's', ' ':
+			item = nil
+		case 'u':
+			if i > 0 {
+				i--
+				decisions[i] = nil
+			}
+			continue
+		case 'q', 0x03, 0x04:
+			// Ctrl-C and Ctrl-D arrive as keys in raw mode
+			return buildPlan(tabs, decisions, opts.Browser), nil
+		case '?', 'h':
+			fmt.Fprint(w, help)
+			continue
+		default:
+			fmt.Fprintf(w, "  unknown key %q; press ? for help\n", key)
+			continue
+		}
+
+		decisions[i] = item
+		i++
+	}
+
+	return buildPlan(tabs, decisions, opts.Browser), nil
+}
+
+func buildPlan(tabs []models.TabEntry, decisions []*models.TriageItem, browser string) models.TriagePlan {
+	plan := models.TriagePlan{
+		CreatedAt: time.Now().UTC(),
+		Browser:   browser,
+		TotalTabs: len(tabs),
+		Counts:    make(map[string]int),
+		Items:     []models.TriageItem{},
+	}
+	for _, d := range decisions {
+		if d == nil {
+			continue
+		}
+		plan.Items = append(plan.Items, *d)
+		plan.Counts[d.Action]++
+	}
+	plan.Decided = len(plan.Items)
+	return plan
+}
+
+// Summary describes the plan in one line, e.g. "12 keep, 3 bookmark, 40 close"
+func Summary(plan models.TriagePlan) string {
+	var parts []string
+	for _, action := range []string{models.TriageKeep, models.TriageBookmark, models.TriageReadLater, models.TriageClose} {
+		if n := plan.Counts[action]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, action))
+		}
+	}
+	if undecided := plan.TotalTabs - plan.Decided; undecided > 0 {
+		parts = append(parts, fmt.Sprintf("%d undecided", undecided))
+	}
+	if len(parts) == 0 {
+		return "no decisions"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readKey returns the next key press, ignoring line endings so the session
+// also works when input is line buffered
+func readKey(in *bufio.Reader) (rune, error) {
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if r != '\n' && r != '\r' {
+			return r, nil
+		}
+	}
+}
+
+// readLine reads a line, handling backspace and echoing input when the
+// terminal does not
+func readLine(in *bufio.Reader, w io.Writer, echo bool) (string, error) {
+	var line []rune
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			if echo {
+				fmt.Fprintln(w)
+			}
+			return string(line), err
+		}
+		switch r {
+		case '\n', '\r':
+			if len(line) == 0 && !echo {
+				// Line-buffered input: skip the newline that ended the key press
+				continue
+			}
+			if echo {
+				fmt.Fprintln(w)
+			}
+			return string(line), nil
+		case 0x7f, 0x08:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				if echo {
+					fmt.Fprint(w, "\b \b")
+				}
+			}
+		default:
+			line = append(line, r)
+			if echo {
+				fmt.Fprint(w, string(r))
+			}
+		}
+	}
+}
+
+func displayTitle(tab models.TabEntry) string {
+	title := strings.TrimSpace(tab.Title)
+	if title == "" {
+		title = tab.Domain
+	}
+	if utf8.RuneCountInString(title) > 100 {
+		title = string([]rune(title)[:99]) + "…"
+	}
+	return title
+}
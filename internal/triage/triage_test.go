@@ -0,0 +1,81 @@
+package triage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func TestRun(t *testing.T) {
+	tabs := []models.TabEntry{
+		{URL: "https://a.example/", Title: "A"},
+		{URL: "https://b.example/", Title: "B"},
+		{URL: "https://c.example/", Title: "C"},
+		{URL: "https://a.example/", Title: "A again"},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		echo    bool
+		actions []string
+		folders []string
+	}{
+		{
+			// Raw mode: single keys, folder line ends with Enter, undo redoes tab 2
+			name:    "raw",
+			input:   "kbReading/Go\ruxb\rrq",
+			echo:    true,
+			actions: []string{models.TriageKeep, models.TriageBookmark, models.TriageReadLater},
+			folders: []string{"", "Reading/Go", ""},
+		},
+		{
+			// Raw mode: Ctrl-C quits like q
+			name:    "raw interrupt",
+			input:   "kbNews\rc\x03k",
+			echo:    true,
+			actions: []string{models.TriageKeep, models.TriageBookmark, models.TriageClose},
+			folders: []string{"", "News", ""},
+		},
+		{
+			// Line mode: every key is followed by Enter; input ends early
+			name:    "line",
+			input:   "k\nb\nNews/\n?\nc\n",
+			actions: []string{models.TriageKeep, models.TriageBookmark, models.TriageClose},
+			folders: []string{"", "News", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := Run(strings.NewReader(tt.input), io.Discard, tabs, Options{Echo: tt.echo})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if plan.TotalTabs != 4 || plan.Decided != len(tt.actions) {
+				t.Fatalf("total %d decided %d, want 4 and %d: %+v", plan.TotalTabs, plan.Decided, len(tt.actions), plan.Items)
+			}
+			for i, item := range plan.Items {
+				if item.URL != tabs[i].URL || item.Action != tt.actions[i] || item.Folder != tt.folders[i] {
+					t.Errorf("item %d = %+v, want %s %s", i, item, tt.actions[i], tt.folders[i])
+				}
+			}
+			if got := Summary(plan); !strings.Contains(got, "1 undecided") {
+				t.Errorf("Summary = %q", got)
+			}
+		})
+	}
+}
+
+func TestRawWriter(t *testing.T) {
+	var buf strings.Builder
+	w := NewRawWriter(&buf)
+	if n, err := w.Write([]byte("a\nb\n")); n != 4 || err != nil {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if buf.String() != "a\r\nb\r\n" {
+		t.Errorf("wrote %q", buf.String())
+	}
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scores in the spirit of fzf: every matched character counts, matches at
+// word boundaries and runs of adjacent matches count extra, gaps cost
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusConsecutive = 8
+	penaltyGapStart  = 3
+	penaltyGapExtend = 1
+)
+
+// FuzzyMatch reports whether every space-separated term of pattern occurs in
+// text as a subsequence, with a score (higher is better) and the matched
+// rune positions. Terms without upper-case letters match case-insensitively.
+func FuzzyMatch(pattern, text string) (score int, positions []int, ok bool) {
+	runes := []rune(text)
+	var lower []rune
+	for _, term := range strings.Fields(pattern) {
+		haystack := runes
+		if !hasUpper(term) {
+			if lower == nil {
+				lower = toLowerRunes(runes)
+			}
+			haystack = lower
+		}
+		s, pos, found := matchTerm([]rune(term), haystack)
+		if !found {
+			return 0, nil, false
+		}
+		score += s
+		positions = append(positions, pos...)
+	}
+	return score, positions, true
+}
+
+// matchTerm finds the first subsequence match of term, then walks back from
+// its end to find the tightest match ending there, and scores it
+func matchTerm(term, text []rune) (int, []int, bool) {
+	if len(term) == 0 {
+		return 0, nil, true
+	}
+
+	// Forward: where does the first complete match end?
+	ti := 0
+	end := -1
+	for i, r := range text {
+		if r == term[ti] {
+			ti++
+			if ti == len(term) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backward: the latest start that still matches, for a tighter window
+	positions := make([]int, len(term))
+	ti = len(term) - 1
+	for i := end; i >= 0 && ti >= 0; i-- {
+		if text[i] == term[ti] {
+			positions[ti] = i
+			ti--
+		}
+	}
+
+	score := 0
+	for k, pos := range positions {
+		score += scoreMatch
+		if pos == 0 || !isWordRune(text[pos-1]) {
+			score += bonusBoundary
+			if k == 0 {
+				score += bonusBoundary
+			}
+		}
+		if k > 0 {
+			gap := pos - positions[k-1] - 1
+			if gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= penaltyGapStart + (gap-1)*penaltyGapExtend
+			}
+		}
+	}
+	return score, positions, true
+}
+
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// toLowerRunes lowers rune by rune, keeping positions aligned with the
+// original text
+func toLowerRunes(runes []rune) []rune {
+	out := make([]rune, len(runes))
+	for i, r := range runes {
+		out[i] = unicode.ToLower(r)
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// KeyType identifies a key press
+type KeyType int
+
+const (
+	KeyRune KeyType = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyPgUp
+	KeyPgDown
+	KeyHome
+	KeyEnd
+	KeyCtrlC
+	KeyCtrlU
+	KeyUnknown
+)
+
+// Key is a decoded key press; Rune is set for KeyRune
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// teaKeys maps bubbletea's key types to the keys views handle
+var teaKeys = map[tea.KeyType]KeyType{
+	tea.KeyEnter:     KeyEnter,
+	tea.KeyEsc:       KeyEsc,
+	tea.KeyBackspace: KeyBackspace,
+	tea.KeyCtrlH:     KeyBackspace,
+	tea.KeyTab:       KeyTab,
+	tea.KeyUp:        KeyUp,
+	tea.KeyCtrlP:     KeyUp,
+	tea.KeyDown:      KeyDown,
+	tea.KeyCtrlN:     KeyDown,
+	tea.KeyPgUp:      KeyPgUp,
+	tea.KeyPgDown:    KeyPgDown,
+	tea.KeyHome:      KeyHome,
+	tea.KeyEnd:       KeyEnd,
+	tea.KeyCtrlC:     KeyCtrlC,
+	tea.KeyCtrlU:     KeyCtrlU,
+}
+
+// keys converts a bubbletea key message into key presses. Typed or pasted
+// text arrives as one message and becomes a KeyRune per rune; Alt
+// combinations are ignored.
+func keys(msg tea.KeyMsg) []Key {
+	switch {
+	case msg.Alt:
+		return []Key{{Type: KeyUnknown}}
+	case msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace:
+		out := make([]Key, 0, len(msg.Runes))
+		for _, r := range msg.Runes {
+			out = append(out, Key{Type: KeyRune, Rune: r})
+		}
+		return out
+	}
+	if t, ok := teaKeys[msg.Type]; ok {
+		return []Key{{Type: t}}
+	}
+	return []Key{{Type: KeyUnknown}}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		msg  tea.KeyMsg
+		want []Key
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}, []Key{{Type: KeyRune, Rune: 'a'}}},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("é")}, []Key{{Type: KeyRune, Rune: 'é'}}},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("go"), Paste: true}, []Key{{Type: KeyRune, Rune: 'g'}, {Type: KeyRune, Rune: 'o'}}},
+		{tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, []Key{{Type: KeyRune, Rune: ' '}}},
+		{tea.KeyMsg{Type: tea.KeyEnter}, []Key{{Type: KeyEnter}}},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, []Key{{Type: KeyBackspace}}},
+		{tea.KeyMsg{Type: tea.KeyCtrlC}, []Key{{Type: KeyCtrlC}}},
+		{tea.KeyMsg{Type: tea.KeyEsc}, []Key{{Type: KeyEsc}}},
+		{tea.KeyMsg{Type: tea.KeyCtrlN}, []Key{{Type: KeyDown}}},
+		{tea.KeyMsg{Type: tea.KeyPgUp}, []Key{{Type: KeyPgUp}}},
+		{tea.KeyMsg{Type: tea.KeyHome}, []Key{{Type: KeyHome}}},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q"), Alt: true}, []Key{{Type: KeyUnknown}}},
+		{tea.KeyMsg{Type: tea.KeyF1}, []Key{{Type: KeyUnknown}}},
+	}
+	for _, tt := range tests {
+		if got := keys(tt.msg); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("keys(%q) = %+v, want %+v", tt.msg.String(), got, tt.want)
+		}
+	}
+}
+
+func TestProgramQuits(t *testing.T) {
+	tl := NewTimeline("test", testEntries(), time.UTC)
+	p := &program{model: tl}
+	if p.View() != "" {
+		t.Error("view drawn before the terminal size is known")
+	}
+	p.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	if p.View() == "" {
+		t.Error("empty view after the terminal size is known")
+	}
+	if _, cmd := p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}); cmd != nil {
+		t.Error("j should not quit")
+	}
+	if _, cmd := p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Error("q should quit")
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := Fit("abc", 5); got != "abc  " {
+		t.Errorf("Fit pad = %q", got)
+	}
+	if got := Fit("abcdef", 4); got != "abc…" {
+		t.Errorf("Fit truncate = %q", got)
+	}
+	if got := Fit("a\tb", 3); got != "a b" {
+		t.Errorf("Fit control = %q", got)
+	}
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	boldOn  = "\x1b[1m"
+	boldOff = "\x1b[22m"
+)
+
+// PickItem is a candidate in a Picker: Label is matched and shown, Value
+// is returned when picked
+type PickItem struct {
+	Label string
+	Value string
+}
+
+// Picker is a fuzzy finder over a list of items, ranked by match score and
+// then by their original order
+type Picker struct {
+	items    []PickItem
+	multi    bool
+	query    string
+	matches  []pickMatch
+	marked   map[int]bool
+	cursor   int
+	offset   int
+	accepted bool
+}
+
+type pickMatch struct {
+	item      int
+	score     int
+	positions []int
+}
+
+// NewPicker returns a picker over items starting with query. With multi,
+// Tab marks several items.
+func NewPicker(items []PickItem, query string, multi bool) *Picker {
+	p := &Picker{items: items, multi: multi, query: query, marked: make(map[int]bool)}
+	p.rematch()
+	return p
+}
+
+// Selected returns the values of the marked items, or of the item under
+// the cursor, or nil when the picker was cancelled
+func (p *Picker) Selected() []string {
+	if !p.accepted {
+		return nil
+	}
+	var values []string
+	for i, item := range p.items {
+		if p.marked[i] {
+			values = append(values, item.Value)
+		}
+	}
+	if len(values) == 0 && len(p.matches) > 0 {
+		values = append(values, p.items[p.matches[p.cursor].item].Value)
+	}
+	return values
+}
+
+// Update applies a key press
+func (p *Picker) Update(key Key) bool {
+	switch key.Type {
+	case KeyCtrlC, KeyEsc:
+		return true
+	case KeyEnter:
+		p.accepted = true
+		return true
+	case KeyUp:
+		p.move(-1)
+	case KeyDown:
+		p.move(1)
+	case KeyPgUp:
+		p.move(-10)
+	case KeyPgDown:
+		p.move(10)
+	case KeyTab:
+		if p.multi && len(p.matches) > 0 {
+			i := p.matches[p.cursor].item
+			if p.marked[i] {
+				delete(p.marked, i)
+			} else {
+				p.marked[i] = true
+			}
+			p.move(1)
+		}
+	case KeyBackspace:
+		if r := []rune(p.query); len(r) > 0 {
+			p.query = string(r[:len(r)-1])
+			p.rematch()
+		}
+	case KeyCtrlU:
+		p.query = ""
+		p.rematch()
+	case KeyRune:
+		p.query += string(key.Rune)
+		p.rematch()
+	}
+	return false
+}
+
+// rematch ranks the items against the query and resets the cursor
+func (p *Picker) rematch() {
+	p.matches = p.matches[:0]
+	for i, item := range p.items {
+		score, positions, ok := FuzzyMatch(p.query, item.Label)
+		if ok {
+			p.matches = append(p.matches, pickMatch{item: i, score: score, positions: positions})
+		}
+	}
+	sort.SliceStable(p.matches, func(i, j int) bool {
+		return p.matches[i].score > p.matches[j].score
+	})
+	p.cursor, p.offset = 0, 0
+}
+
+func (p *Picker) move(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.matches) {
+		p.cursor = len(p.matches) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+// View renders the prompt, match count, and ranked items
+func (p *Picker) View(width, height int) []string {
+	status := fmt.Sprintf("  %d/%d", len(p.matches), len(p.items))
+	if n := len(p.marked); n > 0 {
+		status += fmt.Sprintf(" (%d marked)", n)
+	}
+	lines := []string{Fit("> "+p.query+"▏", width), Fit(status, width)}
+
+	listHeight := height - len(lines)
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+listHeight {
+		p.offset = p.cursor - listHeight + 1
+	}
+	for i := p.offset; i < len(p.matches) && i < p.offset+listHeight; i++ {
+		m := p.matches[i]
+		prefix := "  "
+		if i == p.cursor {
+			prefix = "> "
+		}
+		if p.marked[m.item] {
+			prefix = prefix[:1] + "*"
+		}
+		line := prefix + emphasize(p.items[m.item].Label, width-len(prefix), m.positions)
+		if i == p.cursor {
+			line = Highlight(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// emphasize fits label to width with the runes at positions in bold,
+// except those cut off by truncation
+func emphasize(label string, width int, positions []int) string {
+	s := Fit(label, width)
+	if len(positions) == 0 {
+		return s
+	}
+	runes := []rune(s)
+	truncated := utf8.RuneCountInString(label) > width
+	bold := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		if pos < len(runes) && !(truncated && pos == len(runes)-1) {
+			bold[pos] = true
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if bold[i] && !bold[i-1] {
+			b.WriteString(boldOn)
+		}
+		b.WriteRune(r)
+		if bold[i] && !bold[i+1] {
+			b.WriteString(boldOff)
+		}
+	}
+	return b.String()
+}
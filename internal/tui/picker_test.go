@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{"gh", "github.com", true, []int{0, 3}},
+		{"ghc", "GitHub Copilot", true, []int{0, 3, 7}},
+		{"Hub", "github", false, nil},
+		{"hub", "GitHub", true, []int{3, 4, 5}},
+		{"go doc", "Go docs  https://go.dev/doc", true, []int{0, 1, 3, 4, 5}},
+		{"xyz", "github.com", false, nil},
+		{"", "anything", true, nil},
+	}
+	for _, tt := range tests {
+		_, positions, ok := FuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, %v; want %v, %v", tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyMatchPrefersTightMatches(t *testing.T) {
+	tight, _, _ := FuzzyMatch("repo", "My repo")
+	loose, _, _ := FuzzyMatch("repo", "readme project overview")
+	if tight <= loose {
+		t.Errorf("tight score %d should beat loose score %d", tight, loose)
+	}
+}
+
+func TestPicker(t *testing.T) {
+	items := []PickItem{
+		{Label: "Readme project overview  https://a.example", Value: "a"},
+		{Label: "My repo  https://b.example", Value: "b"},
+		{Label: "Go docs  https://c.example", Value: "c"},
+	}
+
+	p := NewPicker(items, "repo", false)
+	if done := p.Update(Key{Type: KeyEnter}); !done {
+		t.Fatal("enter should finish")
+	}
+	if got := p.Selected(); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("best match = %v, want [b]", got)
+	}
+
+	p = NewPicker(items, "", true)
+	p.Update(Key{Type: KeyTab})
+	p.Update(Key{Type: KeyDown})
+	p.Update(Key{Type: KeyTab})
+	p.Update(Key{Type: KeyEnter})
+	if got := p.Selected(); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("marked = %v, want [a c]", got)
+	}
+
+	p = NewPicker(items, "", false)
+	typeKeys(p, "go")
+	p.Update(Key{Type: KeyEsc})
+	if got := p.Selected(); got != nil {
+		t.Errorf("cancelled picker selected %v", got)
+	}
+}
@@ -0,0 +1,103 @@
+// Package tui runs full-screen terminal views over browsing data
+package tui
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// Model is a full-screen view driven by key presses
+type Model interface {
+	// Update applies a key press and reports whether the view is done
+	Update(key Key) (done bool)
+	// View renders the screen as at most height lines
+	View(width, height int) []string
+}
+
+const (
+	reverseVideo = "\x1b[7m"
+	resetStyle   = "\x1b[0m"
+)
+
+// RequireTerminal fails unless a view can be drawn, so commands can check
+// before reading any data
+func RequireTerminal() error {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return errors.New("an interactive terminal is required")
+	}
+	return nil
+}
+
+// Run draws m on the terminal until Update reports it is done. Keys are
+// read from the terminal and the view is drawn on stderr, so stdout can be
+// piped.
+func Run(m Model) error {
+	if err := RequireTerminal(); err != nil {
+		return err
+	}
+	p := tea.NewProgram(&program{model: m}, tea.WithAltScreen(), tea.WithInputTTY(), tea.WithOutput(os.Stderr))
+	_, err := p.Run()
+	return err
+}
+
+// program adapts a Model to bubbletea, which handles the terminal
+type program struct {
+	model         Model
+	width, height int
+}
+
+func (p *program) Init() tea.Cmd {
+	return nil
+}
+
+func (p *program) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		for _, key := range keys(msg) {
+			if p.model.Update(key) {
+				return p, tea.Quit
+			}
+		}
+	}
+	return p, nil
+}
+
+func (p *program) View() string {
+	// Nothing is drawn until the terminal size is known
+	if p.width <= 0 || p.height <= 0 {
+		return ""
+	}
+	return strings.Join(p.model.View(p.width, p.height), "\n")
+}
+
+// Fit truncates or pads s to exactly width columns, counting one column
+// per rune
+func Fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 {
+			return ' '
+		}
+		return r
+	}, s)
+	n := utf8.RuneCountInString(s)
+	if n <= width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// Highlight renders a line in reverse video, for the cursor row
+func Highlight(line string) string {
+	return reverseVideo + line + resetStyle
+}
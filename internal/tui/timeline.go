@@ -0,0 +1,340 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+const timelineHelp = "↑/↓ move  / filter  space select  a all  d group  enter export  q quit"
+
+// Timeline is a scrollable, filterable view of history entries that ends
+// with an optional export of the selected entries
+type Timeline struct {
+	title    string
+	loc      *time.Location
+	entries  []models.HistoryEntry
+	selected map[int]bool
+
+	filter    string
+	filtering bool
+	grouped   bool
+	rows      []timelineRow
+	cursor    int
+	offset    int
+	exported  bool
+}
+
+// timelineRow is either an entry or, when grouped, a domain header
+type timelineRow struct {
+	entry  int // index into entries, -1 for a header
+	domain string
+	count  int
+}
+
+// NewTimeline returns a timeline over entries, shown newest first in loc
+func NewTimeline(title string, entries []models.HistoryEntry, loc *time.Location) *Timeline {
+	sorted := append([]models.HistoryEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+	t := &Timeline{title: title, loc: loc, entries: sorted, selected: make(map[int]bool)}
+	t.refresh()
+	return t
+}
+
+// Exported reports whether the view was closed with the export action
+func (t *Timeline) Exported() bool {
+	return t.exported
+}
+
+// Selection returns the selected entries, or every entry matching the
+// filter when none are selected
+func (t *Timeline) Selection() []models.HistoryEntry {
+	var out []models.HistoryEntry
+	for i, e := range t.entries {
+		if t.selected[i] {
+			out = append(out, e)
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	for i, e := range t.entries {
+		if t.matches(i) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Update applies a key press
+func (t *Timeline) Update(key Key) bool {
+	if t.filtering {
+		t.updateFilter(key)
+		return false
+	}
+
+	switch key.Type {
+	case KeyCtrlC, KeyEsc:
+		return true
+	case KeyEnter:
+		t.exported = true
+		return true
+	case KeyUp:
+		t.move(-1)
+	case KeyDown:
+		t.move(1)
+	case KeyPgUp:
+		t.move(-10)
+	case KeyPgDown:
+		t.move(10)
+	case KeyHome:
+		t.move(-len(t.rows))
+	case KeyEnd:
+		t.move(len(t.rows))
+	case KeyRune:
+		switch key.Rune {
+		case 'q':
+			return true
+		case 'e':
+			t.exported = true
+			return true
+		case 'k':
+			t.move(-1)
+		case 'j':
+			t.move(1)
+		case '/':
+			t.filtering = true
+		case ' ':
+			t.toggle()
+			t.move(1)
+		case 'a':
+			t.toggleAll()
+		case 'd':
+			t.grouped = !t.grouped
+			t.refresh()
+		}
+	}
+	return false
+}
+
+// updateFilter edits the filter while it has focus
+func (t *Timeline) updateFilter(key Key) {
+	switch key.Type {
+	case KeyEnter:
+		t.filtering = false
+		return
+	case KeyEsc, KeyCtrlC:
+		t.filtering = false
+		t.filter = ""
+	case KeyBackspace:
+		if r := []rune(t.filter); len(r) > 0 {
+			t.filter = string(r[:len(r)-1])
+		}
+	case KeyCtrlU:
+		t.filter = ""
+	case KeyRune:
+		t.filter += string(key.Rune)
+	default:
+		return
+	}
+	t.cursor, t.offset = 0, 0
+	t.refresh()
+}
+
+// matches reports whether entry i contains every word of the filter
+func (t *Timeline) matches(i int) bool {
+	if t.filter == "" {
+		return true
+	}
+	e := t.entries[i]
+	haystack := strings.ToLower(e.Title + " " + e.URL + " " + e.Domain)
+	for _, word := range strings.Fields(strings.ToLower(t.filter)) {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// refresh rebuilds the rows from the filter and grouping
+func (t *Timeline) refresh() {
+	t.rows = t.rows[:0]
+	if !t.grouped {
+		for i := range t.entries {
+			if t.matches(i) {
+				t.rows = append(t.rows, timelineRow{entry: i})
+			}
+		}
+		t.clampCursor()
+		return
+	}
+
+	groups := make(map[string][]int)
+	var domains []string
+	for i, e := range t.entries {
+		if !t.matches(i) {
+			continue
+		}
+		if _, ok := groups[e.Domain]; !ok {
+			domains = append(domains, e.Domain)
+		}
+		groups[e.Domain] = append(groups[e.Domain], i)
+	}
+	sort.SliceStable(domains, func(i, j int) bool {
+		return len(groups[domains[i]]) > len(groups[domains[j]])
+	})
+	for _, d := range domains {
+		t.rows = append(t.rows, timelineRow{entry: -1, domain: d, count: len(groups[d])})
+		for _, i := range groups[d] {
+			t.rows = append(t.rows, timelineRow{entry: i, domain: d})
+		}
+	}
+	t.clampCursor()
+}
+
+func (t *Timeline) move(delta int) {
+	t.cursor += delta
+	t.clampCursor()
+}
+
+func (t *Timeline) clampCursor() {
+	if t.cursor >= len(t.rows) {
+		t.cursor = len(t.rows) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+}
+
+// toggle selects or deselects the entry under the cursor, or every entry
+// of the domain under the cursor
+func (t *Timeline) toggle() {
+	if len(t.rows) == 0 {
+		return
+	}
+	r := t.rows[t.cursor]
+	if r.entry >= 0 {
+		t.setSelected(r.entry, !t.selected[r.entry])
+		return
+	}
+
+	var group []int
+	for _, row := range t.rows {
+		if row.entry >= 0 && row.domain == r.domain {
+			group = append(group, row.entry)
+		}
+	}
+	t.setAll(group)
+}
+
+// toggleAll selects every visible entry, or deselects them if all are
+// already selected
+func (t *Timeline) toggleAll() {
+	var visible []int
+	for _, r := range t.rows {
+		if r.entry >= 0 {
+			visible = append(visible, r.entry)
+		}
+	}
+	t.setAll(visible)
+}
+
+// setAll selects entries, or deselects them if all are already selected
+func (t *Timeline) setAll(entries []int) {
+	all := true
+	for _, i := range entries {
+		all = all && t.selected[i]
+	}
+	for _, i := range entries {
+		t.setSelected(i, !all)
+	}
+}
+
+func (t *Timeline) setSelected(i int, on bool) {
+	if on {
+		t.selected[i] = true
+	} else {
+		delete(t.selected, i)
+	}
+}
+
+// View renders the title, filter, rows, and help lines
+func (t *Timeline) View(width, height int) []string {
+	visible := 0
+	for _, r := range t.rows {
+		if r.entry >= 0 {
+			visible++
+		}
+	}
+
+	status := fmt.Sprintf(" %s  %d of %d entries", t.title, visible, len(t.entries))
+	if n := len(t.selected); n > 0 {
+		status += fmt.Sprintf(", %d selected", n)
+	}
+	lines := []string{Highlight(Fit(status, width))}
+
+	switch {
+	case t.filtering:
+		lines = append(lines, Fit(" /"+t.filter+"▏", width))
+	case t.filter != "":
+		lines = append(lines, Fit(" filter: "+t.filter+"  (/ to edit, Esc in filter to clear)", width))
+	default:
+		lines = append(lines, "")
+	}
+
+	listHeight := height - 3
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+listHeight {
+		t.offset = t.cursor - listHeight + 1
+	}
+	for i := t.offset; i < len(t.rows) && i < t.offset+listHeight; i++ {
+		line := Fit(t.formatRow(t.rows[i], width), width)
+		if i == t.cursor {
+			line = Highlight(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(t.rows) == 0 {
+		lines = append(lines, Fit(" No matching entries", width))
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	return append(lines, Fit(" "+timelineHelp, width))
+}
+
+func (t *Timeline) formatRow(r timelineRow, width int) string {
+	if r.entry < 0 {
+		return fmt.Sprintf(" %s (%d)", r.domain, r.count)
+	}
+
+	e := t.entries[r.entry]
+	check := "[ ]"
+	if t.selected[r.entry] {
+		check = "[x]"
+	}
+	title := e.Title
+	if title == "" {
+		title = e.URL
+	}
+	when := e.Timestamp.In(t.loc).Format("15:04")
+	if t.grouped {
+		return fmt.Sprintf("   %s %s  %s", check, when, title)
+	}
+	domainWidth := 24
+	if width < 80 {
+		domainWidth = 16
+	}
+	return fmt.Sprintf(" %s %s  %s  %s", check, when, Fit(e.Domain, domainWidth), title)
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+func typeKeys(m Model, s string) {
+	for _, r := range s {
+		m.Update(Key{Type: KeyRune, Rune: r})
+	}
+}
+
+func testEntries() []models.HistoryEntry {
+	base := time.Date(2025, 12, 15, 9, 0, 0, 0, time.UTC)
+	return []models.HistoryEntry{
+		{URL: "https://go.dev/doc", Title: "Go docs", Domain: "go.dev", Timestamp: base},
+		{URL: "https://github.com/a", Title: "Repo A", Domain: "github.com", Timestamp: base.Add(time.Minute)},
+		{URL: "https://github.com/b", Title: "Repo B", Domain: "github.com", Timestamp: base.Add(2 * time.Minute)},
+	}
+}
+
+func urls(entries []models.HistoryEntry) string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.URL)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestTimelineFilterAndExport(t *testing.T) {
+	tl := NewTimeline("test", testEntries(), time.UTC)
+	typeKeys(tl, "/repo")
+	tl.Update(Key{Type: KeyEnter})
+
+	if got := urls(tl.Selection()); got != "https://github.com/b https://github.com/a" {
+		t.Errorf("filtered selection = %s", got)
+	}
+
+	// Select the first match only, then export
+	typeKeys(tl, " ")
+	if done := tl.Update(Key{Type: KeyEnter}); !done || !tl.Exported() {
+		t.Fatal("enter should export and finish")
+	}
+	if got := urls(tl.Selection()); got != "https://github.com/b" {
+		t.Errorf("selection = %s", got)
+	}
+}
+
+func TestTimelineGroupSelect(t *testing.T) {
+	tl := NewTimeline("test", testEntries(), time.UTC)
+	typeKeys(tl, "d")
+
+	view := strings.Join(tl.View(80, 10), "\n")
+	if !strings.Contains(view, "github.com (2)") || !strings.Contains(view, "go.dev (1)") {
+		t.Fatalf("grouped view missing headers:\n%s", view)
+	}
+
+	// The cursor starts on the largest group's header
+	typeKeys(tl, " ")
+	if got := urls(tl.Selection()); got != "https://github.com/b https://github.com/a" {
+		t.Errorf("group selection = %s", got)
+	}
+
+	if done := tl.Update(Key{Type: KeyRune, Rune: 'q'}); !done || tl.Exported() {
+		t.Error("q should quit without exporting")
+	}
+}
@@ -0,0 +1,174 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// azureAPIVersion is the Blob service REST version requests are made with
+const azureAPIVersion = "2021-08-06"
+
+// azureStorageScope is the OAuth scope of bearer tokens for Blob storage
+const azureStorageScope = "https://storage.azure.com/.default"
+
+// azureCredential returns the token credential used when no key or SAS is
+// set, replaced in tests
+var azureCredential = func() (azcore.TokenCredential, error) {
+	return azidentity.NewDefaultAzureCredential(nil)
+}
+
+// azureAccount holds the storage account settings used for az:// uploads
+type azureAccount struct {
+	Name     string
+	Key      string
+	SAS      string
+	Endpoint string
+}
+
+// loadAzureAccount reads AZURE_STORAGE_CONNECTION_STRING, or
+// AZURE_STORAGE_ACCOUNT with AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN,
+// the same variables the az CLI uses. An account with neither a key nor a
+// SAS is signed in to with Microsoft Entra ID instead.
+func loadAzureAccount() (azureAccount, error) {
+	var a azureAccount
+	if cs := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); cs != "" {
+		for _, part := range strings.Split(cs, ";") {
+			k, v, _ := strings.Cut(part, "=")
+			switch k {
+			case "AccountName":
+				a.Name = v
+			case "AccountKey":
+				a.Key = v
+			case "SharedAccessSignature":
+				a.SAS = v
+			case "BlobEndpoint":
+				a.Endpoint = strings.TrimSuffix(v, "/")
+			}
+		}
+	} else {
+		a.Name = os.Getenv("AZURE_STORAGE_ACCOUNT")
+		a.Key = os.Getenv("AZURE_STORAGE_KEY")
+		a.SAS = os.Getenv("AZURE_STORAGE_SAS_TOKEN")
+	}
+	a.SAS = strings.TrimPrefix(a.SAS, "?")
+
+	if a.Endpoint == "" && a.Name != "" {
+		a.Endpoint = "https://" + a.Name + ".blob.core.windows.net"
+	}
+	if a.Endpoint == "" {
+		return a, fmt.Errorf("no Azure storage account found (set AZURE_STORAGE_CONNECTION_STRING or AZURE_STORAGE_ACCOUNT)")
+	}
+	return a, nil
+}
+
+// putAzure uploads body as a block blob to the container named by the
+// target's bucket. Without a key or SAS the request carries a bearer token
+// from azidentity's default chain: AZURE_CLIENT_ID/AZURE_TENANT_ID with a
+// secret or certificate, workload identity, managed identity, then the az
+// and azd CLI logins.
+func putAzure(ctx context.Context, t Target, body []byte, contentType string) error {
+	account, err := loadAzureAccount()
+	if err != nil {
+		return err
+	}
+
+	endpoint := account.Endpoint + "/" + t.Bucket + "/" + escapeKey(t.Key)
+	if account.Key == "" && account.SAS != "" {
+		endpoint += "?" + account.SAS
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	switch {
+	case account.Key != "":
+		if err := signAzureSharedKey(req, account, len(body)); err != nil {
+			return err
+		}
+	case account.SAS == "":
+		cred, err := azureCredential()
+		if err != nil {
+			return fmt.Errorf("no Azure storage credentials found (set AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN, or sign in with az login): %v", err)
+		}
+		token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureStorageScope}})
+		if err != nil {
+			return fmt.Errorf("failed to get an Azure storage token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to Azure: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Azure upload returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// signAzureSharedKey sets the SharedKey Authorization header
+func signAzureSharedKey(req *http.Request, account azureAccount, contentLength int) error {
+	key, err := base64.StdEncoding.DecodeString(account.Key)
+	if err != nil {
+		return fmt.Errorf("invalid Azure storage key: %v", err)
+	}
+
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+	var canonicalHeaders strings.Builder
+	for _, name := range msHeaders {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalHeaders.String() + "/" + account.Name + req.URL.EscapedPath()
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+account.Name+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return nil
+}
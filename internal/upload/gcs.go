@@ -0,0 +1,29 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/storage/v1"
+)
+
+// gcsOptions are extra client options, replaced in tests
+var gcsOptions []option.ClientOption
+
+// putGCS uploads body with credentials from Application Default Credentials
+// (GOOGLE_APPLICATION_CREDENTIALS, gcloud auth application-default login, or
+// the metadata server)
+func putGCS(ctx context.Context, t Target, body []byte, contentType string) error {
+	opts := append([]option.ClientOption{option.WithScopes(storage.DevstorageReadWriteScope)}, gcsOptions...)
+	svc, err := storage.NewService(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create GCS client: %v", err)
+	}
+	obj := &storage.Object{Name: t.Key, ContentType: contentType}
+	if _, err := svc.Objects.Insert(t.Bucket, obj).Media(bytes.NewReader(body)).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to upload to GCS: %v", err)
+	}
+	return nil
+}
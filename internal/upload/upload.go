@@ -0,0 +1,82 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// Target is a parsed object storage destination such as s3://bucket/prefix/
+type Target struct {
+	Scheme string
+	Bucket string
+	Key    string
+}
+
+// IsTarget reports whether dest is an s3://, gs://, or az:// URL
+func IsTarget(dest string) bool {
+	for _, scheme := range []string{"s3://", "gs://", "az://"} {
+		if strings.HasPrefix(dest, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse splits dest into scheme, bucket (container for az://), and key.
+// A key ending in "/" is a prefix that name is appended to.
+func Parse(dest, name string) (Target, error) {
+	u, err := url.Parse(dest)
+	if err != nil || !IsTarget(dest) || u.Host == "" {
+		return Target{}, fmt.Errorf("invalid upload destination %q (use s3://bucket/prefix/, gs://bucket/prefix/, or az://container/prefix/)", dest)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" || strings.HasSuffix(key, "/") {
+		key += name
+	}
+	return Target{Scheme: u.Scheme, Bucket: u.Host, Key: key}, nil
+}
+
+// Upload stores body at dest and returns the URL of the stored object. When
+// dest is a prefix the object is named web-recap-<UTC time>.<ext>, with the
+// extension guessed from the content.
+func Upload(ctx context.Context, dest string, body []byte) (string, error) {
+	ext, contentType := contentKind(body)
+	name := fmt.Sprintf("web-recap-%s.%s", time.Now().UTC().Format("20060102T150405Z"), ext)
+	t, err := Parse(dest, name)
+	if err != nil {
+		return "", err
+	}
+
+	switch t.Scheme {
+	case "s3":
+		err = putS3(ctx, t, body, contentType)
+	case "gs":
+		err = putGCS(ctx, t, body, contentType)
+	case "az":
+		err = putAzure(ctx, t, body, contentType)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s://%s/%s", t.Scheme, t.Bucket, t.Key), nil
+}
+
+// contentKind guesses the file extension and content type of the output
+func contentKind(body []byte) (string, string) {
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(body, []byte("ARROW1")):
+		return "arrow", "application/vnd.apache.arrow.file"
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['):
+		return "json", "application/json"
+	default:
+		return "txt", "text/plain; charset=utf-8"
+	}
+}
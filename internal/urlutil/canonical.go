@@ -0,0 +1,145 @@
+package urlutil
+
+import (
+	"net/url"
+	"strings"
+)
+
+// mobileLabels are host labels that mark a mobile or AMP copy of a site,
+// e.g. m.youtube.com or en.m.wikipedia.org
+var mobileLabels = map[string]bool{"m": true, "mobile": true, "amp": true}
+
+// CanonicalURL rewrites the AMP and mobile forms of a page's URL to its
+// desktop form, so the same article is counted once:
+//
+//   - AMP cache URLs (google.com/amp/s/..., *.cdn.ampproject.org/c/s/...)
+//     become the page they cache
+//   - amp., m., and mobile. host labels are dropped (m.example.com,
+//     en.m.wikipedia.org), unless that would leave a bare top-level domain
+//   - an amp path segment at either end of the path (/amp/story,
+//     /story/amp/), a .amp extension, and amp or outputType=amp query
+//     parameters are removed
+//
+// Other URLs, and those that don't parse, are returned unchanged.
+func CanonicalURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return rawURL
+	}
+
+	if cached, ok := ampCacheTarget(u); ok {
+		if t, err := url.Parse(cached); err == nil && t.Host != "" {
+			u = t
+		}
+	}
+
+	labels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	kept := labels[:0:0]
+	for i, l := range labels {
+		// Keep at least a name and a top-level domain after the label
+		if mobileLabels[l] && len(labels)-i > 2 {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if len(kept) != len(labels) {
+		host := strings.Join(kept, ".")
+		if port := u.Port(); port != "" {
+			host += ":" + port
+		}
+		u.Host = host
+	}
+
+	if p := u.EscapedPath(); canonicalPath(p) != p {
+		escaped := canonicalPath(p)
+		if unescaped, err := url.PathUnescape(escaped); err == nil {
+			u.Path, u.RawPath = unescaped, escaped
+		}
+	}
+	u.RawQuery = dropAMPParams(u.RawQuery)
+	return u.String()
+}
+
+// ampCacheTarget returns the URL of the page an AMP cache URL serves
+func ampCacheTarget(u *url.URL) (string, bool) {
+	host := strings.ToLower(u.Hostname())
+	var rest string
+	switch {
+	case (host == "www.google.com" || host == "google.com") && strings.HasPrefix(u.Path, "/amp/"):
+		rest = strings.TrimPrefix(u.Path, "/amp/")
+	case strings.HasSuffix(host, ".cdn.ampproject.org"):
+		rest = strings.TrimPrefix(u.Path, "/")
+		for _, prefix := range []string{"c/", "v/", "i/"} {
+			rest = strings.TrimPrefix(rest, prefix)
+		}
+	default:
+		return "", false
+	}
+
+	scheme := "http://"
+	if strings.HasPrefix(rest, "s/") {
+		scheme, rest = "https://", rest[2:]
+	}
+	if rest == "" {
+		return "", false
+	}
+	target := scheme + rest
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target, true
+}
+
+// canonicalPath removes an amp segment at the start or end of p, and a
+// .amp extension, keeping any trailing slash
+func canonicalPath(p string) string {
+	if p == "" {
+		return p
+	}
+	trailing := strings.HasSuffix(p, "/") && p != "/"
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	if len(segments) > 1 && strings.EqualFold(segments[0], "amp") {
+		segments = segments[1:]
+	}
+	if n := len(segments); n > 1 && strings.EqualFold(segments[n-1], "amp") {
+		segments = segments[:n-1]
+		trailing = false
+	}
+	last := segments[len(segments)-1]
+	for _, ext := range []string{".amp.html", ".amp"} {
+		if len(last) > len(ext) && strings.HasSuffix(strings.ToLower(last), ext) {
+			last = last[:len(last)-len(ext)]
+			if ext == ".amp.html" {
+				last += ".html"
+			}
+			break
+		}
+	}
+	segments[len(segments)-1] = last
+
+	p = "/" + strings.Join(segments, "/")
+	if trailing {
+		p += "/"
+	}
+	return p
+}
+
+// dropAMPParams removes amp, amp=1, and outputType=amp from a raw query,
+// keeping the other parameters in their order
+func dropAMPParams(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, p := range params {
+		key, value, _ := strings.Cut(p, "=")
+		switch {
+		case strings.EqualFold(key, "amp") && (value == "" || value == "1" || strings.EqualFold(value, "true")):
+		case strings.EqualFold(key, "outputType") && strings.EqualFold(value, "amp"):
+		default:
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "&")
+}
@@ -0,0 +1,34 @@
+package urlutil
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://m.youtube.com/watch?v=abc", "https://youtube.com/watch?v=abc"},
+		{"https://en.m.wikipedia.org/wiki/Go_(programming_language)", "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+		{"https://mobile.twitter.com/golang", "https://twitter.com/golang"},
+		{"https://amp.theguardian.com/world/2024/story", "https://theguardian.com/world/2024/story"},
+		{"https://www.example.com/amp/2024/01/story", "https://www.example.com/2024/01/story"},
+		{"https://www.example.com/2024/01/story/amp/", "https://www.example.com/2024/01/story"},
+		{"https://news.example.com/article.amp.html", "https://news.example.com/article.html"},
+		{"https://news.example.com/article.amp", "https://news.example.com/article"},
+		{"https://news.example.com/story?id=7&amp=1&ref=rss", "https://news.example.com/story?id=7&ref=rss"},
+		{"https://www.example.com/story?outputType=amp", "https://www.example.com/story"},
+		{"https://www.google.com/amp/s/www.example.co.uk/news/world-123/amp", "https://www.example.co.uk/news/world-123"},
+		{"https://www-example-com.cdn.ampproject.org/c/s/www.example.com/story/amp", "https://www.example.com/story"},
+		// Left alone
+		{"https://m.me/someone", "https://m.me/someone"},
+		{"https://amp.dev/", "https://amp.dev/"},
+		{"https://example.com/amp", "https://example.com/amp"},
+		{"https://example.com/champ/ions", "https://example.com/champ/ions"},
+		{"https://example.com/?q=a%20b&x=1", "https://example.com/?q=a%20b&x=1"},
+		{"chrome://settings", "chrome://settings"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.in); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package urlutil
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// cjkScripts may be mixed with Latin and each other in one label, as
+// Japanese and Korean names often are
+var cjkScripts = []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Bopomofo}
+
+// otherScripts are the scripts besides Latin and CJK a label's letters are
+// checked against
+var otherScripts = map[string]*unicode.RangeTable{
+	"Arabic":     unicode.Arabic,
+	"Armenian":   unicode.Armenian,
+	"Cherokee":   unicode.Cherokee,
+	"Cyrillic":   unicode.Cyrillic,
+	"Devanagari": unicode.Devanagari,
+	"Georgian":   unicode.Georgian,
+	"Greek":      unicode.Greek,
+	"Hebrew":     unicode.Hebrew,
+	"Thai":       unicode.Thai,
+}
+
+// latinLookalikes are the Cyrillic and Greek letters that pass for Latin
+// ones; a label made only of them reads as a Latin word, e.g. "аре" as "ape"
+var latinLookalikes = "асԁеһіјӏорԛѕԝхуъьАВЕКМНОРСТХαικνορτυχΑΒΕΖΗΙΚΜΝΟΡΤΥΧ"
+
+// DisplayHost returns host, with or without a port, with its punycode
+// (xn--) labels decoded to Unicode, e.g. münchen.de for xn--mnchen-3ya.de.
+// It returns "" for a host with nothing to decode or that does not decode.
+func DisplayHost(host string) string {
+	if !strings.Contains(strings.ToLower(host), "xn--") {
+		return ""
+	}
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		name, port = host, ""
+	}
+	decoded, err := idna.Punycode.ToUnicode(strings.ToLower(name))
+	if err != nil || decoded == name {
+		return ""
+	}
+	if port != "" {
+		return net.JoinHostPort(decoded, port)
+	}
+	return decoded
+}
+
+// DisplayURL returns rawURL with its host decoded by DisplayHost, or "" when
+// the host has nothing to decode
+func DisplayURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	display := DisplayHost(u.Host)
+	if display == "" {
+		return ""
+	}
+	return strings.Replace(rawURL, u.Host, display, 1)
+}
+
+// Lookalike reports whether an internationalized host could pass for
+// another one: a label that mixes Latin with another script (apple.com with
+// a Cyrillic "а"), mixes two non-Latin scripts, or under an ASCII top-level
+// domain is made only of letters that look Latin. Plain ASCII hosts are
+// never lookalikes.
+func Lookalike(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if display := DisplayHost(host); display != "" {
+		host = display
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	asciiTLD := isASCII(labels[len(labels)-1])
+	for _, label := range labels {
+		if isASCII(label) {
+			continue
+		}
+		if mixedScripts(label) {
+			return true
+		}
+		if asciiTLD && allLookalikes(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// mixedScripts reports whether label has letters from more than one script,
+// leaving out the mixes of Latin and CJK scripts that are normal
+func mixedScripts(label string) bool {
+	var latin, cjk bool
+	other := ""
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		case unicode.In(r, cjkScripts...):
+			cjk = true
+		default:
+			script := scriptOf(r)
+			if other != "" && script != other {
+				return true
+			}
+			other = script
+		}
+	}
+	return other != "" && (latin || cjk)
+}
+
+// scriptOf names the script of a letter outside Latin and CJK, or "other"
+func scriptOf(r rune) string {
+	for name, table := range otherScripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return "other"
+}
+
+// allLookalikes reports whether every letter of label passes for Latin
+func allLookalikes(label string) bool {
+	letters := 0
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !strings.ContainsRune(latinLookalikes, r) {
+			return false
+		}
+		letters++
+	}
+	return letters > 0
+}
+
+// isASCII reports whether s has only ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
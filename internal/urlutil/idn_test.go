@@ -0,0 +1,39 @@
+package urlutil
+
+import "testing"
+
+func TestDisplayHost(t *testing.T) {
+	tests := []struct {
+		host, display string
+		lookalike     bool
+	}{
+		{"xn--mnchen-3ya.de", "münchen.de", false},
+		{"XN--MNCHEN-3YA.DE:8080", "münchen.de:8080", false},
+		{"xn--e1afmkfd.xn--p1ai", "пример.рф", false},
+		{"xn--wgv71a119e.jp", "日本語.jp", false},
+		{"xn--hxajbheg2az3al.gr", "παράδειγμα.gr", false},
+		// A Cyrillic "а" in apple.com, and a word spelled only in Cyrillic
+		// letters that look Latin
+		{"xn--pple-43d.com", "аpple.com", true},
+		{"xn--80ak6aa92e.com", "аррӏе.com", true},
+		// Under a Cyrillic top-level domain such a word is left alone
+		{"xn--e1argc3h.xn--p1ai", "ѕсоре.рф", false},
+		{"example.com", "", false},
+		{"xn--99999999999.com", "", false},
+	}
+	for _, tt := range tests {
+		if got := DisplayHost(tt.host); got != tt.display {
+			t.Errorf("DisplayHost(%q) = %q, want %q", tt.host, got, tt.display)
+		}
+		if got := Lookalike(tt.host); got != tt.lookalike {
+			t.Errorf("Lookalike(%q) = %v, want %v", tt.host, got, tt.lookalike)
+		}
+	}
+
+	if got := DisplayURL("https://xn--mnchen-3ya.de/stadt?q=1"); got != "https://münchen.de/stadt?q=1" {
+		t.Errorf("DisplayURL = %q", got)
+	}
+	if got := DisplayURL("https://example.com/xn--mnchen-3ya"); got != "" {
+		t.Errorf("DisplayURL of an ASCII host = %q, want empty", got)
+	}
+}
@@ -0,0 +1,75 @@
+package urlutil
+
+import (
+	"net/url"
+	"strings"
+)
+
+// internalSchemes are the schemes of pages a browser serves itself or that
+// carry no page: settings, new tabs, extensions, and inline data
+var internalSchemes = map[string]bool{
+	"about":                true,
+	"blob":                 true,
+	"brave":                true,
+	"chrome":               true,
+	"chrome-error":         true,
+	"chrome-extension":     true,
+	"chrome-native":        true,
+	"chrome-search":        true,
+	"chrome-untrusted":     true,
+	"data":                 true,
+	"devtools":             true,
+	"edge":                 true,
+	"javascript":           true,
+	"moz-extension":        true,
+	"opera":                true,
+	"resource":             true,
+	"safari-web-extension": true,
+	"view-source":          true,
+	"vivaldi":              true,
+}
+
+// sponsoredHosts are ad servers and browser rewards endpoints; a visit to
+// one of them or a subdomain is an ad click or a ping, not a page read
+var sponsoredHosts = []string{
+	"doubleclick.net",
+	"googleadservices.com",
+	"googlesyndication.com",
+	"ads.brave.com",
+	"ads-serve.brave.com",
+	"rewards.brave.com",
+}
+
+// Internal reports whether rawURL is a browser's own page (chrome://,
+// brave://, edge://, about:, extension pages) or a sponsored hit: an ad click
+// redirect such as google.com/aclk or a Brave Ads or Rewards endpoint
+func Internal(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if internalSchemes[scheme] {
+		return true
+	}
+	if scheme != "http" && scheme != "https" {
+		return false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	for _, h := range sponsoredHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	// Ad clicks on search result pages redirect through these paths
+	switch {
+	case strings.HasPrefix(host, "google.") && u.Path == "/aclk":
+		return true
+	case host == "bing.com" && u.Path == "/aclick":
+		return true
+	case host == "duckduckgo.com" && u.Path == "/y.js":
+		return true
+	}
+	return false
+}
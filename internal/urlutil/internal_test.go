@@ -0,0 +1,39 @@
+package urlutil
+
+import "testing"
+
+func TestInternal(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"chrome://newtab/", true},
+		{"brave://rewards/", true},
+		{"edge://settings/privacy", true},
+		{"about:blank", true},
+		{"about:reader?url=https://example.com/", true},
+		{"chrome-extension://abcdef/options.html", true},
+		{"moz-extension://1234/popup.html", true},
+		{"view-source:https://example.com/", true},
+		{"https://www.googleadservices.com/pagead/aclk?sa=L", true},
+		{"https://ad.doubleclick.net/ddm/clk/123", true},
+		{"https://www.google.com/aclk?sa=l&ai=abc", true},
+		{"https://www.google.co.uk/aclk?sa=l", true},
+		{"https://www.bing.com/aclick?ld=abc", true},
+		{"https://duckduckgo.com/y.js?ad_domain=shop.example", true},
+		{"https://rewards.brave.com/", true},
+		{"https://static.ads.brave.com/creative.html", true},
+		{"https://example.com/", false},
+		{"https://www.google.com/search?q=aclk", false},
+		{"https://brave.com/download/", false},
+		{"https://search.brave.com/search?q=go", false},
+		{"file:///home/me/notes.html", false},
+		{"https://notdoubleclick.net/", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := Internal(tt.url); got != tt.want {
+			t.Errorf("Internal(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Package webrecap reads browser history, bookmarks, and open tabs from the
+// local Chrome, Chromium, Edge, Brave, Vivaldi, Firefox, and Safari profiles.
+//
+// It is the library behind the web-recap command, for embedding in other
+// tools:
+//
+//	entries, err := webrecap.QueryHistory(ctx, webrecap.HistoryOptions{
+//		Start: time.Now().Add(-24 * time.Hour),
+//	})
+//
+// Databases are copied before they are read, so browsers can stay open.
+package webrecap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/browser"
+	"github.com/rzolkos/web-recap/internal/database"
+	"github.com/rzolkos/web-recap/internal/models"
+)
+
+// BrowserType identifies a browser family
+type BrowserType = browser.Type
+
+// Supported browser types. Auto (or an empty BrowserType) queries every
+// detected browser.
+const (
+	Auto     BrowserType = browser.Auto
+	Chrome   BrowserType = browser.Chrome
+	Chromium BrowserType = browser.Chromium
+	Edge     BrowserType = browser.Edge
+	Brave    BrowserType = browser.Brave
+	Vivaldi  BrowserType = browser.Vivaldi
+	Firefox  BrowserType = browser.Firefox
+	Safari   BrowserType = browser.Safari
+
+	Whale           BrowserType = browser.Whale
+	SamsungInternet BrowserType = browser.SamsungInternet
+	Yandex          BrowserType = browser.Yandex
+	Arc             BrowserType = browser.Arc
+	PaleMoon        BrowserType = browser.PaleMoon
+	SeaMonkey       BrowserType = browser.SeaMonkey
+	Lynx            BrowserType = browser.Lynx
+	W3m             BrowserType = browser.W3m
+)
+
+// Browser is a browser installation found on this machine
+type Browser = browser.Browser
+
+// Engine is a browser engine family
+type Engine = browser.Engine
+
+// Engines a registered browser can use. Chromium, Gecko, WebKit, and Text
+// browsers are read by the built-in handlers; Custom needs
+// RegisterCustomBrowser.
+const (
+	EngineChromium Engine = browser.EngineChromium
+	EngineGecko    Engine = browser.EngineGecko
+	EngineWebKit   Engine = browser.EngineWebKit
+	EngineText     Engine = browser.EngineText
+	EngineCustom   Engine = browser.EngineCustom
+)
+
+// BrowserDefinition describes a browser to add to detection, such as a
+// Chromium fork, by engine and candidate profile paths
+type BrowserDefinition = browser.Definition
+
+// HistoryQuerier reads history for a custom browser
+type HistoryQuerier = database.HistoryQuerier
+
+// BookmarkQuerier reads bookmarks for a custom browser
+type BookmarkQuerier = database.BookmarkQuerier
+
+// Handlers creates the queriers for a custom browser from its resolved path
+type Handlers = database.Handlers
+
+// HistoryEntry is a single page visit
+type HistoryEntry = models.HistoryEntry
+
+// BookmarkEntry is a single bookmark
+type BookmarkEntry = models.BookmarkEntry
+
+// TabEntry is a single open tab
+type TabEntry = models.TabEntry
+
+// Errors returned when a browser or its data cannot be used
+var (
+	ErrDatabaseNotFound   = browser.ErrDatabaseNotFound
+	ErrUnsupportedBrowser = database.ErrUnsupportedBrowser
+)
+
+// HistoryOptions selects the history to read
+type HistoryOptions struct {
+	// Browser to read; empty or Auto reads every detected browser
+	Browser BrowserType
+	// Path overrides the history database location; requires Browser
+	Path string
+	// Start and End bound the half-open range [Start, End); a zero value
+	// leaves that side open
+	Start time.Time
+	End   time.Time
+}
+
+// BookmarkOptions selects the bookmarks to read
+type BookmarkOptions struct {
+	// Browser to read; empty or Auto reads every detected browser
+	Browser BrowserType
+	// Path overrides the bookmark file (Firefox: profile directory); requires Browser
+	Path string
+	// Start and End bound the date added; a zero value leaves that side open
+	Start time.Time
+	End   time.Time
+}
+
+// TabOptions selects the browsers to read open tabs from. Only
+// Chromium-based browsers are supported.
+type TabOptions struct {
+	// Browser to read; empty or Auto reads every detected Chromium-based browser
+	Browser BrowserType
+	// Path overrides the session directory; requires Browser
+	Path string
+}
+
+// RegisterBrowser adds a browser that uses a built-in engine, so it is
+// detected and can be selected by type in the options
+func RegisterBrowser(def BrowserDefinition) error {
+	if def.Engine == EngineCustom {
+		return fmt.Errorf("browser %s: use RegisterCustomBrowser for the custom engine", def.Type)
+	}
+	return browser.Register(def)
+}
+
+// RegisterCustomBrowser adds a browser with its own handlers
+func RegisterCustomBrowser(def BrowserDefinition, h Handlers) error {
+	return database.RegisterHandlers(def, h)
+}
+
+// Detect returns the browsers with history databases on this machine
+func Detect() []Browser {
+	return browser.NewDetector().Detect()
+}
+
+// QueryHistory returns history entries, newest first. When reading every
+// detected browser, browsers that fail to open are skipped.
+func QueryHistory(ctx context.Context, opts HistoryOptions) ([]HistoryEntry, error) {
+	if isAuto(opts.Browser) {
+		if opts.Path != "" {
+			return nil, fmt.Errorf("a browser type is required with a custom path")
+		}
+
+		var all []HistoryEntry
+		for _, b := range Detect() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			entries, err := database.Query(ctx, &b, opts.Start, opts.End)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				continue
+			}
+			all = append(all, inRange(entries, opts.Start, opts.End)...)
+		}
+		sort.SliceStable(all, func(i, j int) bool {
+			return all[i].Timestamp.After(all[j].Timestamp)
+		})
+		return all, ctx.Err()
+	}
+
+	b, err := resolveBrowser(opts.Browser, opts.Path, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := database.Query(ctx, b, opts.Start, opts.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	return inRange(entries, opts.Start, opts.End), nil
+}
+
+// StreamHistory passes history entries to fn as they are read, newest first,
+// so a large range never has to be held in memory. Browsers are chosen and
+// skipped as for QueryHistory. An error from fn stops the stream and is
+// returned as is.
+func StreamHistory(ctx context.Context, opts HistoryOptions, fn func(HistoryEntry) error) error {
+	var fnErr error
+	send := func(e HistoryEntry) error {
+		if !database.WithinHalfOpenRange(e.Timestamp, opts.Start, opts.End) {
+			return nil
+		}
+		fnErr = fn(e)
+		return fnErr
+	}
+
+	if isAuto(opts.Browser) {
+		if opts.Path != "" {
+			return fmt.Errorf("a browser type is required with a custom path")
+		}
+		// Browsers that fail are only reported in the sources, which are
+		// dropped here as QueryHistory skips them
+		_, err := database.StreamMultipleBrowsers(ctx, browser.NewDetector(), opts.Start, opts.End, send)
+		if fnErr != nil {
+			return fnErr
+		}
+		if err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+
+	b, err := resolveBrowser(opts.Browser, opts.Path, false)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := database.Stream(ctx, b, opts.Start, opts.End, send); err != nil {
+		if fnErr != nil {
+			return fnErr
+		}
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+	return nil
+}
+
+// QueryBookmarks returns bookmarks, most recently added first. When reading
+// every detected browser, browsers that fail to open are skipped.
+func QueryBookmarks(ctx context.Context, opts BookmarkOptions) ([]BookmarkEntry, error) {
+	if isAuto(opts.Browser) {
+		if opts.Path != "" {
+			return nil, fmt.Errorf("a browser type is required with a custom path")
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entries, _, err := database.QueryMultipleBrowsersBookmarks(ctx, browser.NewDetector(), opts.Start, opts.End)
+		return entries, err
+	}
+
+	b, err := resolveBrowser(opts.Browser, opts.Path, browser.EngineOf(opts.Browser) == browser.EngineGecko)
+	if err != nil {
+		return nil, err
+	}
+
+	path := opts.Path
+	if path == "" {
+		path, err = browser.GetBookmarkPath(b.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bookmark path: %v", err)
+		}
+		if browser.EngineOf(b.Type) == browser.EngineGecko {
+			path, err = browser.GetFirefoxProfilePath(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find Firefox profile: %v", err)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := database.QueryBookmarks(ctx, b, path, opts.Start, opts.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks: %w", err)
+	}
+	return entries, nil
+}
+
+// QueryTabs returns the open tabs of Chromium-based browsers. Session file
+// commands that cannot be read are skipped, returning the tabs that can.
+func QueryTabs(ctx context.Context, opts TabOptions) ([]TabEntry, error) {
+	if isAuto(opts.Browser) {
+		if opts.Path != "" {
+			return nil, fmt.Errorf("a browser type is required with a custom path")
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entries, _, err := database.QueryMultipleBrowsersTabs(ctx, browser.NewDetector())
+		return entries, err
+	}
+
+	if !browser.IsChromiumBased(opts.Browser) {
+		return nil, fmt.Errorf("tabs extraction only supported for Chromium-based browsers (chrome, chromium, edge, brave, vivaldi, whale, samsung, yandex, arc)")
+	}
+
+	b, err := resolveBrowser(opts.Browser, opts.Path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	path := opts.Path
+	if path == "" {
+		path, err = browser.GetSessionPath(b.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session path: %v", err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, _, err := database.QueryTabs(ctx, b, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tabs: %w", err)
+	}
+	return entries, nil
+}
+
+// inRange filters entries to [start, end). The history handlers treat an end
+// at midnight as a whole date and read a day past it.
+func inRange(entries []HistoryEntry, start, end time.Time) []HistoryEntry {
+	result := entries[:0]
+	for _, e := range entries {
+		if database.WithinHalfOpenRange(e.Timestamp, start, end) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func isAuto(t BrowserType) bool {
+	return t == "" || t == Auto
+}
+
+// resolveBrowser returns the browser at path, or the detected installation
+// of t when path is empty
+func resolveBrowser(t BrowserType, path string, allowDir bool) (*Browser, error) {
+	if path == "" {
+		b, err := browser.NewDetector().GetBrowser(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get browser: %w", err)
+		}
+		return b, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, path)
+		}
+		return nil, fmt.Errorf("cannot access database file: %v", err)
+	}
+	if info.IsDir() && !allowDir {
+		return nil, fmt.Errorf("path is a directory, not a file: %s", path)
+	}
+
+	return &Browser{Type: t, Name: string(t), Path: path}, nil
+}
@@ -0,0 +1,108 @@
+package webrecap
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rzolkos/web-recap/internal/testutil"
+)
+
+func TestQueryHistoryCustomPath(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	dbPath := createChromeHistoryDB(t, map[string]time.Time{
+		"https://example.com/before": day.Add(-time.Minute),
+		"https://example.com/a":      day.Add(9 * time.Hour),
+		"https://example.com/b":      day.Add(17 * time.Hour),
+		"https://example.com/after":  day.Add(24 * time.Hour),
+	})
+
+	entries, err := QueryHistory(context.Background(), HistoryOptions{
+		Browser: Chrome,
+		Path:    dbPath,
+		Start:   day,
+		End:     day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("QueryHistory: %v", err)
+	}
+
+	var urls []string
+	for _, e := range entries {
+		urls = append(urls, e.URL)
+	}
+	if len(urls) != 2 || urls[0] != "https://example.com/b" || urls[1] != "https://example.com/a" {
+		t.Errorf("got %v, want b then a", urls)
+	}
+	if entries[0].Domain != "example.com" {
+		t.Errorf("domain = %q", entries[0].Domain)
+	}
+}
+
+func TestStreamHistory(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	dbPath := createChromeHistoryDB(t, map[string]time.Time{
+		"https://example.com/before": day.Add(-time.Minute),
+		"https://example.com/a":      day.Add(9 * time.Hour),
+		"https://example.com/b":      day.Add(17 * time.Hour),
+	})
+	opts := HistoryOptions{Browser: Chrome, Path: dbPath, Start: day, End: day.AddDate(0, 0, 1)}
+
+	var urls []string
+	err := StreamHistory(context.Background(), opts, func(e HistoryEntry) error {
+		urls = append(urls, e.URL)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamHistory: %v", err)
+	}
+	if len(urls) != 2 || urls[0] != "https://example.com/b" || urls[1] != "https://example.com/a" {
+		t.Errorf("got %v, want b then a", urls)
+	}
+
+	// An error from fn stops the stream and comes back unwrapped
+	stop := errors.New("stop")
+	calls := 0
+	err = StreamHistory(context.Background(), opts, func(HistoryEntry) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("got %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+func TestQueryHistoryErrors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := QueryHistory(ctx, HistoryOptions{Browser: Chrome, Path: filepath.Join(t.TempDir(), "missing")})
+	if !errors.Is(err, ErrDatabaseNotFound) {
+		t.Errorf("missing path: got %v, want ErrDatabaseNotFound", err)
+	}
+
+	if _, err := QueryHistory(ctx, HistoryOptions{Path: "History"}); err == nil {
+		t.Error("custom path without a browser type: expected error")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	dbPath := createChromeHistoryDB(t, nil)
+	if _, err := QueryHistory(canceled, HistoryOptions{Browser: Chrome, Path: dbPath}); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled context: got %v", err)
+	}
+
+	if _, err := QueryTabs(ctx, TabOptions{Browser: Firefox}); err == nil {
+		t.Error("tabs from Firefox: expected error")
+	}
+}
+
+func createChromeHistoryDB(t *testing.T, visits map[string]time.Time) string {
+	t.Helper()
+	var rows []testutil.ChromeVisit
+	for url, ts := range visits {
+		rows = append(rows, testutil.ChromeVisit{URL: url, Title: url, Time: ts})
+	}
+	return testutil.NewChromeHistoryDB(t, rows...)
+}